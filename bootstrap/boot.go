@@ -10,28 +10,53 @@ import (
 	"github.com/charmbracelet/x/term"
 	"github.com/pubgo/dix/v2"
 	"github.com/pubgo/dix/v2/dixcontext"
+	"github.com/pubgo/fastgit/cmds/addcmd"
+	"github.com/pubgo/fastgit/cmds/auditcmd"
+	"github.com/pubgo/fastgit/cmds/backportcmd"
 	"github.com/pubgo/fastgit/cmds/checkcmd"
+	"github.com/pubgo/fastgit/cmds/checkoutcmd"
 	"github.com/pubgo/fastgit/cmds/chglogcmd"
-	"github.com/pubgo/fastgit/cmds/conflictcmd"
-	"github.com/pubgo/fastgit/cmds/teamcmd"
+	"github.com/pubgo/fastgit/cmds/cicmd"
+	"github.com/pubgo/fastgit/cmds/comparecmd"
 	"github.com/pubgo/fastgit/cmds/configcmd"
+	"github.com/pubgo/fastgit/cmds/conflictcmd"
 	"github.com/pubgo/fastgit/cmds/copilotcmd"
+	"github.com/pubgo/fastgit/cmds/daemoncmd"
 	"github.com/pubgo/fastgit/cmds/docscmd"
+	"github.com/pubgo/fastgit/cmds/doctorcmd"
 	"github.com/pubgo/fastgit/cmds/fastcommitcmd"
 	"github.com/pubgo/fastgit/cmds/ggccmd"
 	"github.com/pubgo/fastgit/cmds/historycmd"
 	"github.com/pubgo/fastgit/cmds/initcmd"
+	"github.com/pubgo/fastgit/cmds/maintaincmd"
+	"github.com/pubgo/fastgit/cmds/newcmd"
 	"github.com/pubgo/fastgit/cmds/prcmd"
+	"github.com/pubgo/fastgit/cmds/previewcmd"
 	"github.com/pubgo/fastgit/cmds/pullcmd"
 	"github.com/pubgo/fastgit/cmds/pushcmd"
+	"github.com/pubgo/fastgit/cmds/releasecmd"
+	"github.com/pubgo/fastgit/cmds/remotecmd"
 	"github.com/pubgo/fastgit/cmds/reviewcmd"
+	"github.com/pubgo/fastgit/cmds/rewordcmd"
+	"github.com/pubgo/fastgit/cmds/scorecmd"
+	"github.com/pubgo/fastgit/cmds/servecmd"
+	"github.com/pubgo/fastgit/cmds/sparsecmd"
 	"github.com/pubgo/fastgit/cmds/sshcmd"
+	"github.com/pubgo/fastgit/cmds/standupcmd"
+	"github.com/pubgo/fastgit/cmds/stashcmd"
 	"github.com/pubgo/fastgit/cmds/tagcmd"
+	"github.com/pubgo/fastgit/cmds/teamcmd"
+	"github.com/pubgo/fastgit/cmds/templatecmd"
+	"github.com/pubgo/fastgit/cmds/ticketcmd"
+	"github.com/pubgo/fastgit/cmds/tutorialcmd"
 	"github.com/pubgo/fastgit/cmds/upgradecmd"
 	"github.com/pubgo/fastgit/cmds/versioncmd"
 	"github.com/pubgo/fastgit/cmds/worktreecmd"
+	"github.com/pubgo/fastgit/cmds/wscmd"
 	"github.com/pubgo/fastgit/pkg/aiprovider"
+	"github.com/pubgo/fastgit/pkg/timing"
 	"github.com/pubgo/fastgit/utils"
+	"github.com/pubgo/fastgit/utils/genaiclient"
 	"github.com/pubgo/funk/v2/assert"
 	"github.com/pubgo/funk/v2/config"
 	"github.com/pubgo/funk/v2/errors"
@@ -52,9 +77,11 @@ func Main() {
 		ggccmd.New(),
 		fastcommitcmd.New(),
 		checkcmd.New(),
+		cicmd.New(),
 		conflictcmd.New(),
 		prcmd.New(),
 		reviewcmd.New(),
+		scorecmd.New(),
 		teamcmd.New(),
 		configcmd.New(),
 		docscmd.New(),
@@ -63,6 +90,27 @@ func Main() {
 		worktreecmd.New(),
 		chglogcmd.NewCommand(),
 		copilotcmd.New(),
+		daemoncmd.New(),
+		tutorialcmd.New(),
+		templatecmd.New(),
+		ticketcmd.New(),
+		previewcmd.New(),
+		backportcmd.New(),
+		rewordcmd.New(),
+		releasecmd.New(),
+		remotecmd.New(),
+		doctorcmd.New(),
+		addcmd.New(),
+		newcmd.New(),
+		standupcmd.New(),
+		wscmd.New(),
+		comparecmd.New(),
+		auditcmd.New(),
+		checkoutcmd.New(),
+		servecmd.New(),
+		sparsecmd.New(),
+		maintaincmd.New(),
+		stashcmd.New(),
 	)
 }
 
@@ -80,24 +128,53 @@ func run(cmds ...*redant.Command) {
 		return nil
 	})
 
+	var timings bool
 	app := &redant.Command{
 		Use:      "fastgit",
 		Short:    "Intelligent generation of git commit message",
 		Children: cmds,
+		Options: redant.OptionSet{
+			{
+				Flag:        "timings",
+				Description: "print a per-phase timing breakdown (git / llm / ui wait) when the command finishes",
+				Value:       redant.BoolOf(&timings),
+				Envs:        []string{"FASTGIT_TIMINGS"},
+				Inherit:     true,
+			},
+			{
+				Flag:        "no-spinner",
+				Description: "print plain progress lines instead of spinners and live views; implied when stdout is not a terminal",
+				Value:       redant.BoolOf(utils.PlainOutputVar()),
+				Envs:        []string{"FASTGIT_PLAIN"},
+				Inherit:     true,
+			},
+		},
 		Middleware: func(next redant.HandlerFunc) redant.HandlerFunc {
 			return func(ctx context.Context, i *redant.Invocation) error {
 				if utils.IsHelp() {
 					return redant.DefaultHelpFn()(ctx, i)
 				}
 
-				if !term.IsTerminal(os.Stdin.Fd()) {
-					return fmt.Errorf("stdin is not terminal")
+				if timings {
+					rec := timing.New()
+					ctx = timing.NewContext(ctx, rec)
+					defer rec.Report(os.Stderr)
+				}
+
+				if !term.IsTerminal(os.Stdin.Fd()) && i.Command.Metadata[utils.CommandMetaNoTTY] != "true" {
+					if i.Command.Metadata[utils.CommandMetaNonInteractive] != "true" {
+						return fmt.Errorf("stdin is not terminal")
+					}
+					if !utils.NonInteractive() {
+						return fmt.Errorf("stdin is not terminal, pass --yes to run without prompts")
+					}
 				}
 
 				initConfig()
 				di := dix.New(dix.WithValuesNull())
 				di.Provide(config.Load[configProvider])
 				di.Provide(utils.NewOpenaiClient)
+				di.Provide(genaiclient.New)
 				di.Provide(aiprovider.Default)
 				return next(dixcontext.Create(ctx, di), i)
 			}
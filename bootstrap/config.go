@@ -5,6 +5,7 @@ import (
 	"log/slog"
 	"os"
 
+	"github.com/joho/godotenv"
 	"github.com/pubgo/funk/v2/assert"
 	"github.com/pubgo/funk/v2/config"
 	"github.com/pubgo/funk/v2/env"
@@ -13,15 +14,31 @@ import (
 	"github.com/pubgo/funk/v2/running"
 	"gopkg.in/yaml.v3"
 
+	"github.com/pubgo/fastgit/cmds/chglogcmd"
 	"github.com/pubgo/fastgit/cmds/fastcommitcmd"
+	"github.com/pubgo/fastgit/cmds/wscmd"
 	"github.com/pubgo/fastgit/configs"
+	"github.com/pubgo/fastgit/pkg/envcrypt"
+	"github.com/pubgo/fastgit/pkg/notify"
+	"github.com/pubgo/fastgit/pkg/scaffold"
+	"github.com/pubgo/fastgit/pkg/tagcheck"
+	"github.com/pubgo/fastgit/pkg/ticket"
 	"github.com/pubgo/fastgit/utils"
+	"github.com/pubgo/fastgit/utils/genaiclient"
 )
 
 type configProvider struct {
-	Version      *configs.Version      `yaml:"version"`
-	OpenaiConfig *utils.OpenaiConfig   `yaml:"openai"`
-	CommitConfig *fastcommitcmd.Config `yaml:"commit"`
+	Version         *configs.Version      `yaml:"version"`
+	OpenaiConfig    *utils.OpenaiConfig   `yaml:"openai"`
+	CommitConfig    *fastcommitcmd.Config `yaml:"commit"`
+	ChangelogConfig *chglogcmd.Config     `yaml:"changelog"`
+	NotifyConfig    *notify.Config        `yaml:"notify"`
+	TicketConfig    *ticket.Config        `yaml:"ticket"`
+	GenaiConfig     *genaiclient.Config   `yaml:"genai"`
+	NewConfig       *scaffold.Config      `yaml:"new"`
+	WorkspaceConfig *wscmd.Config         `yaml:"workspace"`
+	TagConfig       *tagcheck.Config      `yaml:"tag"`
+	EditorConfig    *utils.EditorConfig   `yaml:"editor"`
 }
 
 func initConfig() {
@@ -38,7 +55,10 @@ func initConfig() {
 	})
 
 	env.MustSet("LC_ALL", "C")
-	env.LoadFiles(configs.GetLocalEnvPath()).Must()
+	// fastgit new 等命令会在仓库外运行，此时没有仓库级 env
+	if utils.IsGitRepository() {
+		loadLocalEnv()
+	}
 
 	configPath := configs.GetConfigPath()
 	envPath := configs.GetEnvPath()
@@ -64,3 +84,23 @@ func initConfig() {
 
 	config.SetConfigPath(configPath)
 }
+
+// loadLocalEnv 加载仓库级 env，加密的 fastgit.env.gpg / fastgit.env.age 在此透明解密；解密失败只告警
+func loadLocalEnv() {
+	data, err := envcrypt.Read(configs.GetLocalEnvPath())
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to read local env, provider keys from it are unavailable")
+		return
+	}
+	if len(data) == 0 {
+		return
+	}
+	dataMap := assert.Must1(godotenv.UnmarshalBytes(data))
+	for k, v := range dataMap {
+		if k == "" || v == "" {
+			continue
+		}
+		env.MustSet(k, v)
+	}
+	env.Reload()
+}
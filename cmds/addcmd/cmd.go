@@ -0,0 +1,126 @@
+package addcmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/pubgo/funk/v2/errors"
+	"github.com/pubgo/redant"
+	"github.com/yarlson/tap"
+
+	"github.com/pubgo/fastgit/configs"
+	"github.com/pubgo/fastgit/pkg/hunk"
+	"github.com/pubgo/fastgit/pkg/timing"
+	"github.com/pubgo/fastgit/utils"
+)
+
+// New creates the add command.
+func New() *redant.Command {
+	var (
+		patch  bool
+		commit bool
+	)
+
+	return &redant.Command{
+		Use:   "add [pathspec...]",
+		Short: "按 hunk 交互式暂存（git add -p 的 TUI 版）",
+		Long: "逐个 hunk 选择是否暂存：y 暂存、n 跳过、s 拆分、e 在编辑器中修改，结果写入 index。" +
+			"指定 pathspec 且不带 -p 时等同 git add。暂存完成后可直接进入 fastgit commit 流程。",
+		Options: redant.OptionSet{
+			{Flag: "patch", Shorthand: "p", Description: "逐个 hunk 选择暂存（不指定 pathspec 时默认开启）", Value: redant.BoolOf(&patch)},
+			{Flag: "commit", Description: "暂存后直接进入 fastgit commit，不再询问", Value: redant.BoolOf(&commit)},
+		},
+		Handler: func(ctx context.Context, inv *redant.Invocation) error {
+			if !patch && len(inv.Args) > 0 {
+				return gitAdd(ctx, inv.Args)
+			}
+
+			staged, err := Patch(ctx, inv.Args)
+			if err != nil || staged == 0 {
+				return err
+			}
+
+			if !commit {
+				done := timing.Track(ctx, timing.PhaseUI, "continue to commit?")
+				commit = tap.Confirm(ctx, tap.ConfirmOptions{
+					Message:      "继续生成提交信息并提交？",
+					InitialValue: true,
+				})
+				done()
+			}
+			if !commit {
+				return nil
+			}
+			return runSelf(ctx, "commit")
+		},
+	}
+}
+
+// Patch 运行 hunk 选择界面并把选中的部分写入 index，返回暂存的 hunk 数；commit --patch 复用
+func Patch(ctx context.Context, paths []string) (int, error) {
+	root, err := configs.RepoPath()
+	if err != nil {
+		return 0, err
+	}
+
+	diff, err := hunk.Diff(ctx, "", paths...)
+	if err != nil {
+		return 0, err
+	}
+	files, err := hunk.Parse(diff)
+	if err != nil {
+		return 0, err
+	}
+	for _, f := range files {
+		if f.Binary {
+			fmt.Printf("skip binary file %s (use git add)\n", f.Path)
+		}
+	}
+	if len(hunk.Pieces(files)) == 0 {
+		fmt.Println("no unstaged changes")
+		return 0, nil
+	}
+
+	m := newModel(ctx, root, utils.Editor(ctx), files)
+	if _, err := tea.NewProgram(m, tea.WithAltScreen(), tea.WithContext(ctx)).Run(); err != nil {
+		return 0, errors.Wrap(err, "run hunk picker")
+	}
+	if m.aborted {
+		fmt.Println("aborted, index unchanged")
+		return 0, nil
+	}
+
+	selected := m.selected()
+	if len(selected) == 0 {
+		fmt.Println("nothing staged")
+		return 0, nil
+	}
+	if err := hunk.Apply(ctx, root, hunk.BuildPatch(files, selected)); err != nil {
+		return 0, errors.Wrap(err, "stage selected hunks")
+	}
+	fmt.Printf("✓ staged %d hunk(s)\n", len(selected))
+	return len(selected), nil
+}
+
+func gitAdd(ctx context.Context, paths []string) error {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"add", "--"}, paths...)...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// runSelf 以子进程运行当前 fastgit 可执行文件
+func runSelf(ctx context.Context, args ...string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, exe, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
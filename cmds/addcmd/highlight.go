@@ -0,0 +1,111 @@
+package addcmd
+
+import (
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	keywordStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("204"))
+	stringStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("221"))
+	commentStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Italic(true)
+	numberStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("141"))
+)
+
+// 常见语言关键字的并集，只用于着色，不追求精确
+var keywords = map[string]bool{
+	"func": true, "package": true, "import": true, "return": true, "if": true, "else": true,
+	"for": true, "range": true, "switch": true, "case": true, "default": true, "break": true,
+	"continue": true, "go": true, "defer": true, "select": true, "chan": true, "map": true,
+	"struct": true, "interface": true, "type": true, "var": true, "const": true, "nil": true,
+	"true": true, "false": true, "def": true, "class": true, "self": true, "None": true,
+	"True": true, "False": true, "from": true, "as": true, "with": true, "try": true,
+	"except": true, "finally": true, "raise": true, "lambda": true, "yield": true, "async": true,
+	"await": true, "function": true, "let": true, "new": true, "this": true, "null": true,
+	"undefined": true, "export": true, "throw": true, "catch": true, "fn": true, "impl": true,
+	"pub": true, "use": true, "mod": true, "match": true, "mut": true, "while": true,
+	"public": true, "private": true, "static": true, "void": true, "then": true, "fi": true,
+	"do": true, "done": true, "echo": true, "local": true, "end": true, "elif": true,
+}
+
+// commentPrefix 根据扩展名猜测行注释前缀
+func commentPrefix(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".py", ".sh", ".bash", ".zsh", ".rb", ".yaml", ".yml", ".toml", ".pl", ".r", ".mk", ".dockerfile":
+		return "#"
+	case ".sql", ".lua", ".hs":
+		return "--"
+	case ".md", ".txt", ".json", ".csv":
+		return ""
+	}
+	if base := filepath.Base(path); base == "Makefile" || base == "Dockerfile" {
+		return "#"
+	}
+	return "//"
+}
+
+// highlight 对一行代码做轻量的词法着色：关键字、字符串、数字与行注释
+func highlight(text, comment string, base lipgloss.Style) string {
+	var out strings.Builder
+	var plain strings.Builder
+	flush := func() {
+		if plain.Len() > 0 {
+			out.WriteString(base.Render(plain.String()))
+			plain.Reset()
+		}
+	}
+
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case comment != "" && strings.HasPrefix(string(runes[i:]), comment):
+			flush()
+			out.WriteString(commentStyle.Inherit(base).Render(string(runes[i:])))
+			return out.String()
+		case r == '"' || r == '\'' || r == '`':
+			j := i + 1
+			for j < len(runes) && runes[j] != r {
+				if runes[j] == '\\' && r != '`' {
+					j++
+				}
+				j++
+			}
+			if j < len(runes) {
+				j++
+			}
+			flush()
+			out.WriteString(stringStyle.Inherit(base).Render(string(runes[i:min(j, len(runes))])))
+			i = j
+		case unicode.IsLetter(r) || r == '_':
+			j := i
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || runes[j] == '_') {
+				j++
+			}
+			word := string(runes[i:j])
+			if keywords[word] {
+				flush()
+				out.WriteString(keywordStyle.Inherit(base).Render(word))
+			} else {
+				plain.WriteString(word)
+			}
+			i = j
+		case unicode.IsDigit(r):
+			j := i
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.' || runes[j] == 'x' || unicode.Is(unicode.ASCII_Hex_Digit, runes[j])) {
+				j++
+			}
+			flush()
+			out.WriteString(numberStyle.Inherit(base).Render(string(runes[i:j])))
+			i = j
+		default:
+			plain.WriteRune(r)
+			i++
+		}
+	}
+	flush()
+	return out.String()
+}
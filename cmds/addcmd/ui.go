@@ -0,0 +1,327 @@
+package addcmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/pubgo/fastgit/pkg/hunk"
+	"github.com/pubgo/fastgit/utils"
+)
+
+const contextLines = 3
+
+type decision int
+
+const (
+	undecided decision = iota
+	accepted
+	skipped
+)
+
+type item struct {
+	piece    *hunk.Piece
+	decision decision
+}
+
+type editDoneMsg struct {
+	err error
+}
+
+var (
+	titleStyle  = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39"))
+	hunkStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("39"))
+	addStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("114")).Background(lipgloss.Color("22"))
+	delStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("210")).Background(lipgloss.Color("52"))
+	ctxStyle    = lipgloss.NewStyle()
+	helpStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+	statusStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+)
+
+type model struct {
+	ctx      context.Context
+	dir      string
+	editor   string
+	files    []*hunk.File
+	items    []*item
+	cursor   int
+	viewport viewport.Model
+	ready    bool
+	status   string
+	editPath string
+	aborted  bool
+}
+
+func newModel(ctx context.Context, dir, editor string, files []*hunk.File) *model {
+	m := &model{ctx: ctx, dir: dir, editor: editor, files: files}
+	for _, p := range hunk.Pieces(files) {
+		m.items = append(m.items, &item{piece: p})
+	}
+	return m
+}
+
+func (m *model) Init() tea.Cmd { return nil }
+
+func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		height := max(msg.Height-4, 3)
+		if !m.ready {
+			m.viewport = viewport.New(msg.Width, height)
+			m.ready = true
+		} else {
+			m.viewport.Width, m.viewport.Height = msg.Width, height
+		}
+		m.refresh()
+	case editDoneMsg:
+		m.finishEdit(msg.err)
+		if m.cursor >= len(m.items) {
+			return m, tea.Quit
+		}
+		m.refresh()
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	}
+	return m, nil
+}
+
+func (m *model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.status = ""
+	cur := m.items[m.cursor]
+	switch msg.String() {
+	case "ctrl+c", "esc":
+		m.aborted = true
+		return m, tea.Quit
+	case "q", "enter":
+		return m, tea.Quit
+	case "y":
+		cur.decision = accepted
+		return m.advance()
+	case "n":
+		cur.decision = skipped
+		return m.advance()
+	case "a", "d":
+		d := accepted
+		if msg.String() == "d" {
+			d = skipped
+		}
+		for _, it := range m.items[m.cursor:] {
+			if it.piece.File == cur.piece.File && it.decision == undecided {
+				it.decision = d
+			}
+		}
+		cur.decision = d
+		return m.advance()
+	case "s":
+		parts := cur.piece.Split()
+		if parts == nil {
+			m.status = "this hunk cannot be split further"
+			break
+		}
+		split := make([]*item, 0, len(parts))
+		for _, p := range parts {
+			split = append(split, &item{piece: p})
+		}
+		m.items = append(m.items[:m.cursor], append(split, m.items[m.cursor+1:]...)...)
+		m.status = fmt.Sprintf("split into %d hunks", len(parts))
+	case "e":
+		return m, m.startEdit()
+	case "left", "h", "p":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "right", "l", "tab":
+		if m.cursor < len(m.items)-1 {
+			m.cursor++
+		}
+	case "up", "k":
+		m.viewport.ScrollUp(1)
+		return m, nil
+	case "down", "j":
+		m.viewport.ScrollDown(1)
+		return m, nil
+	case "pgup", "ctrl+u":
+		m.viewport.HalfPageUp()
+		return m, nil
+	case "pgdown", "ctrl+d", " ":
+		m.viewport.HalfPageDown()
+		return m, nil
+	}
+	m.refresh()
+	return m, nil
+}
+
+// advance 跳到下一个未决定的 hunk；全部决定后退出
+func (m *model) advance() (tea.Model, tea.Cmd) {
+	for i := m.cursor + 1; i < len(m.items); i++ {
+		if m.items[i].decision == undecided {
+			m.cursor = i
+			m.refresh()
+			return m, nil
+		}
+	}
+	for i := 0; i < m.cursor; i++ {
+		if m.items[i].decision == undecided {
+			m.cursor = i
+			m.refresh()
+			return m, nil
+		}
+	}
+	m.cursor = len(m.items)
+	return m, tea.Quit
+}
+
+// startEdit 把整个 hunk（含已拆分的部分）写入临时文件交给编辑器
+func (m *model) startEdit() tea.Cmd {
+	h := m.items[m.cursor].piece.Hunk
+	f, err := os.CreateTemp("", "fastgit-hunk-*.diff")
+	if err != nil {
+		m.status = err.Error()
+		return nil
+	}
+	defer f.Close()
+
+	fmt.Fprintf(f, "# Manual hunk edit mode — %s\n", m.items[m.cursor].piece.File.Path)
+	fmt.Fprintln(f, "# To drop a '-' line, turn it into context by replacing '-' with ' '.")
+	fmt.Fprintln(f, "# To drop a '+' line, delete it. Lines starting with '#' are removed.")
+	fmt.Fprintln(f, "# Save an empty file to cancel.")
+	for _, l := range h.Lines {
+		fmt.Fprintln(f, l.String())
+	}
+	m.editPath = f.Name()
+
+	args := utils.EditorArgs(m.editor, m.editPath)
+	cmd := exec.Command(args[0], args[1:]...)
+	return tea.ExecProcess(cmd, func(err error) tea.Msg { return editDoneMsg{err: err} })
+}
+
+func (m *model) finishEdit(runErr error) {
+	defer os.Remove(m.editPath)
+	if runErr != nil {
+		m.status = "editor failed: " + runErr.Error()
+		return
+	}
+	data, err := os.ReadFile(m.editPath)
+	if err != nil {
+		m.status = err.Error()
+		return
+	}
+	lines, err := hunk.ParseEdited(string(data))
+	if err != nil {
+		m.status = err.Error()
+		return
+	}
+	if len(lines) == 0 {
+		m.status = "edit cancelled"
+		return
+	}
+
+	cur := m.items[m.cursor].piece
+	edited := *cur.Hunk
+	edited.Lines = lines
+	file := *cur.File
+	file.Hunks = []*hunk.Hunk{&edited}
+	piece := &hunk.Piece{File: &file, Hunk: &edited, End: len(lines)}
+	if err := hunk.Check(m.ctx, m.dir, hunk.BuildPatch([]*hunk.File{&file}, []*hunk.Piece{piece})); err != nil {
+		m.status = "edited hunk does not apply: " + err.Error()
+		return
+	}
+
+	// 编辑结果覆盖原 hunk，其所有拆分部分合并为一个已接受的条目
+	cur.Hunk.Lines = lines
+	first := -1
+	items := make([]*item, 0, len(m.items))
+	for _, it := range m.items {
+		if it.piece.Hunk != cur.Hunk {
+			items = append(items, it)
+			continue
+		}
+		if first < 0 {
+			first = len(items)
+			items = append(items, &item{piece: &hunk.Piece{File: cur.File, Hunk: cur.Hunk, End: len(lines)}, decision: accepted})
+		}
+	}
+	m.items, m.cursor = items, first
+	m.status = "hunk edited"
+	m.advance()
+}
+
+func (m *model) refresh() {
+	if !m.ready || m.cursor >= len(m.items) {
+		return
+	}
+	p := m.items[m.cursor].piece
+	comment := commentPrefix(p.File.Path)
+
+	var b strings.Builder
+	b.WriteString(hunkStyle.Render(fmt.Sprintf("@@ -%d,%d +%d,%d @@ %s", p.Hunk.OldStart, p.Hunk.OldLines, p.Hunk.NewStart, p.Hunk.NewLines, p.Hunk.Section)))
+	b.WriteByte('\n')
+	for _, l := range p.Lines(contextLines) {
+		text := strings.ReplaceAll(l.Text, "\t", "    ")
+		switch l.Op {
+		case '+':
+			b.WriteString(addStyle.Render("+") + highlight(text, comment, addStyle))
+		case '-':
+			b.WriteString(delStyle.Render("-") + highlight(text, comment, delStyle))
+		case '\\':
+			b.WriteString(helpStyle.Render(l.String()))
+		default:
+			b.WriteString(" " + highlight(text, comment, ctxStyle))
+		}
+		b.WriteByte('\n')
+	}
+	m.viewport.SetContent(b.String())
+	m.viewport.GotoTop()
+}
+
+func (m *model) View() string {
+	if !m.ready || m.cursor >= len(m.items) {
+		return ""
+	}
+	cur := m.items[m.cursor]
+	var acc, skip int
+	for _, it := range m.items {
+		switch it.decision {
+		case accepted:
+			acc++
+		case skipped:
+			skip++
+		}
+	}
+
+	mark := "?"
+	switch cur.decision {
+	case accepted:
+		mark = "staged"
+	case skipped:
+		mark = "skipped"
+	}
+	title := fmt.Sprintf("[%d/%d] %s", m.cursor+1, len(m.items), cur.piece.File.Path)
+	if !cur.piece.Whole() {
+		title += " (split)"
+	}
+	header := titleStyle.Render(title) + helpStyle.Render(fmt.Sprintf("  %s · %d staged · %d skipped", mark, acc, skip))
+	help := helpStyle.Render("y stage · n skip · s split · e edit · a/d stage/skip rest of file · ←/→ move · ↑/↓ scroll · q done · esc abort")
+	footer := help
+	if m.status != "" {
+		footer = statusStyle.Render(m.status) + "\n" + help
+	}
+	return header + "\n" + m.viewport.View() + "\n" + footer
+}
+
+// selected 返回用户接受的 hunk
+func (m *model) selected() []*hunk.Piece {
+	var pieces []*hunk.Piece
+	for _, it := range m.items {
+		if it.decision == accepted {
+			pieces = append(pieces, it.piece)
+		}
+	}
+	return pieces
+}
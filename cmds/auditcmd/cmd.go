@@ -0,0 +1,166 @@
+package auditcmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pubgo/redant"
+
+	"github.com/pubgo/fastgit/pkg/execlog"
+	"github.com/pubgo/fastgit/utils"
+)
+
+// New creates the audit command.
+func New() *redant.Command {
+	var (
+		limit   int64
+		all     bool
+		ops     string
+		since   time.Duration
+		jsonOut bool
+	)
+
+	return &redant.Command{
+		Use:   "audit",
+		Short: "查看 fastgit 执行过的提交、推送、打 tag、删除、amend 等变更记录",
+		Long: "fastgit 每次执行会改变历史或引用的 git 命令时，都会向 ~/.config/fastgit/exec.log 追加一行 JSON，" +
+			"记录时间、仓库、命令参数、触发它的 fastgit 命令以及前后的 HEAD，便于回滚和事后排查。" +
+			"默认只列出当前仓库最近 20 条。",
+		Metadata: utils.NoTTYMetadata(),
+		Options: redant.OptionSet{
+			{Flag: "limit", Shorthand: "n", Description: "最多显示的条数，0 表示全部", Value: redant.Int64Of(&limit), Default: "20"},
+			{Flag: "all", Description: "显示所有仓库的记录", Value: redant.BoolOf(&all)},
+			{Flag: "op", Description: "只显示这些操作，逗号分隔，如 commit,amend,push,tag,delete,reset", Value: redant.StringOf(&ops)},
+			{Flag: "since", Description: "只显示这段时间内的记录，如 24h", Value: redant.DurationOf(&since)},
+			{Flag: "json", Description: "以 JSON 输出", Value: redant.BoolOf(&jsonOut)},
+		},
+		Handler: func(ctx context.Context, inv *redant.Invocation) error {
+			entries, err := execlog.Read()
+			if err != nil {
+				return err
+			}
+
+			f := filter{limit: int(limit), ops: splitList(ops)}
+			if since > 0 {
+				f.since = time.Now().Add(-since)
+			}
+			if !all {
+				out, err := exec.CommandContext(ctx, "git", "rev-parse", "--show-toplevel").Output()
+				if err != nil {
+					return fmt.Errorf("not in a git repository, pass --all to list every repository: %w", err)
+				}
+				f.repo = strings.TrimSpace(string(out))
+			}
+			entries = f.apply(entries)
+
+			if jsonOut {
+				enc := json.NewEncoder(inv.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(entries)
+			}
+			if len(entries) == 0 {
+				_, err := fmt.Fprintf(inv.Stdout, "no recorded operations (%s)\n", execlog.Path())
+				return err
+			}
+			render(inv.Stdout, entries, all)
+			return nil
+		},
+	}
+}
+
+type filter struct {
+	repo  string
+	ops   []string
+	since time.Time
+	limit int
+}
+
+// apply 过滤后保留最近的 limit 条，按时间正序返回
+func (f filter) apply(entries []execlog.Entry) []execlog.Entry {
+	var out []execlog.Entry
+	for _, e := range entries {
+		if f.repo != "" && e.Repo != f.repo {
+			continue
+		}
+		if len(f.ops) > 0 && !contains(f.ops, e.Op) {
+			continue
+		}
+		if !f.since.IsZero() && e.Time.Before(f.since) {
+			continue
+		}
+		out = append(out, e)
+	}
+	if f.limit > 0 && len(out) > f.limit {
+		out = out[len(out)-f.limit:]
+	}
+	return out
+}
+
+func render(w io.Writer, entries []execlog.Entry, withRepo bool) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := "TIME\tOP\tHEAD\tCOMMAND"
+	if withRepo {
+		header = "TIME\tREPO\tOP\tHEAD\tCOMMAND"
+	}
+	_, _ = fmt.Fprintln(tw, header)
+	for _, e := range entries {
+		cmd := "git " + strings.Join(e.Args, " ")
+		if e.Error != "" {
+			cmd += "  [failed: " + firstLine(e.Error) + "]"
+		}
+		cols := []string{e.Time.Local().Format(time.DateTime), e.Op, headChange(e), cmd}
+		if withRepo {
+			cols = append([]string{cols[0], e.Repo}, cols[1:]...)
+		}
+		_, _ = fmt.Fprintln(tw, strings.Join(cols, "\t"))
+	}
+	_ = tw.Flush()
+}
+
+// headChange 显示 HEAD 的变化，如 1a2b3c4→5d6e7f8；撤销时 git reset 到箭头左侧即可
+func headChange(e execlog.Entry) string {
+	if e.Before == "" && e.After == "" {
+		return "-"
+	}
+	if e.Before == e.After {
+		return short(e.After)
+	}
+	return short(e.Before) + "→" + short(e.After)
+}
+
+func short(hash string) string {
+	if hash == "" {
+		return "(none)"
+	}
+	return hash[:min(len(hash), 7)]
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return line
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
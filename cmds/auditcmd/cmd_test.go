@@ -0,0 +1,39 @@
+package auditcmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/pubgo/fastgit/pkg/execlog"
+)
+
+func TestFilterApply(t *testing.T) {
+	now := time.Now()
+	entries := []execlog.Entry{
+		{Time: now.Add(-48 * time.Hour), Repo: "/a", Op: "commit"},
+		{Time: now.Add(-time.Hour), Repo: "/b", Op: "push"},
+		{Time: now.Add(-30 * time.Minute), Repo: "/a", Op: "push"},
+		{Time: now.Add(-time.Minute), Repo: "/a", Op: "tag"},
+	}
+
+	assert.Len(t, filter{repo: "/a"}.apply(entries), 3)
+	assert.Equal(t, []execlog.Entry{entries[1], entries[2]}, filter{ops: []string{"push"}}.apply(entries))
+	assert.Equal(t, []execlog.Entry{entries[3]}, filter{repo: "/a", limit: 1}.apply(entries))
+	assert.Len(t, filter{since: now.Add(-2 * time.Hour)}.apply(entries), 3)
+}
+
+func TestRender(t *testing.T) {
+	var out bytes.Buffer
+	render(&out, []execlog.Entry{
+		{Time: time.Now(), Repo: "/a", Op: "amend", Args: []string{"commit", "--amend", "-m", "fix: x"},
+			Before: "1111111aaaa", After: "2222222bbbb"},
+		{Time: time.Now(), Repo: "/a", Op: "push", Args: []string{"push", "origin", "main"}, Error: "rejected\nhint"},
+	}, false)
+
+	assert.Contains(t, out.String(), "1111111→2222222")
+	assert.Contains(t, out.String(), "git push origin main  [failed: rejected]")
+	assert.NotContains(t, out.String(), "REPO")
+}
@@ -0,0 +1,285 @@
+package backportcmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/pubgo/fastgit/pkg/changelog"
+	"github.com/pubgo/fastgit/pkg/gitshell"
+	"github.com/pubgo/fastgit/pkg/tempworktree"
+)
+
+// Pick statuses reported per selected commit.
+const (
+	StatusPlanned  = "planned"
+	StatusPicked   = "picked"
+	StatusConflict = "conflict"
+	StatusApplied  = "applied"
+	StatusEmpty    = "empty"
+)
+
+// Options controls a backport run.
+type Options struct {
+	RepoRoot string
+	// Range is `<from>..<to>`; a single ref means `<ref>..HEAD`.
+	Range string
+	// Onto is the target branch, e.g. release/1.2. A missing local branch is created from origin/<Onto>.
+	Onto string
+	// Types are the conventional commit types (fix, security) or changelog section titles (修复) to pick.
+	Types []string
+	// DryRun lists the selected commits without creating a worktree.
+	DryRun bool
+	Output io.Writer
+}
+
+// Pick is one selected commit and what happened to it.
+type Pick struct {
+	Entry  changelog.ChangelogEntry `json:"entry"`
+	Status string                   `json:"status"`
+	// Conflicts are the unmerged paths when Status is StatusConflict.
+	Conflicts []string `json:"conflicts,omitempty"`
+	// Commit is the new commit on Onto when Status is StatusPicked.
+	Commit string `json:"commit,omitempty"`
+}
+
+// Result describes a backport run.
+type Result struct {
+	Range string `json:"range"`
+	Onto  string `json:"onto"`
+	Picks []Pick `json:"picks"`
+}
+
+// Conflicts returns the picks that could not be applied.
+func (r Result) Conflicts() []Pick {
+	var out []Pick
+	for _, p := range r.Picks {
+		if p.Status == StatusConflict {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// Backport selects the commits of Range whose changelog type matches Types and cherry-picks
+// them (oldest first, with -x) onto Onto inside a temporary detached worktree, so the current
+// worktree, index and HEAD are never touched; Onto is then moved to the result, see
+// updateOnto. A conflicting commit is aborted and reported, and the remaining commits are
+// still tried.
+func Backport(ctx context.Context, opts Options) (res Result, err error) {
+	out := opts.Output
+	if out == nil {
+		out = io.Discard
+	}
+
+	from, to := splitRange(opts.Range)
+	if from == "" {
+		return res, fmt.Errorf("range is required, e.g. v1.2.0..main")
+	}
+	onto := strings.TrimSpace(opts.Onto)
+	if onto == "" {
+		return res, fmt.Errorf("--onto is required, e.g. release/1.2")
+	}
+	res.Range, res.Onto = from+".."+to, onto
+
+	ontoRef, create, err := resolveOnto(ctx, opts.RepoRoot, onto)
+	if err != nil {
+		return res, err
+	}
+	entries, err := selectEntries(ctx, opts.RepoRoot, res.Range, opts.Types)
+	if err != nil {
+		return res, err
+	}
+	// git cherry 以 patch-id 判断目标分支上已有的等价提交（包括此前 backport 过的）
+	applied, err := appliedCommits(ctx, opts.RepoRoot, ontoRef, to, from)
+	if err != nil {
+		return res, err
+	}
+	for _, e := range entries {
+		status := StatusPlanned
+		if applied[e.Hash] {
+			status = StatusApplied
+		}
+		res.Picks = append(res.Picks, Pick{Entry: e, Status: status})
+	}
+	if opts.DryRun || !slices.ContainsFunc(res.Picks, func(p Pick) bool { return p.Status == StatusPlanned }) {
+		return res, nil
+	}
+
+	base, err := gitshell.Run(ctx, opts.RepoRoot, "rev-parse", "--verify", ontoRef+"^{commit}")
+	if err != nil {
+		return res, err
+	}
+	// 以分离 HEAD 检出，onto 在其他 worktree 中检出时同样可用；挑选完成后再更新分支
+	dir, err := tempworktree.Add(ctx, opts.RepoRoot, "fastgit-backport-", base)
+	if err != nil {
+		return res, fmt.Errorf("create backport worktree: %w", err)
+	}
+	defer func() {
+		if cerr := tempworktree.Remove(opts.RepoRoot, dir); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+	_, _ = fmt.Fprintf(out, "backport %s onto %s (worktree %s)\n", res.Range, onto, dir)
+
+	for i := range res.Picks {
+		p := &res.Picks[i]
+		if p.Status != StatusPlanned {
+			continue
+		}
+		pickOne(ctx, dir, p)
+		_, _ = fmt.Fprintln(out, FormatPick(*p))
+	}
+
+	head, err := gitshell.Run(ctx, dir, "rev-parse", "HEAD")
+	if err != nil {
+		return res, err
+	}
+	return res, updateOnto(ctx, opts.RepoRoot, onto, ontoRef, create, base, head)
+}
+
+// updateOnto moves onto from base to head, the tip of the backport worktree. A missing local
+// branch is created at head tracking ontoRef; a branch checked out in another worktree is
+// fast-forwarded there so that worktree does not fall out of sync with it.
+func updateOnto(ctx context.Context, repoRoot, onto, ontoRef string, create bool, base, head string) error {
+	if create {
+		if _, err := gitshell.Run(ctx, repoRoot, "branch", "--no-track", onto, head); err != nil {
+			return fmt.Errorf("create %s: %w", onto, err)
+		}
+		_, err := gitshell.Run(ctx, repoRoot, "branch", "--set-upstream-to", ontoRef, onto)
+		return err
+	}
+	if head == base {
+		return nil
+	}
+	if wt := checkedOutAt(ctx, repoRoot, onto); wt != "" {
+		if _, err := gitshell.Run(ctx, wt, "merge", "--ff-only", "-q", head); err != nil {
+			return fmt.Errorf("%s is checked out at %s and could not be fast-forwarded to %s: %w", onto, wt, gitshell.ShortHash(head), err)
+		}
+		return nil
+	}
+	_, err := gitshell.Run(ctx, repoRoot, "update-ref", "-m", "backport", "refs/heads/"+onto, head, base)
+	return err
+}
+
+// checkedOutAt returns the worktree that has branch checked out, "" when none has.
+func checkedOutAt(ctx context.Context, repoRoot, branch string) string {
+	out, err := gitshell.Run(ctx, repoRoot, "worktree", "list", "--porcelain")
+	if err != nil {
+		return ""
+	}
+	var path string
+	for _, line := range strings.Split(out, "\n") {
+		if p, ok := strings.CutPrefix(line, "worktree "); ok {
+			path = p
+		} else if line == "branch refs/heads/"+branch {
+			return path
+		}
+	}
+	return ""
+}
+
+// pickOne cherry-picks p onto the worktree at dir; a conflict is aborted so the next commit starts clean.
+func pickOne(ctx context.Context, dir string, p *Pick) {
+	if _, err := gitshell.Run(ctx, dir, "cherry-pick", "-x", p.Entry.Hash); err == nil {
+		p.Status = StatusPicked
+		p.Commit, _ = gitshell.Run(ctx, dir, "rev-parse", "HEAD")
+		return
+	}
+
+	unmerged, _ := gitshell.Run(ctx, dir, "diff", "--name-only", "--diff-filter=U")
+	if unmerged == "" {
+		// 改动在目标分支上已存在，cherry-pick 得到空提交
+		p.Status = StatusEmpty
+		_, _ = gitshell.Run(ctx, dir, "cherry-pick", "--skip")
+		return
+	}
+	p.Status = StatusConflict
+	p.Conflicts = strings.Split(unmerged, "\n")
+	_, _ = gitshell.Run(ctx, dir, "cherry-pick", "--abort")
+}
+
+// FormatPick renders one pick as a report line.
+func FormatPick(p Pick) string {
+	line := fmt.Sprintf("%s %s", gitshell.ShortHash(p.Entry.Hash), p.Entry.Header())
+	switch p.Status {
+	case StatusPicked:
+		return "✓ " + line + " -> " + gitshell.ShortHash(p.Commit)
+	case StatusConflict:
+		return "✗ " + line + " (conflict: " + strings.Join(p.Conflicts, ", ") + ")"
+	case StatusApplied:
+		return "= " + line + " (already on target)"
+	case StatusEmpty:
+		return "= " + line + " (no changes left, skipped)"
+	default:
+		return "• " + line
+	}
+}
+
+// selectEntries returns the non-merge commits of revRange matching types, oldest first.
+func selectEntries(ctx context.Context, repoRoot, revRange string, types []string) ([]changelog.ChangelogEntry, error) {
+	entries, _, err := changelog.Collect(ctx, repoRoot, revRange, nil)
+	if err != nil {
+		return nil, fmt.Errorf("read commits %s: %w", revRange, err)
+	}
+	var out []changelog.ChangelogEntry
+	for _, e := range entries {
+		if matchesTypes(e, types) {
+			out = append(out, e)
+		}
+	}
+	slices.Reverse(out)
+	return out, nil
+}
+
+func matchesTypes(e changelog.ChangelogEntry, types []string) bool {
+	for _, t := range types {
+		t = strings.TrimSpace(t)
+		if t == "*" || (e.Type != "" && strings.EqualFold(t, e.Type)) || t == e.Section() {
+			return true
+		}
+	}
+	return false
+}
+
+// appliedCommits returns the commits of from..to that already have an equivalent patch on onto.
+func appliedCommits(ctx context.Context, repoRoot, onto, to, from string) (map[string]bool, error) {
+	out, err := gitshell.Run(ctx, repoRoot, "cherry", onto, to, from)
+	if err != nil {
+		return nil, err
+	}
+	applied := make(map[string]bool)
+	for _, line := range strings.Split(out, "\n") {
+		if sha, ok := strings.CutPrefix(line, "- "); ok {
+			applied[strings.TrimSpace(sha)] = true
+		}
+	}
+	return applied, nil
+}
+
+// resolveOnto returns the ref to compare against and whether the local branch has to be
+// created from origin/<onto>.
+func resolveOnto(ctx context.Context, repoRoot, onto string) (string, bool, error) {
+	if _, err := gitshell.Run(ctx, repoRoot, "rev-parse", "--verify", "--quiet", "refs/heads/"+onto); err == nil {
+		return onto, false, nil
+	}
+	remote := "origin/" + onto
+	if _, err := gitshell.Run(ctx, repoRoot, "rev-parse", "--verify", "--quiet", "refs/remotes/"+remote); err == nil {
+		return remote, true, nil
+	}
+	return "", false, fmt.Errorf("branch %q not found locally or on origin", onto)
+}
+
+func splitRange(r string) (string, string) {
+	r = strings.TrimSpace(r)
+	if r == "" {
+		return "", ""
+	}
+	from, to, ok := strings.Cut(r, "..")
+	if !ok || strings.TrimSpace(to) == "" {
+		to = "HEAD"
+	}
+	return strings.TrimSpace(from), strings.TrimSpace(to)
+}
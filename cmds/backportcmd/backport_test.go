@@ -0,0 +1,104 @@
+package backportcmd
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackport(t *testing.T) {
+	dir := t.TempDir()
+	run := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+		return strings.TrimSpace(string(out))
+	}
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	run("init", "-q", "-b", "main")
+	run("config", "user.email", "t@example.com")
+	run("config", "user.name", "t")
+	run("config", "commit.gpgsign", "false")
+	write("a.txt", "a\n")
+	write("b.txt", "b\n")
+	run("add", ".")
+	run("commit", "-qm", "chore: init")
+	run("tag", "v1.0.0")
+	run("branch", "release/1.0")
+
+	write("a.txt", "a fixed\n")
+	run("commit", "-qam", "fix(a): repair a")
+	write("c.txt", "c\n")
+	run("add", ".")
+	run("commit", "-qm", "feat: add c")
+	write("b.txt", "b main\n")
+	run("commit", "-qam", "security: patch b")
+	write("d.txt", "d\n")
+	run("add", ".")
+	run("commit", "-qm", "fix: add d")
+
+	// release 分支上 b.txt 另有改动，security 提交冲突；d.txt 的修复已单独合入
+	run("checkout", "-q", "release/1.0")
+	write("b.txt", "b release\n")
+	run("commit", "-qam", "fix: release only b")
+	write("d.txt", "d\n")
+	run("add", ".")
+	run("commit", "-qm", "fix: add d")
+	run("checkout", "-q", "main")
+	write("dirty.txt", "wip\n")
+
+	var out strings.Builder
+	plan, err := Backport(context.Background(), Options{RepoRoot: dir, Range: "v1.0.0..main", Onto: "release/1.0", Types: []string{"fix", "security"}, DryRun: true, Output: &out})
+	require.NoError(t, err)
+	require.Equal(t, []string{StatusPlanned, StatusPlanned, StatusApplied}, statuses(plan))
+	require.Equal(t, "repair a", plan.Picks[0].Entry.Subject)
+	require.Empty(t, out.String())
+
+	res, err := Backport(context.Background(), Options{RepoRoot: dir, Range: "v1.0.0", Onto: "release/1.0", Types: []string{"fix", "security"}, Output: &out})
+	require.NoError(t, err)
+	require.Equal(t, "v1.0.0..HEAD", res.Range)
+	require.Equal(t, []string{StatusPicked, StatusConflict, StatusApplied}, statuses(res))
+	require.Equal(t, []string{"b.txt"}, res.Picks[1].Conflicts)
+	require.Len(t, res.Conflicts(), 1)
+	require.Contains(t, out.String(), "✗ ")
+
+	require.Equal(t, "a fixed", run("show", "release/1.0:a.txt"))
+	require.Contains(t, run("log", "-1", "--format=%B", "release/1.0"), "(cherry picked from commit "+plan.Picks[0].Entry.Hash+")")
+	require.Equal(t, "main", run("rev-parse", "--abbrev-ref", "HEAD"))
+	require.FileExists(t, filepath.Join(dir, "dirty.txt"))
+	require.Equal(t, 1, strings.Count(run("worktree", "list"), "\n")+1)
+
+	// release 分支在另一个 worktree 中检出时仍可回合，挑选结果在那里 fast-forward
+	rel := filepath.Join(t.TempDir(), "rel")
+	run("worktree", "add", "-q", rel, "release/1.0")
+	res, err = Backport(context.Background(), Options{RepoRoot: dir, Range: "v1.0.0", Onto: "release/1.0", Types: []string{"feat"}})
+	require.NoError(t, err)
+	require.Equal(t, []string{StatusPicked}, statuses(res))
+	require.Equal(t, res.Picks[0].Commit, run("rev-parse", "release/1.0"))
+	require.FileExists(t, filepath.Join(rel, "c.txt"))
+
+	// 本地没有的分支从 origin/<onto> 创建并跟踪
+	run("remote", "add", "origin", dir)
+	run("update-ref", "refs/remotes/origin/release/2.0", "v1.0.0")
+	res, err = Backport(context.Background(), Options{RepoRoot: dir, Range: "v1.0.0", Onto: "release/2.0", Types: []string{"feat"}})
+	require.NoError(t, err)
+	require.Equal(t, []string{StatusPicked}, statuses(res))
+	require.Equal(t, res.Picks[0].Commit, run("rev-parse", "release/2.0"))
+	require.Equal(t, "origin/release/2.0", run("rev-parse", "--abbrev-ref", "release/2.0@{upstream}"))
+}
+
+func statuses(res Result) []string {
+	var out []string
+	for _, p := range res.Picks {
+		out = append(out, p.Status)
+	}
+	return out
+}
@@ -0,0 +1,112 @@
+package backportcmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/pubgo/redant"
+
+	"github.com/pubgo/fastgit/pkg/gitshell"
+	"github.com/pubgo/fastgit/utils"
+)
+
+func New() *redant.Command {
+	var (
+		revRange string
+		onto     string
+		types    string
+		dryRun   bool
+		jsonOut  bool
+	)
+
+	return &redant.Command{
+		Use:   "backport <from>..<to>",
+		Short: "按 changelog 类型挑选区间内的提交，在临时 worktree 中批量 cherry-pick 到发布分支",
+		Long: "不会改动当前工作区、暂存区和 HEAD；冲突的提交会被跳过并逐个报告，其余提交继续应用，目标分支上已有的等价提交不会重复挑选。" +
+			"示例：fastgit backport v1.2.0..main --onto release/1.2 --types fix,security",
+		Metadata: utils.NoTTYMetadata(),
+		Args: redant.ArgSet{
+			{Name: "range", Description: "提交区间 <from>..<to>，只写 <from> 时为 <from>..HEAD", Value: redant.StringOf(&revRange)},
+		},
+		Options: redant.OptionSet{
+			{Flag: "onto", Description: "目标分支，如 release/1.2；本地没有时从 origin 同名分支创建", Value: redant.StringOf(&onto)},
+			{Flag: "types", Description: "挑选的提交类型或 changelog 段落（逗号分隔，* 为全部）", Value: redant.StringOf(&types), Default: "fix,security"},
+			{Flag: "dry-run", Description: "只列出将要挑选的提交", Value: redant.BoolOf(&dryRun)},
+			{Flag: "json", Description: "以 JSON 输出结果", Value: redant.BoolOf(&jsonOut)},
+		},
+		Handler: func(ctx context.Context, inv *redant.Invocation) error {
+			if revRange == "" {
+				return redant.DefaultHelpFn()(ctx, inv)
+			}
+
+			repoRoot, err := gitshell.Run(ctx, ".", "rev-parse", "--show-toplevel")
+			if err != nil {
+				return fmt.Errorf("not in a git repository: %w", err)
+			}
+
+			// Ctrl+C 只中断 cherry-pick，worktree 仍会清理
+			ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+			defer stop()
+
+			out := inv.Stdout
+			if jsonOut {
+				out = inv.Stderr
+			}
+			res, err := Backport(ctx, Options{
+				RepoRoot: repoRoot,
+				Range:    revRange,
+				Onto:     onto,
+				Types:    strings.Split(types, ","),
+				DryRun:   dryRun,
+				Output:   out,
+			})
+			if err != nil {
+				return err
+			}
+
+			if jsonOut {
+				enc := json.NewEncoder(inv.Stdout)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(res); err != nil {
+					return err
+				}
+			} else {
+				report(inv, res, dryRun)
+			}
+			if conflicts := res.Conflicts(); len(conflicts) > 0 {
+				return fmt.Errorf("%d of %d commits conflicted on %s; cherry-pick them by hand with `git cherry-pick -x <sha>`", len(conflicts), len(res.Picks), res.Onto)
+			}
+			return nil
+		},
+	}
+}
+
+// report 输出挑选结果：dry-run 时列出全部选中提交，否则汇总各状态数量并提示推送
+func report(inv *redant.Invocation, res Result, dryRun bool) {
+	if len(res.Picks) == 0 {
+		_, _ = fmt.Fprintf(inv.Stdout, "no matching commits in %s\n", res.Range)
+		return
+	}
+
+	counts := make(map[string]int)
+	for _, p := range res.Picks {
+		counts[p.Status]++
+		// 实际挑选的提交已在执行时逐条输出
+		if dryRun || p.Status == StatusApplied {
+			_, _ = fmt.Fprintln(inv.Stdout, FormatPick(p))
+		}
+	}
+	if dryRun {
+		_, _ = fmt.Fprintf(inv.Stdout, "%d commits would be picked onto %s\n", counts[StatusPlanned], res.Onto)
+		return
+	}
+	_, _ = fmt.Fprintf(inv.Stdout, "picked %d, conflicts %d, already on %s %d\n",
+		counts[StatusPicked], counts[StatusConflict], res.Onto, counts[StatusApplied]+counts[StatusEmpty])
+	if counts[StatusPicked] > 0 {
+		_, _ = fmt.Fprintf(inv.Stdout, "review and push with: git push origin %s\n", res.Onto)
+	}
+}
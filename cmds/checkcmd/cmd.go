@@ -112,9 +112,9 @@ func newHookCommand() *redant.Command {
 		{
 			Use:   "install",
 			Short: "安装 pre-commit 与 pre-push 钩子",
-			Long:  "pre-commit 运行 `fastgit check run --staged-only`；pre-push 运行 `fastgit check run`。与 lefthook 等工具共存时，请勿覆盖非 fastgit 管理的钩子。",
+			Long:  "pre-commit 运行 `fastgit check run --staged-only`；pre-push 运行 `fastgit check run`。遵循 core.hooksPath；已有钩子改名为 <name>.fastgit-prev 并在检查通过后链式调用，husky 的 .husky/<name> 追加标记块。",
 			Options: redant.OptionSet{
-				{Flag: "force", Description: "覆盖已有非 fastgit 钩子", Value: redant.BoolOf(&force)},
+				{Flag: "force", Description: "替换已有钩子而不链式调用（仍保留备份，卸载时还原）", Value: redant.BoolOf(&force)},
 			},
 			Handler: func(ctx context.Context, inv *redant.Invocation) error {
 				_ = ctx
@@ -123,7 +123,7 @@ func newHookCommand() *redant.Command {
 		},
 		{
 			Use:   "uninstall",
-			Short: "移除 fastgit 管理的 pre-commit / pre-push 钩子",
+			Short: "移除 fastgit 管理的 pre-commit / pre-push 钩子并还原安装前的钩子",
 			Handler: func(ctx context.Context, inv *redant.Invocation) error {
 				_ = ctx
 				return uninstallHooks(inv)
@@ -8,73 +8,135 @@ import (
 	"strings"
 
 	"github.com/pubgo/redant"
+
+	"github.com/pubgo/fastgit/pkg/gitshell"
+)
+
+const (
+	hookBlockBegin = hookMarker + " begin"
+	hookBlockEnd   = hookMarker + " end"
+	// hookBackupSuffix 安装时已有钩子改名为 <name>.fastgit-prev，由 fastgit 钩子链式调用，卸载时还原
+	hookBackupSuffix = ".fastgit-prev"
 )
 
 type hookSpec struct {
-	name   string
-	script string
+	name    string
+	command string
+	script  string
 }
 
 var managedHooks = []hookSpec{
-	{
-		name: "pre-commit",
-		script: `#!/bin/sh
-` + hookMarker + `
-exec fastgit check run --staged-only
-`,
-	},
-	{
-		name: "pre-push",
+	newHookSpec("pre-commit", "fastgit check run --staged-only"),
+	newHookSpec("pre-push", "fastgit check run"),
+}
+
+// newHookSpec 生成钩子脚本：先运行 fastgit 检查，再把参数与 stdin（pre-push 的 ref 列表）交给原有钩子
+func newHookSpec(name, command string) hookSpec {
+	return hookSpec{
+		name:    name,
+		command: command,
 		script: `#!/bin/sh
 ` + hookMarker + `
-exec fastgit check run
+` + command + ` </dev/null || exit $?
+prev="$(dirname "$0")/` + name + hookBackupSuffix + `"
+if [ -x "$prev" ]; then
+	exec "$prev" "$@"
+fi
 `,
-	},
+	}
+}
+
+// hookTarget 是钩子实际生效的位置；husky 的 .husky/_ 由 husky 生成，需写入 .husky/<name> 用户脚本
+type hookTarget struct {
+	dir     string
+	manager string
 }
 
+func (t hookTarget) husky() bool { return t.manager == "husky" }
+
 func installHooks(inv *redant.Invocation, force bool) error {
-	gitDir, err := resolveGitDir()
+	target, err := resolveHookTarget()
 	if err != nil {
-		return fmt.Errorf("not a git repository: %w", err)
+		return err
+	}
+	if target.manager != "" {
+		_, _ = fmt.Fprintf(inv.Stdout, "detected %s, hooks dir: %s\n", target.manager, target.dir)
+	}
+	if err := os.MkdirAll(target.dir, 0o755); err != nil {
+		return fmt.Errorf("create hooks dir: %w", err)
 	}
 
 	for _, spec := range managedHooks {
-		if err := installOneHook(inv, gitDir, spec, force); err != nil {
+		if err := installOneHook(inv, target, spec, force); err != nil {
 			return err
 		}
 	}
+	if target.manager == "lefthook" {
+		_, _ = fmt.Fprintln(inv.Stdout, "note: `lefthook install` rewrites hooks; add the fastgit commands to lefthook.yml to keep them")
+	}
 	return nil
 }
 
-func installOneHook(inv *redant.Invocation, gitDir string, spec hookSpec, force bool) error {
-	hookPath := fmt.Sprintf("%s/hooks/%s", gitDir, spec.name)
-	if data, err := os.ReadFile(hookPath); err == nil {
-		content := string(data)
-		if strings.Contains(content, hookMarker) {
-			_, _ = fmt.Fprintf(inv.Stdout, "%s hook already installed: %s\n", spec.name, hookPath)
-			return nil
+func installOneHook(inv *redant.Invocation, target hookTarget, spec hookSpec, force bool) error {
+	hookPath := filepath.Join(target.dir, spec.name)
+	data, err := os.ReadFile(hookPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	content := string(data)
+	if strings.Contains(content, hookMarker) {
+		_, _ = fmt.Fprintf(inv.Stdout, "%s hook already installed: %s\n", spec.name, hookPath)
+		return nil
+	}
+
+	// husky 用户脚本由 husky 调用，追加标记块即可，无需接管整个文件
+	if target.husky() && content != "" {
+		block := hookBlockBegin + "\n" + spec.command + "\n" + hookBlockEnd + "\n"
+		if !strings.HasSuffix(content, "\n") {
+			content += "\n"
 		}
-		if !force {
-			return fmt.Errorf("%s hook exists and is not fastgit-managed; use --force to overwrite", spec.name)
+		if err := os.WriteFile(hookPath, []byte(content+block), 0o755); err != nil {
+			return fmt.Errorf("write %s hook: %w", spec.name, err)
+		}
+		_, _ = fmt.Fprintf(inv.Stdout, "chained %s hook into %s\n", spec.name, hookPath)
+		return nil
+	}
+
+	if content != "" {
+		if force {
+			_, _ = fmt.Fprintf(inv.Stdout, "replacing %s hook, previous one kept as %s%s\n", spec.name, hookPath, hookBackupSuffix)
+		}
+		if err := os.Rename(hookPath, hookPath+hookBackupSuffix); err != nil {
+			return fmt.Errorf("back up %s hook: %w", spec.name, err)
+		}
+		if force {
+			// 保留备份以便卸载时还原，但取消执行权限使其不再被链式调用
+			if err := os.Chmod(hookPath+hookBackupSuffix, 0o644); err != nil {
+				return err
+			}
 		}
 	}
 
 	if err := os.WriteFile(hookPath, []byte(spec.script), 0o755); err != nil {
 		return fmt.Errorf("write %s hook: %w", spec.name, err)
 	}
+	if content != "" && !force {
+		_, _ = fmt.Fprintf(inv.Stdout, "installed %s hook: %s (chains to existing %s%s)\n", spec.name, hookPath, spec.name, hookBackupSuffix)
+		return nil
+	}
 	_, _ = fmt.Fprintf(inv.Stdout, "installed %s hook: %s\n", spec.name, hookPath)
 	return nil
 }
 
 func uninstallHooks(inv *redant.Invocation) error {
-	gitDir, err := resolveGitDir()
+	target, err := resolveHookTarget()
 	if err != nil {
-		return fmt.Errorf("not a git repository: %w", err)
+		return err
 	}
 
 	removed := 0
 	for _, spec := range managedHooks {
-		ok, err := removeOneHook(inv, gitDir, spec.name)
+		ok, err := removeOneHook(inv, target.dir, spec.name)
 		if err != nil {
 			return err
 		}
@@ -88,8 +150,9 @@ func uninstallHooks(inv *redant.Invocation) error {
 	return nil
 }
 
-func removeOneHook(inv *redant.Invocation, gitDir, name string) (bool, error) {
-	hookPath := fmt.Sprintf("%s/hooks/%s", gitDir, name)
+// removeOneHook 移除 fastgit 钩子并还原安装前的状态：去掉追加的标记块，或删除脚本并恢复备份
+func removeOneHook(inv *redant.Invocation, dir, name string) (bool, error) {
+	hookPath := filepath.Join(dir, name)
 	data, err := os.ReadFile(hookPath)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -97,16 +160,92 @@ func removeOneHook(inv *redant.Invocation, gitDir, name string) (bool, error) {
 		}
 		return false, err
 	}
-	if !strings.Contains(string(data), hookMarker) {
+	content := string(data)
+	if !strings.Contains(content, hookMarker) {
 		return false, nil
 	}
+
+	if strings.Contains(content, hookBlockBegin) {
+		if err := os.WriteFile(hookPath, []byte(stripHookBlock(content)), 0o755); err != nil {
+			return false, fmt.Errorf("update %s hook: %w", name, err)
+		}
+		_, _ = fmt.Fprintf(inv.Stdout, "removed fastgit block from %s hook: %s\n", name, hookPath)
+		return true, nil
+	}
+
 	if err := os.Remove(hookPath); err != nil {
 		return false, fmt.Errorf("remove %s hook: %w", name, err)
 	}
-	_, _ = fmt.Fprintf(inv.Stdout, "removed %s hook: %s\n", name, hookPath)
+	backup := hookPath + hookBackupSuffix
+	if _, err := os.Stat(backup); err != nil {
+		_, _ = fmt.Fprintf(inv.Stdout, "removed %s hook: %s\n", name, hookPath)
+		return true, nil
+	}
+	if err := os.Rename(backup, hookPath); err != nil {
+		return false, fmt.Errorf("restore %s hook: %w", name, err)
+	}
+	if err := os.Chmod(hookPath, 0o755); err != nil {
+		return false, err
+	}
+	_, _ = fmt.Fprintf(inv.Stdout, "removed %s hook and restored the previous one: %s\n", name, hookPath)
 	return true, nil
 }
 
+func stripHookBlock(content string) string {
+	var out []string
+	inBlock := false
+	for _, line := range strings.Split(content, "\n") {
+		switch strings.TrimSpace(line) {
+		case hookBlockBegin:
+			inBlock = true
+			continue
+		case hookBlockEnd:
+			inBlock = false
+			continue
+		}
+		if !inBlock {
+			out = append(out, line)
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// resolveHookTarget 按 core.hooksPath 解析钩子目录，并识别 husky / lefthook
+func resolveHookTarget() (hookTarget, error) {
+	gitDir, err := resolveGitDir()
+	if err != nil {
+		return hookTarget{}, fmt.Errorf("not a git repository: %w", err)
+	}
+	top, _ := gitshell.RunInDir(".", "rev-parse", "--show-toplevel")
+
+	target := hookTarget{dir: filepath.Join(gitDir, "hooks")}
+	if hooksPath, _ := gitshell.RunInDir(".", "config", "--type=path", "--get", "core.hooksPath"); hooksPath != "" {
+		if !filepath.IsAbs(hooksPath) && top != "" {
+			hooksPath = filepath.Join(top, hooksPath)
+		}
+		target = hookTarget{dir: filepath.Clean(hooksPath), manager: "core.hooksPath"}
+		// husky v9 把 core.hooksPath 指向生成目录 .husky/_，用户脚本在 .husky/<name>
+		switch filepath.Base(target.dir) {
+		case "_":
+			if filepath.Base(filepath.Dir(target.dir)) == ".husky" {
+				target = hookTarget{dir: filepath.Dir(target.dir), manager: "husky"}
+			}
+		case ".husky":
+			target.manager = "husky"
+		}
+	}
+
+	if target.manager != "husky" && top != "" {
+		for _, name := range []string{"lefthook.yml", ".lefthook.yml", "lefthook.yaml", ".lefthook.yaml"} {
+			if _, err := os.Stat(filepath.Join(top, name)); err == nil {
+				target.manager = "lefthook"
+				break
+			}
+		}
+	}
+	return target, nil
+}
+
 func resolveGitDir() (string, error) {
 	cmd := exec.Command("git", "rev-parse", "--git-dir")
 	out, err := cmd.Output()
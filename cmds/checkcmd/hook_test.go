@@ -39,3 +39,69 @@ func TestInstallHooks(t *testing.T) {
 	require.NoError(t, err)
 	require.Contains(t, string(prePush), hookMarker)
 }
+
+func TestInstallHooksChainsExisting(t *testing.T) {
+	repo := t.TempDir()
+	require.NoError(t, exec.Command("git", "init", repo).Run())
+	chdir(t, repo)
+
+	hookPath := filepath.Join(repo, ".git", "hooks", "pre-push")
+	original := "#!/bin/sh\ngit lfs pre-push \"$@\"\n"
+	require.NoError(t, os.WriteFile(hookPath, []byte(original), 0o755))
+
+	inv := &redant.Invocation{Stdout: os.Stdout}
+	require.NoError(t, installHooks(inv, false))
+
+	prePush, err := os.ReadFile(hookPath)
+	require.NoError(t, err)
+	require.Contains(t, string(prePush), hookMarker)
+	require.Contains(t, string(prePush), "pre-push"+hookBackupSuffix)
+	backup, err := os.ReadFile(hookPath + hookBackupSuffix)
+	require.NoError(t, err)
+	require.Equal(t, original, string(backup))
+
+	require.NoError(t, uninstallHooks(inv))
+	restored, err := os.ReadFile(hookPath)
+	require.NoError(t, err)
+	require.Equal(t, original, string(restored))
+	_, err = os.Stat(hookPath + hookBackupSuffix)
+	require.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(repo, ".git", "hooks", "pre-commit"))
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestInstallHooksHusky(t *testing.T) {
+	repo := t.TempDir()
+	require.NoError(t, exec.Command("git", "init", repo).Run())
+	require.NoError(t, exec.Command("git", "-C", repo, "config", "core.hooksPath", ".husky/_").Run())
+	chdir(t, repo)
+
+	huskyDir := filepath.Join(repo, ".husky")
+	require.NoError(t, os.MkdirAll(filepath.Join(huskyDir, "_"), 0o755))
+	original := "npm test\n"
+	require.NoError(t, os.WriteFile(filepath.Join(huskyDir, "pre-commit"), []byte(original), 0o755))
+
+	inv := &redant.Invocation{Stdout: os.Stdout}
+	require.NoError(t, installHooks(inv, false))
+
+	preCommit, err := os.ReadFile(filepath.Join(huskyDir, "pre-commit"))
+	require.NoError(t, err)
+	require.Contains(t, string(preCommit), original+hookBlockBegin+"\nfastgit check run --staged-only\n")
+	_, err = os.Stat(filepath.Join(huskyDir, "pre-push"))
+	require.NoError(t, err)
+
+	require.NoError(t, uninstallHooks(inv))
+	restored, err := os.ReadFile(filepath.Join(huskyDir, "pre-commit"))
+	require.NoError(t, err)
+	require.Equal(t, original, string(restored))
+	_, err = os.Stat(filepath.Join(huskyDir, "pre-push"))
+	require.True(t, os.IsNotExist(err))
+}
+
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	origWd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(origWd) })
+}
@@ -0,0 +1,163 @@
+package checkoutcmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pubgo/fastgit/pkg/gitshell"
+)
+
+// Branch is a checkout candidate: a local branch, or a remote-tracking branch
+// without a local counterpart.
+type Branch struct {
+	// Name is the local branch name, e.g. feat/login.
+	Name string
+	// Remote is the remote-tracking ref for remote-only branches, e.g. origin/feat/login.
+	Remote  string
+	Current bool
+	// Date and Subject describe the last commit.
+	Date    string
+	Subject string
+}
+
+// Ref is what the picker shows and previews: the local name or the remote ref.
+func (b Branch) Ref() string {
+	if b.Remote != "" {
+		return b.Remote
+	}
+	return b.Name
+}
+
+const branchFormat = "%(HEAD)%09%(refname)%09%(committerdate:relative)%09%(subject)"
+
+// ListBranches returns local branches and remote-only branches of the repository
+// at dir, most recently committed first.
+func ListBranches(ctx context.Context, dir string) ([]Branch, error) {
+	out, err := gitshell.Run(ctx, dir, "for-each-ref", "--sort=-committerdate", "--format="+branchFormat, "refs/heads", "refs/remotes")
+	if err != nil {
+		return nil, err
+	}
+	return parseBranches(out), nil
+}
+
+// parseBranches 解析 for-each-ref 输出：本地已有同名分支的远端分支、远端 HEAD 不列出，多个远端同名时保留最近的一个
+func parseBranches(output string) []Branch {
+	type ref struct {
+		head, name, date, subject string
+	}
+	var refs []ref
+	local := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.SplitN(line, "\t", 4)
+		if len(parts) < 4 {
+			continue
+		}
+		r := ref{head: parts[0], name: parts[1], date: parts[2], subject: parts[3]}
+		if name, ok := strings.CutPrefix(r.name, "refs/heads/"); ok {
+			local[name] = true
+		}
+		refs = append(refs, r)
+	}
+
+	var branches []Branch
+	seen := make(map[string]bool)
+	for _, r := range refs {
+		b := Branch{Date: r.date, Subject: r.subject}
+		if name, ok := strings.CutPrefix(r.name, "refs/heads/"); ok {
+			b.Name, b.Current = name, r.head == "*"
+		} else {
+			remoteRef := strings.TrimPrefix(r.name, "refs/remotes/")
+			_, name, ok := strings.Cut(remoteRef, "/")
+			if !ok || name == "HEAD" || local[name] || seen[name] {
+				continue
+			}
+			b.Name, b.Remote = name, remoteRef
+		}
+		seen[b.Name] = true
+		branches = append(branches, b)
+	}
+	return branches
+}
+
+// formatBranches 每行以 ref 开头，fzf 预览用 {1} 取到它
+func formatBranches(branches []Branch) string {
+	width := 0
+	for _, b := range branches {
+		width = max(width, len(b.Ref()))
+	}
+	var sb strings.Builder
+	for _, b := range branches {
+		marker := " "
+		switch {
+		case b.Current:
+			marker = "*"
+		case b.Remote != "":
+			marker = "⇣"
+		}
+		_, _ = fmt.Fprintf(&sb, "%-*s %s %-16s %s\n", width, b.Ref(), marker, b.Date, b.Subject)
+	}
+	return sb.String()
+}
+
+// findBranch 按本地名或远端 ref 精确查找
+func findBranch(branches []Branch, ref string) (Branch, bool) {
+	for _, b := range branches {
+		if b.Ref() == ref || b.Name == ref {
+			return b, true
+		}
+	}
+	return Branch{}, false
+}
+
+// Switch checks b out in dir. A remote-only branch gets a local tracking branch.
+// With autostash, uncommitted changes (including untracked files) are stashed
+// first and re-applied on the target branch; if they do not apply cleanly they
+// stay in the stash and an error says so.
+func Switch(ctx context.Context, dir string, b Branch, autostash bool, out io.Writer) error {
+	if b.Current {
+		_, _ = fmt.Fprintf(out, "already on %s\n", b.Name)
+		return nil
+	}
+
+	status, err := gitshell.Run(ctx, dir, "status", "--porcelain")
+	if err != nil {
+		return err
+	}
+	stashed := false
+	if status != "" && autostash {
+		from, _ := gitshell.Run(ctx, dir, "rev-parse", "--abbrev-ref", "HEAD")
+		if _, err := gitshell.Run(ctx, dir, "stash", "push", "--include-untracked", "-m", "fastgit checkout: autostash from "+from); err != nil {
+			return fmt.Errorf("autostash: %w", err)
+		}
+		stashed = true
+		_, _ = fmt.Fprintln(out, "stashed local changes")
+	}
+
+	args := []string{"checkout", b.Name}
+	if b.Remote != "" {
+		args = []string{"checkout", "--track", b.Remote}
+	}
+	if _, err := gitshell.Run(ctx, dir, args...); err != nil {
+		if stashed {
+			if _, perr := gitshell.Run(ctx, dir, "stash", "pop"); perr != nil {
+				return fmt.Errorf("%w; local changes are kept in the stash: %v", err, perr)
+			}
+		}
+		return err
+	}
+	if b.Remote != "" {
+		_, _ = fmt.Fprintf(out, "switched to new branch %s tracking %s\n", b.Name, b.Remote)
+	} else {
+		_, _ = fmt.Fprintf(out, "switched to %s\n", b.Name)
+	}
+
+	if stashed {
+		if _, err := gitshell.Run(ctx, dir, "stash", "pop"); err != nil {
+			return fmt.Errorf("local changes do not apply cleanly on %s, resolve the conflicts and run `git stash drop`: %w", b.Name, err)
+		}
+		_, _ = fmt.Fprintln(out, "restored local changes")
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package checkoutcmd
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pubgo/fastgit/pkg/gitshell"
+)
+
+func TestParseBranches(t *testing.T) {
+	output := "" +
+		"*\trefs/heads/main\t2 hours ago\tinit\n" +
+		" \trefs/remotes/origin/HEAD\t2 hours ago\tinit\n" +
+		" \trefs/remotes/origin/main\t2 hours ago\tinit\n" +
+		" \trefs/remotes/origin/feat/login\t1 day ago\tadd login\n" +
+		" \trefs/remotes/upstream/feat/login\t2 days ago\told login\n" +
+		" \trefs/heads/fix\t3 days ago\tfix bug\n"
+
+	branches := parseBranches(output)
+	require.Equal(t, []Branch{
+		{Name: "main", Current: true, Date: "2 hours ago", Subject: "init"},
+		{Name: "feat/login", Remote: "origin/feat/login", Date: "1 day ago", Subject: "add login"},
+		{Name: "fix", Date: "3 days ago", Subject: "fix bug"},
+	}, branches)
+
+	b, ok := findBranch(branches, "feat/login")
+	require.True(t, ok)
+	require.Equal(t, "origin/feat/login", b.Ref())
+	_, ok = findBranch(branches, "feat")
+	require.False(t, ok)
+}
+
+func TestSwitchTracksRemoteBranchWithAutostash(t *testing.T) {
+	t.Setenv("FASTGIT_EXEC_LOG", "0")
+	root := t.TempDir()
+	remote := filepath.Join(root, "origin.git")
+	dir := filepath.Join(root, "repo")
+	other := filepath.Join(root, "other")
+	run := func(dir string, args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	run(root, "init", "-q", "--bare", "-b", "main", remote)
+	run(root, "init", "-q", "-b", "main", dir)
+	run(dir, "config", "user.email", "t@example.com")
+	run(dir, "config", "user.name", "t")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a\n"), 0o644))
+	run(dir, "add", ".")
+	run(dir, "commit", "-qm", "init")
+	run(dir, "remote", "add", "origin", remote)
+	run(dir, "push", "-q", "origin", "main")
+
+	run(root, "clone", "-q", remote, other)
+	run(other, "config", "user.email", "t@example.com")
+	run(other, "config", "user.name", "t")
+	run(other, "checkout", "-qb", "feat/login")
+	require.NoError(t, os.WriteFile(filepath.Join(other, "login.txt"), []byte("login\n"), 0o644))
+	run(other, "add", ".")
+	run(other, "commit", "-qm", "add login")
+	run(other, "push", "-q", "origin", "feat/login")
+	run(dir, "fetch", "-q")
+
+	// 已跟踪文件的改动与未跟踪文件都应随切换带过去
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("changed\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new.txt"), []byte("new\n"), 0o644))
+
+	ctx := context.Background()
+	branches, err := ListBranches(ctx, dir)
+	require.NoError(t, err)
+	b, ok := findBranch(branches, "feat/login")
+	require.True(t, ok)
+	require.Equal(t, "origin/feat/login", b.Remote)
+
+	require.NoError(t, Switch(ctx, dir, b, true, io.Discard))
+	head, err := gitshell.Run(ctx, dir, "rev-parse", "--abbrev-ref", "HEAD@{upstream}")
+	require.NoError(t, err)
+	require.Equal(t, "origin/feat/login", head)
+	require.FileExists(t, filepath.Join(dir, "login.txt"))
+	data, err := os.ReadFile(filepath.Join(dir, "a.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "changed\n", string(data))
+	require.FileExists(t, filepath.Join(dir, "new.txt"))
+	stashes, err := gitshell.Run(ctx, dir, "stash", "list")
+	require.NoError(t, err)
+	require.Empty(t, stashes)
+
+	branches, err = ListBranches(ctx, dir)
+	require.NoError(t, err)
+	b, ok = findBranch(branches, "feat/login")
+	require.True(t, ok)
+	require.True(t, b.Current)
+	require.Empty(t, b.Remote)
+}
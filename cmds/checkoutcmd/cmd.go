@@ -0,0 +1,74 @@
+package checkoutcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pubgo/redant"
+
+	"github.com/pubgo/fastgit/configs"
+	"github.com/pubgo/fastgit/pkg/gitshell"
+	"github.com/pubgo/fastgit/utils/fzfutil"
+)
+
+// previewCommand 预览选中分支最近的提交
+const previewCommand = "git log --oneline --graph --color=always -n 30 {1}"
+
+func New() *redant.Command {
+	var (
+		query       string
+		fetch       bool
+		noAutostash bool
+	)
+
+	return &redant.Command{
+		Use:   "checkout [query]",
+		Short: "模糊选择本地或远端分支并切换，远端分支自动建立跟踪分支，工作区有改动时自动 stash",
+		Long: "列出本地分支与只存在于远端的分支（按最近提交时间排序），右侧预览最近的提交。" +
+			"query 恰好是分支名时直接切换，否则作为 fzf 的初始搜索词。示例：fastgit checkout --fetch feat/",
+		Args: redant.ArgSet{
+			{Name: "query", Description: "分支名或初始搜索词", Value: redant.StringOf(&query)},
+		},
+		Options: redant.OptionSet{
+			{Flag: "fetch", Description: "列出前先 git fetch --prune 更新远端分支", Value: redant.BoolOf(&fetch)},
+			{Flag: "no-autostash", Description: "工作区有改动时不自动 stash，交给 git checkout 处理", Value: redant.BoolOf(&noAutostash)},
+		},
+		Handler: func(ctx context.Context, inv *redant.Invocation) error {
+			repoRoot, err := configs.RepoPath()
+			if err != nil {
+				return err
+			}
+			if fetch {
+				if _, err := gitshell.Run(ctx, repoRoot, "fetch", "--prune"); err != nil {
+					return err
+				}
+			}
+
+			branches, err := ListBranches(ctx, repoRoot)
+			if err != nil {
+				return err
+			}
+			if len(branches) == 0 {
+				return errors.New("no branches to check out")
+			}
+
+			b, ok := findBranch(branches, strings.TrimSpace(query))
+			if !ok {
+				line, err := fzfutil.PickPreview(ctx, strings.NewReader(formatBranches(branches)), "checkout: ", query, previewCommand)
+				if err != nil {
+					return err
+				}
+				fields := strings.Fields(line)
+				if len(fields) == 0 {
+					return nil
+				}
+				if b, ok = findBranch(branches, fields[0]); !ok {
+					return fmt.Errorf("unknown branch %q", fields[0])
+				}
+			}
+			return Switch(ctx, repoRoot, b, !noAutostash, inv.Stdout)
+		},
+	}
+}
@@ -37,10 +37,10 @@ const draftPromptTemplate = `你是当前仓库的 Changelog 维护助手。
 --- .version/changelog/README.md ---
 %s
 
---- git diff %s --stat ---
+--- git %s ---
 %s
 
---- git diff %s --name-only ---
+--- git %s ---
 %s
 
 完成后请仅输出简短自检：
@@ -56,6 +56,7 @@ type draftPromptData struct {
 	ReadmeContent     string
 	DiffStat          string
 	DiffNames         string
+	Pathspecs         []string
 }
 
 func renderDraftPrompt(data draftPromptData) string {
@@ -66,9 +67,9 @@ func renderDraftPrompt(data draftPromptData) string {
 		data.Version,
 		data.UnreleasedContent,
 		data.ReadmeContent,
-		data.BaseRef,
+		strings.Join(diffArgs(data.BaseRef, "--stat", data.Pathspecs), " "),
 		data.DiffStat,
-		data.BaseRef,
+		strings.Join(diffArgs(data.BaseRef, "--name-only", data.Pathspecs), " "),
 		data.DiffNames,
 	))
 }
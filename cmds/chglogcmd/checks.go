@@ -0,0 +1,29 @@
+package chglogcmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pubgo/dix/v2"
+	"github.com/pubgo/dix/v2/dixcontext"
+	"github.com/pubgo/fastgit/pkg/tagcheck"
+	"github.com/pubgo/redant"
+)
+
+type checksParams struct {
+	TagCfg []*tagcheck.Config
+}
+
+// runReleaseChecks 落版前运行 config 中 tag.checks 的命令，任一失败即中止，不写入任何文件
+func runReleaseChecks(ctx context.Context, inv *redant.Invocation, repoRoot string) error {
+	di := dixcontext.GetOrNil(ctx)
+	if di == nil {
+		return nil
+	}
+	params := dix.Inject(di, checksParams{})
+
+	if err := tagcheck.Run(ctx, repoRoot, tagcheck.Checks(params.TagCfg), inv.Stderr); err != nil {
+		return fmt.Errorf("%w\nhint: fix the failure before releasing, or use --skip-checks to bypass", err)
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/pubgo/fastgit/pkg/copilotperm"
+	"github.com/pubgo/fastgit/utils"
 	"github.com/pubgo/redant"
 )
 
@@ -20,7 +21,11 @@ func NewCommand() *redant.Command {
 	root.Children = []*redant.Command{
 		newInitCommand(),
 		newDraftCommand(),
+		newGenerateCommand(),
+		newAddCommand(),
+		newBuildCommand(),
 		newReleaseCommand(),
+		newPublishCommand(),
 	}
 
 	return root
@@ -78,6 +83,7 @@ func newDraftCommand() *redant.Command {
 		baseRef         string
 		printPrompt     bool
 		enrich          bool
+		pathFilter      string
 		cliPath         string
 		logLevel        string
 		githubToken     string
@@ -97,6 +103,7 @@ func newDraftCommand() *redant.Command {
 			{Flag: "base", Description: "diff 基线（默认自动探测）", Value: redant.StringOf(&baseRef)},
 			{Flag: "print-prompt", Description: "只打印最终 prompt，不调用 Copilot", Value: redant.BoolOf(&printPrompt), Default: "false"},
 			{Flag: "enrich", Description: "用规则引擎预填 影响范围/验证建议/回滚建议", Value: redant.BoolOf(&enrich), Default: "false"},
+			{Flag: "path", Description: "只统计指定路径的改动，逗号分隔；可填写 .fastgit/modules.yaml 中的模块名", Value: redant.StringOf(&pathFilter)},
 			{Flag: "copilot-cli-path", Description: "Copilot CLI 可执行路径（可选）", Value: redant.StringOf(&cliPath)},
 			{Flag: "copilot-log-level", Description: "Copilot CLI 日志级别", Value: redant.StringOf(&logLevel), Default: "error"},
 			{Flag: "copilot-token", Description: "GitHub Token（可选）", Value: redant.StringOf(&githubToken), Envs: []string{"GITHUB_TOKEN"}},
@@ -112,6 +119,9 @@ func newDraftCommand() *redant.Command {
 			if err != nil {
 				return err
 			}
+			if err := checkSparse(ctx, repoRoot, inv.Stdout); err != nil {
+				return err
+			}
 
 			result, err := ensureChangelogScaffold(repoRoot, scaffoldOptions{
 				Version:                defaultInitialVersion,
@@ -124,14 +134,20 @@ func newDraftCommand() *redant.Command {
 				_, _ = fmt.Fprintf(inv.Stdout, "created: %s\n", file)
 			}
 
-			prompt, detectedBase, err := buildDraftPrompt(ctx, repoRoot, strings.TrimSpace(baseRef))
+			pathspecs, err := resolveDraftPathspecs(repoRoot, pathFilter)
+			if err != nil {
+				return err
+			}
+
+			prompt, detectedBase, err := buildDraftPrompt(ctx, repoRoot, strings.TrimSpace(baseRef), pathspecs)
 			if err != nil {
 				return err
 			}
+			prompt = appendDraftTicket(prompt, lookupDraftTicket(ctx, repoRoot, inv.Stdout))
 			_, _ = fmt.Fprintf(inv.Stdout, "repo: %s\nbase: %s\n", repoRoot, detectedBase)
 
 			if enrich {
-				diffNames, err := gitOutput(ctx, repoRoot, "diff", detectedBase, "--name-only")
+				diffNames, err := gitOutput(ctx, repoRoot, diffArgs(detectedBase, "--name-only", pathspecs)...)
 				if err != nil {
 					return err
 				}
@@ -183,12 +199,16 @@ func newReleaseCommand() *redant.Command {
 		dryRun        bool
 		skipValidate  bool
 		skipBumpCheck bool
+		skipNotify    bool
+		skipChecks    bool
+		gh            githubOutputs
 	)
 
 	return &redant.Command{
-		Use:   "release",
-		Short: "将 Unreleased.md 落版为版本文件并重建模板",
-		Options: redant.OptionSet{
+		Use:      "release",
+		Short:    "将 Unreleased.md 落版为版本文件并重建模板",
+		Metadata: utils.NoTTYMetadata(),
+		Options: append(redant.OptionSet{
 			{Flag: "repo", Description: "目标仓库目录（默认当前目录）", Value: redant.StringOf(&repoPath)},
 			{Flag: "version", Description: "发布版本号（默认读取 .version/VERSION）", Value: redant.StringOf(&version)},
 			{Flag: "next-version", Description: "发布后写回 .version/VERSION 的下一个版本号", Value: redant.StringOf(&nextVersion)},
@@ -196,13 +216,29 @@ func newReleaseCommand() *redant.Command {
 			{Flag: "dry-run", Description: "仅预览将要改动的文件，不写入磁盘", Value: redant.BoolOf(&dryRun), Default: "false"},
 			{Flag: "skip-validate", Description: "跳过 Unreleased 完整性校验（影响/验证/回滚）", Value: redant.BoolOf(&skipValidate), Default: "false"},
 			{Flag: "skip-bump-check", Description: "跳过 bump 与变更类型一致性校验", Value: redant.BoolOf(&skipBumpCheck), Default: "false"},
-		},
+			{Flag: "skip-notify", Description: "不推送 config 中 notify 配置的发布通知", Value: redant.BoolOf(&skipNotify), Default: "false"},
+			{Flag: "skip-checks", Description: "跳过 config 中 tag.checks 配置的发布前检查", Value: redant.BoolOf(&skipChecks), Default: "false"},
+		}, gh.options()...),
 		Handler: func(ctx context.Context, inv *redant.Invocation) error {
-			_ = ctx
 			repoRoot, err := resolveRepoRoot(strings.TrimSpace(repoPath))
 			if err != nil {
 				return err
 			}
+			if err := checkSparse(ctx, repoRoot, inv.Stdout); err != nil {
+				return err
+			}
+			if !dryRun && !skipChecks {
+				if err := runReleaseChecks(ctx, inv, repoRoot); err != nil {
+					return err
+				}
+			}
+			layout, err := sectionLayout(ctx)
+			if err != nil {
+				return err
+			}
+			if err := buildPendingFragments(inv, repoRoot, layout, dryRun); err != nil {
+				return err
+			}
 
 			result, err := releaseChangelog(repoRoot, releaseOptions{
 				Version:       strings.TrimSpace(version),
@@ -233,6 +269,12 @@ func newReleaseCommand() *redant.Command {
 			if result.NextVersion != "" {
 				_, _ = fmt.Fprintf(inv.Stdout, "next version: %s\n", result.NextVersion)
 			}
+			if !dryRun && !skipNotify {
+				notifyRelease(ctx, inv, repoRoot, result)
+			}
+			if gh.enabled() {
+				return gh.publish(ctx, repoRoot, result.Content, inv.Stdout)
+			}
 			return nil
 		},
 	}
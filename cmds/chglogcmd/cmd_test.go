@@ -2,11 +2,17 @@ package chglogcmd
 
 import (
 	"context"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/pubgo/redant"
+
+	"github.com/pubgo/fastgit/pkg/changelog"
+	"github.com/pubgo/fastgit/pkg/ticket"
 )
 
 func TestEnsureChangelogScaffoldCreatesTemplates(t *testing.T) {
@@ -128,7 +134,7 @@ func TestBuildDraftPromptIncludesBaseAndDiff(t *testing.T) {
 		t.Fatalf("rewrite README: %v", err)
 	}
 
-	prompt, base, err := buildDraftPrompt(context.Background(), repo, "main")
+	prompt, base, err := buildDraftPrompt(context.Background(), repo, "main", nil)
 	if err != nil {
 		t.Fatalf("buildDraftPrompt() error = %v", err)
 	}
@@ -146,6 +152,50 @@ func TestBuildDraftPromptIncludesBaseAndDiff(t *testing.T) {
 	}
 }
 
+func TestBuildDraftPromptFiltersByModulePath(t *testing.T) {
+	repo := t.TempDir()
+	runGit(t, repo, "init")
+	runGit(t, repo, "config", "user.email", "test@example.com")
+	runGit(t, repo, "config", "user.name", "tester")
+	for _, dir := range []string{"services/api", "services/web", ".fastgit"} {
+		if err := os.MkdirAll(filepath.Join(repo, dir), 0o755); err != nil {
+			t.Fatalf("mkdir %s: %v", dir, err)
+		}
+	}
+	modules := "modules:\n  - name: api\n    paths: [services/api]\n"
+	if err := os.WriteFile(filepath.Join(repo, ".fastgit", "modules.yaml"), []byte(modules), 0o644); err != nil {
+		t.Fatalf("write modules.yaml: %v", err)
+	}
+	runGit(t, repo, "add", ".")
+	runGit(t, repo, "commit", "--allow-empty", "-m", "feat: init")
+	runGit(t, repo, "branch", "-M", "main")
+
+	if _, err := ensureChangelogScaffold(repo, scaffoldOptions{Version: "v0.1.0", CreateVersionIfMissing: true}); err != nil {
+		t.Fatalf("ensureChangelogScaffold() error = %v", err)
+	}
+	for _, file := range []string{"services/api/main.go", "services/web/main.go"} {
+		if err := os.WriteFile(filepath.Join(repo, file), []byte("package main\n"), 0o644); err != nil {
+			t.Fatalf("write %s: %v", file, err)
+		}
+	}
+	runGit(t, repo, "add", "-N", ".")
+
+	specs, err := resolveDraftPathspecs(repo, "api")
+	if err != nil {
+		t.Fatalf("resolveDraftPathspecs() error = %v", err)
+	}
+	prompt, _, err := buildDraftPrompt(context.Background(), repo, "main", specs)
+	if err != nil {
+		t.Fatalf("buildDraftPrompt() error = %v", err)
+	}
+	if !strings.Contains(prompt, "git diff main --name-only -- services/api") {
+		t.Fatalf("prompt missing path filter heading: %s", prompt)
+	}
+	if !strings.Contains(prompt, "services/api/main.go") || strings.Contains(prompt, "services/web/main.go") {
+		t.Fatalf("prompt not filtered to module paths: %s", prompt)
+	}
+}
+
 func assertFileContains(t *testing.T, path, want string) {
 	t.Helper()
 	content, err := os.ReadFile(path)
@@ -165,3 +215,91 @@ func runGit(t *testing.T, repo string, args ...string) {
 		t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, string(out))
 	}
 }
+
+func TestAppendDraftTicket(t *testing.T) {
+	if got := appendDraftTicket("prompt\n", nil); got != "prompt\n" {
+		t.Fatalf("expected prompt unchanged, got %q", got)
+	}
+	got := appendDraftTicket("prompt\n", &ticket.Ticket{Key: "ABC-1", Title: "Fix login"})
+	if !strings.Contains(got, "关联工单：ABC-1 Fix login") || !strings.Contains(got, "(ABC-1)") {
+		t.Fatalf("prompt missing ticket context: %s", got)
+	}
+}
+
+func TestGenerateEntriesWritesUnreleased(t *testing.T) {
+	repo := t.TempDir()
+	runGit(t, repo, "init")
+	runGit(t, repo, "config", "user.email", "test@example.com")
+	runGit(t, repo, "config", "user.name", "tester")
+	runGit(t, repo, "commit", "--allow-empty", "-m", "feat: old feature")
+	runGit(t, repo, "tag", "v0.1.0")
+	runGit(t, repo, "commit", "--allow-empty", "-m", "fix: crash on start")
+
+	result, err := generateEntries(context.Background(), repo, generateOptions{})
+	if err != nil {
+		t.Fatalf("generateEntries() error = %v", err)
+	}
+	if result.Range != "v0.1.0..HEAD" || result.Stats.Total != 1 {
+		t.Fatalf("unexpected range/stats: %s %+v", result.Range, result.Stats)
+	}
+
+	if _, err := ensureChangelogScaffold(repo, scaffoldOptions{Version: "v0.1.0", CreateVersionIfMissing: true}); err != nil {
+		t.Fatalf("ensureChangelogScaffold() error = %v", err)
+	}
+	paths := buildPaths(repo)
+	if err := writeGeneratedSections(paths.UnreleasedFile, result); err != nil {
+		t.Fatalf("writeGeneratedSections() error = %v", err)
+	}
+	assertFileContains(t, paths.UnreleasedFile, "- crash on start")
+	assertFileContains(t, paths.UnreleasedFile, "## 影响范围")
+}
+
+func TestBuildPendingFragmentsAppendsToUnreleased(t *testing.T) {
+	repo := t.TempDir()
+	paths := buildPaths(repo)
+	if _, err := ensureChangelogScaffold(repo, scaffoldOptions{Version: "v0.4.0", CreateVersionIfMissing: true}); err != nil {
+		t.Fatalf("ensureChangelogScaffold() error = %v", err)
+	}
+	if err := os.WriteFile(paths.UnreleasedFile, []byte("# [Unreleased]\n\n## 新增\n\n- 已有条目\n\n## 影响范围\n\n- CLI\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(repo, changelog.FragmentDir)
+	for typ, text := range map[string]string{"feat": "片段新增", "fix": "片段修复", "chore": "片段杂项"} {
+		if _, err := changelog.WriteFragment(dir, typ, text, typ); err != nil {
+			t.Fatal(err)
+		}
+	}
+	layout, err := changelog.NewLayout([]changelog.SectionRule{{Hidden: true, Types: []string{"chore"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inv := &redant.Invocation{Stdout: io.Discard}
+	if err := buildPendingFragments(inv, repo, layout, true); err != nil {
+		t.Fatal(err)
+	}
+	if fragments, _ := changelog.ReadFragments(dir); len(fragments) != 3 {
+		t.Fatalf("dry run must keep fragments, got %d", len(fragments))
+	}
+
+	if err := buildPendingFragments(inv, repo, layout, false); err != nil {
+		t.Fatal(err)
+	}
+	assertFileContains(t, paths.UnreleasedFile, "## 新增\n\n- 已有条目\n- 片段新增")
+	assertFileContains(t, paths.UnreleasedFile, "## 修复\n\n- 片段修复")
+	assertFileContains(t, paths.UnreleasedFile, "## 影响范围\n\n- CLI")
+	if fragments, _ := changelog.ReadFragments(dir); len(fragments) != 1 || fragments[0].Type != "chore" {
+		t.Fatalf("built fragments must be removed and hidden ones kept, got %+v", fragments)
+	}
+}
+
+func TestFoldSectionsMergesCustomSectionsIntoChanged(t *testing.T) {
+	layout, err := changelog.NewLayout([]changelog.SectionRule{{Title: "安全", Types: []string{"sec"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := foldSections(layout, map[string]string{"安全": "- 修复越权", "新增": "- a", "变更": "- b", "修复": "暂无"})
+	if got["变更"] != "- b\n- 修复越权" || got["新增"] != "- a" || len(got) != len(standardSections) {
+		t.Fatalf("unexpected sections: %+v", got)
+	}
+}
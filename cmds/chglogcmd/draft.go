@@ -9,6 +9,7 @@ import (
 
 	"github.com/pubgo/fastgit/pkg/aiprovider"
 	"github.com/pubgo/fastgit/pkg/copilotperm"
+	"github.com/pubgo/fastgit/pkg/repoconfig"
 	"github.com/pubgo/redant"
 )
 
@@ -25,14 +26,14 @@ type draftCopilotOptions struct {
 	PermissionMode  string
 }
 
-func buildDraftPrompt(ctx context.Context, repoRoot, requestedBase string) (string, string, error) {
+func buildDraftPrompt(ctx context.Context, repoRoot, requestedBase string, pathspecs []string) (string, string, error) {
 	paths := buildPaths(repoRoot)
 	baseRef, err := detectBaseRef(ctx, repoRoot, requestedBase)
 	if err != nil {
 		return "", "", err
 	}
 
-	versionContent, err := os.ReadFile(paths.VersionFile)
+	versionContent, err := paths.Version.Current()
 	if err != nil {
 		return "", "", fmt.Errorf("read VERSION: %w", err)
 	}
@@ -45,11 +46,11 @@ func buildDraftPrompt(ctx context.Context, repoRoot, requestedBase string) (stri
 		return "", "", fmt.Errorf("read changelog README.md: %w", err)
 	}
 
-	diffStat, err := gitOutput(ctx, repoRoot, "diff", baseRef, "--stat")
+	diffStat, err := gitOutput(ctx, repoRoot, diffArgs(baseRef, "--stat", pathspecs)...)
 	if err != nil {
 		return "", "", err
 	}
-	diffNames, err := gitOutput(ctx, repoRoot, "diff", baseRef, "--name-only")
+	diffNames, err := gitOutput(ctx, repoRoot, diffArgs(baseRef, "--name-only", pathspecs)...)
 	if err != nil {
 		return "", "", err
 	}
@@ -57,16 +58,54 @@ func buildDraftPrompt(ctx context.Context, repoRoot, requestedBase string) (stri
 	prompt := renderDraftPrompt(draftPromptData{
 		RepoRoot:          repoRoot,
 		BaseRef:           baseRef,
-		Version:           strings.TrimSpace(string(versionContent)),
+		Version:           versionContent,
 		UnreleasedContent: strings.TrimSpace(string(unreleasedContent)),
 		ReadmeContent:     strings.TrimSpace(string(readmeContent)),
 		DiffStat:          emptyAsNone(diffStat),
 		DiffNames:         emptyAsNone(diffNames),
+		Pathspecs:         pathspecs,
 	})
 
 	return prompt + "\n", baseRef, nil
 }
 
+// resolveDraftPathspecs 将 --path 参数展开为 git pathspec；
+// 与 .fastgit/modules.yaml 中模块同名的条目展开为该模块的 paths
+func resolveDraftPathspecs(repoRoot, raw string) ([]string, error) {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	bundle, err := repoconfig.Load(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	specs := make([]string, 0, len(items))
+	for _, item := range items {
+		if m, ok := bundle.FindModule(item); ok {
+			specs = append(specs, m.Pathspecs()...)
+			continue
+		}
+		specs = append(specs, item)
+	}
+	return specs, nil
+}
+
+func diffArgs(baseRef, mode string, pathspecs []string) []string {
+	args := []string{"diff", baseRef, mode}
+	if len(pathspecs) > 0 {
+		args = append(append(args, "--"), pathspecs...)
+	}
+	return args
+}
+
 func detectBaseRef(ctx context.Context, repoRoot, requested string) (string, error) {
 	requested = strings.TrimSpace(requested)
 	if requested != "" {
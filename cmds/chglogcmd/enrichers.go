@@ -0,0 +1,228 @@
+package chglogcmd
+
+import (
+	"context"
+	"fmt"
+	neturl "net/url"
+	"os"
+	"strings"
+
+	"github.com/google/go-github/v71/github"
+	"github.com/pubgo/dix/v2"
+	"github.com/pubgo/dix/v2/dixcontext"
+
+	"github.com/pubgo/fastgit/pkg/changelog"
+	"github.com/pubgo/fastgit/pkg/ticket"
+	"github.com/pubgo/fastgit/utils"
+)
+
+const (
+	EnricherGitHub = "github"
+	EnricherJira   = "jira"
+	EnricherLLM    = "llm"
+)
+
+// Config 是 config.yaml 的 changelog 段
+type Config struct {
+	// Enrichers 按顺序组成 generate 的条目增强流水线，留空不增强
+	Enrichers []EnricherConfig `yaml:"enrichers"`
+	// Sections 为提交类型到段落的映射，如 sec → 安全，优先于内置的 feat/fix/docs 映射
+	Sections []changelog.SectionRule `yaml:"sections"`
+}
+
+// EnricherConfig 描述流水线中的一个增强器
+type EnricherConfig struct {
+	// Name 为 github|jira|llm
+	Name string `yaml:"name"`
+	// Token 为 github 增强器的访问令牌，留空读取 GITHUB_TOKEN / GH_TOKEN
+	Token string `yaml:"token"`
+	// UseTitle 为 true 时 github 增强器用 PR 标题替换提交标题
+	UseTitle bool `yaml:"use_title"`
+	// Provider 为 llm 增强器的 AI 提供方，默认 auto
+	Provider string `yaml:"provider"`
+}
+
+type enricherParams struct {
+	ChangelogCfg []*Config
+	TicketCfg    []*ticket.Config
+	OpenaiCfg    []*utils.OpenaiConfig
+}
+
+// configuredProxy 返回 openai.proxy，GitHub API 请求与 AI 请求走同一代理
+func configuredProxy(ctx context.Context) string {
+	di := dixcontext.GetOrNil(ctx)
+	if di == nil {
+		return ""
+	}
+	return utils.ConfiguredProxy(dix.Inject(di, enricherParams{}).OpenaiCfg)
+}
+
+// loadEnrichers 合并各层配置中的流水线，后出现的非空配置生效
+func loadEnrichers(ctx context.Context) ([]EnricherConfig, []*ticket.Config) {
+	di := dixcontext.GetOrNil(ctx)
+	if di == nil {
+		return nil, nil
+	}
+	params := dix.Inject(di, enricherParams{})
+	var enrichers []EnricherConfig
+	for _, cfg := range params.ChangelogCfg {
+		if cfg != nil && len(cfg.Enrichers) > 0 {
+			enrichers = cfg.Enrichers
+		}
+	}
+	return enrichers, params.TicketCfg
+}
+
+// sectionLayout 按各层配置中的 changelog.sections 构造段落布局，后出现的非空配置生效；未配置时为标准段落
+func sectionLayout(ctx context.Context) (changelog.Layout, error) {
+	di := dixcontext.GetOrNil(ctx)
+	if di == nil {
+		return changelog.Layout{}, nil
+	}
+	params := dix.Inject(di, enricherParams{})
+	var rules []changelog.SectionRule
+	for _, cfg := range params.ChangelogCfg {
+		if cfg != nil && len(cfg.Sections) > 0 {
+			rules = cfg.Sections
+		}
+	}
+	return changelog.NewLayout(rules)
+}
+
+// buildPipeline 按配置构造增强流水线；未知名称直接报错，避免配置拼写错误被静默忽略
+func buildPipeline(ctx context.Context, repoRoot string, cfgs []EnricherConfig, ticketCfgs []*ticket.Config) (changelog.Pipeline, error) {
+	var pipeline changelog.Pipeline
+	for _, cfg := range cfgs {
+		switch strings.ToLower(strings.TrimSpace(cfg.Name)) {
+		case EnricherGitHub:
+			enricher, err := newGitHubEnricher(ctx, repoRoot, cfg)
+			if err != nil {
+				return nil, err
+			}
+			pipeline = append(pipeline, enricher)
+		case EnricherJira:
+			pipeline = append(pipeline, newJiraEnricher(ticketCfgs))
+		case EnricherLLM:
+			pipeline = append(pipeline, newLLMEnricher(defaultString(cfg.Provider, "auto"), repoRoot))
+		default:
+			return nil, fmt.Errorf("unknown changelog enricher %q, use %s|%s|%s", cfg.Name, EnricherGitHub, EnricherJira, EnricherLLM)
+		}
+	}
+	return pipeline, nil
+}
+
+// newGitHubEnricher 通过提交查询合入它的 PR，记录 PR 号，use_title 时改用 PR 标题
+func newGitHubEnricher(ctx context.Context, repoRoot string, cfg EnricherConfig) (changelog.Enricher, error) {
+	remote, err := gitOutput(ctx, repoRoot, "remote", "get-url", "origin")
+	if err != nil {
+		return nil, fmt.Errorf("github enricher needs an origin remote: %w", err)
+	}
+	owner, repo, err := githubRepo(remote)
+	if err != nil {
+		return nil, fmt.Errorf("github enricher: %w", err)
+	}
+
+	token := strings.TrimSpace(cfg.Token)
+	for _, name := range []string{"GITHUB_TOKEN", "GH_TOKEN"} {
+		if token == "" {
+			token = strings.TrimSpace(os.Getenv(name))
+		}
+	}
+	client := github.NewClient(utils.NewHTTPClient(configuredProxy(ctx)))
+	if token != "" {
+		client = client.WithAuthToken(token)
+	}
+	return changelog.EnricherFunc(EnricherGitHub, func(ctx context.Context, e changelog.ChangelogEntry) (changelog.ChangelogEntry, error) {
+		if e.Hash == "" || e.PR > 0 {
+			return e, nil
+		}
+		prs, _, err := client.PullRequests.ListPullRequestsWithCommit(ctx, owner, repo, e.Hash, nil)
+		if err != nil {
+			return e, err
+		}
+		for _, pr := range prs {
+			if pr.MergedAt == nil {
+				continue
+			}
+			e.PR = pr.GetNumber()
+			if cfg.UseTitle {
+				if title := strings.TrimSpace(changelog.ParseCommit(e.Hash, pr.GetTitle(), "", e.Date).Subject); title != "" {
+					e.Subject = title
+				}
+			}
+			break
+		}
+		return e, nil
+	}), nil
+}
+
+// newJiraEnricher 为 Refs 中的工单号拉取标题写入 Notes，同一工单只查询一次；未配置 ticket 时不做任何事
+func newJiraEnricher(cfgs []*ticket.Config) changelog.Enricher {
+	var cfg *ticket.Config
+	for _, c := range cfgs {
+		if c.Enabled() {
+			cfg = c
+			break
+		}
+	}
+	summaries := map[string]string{}
+	return changelog.EnricherFunc(EnricherJira, func(ctx context.Context, e changelog.ChangelogEntry) (changelog.ChangelogEntry, error) {
+		if cfg == nil {
+			return e, nil
+		}
+		var notes []string
+		for _, ref := range e.Refs {
+			key := cfg.Key(ref)
+			if key == "" {
+				continue
+			}
+			summary, ok := summaries[key]
+			if !ok {
+				tk, err := cfg.Fetch(ctx, key)
+				if err != nil {
+					return e, err
+				}
+				summary = tk.Ref()
+				summaries[key] = summary
+			}
+			if summary != "" {
+				notes = append(notes, summary)
+			}
+		}
+		e.Notes = append(append([]string(nil), e.Notes...), notes...)
+		return e, nil
+	})
+}
+
+// newLLMEnricher 复用 generate -i 的 AI 改写，把提交标题改写为面向用户的描述
+func newLLMEnricher(provider, repoRoot string) changelog.Enricher {
+	reword := providerReword(provider, repoRoot)
+	return changelog.EnricherFunc(EnricherLLM, func(ctx context.Context, e changelog.ChangelogEntry) (changelog.ChangelogEntry, error) {
+		text, err := reword(ctx, e)
+		if err != nil {
+			return e, err
+		}
+		if text != "" {
+			e.Subject = text
+		}
+		return e, nil
+	})
+}
+
+// githubRepo 从 origin 地址解析 github.com 上的 owner/repo
+func githubRepo(remote string) (owner, repo string, err error) {
+	remote = strings.TrimSuffix(strings.TrimSpace(remote), ".git")
+	path, ok := strings.CutPrefix(remote, "git@github.com:")
+	if !ok {
+		u, err := neturl.Parse(remote)
+		if err != nil || !strings.EqualFold(u.Hostname(), "github.com") {
+			return "", "", fmt.Errorf("origin %s is not a github.com remote", remote)
+		}
+		path = strings.TrimPrefix(u.Path, "/")
+	}
+	parts := strings.Split(path, "/")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid github remote %s", remote)
+	}
+	return parts[0], parts[1], nil
+}
@@ -0,0 +1,38 @@
+package chglogcmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pubgo/fastgit/pkg/changelog"
+	"github.com/pubgo/fastgit/pkg/ticket"
+)
+
+func TestGitHubRepo(t *testing.T) {
+	for _, remote := range []string{"git@github.com:pubgo/fastgit.git", "https://github.com/pubgo/fastgit", "ssh://git@github.com/pubgo/fastgit.git"} {
+		owner, repo, err := githubRepo(remote)
+		require.NoError(t, err, remote)
+		require.Equal(t, "pubgo", owner)
+		require.Equal(t, "fastgit", repo)
+	}
+	_, _, err := githubRepo("https://gitlab.com/pubgo/fastgit.git")
+	require.Error(t, err)
+}
+
+func TestBuildPipeline(t *testing.T) {
+	pipeline, err := buildPipeline(context.Background(), t.TempDir(), []EnricherConfig{{Name: "jira"}, {Name: "LLM"}}, nil)
+	require.NoError(t, err)
+	require.Len(t, pipeline, 2)
+
+	_, err = buildPipeline(context.Background(), t.TempDir(), []EnricherConfig{{Name: "slack"}}, nil)
+	require.ErrorContains(t, err, `unknown changelog enricher "slack"`)
+}
+
+func TestJiraEnricherWithoutTicketConfig(t *testing.T) {
+	e := changelog.ChangelogEntry{Subject: "login", Refs: []string{"ABC-1"}}
+	got, err := newJiraEnricher([]*ticket.Config{{}}).Enrich(context.Background(), e)
+	require.NoError(t, err)
+	require.Empty(t, got.Notes)
+}
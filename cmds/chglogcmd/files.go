@@ -2,8 +2,10 @@ package chglogcmd
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -12,6 +14,9 @@ import (
 	"time"
 
 	semver "github.com/hashicorp/go-version"
+
+	"github.com/pubgo/fastgit/pkg/sparse"
+	"github.com/pubgo/fastgit/pkg/versionfile"
 )
 
 const defaultInitialVersion = "v0.1.0"
@@ -20,7 +25,7 @@ var standardSections = []string{"新增", "修复", "变更", "文档"}
 
 type changelogPaths struct {
 	RepoRoot            string
-	VersionDir          string
+	Version             versionfile.Config
 	VersionFile         string
 	ChangelogDir        string
 	UnreleasedFile      string
@@ -57,6 +62,8 @@ type releaseResult struct {
 	CreatedFiles []string
 	UpdatedFiles []string
 	NextVersion  string
+	Version      string
+	Content      string
 }
 
 func resolveRepoRoot(input string) (string, error) {
@@ -89,13 +96,25 @@ func resolveExistingGitRepo(input string) (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
+// checkSparse 在 sparse-checkout 仓库中确认 .version/changelog 已检出，否则提示 fastgit sparse add；
+// 部分克隆时提醒 diff 会按需下载缺失的文件内容
+func checkSparse(ctx context.Context, repoRoot string, w io.Writer) error {
+	if hint := sparse.Hint(ctx, repoRoot, ".version/changelog/Unreleased.md"); hint != "" {
+		return errors.New(hint)
+	}
+	if info := sparse.Status(ctx, repoRoot); info.Filter != "" {
+		_, _ = fmt.Fprintf(w, "note: partial clone (%s), diffs may download missing objects from origin\n", info.Filter)
+	}
+	return nil
+}
+
 func buildPaths(repoRoot string) changelogPaths {
-	versionDir := filepath.Join(repoRoot, ".version")
-	changelogDir := filepath.Join(versionDir, "changelog")
+	changelogDir := filepath.Join(repoRoot, ".version", "changelog")
+	version := versionfile.MustLoadConfig(repoRoot)
 	return changelogPaths{
 		RepoRoot:            repoRoot,
-		VersionDir:          versionDir,
-		VersionFile:         filepath.Join(versionDir, "VERSION"),
+		Version:             version,
+		VersionFile:         version.Primary().Path,
 		ChangelogDir:        changelogDir,
 		UnreleasedFile:      filepath.Join(changelogDir, "Unreleased.md"),
 		ReadmeFile:          filepath.Join(changelogDir, "README.md"),
@@ -114,7 +133,7 @@ func ensureChangelogScaffold(repoRoot string, opts scaffoldOptions) (scaffoldRes
 	if version == "" {
 		version = defaultInitialVersion
 	}
-	if err := validateVersion(version); err != nil {
+	if err := versionfile.Validate(version); err != nil {
 		return scaffoldResult{}, err
 	}
 
@@ -131,18 +150,16 @@ func ensureChangelogScaffold(repoRoot string, opts scaffoldOptions) (scaffoldRes
 	result := scaffoldResult{}
 
 	if !fileExists(paths.VersionFile) && opts.CreateVersionIfMissing {
-		if err := os.MkdirAll(paths.VersionDir, 0o755); err != nil {
-			return scaffoldResult{}, fmt.Errorf("create version directory: %w", err)
-		}
-		if err := os.WriteFile(paths.VersionFile, []byte(version+"\n"), 0o644); err != nil {
+		if err := paths.Version.Primary().Write(version); err != nil {
 			return scaffoldResult{}, fmt.Errorf("write VERSION: %w", err)
 		}
 		result.Created = append(result.Created, paths.VersionFile)
 	} else if fileExists(paths.VersionFile) && opts.Force {
-		if err := os.WriteFile(paths.VersionFile, []byte(version+"\n"), 0o644); err != nil {
+		files, err := paths.Version.Write(version)
+		if err != nil {
 			return scaffoldResult{}, fmt.Errorf("rewrite VERSION: %w", err)
 		}
-		result.Updated = append(result.Updated, paths.VersionFile)
+		result.Updated = append(result.Updated, files...)
 	}
 
 	state, err := writeManagedFile(paths.UnreleasedFile, renderUnreleasedTemplate(), opts.Force)
@@ -216,13 +233,13 @@ func releaseChangelog(repoRoot string, opts releaseOptions) (releaseResult, erro
 
 	currentVersion := strings.TrimSpace(opts.Version)
 	if currentVersion == "" {
-		content, err := os.ReadFile(paths.VersionFile)
+		content, err := paths.Version.Current()
 		if err != nil {
 			return releaseResult{}, fmt.Errorf("read VERSION: %w", err)
 		}
-		currentVersion = strings.TrimSpace(string(content))
+		currentVersion = content
 	}
-	if err := validateVersion(currentVersion); err != nil {
+	if err := versionfile.Validate(currentVersion); err != nil {
 		return releaseResult{}, err
 	}
 
@@ -258,11 +275,13 @@ func releaseChangelog(repoRoot string, opts releaseOptions) (releaseResult, erro
 	created := []string{targetFile}
 	updated := []string{paths.UnreleasedFile, paths.ReadmeFile}
 	if nextVersion != "" {
-		updated = append(updated, paths.VersionFile)
+		for _, f := range paths.Version.Files {
+			updated = append(updated, f.Path)
+		}
 	}
 
 	if opts.DryRun {
-		return releaseResult{CreatedFiles: created, UpdatedFiles: updated, NextVersion: nextVersion}, nil
+		return releaseResult{CreatedFiles: created, UpdatedFiles: updated, NextVersion: nextVersion, Version: currentVersion, Content: releaseContent}, nil
 	}
 
 	if err := os.WriteFile(targetFile, []byte(releaseContent), 0o644); err != nil {
@@ -281,12 +300,12 @@ func releaseChangelog(repoRoot string, opts releaseOptions) (releaseResult, erro
 	}
 
 	if nextVersion != "" {
-		if err := os.WriteFile(paths.VersionFile, []byte(nextVersion+"\n"), 0o644); err != nil {
+		if _, err := paths.Version.Write(nextVersion); err != nil {
 			return releaseResult{}, fmt.Errorf("update VERSION: %w", err)
 		}
 	}
 
-	return releaseResult{CreatedFiles: created, UpdatedFiles: updated, NextVersion: nextVersion}, nil
+	return releaseResult{CreatedFiles: created, UpdatedFiles: updated, NextVersion: nextVersion, Version: currentVersion, Content: releaseContent}, nil
 }
 
 func renderUnreleasedTemplate() string {
@@ -336,7 +355,7 @@ func hasMeaningfulEntries(sections map[string]string) bool {
 }
 
 func renderChangelogReadme(changelogDir string) (string, error) {
-	versions, err := listReleaseVersions(changelogDir)
+	versions, err := ListReleaseVersions(changelogDir)
 	if err != nil {
 		return "", err
 	}
@@ -359,7 +378,8 @@ func renderChangelogReadme(changelogDir string) (string, error) {
 	return buf.String(), nil
 }
 
-func listReleaseVersions(changelogDir string) ([]string, error) {
+// ListReleaseVersions 返回 changelog 目录中已落版的版本（vX.Y.Z.md），按版本号从新到旧排序
+func ListReleaseVersions(changelogDir string) ([]string, error) {
 	entries, err := os.ReadDir(changelogDir)
 	if err != nil {
 		return nil, fmt.Errorf("read changelog directory: %w", err)
@@ -394,7 +414,7 @@ func resolveNextVersion(current, explicit, bump string) (string, error) {
 		return "", errors.New("--next-version 与 --bump 只能二选一")
 	}
 	if strings.TrimSpace(explicit) != "" {
-		if err := validateVersion(explicit); err != nil {
+		if err := versionfile.Validate(explicit); err != nil {
 			return "", err
 		}
 		return strings.TrimSpace(explicit), nil
@@ -402,42 +422,7 @@ func resolveNextVersion(current, explicit, bump string) (string, error) {
 	if strings.TrimSpace(bump) == "" {
 		return "", nil
 	}
-
-	v, err := semver.NewVersion(current)
-	if err != nil {
-		return "", fmt.Errorf("parse current version %q: %w", current, err)
-	}
-	segments := v.Segments()
-	if len(segments) < 3 {
-		return "", fmt.Errorf("invalid version: %s", current)
-	}
-
-	switch strings.ToLower(strings.TrimSpace(bump)) {
-	case "patch":
-		segments[2]++
-	case "minor":
-		segments[1]++
-		segments[2] = 0
-	case "major":
-		segments[0]++
-		segments[1] = 0
-		segments[2] = 0
-	default:
-		return "", fmt.Errorf("unsupported bump level: %s", bump)
-	}
-
-	return fmt.Sprintf("v%d.%d.%d", segments[0], segments[1], segments[2]), nil
-}
-
-func validateVersion(version string) error {
-	version = strings.TrimSpace(version)
-	if version == "" {
-		return errors.New("version cannot be empty")
-	}
-	if _, err := semver.NewVersion(version); err != nil {
-		return fmt.Errorf("invalid version %q: %w", version, err)
-	}
-	return nil
+	return versionfile.Bump(current, bump)
 }
 
 func fileExists(path string) bool {
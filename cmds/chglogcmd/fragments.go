@@ -0,0 +1,192 @@
+package chglogcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pubgo/redant"
+
+	"github.com/pubgo/fastgit/pkg/changelog"
+	"github.com/pubgo/fastgit/utils"
+)
+
+func newAddCommand() *redant.Command {
+	var (
+		repoPath string
+		name     string
+	)
+
+	return &redant.Command{
+		Use:      "add <type> <text...>",
+		Short:    "在 changelog.d/ 写入一条手写的 changelog 片段，发布时由 changelog build 汇总",
+		Long:     "type 为 conventional 类型（feat→新增、fix→修复、docs→文档，其余→变更，可用 changelog.sections 配置），片段文件名为 <name>.<type>.md。示例：fastgit changelog add feat \"支持 --watch 模式\"",
+		Metadata: utils.NoTTYMetadata(),
+		Options: redant.OptionSet{
+			{Flag: "repo", Description: "目标仓库目录（默认当前目录）", Value: redant.StringOf(&repoPath)},
+			{Flag: "name", Description: "片段名（如 issue 号 123），缺省由内容生成", Value: redant.StringOf(&name)},
+		},
+		Handler: func(ctx context.Context, inv *redant.Invocation) error {
+			if len(inv.Args) < 2 {
+				return redant.DefaultHelpFn()(ctx, inv)
+			}
+			repoRoot, err := resolveExistingGitRepo(strings.TrimSpace(repoPath))
+			if err != nil {
+				return err
+			}
+			path, err := changelog.WriteFragment(filepath.Join(repoRoot, changelog.FragmentDir), inv.Args[0], strings.Join(inv.Args[1:], " "), name)
+			if err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(inv.Stdout, "created: %s\n", path)
+			return nil
+		},
+	}
+}
+
+func newBuildCommand() *redant.Command {
+	var (
+		repoPath string
+		output   string
+		version  string
+		dryRun   bool
+		keep     bool
+	)
+
+	return &redant.Command{
+		Use:      "build",
+		Short:    "汇总 changelog.d/ 的片段：追加到 Unreleased.md（或 --output 指定的 CHANGELOG.md）并删除已汇总的片段",
+		Long:     "changelog release 落版前也会自动汇总尚未 build 的片段。",
+		Metadata: utils.NoTTYMetadata(),
+		Options: redant.OptionSet{
+			{Flag: "repo", Description: "目标仓库目录（默认当前目录）", Value: redant.StringOf(&repoPath)},
+			{Flag: "output", Shorthand: "o", Description: "合并进该 changelog 文件（如 CHANGELOG.md，同 generate --update），而不是 Unreleased.md", Value: redant.StringOf(&output)},
+			{Flag: "version", Description: "--output 时的版本标题（如 v1.2.0，带当天日期），缺省为 Unreleased", Value: redant.StringOf(&version)},
+			{Flag: "dry-run", Description: "只输出汇总结果，不写文件也不删除片段", Value: redant.BoolOf(&dryRun)},
+			{Flag: "keep", Description: "汇总后保留片段文件", Value: redant.BoolOf(&keep)},
+		},
+		Handler: func(ctx context.Context, inv *redant.Invocation) error {
+			repoRoot, err := resolveExistingGitRepo(strings.TrimSpace(repoPath))
+			if err != nil {
+				return err
+			}
+			if version != "" && output == "" {
+				return errors.New("--version is only used with --output")
+			}
+			layout, err := sectionLayout(ctx)
+			if err != nil {
+				return err
+			}
+			fragments, err := changelog.ReadFragments(filepath.Join(repoRoot, changelog.FragmentDir))
+			if err != nil {
+				return err
+			}
+			if len(fragments) == 0 {
+				_, _ = fmt.Fprintf(inv.Stdout, "no fragments in %s\n", changelog.FragmentDir)
+				return nil
+			}
+			result := changelog.FromFragments(fragments, layout)
+
+			if dryRun {
+				for _, title := range result.Layout.Titles() {
+					_, _ = fmt.Fprintf(inv.Stdout, "\n## %s\n\n%s\n", title, result.Sections[title])
+				}
+				return nil
+			}
+
+			if output != "" {
+				release := changelog.Release{Version: changelog.Unreleased, Result: result}
+				if version = strings.TrimSpace(version); version != "" {
+					release.Version, release.Date = version, time.Now()
+				}
+				if err := updateChangelog(inv, output, []changelog.Release{release}); err != nil {
+					return err
+				}
+			} else {
+				if err := checkSparse(ctx, repoRoot, inv.Stdout); err != nil {
+					return err
+				}
+				if err := appendFragments(inv, repoRoot, result); err != nil {
+					return err
+				}
+			}
+			if keep {
+				return nil
+			}
+			return removeFragments(inv, layout, fragments)
+		},
+	}
+}
+
+// buildPendingFragments 在落版前把 changelog.d/ 中尚未汇总的片段追加到 Unreleased.md 并删除；dryRun 时只提示数量
+func buildPendingFragments(inv *redant.Invocation, repoRoot string, layout changelog.Layout, dryRun bool) error {
+	fragments, err := changelog.ReadFragments(filepath.Join(repoRoot, changelog.FragmentDir))
+	if err != nil || len(fragments) == 0 {
+		return err
+	}
+	if dryRun {
+		_, _ = fmt.Fprintf(inv.Stdout, "would build: %d fragments in %s\n", len(fragments), changelog.FragmentDir)
+		return nil
+	}
+	if err := appendFragments(inv, repoRoot, changelog.FromFragments(fragments, layout)); err != nil {
+		return err
+	}
+	return removeFragments(inv, layout, fragments)
+}
+
+// appendFragments 把片段条目追加到 Unreleased.md 对应段落的已有条目之后，保留 meta 段落
+func appendFragments(inv *redant.Invocation, repoRoot string, result changelog.Result) error {
+	if _, err := ensureChangelogScaffold(repoRoot, scaffoldOptions{
+		Version:                defaultInitialVersion,
+		CreateVersionIfMissing: true,
+	}); err != nil {
+		return err
+	}
+	paths := buildPaths(repoRoot)
+	content, err := os.ReadFile(paths.UnreleasedFile)
+	if err != nil {
+		return err
+	}
+	sections := parseAllSections(string(content))
+	generated := foldSections(result.Layout, result.Sections)
+	for _, title := range standardSections {
+		added := generated[title]
+		if added == "" || added == "暂无" {
+			continue
+		}
+		if body := normalizeSectionBody(sections[title]); body != "暂无" {
+			added = body + "\n" + added
+		}
+		sections[title] = added
+	}
+	if err := os.WriteFile(paths.UnreleasedFile, []byte(renderUnreleasedWithMeta(sections)), 0o644); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(inv.Stdout, "updated: %s (%d fragments)\n", paths.UnreleasedFile, result.Stats.Total)
+	return nil
+}
+
+// removeFragments 删除已汇总的片段；被 changelog.sections 隐藏的片段没有写入 changelog，保留在磁盘上
+func removeFragments(inv *redant.Invocation, layout changelog.Layout, fragments []changelog.Fragment) error {
+	var removed int
+	var hidden []string
+	for _, f := range fragments {
+		if layout.Section(f.Entry()) == "" {
+			hidden = append(hidden, filepath.Base(f.Path))
+			continue
+		}
+		if err := os.Remove(f.Path); err != nil {
+			return err
+		}
+		removed++
+	}
+	_, _ = fmt.Fprintf(inv.Stdout, "removed: %d fragments\n", removed)
+	if len(hidden) > 0 {
+		_, _ = fmt.Fprintf(inv.Stdout, "kept: %d fragments hidden by changelog.sections (%s)\n", len(hidden), strings.Join(hidden, ", "))
+	}
+	return nil
+}
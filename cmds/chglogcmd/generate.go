@@ -0,0 +1,261 @@
+package chglogcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pubgo/redant"
+
+	"github.com/pubgo/fastgit/pkg/changelog"
+	"github.com/pubgo/fastgit/utils"
+)
+
+type (
+	generateOptions = changelog.Options
+	generateResult  = changelog.Result
+)
+
+func newGenerateCommand() *redant.Command {
+	var (
+		repoPath    string
+		opts        generateOptions
+		paths       []string
+		write       bool
+		interactive bool
+		aiProvider  string
+		noEnrich    bool
+		pr          int64
+		tplPath     string
+		all         bool
+		update      bool
+		output      string
+		gh          githubOutputs
+	)
+
+	return &redant.Command{
+		Use:      "generate",
+		Short:    "根据 conventional 提交记录生成 changelog 条目（按提交 hash 增量缓存）",
+		Long:     "解析结果缓存在 .git/fastgit/changelog-cache.json，重复生成大范围（上千提交）时只解析新增提交。",
+		Metadata: utils.NoTTYMetadata(),
+		Options: append(redant.OptionSet{
+			{Flag: "repo", Description: "目标仓库目录（默认当前目录）", Value: redant.StringOf(&repoPath)},
+			{Flag: "from", Description: "起始 ref（不含），默认最近的 tag；无 tag 时为全部历史", Value: redant.StringOf(&opts.From)},
+			{Flag: "to", Description: "结束 ref", Value: redant.StringOf(&opts.To), Default: "HEAD"},
+			{Flag: "write", Description: "写入 Unreleased.md 的 新增/修复/变更/文档 段落", Value: redant.BoolOf(&write), Default: "false"},
+			{Flag: "scope", Description: "只保留 conventional scope 匹配的条目（逗号分隔或可重复，如 api,cli；api 同时匹配 api/users），无 scope 的提交被排除", Value: redant.StringArrayOf(&opts.Scopes)},
+			{Flag: "scopes", Description: "同 --scope", Value: redant.StringArrayOf(&opts.Scopes), Hidden: true, Deprecated: "use --scope"},
+			{Flag: "exclude-scope", Description: "排除 scope 匹配的条目（如 deps,ci）；多 scope 提交只有全部命中时才排除", Value: redant.StringArrayOf(&opts.ExcludeScopes)},
+			{Flag: "path", Description: "只统计改动这些路径的提交（可重复或逗号分隔，支持 pkg/foo/... 写法与 .fastgit/modules.yaml 模块名），用于 monorepo 按包生成", Value: redant.StringArrayOf(&paths)},
+			{Flag: "tag-prefix", Description: "包的 tag 前缀，如 foo/ 时以 foo/v* 作为最近的 tag 与 --all 遍历的版本", Value: redant.StringOf(&opts.TagPrefix)},
+			{Flag: "no-cache", Description: "忽略缓存，重新解析全部提交", Value: redant.BoolOf(&opts.NoCache), Default: "false"},
+			{Flag: "interactive", Shorthand: "i", Description: "输出或写入前在 TUI 中逐条确认：丢弃、改类型、手动或用 AI 改写", Value: redant.BoolOf(&interactive), Default: "false"},
+			{Flag: "ai-provider", Description: "--interactive 改写条目使用的 AI 提供方 auto|openai|gemini|anthropic|ollama|copilot", Value: redant.StringOf(&aiProvider), Default: "auto"},
+			{Flag: "no-enrich", Description: "跳过 config.yaml 中 changelog.enrichers 配置的条目增强流水线", Value: redant.BoolOf(&noEnrich), Default: "false"},
+			{Flag: "commit", Description: "只输出单个提交的条目与提交说明（merge 提交列出其合入的提交），忽略 --from/--to", Value: redant.StringOf(&opts.Commit)},
+			{Flag: "template", Description: "用 Go text/template 文件渲染输出（数据为 changelog.Changelog：.Range .Date .Sections .Entries），不能与 --write 同用", Value: redant.StringOf(&tplPath)},
+			{Flag: "all", Description: "遍历全部 v* tag，按相邻 tag 逐个版本生成（含发布日期，最新 tag 之后的提交为 Unreleased），输出完整的 CHANGELOG.md", Value: redant.BoolOf(&all)},
+			{Flag: "update", Description: "把生成的版本段落插入已有的 changelog 文件顶部（替换 ## [Unreleased] 或插在标题下），保留此前的版本，而不是覆盖整个文件", Value: redant.BoolOf(&update)},
+			{Flag: "output", Shorthand: "o", Description: "--all / --update 写入的文件（如 CHANGELOG.md）；--all 缺省输出到 stdout，--update 缺省为仓库根目录的 CHANGELOG.md", Value: redant.StringOf(&output)},
+			{Flag: "pr", Description: "只输出单个 PR 的条目、描述与提交（使用 gh CLI，不可用时在本地历史中查找 merge/squash 提交）", Value: redant.Int64Of(&pr)},
+		}, append(exclusionOptions(&opts.Exclude), gh.options()...)...),
+		Handler: func(ctx context.Context, inv *redant.Invocation) error {
+			repoRoot, err := resolveExistingGitRepo(strings.TrimSpace(repoPath))
+			if err != nil {
+				return err
+			}
+			if opts.Layout, err = sectionLayout(ctx); err != nil {
+				return err
+			}
+			if opts.Paths, err = resolveDraftPathspecs(repoRoot, strings.Join(paths, ",")); err != nil {
+				return err
+			}
+			opts.TagPrefix = strings.TrimSpace(opts.TagPrefix)
+
+			if all {
+				switch {
+				case opts.Commit != "" || pr > 0 || opts.From != "":
+					return errors.New("--all walks every tag and cannot be combined with --from, --commit or --pr")
+				case write || interactive || tplPath != "":
+					return errors.New("--all cannot be combined with --write, --interactive or --template, use --output instead")
+				}
+				return runAll(ctx, inv, repoRoot, opts, output, update, !noEnrich, gh)
+			}
+			if output != "" && !update {
+				return errors.New("--output is only used with --all or --update")
+			}
+			if update && (write || tplPath != "" || opts.Commit != "" || pr > 0) {
+				return errors.New("--update cannot be combined with --write, --template, --commit or --pr")
+			}
+
+			if opts.Commit != "" || pr > 0 {
+				switch {
+				case opts.Commit != "" && pr > 0:
+					return errors.New("--commit and --pr cannot be used together")
+				case len(opts.Scopes) > 0 || len(opts.ExcludeScopes) > 0 || len(opts.Paths) > 0 || opts.TagPrefix != "":
+					return errors.New("--scope, --exclude-scope, --path and --tag-prefix filter a range and cannot be combined with --commit or --pr")
+				case write || interactive:
+					return errors.New("--commit/--pr print a single change and cannot be combined with --write or --interactive")
+				}
+				return runSingle(ctx, inv.Stdout, repoRoot, opts.Layout, opts.Commit, int(pr), !noEnrich, gh)
+			}
+			if tplPath != "" && write {
+				return errors.New("--template renders to stdout and cannot be combined with --write")
+			}
+
+			result, err := generateEntries(ctx, repoRoot, opts)
+			if err != nil {
+				return err
+			}
+			// 模板输出可能被重定向到文件，进度信息改写到 stderr
+			status := inv.Stdout
+			if tplPath != "" {
+				status = inv.Stderr
+			}
+			_, _ = fmt.Fprintf(status, "range: %s (%d commits, %d cached, %d parsed)\n",
+				result.Range, result.Stats.Total, result.Stats.Cached, result.Stats.Parsed)
+
+			if !noEnrich {
+				if err := enrichEntries(ctx, repoRoot, &result, status); err != nil {
+					return err
+				}
+			}
+
+			if interactive {
+				entries, ok, err := reviewEntries(ctx, result.Layout, result.Entries, providerReword(aiProvider, repoRoot))
+				if err != nil {
+					return err
+				}
+				if !ok {
+					_, _ = fmt.Fprintln(inv.Stdout, "aborted, nothing written")
+					return nil
+				}
+				result.Entries = entries
+				result.Sections = result.Layout.Render(result.Layout.Group(entries))
+			}
+
+			markdown := generatedMarkdown(result)
+			if tplPath != "" {
+				// 模板输出同时作为 --summary / --comment-pr 的内容
+				markdown, err = changelog.RenderTemplateFile(tplPath, changelog.NewChangelog(result, time.Now()))
+				if err != nil {
+					return err
+				}
+			}
+			if gh.enabled() {
+				if err := gh.publish(ctx, repoRoot, markdown, inv.Stdout); err != nil {
+					return err
+				}
+			}
+
+			if tplPath != "" {
+				_, _ = fmt.Fprint(inv.Stdout, markdown)
+				return nil
+			}
+			if update {
+				return updateChangelog(inv, changelogPath(repoRoot, output), []changelog.Release{unreleasedRelease(result, opts.To)})
+			}
+			if !write {
+				for _, title := range result.Layout.Titles() {
+					_, _ = fmt.Fprintf(inv.Stdout, "\n## %s\n\n%s\n", title, result.Sections[title])
+				}
+				return nil
+			}
+
+			if err := checkSparse(ctx, repoRoot, inv.Stdout); err != nil {
+				return err
+			}
+			if _, err := ensureChangelogScaffold(repoRoot, scaffoldOptions{
+				Version:                defaultInitialVersion,
+				CreateVersionIfMissing: true,
+			}); err != nil {
+				return err
+			}
+			paths := buildPaths(repoRoot)
+			if err := writeGeneratedSections(paths.UnreleasedFile, result); err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(inv.Stdout, "updated: %s\n", paths.UnreleasedFile)
+			return nil
+		},
+	}
+}
+
+// exclusionOptions 是 generate 与 publish 共用的提交排除选项
+func exclusionOptions(x *changelog.Exclusions) redant.OptionSet {
+	return redant.OptionSet{
+		{Flag: "exclude-pattern", Description: "排除标题行（含 type(scope): 前缀）匹配该正则的提交，可重复，如 '^chore\\(release\\)'；含逗号的正则需加双引号，如 '\"x{1,3}\"'", Value: redant.StringArrayOf(&x.Patterns)},
+		{Flag: "exclude-deps", Description: "排除依赖升级提交：chore(deps)、chore(deps-dev)、build(deps)", Value: redant.BoolOf(&x.Deps)},
+		{Flag: "exclude-merge-back", Description: "排除回合提交，如 Merge branch 'main' into feat/x、merge back release/1.2", Value: redant.BoolOf(&x.MergeBack)},
+		{Flag: "keep-skipped", Description: "保留提交信息中带 [skip changelog] 标记的提交（默认排除）", Value: redant.BoolOf(&x.KeepSkipped)},
+	}
+}
+
+func generateEntries(ctx context.Context, repoRoot string, opts generateOptions) (generateResult, error) {
+	return changelog.Generate(ctx, repoRoot, opts)
+}
+
+// enrichEntries 运行配置的增强流水线；单个增强器失败只提示，条目保持原样
+func enrichEntries(ctx context.Context, repoRoot string, result *generateResult, w io.Writer) error {
+	cfgs, ticketCfgs := loadEnrichers(ctx)
+	if len(cfgs) == 0 || len(result.Entries) == 0 {
+		return nil
+	}
+	pipeline, err := buildPipeline(ctx, repoRoot, cfgs, ticketCfgs)
+	if err != nil {
+		return err
+	}
+	entries, errs := pipeline.Apply(ctx, result.Entries)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	for _, err := range errs {
+		_, _ = fmt.Fprintf(w, "enricher warning: %v\n", err)
+	}
+	result.Entries = entries
+	result.Sections = result.Layout.Render(result.Layout.Group(entries))
+	return nil
+}
+
+// writeGeneratedSections replaces the standard sections of Unreleased.md and keeps the meta sections.
+func writeGeneratedSections(path string, result generateResult) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	generated := foldSections(result.Layout, result.Sections)
+	sections := parseAllSections(string(content))
+	for _, title := range standardSections {
+		if body, ok := generated[title]; ok {
+			sections[title] = body
+		}
+	}
+	return os.WriteFile(path, []byte(renderUnreleasedWithMeta(sections)), 0o644)
+}
+
+// foldSections 把 changelog.sections 配置的自定义段落并入「变更」，Unreleased.md 只有固定的标准段落
+func foldSections(layout changelog.Layout, generated map[string]string) map[string]string {
+	out := make(map[string]string, len(standardSections))
+	var extra []string
+	for _, title := range layout.Titles() {
+		body := generated[title]
+		if containsString(standardSections, title) {
+			out[title] = body
+			continue
+		}
+		if body != "" && body != "暂无" {
+			extra = append(extra, body)
+		}
+	}
+	if len(extra) > 0 {
+		if body := normalizeSectionBody(out[changelog.SectionChanged]); body != "暂无" {
+			extra = append([]string{body}, extra...)
+		}
+		out[changelog.SectionChanged] = strings.Join(extra, "\n")
+	}
+	return out
+}
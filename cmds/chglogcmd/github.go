@@ -0,0 +1,102 @@
+package chglogcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/pubgo/redant"
+)
+
+// githubOutputs 是 --github-summary / --comment-pr：把 changelog 交给 GitHub Actions 的 Step Summary 或 PR 评论
+type githubOutputs struct {
+	Summary   bool
+	CommentPR int64
+}
+
+func (o *githubOutputs) options() redant.OptionSet {
+	return redant.OptionSet{
+		{Flag: "github-summary", Description: "同时追加到 $GITHUB_STEP_SUMMARY（GitHub Actions 的 Step Summary）", Value: redant.BoolOf(&o.Summary), Default: "false"},
+		{Flag: "comment-pr", Description: "同时以评论发布到指定编号的 PR（使用 gh CLI，Actions 中需设置 GH_TOKEN）", Value: redant.Int64Of(&o.CommentPR)},
+	}
+}
+
+func (o githubOutputs) enabled() bool { return o.Summary || o.CommentPR > 0 }
+
+// publish 输出到选定的 GitHub 目标；两个目标互不影响，全部尝试后再汇总错误
+func (o githubOutputs) publish(ctx context.Context, repoRoot, markdown string, w io.Writer) error {
+	var errs []error
+	if o.Summary {
+		path, err := writeStepSummary(markdown)
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			_, _ = fmt.Fprintf(w, "step summary: %s\n", path)
+		}
+	}
+	if o.CommentPR > 0 {
+		url, err := commentPR(ctx, repoRoot, o.CommentPR, markdown)
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			_, _ = fmt.Fprintf(w, "commented: %s\n", url)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// writeStepSummary 追加到 $GITHUB_STEP_SUMMARY，同一 job 中多个步骤的输出依次拼接
+func writeStepSummary(markdown string) (string, error) {
+	path := strings.TrimSpace(os.Getenv("GITHUB_STEP_SUMMARY"))
+	if path == "" {
+		return "", errors.New("--github-summary: $GITHUB_STEP_SUMMARY is not set, run it in a GitHub Actions step")
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return "", fmt.Errorf("open step summary: %w", err)
+	}
+	if _, err := f.WriteString(strings.TrimSpace(markdown) + "\n\n"); err != nil {
+		_ = f.Close()
+		return "", fmt.Errorf("write step summary: %w", err)
+	}
+	return path, f.Close()
+}
+
+// commentPR 通过 gh pr comment 发布评论，正文走 stdin，不受命令行长度限制
+func commentPR(ctx context.Context, repoRoot string, number int64, markdown string) (string, error) {
+	if _, err := exec.LookPath("gh"); err != nil {
+		return "", fmt.Errorf("--comment-pr needs the gh CLI: %w\nInstall: https://cli.github.com/", err)
+	}
+	cmd := exec.CommandContext(ctx, "gh", "pr", "comment", strconv.FormatInt(number, 10), "--body-file", "-")
+	cmd.Dir = repoRoot
+	cmd.Stdin = strings.NewReader(markdown)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("gh pr comment %d: %w\n%s", number, err, strings.TrimSpace(string(out)))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// generatedMarkdown 把 generate 的结果渲染为 markdown，省略没有条目的段落
+func generatedMarkdown(result generateResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## Changelog (%s)\n", result.Range)
+	empty := true
+	for _, title := range result.Layout.Titles() {
+		body := result.Sections[title]
+		if body == "" || body == "暂无" {
+			continue
+		}
+		empty = false
+		fmt.Fprintf(&b, "\n### %s\n\n%s\n", title, body)
+	}
+	if empty {
+		b.WriteString("\n暂无变更\n")
+	}
+	return b.String()
+}
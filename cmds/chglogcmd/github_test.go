@@ -0,0 +1,41 @@
+package chglogcmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pubgo/fastgit/pkg/changelog"
+)
+
+func TestGeneratedMarkdownSkipsEmptySections(t *testing.T) {
+	entries := []changelog.ChangelogEntry{{Type: "feat", Subject: "add login", Hash: "0123456789"}}
+	md := generatedMarkdown(generateResult{Range: "v1.0.0..HEAD", Sections: changelog.Render(changelog.Group(entries))})
+	require.Contains(t, md, "## Changelog (v1.0.0..HEAD)")
+	require.Contains(t, md, "### 新增\n\n- add login (0123456)")
+	require.NotContains(t, md, "暂无")
+
+	empty := generatedMarkdown(generateResult{Range: "HEAD", Sections: changelog.Render(nil)})
+	require.Contains(t, empty, "暂无变更")
+}
+
+func TestPublishStepSummary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.md")
+	require.NoError(t, os.WriteFile(path, []byte("# build\n\n"), 0o644))
+	t.Setenv("GITHUB_STEP_SUMMARY", path)
+
+	var out bytes.Buffer
+	require.NoError(t, githubOutputs{Summary: true}.publish(context.Background(), t.TempDir(), "## Changelog\n", &out))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "# build\n\n## Changelog\n\n", string(data))
+	require.Contains(t, out.String(), "step summary: "+path)
+
+	t.Setenv("GITHUB_STEP_SUMMARY", "")
+	err = githubOutputs{Summary: true}.publish(context.Background(), t.TempDir(), "x", &out)
+	require.ErrorContains(t, err, "GITHUB_STEP_SUMMARY is not set")
+}
@@ -0,0 +1,81 @@
+package chglogcmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pubgo/redant"
+
+	"github.com/pubgo/fastgit/pkg/changelog"
+)
+
+// runAll 按相邻 tag 逐个版本生成 changelog，输出完整的 CHANGELOG.md；output 为空时写到 stdout。
+// update 时合并进已有文件：只替换 Unreleased、补上缺少的版本
+func runAll(ctx context.Context, inv *redant.Invocation, repoRoot string, opts generateOptions, output string, update, enrich bool, gh githubOutputs) error {
+	releases, err := changelog.GenerateAll(ctx, repoRoot, opts)
+	if err != nil {
+		return err
+	}
+	if len(releases) == 0 {
+		_, _ = fmt.Fprintln(inv.Stderr, "no commits found")
+		return nil
+	}
+
+	commits := 0
+	for i := range releases {
+		if enrich {
+			if err := enrichEntries(ctx, repoRoot, &releases[i].Result, inv.Stderr); err != nil {
+				return err
+			}
+		}
+		commits += releases[i].Stats.Total
+	}
+	_, _ = fmt.Fprintf(inv.Stderr, "%d releases, %d commits\n", len(releases), commits)
+
+	markdown := changelog.RenderReleases(releases)
+	if gh.enabled() {
+		if err := gh.publish(ctx, repoRoot, markdown, inv.Stderr); err != nil {
+			return err
+		}
+	}
+	if update {
+		return updateChangelog(inv, changelogPath(repoRoot, output), releases)
+	}
+	if output == "" {
+		_, _ = fmt.Fprint(inv.Stdout, markdown)
+		return nil
+	}
+	if err := os.WriteFile(output, []byte(markdown), 0o644); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(inv.Stdout, "updated: %s\n", output)
+	return nil
+}
+
+// changelogPath 返回 --update 写入的文件，缺省为仓库根目录的 CHANGELOG.md
+func changelogPath(repoRoot, output string) string {
+	if output = strings.TrimSpace(output); output != "" {
+		return output
+	}
+	return filepath.Join(repoRoot, "CHANGELOG.md")
+}
+
+// unreleasedRelease 把单个范围的生成结果作为一个版本段落：--to 为 HEAD 时记为 Unreleased，否则以 --to 命名
+func unreleasedRelease(result generateResult, to string) changelog.Release {
+	version := strings.TrimSpace(to)
+	if version == "" || version == "HEAD" {
+		version = changelog.Unreleased
+	}
+	return changelog.Release{Version: version, Result: result}
+}
+
+func updateChangelog(inv *redant.Invocation, path string, releases []changelog.Release) error {
+	if err := changelog.UpdateFile(path, releases); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(inv.Stdout, "updated: %s\n", path)
+	return nil
+}
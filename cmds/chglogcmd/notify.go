@@ -0,0 +1,34 @@
+package chglogcmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/pubgo/dix/v2"
+	"github.com/pubgo/dix/v2/dixcontext"
+	"github.com/pubgo/fastgit/pkg/notify"
+	"github.com/pubgo/redant"
+)
+
+type notifyParams struct {
+	NotifyCfg []*notify.Config
+}
+
+// notifyRelease 将刚落版的 changelog 推送到 config 中配置的 webhook/email，失败只提示不回滚
+func notifyRelease(ctx context.Context, inv *redant.Invocation, repoRoot string, result releaseResult) {
+	di := dixcontext.GetOrNil(ctx)
+	if di == nil {
+		return
+	}
+	params := dix.Inject(di, notifyParams{})
+
+	rel := notify.Release{
+		Repo:      filepath.Base(repoRoot),
+		Version:   result.Version,
+		Changelog: result.Content,
+	}
+	if err := notify.Send(ctx, params.NotifyCfg, rel); err != nil {
+		_, _ = fmt.Fprintf(inv.Stderr, "notify: %v\n", err)
+	}
+}
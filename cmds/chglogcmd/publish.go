@@ -0,0 +1,175 @@
+package chglogcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-version"
+	"github.com/pubgo/redant"
+
+	"github.com/pubgo/fastgit/pkg/changelog"
+	"github.com/pubgo/fastgit/utils"
+	"github.com/pubgo/fastgit/utils/githubclient"
+)
+
+func newPublishCommand() *redant.Command {
+	var (
+		repoPath   string
+		tag        string
+		from       string
+		scopes     []string
+		exclude    []string
+		exclusions changelog.Exclusions
+		paths      []string
+		tagPrefix  string
+		title      string
+		prerelease bool
+		draft      bool
+		tplPath    string
+		noEnrich   bool
+		dryRun     bool
+		token      string
+	)
+
+	return &redant.Command{
+		Use:      "publish",
+		Short:    "把 tag 的 changelog 发布为 GitHub Release：不存在时创建，已存在时更新（内容未变则跳过）",
+		Long:     "发布说明为上一个 v* tag 到目标 tag 之间的提交生成的 changelog，重复执行是幂等的；需要 GITHUB_TOKEN / GH_TOKEN。",
+		Metadata: utils.NoTTYMetadata(),
+		Options: append(redant.OptionSet{
+			{Flag: "repo", Description: "目标仓库目录（默认当前目录）", Value: redant.StringOf(&repoPath)},
+			{Flag: "tag", Description: "要发布的 tag，缺省为 HEAD 可达的最新 v*（或 <tag-prefix>v*）tag", Value: redant.StringOf(&tag)},
+			{Flag: "from", Description: "起始 ref（不含），缺省为目标 tag 之前的 v* tag；没有时为全部历史", Value: redant.StringOf(&from)},
+			{Flag: "scope", Description: "只保留 conventional scope 匹配的条目，同 generate --scope", Value: redant.StringArrayOf(&scopes)},
+			{Flag: "scopes", Description: "同 --scope", Value: redant.StringArrayOf(&scopes), Hidden: true, Deprecated: "use --scope"},
+			{Flag: "exclude-scope", Description: "排除 scope 匹配的条目，同 generate --exclude-scope", Value: redant.StringArrayOf(&exclude)},
+			{Flag: "path", Description: "只统计改动这些路径的提交，同 generate --path", Value: redant.StringArrayOf(&paths)},
+			{Flag: "tag-prefix", Description: "包的 tag 前缀，如 foo/ 时 --tag 与 --from 缺省取 foo/v* tag", Value: redant.StringOf(&tagPrefix)},
+			{Flag: "title", Description: "release 标题，缺省为 tag", Value: redant.StringOf(&title)},
+			{Flag: "prerelease", Description: "标记为预发布；tag 带预发布后缀（如 v1.2.0-rc.1）时自动标记", Value: redant.BoolOf(&prerelease)},
+			{Flag: "draft", Description: "创建或保持为草稿", Value: redant.BoolOf(&draft)},
+			{Flag: "template", Description: "用 Go text/template 文件渲染发布说明，数据同 generate --template", Value: redant.StringOf(&tplPath)},
+			{Flag: "no-enrich", Description: "跳过 config.yaml 中 changelog.enrichers 配置的条目增强流水线", Value: redant.BoolOf(&noEnrich)},
+			{Flag: "dry-run", Description: "只输出将要发布的标题与说明，不访问 GitHub", Value: redant.BoolOf(&dryRun)},
+			{Flag: "token", Description: "GitHub Token，缺省读取 GITHUB_TOKEN / GH_TOKEN", Value: redant.StringOf(&token), Envs: []string{"GITHUB_TOKEN", "GH_TOKEN"}},
+		}, exclusionOptions(&exclusions)...),
+		Handler: func(ctx context.Context, inv *redant.Invocation) error {
+			repoRoot, err := resolveExistingGitRepo(strings.TrimSpace(repoPath))
+			if err != nil {
+				return err
+			}
+			pathspecs, err := resolveDraftPathspecs(repoRoot, strings.Join(paths, ","))
+			if err != nil {
+				return err
+			}
+			layout, err := sectionLayout(ctx)
+			if err != nil {
+				return err
+			}
+
+			tagPrefix = strings.TrimSpace(tagPrefix)
+			tag = strings.TrimSpace(tag)
+			if tag == "" {
+				if tag, err = gitOutput(ctx, repoRoot, "describe", "--tags", "--abbrev=0", "--match", tagPrefix+"v*", "HEAD"); err != nil {
+					return fmt.Errorf("no %sv* tag reachable from HEAD, pass --tag", tagPrefix)
+				}
+			}
+			if _, err := gitOutput(ctx, repoRoot, "rev-parse", "--verify", "--quiet", "refs/tags/"+tag); err != nil {
+				return fmt.Errorf("tag %s does not exist", tag)
+			}
+			opts := changelog.Options{From: strings.TrimSpace(from), To: tag, Scopes: scopes, ExcludeScopes: exclude, Exclude: exclusions, Paths: pathspecs, TagPrefix: tagPrefix, Layout: layout}
+			if opts.From == "" {
+				// 目标 tag 之前的 tag；第一个 tag 没有前驱，包含全部历史
+				opts.From, _ = gitOutput(ctx, repoRoot, "describe", "--tags", "--abbrev=0", "--match", tagPrefix+"v*", tag+"^")
+				opts.FullHistory = opts.From == ""
+			}
+
+			notes, err := releaseNotes(ctx, inv, repoRoot, opts, tplPath, !noEnrich)
+			if err != nil {
+				return err
+			}
+			release := githubclient.ReleaseNotes{
+				Tag:        tag,
+				Name:       strings.TrimSpace(title),
+				Body:       notes,
+				Prerelease: prerelease || isPrerelease(strings.TrimPrefix(tag, tagPrefix)),
+				Draft:      draft,
+			}
+			if release.Name == "" {
+				release.Name = tag
+			}
+			if release.Commit, err = gitOutput(ctx, repoRoot, "rev-parse", tag+"^{commit}"); err != nil {
+				return err
+			}
+
+			if dryRun {
+				_, _ = fmt.Fprintf(inv.Stdout, "# %s (prerelease: %t, draft: %t)\n\n%s", release.Name, release.Prerelease, release.Draft, release.Body)
+				return nil
+			}
+
+			remote, err := gitOutput(ctx, repoRoot, "remote", "get-url", "origin")
+			if err != nil {
+				return fmt.Errorf("publish needs an origin remote: %w", err)
+			}
+			owner, repo, err := githubRepo(remote)
+			if err != nil {
+				return err
+			}
+			if strings.TrimSpace(token) == "" {
+				return errors.New("publish needs a GitHub token: pass --token or set GITHUB_TOKEN / GH_TOKEN")
+			}
+			// 未推送的 tag 会被 GitHub 在默认分支上新建，先确认远端已有该 tag
+			if remoteTag, err := gitOutput(ctx, repoRoot, "ls-remote", "--tags", "origin", "refs/tags/"+tag); err != nil {
+				return fmt.Errorf("check tag %s on origin: %w", tag, err)
+			} else if remoteTag == "" {
+				return fmt.Errorf("tag %s is not on origin, push it first: git push origin %s", tag, tag)
+			}
+
+			published, action, err := githubclient.NewReleasePublisher(owner, repo, strings.TrimSpace(token), configuredProxy(ctx)).Publish(ctx, release)
+			if err != nil {
+				return fmt.Errorf("publish release %s: %w", tag, err)
+			}
+			_, _ = fmt.Fprintf(inv.Stdout, "%s: %s\n", action, published.GetHTMLURL())
+			return nil
+		},
+	}
+}
+
+// releaseNotes 生成 opts 范围的发布说明：有模板时按模板渲染，否则为各非空段落
+func releaseNotes(ctx context.Context, inv *redant.Invocation, repoRoot string, opts changelog.Options, tplPath string, enrich bool) (string, error) {
+	result, err := generateEntries(ctx, repoRoot, opts)
+	if err != nil {
+		return "", err
+	}
+	_, _ = fmt.Fprintf(inv.Stderr, "range: %s (%d commits)\n", result.Range, result.Stats.Total)
+	if enrich {
+		if err := enrichEntries(ctx, repoRoot, &result, inv.Stderr); err != nil {
+			return "", err
+		}
+	}
+	if tplPath != "" {
+		return changelog.RenderTemplateFile(tplPath, changelog.NewChangelog(result, time.Now()))
+	}
+
+	var b strings.Builder
+	for _, title := range result.Layout.Titles() {
+		body := result.Sections[title]
+		if body == "" || body == "暂无" {
+			continue
+		}
+		fmt.Fprintf(&b, "### %s\n\n%s\n\n", title, body)
+	}
+	if b.Len() == 0 {
+		return "暂无变更\n", nil
+	}
+	return strings.TrimRight(b.String(), "\n") + "\n", nil
+}
+
+// isPrerelease 报告 tag 是否为带预发布后缀的语义化版本
+func isPrerelease(tag string) bool {
+	v, err := version.NewSemver(tag)
+	return err == nil && v.Prerelease() != ""
+}
@@ -0,0 +1,250 @@
+package chglogcmd
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/pubgo/fastgit/pkg/aiprovider"
+	"github.com/pubgo/fastgit/pkg/changelog"
+)
+
+// entryTypes 是 t 键循环切换的类型，依次对应 新增/修复/变更/文档
+var entryTypes = []string{"feat", "fix", "refactor", "docs"}
+
+const rewordSystemPrompt = `Rewrite the changelog entry as one concise, user-facing sentence describing the change.
+Keep the language of the input. Return only the sentence, without a type prefix, scope, markdown or trailing period.`
+
+var (
+	reviewTitleStyle   = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39"))
+	reviewCursorStyle  = lipgloss.NewStyle().Bold(true)
+	reviewDroppedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Strikethrough(true)
+	reviewHelpStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+	reviewStatusStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+)
+
+type reviewItem struct {
+	entry   changelog.ChangelogEntry
+	dropped bool
+}
+
+type rewordDoneMsg struct {
+	index int
+	text  string
+	err   error
+}
+
+// rewordFunc 用 LLM 改写一条条目的描述
+type rewordFunc func(ctx context.Context, e changelog.ChangelogEntry) (string, error)
+
+type reviewModel struct {
+	ctx     context.Context
+	items   []*reviewItem
+	cursor  int
+	height  int
+	input   textinput.Model
+	editing bool
+	busy    bool
+	status  string
+	aborted bool
+	reword  rewordFunc
+	layout  changelog.Layout
+}
+
+func newReviewModel(ctx context.Context, layout changelog.Layout, entries []changelog.ChangelogEntry, reword rewordFunc) *reviewModel {
+	m := &reviewModel{ctx: ctx, reword: reword, layout: layout, height: 20, input: textinput.New()}
+	m.input.Prompt = "subject: "
+	for _, e := range entries {
+		m.items = append(m.items, &reviewItem{entry: e})
+	}
+	return m
+}
+
+// reviewEntries 在 TUI 中逐条确认 changelog 条目：丢弃、改类型、手动或用 LLM 改写；返回保留的条目，放弃时 ok 为 false
+func reviewEntries(ctx context.Context, layout changelog.Layout, entries []changelog.ChangelogEntry, reword rewordFunc) ([]changelog.ChangelogEntry, bool, error) {
+	if len(entries) == 0 {
+		return entries, true, nil
+	}
+	m := newReviewModel(ctx, layout, entries, reword)
+	if _, err := tea.NewProgram(m, tea.WithAltScreen(), tea.WithContext(ctx)).Run(); err != nil {
+		return nil, false, fmt.Errorf("run changelog review: %w", err)
+	}
+	if m.aborted {
+		return nil, false, nil
+	}
+	return m.kept(), true, nil
+}
+
+// providerReword 返回按需解析 AI 提供方的改写函数，首次按 r 时才解析
+func providerReword(name, repoRoot string) rewordFunc {
+	var provider aiprovider.Provider
+	return func(ctx context.Context, e changelog.ChangelogEntry) (string, error) {
+		if provider == nil {
+			provider = aiprovider.ResolveProvider(name, repoRoot)
+		}
+		user := fmt.Sprintf("type: %s\nscope: %s\nentry: %s", defaultString(e.Type, "(none)"), defaultString(e.Scope, "(none)"), e.Subject)
+		text, ok, err := aiprovider.EnhanceText(ctx, provider, rewordSystemPrompt, user, e.Subject)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			return "", fmt.Errorf("no AI provider available, see `fastgit doctor`")
+		}
+		return strings.TrimSuffix(strings.TrimSpace(strings.SplitN(text, "\n", 2)[0]), "."), nil
+	}
+}
+
+func (m *reviewModel) kept() []changelog.ChangelogEntry {
+	var out []changelog.ChangelogEntry
+	for _, it := range m.items {
+		if !it.dropped {
+			out = append(out, it.entry)
+		}
+	}
+	return out
+}
+
+func (m *reviewModel) Init() tea.Cmd { return nil }
+
+func (m *reviewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = max(msg.Height-5, 3)
+	case rewordDoneMsg:
+		m.busy = false
+		if msg.err != nil {
+			m.status = "reword failed: " + msg.err.Error()
+			break
+		}
+		m.items[msg.index].entry.Subject = msg.text
+		m.status = "reworded"
+	case tea.KeyMsg:
+		if m.editing {
+			return m.handleEditKey(msg)
+		}
+		return m.handleKey(msg)
+	}
+	return m, nil
+}
+
+func (m *reviewModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.status = ""
+	cur := m.items[m.cursor]
+	switch msg.String() {
+	case "ctrl+c", "esc":
+		m.aborted = true
+		return m, tea.Quit
+	case "q", "enter":
+		return m, tea.Quit
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.items)-1 {
+			m.cursor++
+		}
+	case "d", "x", " ":
+		cur.dropped = !cur.dropped
+	case "t":
+		cur.entry.Type = nextEntryType(cur.entry.Type)
+		m.status = "section: " + m.layout.Section(cur.entry)
+	case "e":
+		m.editing = true
+		m.input.SetValue(cur.entry.Subject)
+		m.input.CursorEnd()
+		return m, m.input.Focus()
+	case "r":
+		if m.busy {
+			m.status = "a reword is already running"
+			break
+		}
+		if m.reword == nil {
+			m.status = "LLM reword is not available"
+			break
+		}
+		m.busy = true
+		m.status = "rewording with AI..."
+		index, entry := m.cursor, cur.entry
+		return m, func() tea.Msg {
+			text, err := m.reword(m.ctx, entry)
+			return rewordDoneMsg{index: index, text: text, err: err}
+		}
+	}
+	return m, nil
+}
+
+func (m *reviewModel) handleEditKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.editing = false
+		m.input.Blur()
+		return m, nil
+	case "enter":
+		m.editing = false
+		m.input.Blur()
+		if text := strings.TrimSpace(m.input.Value()); text != "" {
+			m.items[m.cursor].entry.Subject = text
+		}
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m *reviewModel) View() string {
+	var b strings.Builder
+	dropped := 0
+	for _, it := range m.items {
+		if it.dropped {
+			dropped++
+		}
+	}
+	b.WriteString(reviewTitleStyle.Render(fmt.Sprintf("Review changelog entries — %d kept, %d dropped", len(m.items)-dropped, dropped)))
+	b.WriteString("\n\n")
+
+	start := 0
+	if m.cursor >= m.height {
+		start = m.cursor - m.height + 1
+	}
+	end := min(start+m.height, len(m.items))
+	for i := start; i < end; i++ {
+		it := m.items[i]
+		line := fmt.Sprintf("[%s] %s", m.layout.Section(it.entry), strings.TrimPrefix(it.entry.Line(), "- "))
+		switch {
+		case it.dropped:
+			line = reviewDroppedStyle.Render(line)
+		case i == m.cursor:
+			line = reviewCursorStyle.Render(line)
+		}
+		marker := "  "
+		if i == m.cursor {
+			marker = "> "
+		}
+		b.WriteString(marker + line + "\n")
+	}
+
+	b.WriteString("\n")
+	if m.editing {
+		b.WriteString(m.input.View() + "\n")
+		b.WriteString(reviewHelpStyle.Render("enter save · esc cancel"))
+		return b.String()
+	}
+	if m.status != "" {
+		b.WriteString(reviewStatusStyle.Render(m.status) + "\n")
+	}
+	b.WriteString(reviewHelpStyle.Render("↑/↓ move · d drop/keep · t change type · e edit · r reword with AI · enter write · esc abort"))
+	return b.String()
+}
+
+// nextEntryType 按 entryTypes 循环切换；不在列表中的类型（chore 等）切到第一个
+func nextEntryType(current string) string {
+	i := slices.Index(entryTypes, current)
+	return entryTypes[(i+1)%len(entryTypes)]
+}
@@ -0,0 +1,65 @@
+package chglogcmd
+
+import (
+	"context"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pubgo/fastgit/pkg/changelog"
+)
+
+func TestReviewModel(t *testing.T) {
+	entries := []changelog.ChangelogEntry{
+		{Hash: "aaaaaaa1", Type: "feat", Subject: "add login"},
+		{Hash: "bbbbbbb2", Type: "chore", Subject: "bump deps"},
+		{Hash: "ccccccc3", Type: "fix", Subject: "fix typo"},
+	}
+	reword := func(_ context.Context, e changelog.ChangelogEntry) (string, error) {
+		return "Users can sign in with SSO", nil
+	}
+	m := newReviewModel(context.Background(), changelog.Layout{}, entries, reword)
+	key := func(s string) tea.Cmd {
+		var msg tea.KeyMsg
+		switch s {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "down":
+			msg = tea.KeyMsg{Type: tea.KeyDown}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+		}
+		_, cmd := m.Update(msg)
+		return cmd
+	}
+
+	// 第一条用 AI 改写
+	cmd := key("r")
+	require.NotNil(t, cmd)
+	m.Update(cmd())
+	// 第二条丢弃
+	key("down")
+	key("d")
+	// 第三条改为文档并手动改写
+	key("down")
+	key("t")
+	key("t")
+	key("e")
+	m.input.SetValue("document the typo fix")
+	key("enter")
+
+	kept := m.kept()
+	require.Len(t, kept, 2)
+	require.Equal(t, "Users can sign in with SSO", kept[0].Subject)
+	require.Equal(t, "docs", kept[1].Type)
+	require.Equal(t, changelog.SectionDocs, kept[1].Section())
+	require.Equal(t, "document the typo fix", kept[1].Subject)
+	require.False(t, m.aborted)
+}
+
+func TestNextEntryType(t *testing.T) {
+	require.Equal(t, "feat", nextEntryType("chore"))
+	require.Equal(t, "fix", nextEntryType("feat"))
+	require.Equal(t, "feat", nextEntryType("docs"))
+}
@@ -0,0 +1,262 @@
+package chglogcmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pubgo/fastgit/pkg/changelog"
+)
+
+// singleChange 是 --commit / --pr 选中的一个变更：标题条目、说明正文与其包含的提交
+type singleChange struct {
+	// Headline 为提交或 PR 标题对应的条目，普通 merge 提交没有
+	Headline *changelog.ChangelogEntry
+	URL      string
+	Body     string
+	Commits  []changelog.ChangelogEntry
+	// Layout 为渲染时使用的段落布局
+	Layout changelog.Layout
+}
+
+// mergePRPattern 匹配 GitHub 的 merge 提交标题：Merge pull request #42 from owner/branch
+var mergePRPattern = regexp.MustCompile(`^Merge pull request #(\d+) `)
+
+// runSingle 输出单个提交或 PR 的 changelog 条目与说明，用于 backport 说明与热修复公告
+func runSingle(ctx context.Context, w io.Writer, repoRoot string, layout changelog.Layout, commit string, pr int, enrich bool, gh githubOutputs) error {
+	var (
+		change singleChange
+		err    error
+	)
+	if pr > 0 {
+		change, err = prChange(ctx, repoRoot, pr)
+	} else {
+		change, err = commitChange(ctx, repoRoot, commit)
+	}
+	if err != nil {
+		return err
+	}
+	change.Layout = layout
+
+	if enrich && len(change.Commits) > 0 {
+		result := generateResult{Entries: change.Commits, Layout: layout}
+		if err := enrichEntries(ctx, repoRoot, &result, w); err != nil {
+			return err
+		}
+		change.Commits = result.Entries
+	}
+
+	markdown := singleMarkdown(change)
+	if gh.enabled() {
+		if err := gh.publish(ctx, repoRoot, markdown, w); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprint(w, markdown)
+	return err
+}
+
+// commitChange 读取单个提交；merge 提交列出其合入的提交，GitHub PR 的 merge 提交以 PR 标题作为标题条目
+func commitChange(ctx context.Context, repoRoot, rev string) (singleChange, error) {
+	res, err := generateEntries(ctx, repoRoot, generateOptions{Commit: rev})
+	if err != nil {
+		return singleChange{}, err
+	}
+	out, err := exec.CommandContext(ctx, "git", "-C", repoRoot, "show", "-s", "--format=%H%x00%an%x00%aI%x00%B", rev+"^{commit}").Output()
+	if err != nil {
+		return singleChange{}, fmt.Errorf("git show %s: %w", rev, err)
+	}
+	fields := strings.SplitN(string(out), "\x00", 4)
+	if len(fields) != 4 {
+		return singleChange{}, fmt.Errorf("unexpected git show output for %s", rev)
+	}
+	date, _ := time.Parse(time.RFC3339, fields[2])
+	subject, body, _ := strings.Cut(strings.TrimSpace(fields[3]), "\n")
+	body = strings.TrimSpace(body)
+
+	// merge 提交本身不作为条目
+	var entries []changelog.ChangelogEntry
+	for _, e := range res.Entries {
+		if e.Type != "" || !strings.HasPrefix(e.Subject, "Merge ") {
+			entries = append(entries, e)
+		}
+	}
+	res.Entries = entries
+
+	change := singleChange{Body: body}
+	switch m := mergePRPattern.FindStringSubmatch(subject); {
+	case m != nil:
+		// GitHub 把 PR 标题写在 merge 提交正文的第一行
+		title, rest, _ := strings.Cut(body, "\n")
+		entry := changelog.ParseCommit(fields[0], title, fields[1], date)
+		entry.PR, _ = strconv.Atoi(m[1])
+		change.Headline, change.Body, change.Commits = &entry, strings.TrimSpace(rest), res.Entries
+	case strings.HasSuffix(res.Range, "^!"):
+		if len(res.Entries) == 1 {
+			change.Headline = &res.Entries[0]
+		}
+	default:
+		change.Commits = res.Entries
+	}
+	return change, nil
+}
+
+// prDetails 是 gh pr view --json 输出中用到的字段
+type prDetails struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+	URL    string `json:"url"`
+	Author struct {
+		Login string `json:"login"`
+	} `json:"author"`
+	MergedAt  string `json:"mergedAt"`
+	CreatedAt string `json:"createdAt"`
+	Commits   []struct {
+		Oid             string    `json:"oid"`
+		MessageHeadline string    `json:"messageHeadline"`
+		MessageBody     string    `json:"messageBody"`
+		AuthoredDate    time.Time `json:"authoredDate"`
+		Authors         []struct {
+			Name  string `json:"name"`
+			Login string `json:"login"`
+		} `json:"authors"`
+	} `json:"commits"`
+}
+
+// prChange 通过 gh 读取 PR 的标题、描述与提交；没有 gh 或查询失败时在本地历史中查找该 PR 的 merge/squash 提交
+func prChange(ctx context.Context, repoRoot string, number int) (singleChange, error) {
+	details, ghErr := viewPR(ctx, repoRoot, number)
+	if ghErr == nil {
+		return details.change(), nil
+	}
+
+	sha, err := findPRCommit(ctx, repoRoot, number)
+	if err != nil {
+		return singleChange{}, errors.Join(ghErr, err)
+	}
+	change, err := commitChange(ctx, repoRoot, sha)
+	if err != nil {
+		return singleChange{}, err
+	}
+	if change.Headline != nil {
+		// squash 提交标题已带 (#N)，由 PR 字段统一渲染
+		change.Headline.Subject = strings.TrimSpace(strings.TrimSuffix(change.Headline.Subject, fmt.Sprintf("(#%d)", number)))
+		change.Headline.PR = number
+	}
+	return change, nil
+}
+
+func viewPR(ctx context.Context, repoRoot string, number int) (prDetails, error) {
+	var details prDetails
+	if _, err := exec.LookPath("gh"); err != nil {
+		return details, fmt.Errorf("gh CLI not found: %w", err)
+	}
+	cmd := exec.CommandContext(ctx, "gh", "pr", "view", strconv.Itoa(number),
+		"--json", "number,title,body,url,author,mergedAt,createdAt,commits")
+	cmd.Dir = repoRoot
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return details, fmt.Errorf("gh pr view %d: %w: %s", number, err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return details, fmt.Errorf("gh pr view %d: %w", number, err)
+	}
+	if err := json.Unmarshal(out, &details); err != nil {
+		return details, fmt.Errorf("parse gh pr view output: %w", err)
+	}
+	return details, nil
+}
+
+func (d prDetails) change() singleChange {
+	date, err := time.Parse(time.RFC3339, d.MergedAt)
+	if err != nil {
+		date, _ = time.Parse(time.RFC3339, d.CreatedAt)
+	}
+	headline := changelog.ParseCommit("", d.Title, d.Author.Login, date)
+	headline.PR = d.Number
+
+	change := singleChange{Headline: &headline, URL: d.URL, Body: strings.TrimSpace(d.Body)}
+	for _, c := range d.Commits {
+		author := ""
+		if len(c.Authors) > 0 {
+			author = c.Authors[0].Name
+		}
+		message := c.MessageHeadline
+		if body := strings.TrimSpace(c.MessageBody); body != "" {
+			message += "\n\n" + body
+		}
+		change.Commits = append(change.Commits, changelog.ParseCommit(c.Oid, message, author, c.AuthoredDate))
+	}
+	return change
+}
+
+// findPRCommit 在 HEAD 的历史中查找 "Merge pull request #N " 或以 "(#N)" 结尾的 squash 提交
+func findPRCommit(ctx context.Context, repoRoot string, number int) (string, error) {
+	out, err := exec.CommandContext(ctx, "git", "-C", repoRoot, "log", "--format=%H%x00%s", "HEAD").Output()
+	if err != nil {
+		return "", fmt.Errorf("git log: %w", err)
+	}
+	ref := "#" + strconv.Itoa(number)
+	for _, line := range strings.Split(string(out), "\n") {
+		sha, subject, ok := strings.Cut(line, "\x00")
+		if !ok {
+			continue
+		}
+		if strings.HasPrefix(subject, "Merge pull request "+ref+" ") || strings.HasSuffix(strings.TrimSpace(subject), "("+ref+")") {
+			return sha, nil
+		}
+	}
+	return "", fmt.Errorf("no merge or squash commit for PR %s in the history of HEAD", ref)
+}
+
+// singleMarkdown 渲染为：所属段落与条目、链接、说明正文，以及包含的提交
+func singleMarkdown(c singleChange) string {
+	var b strings.Builder
+	if c.Headline != nil {
+		if section := c.Layout.Section(*c.Headline); section != "" {
+			fmt.Fprintf(&b, "### %s\n\n", section)
+		}
+		b.WriteString(c.Headline.Line() + "\n")
+	}
+	if c.URL != "" {
+		fmt.Fprintf(&b, "\n%s\n", c.URL)
+	}
+	if c.Body != "" {
+		fmt.Fprintf(&b, "\n%s\n", c.Body)
+	}
+
+	groups := c.Layout.Group(c.Commits)
+	if c.Headline == nil {
+		// 没有标题条目时按段落列出全部提交
+		for _, title := range c.Layout.Titles() {
+			if len(groups[title]) == 0 {
+				continue
+			}
+			if b.Len() > 0 {
+				b.WriteString("\n")
+			}
+			fmt.Fprintf(&b, "### %s\n\n", title)
+			for _, e := range groups[title] {
+				b.WriteString(e.Line() + "\n")
+			}
+		}
+	} else if len(c.Commits) > 1 {
+		b.WriteString("\n#### 提交\n\n")
+		for _, e := range c.Commits {
+			b.WriteString(e.Line() + "\n")
+		}
+	}
+	if b.Len() == 0 {
+		b.WriteString("暂无变更\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,51 @@
+package chglogcmd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pubgo/fastgit/pkg/changelog"
+)
+
+func TestPRDetailsChange(t *testing.T) {
+	raw := `{
+		"number": 42,
+		"title": "fix(api): handle empty body",
+		"body": "Closes #40.\r\n",
+		"url": "https://github.com/o/r/pull/42",
+		"author": {"login": "alice"},
+		"mergedAt": "2026-01-02T03:04:05Z",
+		"commits": [
+			{"oid": "0123456789abcdef", "messageHeadline": "fix(api): nil check", "messageBody": "", "authoredDate": "2026-01-01T00:00:00Z", "authors": [{"name": "Alice"}]},
+			{"oid": "fedcba9876543210", "messageHeadline": "test: cover empty body", "messageBody": "Refs: ABC-1", "authoredDate": "2026-01-01T00:00:00Z"}
+		]
+	}`
+	var details prDetails
+	require.NoError(t, json.Unmarshal([]byte(raw), &details))
+
+	change := details.change()
+	require.NotNil(t, change.Headline)
+	require.Equal(t, "fix", change.Headline.Type)
+	require.Equal(t, 42, change.Headline.PR)
+	require.Equal(t, "alice", change.Headline.Author)
+	require.Equal(t, "Closes #40.", change.Body)
+	require.Len(t, change.Commits, 2)
+	require.Equal(t, "Alice", change.Commits[0].Author)
+	require.Equal(t, []string{"ABC-1"}, change.Commits[1].Refs)
+
+	require.Equal(t, "### 修复\n\n- **api**: handle empty body (#42)\n\n"+
+		"https://github.com/o/r/pull/42\n\nCloses #40.\n\n"+
+		"#### 提交\n\n- **api**: nil check (0123456)\n- cover empty body (fedcba9)\n", singleMarkdown(change))
+}
+
+func TestSingleMarkdownWithoutHeadline(t *testing.T) {
+	md := singleMarkdown(singleChange{Commits: []changelog.ChangelogEntry{
+		{Type: "fix", Subject: "b", Hash: "1111111111"},
+		{Type: "feat", Subject: "a", Hash: "2222222222"},
+	}})
+	require.Equal(t, "### 新增\n\n- a (2222222)\n\n### 修复\n\n- b (1111111)\n", md)
+
+	require.Equal(t, "暂无变更\n", singleMarkdown(singleChange{}))
+}
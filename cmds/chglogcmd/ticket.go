@@ -0,0 +1,43 @@
+package chglogcmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/pubgo/dix/v2"
+	"github.com/pubgo/dix/v2/dixcontext"
+
+	"github.com/pubgo/fastgit/pkg/ticket"
+)
+
+type ticketParams struct {
+	TicketCfg []*ticket.Config
+}
+
+// lookupDraftTicket 查询当前分支关联的工单，供 draft prompt 标注条目来源
+func lookupDraftTicket(ctx context.Context, repoRoot string, w io.Writer) *ticket.Ticket {
+	di := dixcontext.GetOrNil(ctx)
+	if di == nil {
+		return nil
+	}
+	params := dix.Inject(di, ticketParams{})
+
+	branch, err := gitOutput(ctx, repoRoot, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return nil
+	}
+	tk, err := ticket.Lookup(ctx, params.TicketCfg, branch)
+	if err != nil {
+		_, _ = fmt.Fprintf(w, "ticket warning: %v\n", err)
+		return nil
+	}
+	return tk
+}
+
+func appendDraftTicket(prompt string, tk *ticket.Ticket) string {
+	if tk == nil {
+		return prompt
+	}
+	return prompt + fmt.Sprintf("\n关联工单：%s\n请在对应条目末尾标注工单号，例如「(%s)」。\n", tk.Ref(), tk.Key)
+}
@@ -0,0 +1,107 @@
+package cicmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const sampleWorkflow = `name: ci
+env:
+  GOFLAGS: -mod=mod
+  TOKEN: ${{ secrets.TOKEN }}
+jobs:
+  lint:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - name: vet
+        run: go vet ./...
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: go build ./...
+  test:
+    name: unit tests
+    defaults:
+      run:
+        working-directory: sub
+    steps:
+      - run: echo ${{ matrix.go }}
+      - name: test
+        run: |
+          go test ./...
+        env:
+          CGO_ENABLED: "0"
+`
+
+func TestParseWorkflow(t *testing.T) {
+	jobs, err := ParseWorkflow(".github/workflows/ci.yml", []byte(sampleWorkflow))
+	require.NoError(t, err)
+	require.Len(t, jobs, 3)
+	require.Equal(t, []string{"lint", "build", "test"}, []string{jobs[0].ID, jobs[1].ID, jobs[2].ID})
+
+	lint := jobs[0]
+	require.Equal(t, map[string]string{"GOFLAGS": "-mod=mod"}, lint.Env)
+	require.Equal(t, "uses: actions/checkout@v4", lint.Steps[0].SkipReason)
+	require.Equal(t, "go vet ./...", lint.Steps[1].Run)
+
+	test := jobs[2]
+	require.Equal(t, "unit tests", test.Name)
+	require.Contains(t, test.Steps[0].SkipReason, "${{ }}")
+	require.Equal(t, "sub", test.Steps[1].WorkingDirectory)
+	require.Equal(t, "0", test.Steps[1].Env["CGO_ENABLED"])
+
+	selected := SelectJobs(jobs, nil)
+	require.Len(t, selected, 2)
+	require.Equal(t, "build", SelectJobs(jobs, []string{"build"})[0].ID)
+}
+
+func TestConfigCommandsOverrideWorkflow(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".fastgit"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".fastgit", "ci.yaml"), []byte("commit: true\ncommands:\n  - name: hello\n    run: echo hello\n  - run: exit 3\n  - run: echo unreachable\n"), 0o644))
+
+	cfg := LoadConfig(dir)
+	require.True(t, cfg.Commit)
+	plan, err := cfg.Plan(dir, nil)
+	require.NoError(t, err)
+	require.Len(t, plan, 1)
+	require.Len(t, plan[0].Steps, 3)
+
+	var out strings.Builder
+	results, err := Run(context.Background(), plan, RunOptions{RepoRoot: dir, Output: &out})
+	require.Error(t, err)
+	require.Contains(t, out.String(), "hello")
+	require.NoError(t, results[0].Err)
+	require.Error(t, results[1].Err)
+	require.True(t, results[2].Skipped)
+
+	summary := FormatSummary(results)
+	require.Contains(t, summary, "1 passed, 1 failed, 1 skipped")
+}
+
+func TestRunUsesWorkingDirectoryAndEnv(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "sub"), 0o755))
+	jobs := []Job{{
+		Name: "env",
+		Env:  map[string]string{"FROM_JOB": "job"},
+		Steps: []Step{{
+			Name:             "write",
+			Run:              `echo "$FROM_JOB-$FROM_STEP-$CI" > out.txt`,
+			WorkingDirectory: "sub",
+			Env:              map[string]string{"FROM_STEP": "step"},
+		}},
+	}}
+
+	_, err := Run(context.Background(), jobs, RunOptions{RepoRoot: dir})
+	require.NoError(t, err)
+	data, err := os.ReadFile(filepath.Join(dir, "sub", "out.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "job-step-true\n", string(data))
+}
@@ -0,0 +1,96 @@
+package cicmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pubgo/redant"
+)
+
+// New creates the local CI simulation command group.
+func New() *redant.Command {
+	var (
+		jobs   []string
+		dryRun bool
+	)
+
+	return &redant.Command{
+		Use:   "ci",
+		Short: "本地模拟 CI：解析 GitHub Actions 工作流，在 push 前运行 lint/test",
+		Long:  "默认解析 .github/workflows 中名称含 lint/test/check 的 job；可在 .fastgit/ci.yaml 中指定 workflows/jobs，或用 commands 直接配置命令列表。",
+		Children: []*redant.Command{
+			{
+				Use:   "run",
+				Short: "按顺序执行选中的 job，并输出汇总",
+				Options: redant.OptionSet{
+					{Flag: "job", Description: "只运行指定 job（id 或 name，可重复）", Value: redant.StringArrayOf(&jobs)},
+					{Flag: "dry-run", Description: "只列出将执行的命令", Value: redant.BoolOf(&dryRun)},
+				},
+				Handler: func(ctx context.Context, inv *redant.Invocation) error {
+					repoRoot, err := os.Getwd()
+					if err != nil {
+						return err
+					}
+
+					plan, err := LoadConfig(repoRoot).Plan(repoRoot, jobs)
+					if err != nil {
+						return err
+					}
+					if len(plan) == 0 {
+						_, _ = fmt.Fprintln(inv.Stdout, "no ci jobs selected; configure .fastgit/ci.yaml or pass --job")
+						return nil
+					}
+
+					results, err := Run(ctx, plan, RunOptions{RepoRoot: repoRoot, DryRun: dryRun, Output: inv.Stdout})
+					_, _ = fmt.Fprintln(inv.Stdout, "\n"+FormatSummary(results))
+					return err
+				},
+			},
+			{
+				Use:   "list",
+				Short: "列出解析到的 job 与步骤",
+				Handler: func(ctx context.Context, inv *redant.Invocation) error {
+					repoRoot, err := os.Getwd()
+					if err != nil {
+						return err
+					}
+
+					cfg := LoadConfig(repoRoot)
+					plan, err := cfg.Plan(repoRoot, nil)
+					if err != nil {
+						return err
+					}
+					selected := make(map[string]bool, len(plan))
+					for _, job := range plan {
+						selected[job.Workflow+"/"+job.ID] = true
+					}
+
+					all := plan
+					if len(cfg.Commands) == 0 {
+						if all, err = LoadWorkflowJobs(repoRoot, cfg.Workflows); err != nil {
+							return err
+						}
+					}
+					for _, job := range all {
+						mark := " "
+						if selected[job.Workflow+"/"+job.ID] {
+							mark = "*"
+						}
+						_, _ = fmt.Fprintf(inv.Stdout, "%s %s (%s)\n", mark, job.Name, job.Workflow)
+						for _, step := range job.Steps {
+							if step.SkipReason != "" {
+								_, _ = fmt.Fprintf(inv.Stdout, "    - %s [skip: %s]\n", step.Name, step.SkipReason)
+								continue
+							}
+							_, _ = fmt.Fprintf(inv.Stdout, "    - %s: %s\n", step.Name, strings.ReplaceAll(step.Run, "\n", "; "))
+						}
+					}
+					_, _ = fmt.Fprintln(inv.Stdout, "(* = selected by default)")
+					return nil
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,69 @@
+package cicmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is `.fastgit/ci.yaml`.
+type Config struct {
+	// Workflows limits parsing to these files (default: all of .github/workflows).
+	Workflows []string `yaml:"workflows"`
+	// Jobs selects workflow jobs by id or name (default: lint/test-like jobs).
+	Jobs []string `yaml:"jobs"`
+	// Commands replaces workflow parsing with an explicit command list.
+	Commands []Command `yaml:"commands"`
+	// Commit runs `ci run` as a gate inside `fastgit commit`.
+	Commit bool `yaml:"commit"`
+}
+
+// Command is one entry of the configured command list.
+type Command struct {
+	Name             string            `yaml:"name"`
+	Run              string            `yaml:"run"`
+	WorkingDirectory string            `yaml:"working-directory"`
+	Env              map[string]string `yaml:"env"`
+}
+
+// LoadConfig reads `.fastgit/ci.yaml`; a missing or invalid file yields the zero config.
+func LoadConfig(repoRoot string) Config {
+	var cfg Config
+	data, err := os.ReadFile(filepath.Join(repoRoot, ".fastgit", "ci.yaml"))
+	if err != nil {
+		return cfg
+	}
+	_ = yaml.Unmarshal(data, &cfg)
+	return cfg
+}
+
+// Plan resolves the jobs to run: configured commands win over workflow parsing.
+func (c Config) Plan(repoRoot string, jobNames []string) ([]Job, error) {
+	if len(c.Commands) > 0 {
+		job := Job{ID: "commands", Name: "commands", Workflow: ".fastgit/ci.yaml"}
+		for _, cmd := range c.Commands {
+			run := strings.TrimSpace(cmd.Run)
+			if run == "" {
+				continue
+			}
+			job.Steps = append(job.Steps, Step{
+				Name:             firstNonEmpty(cmd.Name, firstLine(run)),
+				Run:              run,
+				WorkingDirectory: cmd.WorkingDirectory,
+				Env:              cmd.Env,
+			})
+		}
+		return []Job{job}, nil
+	}
+
+	jobs, err := LoadWorkflowJobs(repoRoot, c.Workflows)
+	if err != nil {
+		return nil, err
+	}
+	if len(jobNames) == 0 {
+		jobNames = c.Jobs
+	}
+	return SelectJobs(jobs, jobNames), nil
+}
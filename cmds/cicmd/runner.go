@@ -0,0 +1,133 @@
+package cicmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// RunOptions controls local CI execution.
+type RunOptions struct {
+	RepoRoot string
+	DryRun   bool
+	// Output receives live step output; nil discards it.
+	Output io.Writer
+}
+
+// StepResult is the outcome of one step.
+type StepResult struct {
+	Job      string
+	Step     string
+	Skipped  bool
+	Reason   string
+	Err      error
+	Duration time.Duration
+}
+
+// Run executes jobs in order; a failing step stops its job but other jobs still run.
+func Run(ctx context.Context, jobs []Job, opts RunOptions) ([]StepResult, error) {
+	out := opts.Output
+	if out == nil {
+		out = io.Discard
+	}
+
+	var results []StepResult
+	failed := 0
+	for _, job := range jobs {
+		_, _ = fmt.Fprintf(out, "==> %s (%s)\n", job.Name, job.Workflow)
+		jobFailed := false
+		for _, step := range job.Steps {
+			res := StepResult{Job: job.Name, Step: step.Name}
+			switch {
+			case jobFailed:
+				res.Skipped, res.Reason = true, "previous step failed"
+			case step.SkipReason != "":
+				res.Skipped, res.Reason = true, step.SkipReason
+			case opts.DryRun:
+				res.Skipped, res.Reason = true, "dry-run"
+				_, _ = fmt.Fprintf(out, "[dry-run] %s: %s\n", step.Name, step.Run)
+			default:
+				_, _ = fmt.Fprintf(out, "--> %s\n", step.Name)
+				start := time.Now()
+				res.Err = runStep(ctx, opts.RepoRoot, job, step, out)
+				res.Duration = time.Since(start)
+				if res.Err != nil {
+					jobFailed = true
+				}
+			}
+			results = append(results, res)
+		}
+		if jobFailed {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return results, fmt.Errorf("%d of %d ci jobs failed", failed, len(jobs))
+	}
+	return results, nil
+}
+
+func runStep(ctx context.Context, repoRoot string, job Job, step Step, out io.Writer) error {
+	name, args := shellCommand(step.Shell, step.Run)
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = repoRoot
+	if step.WorkingDirectory != "" {
+		cmd.Dir = filepath.Join(repoRoot, step.WorkingDirectory)
+	}
+	cmd.Env = append(os.Environ(), "CI=true", "FASTGIT_CI=true")
+	for k, v := range mergeEnv(job.Env, step.Env) {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	cmd.Stdout = out
+	cmd.Stderr = out
+	return cmd.Run()
+}
+
+// shellCommand mirrors the GitHub Actions default of `bash -e`, falling back to sh.
+func shellCommand(shell, script string) (string, []string) {
+	var name string
+	if fields := strings.Fields(shell); len(fields) > 0 {
+		name = fields[0]
+	}
+	switch name {
+	case "sh":
+		return "sh", []string{"-e", "-c", script}
+	case "pwsh", "powershell":
+		return name, []string{"-Command", script}
+	case "python":
+		return "python", []string{"-c", script}
+	}
+	if _, err := exec.LookPath("bash"); err == nil {
+		return "bash", []string{"--noprofile", "--norc", "-eo", "pipefail", "-c", script}
+	}
+	return "sh", []string{"-e", "-c", script}
+}
+
+// FormatSummary renders a one-line-per-step gate summary.
+func FormatSummary(results []StepResult) string {
+	var b strings.Builder
+	passed, failed, skipped := 0, 0, 0
+	for _, r := range results {
+		status := "ok  "
+		detail := r.Duration.Round(time.Millisecond).String()
+		switch {
+		case r.Err != nil:
+			status, detail = "FAIL", r.Err.Error()
+			failed++
+		case r.Skipped:
+			status, detail = "skip", r.Reason
+			skipped++
+		default:
+			passed++
+		}
+		fmt.Fprintf(&b, "  %s %s / %s (%s)\n", status, r.Job, r.Step, detail)
+	}
+	fmt.Fprintf(&b, "ci summary: %d passed, %d failed, %d skipped", passed, failed, skipped)
+	return b.String()
+}
@@ -0,0 +1,214 @@
+package cicmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultJobPattern 未配置 jobs 时，只挑选看起来是 lint/test 的 job
+var defaultJobPattern = regexp.MustCompile(`(?i)lint|test|check|vet|fmt|format`)
+
+// Step is one runnable command of a job.
+type Step struct {
+	Name             string
+	Run              string
+	Shell            string
+	WorkingDirectory string
+	Env              map[string]string
+	// SkipReason is set for steps that cannot run locally (`uses:` actions, `${{ }}` expressions).
+	SkipReason string
+}
+
+// Job is a named list of steps, from a workflow job or `.fastgit/ci.yaml` commands.
+type Job struct {
+	ID       string
+	Name     string
+	Workflow string
+	Env      map[string]string
+	Steps    []Step
+}
+
+type workflowFile struct {
+	Name     string            `yaml:"name"`
+	Env      map[string]string `yaml:"env"`
+	Defaults workflowDefaults  `yaml:"defaults"`
+	Jobs     yaml.Node         `yaml:"jobs"`
+}
+
+type workflowDefaults struct {
+	Run struct {
+		Shell            string `yaml:"shell"`
+		WorkingDirectory string `yaml:"working-directory"`
+	} `yaml:"run"`
+}
+
+type workflowJob struct {
+	Name     string            `yaml:"name"`
+	Env      map[string]string `yaml:"env"`
+	Defaults workflowDefaults  `yaml:"defaults"`
+	Steps    []workflowStep    `yaml:"steps"`
+}
+
+type workflowStep struct {
+	Name             string            `yaml:"name"`
+	Run              string            `yaml:"run"`
+	Uses             string            `yaml:"uses"`
+	Shell            string            `yaml:"shell"`
+	WorkingDirectory string            `yaml:"working-directory"`
+	Env              map[string]string `yaml:"env"`
+}
+
+// ParseWorkflow converts a GitHub Actions workflow into jobs, preserving job order.
+func ParseWorkflow(path string, data []byte) ([]Job, error) {
+	var wf workflowFile
+	if err := yaml.Unmarshal(data, &wf); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if wf.Jobs.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	var jobs []Job
+	for i := 0; i+1 < len(wf.Jobs.Content); i += 2 {
+		id := wf.Jobs.Content[i].Value
+		var wj workflowJob
+		if err := wf.Jobs.Content[i+1].Decode(&wj); err != nil {
+			return nil, fmt.Errorf("parse %s job %s: %w", path, id, err)
+		}
+
+		job := Job{
+			ID:       id,
+			Name:     wj.Name,
+			Workflow: path,
+			Env:      mergeEnv(literalEnv(wf.Env), literalEnv(wj.Env)),
+		}
+		if job.Name == "" || strings.Contains(job.Name, "${{") {
+			job.Name = id
+		}
+
+		for idx, ws := range wj.Steps {
+			step := Step{
+				Name:             ws.Name,
+				Run:              strings.TrimSpace(ws.Run),
+				Shell:            firstNonEmpty(ws.Shell, wj.Defaults.Run.Shell, wf.Defaults.Run.Shell),
+				WorkingDirectory: firstNonEmpty(ws.WorkingDirectory, wj.Defaults.Run.WorkingDirectory, wf.Defaults.Run.WorkingDirectory),
+				Env:              literalEnv(ws.Env),
+			}
+			switch {
+			case ws.Uses != "":
+				step.SkipReason = "uses: " + ws.Uses
+				if step.Name == "" {
+					step.Name = ws.Uses
+				}
+			case step.Run == "":
+				step.SkipReason = "no run command"
+			case strings.Contains(step.Run, "${{"):
+				step.SkipReason = "uses ${{ }} expressions"
+			case strings.Contains(step.WorkingDirectory, "${{"):
+				step.SkipReason = "working-directory uses ${{ }} expressions"
+			}
+			if step.Name == "" {
+				step.Name = firstLine(step.Run)
+			}
+			if step.Name == "" {
+				step.Name = fmt.Sprintf("step %d", idx+1)
+			}
+			job.Steps = append(job.Steps, step)
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// LoadWorkflowJobs reads the given workflow files, or every `.github/workflows/*.y*ml` when none are given.
+func LoadWorkflowJobs(repoRoot string, files []string) ([]Job, error) {
+	if len(files) == 0 {
+		for _, pattern := range []string{"*.yml", "*.yaml"} {
+			matches, _ := filepath.Glob(filepath.Join(repoRoot, ".github", "workflows", pattern))
+			files = append(files, matches...)
+		}
+		sort.Strings(files)
+	}
+
+	var jobs []Job
+	for _, file := range files {
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(repoRoot, file)
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		rel, err := filepath.Rel(repoRoot, file)
+		if err != nil {
+			rel = file
+		}
+		parsed, err := ParseWorkflow(filepath.ToSlash(rel), data)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, parsed...)
+	}
+	return jobs, nil
+}
+
+// SelectJobs keeps jobs whose id or name is in names; without names it keeps lint/test-like jobs.
+func SelectJobs(jobs []Job, names []string) []Job {
+	var selected []Job
+	for _, job := range jobs {
+		if len(names) == 0 {
+			if defaultJobPattern.MatchString(job.ID) || defaultJobPattern.MatchString(job.Name) {
+				selected = append(selected, job)
+			}
+			continue
+		}
+		for _, name := range names {
+			if name = strings.TrimSpace(name); name == job.ID || name == job.Name {
+				selected = append(selected, job)
+				break
+			}
+		}
+	}
+	return selected
+}
+
+// literalEnv drops values that depend on `${{ }}` expressions, which cannot be evaluated locally.
+func literalEnv(env map[string]string) map[string]string {
+	out := make(map[string]string, len(env))
+	for k, v := range env {
+		if !strings.Contains(v, "${{") {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func mergeEnv(envs ...map[string]string) map[string]string {
+	out := make(map[string]string)
+	for _, env := range envs {
+		for k, v := range env {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(line)
+}
@@ -0,0 +1,107 @@
+package comparecmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pubgo/funk/v2/log"
+	"github.com/pubgo/redant"
+
+	"github.com/pubgo/fastgit/pkg/aiprovider"
+	"github.com/pubgo/fastgit/pkg/gitshell"
+	"github.com/pubgo/fastgit/pkg/timing"
+	"github.com/pubgo/fastgit/utils"
+)
+
+const summarySystemPrompt = `You summarize what a git branch changes compared with its base, for a reviewer about to open a pull request.
+
+Write markdown:
+- one sentence on the overall purpose
+- 3-6 bullets grouping the changes by theme (features, fixes, refactors, tests, docs)
+- a "Risks" bullet only if the diff touches something risky (migrations, public APIs, config, security)
+
+Do not invent changes that are not in the commits or diff. Keep it under 150 words.`
+
+// New creates the compare command.
+func New() *redant.Command {
+	var (
+		base       string
+		head       string
+		noAI       bool
+		aiProvider string
+		jsonOut    bool
+	)
+
+	return &redant.Command{
+		Use:   "compare <base> [head]",
+		Short: "对比两个分支：各自独有的提交、diffstat、冲突预测与 AI 变更摘要",
+		Long: "开 PR 前查看 head（默认 HEAD）相对 base 改了什么：双方独有的提交、合并 diffstat（base...head）、" +
+			"git merge-tree 预测的冲突文件，以及 AI 生成的分支摘要。不改动工作区、暂存区与引用。",
+		Metadata: utils.NoTTYMetadata(),
+		Args: redant.ArgSet{
+			{Name: "base", Description: "对比基准，如 main、origin/main", Value: redant.StringOf(&base)},
+			{Name: "head", Description: "要对比的分支，默认 HEAD", Value: redant.StringOf(&head)},
+		},
+		Options: redant.OptionSet{
+			{Flag: "no-ai", Description: "不生成 AI 摘要", Value: redant.BoolOf(&noAI)},
+			{Flag: "ai-provider", Description: "AI 提供方 auto|openai|gemini|anthropic|ollama|copilot", Value: redant.StringOf(&aiProvider), Default: "auto"},
+			{Flag: "json", Description: "以 JSON 输出对比结果", Value: redant.BoolOf(&jsonOut)},
+		},
+		Handler: func(ctx context.Context, inv *redant.Invocation) error {
+			if base == "" {
+				return redant.DefaultHelpFn()(ctx, inv)
+			}
+			if head == "" {
+				head = "HEAD"
+			}
+
+			root, err := gitshell.Output(ctx, ".", "rev-parse", "--show-toplevel")
+			if err != nil {
+				return fmt.Errorf("not in a git repository: %w", err)
+			}
+			root = strings.TrimSpace(root)
+
+			done := timing.Track(ctx, timing.PhaseGit, "compare branches")
+			res, err := Compare(ctx, root, base, head)
+			done()
+			if err != nil {
+				return err
+			}
+
+			if !noAI && len(res.Ahead) > 0 {
+				res.Summary = summarize(ctx, root, aiProvider, res)
+			}
+
+			if jsonOut {
+				enc := json.NewEncoder(inv.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(res)
+			}
+			render(inv.Stdout, res)
+			return nil
+		},
+	}
+}
+
+// summarize 生成分支摘要；AI 不可用或失败时返回空，报告其余部分照常输出
+func summarize(ctx context.Context, root, providerName string, res *Result) string {
+	input, err := summaryInput(ctx, root, res)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to read the branch diff, skipping the summary")
+		return ""
+	}
+
+	provider := aiprovider.ResolveProvider(providerName, root)
+	done := timing.Track(ctx, timing.PhaseLLM, "summarize branch")
+	text, ok, err := aiprovider.EnhanceText(ctx, provider, summarySystemPrompt, input, "")
+	done()
+	if err != nil {
+		log.Warn().Err(err).Msg("AI summary failed")
+	}
+	if !ok {
+		return ""
+	}
+	return text
+}
@@ -0,0 +1,204 @@
+package comparecmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/pubgo/fastgit/pkg/gitdiff"
+	"github.com/pubgo/fastgit/pkg/gitshell"
+)
+
+const fieldSep = "\x1f"
+
+// maxSummaryDiff 限制交给 AI 的 diff 大小，超出部分截断
+const maxSummaryDiff = 24000
+
+// Commit 只在一侧存在的提交
+type Commit struct {
+	Hash    string `json:"hash"`
+	Subject string `json:"subject"`
+	Author  string `json:"author"`
+}
+
+// Result 是 base 与 head 的对比结果
+type Result struct {
+	Base      string            `json:"base"`
+	Head      string            `json:"head"`
+	MergeBase string            `json:"merge_base"`
+	Ahead     []Commit          `json:"ahead"`
+	Behind    []Commit          `json:"behind"`
+	Files     []gitdiff.NumStat `json:"files"`
+	// Conflicts 为 nil 表示 git 不支持 merge-tree --write-tree（< 2.38），无法预测
+	Conflicts []string `json:"conflicts"`
+	Summary   string   `json:"summary,omitempty"`
+}
+
+// Compare 收集 head 相对 base 的提交、diffstat 与合并冲突预测；不改动工作区与引用
+func Compare(ctx context.Context, dir, base, head string) (*Result, error) {
+	for _, ref := range []string{base, head} {
+		if _, err := gitshell.Output(ctx, dir, "rev-parse", "--verify", "--quiet", ref+"^{commit}"); err != nil {
+			return nil, fmt.Errorf("unknown revision %q", ref)
+		}
+	}
+
+	res := &Result{Base: base, Head: head}
+	mb, err := gitshell.Output(ctx, dir, "merge-base", base, head)
+	if err != nil {
+		return nil, fmt.Errorf("%s and %s have no common history: %w", base, head, err)
+	}
+	res.MergeBase = strings.TrimSpace(mb)
+
+	if res.Ahead, err = loadCommits(ctx, dir, base+".."+head); err != nil {
+		return nil, err
+	}
+	if res.Behind, err = loadCommits(ctx, dir, head+".."+base); err != nil {
+		return nil, err
+	}
+
+	numstat, err := gitshell.Output(ctx, dir, "diff", "--numstat", base+"..."+head)
+	if err != nil {
+		return nil, err
+	}
+	res.Files = gitdiff.ParseNumstat(numstat)
+
+	res.Conflicts, err = predictConflicts(ctx, dir, base, head)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func loadCommits(ctx context.Context, dir, rangeSpec string) ([]Commit, error) {
+	out, err := gitshell.Output(ctx, dir, "log", "--no-merges", "--format=%h"+fieldSep+"%an"+fieldSep+"%s", rangeSpec)
+	if err != nil {
+		return nil, err
+	}
+	var commits []Commit
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.SplitN(strings.TrimSpace(line), fieldSep, 3)
+		if len(parts) < 3 {
+			continue
+		}
+		commits = append(commits, Commit{Hash: parts[0], Author: parts[1], Subject: parts[2]})
+	}
+	return commits, nil
+}
+
+// predictConflicts 用 git merge-tree --write-tree 在内存中试合并，返回会冲突的文件；
+// 退出码 1 表示有冲突，旧版 git 不支持时返回 nil
+func predictConflicts(ctx context.Context, dir, base, head string) ([]string, error) {
+	cmd := exec.CommandContext(ctx, "git", "merge-tree", "--write-tree", "--name-only", "--no-messages", base, head)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return []string{}, nil
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == 1:
+		return parseMergeTree(string(out)), nil
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == 129:
+		return nil, nil
+	}
+	return nil, fmt.Errorf("git merge-tree: %w", err)
+}
+
+// parseMergeTree 解析 merge-tree --name-only 输出：首行是结果 tree，之后每行一个冲突文件
+func parseMergeTree(output string) []string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	files := []string{}
+	seen := map[string]bool{}
+	for _, line := range lines[1:] {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+		if !seen[line] {
+			seen[line] = true
+			files = append(files, line)
+		}
+	}
+	return files
+}
+
+// summaryInput 拼出交给 AI 的内容：提交列表、diffstat 与截断后的 diff
+func summaryInput(ctx context.Context, dir string, res *Result) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Branch %s compared with %s.\n\nCommits on the branch:\n", res.Head, res.Base)
+	for _, c := range res.Ahead {
+		fmt.Fprintf(&b, "- %s\n", c.Subject)
+	}
+	b.WriteString("\nFiles:\n")
+	for _, f := range res.Files {
+		fmt.Fprintf(&b, "- %s %s\n", f.Path, statText(f))
+	}
+
+	diff, err := gitshell.Output(ctx, dir, "diff", res.Base+"..."+res.Head)
+	if err != nil {
+		return "", err
+	}
+	if len(diff) > maxSummaryDiff {
+		diff = diff[:maxSummaryDiff] + fmt.Sprintf("\n... (truncated %d bytes)\n", len(diff)-maxSummaryDiff)
+	}
+	b.WriteString("\nDiff:\n")
+	b.WriteString(diff)
+	return b.String(), nil
+}
+
+// render 输出人读的对比报告
+func render(w io.Writer, res *Result) {
+	_, _ = fmt.Fprintf(w, "# %s...%s (merge base %s)\n\n", res.Base, res.Head, gitshell.ShortHash(res.MergeBase))
+
+	renderCommits(w, fmt.Sprintf("Only in %s", res.Head), res.Ahead)
+	renderCommits(w, fmt.Sprintf("Only in %s", res.Base), res.Behind)
+
+	add, del := 0, 0
+	for _, f := range res.Files {
+		add += max(f.Additions, 0)
+		del += max(f.Deletions, 0)
+	}
+	_, _ = fmt.Fprintf(w, "## Diffstat: %d files, +%d -%d\n\n", len(res.Files), add, del)
+	for _, f := range res.Files {
+		_, _ = fmt.Fprintf(w, "- %s %s\n", f.Path, statText(f))
+	}
+	if len(res.Files) > 0 {
+		_, _ = fmt.Fprintln(w)
+	}
+
+	switch {
+	case res.Conflicts == nil:
+		_, _ = fmt.Fprint(w, "## Conflicts\n\nunknown, conflict prediction needs git >= 2.38\n\n")
+	case len(res.Conflicts) == 0:
+		_, _ = fmt.Fprintf(w, "## Conflicts\n\nnone, %s merges cleanly into %s\n\n", res.Head, res.Base)
+	default:
+		_, _ = fmt.Fprintf(w, "## Conflicts: %d files\n\n", len(res.Conflicts))
+		for _, f := range res.Conflicts {
+			_, _ = fmt.Fprintf(w, "- %s\n", f)
+		}
+		_, _ = fmt.Fprintln(w)
+	}
+
+	if res.Summary != "" {
+		_, _ = fmt.Fprintf(w, "## Summary\n\n%s\n", res.Summary)
+	}
+}
+
+func renderCommits(w io.Writer, title string, commits []Commit) {
+	_, _ = fmt.Fprintf(w, "## %s: %d commits\n\n", title, len(commits))
+	for _, c := range commits {
+		_, _ = fmt.Fprintf(w, "- %s %s (%s)\n", c.Hash, c.Subject, c.Author)
+	}
+	if len(commits) > 0 {
+		_, _ = fmt.Fprintln(w)
+	}
+}
+
+func statText(f gitdiff.NumStat) string {
+	if f.Binary() {
+		return "(binary)"
+	}
+	return fmt.Sprintf("+%d -%d", f.Additions, f.Deletions)
+}
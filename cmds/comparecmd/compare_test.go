@@ -0,0 +1,77 @@
+package comparecmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pubgo/fastgit/pkg/gitdiff"
+)
+
+func TestStatText(t *testing.T) {
+	assert.Equal(t, "+3 -1", statText(gitdiff.NumStat{Path: "main.go", Additions: 3, Deletions: 1}))
+	assert.Equal(t, "(binary)", statText(gitdiff.NumStat{Path: "logo.png", Additions: -1, Deletions: -1}))
+}
+
+func TestParseMergeTree(t *testing.T) {
+	assert.Equal(t, []string{"a.go", "b.go"}, parseMergeTree("4b825dc642cb6eb9a060e54bf8d69288fbee4904\na.go\na.go\nb.go\n"))
+	assert.Empty(t, parseMergeTree("4b825dc642cb6eb9a060e54bf8d69288fbee4904\n"))
+}
+
+func TestCompare(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found in PATH")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_CONFIG_NOSYSTEM=1", "HOME="+dir)
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+
+	git("init", "-b", "main")
+	git("config", "user.email", "compare@example.com")
+	git("config", "user.name", "compare")
+	write("shared.txt", "base\n")
+	git("add", ".")
+	git("commit", "-m", "init")
+	git("checkout", "-b", "feat")
+	write("shared.txt", "feat\n")
+	write("new.txt", "new\n")
+	git("add", ".")
+	git("commit", "-m", "feat: change shared")
+	git("checkout", "main")
+	write("shared.txt", "main\n")
+	git("commit", "-am", "fix: main change")
+
+	res, err := Compare(context.Background(), dir, "main", "feat")
+	require.NoError(t, err)
+	require.Len(t, res.Ahead, 1)
+	assert.Equal(t, "feat: change shared", res.Ahead[0].Subject)
+	require.Len(t, res.Behind, 1)
+	assert.Equal(t, "fix: main change", res.Behind[0].Subject)
+	assert.Len(t, res.Files, 2)
+	if res.Conflicts != nil {
+		assert.Equal(t, []string{"shared.txt"}, res.Conflicts)
+	}
+
+	var out bytes.Buffer
+	render(&out, res)
+	assert.Contains(t, out.String(), "## Only in feat: 1 commits")
+	assert.Contains(t, out.String(), "- new.txt +1 -0")
+
+	_, err = Compare(context.Background(), dir, "missing", "feat")
+	assert.ErrorContains(t, err, `unknown revision "missing"`)
+}
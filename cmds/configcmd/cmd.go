@@ -8,6 +8,7 @@ import (
 	"github.com/a8m/envsubst"
 	"github.com/joho/godotenv"
 	"github.com/pubgo/fastgit/configs"
+	"github.com/pubgo/fastgit/pkg/envcrypt"
 	"github.com/pubgo/fastgit/utils"
 	"github.com/pubgo/funk/v2/assert"
 	"github.com/pubgo/funk/v2/config"
@@ -27,6 +28,7 @@ func New() *redant.Command {
 		Use:   "config",
 		Short: "config management",
 		Children: []*redant.Command{
+			newLocalCommand(),
 			{
 				Use:   "edit",
 				Short: "edit config, env or local env file, args: [config|env|local], default:config",
@@ -34,16 +36,18 @@ func New() *redant.Command {
 					command := i.Command
 					args := command.Args
 					if len(args) == 0 {
-						utils.Edit(configs.GetConfigPath())
-						return nil
+						return utils.Edit(ctx, configs.GetConfigPath())
 					}
 
 					switch args[0].Value.String() {
 					case "config":
-						utils.Edit(configs.GetConfigPath())
+						return utils.Edit(ctx, configs.GetConfigPath())
 					case "env":
-						utils.Edit(configs.GetEnvPath())
+						return utils.Edit(ctx, configs.GetEnvPath())
 					case "local":
+						if encrypted, _ := envcrypt.Find(configs.GetLocalEnvPath()); encrypted != "" {
+							return envcrypt.Edit(configs.GetLocalEnvPath(), func(path string) error { return utils.Edit(ctx, path) })
+						}
 						if pathutil.IsNotExist(configs.GetLocalEnvPath()) {
 							file := assert.Exit1(os.Create(configs.GetLocalEnvPath()))
 							defer func() { _ = file.Close() }()
@@ -54,7 +58,7 @@ func New() *redant.Command {
 								}
 							}
 						}
-						utils.Edit(configs.GetLocalEnvPath())
+						return utils.Edit(ctx, configs.GetLocalEnvPath())
 					}
 
 					return nil
@@ -95,7 +99,7 @@ func New() *redant.Command {
 						pretty.Println(lo.Values(envMap))
 					case "local":
 						log.Info().Msgf("local env path: %s", configs.GetLocalEnvPath())
-						data := result.Wrap(os.ReadFile(configs.GetLocalEnvPath())).Unwrap()
+						data := result.Wrap(envcrypt.Read(configs.GetLocalEnvPath())).Unwrap()
 						dataMap := result.Wrap(godotenv.UnmarshalBytes(data)).Unwrap()
 						pretty.Println(dataMap)
 					}
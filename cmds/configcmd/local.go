@@ -0,0 +1,58 @@
+package configcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/pubgo/redant"
+
+	"github.com/pubgo/fastgit/configs"
+	"github.com/pubgo/fastgit/pkg/envcrypt"
+)
+
+func newLocalCommand() *redant.Command {
+	var (
+		encrypt    bool
+		decrypt    bool
+		method     string
+		recipients []string
+	)
+
+	return &redant.Command{
+		Use:   "local",
+		Short: "encrypt or decrypt the repository local env file (.git/fastgit.env)",
+		Long:  "--encrypt 用 gpg 或 age 加密 .git/fastgit.env 并删除明文，之后启动时透明解密；--decrypt 还原明文。不带 --recipient 时使用口令加密。",
+		Options: redant.OptionSet{
+			{Flag: "encrypt", Description: "encrypt the local env file and remove the plaintext", Value: redant.BoolOf(&encrypt)},
+			{Flag: "decrypt", Description: "restore the plaintext local env file", Value: redant.BoolOf(&decrypt)},
+			{Flag: "method", Description: "encryption tool: gpg|age", Default: envcrypt.MethodGPG, Value: redant.EnumOf(&method, envcrypt.Methods...)},
+			{Flag: "recipient", Description: "gpg key id/email or age public key (repeatable); empty uses a passphrase", Value: redant.StringArrayOf(&recipients)},
+		},
+		Handler: func(ctx context.Context, inv *redant.Invocation) error {
+			plainPath := configs.GetLocalEnvPath()
+			switch {
+			case encrypt && decrypt:
+				return errors.New("--encrypt and --decrypt are mutually exclusive")
+			case encrypt:
+				path, err := envcrypt.Encrypt(plainPath, method, recipients)
+				if err != nil {
+					return err
+				}
+				_, _ = fmt.Fprintf(inv.Stdout, "encrypted %s -> %s, plaintext removed\n", plainPath, path)
+			case decrypt:
+				if err := envcrypt.Decrypt(plainPath); err != nil {
+					return err
+				}
+				_, _ = fmt.Fprintf(inv.Stdout, "decrypted %s\n", plainPath)
+			default:
+				if path, m := envcrypt.Find(plainPath); path != "" {
+					_, _ = fmt.Fprintf(inv.Stdout, "%s (encrypted with %s)\n", path, m)
+				} else {
+					_, _ = fmt.Fprintf(inv.Stdout, "%s (plaintext, run `fastgit config local --encrypt` to encrypt it)\n", plainPath)
+				}
+			}
+			return nil
+		},
+	}
+}
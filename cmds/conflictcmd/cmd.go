@@ -4,11 +4,11 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"strings"
 
 	"github.com/pubgo/fastgit/pkg/aiprovider"
 	"github.com/pubgo/fastgit/pkg/gitconflict"
+	"github.com/pubgo/fastgit/utils"
 	"github.com/pubgo/redant"
 )
 
@@ -17,13 +17,16 @@ func New() *redant.Command {
 	root := &redant.Command{
 		Use:   "conflict",
 		Short: "冲突检测、分组摘要与文件处理",
-		Long:  "在 pull/rebase/merge 冲突时输出结构化摘要，并辅助打开冲突文件。",
+		Long:  "在 pull/rebase/merge 冲突时输出结构化摘要，辅助打开冲突文件，并按当前未完成的操作 continue/abort/skip。",
 	}
 
 	root.Children = []*redant.Command{
 		newSummaryCommand(),
 		newListCommand(),
 		newOpenCommand(),
+		newResumeCommand(gitconflict.ActionContinue, "继续未完成的 merge/rebase/cherry-pick/revert/am"),
+		newResumeCommand(gitconflict.ActionAbort, "放弃未完成的操作，回到开始前的状态"),
+		newResumeCommand(gitconflict.ActionSkip, "跳过当前提交（rebase/cherry-pick/revert/am）"),
 	}
 
 	root.Handler = newSummaryCommand().Handler
@@ -42,7 +45,7 @@ func newSummaryCommand() *redant.Command {
 		Options: redant.OptionSet{
 			{Flag: "repo", Description: "仓库目录（默认当前目录）", Value: redant.StringOf(&repo)},
 			{Flag: "ai", Description: "使用 AI 分析冲突原因（失败时保留启发式建议）", Value: redant.BoolOf(&useAI)},
-			{Flag: "ai-provider", Description: "AI 提供方 auto|openai|copilot", Value: redant.StringOf(&aiProvider), Default: "auto"},
+			{Flag: "ai-provider", Description: "AI 提供方 auto|openai|gemini|anthropic|ollama|copilot", Value: redant.StringOf(&aiProvider), Default: "auto"},
 		},
 		Handler: func(ctx context.Context, inv *redant.Invocation) error {
 			repoRoot, err := resolveRepoRoot(repo)
@@ -60,6 +63,9 @@ func newSummaryCommand() *redant.Command {
 				_, _ = fmt.Fprintln(inv.Stdout, "ai: unavailable, using heuristic reasons")
 			}
 			_, _ = fmt.Fprintln(inv.Stdout, snap.Summary)
+			if state, _ := gitconflict.DetectInProgress(ctx, repoRoot); state != nil {
+				_, _ = fmt.Fprintf(inv.Stdout, "%s: fastgit conflict %s\n", state, strings.Join(state.Actions(), "|"))
+			}
 			if len(snap.Files) > 0 {
 				return fmt.Errorf("%d conflicted file(s) remain", len(snap.Files))
 			}
@@ -101,7 +107,7 @@ func newOpenCommand() *redant.Command {
 	var repo string
 	return &redant.Command{
 		Use:   "open",
-		Short: "在 $EDITOR 中打开全部冲突文件",
+		Short: "在编辑器中打开全部冲突文件（config.yaml 的 editor，其次 $GIT_EDITOR/$VISUAL/$EDITOR）",
 		Options: redant.OptionSet{
 			{Flag: "repo", Description: "仓库目录（默认当前目录）", Value: redant.StringOf(&repo)},
 		},
@@ -119,18 +125,13 @@ func newOpenCommand() *redant.Command {
 				return nil
 			}
 
-			editor := resolveEditor()
 			for _, file := range files {
 				fullPath := file
 				if !strings.HasPrefix(file, "/") {
 					fullPath = strings.TrimRight(repoRoot, "/") + "/" + file
 				}
 				_, _ = fmt.Fprintf(inv.Stdout, "opening %s\n", file)
-				cmd := exec.CommandContext(ctx, editor, fullPath)
-				cmd.Stdin = os.Stdin
-				cmd.Stdout = os.Stdout
-				cmd.Stderr = os.Stderr
-				if err := cmd.Run(); err != nil {
+				if err := utils.EditorCommand(ctx, fullPath).Run(); err != nil {
 					return fmt.Errorf("open %s: %w", file, err)
 				}
 			}
@@ -150,15 +151,3 @@ func resolveRepoRoot(repo string) (string, error) {
 	}
 	return repo, nil
 }
-
-func resolveEditor() string {
-	if e := strings.TrimSpace(os.Getenv("EDITOR")); e != "" {
-		return e
-	}
-	for _, candidate := range []string{"code", "vim", "nano", "vi"} {
-		if _, err := exec.LookPath(candidate); err == nil {
-			return candidate
-		}
-	}
-	return "vi"
-}
@@ -0,0 +1,130 @@
+package conflictcmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/pubgo/redant"
+	"github.com/yarlson/tap"
+
+	"github.com/pubgo/fastgit/pkg/gitconflict"
+	"github.com/pubgo/fastgit/pkg/timing"
+	"github.com/pubgo/fastgit/utils"
+)
+
+const actionCancel = "cancel"
+
+var actionLabels = map[string]string{
+	gitconflict.ActionContinue: "Continue",
+	gitconflict.ActionAbort:    "Abort",
+	gitconflict.ActionSkip:     "Skip this commit",
+}
+
+// HandleInProgress 在 commit/tag/pull 前检查未完成的 merge/rebase/cherry-pick，由用户选择 continue/abort/skip；
+// 返回 nil 表示操作已结束、可以继续执行 command，否则返回带处理提示的错误
+func HandleInProgress(ctx context.Context, repoRoot, command string) error {
+	state, err := gitconflict.DetectInProgress(ctx, repoRoot)
+	if err != nil || state == nil {
+		// 检测失败（如不在仓库中）交给后续命令报告
+		return nil
+	}
+	return resolveInProgress(ctx, repoRoot, command, state)
+}
+
+// HandleInProgressCommit 是提交前的检查：只有仍有未解决的冲突时才需先处理；冲突已解决的 merge
+// （提交即完成合并）、停在 edit 步骤的 rebase 以及已解决的 cherry-pick/revert 照常提交。
+// 返回进行中的操作（没有时为 nil），供调用方决定是否推送
+func HandleInProgressCommit(ctx context.Context, repoRoot string) (*gitconflict.InProgress, error) {
+	state, err := gitconflict.DetectInProgress(ctx, repoRoot)
+	if err != nil || state == nil {
+		return nil, nil
+	}
+	if len(state.Conflicts) == 0 {
+		return state, nil
+	}
+	if err := resolveInProgress(ctx, repoRoot, "committing", state); err != nil {
+		return state, err
+	}
+	state, _ = gitconflict.DetectInProgress(ctx, repoRoot)
+	return state, nil
+}
+
+func resolveInProgress(ctx context.Context, repoRoot, command string, state *gitconflict.InProgress) error {
+	if !term.IsTerminal(os.Stdin.Fd()) || utils.NonInteractive() {
+		return inProgressError(state, command)
+	}
+
+	fmt.Println(state.String())
+	for _, file := range state.Conflicts {
+		fmt.Println("  " + file)
+	}
+	options := make([]tap.SelectOption[string], 0, 4)
+	for _, action := range state.Actions() {
+		// 还有冲突时 continue 必然失败，不提供
+		if action == gitconflict.ActionContinue && len(state.Conflicts) > 0 {
+			continue
+		}
+		options = append(options, tap.SelectOption[string]{
+			Value: action,
+			Label: actionLabels[action],
+			Hint:  fmt.Sprintf("git %s --%s", state.Op, action),
+		})
+	}
+	options = append(options, tap.SelectOption[string]{Value: actionCancel, Label: "Cancel", Hint: "resolve it yourself, see `fastgit conflict`"})
+
+	done := timing.Track(ctx, timing.PhaseUI, "resolve in-progress "+string(state.Op))
+	action := tap.Select[string](ctx, tap.SelectOptions[string]{
+		Message: fmt.Sprintf("Finish the %s before %s?", state.Op, command),
+		Options: options,
+	})
+	done()
+	if action == "" || action == actionCancel {
+		return inProgressError(state, command)
+	}
+	if err := gitconflict.Resume(ctx, repoRoot, state.Op, action); err != nil {
+		return err
+	}
+
+	// rebase 可能在下一个提交再次停下
+	if next, err := gitconflict.DetectInProgress(ctx, repoRoot); err == nil && next != nil {
+		return inProgressError(next, command)
+	}
+	return nil
+}
+
+func inProgressError(state *gitconflict.InProgress, command string) error {
+	hint := "resolve conflicts, `git add` them, then run `fastgit conflict continue`"
+	if len(state.Conflicts) == 0 {
+		hint = "run `fastgit conflict continue`"
+	}
+	return fmt.Errorf("%s: finish it before %s\nhint: %s, or `fastgit conflict abort` to give up", state, command, hint)
+}
+
+// newResumeCommand 创建 continue/abort/skip 子命令，按当前未完成的操作调用对应的 git 命令
+func newResumeCommand(action, short string) *redant.Command {
+	var repo string
+	return &redant.Command{
+		Use:   action,
+		Short: short,
+		Options: redant.OptionSet{
+			{Flag: "repo", Description: "仓库目录（默认当前目录）", Value: redant.StringOf(&repo)},
+		},
+		Handler: func(ctx context.Context, inv *redant.Invocation) error {
+			repoRoot, err := resolveRepoRoot(repo)
+			if err != nil {
+				return err
+			}
+			state, err := gitconflict.DetectInProgress(ctx, repoRoot)
+			if err != nil {
+				return err
+			}
+			if state == nil {
+				_, _ = fmt.Fprintln(inv.Stdout, "no merge/rebase/cherry-pick in progress")
+				return nil
+			}
+			return gitconflict.Resume(ctx, repoRoot, state.Op, action)
+		},
+	}
+}
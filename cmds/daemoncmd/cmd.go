@@ -0,0 +1,132 @@
+package daemoncmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+
+	"github.com/pubgo/fastgit/configs"
+	"github.com/pubgo/fastgit/pkg/daemon"
+	"github.com/pubgo/funk/v2/errors"
+	"github.com/pubgo/redant"
+)
+
+func New() *redant.Command {
+	var startFlags = new(struct {
+		detach bool
+		wip    time.Duration
+	})
+
+	return &redant.Command{
+		Use:   "daemon",
+		Short: "Background daemon that keeps repo state warm for low-latency commands",
+		Children: []*redant.Command{
+			{
+				Use:   "start",
+				Short: "Start the daemon for the current repository",
+				Options: []redant.Option{
+					{
+						Flag:        "detach",
+						Description: "run the daemon in the background",
+						Value:       redant.BoolOf(&startFlags.detach),
+					},
+					{
+						Flag:        "wip",
+						Description: "dev mode: checkpoint the working tree as a wip commit on refs/fastgit/wip/<branch> at this interval while files change, e.g. 10m",
+						Value:       redant.DurationOf(&startFlags.wip),
+					},
+				},
+				Handler: func(ctx context.Context, i *redant.Invocation) error {
+					root, err := configs.RepoPath()
+					if err != nil {
+						return err
+					}
+					if daemon.Ping(root) == nil {
+						fmt.Println("daemon already running")
+						return nil
+					}
+
+					if startFlags.detach {
+						return startDetached(root, startFlags.wip)
+					}
+
+					srv, err := daemon.NewServer(root)
+					if err != nil {
+						return err
+					}
+					srv.WIPInterval = startFlags.wip
+					fmt.Printf("daemon listening on %s\n", srv.Socket())
+					if srv.WIPInterval > 0 {
+						fmt.Printf("wip checkpoints every %s\n", srv.WIPInterval)
+					}
+					return srv.Serve(ctx)
+				},
+			},
+			{
+				Use:   "stop",
+				Short: "Stop the daemon for the current repository",
+				Handler: func(ctx context.Context, i *redant.Invocation) error {
+					if err := daemon.Stop(configs.GetRepoPath()); err != nil {
+						return errors.Errorf("daemon is not running: %v", err)
+					}
+					fmt.Println("daemon stopped")
+					return nil
+				},
+			},
+			{
+				Use:   "status",
+				Short: "Show the warm snapshot served by the daemon",
+				Handler: func(ctx context.Context, i *redant.Invocation) error {
+					snap, err := daemon.Query(configs.GetRepoPath())
+					if err != nil {
+						fmt.Println("daemon is not running")
+						return nil
+					}
+
+					fmt.Printf("repo:     %s\n", snap.Repo)
+					fmt.Printf("branch:   %s\n", snap.Branch)
+					fmt.Printf("branches: %d\n", len(snap.Branches))
+					fmt.Printf("tags:     %d\n", len(snap.Tags))
+					fmt.Printf("dirty:    %t\n", snap.Dirty())
+					fmt.Printf("updated:  %s\n", snap.UpdatedAt.Format(time.RFC3339))
+					if snap.WIP != "" {
+						fmt.Printf("wip:      %s %s (%s)\n", daemon.WIPRef(snap.Branch), snap.WIP[:min(len(snap.WIP), 12)], snap.WIPAt.Format(time.RFC3339))
+					}
+					return nil
+				},
+			},
+		},
+	}
+}
+
+func startDetached(root string, wip time.Duration) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	args := []string{"daemon", "start"}
+	if wip > 0 {
+		args = append(args, "--wip", wip.String())
+	}
+	cmd := exec.Command(exe, args...)
+	cmd.Dir = root
+	cmd.Stdin = os.Stdin
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if daemon.Ping(root) == nil {
+			fmt.Printf("daemon started, pid=%d\n", cmd.Process.Pid)
+			return cmd.Process.Release()
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return errors.Errorf("daemon did not become ready, pid=%d", cmd.Process.Pid)
+}
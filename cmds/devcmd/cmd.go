@@ -23,6 +23,8 @@ import (
 	"github.com/pubgo/funk/v2/recovery"
 	"github.com/pubgo/redant"
 	"gopkg.in/yaml.v3"
+
+	"github.com/pubgo/fastgit/pkg/fswatch"
 )
 
 //go:embed static/index.html
@@ -50,7 +52,7 @@ type ServiceConfig struct {
 type DevServer struct {
 	name        string
 	config      *ServiceConfig
-	watcher     *fsnotify.Watcher
+	watcher     *fswatch.Watcher
 	cmd         *exec.Cmd
 	mu          sync.RWMutex
 	logs        []LogEntry
@@ -281,7 +283,7 @@ func (m *DevManager) Stop() error {
 
 func (s *DevServer) Start(ctx context.Context) error {
 	// 创建文件监控
-	watcher, err := fsnotify.NewWatcher()
+	watcher, err := fswatch.New(s.ignored)
 	if err != nil {
 		return fmt.Errorf("创建文件监控失败: %w", err)
 	}
@@ -328,30 +330,14 @@ func (s *DevServer) watchFiles() {
 				return
 			}
 
+			// 新建的目录（含其子目录）加入监控
+			s.watcher.Track(event)
+
 			// 检查文件扩展名
 			if !s.shouldWatch(event.Name) {
 				continue
 			}
 
-			// 处理目录创建事件，添加监控
-			if event.Op&fsnotify.Create == fsnotify.Create {
-				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
-					// 检查是否应该忽略
-					shouldIgnore := false
-					for _, ignoreDir := range s.config.IgnoreDirs {
-						if strings.Contains(event.Name, ignoreDir) {
-							shouldIgnore = true
-							break
-						}
-					}
-					if !shouldIgnore {
-						if err := s.watcher.Add(event.Name); err != nil {
-							s.addLog("error", fmt.Sprintf("添加监控目录失败: %v", err))
-						}
-					}
-				}
-			}
-
 			// 忽略某些事件
 			if event.Op&fsnotify.Write == fsnotify.Write ||
 				event.Op&fsnotify.Create == fsnotify.Create ||
@@ -374,36 +360,23 @@ func (s *DevServer) watchFiles() {
 
 func (s *DevServer) addWatchDirs() error {
 	for _, dir := range s.config.WatchDirs {
-		if err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return nil // 忽略错误，继续遍历
-			}
-
-			// 检查是否应该忽略
-			for _, ignoreDir := range s.config.IgnoreDirs {
-				if strings.Contains(path, ignoreDir) {
-					if info.IsDir() {
-						return filepath.SkipDir
-					}
-					return nil
-				}
-			}
-
-			// 只监控目录
-			if info.IsDir() {
-				if err := s.watcher.Add(path); err != nil {
-					log.Debug().Err(err).Msgf("添加监控目录失败: %s", path)
-				}
-			}
-
-			return nil
-		}); err != nil {
-			return fmt.Errorf("遍历目录失败 %s: %w", dir, err)
+		if s.watcher.AddTree(dir) == 0 {
+			return fmt.Errorf("没有可监控的目录: %s", dir)
 		}
 	}
 	return nil
 }
 
+// ignored 报告目录是否命中 ignore_dirs
+func (s *DevServer) ignored(path string) bool {
+	for _, ignoreDir := range s.config.IgnoreDirs {
+		if strings.Contains(path, ignoreDir) {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *DevServer) shouldWatch(path string) bool {
 	// 检查扩展名
 	ext := filepath.Ext(path)
@@ -419,13 +392,7 @@ func (s *DevServer) shouldWatch(path string) bool {
 	}
 
 	// 检查忽略目录
-	for _, ignoreDir := range s.config.IgnoreDirs {
-		if strings.Contains(path, ignoreDir) {
-			return false
-		}
-	}
-
-	return true
+	return !s.ignored(path)
 }
 
 func (s *DevServer) scheduleRestart() {
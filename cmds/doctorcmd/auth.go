@@ -0,0 +1,394 @@
+package doctorcmd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	levelOK    = "ok"
+	levelWarn  = "warn"
+	levelError = "error"
+	levelHint  = "hint"
+)
+
+// remoteTarget 是解析后的 remote 地址
+type remoteTarget struct {
+	URL    string
+	Scheme string // ssh | https | http | file
+	User   string
+	Host   string
+	Port   string
+}
+
+// parseRemote 支持 scp 风格（git@host:org/repo.git）、ssh://、https:// 与本地路径
+func parseRemote(raw string) (remoteTarget, error) {
+	raw = strings.TrimSpace(raw)
+	t := remoteTarget{URL: raw}
+	if raw == "" {
+		return t, fmt.Errorf("empty remote url")
+	}
+
+	if !strings.Contains(raw, "://") {
+		userHost, _, ok := strings.Cut(raw, ":")
+		// Windows 盘符与本地路径不是 scp 风格
+		if !ok || strings.Contains(userHost, "/") || len(userHost) == 1 {
+			t.Scheme = "file"
+			return t, nil
+		}
+		t.Scheme = "ssh"
+		if user, host, ok := strings.Cut(userHost, "@"); ok {
+			t.User, t.Host = user, host
+		} else {
+			t.Host = userHost
+		}
+		return t, nil
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return t, fmt.Errorf("parse remote url %q: %w", raw, err)
+	}
+	t.Scheme = u.Scheme
+	if strings.Contains(u.Scheme, "ssh") {
+		t.Scheme = "ssh"
+	}
+	t.Host = u.Hostname()
+	t.Port = u.Port()
+	if u.User != nil {
+		t.User = u.User.Username()
+	}
+	return t, nil
+}
+
+type authInput struct {
+	Remote  string
+	Target  remoteTarget
+	Timeout int
+}
+
+type finding struct {
+	Level string
+	Text  string
+}
+
+type authReport struct {
+	findings []finding
+}
+
+func (r *authReport) add(level, format string, args ...any) {
+	r.findings = append(r.findings, finding{Level: level, Text: fmt.Sprintf(format, args...)})
+}
+
+func (r authReport) count(level string) int {
+	n := 0
+	for _, f := range r.findings {
+		if f.Level == level {
+			n++
+		}
+	}
+	return n
+}
+
+func (r authReport) lines() []string {
+	out := make([]string, 0, len(r.findings)+2)
+	out = append(out, "Auth doctor report")
+	for _, f := range r.findings {
+		prefix := "[INFO]"
+		switch f.Level {
+		case levelOK:
+			prefix = "[ OK ]"
+		case levelWarn:
+			prefix = "[WARN]"
+		case levelError:
+			prefix = "[ERR ]"
+		case levelHint:
+			prefix = "       →"
+		}
+		out = append(out, prefix+" "+f.Text)
+	}
+	out = append(out, fmt.Sprintf("summary: errors=%d warnings=%d", r.count(levelError), r.count(levelWarn)))
+	return out
+}
+
+func runAuthChecks(ctx context.Context, in authInput) authReport {
+	var report authReport
+	t := in.Target
+	report.add("info", "remote %s: %s (%s)", in.Remote, t.URL, t.Scheme)
+
+	switch t.Scheme {
+	case "ssh":
+		checkSSH(ctx, &report, in)
+	case "https", "http":
+		checkHTTPS(ctx, &report, in)
+	default:
+		report.add(levelOK, "本地/文件协议 remote，无需认证")
+		return report
+	}
+
+	checkLsRemote(ctx, &report, in)
+	return report
+}
+
+func checkSSH(ctx context.Context, report *authReport, in authInput) {
+	if _, err := exec.LookPath("ssh"); err != nil {
+		report.add(levelError, "未找到 ssh 可执行文件")
+		return
+	}
+	if cmd := os.Getenv("GIT_SSH_COMMAND"); cmd != "" {
+		report.add("info", "GIT_SSH_COMMAND=%s", cmd)
+	}
+
+	agentKeys := checkAgent(ctx, report)
+	if agentKeys == 0 {
+		keys := defaultKeyFiles()
+		if len(keys) == 0 {
+			report.add(levelWarn, "~/.ssh 下没有默认私钥（id_ed25519/id_ecdsa/id_rsa）")
+			report.add(levelHint, "生成密钥：ssh-keygen -t ed25519，并把 .pub 添加到代码托管平台")
+		} else {
+			report.add(levelOK, "默认私钥: %s", strings.Join(keys, ", "))
+		}
+	}
+
+	args := []string{"-T", "-o", "BatchMode=yes", "-o", fmt.Sprintf("ConnectTimeout=%d", in.Timeout)}
+	if in.Target.Port != "" {
+		args = append(args, "-p", in.Target.Port)
+	}
+	dest := in.Target.Host
+	if in.Target.User != "" {
+		dest = in.Target.User + "@" + dest
+	}
+	args = append(args, dest)
+
+	out, err := runWithTimeout(ctx, in.Timeout, nil, "ssh", args...)
+	// GitHub/GitLab 的 ssh -T 认证成功也以非 0 退出，只能按输出判断
+	if sshAuthenticated(out) {
+		report.add(levelOK, "SSH 认证成功: %s", firstLine(out))
+		return
+	}
+	if err == nil {
+		report.add(levelOK, "SSH 连接成功: ssh %s", dest)
+		return
+	}
+	report.add(levelError, "SSH 连接失败: ssh %s: %s", dest, firstLine(out, err.Error()))
+	for _, hint := range explainSSH(out, in.Target) {
+		report.add(levelHint, "%s", hint)
+	}
+}
+
+func checkAgent(ctx context.Context, report *authReport) int {
+	if os.Getenv("SSH_AUTH_SOCK") == "" {
+		report.add(levelWarn, "SSH_AUTH_SOCK 未设置，ssh-agent 未运行或未转发")
+		report.add(levelHint, `启动 agent 并加载密钥：eval "$(ssh-agent -s)" && ssh-add`)
+		return 0
+	}
+	out, err := exec.CommandContext(ctx, "ssh-add", "-l").CombinedOutput()
+	text := strings.TrimSpace(string(out))
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			report.add(levelWarn, "ssh-agent 中没有已加载的密钥")
+			report.add(levelHint, "加载密钥：ssh-add ~/.ssh/id_ed25519（macOS 可加 --apple-use-keychain）")
+			return 0
+		}
+		report.add(levelWarn, "无法连接 ssh-agent: %s", firstLine(text, err.Error()))
+		report.add(levelHint, "SSH_AUTH_SOCK 指向的 socket 已失效，重新登录或重启 ssh-agent")
+		return 0
+	}
+	lines := strings.Split(text, "\n")
+	report.add(levelOK, "ssh-agent 已加载 %d 个密钥", len(lines))
+	for _, line := range lines {
+		report.add("info", "  %s", line)
+	}
+	return len(lines)
+}
+
+func checkHTTPS(ctx context.Context, report *authReport, in authInput) {
+	helpers, _ := exec.CommandContext(ctx, "git", "config", "--get-urlmatch", "credential.helper", in.Target.URL).Output()
+	helper := strings.TrimSpace(string(helpers))
+	if helper == "" {
+		report.add(levelWarn, "未配置 credential.helper，HTTPS 推送每次都需要输入凭据")
+		report.add(levelHint, "配置凭据助手：git config --global credential.helper store（或 osxkeychain / manager / cache）")
+		if in.Target.Host == "github.com" {
+			report.add(levelHint, "GitHub 用户可执行 gh auth setup-git 使用 gh 的 token")
+		}
+		return
+	}
+	report.add(levelOK, "credential.helper: %s", helper)
+
+	// 只询问助手，不弹出终端或图形化密码框
+	input := fmt.Sprintf("protocol=%s\nhost=%s\n", in.Target.Scheme, hostWithPort(in.Target))
+	if in.Target.User != "" {
+		input += "username=" + in.Target.User + "\n"
+	}
+	out, err := runWithTimeout(ctx, in.Timeout, strings.NewReader(input+"\n"), "git", "credential", "fill")
+	cred := parseCredential(out)
+	if err != nil || cred["password"] == "" {
+		report.add(levelError, "凭据助手没有 %s 的凭据", hostWithPort(in.Target))
+		report.add(levelHint, "先手动 git push 一次让助手保存凭据；GitHub 需使用 Personal Access Token 而非登录密码")
+		return
+	}
+	report.add(levelOK, "凭据助手返回了 %s 的凭据（username=%s）", hostWithPort(in.Target), cred["username"])
+}
+
+func checkLsRemote(ctx context.Context, report *authReport, in authInput) {
+	out, err := runWithTimeout(ctx, in.Timeout, nil, "git", "ls-remote", in.Remote, "HEAD")
+	if err == nil {
+		report.add(levelOK, "git ls-remote %s 成功，读取权限正常", in.Remote)
+		return
+	}
+	report.add(levelError, "git ls-remote %s 失败: %s", in.Remote, firstLine(out, err.Error()))
+	hints := explainSSH(out, in.Target)
+	if in.Target.Scheme != "ssh" {
+		hints = explainHTTPS(out)
+	}
+	for _, hint := range hints {
+		report.add(levelHint, "%s", hint)
+	}
+}
+
+// explainSSH 把常见的 ssh 错误翻译成可执行的修复建议
+func explainSSH(output string, t remoteTarget) []string {
+	switch {
+	case strings.Contains(output, "Permission denied (publickey"):
+		return []string{
+			"服务端拒绝了所有提供的公钥：密钥未加载到 agent，或公钥未添加到账号",
+			fmt.Sprintf("查看实际提供的密钥：ssh -vT %s 2>&1 | grep Offering", sshDest(t)),
+		}
+	case strings.Contains(output, "Host key verification failed"):
+		return []string{
+			"known_hosts 中没有或不匹配该主机的指纹（BatchMode 下不会询问）",
+			fmt.Sprintf("确认指纹后执行一次：ssh %s，或 ssh-keyscan %s >> ~/.ssh/known_hosts", sshDest(t), t.Host),
+		}
+	case strings.Contains(output, "REMOTE HOST IDENTIFICATION HAS CHANGED"):
+		return []string{
+			"主机指纹已变化，可能是服务端换了密钥，也可能是中间人攻击",
+			fmt.Sprintf("核实后移除旧指纹：ssh-keygen -R %s", t.Host),
+		}
+	case strings.Contains(output, "Could not resolve hostname"):
+		return []string{"DNS 无法解析主机名，检查网络、VPN 或 ~/.ssh/config 中的 Host 别名"}
+	case strings.Contains(output, "Connection timed out"), strings.Contains(output, "Operation timed out"), strings.Contains(output, "Connection refused"):
+		hints := []string{"无法连接 SSH 端口，可能被防火墙拦截"}
+		if t.Host == "github.com" {
+			hints = append(hints, "GitHub 可改走 443 端口：在 ~/.ssh/config 中为 github.com 配置 Hostname ssh.github.com 与 Port 443")
+		}
+		return hints
+	case strings.Contains(output, "Too many authentication failures"):
+		return []string{"agent 中密钥过多，服务端在尝试到正确密钥前断开；在 ~/.ssh/config 中为该主机配置 IdentityFile 与 IdentitiesOnly yes"}
+	case strings.Contains(output, "Repository not found"), strings.Contains(output, "does not appear to be a git repository"):
+		return []string{"认证成功但仓库不存在或无权限：检查 remote 地址，或确认当前密钥对应的账号有仓库访问权限"}
+	case strings.Contains(output, "signal: killed"), strings.Contains(output, "context deadline exceeded"):
+		return []string{"检查超时，网络可能不通或需要代理"}
+	}
+	return nil
+}
+
+func explainHTTPS(output string) []string {
+	switch {
+	case strings.Contains(output, "terminal prompts disabled"), strings.Contains(output, "could not read Username"):
+		return []string{"没有可用的凭据：配置 credential.helper 并手动推送一次保存凭据"}
+	case strings.Contains(output, "Authentication failed"), strings.Contains(output, "403"), strings.Contains(output, "401"):
+		return []string{
+			"凭据被拒绝：token 过期、权限不足，或使用了账号密码（GitHub 已不支持）",
+			"清除旧凭据：printf 'protocol=https\\nhost=<host>\\n\\n' | git credential reject",
+		}
+	case strings.Contains(output, "SSL certificate problem"):
+		return []string{"TLS 证书校验失败：公司代理可能替换了证书，配置 http.sslCAInfo 指向企业根证书"}
+	case strings.Contains(output, "Could not resolve host"):
+		return []string{"DNS 无法解析主机名，检查网络或代理设置（https_proxy / http.proxy）"}
+	case strings.Contains(output, "Repository not found"):
+		return []string{"仓库不存在，或当前凭据对应的账号无访问权限"}
+	}
+	return nil
+}
+
+func sshAuthenticated(output string) bool {
+	lower := strings.ToLower(output)
+	for _, marker := range []string{"successfully authenticated", "welcome to gitlab", "logged in as", "authenticated via"} {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func runWithTimeout(ctx context.Context, seconds int, stdin *strings.Reader, name string, args ...string) (string, error) {
+	if seconds <= 0 {
+		seconds = 10
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(seconds)*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_ASKPASS=true", "SSH_ASKPASS=true")
+	if os.Getenv("GIT_SSH_COMMAND") == "" {
+		cmd.Env = append(cmd.Env, "GIT_SSH_COMMAND=ssh -o BatchMode=yes")
+	}
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	out, err := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		err = fmt.Errorf("timed out after %ds", seconds)
+	}
+	return strings.TrimSpace(string(out)), err
+}
+
+func parseCredential(out string) map[string]string {
+	cred := map[string]string{}
+	for _, line := range strings.Split(out, "\n") {
+		if k, v, ok := strings.Cut(strings.TrimSpace(line), "="); ok {
+			cred[k] = v
+		}
+	}
+	return cred
+}
+
+func defaultKeyFiles() []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	var keys []string
+	for _, name := range []string{"id_ed25519", "id_ecdsa", "id_rsa"} {
+		if _, err := os.Stat(filepath.Join(home, ".ssh", name)); err == nil {
+			keys = append(keys, "~/.ssh/"+name)
+		}
+	}
+	return keys
+}
+
+func sshDest(t remoteTarget) string {
+	dest := t.Host
+	if t.User != "" {
+		dest = t.User + "@" + dest
+	}
+	if t.Port != "" {
+		dest = "-p " + t.Port + " " + dest
+	}
+	return dest
+}
+
+func hostWithPort(t remoteTarget) string {
+	if t.Port != "" {
+		return t.Host + ":" + t.Port
+	}
+	return t.Host
+}
+
+func firstLine(out string, fallback ...string) string {
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "Warning: Permanently added") {
+			return line
+		}
+	}
+	if len(fallback) > 0 {
+		return fallback[0]
+	}
+	return ""
+}
@@ -0,0 +1,45 @@
+package doctorcmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRemote(t *testing.T) {
+	cases := map[string]remoteTarget{
+		"git@github.com:pubgo/fastgit.git":          {Scheme: "ssh", User: "git", Host: "github.com"},
+		"ssh://git@git.corp:2222/team/app.git":      {Scheme: "ssh", User: "git", Host: "git.corp", Port: "2222"},
+		"git+ssh://git.corp/team/app.git":           {Scheme: "ssh", Host: "git.corp"},
+		"https://github.com/pubgo/fastgit.git":      {Scheme: "https", Host: "github.com"},
+		"https://bot@gitlab.corp:8443/team/app.git": {Scheme: "https", User: "bot", Host: "gitlab.corp", Port: "8443"},
+		"/srv/git/app.git":                          {Scheme: "file"},
+		"../app.git":                                {Scheme: "file"},
+	}
+	for raw, want := range cases {
+		got, err := parseRemote(raw)
+		require.NoError(t, err, raw)
+		want.URL = raw
+		require.Equal(t, want, got, raw)
+	}
+}
+
+func TestExplainSSH(t *testing.T) {
+	gh := remoteTarget{Scheme: "ssh", User: "git", Host: "github.com"}
+
+	hints := explainSSH("git@github.com: Permission denied (publickey).", gh)
+	require.Len(t, hints, 2)
+	require.Contains(t, hints[1], "ssh -vT git@github.com")
+
+	hints = explainSSH("ssh: connect to host github.com port 22: Connection timed out", gh)
+	require.Contains(t, hints[len(hints)-1], "ssh.github.com")
+
+	require.Contains(t, explainSSH("Host key verification failed.", gh)[1], "ssh-keyscan github.com")
+	require.Empty(t, explainSSH("something else", gh))
+}
+
+func TestSSHAuthenticated(t *testing.T) {
+	require.True(t, sshAuthenticated("Hi octocat! You've successfully authenticated, but GitHub does not provide shell access."))
+	require.True(t, sshAuthenticated("Welcome to GitLab, @octocat!"))
+	require.False(t, sshAuthenticated("git@github.com: Permission denied (publickey)."))
+}
@@ -0,0 +1,67 @@
+package doctorcmd
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/pubgo/redant"
+
+	"github.com/pubgo/fastgit/utils"
+)
+
+// New creates the doctor command group.
+func New() *redant.Command {
+	return &redant.Command{
+		Use:   "doctor",
+		Short: "环境诊断",
+		Children: []*redant.Command{
+			newAuthCommand(),
+		},
+	}
+}
+
+func newAuthCommand() *redant.Command {
+	var (
+		remote  string
+		timeout int64
+	)
+
+	return &redant.Command{
+		Use:      "auth",
+		Short:    "诊断推送认证：SSH 连通性与 agent 密钥、HTTPS 凭据助手，并解释失败原因",
+		Metadata: utils.NoTTYMetadata(),
+		Options: redant.OptionSet{
+			{Flag: "remote", Description: "要诊断的 remote", Value: redant.StringOf(&remote), Default: "origin"},
+			{Flag: "timeout", Description: "单项网络检查超时（秒）", Value: redant.Int64Of(&timeout), Default: "10"},
+		},
+		Handler: func(ctx context.Context, inv *redant.Invocation) error {
+			url, err := remoteURL(ctx, remote)
+			if err != nil {
+				return err
+			}
+			target, err := parseRemote(url)
+			if err != nil {
+				return err
+			}
+
+			report := runAuthChecks(ctx, authInput{Remote: remote, Target: target, Timeout: int(timeout)})
+			for _, line := range report.lines() {
+				_, _ = fmt.Fprintln(inv.Stdout, line)
+			}
+			if n := report.count(levelError); n > 0 {
+				return fmt.Errorf("doctor auth failed: %d error(s), %d warning(s)", n, report.count(levelWarn))
+			}
+			return nil
+		},
+	}
+}
+
+func remoteURL(ctx context.Context, remote string) (string, error) {
+	out, err := exec.CommandContext(ctx, "git", "remote", "get-url", "--push", remote).Output()
+	if err != nil {
+		return "", fmt.Errorf("remote %q not found (git remote -v): %w", remote, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
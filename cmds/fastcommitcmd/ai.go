@@ -3,12 +3,11 @@ package fastcommitcmd
 import (
 	"context"
 	"fmt"
+	"maps"
 	"os"
 	"strconv"
 	"strings"
-	"time"
 
-	"github.com/briandowns/spinner"
 	"github.com/pubgo/dix/v2"
 	"github.com/pubgo/dix/v2/dixcontext"
 	"github.com/pubgo/funk/v2/assert"
@@ -16,21 +15,72 @@ import (
 	"github.com/pubgo/funk/v2/log"
 	"github.com/yarlson/tap"
 
+	"github.com/pubgo/fastgit/cmds/addcmd"
+	"github.com/pubgo/fastgit/cmds/conflictcmd"
 	"github.com/pubgo/fastgit/pkg/aiprovider"
+	"github.com/pubgo/fastgit/pkg/commitmsg"
+	"github.com/pubgo/fastgit/pkg/commitplan"
 	"github.com/pubgo/fastgit/pkg/gitconflict"
 	"github.com/pubgo/fastgit/pkg/repoconfig"
+	"github.com/pubgo/fastgit/pkg/ticket"
+	"github.com/pubgo/fastgit/pkg/timing"
 	"github.com/pubgo/fastgit/pkg/workflow"
 	"github.com/pubgo/fastgit/utils"
 )
 
 func runAICommit(ctx context.Context, flags *flagOptions) error {
+	if flags.watch {
+		return runWatch(ctx, flags)
+	}
+
 	di := dixcontext.Get(ctx)
 	var params cmdParams
 	params = dix.Inject(di, params)
+	if name := strings.TrimSpace(flags.provider); name != "" {
+		params.AI = aiprovider.ResolveProvider(name, mustRepoRoot())
+	}
+	if path := flags.debugLLM.resolve(mustRepoRoot()); path != "" {
+		params.AI = aiprovider.WithDebugLog(params.AI, path)
+		log.Info().Str("path", path).Msg("LLM requests and responses are appended to the debug log")
+	}
+
+	if flags.planClear {
+		if err := commitplan.Clear(mustRepoRoot()); err != nil {
+			return err
+		}
+		log.Info().Msg("commit plan removed")
+		return nil
+	}
+	if task := strings.TrimSpace(flags.plan); task != "" {
+		return runPlan(ctx, params, flags, task)
+	}
 
 	utils.LogConfigAndBranch()
+	inProgress, err := conflictcmd.HandleInProgressCommit(ctx, mustRepoRoot())
+	if err != nil {
+		return err
+	}
+	// rebase/am 期间 HEAD 处于分离状态，推送留到操作结束后
+	if inProgress != nil && (inProgress.Op == gitconflict.OpRebase || inProgress.Op == gitconflict.OpAm) && !flags.noPush {
+		log.Info().Str("operation", string(inProgress.Op)).Msg("operation in progress, committing without push")
+		flags.noPush = true
+	}
 
-	res := utils.PreGitPush(ctx)
+	amendAI := flags.amend && !flags.fastCommit
+	if amendAI {
+		if err := checkAmend(flags); err != nil {
+			return err
+		}
+	}
+
+	// 工作区干净且有未推送的提交时先推送；关闭自动推送或 amend（HEAD 即将被改写）时跳过
+	var res string
+	if pushEnabled(params.CommitCfg, flags) && !amendAI {
+		var err error
+		if res, err = utils.PreGitPush(ctx); err != nil {
+			return err
+		}
+	}
 	if res != "" {
 		if shouldPullDueToRemoteUpdate(res) {
 			err := gitPull()
@@ -52,16 +102,13 @@ func runAICommit(ctx context.Context, flags *flagOptions) error {
 			return nil
 		}
 
-		preMsg := strings.TrimSpace(utils.ShellExecOutput(ctx, "git", "log", "-1", "--pretty=%B").Unwrap())
-		prefixMsg := fmt.Sprintf("chore: quick update %s", utils.GetBranchName())
-		msg := fmt.Sprintf("%s at %s", prefixMsg, time.Now().Format(time.DateTime))
+		preMsg := strings.TrimSpace(utils.ShellExecOutput(ctx, "git", "log", "-1", "--pretty=%s").Unwrap())
+		msg, err := fastMessage(ctx, params.CommitCfg, utils.GetBranchName())
+		if err != nil {
+			return err
+		}
 
-		msg = strings.TrimSpace(tap.Text(ctx, tap.TextOptions{
-			Message:      "git message(update or enter):",
-			InitialValue: msg,
-			DefaultValue: msg,
-			Placeholder:  "update or enter",
-		}))
+		msg = editMessage(ctx, msg)
 
 		if msg == "" {
 			return nil
@@ -69,32 +116,52 @@ func runAICommit(ctx context.Context, flags *flagOptions) error {
 
 		repoRoot := mustRepoRoot()
 		repoCfg, _ := repoconfig.Load(repoRoot)
+		repoCfg = withMessageStyle(repoCfg, params)
 		if err := enforceRepoPolicy(repoCfg, currentBranch(), msg, flags.skipPolicy); err != nil {
 			return err
 		}
 		warnRepoPolicy(repoCfg, currentBranch(), msg)
 
+		sign, err := signCommit(params, flags)
+		if err != nil {
+			return err
+		}
+		commitArgs := []string{"git", "commit"}
+		if sign {
+			commitArgs = append(commitArgs, "-S")
+		}
+
 		assert.Must(utils.ShellExec(ctx, "git", "add", "-A"))
 		res := utils.ShellExecOutput(ctx, "git", "status").Unwrap()
 
+		if err := checkSecrets(params.CommitCfg, mustRepoRoot(), flags, nil); err != nil {
+			return err
+		}
+
 		if err := runPreCommitCheck(ctx, mustRepoRoot(), flags.skipCheck); err != nil {
 			return err
 		}
 
-		if !flags.amend {
-			assert.Must(utils.ShellExec(ctx, "git", "commit", "-m", strconv.Quote(msg)))
+		// --amend 只续写上一个 --fast 提交，其它情况仍新建提交
+		amended := flags.amend && fastMatcher(ctx, params.CommitCfg, utils.GetBranchName()).MatchString(preMsg) &&
+			!strings.Contains(res, `(use "git commit" to conclude merge)`)
+		if amended {
+			assert.Must(utils.ShellExec(ctx, append(commitArgs, "--amend", "--no-edit", "-m", strconv.Quote(msg))...))
 		} else {
-			if strings.Contains(preMsg, prefixMsg) && !strings.Contains(res, `(use "git commit" to conclude merge)`) {
-				assert.Must(utils.ShellExec(ctx, "git", "commit", "--amend", "--no-edit", "-m", strconv.Quote(msg)))
-			} else {
-				assert.Must(utils.ShellExec(ctx, "git", "commit", "-m", strconv.Quote(msg)))
-			}
+			assert.Must(utils.ShellExec(ctx, append(commitArgs, "-m", strconv.Quote(msg))...))
 		}
 
+		if !pushEnabled(params.CommitCfg, flags) {
+			logPushSkipped()
+			return nil
+		}
 		if err := ensurePushPolicy(mustRepoRoot(), utils.GetBranchName(), flags.overridePolicy); err != nil {
 			return err
 		}
-		res = utils.GitPush(ctx, "--force-with-lease", "origin", utils.GetBranchName())
+		res, err = pushCurrentBranch(ctx, params, amended)
+		if err != nil {
+			return err
+		}
 		if shouldPullDueToRemoteUpdate(res) {
 			err := gitPull()
 			if err != nil {
@@ -110,41 +177,114 @@ func runAICommit(ctx context.Context, flags *flagOptions) error {
 		return nil
 	}
 
-	prefixMsg := fmt.Sprintf("chore: quick update %s", utils.GetBranchName())
-	targetCommit := getFirstNonPrefixCommit(ctx, prefixMsg)
-
-	if targetCommit != "" {
-		assert.Must(utils.ShellExec(ctx, "git", "reset", "--soft", targetCommit))
-	} else {
-		commitsToSquash := getCommitsToSquash(ctx, prefixMsg)
-		if len(commitsToSquash) > 0 {
-			parentCommit := getParentCommit(ctx, commitsToSquash[0])
-			if parentCommit != "" {
-				assert.Must(utils.ShellExec(ctx, "git", "reset", "--soft", parentCommit))
-			} else {
-				assert.Must(utils.ShellExec(ctx, "git", "reset", "--soft", "HEAD~"+strconv.Itoa(len(commitsToSquash))))
-			}
+	// amend 只改写 HEAD，不合并此前的 --fast 提交
+	if !amendAI {
+		branch, err := utils.BranchName()
+		if err != nil {
+			return err
 		}
+		flags.squashedPushed = squashFastCommits(ctx, params, branch)
 	}
 
-	if utils.IsDirty().Unwrap() {
+	if flags.patch && utils.NonInteractive() {
+		return errors.New("--patch picks hunks interactively and cannot be combined with --yes")
+	}
+	if err := checkCommitType(flags, params.CommitCfg); err != nil {
+		return err
+	}
+	if flags.patch {
+		// 只暂存选中的 hunk，AI 只看到这部分 diff；已暂存的内容保持不变
+		if _, err := addcmd.Patch(ctx, nil); err != nil {
+			return err
+		}
+	} else if utils.IsDirty().Unwrap() {
 		assert.Must(utils.ShellExec(ctx, "git", "add", "--update"))
 	}
 
-	diffResult := utils.GetStagedDiff(ctx).Unwrap()
+	repoRoot := mustRepoRoot()
+	repoCfg, _ := repoconfig.Load(repoRoot)
+	repoCfg = withMessageStyle(repoCfg, params)
+	if err := checkStagedHunks(ctx, params.CommitCfg, repoRoot); err != nil {
+		return err
+	}
+
+	// amend 时让模型看到 HEAD 与暂存改动合并后的完整 diff
+	getDiff := utils.GetStagedDiff
+	if amendAI {
+		getDiff = utils.GetAmendDiff
+	}
+	diffResult := getDiff(ctx, DiffExcludes(params.CommitCfg, repoCfg)...).Unwrap()
 	if diffResult == nil || len(diffResult.Files) == 0 {
 		return nil
 	}
 
-	repoRoot := mustRepoRoot()
-	if err := runPreCommitCheck(ctx, repoRoot, flags.skipCheck); err != nil {
+	if err := repoCfg.CheckBranch(currentBranch(), flags.skipPolicy); err != nil {
 		return err
 	}
+	if err := checkSecrets(params.CommitCfg, repoRoot, flags, diffResult); err != nil {
+		return err
+	}
+	// 生成前检查签名密钥，避免生成完才因缺少密钥提交失败
+	sign, err := signCommit(params, flags)
+	if err != nil {
+		return err
+	}
+	if flags.last {
+		return commitLast(ctx, params, repoCfg, repoRoot, sign, flags)
+	}
+	tk := lookupTicket(ctx, params.TicketCfg)
+	if !flags.split && !amendAI {
+		flags.split = offerSplit(ctx, params, diffResult)
+	}
+	if flags.split {
+		if len(diffResult.Files) > 1 {
+			return runSplit(ctx, params, flags, repoCfg, repoRoot, diffResult, tk, sign)
+		}
+		log.Info().Msg("only one file staged, nothing to split")
+	}
+	templateName := strings.TrimSpace(flags.template)
 
-	repoCfg, _ := repoconfig.Load(repoRoot)
-	if err := repoCfg.CheckBranch(currentBranch(), flags.skipPolicy); err != nil {
+	// 提前构建 prompt 并在后台发起生成，模型延迟被提交前检查与文件确认的时间覆盖
+	var (
+		scope, generatePrompt, guidance, input string
+		count                                  int
+		req                                    aiprovider.CompleteRequest
+		msgPrefetch                            *messagePrefetch
+		candidatesPrefetch                     *pending[[]aiprovider.CommitCandidate]
+	)
+	if templateName == "" {
+		scope = repoCfg.InferScope(diffResult.Files)
+		var err error
+		generatePrompt, guidance, err = commitPrompts(flags, repoCfg, params, repoRoot, currentBranch(), scope, tk.Ref(), diffResult)
+		if err != nil {
+			return err
+		}
+		generatePrompt, guidance = withPlanContext(repoRoot, generatePrompt), withPlanContext(repoRoot, guidance)
+		count = candidateTotal(flags, repoCfg, params)
+		// 候选列表每条只有一行，多行的 commit.template 走单条生成
+		if loadGitTemplate(ctx, repoRoot).HasBody() {
+			count = 0
+		}
+
+		if prefetchEnabled(params.CommitCfg, diffResult) {
+			input = diffResult.Diff
+			req = aiprovider.CompleteRequest{System: generatePrompt, User: input}
+			if count > 1 {
+				candidatesPrefetch = goPending(ctx, func(ctx context.Context) ([]aiprovider.CommitCandidate, error) {
+					return aiprovider.GenerateCommitCandidates(ctx, params.AI, input, count, guidance)
+				})
+			} else {
+				msgPrefetch = startMessagePrefetch(ctx, params.AI, req)
+			}
+			defer candidatesPrefetch.stop()
+			defer msgPrefetch.stop()
+		}
+	}
+
+	if err := runPreCommitCheck(ctx, repoRoot, flags.skipCheck); err != nil {
 		return err
 	}
+
 	for _, file := range diffResult.Files {
 		if repoCfg.MatchesSensitivePath(file) {
 			log.Warn().Str("file", file).Msg("sensitive path staged — review carefully")
@@ -155,28 +295,47 @@ func runAICommit(ctx context.Context, flags *flagOptions) error {
 	for _, file := range diffResult.Files {
 		log.Info().Msg("file: " + file)
 	}
+	if flags.review && !confirmStagedFiles(ctx, len(diffResult.Files)) {
+		log.Info().Msg("commit aborted")
+		return nil
+	}
 
-	s := spinner.New(spinner.CharSets[35], 100*time.Millisecond, func(s *spinner.Spinner) {
-		s.Prefix = "generate git message: "
-	})
-	s.Start()
-	locale := "en"
-	maxLength := 50
-	if repoCfg.Commit.Locale != "" {
-		locale = repoCfg.Commit.Locale
-	}
-	if repoCfg.Commit.MaxLength > 0 {
-		maxLength = repoCfg.Commit.MaxLength
-	}
-	generatePrompt := utils.AppendAllowedTypes(
-		utils.GeneratePrompt(locale, maxLength, utils.ConventionalCommitType),
-		repoCfg.Commit.Types,
-	)
+	if templateName != "" {
+		msg, err := RenderTemplate(ctx, params.CommitCfg, templateName)
+		if err != nil {
+			return err
+		}
+		msg = withIssueRef(ticket.WithRef(msg, tk), params)
+		msg = editMessage(ctx, msg)
+		if msg == "" {
+			return nil
+		}
+		if err := commitAndPush(ctx, params, repoCfg, repoRoot, msg, sign, flags); err != nil {
+			return err
+		}
+		log.Info().Str("message", msg).Str("template", templateName).Msg("commit message rendered from template")
+		workflow.PrintRecommendations(os.Stdout, "commit")
+		return nil
+	}
 
-	useCandidates := shouldUseCandidates(flags, repoCfg, params)
+	if msgPrefetch == nil && candidatesPrefetch == nil {
+		input = aiDiffInput(ctx, params, diffResult)
+		req = aiprovider.CompleteRequest{System: generatePrompt, User: input}
+	}
+	useCandidates := count > 1
+	msgType, msgBody := messageFormat(ctx, flags, repoCfg, params, repoRoot)
 	var msg string
 	if useCandidates {
-		candidates, err := aiprovider.GenerateCommitCandidates(ctx, params.AI, diffResult.Diff)
+		s := utils.NewSpinner("generate git message: ")
+		s.Start()
+		noticeCtx := aiprovider.WithRetryNotice(ctx, s.SetSuffix)
+		var candidates []aiprovider.CommitCandidate
+		var err error
+		if candidatesPrefetch != nil {
+			candidates, err = candidatesPrefetch.wait(ctx)
+		} else {
+			candidates, err = aiprovider.GenerateCommitCandidates(noticeCtx, params.AI, input, count, guidance)
+		}
 		s.Stop()
 		if err != nil {
 			log.Err(err).Msg("failed to generate commit candidates")
@@ -188,26 +347,41 @@ func runAICommit(ctx context.Context, flags *flagOptions) error {
 		options := make([]tap.SelectOption[string], 0, len(candidates))
 		for _, candidate := range candidates {
 			candidate := candidate
+			candidate.Message = orText(commitmsg.Repair(candidate.Message, msgType, false), candidate.Message)
+			candidate.Message = repoCfg.FormatMessage(repoconfig.WithScope(withType(candidate.Message, flags, repoCfg), scope))
 			options = append(options, tap.SelectOption[string]{
 				Label: aiprovider.FormatCandidateLabel(candidate),
-				Value: candidate.Message,
+				Value: withIssueRef(ticket.WithRef(candidate.Message, tk), params),
 			})
 		}
 		if len(options) == 0 {
 			return nil
 		}
-		selected := tap.Select[string](ctx, tap.SelectOptions[string]{
-			Message: "Pick a commit message:",
-			Options: options,
-		})
-		msg = strings.TrimSpace(selected)
+		selected := options[0].Value
+		if !utils.NonInteractive() {
+			done := timing.Track(ctx, timing.PhaseUI, "pick commit message")
+			selected = tap.Select[string](ctx, tap.SelectOptions[string]{
+				Message: "Pick a commit message:",
+				Options: options,
+			})
+			done()
+		}
+		if strings.TrimSpace(selected) == "" {
+			return nil
+		}
+		msg = editMessage(ctx, selected)
 	} else {
-		aiResp, err := params.AI.Complete(ctx, aiprovider.CompleteRequest{
-			System: generatePrompt,
-			User:   diffResult.Diff,
-		})
-		s.Stop()
-
+		var aiResp aiprovider.CompleteResponse
+		var err error
+		if msgPrefetch != nil {
+			aiResp, err = runStream(ctx, msgPrefetch.stream)
+		} else {
+			aiResp, err = streamCommitMessage(ctx, params.AI, req)
+		}
+		if errors.Is(err, context.Canceled) {
+			log.Info().Msg("commit message generation cancelled")
+			return nil
+		}
 		if err != nil {
 			log.Err(err).Msg("failed to generate commit message")
 			return errors.WrapCaller(err)
@@ -215,39 +389,152 @@ func runAICommit(ctx context.Context, flags *flagOptions) error {
 
 		if aiResp.Fallback {
 			log.Warn().Str("provider", aiResp.Provider).Msg("using rule-based commit message fallback (AI unavailable)")
+		} else if len(aiResp.Skipped) > 0 {
+			log.Warn().Strs("failed", aiResp.Skipped).Str("provider", aiResp.Provider).Str("model", aiResp.Model).
+				Msg("primary AI provider failed, commit message generated by a fallback provider")
 		}
 		if hint := aiprovider.BreakingChangeHint(diffResult.Diff); hint != "" {
 			log.Warn().Msg(hint)
 			fmt.Println(hint)
 		}
 
-		msg = strings.TrimSpace(tap.Text(ctx, tap.TextOptions{
-			Message:      "git message(update or enter):",
-			InitialValue: aiResp.Text,
-			DefaultValue: aiResp.Text,
-			Placeholder:  "update or enter",
-		}))
+		text := aiResp.Text
+		if !aiResp.Fallback {
+			text = validateResponse(ctx, params.AI, req, text, msgType, msgBody)
+		}
+		decorate := func(text string) string {
+			text = orText(commitmsg.Repair(text, msgType, msgBody), text)
+			if flags.body {
+				text = commitmsg.WrapBody(text, commitmsg.BodyWidth)
+			}
+			return withIssueRef(ticket.WithRef(repoCfg.FormatMessage(repoconfig.WithScope(text, scope)), tk), params)
+		}
+		// --type 只改写首次生成的信息，refine 中 "Change type" 的选择不再被覆盖
+		msg = refineLoop(ctx, params.AI, req, decorate(withType(text, flags, repoCfg)), refineTypes(params.CommitCfg, repoCfg), decorate, messageEditor(flags))
 	}
 	if msg == "" {
 		return nil
 	}
+	if err := commitAndPush(ctx, params, repoCfg, repoRoot, msg, sign, flags); err != nil {
+		return err
+	}
+	if flags.showPrompt && !useCandidates {
+		fmt.Println("\n" + generatePrompt + "\n")
+	}
+	log.Info().Str("message", msg).Bool("candidates", useCandidates).Msg("commit message generated")
+	workflow.PrintRecommendations(os.Stdout, "commit")
+	return nil
+}
+
+// confirmStagedFiles 是 --review 的确认步骤，后台的生成请求在此期间继续进行
+func confirmStagedFiles(ctx context.Context, n int) bool {
+	if utils.NonInteractive() {
+		return true
+	}
+	defer timing.Track(ctx, timing.PhaseUI, "review staged files")()
+	return tap.Confirm(ctx, tap.ConfirmOptions{
+		Message:      fmt.Sprintf("Commit these %d file(s)?", n),
+		InitialValue: true,
+	})
+}
+
+// editMessage 让用户确认或修改提交信息，等待时间计入 --timings 的 ui wait；非交互模式直接采用
+func editMessage(ctx context.Context, initial string) string {
+	if utils.NonInteractive() {
+		return strings.TrimSpace(initial)
+	}
+	defer timing.Track(ctx, timing.PhaseUI, "edit commit message")()
+	return strings.TrimSpace(tap.Text(ctx, tap.TextOptions{
+		Message:      "git message(update or enter):",
+		InitialValue: initial,
+		DefaultValue: initial,
+		Placeholder:  "update or enter",
+	}))
+}
+
+// lookupTicket 根据分支名查询关联工单；失败只告警，不阻断提交
+func lookupTicket(ctx context.Context, cfgs []*ticket.Config) *ticket.Ticket {
+	tk, err := ticket.Lookup(ctx, cfgs, utils.GetBranchName())
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to fetch ticket")
+		return nil
+	}
+	if tk != nil {
+		log.Info().Str("ticket", tk.Ref()).Str("status", tk.Status).Msg("ticket detected")
+	}
+	return tk
+}
+
+// withIssueRef 按 commit.issue_ref 追加分支名中的 issue 编号；模板有误只告警，保留原信息
+func withIssueRef(msg string, params cmdParams) string {
+	tmpl := ticket.DefaultIssueRef
+	for _, cfg := range params.CommitCfg {
+		if cfg != nil && cfg.IssueRef != nil {
+			tmpl = *cfg.IssueRef
+		}
+	}
+	out, err := ticket.WithIssueRef(msg, ticket.IssueFromBranch(utils.GetBranchName()), tmpl)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to append issue reference")
+	}
+	return out
+}
+
+// commitAndPush 校验 commitlint 规则与仓库策略后提交并推送当前分支；模型修正过的信息先交给用户确认
+func commitAndPush(ctx context.Context, params cmdParams, repoCfg repoconfig.Bundle, repoRoot, msg string, sign bool, flags *flagOptions) (gErr error) {
+	// 提交前失败时保存信息，fastgit commit --last 可复用，不必重新生成
+	committed := false
+	defer func() {
+		if gErr != nil && !committed {
+			saveLastMessage(repoRoot, msg)
+		}
+	}()
+
+	fixed, err := lintMessage(ctx, params.AI, params.CommitCfg, repoCfg, repoRoot, msg, flags.skipPolicy)
+	if err != nil {
+		return err
+	}
+	if fixed != msg {
+		if msg = editMessage(ctx, fixed); msg == "" {
+			log.Info().Msg("commit aborted")
+			return nil
+		}
+	}
 
 	if err := enforceRepoPolicy(repoCfg, currentBranch(), msg, flags.skipPolicy); err != nil {
 		return err
 	}
 	warnRepoPolicy(repoCfg, currentBranch(), msg)
+	if diffstatEnabled(params.CommitCfg, flags) {
+		msg = withDiffstat(msg, flags.amend)
+	}
 
-	assert.Must(utils.ShellExec(ctx, "git", "commit", "-m", strconv.Quote(msg)))
-	if err := ensurePushPolicy(repoRoot, utils.GetBranchName(), flags.overridePolicy); err != nil {
+	// 直接调用 git，保留多行消息（如 Refs 尾注）中的换行
+	if flags.amend {
+		done := timing.Track(ctx, timing.PhaseGit, "git commit --amend")
+		err = utils.CommitAmend(msg, sign)
+		done()
+	} else {
+		done := timing.Track(ctx, timing.PhaseGit, "git commit")
+		err = utils.Commit(msg, sign)
+		done()
+	}
+	if err != nil {
 		return err
 	}
-	utils.GitPush(ctx, "--force-with-lease", "origin", utils.GetBranchName())
-	if flags.showPrompt && !useCandidates {
-		fmt.Println("\n" + generatePrompt + "\n")
+	committed = true
+	clearLastMessage(repoRoot)
+	clearCheckpoint(repoRoot)
+	markPlanStep(repoRoot, msg)
+	if !pushEnabled(params.CommitCfg, flags) {
+		logPushSkipped()
+		return nil
 	}
-	log.Info().Str("message", msg).Bool("candidates", useCandidates).Msg("commit message generated")
-	workflow.PrintRecommendations(os.Stdout, "commit")
-	return nil
+	if err := ensurePushPolicy(repoRoot, utils.GetBranchName(), flags.overridePolicy); err != nil {
+		return err
+	}
+	_, err = pushCurrentBranch(ctx, params, flags.amend || flags.squashedPushed)
+	return err
 }
 
 func mustRepoRoot() string {
@@ -258,20 +545,139 @@ func mustRepoRoot() string {
 	return wd
 }
 
-func shouldUseCandidates(flags *flagOptions, repoCfg repoconfig.Bundle, params cmdParams) bool {
-	if flags != nil && flags.single {
-		return false
+// commitStyle 解析提交信息语言与标题长度：命令行参数 > 仓库 .fastgit/commit.yaml > config.yaml 的 commit 配置 > 默认值
+func commitStyle(flags *flagOptions, repoCfg repoconfig.Bundle, params cmdParams) (string, int) {
+	var locale string
+	var maxLength int
+	for _, cfg := range params.CommitCfg {
+		if cfg == nil {
+			continue
+		}
+		if cfg.Locale != "" {
+			locale = cfg.Locale
+		}
+		if cfg.MaxLength > 0 {
+			maxLength = cfg.MaxLength
+		}
+	}
+	locale, maxLength = repoCfg.CommitStyle(locale, maxLength)
+
+	if flags != nil {
+		if lang := strings.TrimSpace(flags.lang); lang != "" {
+			locale = lang
+		}
+		if flags.maxLength > 0 {
+			maxLength = int(flags.maxLength)
+		}
+	}
+	return locale, maxLength
+}
+
+// commitPrompts 返回单条生成的 system prompt 与候选模式的附加约定；
+// 配置了团队 prompt 模板时，模板同时替换内置 prompt 并作为候选约定；commit.repo_context 开启时两者都附上仓库上下文；
+// 仓库有 git commit.template 或 .gitmessage 时要求模型按模板填写
+func commitPrompts(flags *flagOptions, repoCfg repoconfig.Bundle, params cmdParams, repoRoot, branch, scope, ticketRef string, diff *utils.GetStagedDiffRsp) (string, string, error) {
+	prompt, guidance, err := basePrompts(flags, repoCfg, params, branch, scope, ticketRef, diff)
+	if err != nil {
+		return "", "", err
 	}
-	if flags != nil && flags.candidates {
-		return true
+	if extra := repoContextPrompt(params.CommitCfg, repoRoot); extra != "" {
+		prompt, guidance = prompt+"\n\n"+extra, guidance+"\n\n"+extra
+	}
+	if tpl := loadGitTemplate(context.Background(), repoRoot); tpl != nil {
+		prompt, guidance = commitmsg.AppendGitTemplate(prompt, tpl), commitmsg.AppendGitTemplate(guidance, tpl)
+	}
+	return prompt, guidance, nil
+}
+
+// basePrompts 按风格、工单与 prompt 模板构建 commitPrompts 的基础部分
+func basePrompts(flags *flagOptions, repoCfg repoconfig.Bundle, params cmdParams, branch, scope, ticketRef string, diff *utils.GetStagedDiffRsp) (string, string, error) {
+	locale, maxLength := commitStyle(flags, repoCfg, params)
+	prompt := commitmsg.AppendTicket(typePrompt(stylePrompt(repoCfg, locale, maxLength, scope), flags, repoCfg, params), ticketRef)
+	if flags != nil && flags.body {
+		prompt = commitmsg.AppendBody(prompt)
+	}
+	guidance := typePrompt(fmt.Sprintf("Message language: %s\nNo candidate may exceed %d characters.", locale, maxLength), flags, repoCfg, params)
+
+	path, err := promptTemplatePath(params.CommitCfg, repoCfg)
+	if err != nil {
+		return "", "", err
+	}
+	if path == "" {
+		return prompt, guidance, nil
+	}
+	repo, _ := utils.GetRepositoryName()
+	prompt, err = buildPrompt(path, prompt, commitmsg.PromptVars{
+		Branch:    branch,
+		Repo:      repo,
+		Locale:    locale,
+		MaxLength: maxLength,
+		Style:     repoCfg.Commit.Style,
+		Types:     repoCfg.Commit.Types,
+		Scope:     scope,
+		Ticket:    ticketRef,
+	}, diff)
+	if err != nil {
+		return "", "", err
+	}
+	prompt = typePrompt(prompt, flags, repoCfg, params)
+	if flags != nil && flags.body {
+		prompt = commitmsg.AppendBody(prompt)
+	}
+	return prompt, prompt, nil
+}
+
+// withMessageStyle 合并 config.yaml 中的 commit.style / commit.gitmoji，仓库 .fastgit/commit.yaml 优先
+func withMessageStyle(repoCfg repoconfig.Bundle, params cmdParams) repoconfig.Bundle {
+	var style string
+	gitmoji := map[string]string{}
+	for _, cfg := range params.CommitCfg {
+		if cfg == nil {
+			continue
+		}
+		if cfg.Style != "" {
+			style = cfg.Style
+		}
+		maps.Copy(gitmoji, cfg.Gitmoji)
+	}
+	repoCfg = repoCfg.WithMessageStyle(style, gitmoji)
+	if !repoconfig.ValidStyle(repoCfg.Commit.Style) {
+		log.Warn().Str("style", repoCfg.Commit.Style).Msg("unknown commit.style, falling back to conventional")
+		repoCfg.Commit.Style = repoconfig.StyleConventional
+	}
+	return repoCfg
+}
+
+// stylePrompt 按 commit.style 生成内置 prompt：gitmoji 附带类型到表情的映射，plain 不要求类型与 scope
+func stylePrompt(repoCfg repoconfig.Bundle, locale string, maxLength int, scope string) string {
+	switch repoCfg.Commit.Style {
+	case repoconfig.StylePlain:
+		return commitmsg.GeneratePrompt(locale, maxLength, commitmsg.EmptyCommitType)
+	case repoconfig.StyleGitmoji:
+		prompt := commitmsg.GeneratePrompt(locale, maxLength, commitmsg.GitmojiCommitType) + "\n" + repoCfg.GitmojiGuide()
+		return commitmsg.AppendScope(commitmsg.AppendAllowedTypes(prompt, repoCfg.Commit.Types), scope)
+	default:
+		prompt := commitmsg.GeneratePrompt(locale, maxLength, commitmsg.ConventionalCommitType)
+		return commitmsg.AppendScope(commitmsg.AppendAllowedTypes(prompt, repoCfg.Commit.Types), scope)
+	}
+}
+
+// candidateTotal 返回要生成的候选条数，小于 2 时走单条生成
+func candidateTotal(flags *flagOptions, repoCfg repoconfig.Bundle, params cmdParams) int {
+	// 候选列表每条只有一行，--body 固定走单条生成
+	if flags != nil && (flags.single || flags.body) {
+		return 0
+	}
+	if flags != nil && flags.candidates > 0 {
+		return int(flags.candidates)
 	}
 	if repoCfg.Commit.CandidatesDefault {
-		return true
+		return aiprovider.DefaultCandidateCount
 	}
 	for _, cfg := range params.CommitCfg {
 		if cfg != nil && cfg.CandidatesDefault {
-			return true
+			return aiprovider.DefaultCandidateCount
 		}
 	}
-	return false
+	return 0
 }
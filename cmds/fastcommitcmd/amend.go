@@ -0,0 +1,18 @@
+package fastcommitcmd
+
+import (
+	"github.com/pubgo/funk/v2/errors"
+
+	"github.com/pubgo/fastgit/pkg/gitshell"
+)
+
+// checkAmend 校验 AI 路径的 --amend：需要已有提交，且改写单个提交，不能与 --split 同时使用
+func checkAmend(flags *flagOptions) error {
+	if flags.split {
+		return errors.New("--amend rewrites the last commit and cannot be combined with --split")
+	}
+	if _, err := gitshell.RunInDir(mustRepoRoot(), "rev-parse", "--verify", "--quiet", "HEAD"); err != nil {
+		return errors.New("--amend needs an existing commit to rewrite")
+	}
+	return nil
+}
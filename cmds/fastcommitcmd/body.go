@@ -0,0 +1,113 @@
+package fastcommitcmd
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/pubgo/funk/v2/log"
+	"github.com/yarlson/tap"
+
+	"github.com/pubgo/fastgit/pkg/timing"
+	"github.com/pubgo/fastgit/utils"
+)
+
+const (
+	bodyKeep = "keep"
+	bodyEdit = "edit"
+	bodyDrop = "drop"
+)
+
+const bodyEditorHint = "\n# Edit the commit body. Lines starting with '#' are ignored; an empty body drops it.\n"
+
+// messageEditor 返回确认提交信息的方式：--body 时标题与正文分开编辑
+func messageEditor(flags *flagOptions) func(context.Context, string) string {
+	if flags != nil && flags.body && !utils.NonInteractive() {
+		return editSubjectBody
+	}
+	return editMessage
+}
+
+// editSubjectBody 标题在终端单行编辑，正文可保留、在编辑器中修改或丢弃；返回空字符串表示放弃提交
+func editSubjectBody(ctx context.Context, initial string) string {
+	subject, body, _ := strings.Cut(strings.TrimSpace(initial), "\n")
+	body = strings.TrimSpace(body)
+
+	done := timing.Track(ctx, timing.PhaseUI, "edit commit subject")
+	subject = strings.TrimSpace(tap.Text(ctx, tap.TextOptions{
+		Message:      "commit subject(update or enter):",
+		InitialValue: strings.TrimSpace(subject),
+		DefaultValue: strings.TrimSpace(subject),
+		Placeholder:  "update or enter",
+	}))
+	done()
+	if subject == "" {
+		return ""
+	}
+	if body == "" {
+		return subject
+	}
+
+	tap.Message(body)
+	done = timing.Track(ctx, timing.PhaseUI, "edit commit body")
+	action := tap.Select[string](ctx, tap.SelectOptions[string]{
+		Message: "Commit body:",
+		Options: []tap.SelectOption[string]{
+			{Value: bodyKeep, Label: "Keep it"},
+			{Value: bodyEdit, Label: "Edit in editor", Hint: utils.Editor(ctx)},
+			{Value: bodyDrop, Label: "Drop body", Hint: "subject only"},
+		},
+	})
+	switch action {
+	case "":
+		done()
+		return ""
+	case bodyEdit:
+		edited, err := editInEditor(ctx, body)
+		if err != nil {
+			log.Warn().Err(err).Msg("failed to edit commit body, keeping the generated one")
+		} else {
+			body = edited
+		}
+	case bodyDrop:
+		body = ""
+	}
+	done()
+
+	if body == "" {
+		return subject
+	}
+	return subject + "\n\n" + body
+}
+
+// editInEditor 在临时文件中打开编辑器（utils.Editor）编辑正文，忽略 # 开头的注释行
+func editInEditor(ctx context.Context, body string) (string, error) {
+	f, err := os.CreateTemp("", "fastgit-body-*.txt")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(body + "\n" + bodyEditorHint); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+
+	if err := utils.EditFiles(ctx, f.Name()); err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		return "", err
+	}
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, strings.TrimRight(line, " \t"))
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n")), nil
+}
@@ -3,8 +3,10 @@ package fastcommitcmd
 import (
 	"context"
 	"fmt"
+	"os"
 
 	"github.com/pubgo/fastgit/cmds/checkcmd"
+	"github.com/pubgo/fastgit/cmds/cicmd"
 	"github.com/pubgo/fastgit/pkg/repoconfig"
 )
 
@@ -20,6 +22,28 @@ func runPreCommitCheck(ctx context.Context, repoRoot string, skip bool) error {
 	if err != nil {
 		return fmt.Errorf("pre-commit check failed: %w\nhint: fix issues, or use --skip-check to bypass", err)
 	}
+	return runCIGate(ctx, repoRoot)
+}
+
+// runCIGate 在 .fastgit/ci.yaml 中 commit: true 时，提交前本地运行 CI job
+func runCIGate(ctx context.Context, repoRoot string) error {
+	cfg := cicmd.LoadConfig(repoRoot)
+	if !cfg.Commit {
+		return nil
+	}
+	plan, err := cfg.Plan(repoRoot, nil)
+	if err != nil {
+		return err
+	}
+	if len(plan) == 0 {
+		return nil
+	}
+
+	results, err := cicmd.Run(ctx, plan, cicmd.RunOptions{RepoRoot: repoRoot, Output: os.Stdout})
+	fmt.Println(cicmd.FormatSummary(results))
+	if err != nil {
+		return fmt.Errorf("ci gate failed: %w\nhint: run `fastgit ci run` to reproduce, or use --skip-check to bypass", err)
+	}
 	return nil
 }
 
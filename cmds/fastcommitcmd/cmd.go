@@ -6,7 +6,10 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pubgo/funk/v2/errors"
 	"github.com/pubgo/funk/v2/log"
@@ -15,46 +18,142 @@ import (
 
 	"github.com/pubgo/fastgit/pkg/aiprovider"
 	"github.com/pubgo/fastgit/pkg/gitconflict"
+	"github.com/pubgo/fastgit/pkg/msgtemplate"
+	"github.com/pubgo/fastgit/pkg/ticket"
 	"github.com/pubgo/fastgit/utils"
 )
 
 type flagOptions struct {
 	showPrompt     bool
+	debugLLM       debugLLMPath
 	fastCommit     bool
 	amend          bool
-	candidates     bool
+	candidates     candidateCount
 	single         bool
 	skipCheck      bool
 	skipPolicy     bool
 	overridePolicy bool
+	allowSecrets   bool
+	template       string
+	provider       string
+	lang           string
+	maxLength      int64
+	body           bool
+	review         bool
+	sign           bool
+	split          bool
+	patch          bool
+	noPush         bool
+	diffstat       bool
+	commitType     string
+	plan           string
+	planClear      bool
+	last           bool
+	watch          bool
+	watchIdle      time.Duration
+
+	// squashedPushed 由 squashFastCommits 设置：合并的 --fast 提交已推送过，推送需带 --force-with-lease
+	squashedPushed bool
+}
+
+// candidateCount 是 --candidates 的取值：单写 --candidates 取默认个数，也可写 --candidates=5
+type candidateCount int
+
+func (c *candidateCount) String() string { return strconv.Itoa(int(*c)) }
+
+func (c *candidateCount) Set(v string) error {
+	n, err := strconv.Atoi(strings.TrimSpace(v))
+	if err != nil || n < 0 {
+		return fmt.Errorf("invalid candidate count %q", v)
+	}
+	*c = candidateCount(n)
+	return nil
+}
+
+func (c *candidateCount) Type() string { return "int" }
+
+func (c *candidateCount) NoOptDefValue() string {
+	return strconv.Itoa(aiprovider.DefaultCandidateCount)
 }
 
 type Config struct {
-	GenVersion        bool `yaml:"gen_version"`
-	CandidatesDefault bool `yaml:"candidates_default"`
+	GenVersion        bool                   `yaml:"gen_version"`
+	CandidatesDefault bool                   `yaml:"candidates_default"`
+	Templates         []msgtemplate.Template `yaml:"templates"`
+	// AutoSetUpstream 分支无上游时推送自动加 --set-upstream，缺省为 true
+	AutoSetUpstream *bool `yaml:"auto_set_upstream"`
+	// AutoPush 提交后自动推送当前分支，缺省为 true；需要先评审再推送的团队设为 false，等同总是传 --no-push
+	AutoPush *bool `yaml:"auto_push"`
+	// Locale / MaxLength 生成提交信息的语言与标题长度，仓库 .fastgit/commit.yaml 中显式配置时以仓库为准
+	Locale    string `yaml:"locale"`
+	MaxLength int    `yaml:"max_length"`
+	// PromptTemplate 自定义 AI 提交 prompt 的 Go 模板文件，仓库内 .fastgit/commit.yaml 的同名配置优先
+	PromptTemplate string `yaml:"prompt_template"`
+	// Style 提交信息风格 conventional|gitmoji|plain，Gitmoji 覆盖类型到表情的映射；仓库 .fastgit/commit.yaml 优先
+	Style   string            `yaml:"style"`
+	Gitmoji map[string]string `yaml:"gitmoji"`
+	// DiffTokenBudget 交给模型的 diff token 上限，超出时先分块摘要，缺省为 utils.DefaultDiffTokenBudget
+	DiffTokenBudget int `yaml:"diff_token_budget"`
+	// Exclude 不发给 AI 的路径或通配符（lockfile、生成代码、dist/ 等），与仓库 .fastgit/commit.yaml 的 exclude 合并
+	Exclude []string `yaml:"exclude"`
+	// IssueRef 分支名带 issue 编号（feat/1234-x、issue-1234、gh-1234、#1234）时追加的引用模板，{{.Issue}} 为编号；
+	// `Key: value` 形式作为尾注，其它如 "(#{{.Issue}})" 接在标题后；缺省为 ticket.DefaultIssueRef，空字符串关闭
+	IssueRef *string `yaml:"issue_ref"`
+	// Prefetch 在提交前检查与 --review 确认期间提前发起生成请求，缺省为 true
+	Prefetch *bool `yaml:"prefetch"`
+	// Sign 提交时加 -S 签名（gpg.format 决定 GPG 或 SSH），等同总是传 --sign
+	Sign bool `yaml:"sign"`
+	// RepoContext 在 prompt 中附上最近提交标题、README 首段与识别出的语言/框架，让信息风格贴近仓库历史，缺省关闭
+	RepoContext bool `yaml:"repo_context"`
+	// RepoContextCommits 附带的最近提交标题条数，缺省为 repocontext.DefaultSubjects
+	RepoContextCommits int `yaml:"repo_context_commits"`
+	// Lint 提交前按 commitlint 规则校验最终信息，违规时请模型修正；仓库根目录的 .commitlintrc 优先
+	Lint LintConfig `yaml:"lint"`
+	// SizeBudget 暂存改动超出文件数或增删行数上限时提示改用 --split 拆分，缺省 25 个文件 / 400 行
+	SizeBudget SizeBudget `yaml:"size_budget"`
+	// Secrets 提交前与发给 AI 前扫描暂存改动中的密钥，命中时阻止提交
+	Secrets SecretsConfig `yaml:"secrets"`
+	// DebugCheck 提交前按 hunk 标出新增的调试输出、TODO/FIXME 与注释掉的代码，交互模式下可撤出暂存区
+	DebugCheck DebugCheckConfig `yaml:"debug_check"`
+	// FastTemplate --fast 提交信息模板，可用 {{.Branch}} {{.Date}} {{.Time}} {{.Files}} {{.Ticket}} {{.Issue}} 等；
+	// 缺省为 DefaultFastTemplate。日期、时间与文件数之外的部分用于识别此前的 --fast 提交
+	FastTemplate string `yaml:"fast_template"`
+	// DiffstatFooter 在提交信息末尾追加 Diffstat/Renamed 尾注（文件数、增删行数、重命名），由 git 统计生成，等同总是传 --diffstat
+	DiffstatFooter bool `yaml:"diffstat_footer"`
+	// AllowedTypes 允许的提交类型（如 feat、fix、chore），同时约束 prompt 与提交前校验，其它类型在提交前被拒绝；空表示不限制
+	AllowedTypes []string `yaml:"allowed_types"`
 }
 
 type cmdParams struct {
 	AI        aiprovider.Provider
 	CommitCfg []*Config
+	TicketCfg []*ticket.Config
 }
 
 func New() *redant.Command {
 	var flags = new(flagOptions)
 
 	app := &redant.Command{
-		Use:   "commit",
-		Short: "Intelligent generation of git commit message",
+		Use:      "commit",
+		Short:    "Intelligent generation of git commit message",
+		Metadata: utils.NonInteractiveMetadata(),
 		Children: []*redant.Command{
+			newWipCommand(),
 			{
-				Use:   "ai",
-				Short: "AI powered commit flow",
+				Use:      "ai",
+				Short:    "AI powered commit flow",
+				Metadata: utils.NonInteractiveMetadata(),
 				Options: []redant.Option{
 					{
 						Flag:        "prompt",
 						Description: "Show prompt.",
 						Value:       redant.BoolOf(&flags.showPrompt),
 					},
+					{
+						Flag:        "debug-llm",
+						Description: "Append every model request (as sent, after redaction and truncation), token counts, model, latency and raw response to a file (--debug-llm=path, default .git/fastgit/llm-debug.log).",
+						Value:       &flags.debugLLM,
+					},
 					{
 						Flag:        "fast",
 						Description: "Quickly generate messages without prompts.",
@@ -62,13 +161,13 @@ func New() *redant.Command {
 					},
 					{
 						Flag:        "amend",
-						Description: "Amend the last commit.",
+						Description: "Amend the last commit: regenerate its message from HEAD plus staged changes and push with --force-with-lease.",
 						Value:       redant.BoolOf(&flags.amend),
 					},
 					{
 						Flag:        "candidates",
-						Description: "Generate 3 commit message candidates to pick from.",
-						Value:       redant.BoolOf(&flags.candidates),
+						Description: "Generate N commit message candidates to pick from (--candidates=N, default 3).",
+						Value:       &flags.candidates,
 					},
 					{
 						Flag:        "single",
@@ -85,11 +184,115 @@ func New() *redant.Command {
 						Description: "Bypass .fastgit/policy.yaml hard enforcement.",
 						Value:       redant.BoolOf(&flags.skipPolicy),
 					},
+					{
+						Flag:        "allow-secrets",
+						Description: "Commit even if the secret scan (commit.secrets) flags staged changes; matches are redacted from the AI prompt.",
+						Value:       redant.BoolOf(&flags.allowSecrets),
+					},
 					{
 						Flag:        "override-policy",
 						Description: "Bypass protected branch push block from .fastgit/policy.yaml.",
 						Value:       redant.BoolOf(&flags.overridePolicy),
 					},
+					{
+						Flag:        "template",
+						Description: "Use a message template from commit.templates instead of AI (see `fastgit template list`).",
+						Value:       redant.StringOf(&flags.template),
+					},
+					{
+						Flag:        "provider",
+						Description: "AI backend override: openai|gemini|anthropic|ollama|copilot.",
+						Value:       redant.StringOf(&flags.provider),
+					},
+					{
+						Flag:        "lang",
+						Description: "Commit message language, e.g. en, zh-CN (overrides commit.locale).",
+						Value:       redant.StringOf(&flags.lang),
+					},
+					{
+						Flag:        "max-length",
+						Description: "Max commit subject length (overrides commit.max_length).",
+						Value:       redant.Int64Of(&flags.maxLength),
+					},
+					{
+						Flag:        "body",
+						Description: "Generate a subject plus a wrapped body (what/why, BREAKING CHANGE footer) and edit them separately.",
+						Value:       redant.BoolOf(&flags.body),
+					},
+					{
+						Flag:        "review",
+						Description: "Confirm the staged file list before committing; the message is generated meanwhile.",
+						Value:       redant.BoolOf(&flags.review),
+					},
+					{
+						Flag:        "sign",
+						Description: "GPG/SSH sign the commit (git commit -S); defaults to commit.sign.",
+						Value:       redant.BoolOf(&flags.sign),
+					},
+					{
+						Flag:        "split",
+						Description: "Let AI group the staged files into several logical commits and create them one by one.",
+						Value:       redant.BoolOf(&flags.split),
+					},
+					{
+						Flag:        "patch",
+						Shorthand:   "p",
+						Description: "Pick hunks to stage in a TUI (like git add -p) instead of staging all tracked changes.",
+						Value:       redant.BoolOf(&flags.patch),
+					},
+					{
+						Flag:        "yes",
+						Shorthand:   "y",
+						Description: "Non-interactive: no prompts or editors, take the first generated message; runs without a TTY.",
+						Value:       redant.BoolOf(utils.NonInteractiveVar()),
+						Envs:        []string{"FASTGIT_NON_INTERACTIVE"},
+					},
+					{
+						Flag:        "non-interactive",
+						Description: "Alias of --yes.",
+						Value:       redant.BoolOf(utils.NonInteractiveVar()),
+					},
+					{
+						Flag:        "no-push",
+						Description: "Commit locally without pushing (overrides commit.auto_push).",
+						Value:       redant.BoolOf(&flags.noPush),
+					},
+					{
+						Flag:        "type",
+						Description: "Force the conventional commit type, e.g. --type fix (must be in commit.allowed_types when set).",
+						Value:       redant.StringOf(&flags.commitType),
+					},
+					{
+						Flag:        "diffstat",
+						Description: "Append a Diffstat footer (files changed, insertions/deletions, renames) to the commit message (same as commit.diffstat_footer).",
+						Value:       redant.BoolOf(&flags.diffstat),
+					},
+					{
+						Flag:        "last",
+						Description: "Reuse the message saved when the previous commit failed (.git/fastgit/last-message) instead of calling the model.",
+						Value:       redant.BoolOf(&flags.last),
+					},
+					{
+						Flag:        "plan",
+						Description: "Plan commits for a task description; later commits on this branch follow the plan.",
+						Value:       redant.StringOf(&flags.plan),
+					},
+					{
+						Flag:        "plan-clear",
+						Description: "Drop the commit plan of the current worktree.",
+						Value:       redant.BoolOf(&flags.planClear),
+					},
+					{
+						Flag:        "watch",
+						Description: "Watch the worktree and suggest a commit message with a desktop notification once changes settle; nothing is committed.",
+						Value:       redant.BoolOf(&flags.watch),
+					},
+					{
+						Flag:        "watch-idle",
+						Description: "How long changes must stay quiet before --watch suggests a commit.",
+						Value:       redant.DurationOf(&flags.watchIdle),
+						Default:     defaultWatchIdle.String(),
+					},
 				},
 				Handler: func(ctx context.Context, i *redant.Invocation) (gErr error) {
 					defer result.RecoveryErr(&gErr, func(err error) error {
@@ -119,6 +322,11 @@ func New() *redant.Command {
 				Description: "Show prompt.",
 				Value:       redant.BoolOf(&flags.showPrompt),
 			},
+			{
+				Flag:        "debug-llm",
+				Description: "Append every model request (as sent, after redaction and truncation), token counts, model, latency and raw response to a file (--debug-llm=path, default .git/fastgit/llm-debug.log).",
+				Value:       &flags.debugLLM,
+			},
 			{
 				Flag:        "fast",
 				Description: "Quickly generate messages without prompts.",
@@ -126,13 +334,13 @@ func New() *redant.Command {
 			},
 			{
 				Flag:        "amend",
-				Description: "Amend the last commit.",
+				Description: "Amend the last commit: regenerate its message from HEAD plus staged changes and push with --force-with-lease.",
 				Value:       redant.BoolOf(&flags.amend),
 			},
 			{
 				Flag:        "candidates",
-				Description: "Generate 3 commit message candidates to pick from.",
-				Value:       redant.BoolOf(&flags.candidates),
+				Description: "Generate N commit message candidates to pick from (--candidates=N, default 3).",
+				Value:       &flags.candidates,
 			},
 			{
 				Flag:        "single",
@@ -149,11 +357,115 @@ func New() *redant.Command {
 				Description: "Bypass .fastgit/policy.yaml hard enforcement.",
 				Value:       redant.BoolOf(&flags.skipPolicy),
 			},
+			{
+				Flag:        "allow-secrets",
+				Description: "Commit even if the secret scan (commit.secrets) flags staged changes; matches are redacted from the AI prompt.",
+				Value:       redant.BoolOf(&flags.allowSecrets),
+			},
 			{
 				Flag:        "override-policy",
 				Description: "Bypass protected branch push block from .fastgit/policy.yaml.",
 				Value:       redant.BoolOf(&flags.overridePolicy),
 			},
+			{
+				Flag:        "template",
+				Description: "Use a message template from commit.templates instead of AI (see `fastgit template list`).",
+				Value:       redant.StringOf(&flags.template),
+			},
+			{
+				Flag:        "provider",
+				Description: "AI backend override: openai|gemini|anthropic|ollama|copilot.",
+				Value:       redant.StringOf(&flags.provider),
+			},
+			{
+				Flag:        "lang",
+				Description: "Commit message language, e.g. en, zh-CN (overrides commit.locale).",
+				Value:       redant.StringOf(&flags.lang),
+			},
+			{
+				Flag:        "max-length",
+				Description: "Max commit subject length (overrides commit.max_length).",
+				Value:       redant.Int64Of(&flags.maxLength),
+			},
+			{
+				Flag:        "body",
+				Description: "Generate a subject plus a wrapped body (what/why, BREAKING CHANGE footer) and edit them separately.",
+				Value:       redant.BoolOf(&flags.body),
+			},
+			{
+				Flag:        "review",
+				Description: "Confirm the staged file list before committing; the message is generated meanwhile.",
+				Value:       redant.BoolOf(&flags.review),
+			},
+			{
+				Flag:        "sign",
+				Description: "GPG/SSH sign the commit (git commit -S); defaults to commit.sign.",
+				Value:       redant.BoolOf(&flags.sign),
+			},
+			{
+				Flag:        "split",
+				Description: "Let AI group the staged files into several logical commits and create them one by one.",
+				Value:       redant.BoolOf(&flags.split),
+			},
+			{
+				Flag:        "patch",
+				Shorthand:   "p",
+				Description: "Pick hunks to stage in a TUI (like git add -p) instead of staging all tracked changes.",
+				Value:       redant.BoolOf(&flags.patch),
+			},
+			{
+				Flag:        "yes",
+				Shorthand:   "y",
+				Description: "Non-interactive: no prompts or editors, take the first generated message; runs without a TTY.",
+				Value:       redant.BoolOf(utils.NonInteractiveVar()),
+				Envs:        []string{"FASTGIT_NON_INTERACTIVE"},
+			},
+			{
+				Flag:        "non-interactive",
+				Description: "Alias of --yes.",
+				Value:       redant.BoolOf(utils.NonInteractiveVar()),
+			},
+			{
+				Flag:        "no-push",
+				Description: "Commit locally without pushing (overrides commit.auto_push).",
+				Value:       redant.BoolOf(&flags.noPush),
+			},
+			{
+				Flag:        "type",
+				Description: "Force the conventional commit type, e.g. --type fix (must be in commit.allowed_types when set).",
+				Value:       redant.StringOf(&flags.commitType),
+			},
+			{
+				Flag:        "diffstat",
+				Description: "Append a Diffstat footer (files changed, insertions/deletions, renames) to the commit message (same as commit.diffstat_footer).",
+				Value:       redant.BoolOf(&flags.diffstat),
+			},
+			{
+				Flag:        "last",
+				Description: "Reuse the message saved when the previous commit failed (.git/fastgit/last-message) instead of calling the model.",
+				Value:       redant.BoolOf(&flags.last),
+			},
+			{
+				Flag:        "plan",
+				Description: "Plan commits for a task description; later commits on this branch follow the plan.",
+				Value:       redant.StringOf(&flags.plan),
+			},
+			{
+				Flag:        "plan-clear",
+				Description: "Drop the commit plan of the current worktree.",
+				Value:       redant.BoolOf(&flags.planClear),
+			},
+			{
+				Flag:        "watch",
+				Description: "Watch the worktree and suggest a commit message with a desktop notification once changes settle; nothing is committed.",
+				Value:       redant.BoolOf(&flags.watch),
+			},
+			{
+				Flag:        "watch-idle",
+				Description: "How long changes must stay quiet before --watch suggests a commit.",
+				Value:       redant.DurationOf(&flags.watchIdle),
+				Default:     defaultWatchIdle.String(),
+			},
 		},
 		Handler: func(ctx context.Context, i *redant.Invocation) (gErr error) {
 			defer result.RecoveryErr(&gErr, func(err error) error {
@@ -181,10 +493,9 @@ func New() *redant.Command {
 	return app
 }
 
-// getFirstNonPrefixCommit 获取第一个没有prefixMsg的提交ID
-func getFirstNonPrefixCommit(ctx context.Context, prefixMsg string) string {
-	// 获取当前分支最近的提交列表，找到第一个不是prefixMsg开头的提交
-	branchName := utils.GetBranchName()
+// getFirstNonPrefixCommit 获取第一个不是 --fast 提交（标题不匹配 fastMsg）的提交ID
+func getFirstNonPrefixCommit(ctx context.Context, branchName string, fastMsg *regexp.Regexp) string {
+	// 获取当前分支最近的提交列表，找到第一个标题不匹配fastMsg的提交
 	cmd := exec.CommandContext(ctx, "git", "log", branchName, "--oneline", "--pretty=format:%H %s", "-20") // 增加到20个提交以确保找到
 	output, err := cmd.Output()
 	if err != nil {
@@ -206,20 +517,19 @@ func getFirstNonPrefixCommit(ctx context.Context, prefixMsg string) string {
 		commitHash := parts[0]
 		commitMsg := parts[1]
 
-		// 如果提交消息不以prefixMsg开头，返回这个提交的hash
-		if !strings.HasPrefix(commitMsg, prefixMsg) {
+		// 如果提交消息不匹配fastMsg，返回这个提交的hash
+		if !fastMsg.MatchString(commitMsg) {
 			return commitHash
 		}
 	}
 
-	// 如果所有提交都以prefixMsg开头，返回空字符串
+	// 如果所有提交都匹配fastMsg，返回空字符串
 	return ""
 }
 
-// getCommitsToSquash 遍历git log，找到以prefixMsg开头的提交（这些是需要合并的提交）
-func getCommitsToSquash(ctx context.Context, prefixMsg string) []string {
-	// 获取当前分支最近的提交列表，直到遇到不是prefixMsg开头的提交
-	branchName := utils.GetBranchName()
+// getCommitsToSquash 遍历git log，找到匹配fastMsg的提交（这些是需要合并的提交）
+func getCommitsToSquash(ctx context.Context, branchName string, fastMsg *regexp.Regexp) []string {
+	// 获取当前分支最近的提交列表，直到遇到不匹配fastMsg的提交
 	cmd := exec.CommandContext(ctx, "git", "log", branchName, "--oneline", "--pretty=format:%H %s", "-10") // 限制最近10个提交
 	output, err := cmd.Output()
 	if err != nil {
@@ -243,11 +553,11 @@ func getCommitsToSquash(ctx context.Context, prefixMsg string) []string {
 		commitHash := parts[0]
 		commitMsg := parts[1]
 
-		// 如果提交消息以prefixMsg开头，添加到待合并列表
-		if strings.HasPrefix(commitMsg, prefixMsg) {
+		// 如果提交消息匹配fastMsg，添加到待合并列表
+		if fastMsg.MatchString(commitMsg) {
 			commitsToSquash = append(commitsToSquash, commitHash)
 		} else {
-			// 如果遇到不是prefixMsg开头的提交，停止遍历
+			// 如果遇到不匹配fastMsg的提交，停止遍历
 			break
 		}
 	}
@@ -294,7 +604,12 @@ func handleMergeConflict(ctx context.Context) {
 	output, _ := cmd.Output()
 	files := strings.Split(strings.TrimSpace(string(output)), "\n")
 
-	editor := getEditor()
+	// 非交互模式不打开编辑器，只输出摘要
+	if utils.NonInteractive() {
+		informUserToAmendAndPush()
+		return
+	}
+	editor := utils.Editor(ctx)
 
 	for _, file := range files {
 		if file == "" {
@@ -302,13 +617,8 @@ func handleMergeConflict(ctx context.Context) {
 		}
 		fmt.Printf("📝 Conflict in file: %s\n", file)
 
-		editCmd := exec.Command(editor, file)
-		editCmd.Stdin = os.Stdin
-		editCmd.Stdout = os.Stdout
-		editCmd.Stderr = os.Stderr
-
 		fmt.Printf("Opening editor '%s'...\n", editor)
-		if err := editCmd.Run(); err != nil {
+		if err := utils.EditorCommand(ctx, file).Run(); err != nil {
 			log.Printf("Failed to edit %s: %v", file, err)
 		}
 	}
@@ -317,29 +627,6 @@ func handleMergeConflict(ctx context.Context) {
 	informUserToAmendAndPush()
 }
 
-func getEditor() string {
-	if e := os.Getenv("EDITOR"); e != "" {
-		return e
-	}
-
-	if _, err := exec.LookPath("zed"); err == nil {
-		return "zed -w"
-	}
-
-	if _, err := exec.LookPath("code"); err == nil {
-		return "code -w"
-	}
-
-	if _, err := exec.LookPath("vim"); err == nil {
-		return "vim"
-	}
-
-	if _, err := exec.LookPath("nano"); err == nil {
-		return "nano"
-	}
-	return "vi"
-}
-
 // 提示用户如何继续
 func informUserToAmendAndPush() {
 	fmt.Println("\n----------------------------------------")
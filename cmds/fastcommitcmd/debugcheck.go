@@ -0,0 +1,140 @@
+package fastcommitcmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pubgo/funk/v2/log"
+	"github.com/yarlson/tap"
+
+	"github.com/pubgo/fastgit/pkg/debugscan"
+	"github.com/pubgo/fastgit/pkg/hunk"
+	"github.com/pubgo/fastgit/utils"
+)
+
+// DebugCheckConfig 是 commit.debug_check：提交前标出新增调试输出、debugger、TODO/FIXME 与注释掉的代码块的 hunk
+type DebugCheckConfig struct {
+	// Enabled 缺省为 true
+	Enabled *bool `yaml:"enabled"`
+	// Rules 追加的正则规则，files 为文件名通配符，空表示所有文件
+	Rules []debugscan.Rule `yaml:"rules"`
+	// Disable 关闭的规则名，内置 go-print、console-log、debugger、todo、commented-code
+	Disable []string `yaml:"disable"`
+	// AllowPaths 不检查的文件通配符或目录，如 examples/
+	AllowPaths []string `yaml:"allow_paths"`
+}
+
+// debugScanner 合并各层 commit.debug_check；关闭时返回 nil
+func debugScanner(cfgs []*Config) (*debugscan.Scanner, error) {
+	enabled := true
+	var opts debugscan.Options
+	for _, cfg := range cfgs {
+		if cfg == nil {
+			continue
+		}
+		dc := cfg.DebugCheck
+		if dc.Enabled != nil {
+			enabled = *dc.Enabled
+		}
+		opts.Rules = append(opts.Rules, dc.Rules...)
+		opts.Disable = append(opts.Disable, dc.Disable...)
+		opts.AllowPaths = append(opts.AllowPaths, dc.AllowPaths...)
+	}
+	if !enabled {
+		return nil, nil
+	}
+	return debugscan.New(opts)
+}
+
+// flaggedHunk 是带有调试残留的一个暂存 hunk
+type flaggedHunk struct {
+	piece    *hunk.Piece
+	findings []string
+}
+
+// checkStagedHunks 按 hunk 检查暂存改动中的调试残留并输出到 stderr；交互模式下可勾选 hunk 撤出暂存区
+// （工作区不变），之后再生成提交信息。只警告，不阻止提交；密钥由 checkSecrets 检查
+func checkStagedHunks(ctx context.Context, cfgs []*Config, repoRoot string) error {
+	debug, err := debugScanner(cfgs)
+	if err != nil || debug == nil {
+		return err
+	}
+
+	diff, err := hunk.CachedDiff(ctx, repoRoot)
+	if err != nil {
+		return fmt.Errorf("read staged diff for hunk check: %w", err)
+	}
+	files, err := hunk.Parse(diff)
+	if err != nil {
+		return err
+	}
+
+	var flagged []*flaggedHunk
+	byHunk := make(map[*hunk.Hunk]*flaggedHunk)
+	flag := func(p *hunk.Piece, finding string) {
+		fh := byHunk[p.Hunk]
+		if fh == nil {
+			fh = &flaggedHunk{piece: p}
+			byHunk[p.Hunk] = fh
+			flagged = append(flagged, fh)
+		}
+		fh.findings = append(fh.findings, finding)
+	}
+
+	for _, p := range hunk.Pieces(files) {
+		for _, f := range debug.ScanHunk(p.File.Path, p.Hunk) {
+			flag(p, f.String())
+		}
+	}
+	if len(flagged) == 0 {
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "staged hunks with debug leftovers:\n")
+	for _, fh := range flagged {
+		fmt.Fprintf(os.Stderr, "  %s\n", hunkLabel(fh.piece))
+		for _, f := range fh.findings {
+			fmt.Fprintf(os.Stderr, "    %s\n", f)
+		}
+	}
+	if utils.NonInteractive() {
+		return nil
+	}
+
+	options := make([]tap.SelectOption[*flaggedHunk], 0, len(flagged))
+	for _, fh := range flagged {
+		options = append(options, tap.SelectOption[*flaggedHunk]{
+			Value: fh,
+			Label: hunkLabel(fh.piece),
+			Hint:  strings.Join(fh.findings, "; "),
+		})
+	}
+	selected := tap.MultiSelect(ctx, tap.MultiSelectOptions[*flaggedHunk]{
+		Message: "Select hunks to unstage (the working tree keeps the changes; press enter to keep all staged)",
+		Options: options,
+	})
+	if len(selected) == 0 {
+		return nil
+	}
+
+	unstage := make([]*hunk.Piece, 0, len(selected))
+	for _, fh := range selected {
+		unstage = append(unstage, fh.piece)
+	}
+	if err := hunk.Unstage(ctx, repoRoot, hunk.BuildUnstagePatch(files, unstage)); err != nil {
+		return fmt.Errorf("unstage hunks: %w", err)
+	}
+	log.Info().Int("hunks", len(unstage)).Msg("unstaged flagged hunks")
+	return nil
+}
+
+func hunkLabel(p *hunk.Piece) string {
+	h := p.Hunk
+	label := fmt.Sprintf("%s @@ +%d,%d @@", p.File.Path, h.NewStart, h.NewLines)
+	if section := strings.TrimSpace(h.Section); section != "" {
+		label += " " + section
+	}
+	return label
+}
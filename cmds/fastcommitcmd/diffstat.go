@@ -0,0 +1,104 @@
+package fastcommitcmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pubgo/funk/v2/log"
+
+	"github.com/pubgo/fastgit/utils"
+)
+
+// maxFooterRenames 尾注中最多列出的重命名条数，其余合并为一行
+const maxFooterRenames = 3
+
+// footerLinePattern 匹配此前追加的 diffstat 尾注行，amend 或 --last 复用信息时先去掉再重新生成
+var footerLinePattern = regexp.MustCompile(`^(Diffstat|Renamed): `)
+
+// diffstatEnabled 报告是否在提交信息末尾追加 diffstat 尾注：--diffstat 或 commit.diffstat_footer
+func diffstatEnabled(cfgs []*Config, flags *flagOptions) bool {
+	if flags != nil && flags.diffstat {
+		return true
+	}
+	for _, cfg := range cfgs {
+		if cfg != nil && cfg.DiffstatFooter {
+			return true
+		}
+	}
+	return false
+}
+
+// withDiffstat 按暂存区（amend 时为 HEAD 父提交到暂存区）的增删行数追加尾注，不调用 AI；读取失败只告警，保留原信息
+func withDiffstat(msg string, amend bool) string {
+	stats, err := utils.StagedDiffStat()
+	if amend {
+		stats, err = utils.AmendDiffStat()
+	}
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to read diffstat, footer skipped")
+		return msg
+	}
+	return appendFooter(msg, diffstatFooter(stats))
+}
+
+// diffstatFooter 生成尾注行：文件数与增删行数，以及最多 maxFooterRenames 条重命名；没有改动时返回 nil
+func diffstatFooter(stats []utils.FileChange) []string {
+	if len(stats) == 0 {
+		return nil
+	}
+	added, removed := 0, 0
+	var renames []string
+	for _, st := range stats {
+		added += st.Added
+		removed += st.Removed
+		if st.OldPath != "" {
+			renames = append(renames, st.OldPath+" -> "+st.Path)
+		}
+	}
+
+	files := "files"
+	if len(stats) == 1 {
+		files = "file"
+	}
+	lines := []string{fmt.Sprintf("Diffstat: %d %s changed, +%d -%d", len(stats), files, added, removed)}
+	for i, r := range renames {
+		if i == maxFooterRenames {
+			lines = append(lines, fmt.Sprintf("Renamed: and %d more", len(renames)-maxFooterRenames))
+			break
+		}
+		lines = append(lines, "Renamed: "+r)
+	}
+	return lines
+}
+
+// appendFooter 去掉旧的 diffstat 尾注后追加 footer；已有尾注段落（如 Refs: #12）时接在其后，否则空一行
+func appendFooter(msg string, footer []string) string {
+	var kept []string
+	for _, line := range strings.Split(strings.TrimSpace(msg), "\n") {
+		if !footerLinePattern.MatchString(line) {
+			kept = append(kept, line)
+		}
+	}
+	msg = strings.TrimSpace(strings.Join(kept, "\n"))
+	if len(footer) == 0 || msg == "" {
+		return msg
+	}
+
+	paragraphs := strings.Split(msg, "\n\n")
+	if len(paragraphs) > 1 && isTrailerBlock(paragraphs[len(paragraphs)-1]) {
+		return msg + "\n" + strings.Join(footer, "\n")
+	}
+	return msg + "\n\n" + strings.Join(footer, "\n")
+}
+
+var trailerLinePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9-]*: \S`)
+
+func isTrailerBlock(paragraph string) bool {
+	for _, line := range strings.Split(paragraph, "\n") {
+		if !trailerLinePattern.MatchString(strings.TrimSpace(line)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,118 @@
+package fastcommitcmd
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pubgo/funk/v2/assert"
+	"github.com/pubgo/funk/v2/log"
+
+	"github.com/pubgo/fastgit/pkg/gitshell"
+	"github.com/pubgo/fastgit/pkg/msgtemplate"
+	"github.com/pubgo/fastgit/utils"
+)
+
+// DefaultFastTemplate 是 commit.fast_template 的缺省值，生成 chore: quick update main at 2006-01-02 15:04:05
+const DefaultFastTemplate = "chore: quick update {{.Branch}} at {{.Date}} {{.Time}}"
+
+// fastFilesSentinel 渲染匹配用正则时代替 {{.Files}} 的数值
+const fastFilesSentinel = 918273645
+
+// fastTemplate 取各层配置中最后一个非空的 commit.fast_template
+func fastTemplate(cfgs []*Config) msgtemplate.Template {
+	tpl := msgtemplate.Template{Name: "fast_template", Message: DefaultFastTemplate}
+	for _, cfg := range cfgs {
+		if cfg != nil && strings.TrimSpace(cfg.FastTemplate) != "" {
+			tpl.Message = cfg.FastTemplate
+		}
+	}
+	return tpl
+}
+
+// fastMessage 渲染 --fast 的提交信息，{{.Files}} 为 git add -A 将要提交的文件数
+func fastMessage(ctx context.Context, cfgs []*Config, branch string) (string, error) {
+	status := utils.ShellExecOutput(ctx, "git", "status", "--porcelain").UnwrapOr("")
+	files := 0
+	for _, line := range strings.Split(status, "\n") {
+		if strings.TrimSpace(line) != "" {
+			files++
+		}
+	}
+
+	vars := fastVars(ctx, branch, time.Now())
+	vars.Files = files
+	return fastTemplate(cfgs).Render(vars)
+}
+
+func fastVars(ctx context.Context, branch string, now time.Time) msgtemplate.Vars {
+	user := strings.TrimSpace(utils.ShellExecOutput(ctx, "git", "config", "user.name").UnwrapOr(""))
+	repo, _ := utils.GetRepositoryName()
+	return msgtemplate.NewVars(branch, user, repo, now)
+}
+
+// fastMatcher 把模板首行转成正则：日期、时间、文件数换成通配，分支、ticket 等保持原样，
+// 用于识别此前的 --fast 提交，以便 --amend 时续写、AI 提交前合并；模板有误时退回缺省模板
+func fastMatcher(ctx context.Context, cfgs []*Config, branch string) *regexp.Regexp {
+	re, err := fastPattern(fastTemplate(cfgs), fastVars(ctx, branch, time.Now()))
+	if err != nil {
+		log.Warn().Err(err).Msg("invalid commit.fast_template, matching the default quick update messages")
+		re, _ = fastPattern(msgtemplate.Template{Name: "fast_template", Message: DefaultFastTemplate}, fastVars(ctx, branch, time.Now()))
+	}
+	return re
+}
+
+// squashFastCommits 把当前分支末尾连续的 --fast 提交 soft reset 掉，改动留在暂存区，由接下来的 AI 提交合并；
+// 被合并的提交中有已推送到上游的时返回 true，之后的推送需带 --force-with-lease
+func squashFastCommits(ctx context.Context, params cmdParams, branch string) bool {
+	fastMsg := fastMatcher(ctx, params.CommitCfg, branch)
+	target := getFirstNonPrefixCommit(ctx, branch, fastMsg)
+	if target == "" {
+		commitsToSquash := getCommitsToSquash(ctx, branch, fastMsg)
+		if len(commitsToSquash) == 0 {
+			return false
+		}
+		if target = getParentCommit(ctx, commitsToSquash[0]); target == "" {
+			target = "HEAD~" + strconv.Itoa(len(commitsToSquash))
+		}
+	}
+
+	pushed := rewritesUpstream(ctx, target)
+	assert.Must(utils.ShellExec(ctx, "git", "reset", "--soft", target))
+	return pushed
+}
+
+// rewritesUpstream 判断把 HEAD soft reset 到 target 是否会丢掉上游已有的提交：
+// HEAD 与上游的分叉点在 target 之后，说明被合并的提交已经推送过
+func rewritesUpstream(ctx context.Context, target string) bool {
+	if !utils.HasUpstream(ctx) {
+		return false
+	}
+	repoRoot := mustRepoRoot()
+	base, err := gitshell.RunInDir(repoRoot, "merge-base", "HEAD", "@{u}")
+	if err != nil || base == "" {
+		return false
+	}
+	_, err = gitshell.RunInDir(repoRoot, "merge-base", "--is-ancestor", base, target)
+	return err != nil
+}
+
+func fastPattern(tpl msgtemplate.Template, vars msgtemplate.Vars) (*regexp.Regexp, error) {
+	const date, clock = "\x00date\x00", "\x00time\x00"
+	vars.Date, vars.Time, vars.Files = date, clock, fastFilesSentinel
+
+	msg, err := tpl.Render(vars)
+	if err != nil {
+		return nil, err
+	}
+	subject, _, _ := strings.Cut(msg, "\n")
+	pattern := regexp.QuoteMeta(strings.TrimSpace(subject))
+	pattern = strings.NewReplacer(
+		date, `\S+`,
+		clock, `\S+`,
+		strconv.Itoa(fastFilesSentinel), `\d+`,
+	).Replace(pattern)
+	return regexp.Compile("^" + pattern)
+}
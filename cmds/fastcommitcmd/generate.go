@@ -0,0 +1,106 @@
+package fastcommitcmd
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pubgo/funk/v2/errors"
+
+	"github.com/pubgo/fastgit/pkg/aiprovider"
+	"github.com/pubgo/fastgit/pkg/commitmsg"
+	"github.com/pubgo/fastgit/pkg/gitshell"
+	"github.com/pubgo/fastgit/pkg/repoconfig"
+	"github.com/pubgo/fastgit/utils"
+)
+
+// MessageRequest 非交互生成提交信息的参数，供 serve --editor 等调用方使用
+type MessageRequest struct {
+	// Diff 为空时读取仓库暂存区（应用 commit.exclude）
+	Diff string
+	// Candidates 大于 1 时生成多条候选
+	Candidates int
+	Locale     string
+	MaxLength  int
+}
+
+// MessageResult 生成结果，Message 已按 commit.style 与推断的 scope 格式化
+type MessageResult struct {
+	Message    string
+	Candidates []aiprovider.CommitCandidate
+	Provider   string
+	Fallback   bool
+}
+
+// GenerateMessage 复用 commit 命令的 prompt、风格与大 diff 摘要逻辑生成提交信息，不做任何交互
+func GenerateMessage(ctx context.Context, ai aiprovider.Provider, cfgs []*Config, repoRoot string, req MessageRequest) (MessageResult, error) {
+	params := cmdParams{AI: ai, CommitCfg: cfgs}
+	repoCfg, _ := repoconfig.Load(repoRoot)
+	repoCfg = withMessageStyle(repoCfg, params)
+
+	diff, err := messageDiff(ctx, params, repoCfg, req.Diff)
+	if err != nil {
+		return MessageResult{}, err
+	}
+	if err := redactSecrets(cfgs, diff); err != nil {
+		return MessageResult{}, err
+	}
+
+	flags := &flagOptions{lang: req.Locale, maxLength: int64(req.MaxLength)}
+	scope := repoCfg.InferScope(diff.Files)
+	prompt, guidance, err := commitPrompts(flags, repoCfg, params, repoRoot, gitshell.DetectBranch(repoRoot), scope, "", diff)
+	if err != nil {
+		return MessageResult{}, err
+	}
+
+	res, err := commitmsg.Generate(ctx, ai, diff, commitmsg.Options{
+		Prompt:      prompt,
+		Guidance:    guidance,
+		Candidates:  req.Candidates,
+		TokenBudget: diffTokenBudget(cfgs),
+	})
+	if err != nil {
+		return MessageResult{}, err
+	}
+	for i := range res.Candidates {
+		res.Candidates[i].Message = repoCfg.FormatMessage(repoconfig.WithScope(res.Candidates[i].Message, scope))
+	}
+	return MessageResult{
+		Message:    repoCfg.FormatMessage(repoconfig.WithScope(res.Message, scope)),
+		Candidates: res.Candidates,
+		Provider:   res.Provider,
+		Fallback:   res.Fallback,
+	}, nil
+}
+
+// messageDiff 返回调用方传入的 diff，未传入时读取暂存区
+func messageDiff(ctx context.Context, params cmdParams, repoCfg repoconfig.Bundle, raw string) (*utils.GetStagedDiffRsp, error) {
+	if strings.TrimSpace(raw) == "" {
+		diff, err := utils.GetStagedDiff(ctx, DiffExcludes(params.CommitCfg, repoCfg)...).UnwrapErr()
+		if err != nil {
+			return nil, err
+		}
+		if diff == nil || len(diff.Files) == 0 {
+			return nil, errors.New("no staged changes")
+		}
+		return diff, nil
+	}
+
+	diff, err := utils.ReadDiffStream(strings.NewReader(raw), utils.DiffLimits{})
+	if err != nil {
+		return nil, err
+	}
+	for _, stat := range diff.Stats {
+		if stat.Path != "" {
+			diff.Files = append(diff.Files, stat.Path)
+		}
+	}
+	diff.Tokens = utils.CountTokens(diff.Diff)
+	return diff, nil
+}
+
+// LintMessage 按仓库规则与 commit.style 校验提交信息
+func LintMessage(cfgs []*Config, repoRoot, message string) error {
+	repoCfg, _ := repoconfig.Load(repoRoot)
+	repoCfg = withMessageStyle(repoCfg, cmdParams{CommitCfg: cfgs})
+	return repoCfg.ValidateCommitMessage(message)
+}
@@ -0,0 +1,43 @@
+package fastcommitcmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pubgo/funk/v2/log"
+
+	"github.com/pubgo/fastgit/pkg/commitmsg"
+	"github.com/pubgo/fastgit/pkg/gitshell"
+)
+
+// gitMessageFile 是未配置 commit.template 时读取的仓库根目录模板文件
+const gitMessageFile = ".gitmessage"
+
+// loadGitTemplate 读取 git 的 commit.template（相对路径按仓库根目录解析），未配置时读取仓库根目录的 .gitmessage；
+// 都没有或内容为空时返回 nil
+func loadGitTemplate(ctx context.Context, repoRoot string) *commitmsg.GitTemplate {
+	if repoRoot == "" {
+		return nil
+	}
+	path := filepath.Join(repoRoot, gitMessageFile)
+	// --path 展开 ~/，未配置时 git config 以非零状态退出
+	out, err := gitshell.Output(ctx, repoRoot, "config", "--path", "commit.template")
+	configured := err == nil && strings.TrimSpace(out) != ""
+	if configured {
+		path = strings.TrimSpace(out)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(repoRoot, path)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if configured || !os.IsNotExist(err) {
+			log.Warn().Err(err).Str("path", path).Msg("failed to read commit template")
+		}
+		return nil
+	}
+	return commitmsg.ParseGitTemplate(string(data))
+}
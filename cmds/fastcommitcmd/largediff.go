@@ -0,0 +1,56 @@
+package fastcommitcmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pubgo/funk/v2/log"
+
+	"github.com/pubgo/fastgit/pkg/commitmsg"
+	"github.com/pubgo/fastgit/pkg/repoconfig"
+	"github.com/pubgo/fastgit/utils"
+)
+
+// diffTokenBudget 返回 commit.diff_token_budget，未配置时使用 utils.DefaultDiffTokenBudget
+func diffTokenBudget(cfgs []*Config) int {
+	budget := utils.DefaultDiffTokenBudget
+	for _, cfg := range cfgs {
+		if cfg != nil && cfg.DiffTokenBudget > 0 {
+			budget = cfg.DiffTokenBudget
+		}
+	}
+	return budget
+}
+
+// DiffExcludes 合并 config.yaml 与仓库 .fastgit/commit.yaml 中的 exclude，返回 GetStagedDiff 使用的 pathspec
+func DiffExcludes(cfgs []*Config, repoCfg repoconfig.Bundle) []string {
+	var patterns []string
+	for _, cfg := range cfgs {
+		if cfg != nil {
+			patterns = append(patterns, cfg.Exclude...)
+		}
+	}
+	return utils.ExcludePathspecs(append(patterns, repoCfg.Commit.Exclude...)...)
+}
+
+// aiDiffInput 返回交给模型的 diff：超出 token 预算时先按文件分块摘要，再用摘要做最终生成；
+// 摘要失败时截断到预算内，AI 不可用时原样交给规则 fallback
+func aiDiffInput(ctx context.Context, params cmdParams, diff *utils.GetStagedDiffRsp) string {
+	budget := diffTokenBudget(params.CommitCfg)
+	if diff.Tokens <= budget || params.AI == nil || !params.AI.Available() {
+		return diff.Diff
+	}
+
+	chunks := diff.Chunks(budget)
+	log.Warn().Int("tokens", diff.Tokens).Int("budget", budget).Int("chunks", len(chunks)).
+		Msg("staged diff exceeds the token budget, summarizing it in parts")
+
+	s := utils.NewSpinner(fmt.Sprintf("summarize diff (%d parts): ", len(chunks)))
+	s.Start()
+	input, _, err := commitmsg.Input(ctx, params.AI, diff, budget)
+	s.Stop()
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to summarize diff, sending a truncated diff")
+	}
+	return input
+}
@@ -0,0 +1,93 @@
+package fastcommitcmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pubgo/funk/v2/log"
+
+	"github.com/pubgo/fastgit/pkg/daemon"
+	"github.com/pubgo/fastgit/pkg/gitshell"
+	"github.com/pubgo/fastgit/pkg/repoconfig"
+)
+
+// lastMessagePath 是最近一次未能提交的生成结果：<git-dir>/fastgit/last-message，按 worktree 隔离
+func lastMessagePath(repoRoot string) (string, error) {
+	gitDir, err := gitshell.RunInDir(repoRoot, "rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(gitDir, "fastgit", "last-message"), nil
+}
+
+// saveLastMessage 在提交失败（hook 拒绝、策略或 commitlint 未通过）时保存提交信息，--last 可直接复用而不再调用模型
+func saveLastMessage(repoRoot, msg string) {
+	msg = strings.TrimSpace(msg)
+	if msg == "" {
+		return
+	}
+	path, err := lastMessagePath(repoRoot)
+	if err == nil {
+		if err = os.MkdirAll(filepath.Dir(path), 0o755); err == nil {
+			err = os.WriteFile(path, []byte(msg+"\n"), 0o644)
+		}
+	}
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to save the commit message for --last")
+		return
+	}
+	log.Info().Str("path", path).Msg("commit message saved, reuse it with `fastgit commit --last`")
+}
+
+// loadLastMessage 读取保存的提交信息，不存在时返回错误提示
+func loadLastMessage(repoRoot string) (string, error) {
+	path, err := lastMessagePath(repoRoot)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) || (err == nil && strings.TrimSpace(string(data)) == "") {
+		return "", errors.New("no saved commit message, --last reuses the message of a failed commit")
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// clearLastMessage 提交成功后删除保存的信息，避免下次 --last 误用旧内容
+func clearLastMessage(repoRoot string) {
+	if path, err := lastMessagePath(repoRoot); err == nil {
+		_ = os.Remove(path)
+	}
+}
+
+// clearCheckpoint 正常提交后丢弃 daemon --wip 在影子分支上的检查点，其内容已由本次提交取代
+func clearCheckpoint(repoRoot string) {
+	if err := daemon.ClearCheckpoint(repoRoot, currentBranch()); err != nil {
+		log.Warn().Err(err).Msg("failed to drop the wip checkpoint")
+	}
+}
+
+// commitLast 是 --last：复用上次失败时保存的提交信息，确认后走正常的提交与推送流程
+func commitLast(ctx context.Context, params cmdParams, repoCfg repoconfig.Bundle, repoRoot string, sign bool, flags *flagOptions) error {
+	msg, err := loadLastMessage(repoRoot)
+	if err != nil {
+		return err
+	}
+	if err := runPreCommitCheck(ctx, repoRoot, flags.skipCheck); err != nil {
+		return err
+	}
+	if msg = editMessage(ctx, msg); msg == "" {
+		log.Info().Msg("commit aborted")
+		return nil
+	}
+	if err := commitAndPush(ctx, params, repoCfg, repoRoot, msg, sign, flags); err != nil {
+		return err
+	}
+	log.Info().Str("message", msg).Msg("commit created from the saved message")
+	return nil
+}
@@ -0,0 +1,127 @@
+package fastcommitcmd
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/pubgo/funk/v2/errors"
+	"github.com/pubgo/funk/v2/log"
+
+	"github.com/pubgo/fastgit/pkg/aiprovider"
+	"github.com/pubgo/fastgit/pkg/commitlint"
+	"github.com/pubgo/fastgit/pkg/repoconfig"
+	"github.com/pubgo/fastgit/pkg/timing"
+)
+
+// defaultLintFixAttempts 是校验失败时请模型修正的缺省次数
+const defaultLintFixAttempts = 2
+
+// LintConfig 是 commit.lint：commitlint 规则加自动修正次数
+type LintConfig struct {
+	commitlint.Rules `yaml:",inline"`
+	// FixAttempts 有违规时请模型修正的次数，缺省为 defaultLintFixAttempts，0 关闭自动修正
+	FixAttempts *int `yaml:"fix_attempts"`
+	// TemplateTrailers 为 true 时把 git commit.template / .gitmessage 中的尾注行（如 `Signed-off-by:`）也作为必需行校验，
+	// 缺省只在 prompt 中要求保留
+	TemplateTrailers bool `yaml:"template_trailers"`
+}
+
+// lintRules 合并 config.yaml 的 commit.lint 与仓库 .commitlintrc，后者优先；开启 commit.lint.template_trailers 时
+// git commit.template / .gitmessage 中的尾注行追加为必需的尾注；返回空规则表示不校验
+func lintRules(cfgs []*Config, repoRoot string) (commitlint.Rules, int) {
+	var rules commitlint.Rules
+	attempts := defaultLintFixAttempts
+	templateTrailers := false
+	for _, cfg := range cfgs {
+		if cfg == nil {
+			continue
+		}
+		rules = rules.Merge(cfg.Lint.Rules)
+		if cfg.Lint.FixAttempts != nil {
+			attempts = *cfg.Lint.FixAttempts
+		}
+		templateTrailers = templateTrailers || cfg.Lint.TemplateTrailers
+	}
+	rc, path, err := commitlint.Load(repoRoot)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to read commitlint config, using commit.lint only")
+	}
+	if path != "" {
+		rules = rules.Merge(rc)
+	}
+	if tpl := loadGitTemplate(context.Background(), repoRoot); templateTrailers && tpl != nil {
+		rules.Trailers = slices.Clone(rules.Trailers)
+		for _, key := range tpl.Trailers() {
+			if !slices.ContainsFunc(rules.Trailers, func(k string) bool { return strings.EqualFold(k, key) }) {
+				rules.Trailers = append(rules.Trailers, key)
+			}
+		}
+	}
+	return rules, attempts
+}
+
+// lintMessage 按 commitlint 规则校验最终提交信息，有错误时请模型按违规项修正；
+// 返回（可能已修正的）信息，修正后仍不通过时返回错误，--skip-policy 时只告警
+func lintMessage(ctx context.Context, ai aiprovider.Provider, cfgs []*Config, repoCfg repoconfig.Bundle, repoRoot, msg string, skipPolicy bool) (string, error) {
+	rules, attempts := lintRules(cfgs, repoRoot)
+	if repoCfg.Commit.Style != repoconfig.StylePlain {
+		rules.Types = restrictTypes(rules.Types, allowedTypes(cfgs))
+	}
+	if rules.Empty() {
+		return msg, nil
+	}
+	lint := func(msg string) []commitlint.Violation {
+		// gitmoji 前缀不参与类型与大小写校验
+		if repoCfg.Commit.Style == repoconfig.StyleGitmoji {
+			msg = repoconfig.StripGitmoji(msg)
+		}
+		return rules.Lint(msg)
+	}
+
+	violations := lint(msg)
+	for i := 0; i < attempts && len(commitlint.Errors(violations)) > 0 && ai != nil && ai.Available(); i++ {
+		log.Warn().Msg("commit message violates commitlint rules, asking the model to fix it\n" + commitlint.Format(violations))
+		done := timing.Track(ctx, timing.PhaseLLM, "fix commit message")
+		resp, err := ai.Complete(ctx, aiprovider.CompleteRequest{
+			System: commitlint.FixPrompt,
+			User:   commitlint.FixRequest(msg, commitlint.Errors(violations)),
+		})
+		done()
+		if err != nil {
+			log.Warn().Err(err).Msg("failed to fix commit message")
+			break
+		}
+		if resp.Fallback {
+			break
+		}
+		msg = repoCfg.FormatMessage(strings.TrimSpace(resp.Text))
+		violations = lint(msg)
+	}
+
+	if len(violations) == 0 {
+		return msg, nil
+	}
+	if len(commitlint.Errors(violations)) == 0 || skipPolicy {
+		log.Warn().Msg("commitlint:\n" + commitlint.Format(violations))
+		return msg, nil
+	}
+	return msg, errors.Errorf("commit message violates commitlint rules:\n%s\n%s\nhint: fix the message, or use --skip-policy to bypass", msg, commitlint.Format(violations))
+}
+
+// restrictTypes 用 commit.allowed_types 收紧 commitlint 的 type-enum：两者都配置时取交集，交集为空时以 allowed_types 为准
+func restrictTypes(types, allowed []string) []string {
+	if len(allowed) == 0 {
+		return types
+	}
+	var both []string
+	for _, typ := range allowed {
+		if slices.Contains(types, typ) {
+			both = append(both, typ)
+		}
+	}
+	if len(both) == 0 {
+		return allowed
+	}
+	return both
+}
@@ -0,0 +1,127 @@
+package fastcommitcmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pubgo/funk/v2/errors"
+	"github.com/pubgo/funk/v2/log"
+
+	"github.com/pubgo/fastgit/pkg/aiprovider"
+	"github.com/pubgo/fastgit/pkg/commitmsg"
+	"github.com/pubgo/fastgit/pkg/commitplan"
+	"github.com/pubgo/fastgit/pkg/gitshell"
+	"github.com/pubgo/fastgit/pkg/repoconfig"
+	"github.com/pubgo/fastgit/utils"
+)
+
+// runPlan 根据任务描述生成提交拆分与提交信息草稿，保存后供后续 commit 引用
+func runPlan(ctx context.Context, params cmdParams, flags *flagOptions, task string) error {
+	if params.AI == nil || !params.AI.Available() {
+		return errors.New("commit --plan requires an AI provider, see `fastgit doctor`")
+	}
+
+	repoRoot := mustRepoRoot()
+	repoCfg, _ := repoconfig.Load(repoRoot)
+	repoCfg = withMessageStyle(repoCfg, params)
+	locale, maxLength := commitStyle(flags, repoCfg, params)
+
+	system := commitplan.SystemPrompt + fmt.Sprintf("\nMessage language: %s\nNo commit subject may exceed %d characters.", locale, maxLength)
+	system = commitmsg.AppendAllowedTypes(system, repoCfg.Commit.Types)
+
+	s := utils.NewSpinner("plan commits: ")
+	s.Start()
+	resp, err := params.AI.Complete(ctx, aiprovider.CompleteRequest{System: system, User: planInput(repoRoot, task)})
+	s.Stop()
+	if err != nil {
+		return errors.WrapCaller(err)
+	}
+
+	steps := commitplan.ParseSteps(resp.Text)
+	if resp.Fallback || len(steps) == 0 {
+		return errors.Errorf("provider %s returned no usable plan", resp.Provider)
+	}
+	for i := range steps {
+		steps[i].Message = repoCfg.FormatMessage(steps[i].Message)
+	}
+
+	plan := &commitplan.Plan{
+		Task:      task,
+		Branch:    gitshell.DetectBranch(repoRoot),
+		CreatedAt: time.Now(),
+		Steps:     steps,
+	}
+	path, err := commitplan.Save(repoRoot, plan)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Plan: %s\n\n", task)
+	for i, step := range plan.Steps {
+		fmt.Printf("  %d. %s\n", i+1, step.Message)
+		if step.Detail != "" {
+			fmt.Printf("     %s\n", step.Detail)
+		}
+	}
+	fmt.Printf("\nsaved to %s; `fastgit commit` on branch %s will follow it, `fastgit commit --plan-clear` drops it\n", path, plan.Branch)
+	return nil
+}
+
+// planInput 组装任务描述；工作区已有改动时附上 status 与改动统计，便于模型把已完成部分排在前面
+func planInput(repoRoot, task string) string {
+	input := "Task: " + task
+	status, err := gitshell.RunInDir(repoRoot, "status", "--short")
+	if err != nil || status == "" {
+		return input + "\n\nThe working tree is clean; nothing has been implemented yet."
+	}
+	stat, _ := gitshell.RunInDir(repoRoot, "diff", "HEAD", "--stat")
+	return input + "\n\nWork in progress (uncommitted):\n" + status + "\n\n" + stat
+}
+
+// activePlan 返回当前分支上仍有未完成步骤的计划；读取失败只告警
+func activePlan(repoRoot string) *commitplan.Plan {
+	plan, err := commitplan.Load(repoRoot)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to load commit plan")
+		return nil
+	}
+	if plan == nil || plan.Pending() == 0 || plan.Branch != gitshell.DetectBranch(repoRoot) {
+		return nil
+	}
+	return plan
+}
+
+// markPlanStep 提交成功后把匹配的计划步骤标记为完成，全部完成时删除计划
+func markPlanStep(repoRoot, msg string) {
+	plan := activePlan(repoRoot)
+	if plan == nil {
+		return
+	}
+	hash, err := gitshell.RunInDir(repoRoot, "rev-parse", "HEAD")
+	if err != nil || !plan.MarkDone(msg, hash) {
+		return
+	}
+
+	if plan.Pending() == 0 {
+		log.Info().Str("task", plan.Task).Msg("all planned commits are done, removing the plan")
+		if err := commitplan.Clear(repoRoot); err != nil {
+			log.Warn().Err(err).Msg("failed to remove commit plan")
+		}
+		return
+	}
+	if _, err := commitplan.Save(repoRoot, plan); err != nil {
+		log.Warn().Err(err).Msg("failed to update commit plan")
+		return
+	}
+	log.Info().Int("pending", plan.Pending()).Msg("planned commit done")
+}
+
+// withPlanContext 把当前计划附加到 prompt，让模型优先沿用计划中的提交信息
+func withPlanContext(repoRoot, prompt string) string {
+	if plan := activePlan(repoRoot); plan != nil {
+		return strings.TrimSpace(prompt) + "\n\n" + plan.Context()
+	}
+	return prompt
+}
@@ -0,0 +1,117 @@
+package fastcommitcmd
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/pubgo/fastgit/pkg/aiprovider"
+	"github.com/pubgo/fastgit/utils"
+)
+
+// pending 是在后台提前发起的生成请求；结果只在 done 关闭后读取
+type pending[T any] struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+	val    T
+	err    error
+}
+
+func goPending[T any](ctx context.Context, fn func(ctx context.Context) (T, error)) *pending[T] {
+	ctx, cancel := context.WithCancel(ctx)
+	p := &pending[T]{cancel: cancel, done: make(chan struct{})}
+	go func() {
+		defer close(p.done)
+		p.val, p.err = fn(ctx)
+	}()
+	return p
+}
+
+// wait 等待结果；ctx 取消（如 Ctrl+C）时一并取消后台请求
+func (p *pending[T]) wait(ctx context.Context) (T, error) {
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+		p.cancel()
+		<-p.done
+	}
+	return p.val, p.err
+}
+
+// stop 放弃请求，用户中止或提交前检查失败时调用；请求已结束时无副作用
+func (p *pending[T]) stop() {
+	if p != nil {
+		p.cancel()
+	}
+}
+
+// messagePrefetch 在提交前检查与文件确认期间流式生成提交信息，已收到的片段先缓存，进入流式界面时回放
+type messagePrefetch struct {
+	*pending[aiprovider.CompleteResponse]
+
+	mu       sync.Mutex
+	text     strings.Builder
+	onDelta  func(string)
+	notice   string
+	onNotice func(string)
+}
+
+func startMessagePrefetch(ctx context.Context, ai aiprovider.Provider, req aiprovider.CompleteRequest) *messagePrefetch {
+	m := &messagePrefetch{}
+	ctx = aiprovider.WithRetryNotice(ctx, m.emitNotice)
+	m.pending = goPending(ctx, func(ctx context.Context) (aiprovider.CompleteResponse, error) {
+		return aiprovider.Stream(ctx, ai, req, m.emit)
+	})
+	return m
+}
+
+func (m *messagePrefetch) emit(delta string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.text.WriteString(delta)
+	if m.onDelta != nil {
+		m.onDelta(delta)
+	}
+}
+
+// emitNotice 转发重试提示；进入流式界面前只记录最近一条，进入后回放
+func (m *messagePrefetch) emitNotice(msg string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.notice = msg
+	if m.onNotice != nil {
+		m.onNotice(msg)
+	}
+}
+
+// stream 是 runStream 的数据源：先回放已缓存的片段，再转发后续片段直到生成结束
+func (m *messagePrefetch) stream(ctx context.Context, onDelta func(string)) (aiprovider.CompleteResponse, error) {
+	m.mu.Lock()
+	if m.text.Len() > 0 {
+		onDelta(m.text.String())
+	}
+	m.onDelta = onDelta
+	m.onNotice = func(msg string) { aiprovider.NotifyRetry(ctx, msg) }
+	if m.notice != "" && m.text.Len() == 0 {
+		m.onNotice(m.notice)
+	}
+	m.mu.Unlock()
+	return m.wait(ctx)
+}
+
+func (m *messagePrefetch) stop() {
+	if m != nil {
+		m.pending.stop()
+	}
+}
+
+// prefetchEnabled 读取 commit.prefetch，缺省开启；超出 token 预算的 diff 需先分块摘要，不提前发起
+func prefetchEnabled(cfgs []*Config, diff *utils.GetStagedDiffRsp) bool {
+	enabled := true
+	for _, cfg := range cfgs {
+		if cfg != nil && cfg.Prefetch != nil {
+			enabled = *cfg.Prefetch
+		}
+	}
+	return enabled && diff.Tokens <= diffTokenBudget(cfgs)
+}
@@ -0,0 +1,121 @@
+package fastcommitcmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pubgo/fastgit/configs"
+	"github.com/pubgo/fastgit/pkg/commitmsg"
+	"github.com/pubgo/fastgit/pkg/gitshell"
+	"github.com/pubgo/fastgit/pkg/repoconfig"
+	"github.com/pubgo/fastgit/utils"
+)
+
+// promptTemplatePath 返回自定义 prompt 模板路径：仓库 .fastgit/commit.yaml 优先，其次全局 commit.prompt_template；
+// 仓库配置来自仓库本身，模板内容会发给模型，只允许指向仓库内的文件
+func promptTemplatePath(cfgs []*Config, repoCfg repoconfig.Bundle) (string, error) {
+	if p := strings.TrimSpace(repoCfg.Commit.PromptTemplate); p != "" {
+		return repoFile(configs.GetRepoPath(), p)
+	}
+	for _, cfg := range cfgs {
+		if cfg == nil {
+			continue
+		}
+		if p := strings.TrimSpace(cfg.PromptTemplate); p != "" {
+			if rest, ok := strings.CutPrefix(p, "~/"); ok {
+				if home, err := os.UserHomeDir(); err == nil {
+					p = filepath.Join(home, rest)
+				}
+			}
+			return p, nil
+		}
+	}
+	return "", nil
+}
+
+// repoFile 把仓库配置中的相对路径解析到仓库根目录下；绝对路径、../ 越界或符号链接指向仓库外时报错
+func repoFile(repoRoot, p string) (string, error) {
+	outside := fmt.Errorf("commit.prompt_template %q in .fastgit/commit.yaml must be a file inside the repository", p)
+	if filepath.IsAbs(p) {
+		return "", outside
+	}
+	root, err := filepath.Abs(repoRoot)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(root, p)
+	if !within(root, path) {
+		return "", outside
+	}
+	// 符号链接可能指向仓库外，按真实路径再检查一次；文件不存在时留给读取时报错
+	if real, err := filepath.EvalSymlinks(path); err == nil {
+		if realRoot, err := filepath.EvalSymlinks(root); err == nil && !within(realRoot, real) {
+			return "", outside
+		}
+	}
+	return path, nil
+}
+
+func within(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// buildPrompt 渲染自定义 prompt 模板；未配置时直接返回内置 prompt
+func buildPrompt(path string, base string, vars commitmsg.PromptVars, diff *utils.GetStagedDiffRsp) (string, error) {
+	if path == "" {
+		return base, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read commit.prompt_template: %w", err)
+	}
+
+	vars.Default = base
+	vars.Files = diff.Files
+	var stat strings.Builder
+	for _, s := range diff.Stats {
+		vars.Added += s.Added
+		vars.Removed += s.Removed
+		_, _ = fmt.Fprintf(&stat, "%s +%d -%d\n", s.Path, s.Added, s.Removed)
+	}
+	vars.Stat = strings.TrimSpace(stat.String())
+	return commitmsg.RenderPrompt(filepath.Base(path), string(data), vars)
+}
+
+// debugLLMDefault 是单写 --debug-llm 时的取值，表示写到默认位置
+const debugLLMDefault = "true"
+
+// debugLLMPath 是 --debug-llm 的取值：单写时写到 <git-dir>/fastgit/llm-debug.log，也可写 --debug-llm=path
+type debugLLMPath string
+
+func (d *debugLLMPath) String() string { return string(*d) }
+
+func (d *debugLLMPath) Set(v string) error {
+	*d = debugLLMPath(strings.TrimSpace(v))
+	return nil
+}
+
+func (d *debugLLMPath) Type() string { return "string" }
+
+func (d *debugLLMPath) NoOptDefValue() string { return debugLLMDefault }
+
+// resolve 返回调试记录文件的路径，未开启时为空；相对路径按当前目录解析
+func (d debugLLMPath) resolve(repoRoot string) string {
+	switch d {
+	case "", "false":
+		return ""
+	case debugLLMDefault:
+		gitDir, err := gitshell.RunInDir(repoRoot, "rev-parse", "--absolute-git-dir")
+		if err != nil {
+			return filepath.Join(repoRoot, ".git", "fastgit", "llm-debug.log")
+		}
+		return filepath.Join(gitDir, "fastgit", "llm-debug.log")
+	}
+	if abs, err := filepath.Abs(string(d)); err == nil {
+		return abs
+	}
+	return string(d)
+}
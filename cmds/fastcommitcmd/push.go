@@ -0,0 +1,61 @@
+package fastcommitcmd
+
+import (
+	"context"
+
+	"github.com/pubgo/funk/v2/log"
+
+	"github.com/pubgo/fastgit/utils"
+)
+
+// autoSetUpstream 默认开启，commit.auto_set_upstream: false 关闭
+func autoSetUpstream(cfgs []*Config) bool {
+	for _, cfg := range cfgs {
+		if cfg != nil && cfg.AutoSetUpstream != nil {
+			return *cfg.AutoSetUpstream
+		}
+	}
+	return true
+}
+
+// pushEnabled 提交后是否推送：--no-push 优先，其次 commit.auto_push，缺省为 true
+func pushEnabled(cfgs []*Config, flags *flagOptions) bool {
+	if flags != nil && flags.noPush {
+		return false
+	}
+	enabled := true
+	for _, cfg := range cfgs {
+		if cfg != nil && cfg.AutoPush != nil {
+			enabled = *cfg.AutoPush
+		}
+	}
+	return enabled
+}
+
+func logPushSkipped() {
+	log.Info().Str("branch", utils.GetBranchName()).Msg("committed locally, push skipped (--no-push or commit.auto_push: false); run `fastgit push` when ready")
+}
+
+// pushCurrentBranch 推送当前分支；分支尚无上游时按配置自动 --set-upstream，并提示远端返回的 PR 创建链接；
+// rewrote 表示改写了已推送的提交（amend 或合并了已推送的 --fast 提交），只有这时才加 --force-with-lease
+func pushCurrentBranch(ctx context.Context, params cmdParams, rewrote bool) (string, error) {
+	branch, err := utils.BranchName()
+	if err != nil {
+		return "", err
+	}
+	var args []string
+	if rewrote {
+		log.Info().Str("branch", branch).Msg("pushed commits were rewritten, pushing with --force-with-lease")
+		args = append(args, "--force-with-lease")
+	}
+	if autoSetUpstream(params.CommitCfg) && !utils.HasUpstream(ctx) {
+		log.Info().Str("branch", branch).Msg("branch has no upstream, pushing with --set-upstream origin")
+		args = append(args, "--set-upstream")
+	}
+	res, err := utils.GitPush(ctx, append(args, "origin", branch)...)
+	if err != nil {
+		return "", err
+	}
+	utils.OfferPullRequest(ctx, res)
+	return res, nil
+}
@@ -0,0 +1,112 @@
+package fastcommitcmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pubgo/funk/v2/errors"
+	"github.com/pubgo/funk/v2/log"
+	"github.com/yarlson/tap"
+
+	"github.com/pubgo/fastgit/pkg/aiprovider"
+	"github.com/pubgo/fastgit/pkg/timing"
+	"github.com/pubgo/fastgit/utils"
+)
+
+const (
+	refineAccept     = "accept"
+	refineRegenerate = "regenerate"
+	refineShorter    = "shorter"
+	refineBody       = "body"
+	refineType       = "type"
+	refineCustom     = "custom"
+	refineAbort      = "abort"
+)
+
+var refineInstructions = map[string]string{
+	refineRegenerate: "Write a different commit message for the same diff; do not reuse the previous wording.",
+	refineShorter:    "Make the subject line noticeably shorter while keeping the commit type and scope.",
+	refineBody:       "Keep the subject line and add a blank line followed by a short body (2-4 lines) explaining what changed and why.",
+}
+
+// refineLoop 展示 AI 生成的提交信息，可重新生成或按指令改写，确认后交给 edit 编辑；返回空字符串表示放弃提交
+func refineLoop(ctx context.Context, ai aiprovider.Provider, base aiprovider.CompleteRequest, initial string, types []string, decorate func(string) string, edit func(context.Context, string) string) string {
+	if utils.NonInteractive() {
+		return edit(ctx, initial)
+	}
+	current := initial
+	for {
+		tap.Message(current)
+		done := timing.Track(ctx, timing.PhaseUI, "refine commit message")
+		action := tap.Select[string](ctx, tap.SelectOptions[string]{
+			Message: "Use this message?",
+			Options: []tap.SelectOption[string]{
+				{Value: refineAccept, Label: "Use it", Hint: "edit before commit"},
+				{Value: refineRegenerate, Label: "Regenerate"},
+				{Value: refineShorter, Label: "Make shorter"},
+				{Value: refineBody, Label: "Add body"},
+				{Value: refineType, Label: "Change type"},
+				{Value: refineCustom, Label: "Custom instruction"},
+				{Value: refineAbort, Label: "Abort"},
+			},
+		})
+		done()
+
+		var instruction string
+		switch action {
+		case refineAccept:
+			return edit(ctx, current)
+		case refineAbort, "":
+			return ""
+		case refineType:
+			typ := pickCommitType(ctx, types)
+			if typ == "" {
+				continue
+			}
+			instruction = fmt.Sprintf("Change the conventional commit type to %q and adjust the wording to fit it.", typ)
+		case refineCustom:
+			done := timing.Track(ctx, timing.PhaseUI, "refine instruction")
+			instruction = strings.TrimSpace(tap.Text(ctx, tap.TextOptions{
+				Message:     "How should the message change?",
+				Placeholder: "e.g. mention the cache fix, write it in Chinese",
+			}))
+			done()
+			if instruction == "" {
+				continue
+			}
+		default:
+			instruction = refineInstructions[action]
+		}
+
+		resp, err := streamCommitMessage(ctx, ai, aiprovider.RefineRequest(base, current, instruction))
+		switch {
+		case errors.Is(err, context.Canceled):
+			if ctx.Err() != nil {
+				return ""
+			}
+			log.Info().Msg("refinement cancelled, keeping the previous message")
+		case err != nil:
+			log.Warn().Err(err).Msg("failed to refine commit message, keeping the previous one")
+		case resp.Fallback:
+			log.Warn().Str("provider", resp.Provider).Msg("AI unavailable, rule-based fallback cannot refine messages")
+		default:
+			current = decorate(resp.Text)
+		}
+	}
+}
+
+func pickCommitType(ctx context.Context, types []string) string {
+	options := make([]tap.SelectOption[string], 0, len(types))
+	for _, typ := range types {
+		options = append(options, tap.SelectOption[string]{Value: typ, Label: typ})
+	}
+	if len(options) == 0 {
+		return ""
+	}
+	defer timing.Track(ctx, timing.PhaseUI, "pick commit type")()
+	return tap.Select[string](ctx, tap.SelectOptions[string]{
+		Message: "New commit type:",
+		Options: options,
+	})
+}
@@ -0,0 +1,26 @@
+package fastcommitcmd
+
+import (
+	"github.com/pubgo/fastgit/pkg/repocontext"
+)
+
+// repoContextPrompt 读取 commit.repo_context / commit.repo_context_commits，未开启或没有可用信息时返回空字符串
+func repoContextPrompt(cfgs []*Config, repoRoot string) string {
+	enabled := false
+	subjects := repocontext.DefaultSubjects
+	for _, cfg := range cfgs {
+		if cfg == nil {
+			continue
+		}
+		if cfg.RepoContext {
+			enabled = true
+		}
+		if cfg.RepoContextCommits > 0 {
+			subjects = cfg.RepoContextCommits
+		}
+	}
+	if !enabled || repoRoot == "" {
+		return ""
+	}
+	return repocontext.Load(repoRoot, subjects).Prompt()
+}
@@ -0,0 +1,99 @@
+package fastcommitcmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pubgo/funk/v2/log"
+
+	"github.com/pubgo/fastgit/pkg/gitshell"
+	"github.com/pubgo/fastgit/pkg/secretscan"
+	"github.com/pubgo/fastgit/utils"
+)
+
+// SecretsConfig 是 commit.secrets：内置规则（云厂商/GitHub/AI token、私钥、高熵字符串）之外的自定义规则与白名单
+type SecretsConfig struct {
+	// Enabled 缺省为 true
+	Enabled *bool `yaml:"enabled"`
+	// Redact 以 --allow-secrets 继续时把命中内容替换为 [REDACTED:<rule>] 再发给 AI，缺省为 true
+	Redact *bool `yaml:"redact"`
+	// Entropy 高熵字符串阈值（bit/字符），缺省 secretscan.DefaultEntropy，负数关闭
+	Entropy float64 `yaml:"entropy"`
+	// Rules 追加的正则规则，有捕获组时第一组为密钥
+	Rules []secretscan.Rule `yaml:"rules"`
+	// Allow 匹配即忽略的密钥正则，如测试用的假 token
+	Allow []string `yaml:"allow"`
+	// AllowPaths 不扫描的文件通配符或目录，如 testdata/
+	AllowPaths []string `yaml:"allow_paths"`
+}
+
+// secretScanner 合并各层 commit.secrets；关闭时返回 nil
+func secretScanner(cfgs []*Config) (*secretscan.Scanner, bool, error) {
+	enabled, redact := true, true
+	var opts secretscan.Options
+	for _, cfg := range cfgs {
+		if cfg == nil {
+			continue
+		}
+		sc := cfg.Secrets
+		if sc.Enabled != nil {
+			enabled = *sc.Enabled
+		}
+		if sc.Redact != nil {
+			redact = *sc.Redact
+		}
+		if sc.Entropy != 0 {
+			opts.Entropy = sc.Entropy
+		}
+		opts.Rules = append(opts.Rules, sc.Rules...)
+		opts.Allow = append(opts.Allow, sc.Allow...)
+		opts.AllowPaths = append(opts.AllowPaths, sc.AllowPaths...)
+	}
+	if !enabled {
+		return nil, false, nil
+	}
+	scanner, err := secretscan.New(opts)
+	return scanner, redact, err
+}
+
+// checkSecrets 扫描完整的暂存 diff（不受 commit.exclude 与截断影响），命中时输出报告并阻止提交；
+// --allow-secrets 时只警告，并按 commit.secrets.redact 从将发给 AI 的 diff 中抹去命中内容
+func checkSecrets(cfgs []*Config, repoRoot string, flags *flagOptions, diff *utils.GetStagedDiffRsp) error {
+	scanner, redact, err := secretScanner(cfgs)
+	if err != nil || scanner == nil {
+		return err
+	}
+
+	staged, err := gitshell.RunInDir(repoRoot, "diff", "--cached", "--no-color", "--no-ext-diff", "-U0")
+	if err != nil {
+		return fmt.Errorf("read staged diff for secret scan: %w", err)
+	}
+	findings := scanner.ScanDiff(staged)
+	if len(findings) == 0 {
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "possible secrets in staged changes:\n")
+	for _, f := range findings {
+		fmt.Fprintf(os.Stderr, "  %s\n", f)
+	}
+	if !flags.allowSecrets {
+		return fmt.Errorf("%d possible secrets staged; unstage them, allow them in commit.secrets.allow / allow_paths, or pass --allow-secrets", len(findings))
+	}
+
+	log.Warn().Int("findings", len(findings)).Msg("committing possible secrets because of --allow-secrets")
+	if redact && diff != nil {
+		diff.Diff = scanner.Redact(diff.Diff)
+	}
+	return nil
+}
+
+// redactSecrets 只抹去发给 AI 的 diff 中的密钥，不阻止生成；用于 serve --editor 等不提交的调用方
+func redactSecrets(cfgs []*Config, diff *utils.GetStagedDiffRsp) error {
+	scanner, redact, err := secretScanner(cfgs)
+	if err != nil || scanner == nil || !redact {
+		return err
+	}
+	diff.Diff = scanner.Redact(diff.Diff)
+	return nil
+}
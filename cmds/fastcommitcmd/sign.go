@@ -0,0 +1,30 @@
+package fastcommitcmd
+
+import (
+	"github.com/pubgo/funk/v2/errors"
+	"github.com/pubgo/funk/v2/log"
+
+	"github.com/pubgo/fastgit/utils"
+)
+
+// signCommit 决定是否加 -S：--sign 或 commit.sign；git 的 commit.gpgsign 已开启时同样会签名，提前检查密钥
+func signCommit(params cmdParams, flags *flagOptions) (bool, error) {
+	sign := flags.sign
+	for _, cfg := range params.CommitCfg {
+		if cfg != nil && cfg.Sign {
+			sign = true
+		}
+	}
+
+	signing := utils.LoadSigningConfig()
+	if !sign && !signing.CommitSign {
+		return false, nil
+	}
+	if !sign {
+		log.Debug().Str("format", signing.Format).Msg("commit.gpgsign is enabled, git signs the commit")
+	}
+	if err := signing.Check(); err != nil {
+		return false, errors.Wrap(err, "commit signing is not ready")
+	}
+	return sign, nil
+}
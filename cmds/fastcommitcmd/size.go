@@ -0,0 +1,76 @@
+package fastcommitcmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pubgo/funk/v2/log"
+	"github.com/yarlson/tap"
+
+	"github.com/pubgo/fastgit/pkg/timing"
+	"github.com/pubgo/fastgit/utils"
+)
+
+// 提交规模的缺省上限，超出任一项即提示拆分
+const (
+	defaultSizeBudgetFiles = 25
+	defaultSizeBudgetLines = 400
+)
+
+// SizeBudget 是 commit.size_budget：单个提交的文件数与增删行数上限；0 取缺省值，负数关闭该项
+type SizeBudget struct {
+	Files int `yaml:"files"`
+	Lines int `yaml:"lines"`
+}
+
+func sizeBudget(cfgs []*Config) SizeBudget {
+	budget := SizeBudget{Files: defaultSizeBudgetFiles, Lines: defaultSizeBudgetLines}
+	for _, cfg := range cfgs {
+		if cfg == nil {
+			continue
+		}
+		if cfg.SizeBudget.Files != 0 {
+			budget.Files = cfg.SizeBudget.Files
+		}
+		if cfg.SizeBudget.Lines != 0 {
+			budget.Lines = cfg.SizeBudget.Lines
+		}
+	}
+	return budget
+}
+
+// overBudget 返回超出的项，如 "42 files > 25"；被 commit.exclude 排除的文件（lockfile、生成代码）不计入
+func (b SizeBudget) overBudget(diff *utils.GetStagedDiffRsp) []string {
+	files := len(diff.Files) - len(diff.Excluded)
+	lines := 0
+	for _, stat := range diff.Stats {
+		lines += stat.Added + stat.Removed
+	}
+
+	var over []string
+	if b.Files > 0 && files > b.Files {
+		over = append(over, fmt.Sprintf("%d files > %d", files, b.Files))
+	}
+	if b.Lines > 0 && lines > b.Lines {
+		over = append(over, fmt.Sprintf("%d changed lines > %d", lines, b.Lines))
+	}
+	return over
+}
+
+// offerSplit 暂存改动超出 commit.size_budget 时提示拆分，返回用户是否改走 --split 流程
+func offerSplit(ctx context.Context, params cmdParams, diff *utils.GetStagedDiffRsp) bool {
+	over := sizeBudget(params.CommitCfg).overBudget(diff)
+	if len(over) == 0 {
+		return false
+	}
+	log.Warn().Strs("over", over).Msg("staged change is large for a single commit, smaller commits are easier to review")
+	if len(diff.Files) < 2 || params.AI == nil || !params.AI.Available() || utils.NonInteractive() {
+		return false
+	}
+
+	defer timing.Track(ctx, timing.PhaseUI, "confirm split")()
+	return tap.Confirm(ctx, tap.ConfirmOptions{
+		Message:      "Split it into several commits (--split)?",
+		InitialValue: true,
+	})
+}
@@ -0,0 +1,127 @@
+package fastcommitcmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pubgo/funk/v2/errors"
+	"github.com/pubgo/funk/v2/log"
+	"github.com/yarlson/tap"
+
+	"github.com/pubgo/fastgit/pkg/aiprovider"
+	"github.com/pubgo/fastgit/pkg/commitmsg"
+	"github.com/pubgo/fastgit/pkg/commitsplit"
+	"github.com/pubgo/fastgit/pkg/repoconfig"
+	"github.com/pubgo/fastgit/pkg/ticket"
+	"github.com/pubgo/fastgit/pkg/timing"
+	"github.com/pubgo/fastgit/pkg/workflow"
+	"github.com/pubgo/fastgit/utils"
+)
+
+// runSplit 让 AI 把暂存改动按文件分成若干逻辑提交，确认后逐组 reset + add + commit，最后统一推送
+func runSplit(ctx context.Context, params cmdParams, flags *flagOptions, repoCfg repoconfig.Bundle, repoRoot string, diff *utils.GetStagedDiffRsp, tk *ticket.Ticket, sign bool) error {
+	if params.AI == nil || !params.AI.Available() {
+		return errors.New("commit --split requires an AI provider, see `fastgit doctor`")
+	}
+
+	files, err := commitsplit.StagedFiles(repoRoot)
+	if err != nil {
+		return err
+	}
+	partial, err := commitsplit.PartiallyStaged(repoRoot, files)
+	if err != nil {
+		return err
+	}
+	if len(partial) > 0 {
+		return errors.Errorf("commit --split restages files per commit, but these also have unstaged changes: %s\nhint: stage or stash them first (git stash --keep-index)", strings.Join(partial, ", "))
+	}
+
+	locale, maxLength := commitStyle(flags, repoCfg, params)
+	system := commitsplit.SystemPrompt + fmt.Sprintf("\nMessage language: %s\nNo commit subject may exceed %d characters.", locale, maxLength)
+	system = commitmsg.AppendRequiredTypes(commitmsg.AppendAllowedTypes(system, repoCfg.Commit.Types), allowedTypes(params.CommitCfg))
+	system = withPlanContext(repoRoot, system)
+	input := commitsplit.Input(files, aiDiffInput(ctx, params, diff))
+
+	s := utils.NewSpinner("split commits: ")
+	s.Start()
+	resp, err := params.AI.Complete(ctx, aiprovider.CompleteRequest{System: system, User: input})
+	s.Stop()
+	if err != nil {
+		return errors.WrapCaller(err)
+	}
+	groups := commitsplit.ParseGroups(resp.Text, files)
+	if resp.Fallback || len(groups) == 0 {
+		return errors.Errorf("provider %s returned no usable split", resp.Provider)
+	}
+	for i := range groups {
+		msg := withIssueRef(ticket.WithRef(repoCfg.FormatMessage(groups[i].Message), tk), params)
+		// 修正结果随分组摘要一起确认
+		if groups[i].Message, err = lintMessage(ctx, params.AI, params.CommitCfg, repoCfg, repoRoot, msg, flags.skipPolicy); err != nil {
+			return err
+		}
+	}
+
+	fmt.Println(commitsplit.Summary(groups))
+	if !confirmSplit(ctx, len(groups)) {
+		log.Info().Msg("commit split aborted")
+		return nil
+	}
+
+	// 提交前检查覆盖全部暂存文件，只运行一次
+	if err := runPreCommitCheck(ctx, repoRoot, flags.skipCheck); err != nil {
+		return err
+	}
+	for _, g := range groups {
+		if err := enforceRepoPolicy(repoCfg, currentBranch(), g.Message, flags.skipPolicy); err != nil {
+			return err
+		}
+	}
+
+	err = commitsplit.Apply(repoRoot, groups, func(g commitsplit.Group) error {
+		warnRepoPolicy(repoCfg, currentBranch(), g.Message)
+		msg := g.Message
+		if diffstatEnabled(params.CommitCfg, flags) {
+			msg = withDiffstat(msg, false)
+		}
+		done := timing.Track(ctx, timing.PhaseGit, "git commit")
+		err := utils.Commit(msg, sign)
+		done()
+		if err != nil {
+			return err
+		}
+		markPlanStep(repoRoot, g.Message)
+		log.Info().Str("message", g.Message).Int("files", len(g.Files)).Msg("split commit created")
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "split commit failed, remaining files are staged again")
+	}
+	clearCheckpoint(repoRoot)
+
+	if !pushEnabled(params.CommitCfg, flags) {
+		logPushSkipped()
+		workflow.PrintRecommendations(os.Stdout, "commit")
+		return nil
+	}
+	if err := ensurePushPolicy(repoRoot, utils.GetBranchName(), flags.overridePolicy); err != nil {
+		return err
+	}
+	if _, err := pushCurrentBranch(ctx, params, flags.squashedPushed); err != nil {
+		return err
+	}
+	workflow.PrintRecommendations(os.Stdout, "commit")
+	return nil
+}
+
+func confirmSplit(ctx context.Context, n int) bool {
+	if utils.NonInteractive() {
+		return true
+	}
+	defer timing.Track(ctx, timing.PhaseUI, "confirm commit split")()
+	return tap.Confirm(ctx, tap.ConfirmOptions{
+		Message:      fmt.Sprintf("Create these %d commit(s)?", n),
+		InitialValue: true,
+	})
+}
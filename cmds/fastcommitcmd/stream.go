@@ -0,0 +1,138 @@
+package fastcommitcmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/pubgo/fastgit/pkg/aiprovider"
+	"github.com/pubgo/fastgit/utils"
+)
+
+const streamMaxHeight = 12
+
+type streamDeltaMsg string
+
+// streamNoticeMsg 是重试提示，如 "rate limited, retrying in 4s"，显示在 spinner 旁
+type streamNoticeMsg string
+
+type streamDoneMsg struct {
+	resp aiprovider.CompleteResponse
+	err  error
+}
+
+var (
+	streamHintStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+	streamNoticeStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+)
+
+type streamModel struct {
+	cancel   context.CancelFunc
+	spinner  spinner.Model
+	viewport viewport.Model
+	text     strings.Builder
+	notice   string
+	resp     aiprovider.CompleteResponse
+	err      error
+	finished bool
+}
+
+func (m *streamModel) Init() tea.Cmd { return m.spinner.Tick }
+
+func (m *streamModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.viewport.Width = msg.Width
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" || msg.String() == "esc" {
+			m.cancel()
+			m.err = context.Canceled
+			m.finished = true
+			return m, tea.Quit
+		}
+	case streamNoticeMsg:
+		m.notice = string(msg)
+	case streamDeltaMsg:
+		m.notice = ""
+		m.text.WriteString(string(msg))
+		content := strings.TrimSpace(m.text.String())
+		m.viewport.Height = min(strings.Count(content, "\n")+1, streamMaxHeight)
+		m.viewport.SetContent(content)
+		m.viewport.GotoBottom()
+	case streamDoneMsg:
+		m.resp, m.err = msg.resp, msg.err
+		m.finished = true
+		return m, tea.Quit
+	default:
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m *streamModel) View() string {
+	// 结束后清空，交给后续的编辑确认步骤展示最终信息
+	if m.finished {
+		return ""
+	}
+	header := m.spinner.View() + " generate git message " + streamHintStyle.Render("(ctrl+c to cancel)")
+	if m.notice != "" {
+		header += " " + streamNoticeStyle.Render(m.notice)
+	}
+	if m.text.Len() == 0 {
+		return header + "\n"
+	}
+	return header + "\n" + m.viewport.View() + "\n"
+}
+
+// streamCommitMessage 流式生成提交信息并实时渲染；Ctrl+C 通过 context 取消请求
+func streamCommitMessage(ctx context.Context, ai aiprovider.Provider, req aiprovider.CompleteRequest) (aiprovider.CompleteResponse, error) {
+	return runStream(ctx, func(ctx context.Context, onDelta func(string)) (aiprovider.CompleteResponse, error) {
+		return aiprovider.Stream(ctx, ai, req, onDelta)
+	})
+}
+
+// runStream 渲染 source 产生的片段，source 可以是新请求，也可以是已提前发起的请求
+func runStream(ctx context.Context, source func(ctx context.Context, onDelta func(string)) (aiprovider.CompleteResponse, error)) (aiprovider.CompleteResponse, error) {
+	// 非交互模式没有终端可渲染，只等待结果
+	if utils.NonInteractive() {
+		return source(ctx, func(string) {})
+	}
+	// --no-spinner / 非终端输出时不启动 TUI，片段原样写到 stdout，重试提示写到 stderr
+	if utils.PlainOutput() {
+		ctx = aiprovider.WithRetryNotice(ctx, func(notice string) { fmt.Fprintln(os.Stderr, notice) })
+		resp, err := source(ctx, func(delta string) { fmt.Print(delta) })
+		fmt.Println()
+		return resp, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	m := &streamModel{cancel: cancel, spinner: s, viewport: viewport.New(80, 1)}
+	prog := tea.NewProgram(m, tea.WithContext(ctx))
+
+	ctx = aiprovider.WithRetryNotice(ctx, func(notice string) {
+		prog.Send(streamNoticeMsg(notice))
+	})
+	go func() {
+		resp, err := source(ctx, func(delta string) {
+			prog.Send(streamDeltaMsg(delta))
+		})
+		prog.Send(streamDoneMsg{resp: resp, err: err})
+	}()
+
+	if _, err := prog.Run(); err != nil && m.err == nil {
+		return aiprovider.CompleteResponse{}, err
+	}
+	return m.resp, m.err
+}
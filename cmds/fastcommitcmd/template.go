@@ -0,0 +1,48 @@
+package fastcommitcmd
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pubgo/funk/v2/errors"
+
+	"github.com/pubgo/fastgit/pkg/msgtemplate"
+	"github.com/pubgo/fastgit/utils"
+)
+
+// Templates 汇总所有 commit 配置中的消息模板，同名模板以先出现的为准
+func Templates(cfgs []*Config) []msgtemplate.Template {
+	var list []msgtemplate.Template
+	seen := make(map[string]struct{})
+	for _, cfg := range cfgs {
+		if cfg == nil {
+			continue
+		}
+		for _, tpl := range cfg.Templates {
+			if _, ok := seen[tpl.Name]; ok || strings.TrimSpace(tpl.Name) == "" {
+				continue
+			}
+			seen[tpl.Name] = struct{}{}
+			list = append(list, tpl)
+		}
+	}
+	return list
+}
+
+// RenderTemplate 使用当前分支、用户和仓库信息渲染指定模板
+func RenderTemplate(ctx context.Context, cfgs []*Config, name string) (string, error) {
+	tpl, ok := msgtemplate.Find(Templates(cfgs), name)
+	if !ok {
+		return "", errors.Errorf("commit template %q not found, see `fastgit template list`", name)
+	}
+
+	user := strings.TrimSpace(utils.ShellExecOutput(ctx, "git", "config", "user.name").UnwrapOr(""))
+	repo, _ := utils.GetRepositoryName()
+	return tpl.Render(msgtemplate.NewVars(utils.GetBranchName(), user, repo, time.Now()))
+}
+
+// RunTemplateCommit 以模板消息代替 AI 走完整的 commit 流程
+func RunTemplateCommit(ctx context.Context, name string) error {
+	return runAICommit(ctx, &flagOptions{template: name})
+}
@@ -0,0 +1,71 @@
+package fastcommitcmd
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/pubgo/funk/v2/errors"
+
+	"github.com/pubgo/fastgit/pkg/commitmsg"
+	"github.com/pubgo/fastgit/pkg/repoconfig"
+)
+
+// allowedTypes 返回 commit.allowed_types（小写、去空），后加载的非空配置优先；空表示不限制
+func allowedTypes(cfgs []*Config) []string {
+	var types []string
+	for _, cfg := range cfgs {
+		if cfg == nil || len(cfg.AllowedTypes) == 0 {
+			continue
+		}
+		types = types[:0:0]
+		for _, typ := range cfg.AllowedTypes {
+			if typ = strings.ToLower(strings.TrimSpace(typ)); typ != "" && !slices.Contains(types, typ) {
+				types = append(types, typ)
+			}
+		}
+	}
+	return types
+}
+
+// checkCommitType 在生成前校验 --type 是否在 commit.allowed_types 中，避免生成完才被拒绝
+func checkCommitType(flags *flagOptions, cfgs []*Config) error {
+	if flags == nil || strings.TrimSpace(flags.commitType) == "" {
+		return nil
+	}
+	typ := strings.ToLower(strings.TrimSpace(flags.commitType))
+	if strings.ContainsAny(typ, " :()!") {
+		return errors.Errorf("invalid commit type %q", flags.commitType)
+	}
+	if allowed := allowedTypes(cfgs); len(allowed) > 0 && !slices.Contains(allowed, typ) {
+		return errors.Errorf("commit type %q is not allowed, commit.allowed_types: %s", typ, strings.Join(allowed, ", "))
+	}
+	return nil
+}
+
+// typePrompt 把 commit.allowed_types 与 --type 的约束附加到 prompt；plain 风格不写类型，不附加
+func typePrompt(prompt string, flags *flagOptions, repoCfg repoconfig.Bundle, params cmdParams) string {
+	if repoCfg.Commit.Style == repoconfig.StylePlain {
+		return prompt
+	}
+	prompt = commitmsg.AppendRequiredTypes(prompt, allowedTypes(params.CommitCfg))
+	if flags != nil {
+		prompt = commitmsg.AppendType(prompt, flags.commitType)
+	}
+	return prompt
+}
+
+// withType 按 --type 改写生成信息的类型，模型未遵守约束时兜底
+func withType(msg string, flags *flagOptions, repoCfg repoconfig.Bundle) string {
+	if flags == nil || repoCfg.Commit.Style == repoconfig.StylePlain {
+		return msg
+	}
+	return repoconfig.WithType(msg, flags.commitType)
+}
+
+// refineTypes 返回 refine 菜单 "Change type" 可选的类型：commit.allowed_types 优先，其次仓库 commit.types
+func refineTypes(cfgs []*Config, repoCfg repoconfig.Bundle) []string {
+	if allowed := allowedTypes(cfgs); len(allowed) > 0 {
+		return allowed
+	}
+	return repoCfg.Commit.Types
+}
@@ -0,0 +1,63 @@
+package fastcommitcmd
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pubgo/funk/v2/log"
+
+	"github.com/pubgo/fastgit/pkg/aiprovider"
+	"github.com/pubgo/fastgit/pkg/commitmsg"
+	"github.com/pubgo/fastgit/pkg/repoconfig"
+)
+
+// messageFormat 返回校验模型输出所用的格式：团队 prompt 模板或 git commit.template 决定格式时不校验类型；
+// --body 与带正文的 commit.template 允许多行
+func messageFormat(ctx context.Context, flags *flagOptions, repoCfg repoconfig.Bundle, params cmdParams, repoRoot string) (commitmsg.CommitType, bool) {
+	tpl := loadGitTemplate(ctx, repoRoot)
+	body := (flags != nil && flags.body) || tpl.HasBody()
+	if path, _ := promptTemplatePath(params.CommitCfg, repoCfg); tpl != nil || path != "" {
+		return commitmsg.EmptyCommitType, body
+	}
+	switch repoCfg.Commit.Style {
+	case repoconfig.StylePlain:
+		return commitmsg.EmptyCommitType, body
+	case repoconfig.StyleGitmoji:
+		return commitmsg.GitmojiCommitType, body
+	default:
+		return commitmsg.ConventionalCommitType, body
+	}
+}
+
+// validateResponse 在展示前校验模型输出是否为单条提交信息：先自动去掉代码块、前后说明文字、标签与引号；
+// 仍不合格时带纠正指令重新请求一次，重试后仍不合格则警告并使用修复后的文本
+func validateResponse(ctx context.Context, ai aiprovider.Provider, req aiprovider.CompleteRequest, text string, typ commitmsg.CommitType, body bool) string {
+	msg := commitmsg.Repair(text, typ, body)
+	err := commitmsg.Validate(msg, typ, body)
+	if err == nil {
+		if msg != strings.TrimSpace(text) {
+			log.Info().Msg("stripped extra text around the generated commit message")
+		}
+		return msg
+	}
+
+	log.Warn().Err(err).Msg("generated commit message is malformed, asking the model to correct it")
+	resp, retryErr := streamCommitMessage(ctx, ai, aiprovider.RefineRequest(req, text, commitmsg.CorrectionInstruction(err, typ, body)))
+	if retryErr != nil || resp.Fallback {
+		log.Warn().Err(retryErr).Msg("failed to correct the commit message, keeping the repaired one")
+		return orText(msg, text)
+	}
+	retried := commitmsg.Repair(resp.Text, typ, body)
+	if err := commitmsg.Validate(retried, typ, body); err != nil {
+		log.Warn().Err(err).Msg("corrected commit message is still malformed, review it before committing")
+		return orText(retried, orText(msg, text))
+	}
+	return retried
+}
+
+func orText(msg, fallback string) string {
+	if msg == "" {
+		return fallback
+	}
+	return msg
+}
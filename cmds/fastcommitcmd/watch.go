@@ -0,0 +1,205 @@
+package fastcommitcmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pubgo/dix/v2"
+	"github.com/pubgo/dix/v2/dixcontext"
+	"github.com/pubgo/funk/v2/log"
+
+	"github.com/pubgo/fastgit/configs"
+	"github.com/pubgo/fastgit/pkg/aiprovider"
+	"github.com/pubgo/fastgit/pkg/commitmsg"
+	"github.com/pubgo/fastgit/pkg/fswatch"
+	"github.com/pubgo/fastgit/pkg/gitdiff"
+	"github.com/pubgo/fastgit/pkg/gitshell"
+	"github.com/pubgo/fastgit/pkg/repoconfig"
+	"github.com/pubgo/fastgit/utils"
+)
+
+// defaultWatchIdle 是 --watch 的缺省静默时长
+const defaultWatchIdle = 5 * time.Minute
+
+// runWatch 监控工作区，改动静默 idle 后输出建议的提交信息并发送桌面通知；同一份改动只提示一次，Ctrl-C 退出
+func runWatch(ctx context.Context, flags *flagOptions) error {
+	repoRoot, err := configs.RepoPath()
+	if err != nil {
+		return err
+	}
+	di := dixcontext.Get(ctx)
+	var params cmdParams
+	params = dix.Inject(di, params)
+	if name := strings.TrimSpace(flags.provider); name != "" {
+		params.AI = aiprovider.ResolveProvider(name, repoRoot)
+	}
+
+	idle := flags.watchIdle
+	if idle <= 0 {
+		idle = defaultWatchIdle
+	}
+
+	ignored := ignoredDirs(ctx, repoRoot)
+	walked := false
+	watcher, err := fswatch.New(func(path string) bool {
+		if filepath.Base(path) == ".git" || ignored[path] {
+			return true
+		}
+		// 启动后新建的目录不在 ignoredDirs 的结果中，逐个交给 git 判断
+		rel, err := filepath.Rel(repoRoot, path)
+		return walked && err == nil && gitIgnored(ctx, repoRoot, rel)
+	})
+	if err != nil {
+		return fmt.Errorf("create file watcher: %w", err)
+	}
+	defer func() { _ = watcher.Close() }()
+
+	dirs := watcher.AddTree(repoRoot)
+	walked = true
+	log.Info().Int("dirs", dirs).Str("idle", idle.String()).Msg("watching the worktree, a commit message is suggested once changes settle; Ctrl-C to stop")
+
+	timer := time.NewTimer(idle)
+	defer timer.Stop()
+	var suggested string
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			rel, err := filepath.Rel(repoRoot, event.Name)
+			if err != nil || isGitPath(rel) {
+				continue
+			}
+			watcher.Track(event)
+			timer.Reset(idle)
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.Warn().Err(err).Msg("file watcher error")
+
+		case <-timer.C:
+			diff, err := worktreeDiff(ctx, repoRoot, params)
+			if err != nil {
+				log.Warn().Err(err).Msg("failed to read worktree changes")
+				continue
+			}
+			if diff == nil {
+				suggested = ""
+				continue
+			}
+			fingerprint := fmt.Sprintf("%x", sha256.Sum256([]byte(diff.Diff)))
+			if fingerprint == suggested {
+				continue
+			}
+			suggested = fingerprint
+			suggestCommit(ctx, repoRoot, params, flags, diff, idle)
+		}
+	}
+}
+
+// suggestCommit 生成提交信息并提示；AI 不可用或失败时使用启发式信息
+func suggestCommit(ctx context.Context, repoRoot string, params cmdParams, flags *flagOptions, diff *gitdiff.Diff, idle time.Duration) {
+	repoCfg, _ := repoconfig.Load(repoRoot)
+	repoCfg = withMessageStyle(repoCfg, params)
+	locale, maxLength := commitStyle(flags, repoCfg, params)
+
+	msg := aiprovider.HeuristicCommitMessage(diff.Diff)
+	if params.AI != nil && params.AI.Available() {
+		res, err := commitmsg.Generate(ctx, params.AI, diff, commitmsg.Options{
+			Locale:      locale,
+			MaxLength:   maxLength,
+			Prompt:      typePrompt(stylePrompt(repoCfg, locale, maxLength, ""), flags, repoCfg, params),
+			TokenBudget: diffTokenBudget(params.CommitCfg),
+		})
+		switch {
+		case err != nil:
+			log.Warn().Err(err).Msg("AI generation failed, using a heuristic message")
+		case strings.TrimSpace(res.Message) != "":
+			msg = strings.TrimSpace(res.Message)
+		}
+	}
+
+	fmt.Printf("\n[%s] %d files changed and idle for %s, suggested commit:\n\n    %s\n\nrun `fastgit commit` to review and commit\n",
+		time.Now().Format(time.TimeOnly), len(diff.Files), idle, strings.ReplaceAll(msg, "\n", "\n    "))
+	subject, _, _ := strings.Cut(msg, "\n")
+	if err := utils.DesktopNotify(ctx, fmt.Sprintf("fastgit: %d files ready to commit", len(diff.Files)), subject); err != nil {
+		log.Debug().Err(err).Msg("desktop notification skipped")
+	}
+}
+
+// worktreeDiff 返回工作区相对 HEAD 的改动（含未跟踪文件），不改动暂存区；没有改动时返回 nil
+func worktreeDiff(ctx context.Context, repoRoot string, params cmdParams) (*gitdiff.Diff, error) {
+	repoCfg, _ := repoconfig.Load(repoRoot)
+	excludes := DiffExcludes(params.CommitCfg, repoCfg)
+
+	base := "HEAD"
+	if _, err := gitshell.Output(ctx, repoRoot, "rev-parse", "--verify", "--quiet", "HEAD"); err != nil {
+		base = gitdiff.EmptyTree
+	}
+	out, err := gitshell.Output(ctx, repoRoot, append([]string{"diff", "--diff-algorithm=minimal", base, "--", "."}, excludes...)...)
+	if err != nil {
+		return nil, err
+	}
+	untracked, err := gitshell.Output(ctx, repoRoot, append([]string{"ls-files", "--others", "--exclude-standard", "--", "."}, excludes...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	// 未跟踪文件只以 new file 头出现，够启发式判断类型，也避免把大文件整份发给模型
+	var sb strings.Builder
+	sb.WriteString(out)
+	for _, path := range strings.Split(strings.TrimSpace(untracked), "\n") {
+		if path != "" {
+			fmt.Fprintf(&sb, "\ndiff --git a/%s b/%s\nnew file mode 100644\n", path, path)
+		}
+	}
+	diff, err := gitdiff.Read(strings.NewReader(sb.String()), utils.DefaultDiffLimits)
+	if err != nil {
+		return nil, err
+	}
+	if len(diff.Stats) == 0 {
+		return nil, nil
+	}
+	for _, st := range diff.Stats {
+		diff.Files = append(diff.Files, st.Path)
+	}
+	diff.Tokens = gitdiff.CountTokens(diff.Diff)
+	return diff, nil
+}
+
+// ignoredDirs 返回被 .gitignore 整体忽略的目录（绝对路径），如 node_modules/、dist/
+func ignoredDirs(ctx context.Context, repoRoot string) map[string]bool {
+	dirs := make(map[string]bool)
+	out, err := gitshell.Output(ctx, repoRoot, "ls-files", "--others", "--ignored", "--exclude-standard", "--directory")
+	if err != nil {
+		log.Debug().Err(err).Msg("failed to list ignored dirs")
+		return dirs
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if dir, ok := strings.CutSuffix(strings.TrimSpace(line), "/"); ok {
+			dirs[filepath.Join(repoRoot, filepath.FromSlash(dir))] = true
+		}
+	}
+	return dirs
+}
+
+func isGitPath(rel string) bool {
+	rel = filepath.ToSlash(rel)
+	return rel == ".git" || strings.HasPrefix(rel, ".git/")
+}
+
+// gitIgnored 报告 rel 是否被 .gitignore 忽略
+func gitIgnored(ctx context.Context, repoRoot, rel string) bool {
+	_, err := gitshell.Run(ctx, repoRoot, "check-ignore", "-q", rel)
+	return err == nil
+}
@@ -0,0 +1,119 @@
+package fastcommitcmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pubgo/funk/v2/errors"
+	"github.com/pubgo/funk/v2/log"
+	"github.com/pubgo/redant"
+
+	"github.com/pubgo/fastgit/utils"
+)
+
+// wipPrefix 是 commit wip 提交标题的前缀，wip pop 只撤销以它开头的提交
+const wipPrefix = "wip:"
+
+// wipRemoteRef 是 commit wip --push 推送到的远端分支，不影响当前分支的上游
+func wipRemoteRef(branch string) string {
+	return "refs/heads/wip/" + branch
+}
+
+// wipMessage 生成 wip 提交信息：有说明时为 wip: <说明>，否则为 wip: <branch> at <时间>
+func wipMessage(branch, note string, now time.Time) string {
+	if note = strings.TrimSpace(note); note != "" {
+		return wipPrefix + " " + note
+	}
+	return fmt.Sprintf("%s %s at %s", wipPrefix, branch, now.Format(time.DateTime))
+}
+
+func newWipCommand() *redant.Command {
+	var (
+		push    bool
+		message string
+	)
+
+	return &redant.Command{
+		Use:      "wip",
+		Short:    "暂存全部改动并以 wip: 提交（不调用 AI、跳过 hook），wip pop 撤回到工作区",
+		Metadata: utils.NoTTYMetadata(),
+		Options: redant.OptionSet{
+			{Flag: "push", Description: "同时强制推送到远端 wip/<branch> 分支，不影响当前分支", Value: redant.BoolOf(&push)},
+			{Flag: "message", Shorthand: "m", Description: "附加说明，提交信息为 wip: <说明>", Value: redant.StringOf(&message)},
+		},
+		Children: []*redant.Command{
+			{
+				Use:      "pop",
+				Short:    "soft reset 掉最近的 wip: 提交，改动回到暂存区与工作区",
+				Metadata: utils.NoTTYMetadata(),
+				Handler: func(ctx context.Context, inv *redant.Invocation) error {
+					return popWip(ctx)
+				},
+			},
+		},
+		Handler: func(ctx context.Context, inv *redant.Invocation) error {
+			return commitWip(ctx, message, push)
+		},
+	}
+}
+
+// commitWip 执行 git add -A 后以 wip: 信息提交；--no-verify 让半成品不被 hook 拦下
+func commitWip(ctx context.Context, note string, push bool) error {
+	dirty, err := utils.IsDirty().UnwrapErr()
+	if err != nil {
+		return err
+	}
+	if !dirty {
+		log.Info().Msg("working tree clean, nothing to checkpoint")
+		return nil
+	}
+
+	branch := currentBranch()
+	if err := utils.ShellExec(ctx, "git", "add", "-A"); err != nil {
+		return err
+	}
+	msg := wipMessage(branch, note, time.Now())
+	if err := utils.ShellExec(ctx, "git", "commit", "--no-verify", "-m", strconv.Quote(msg)); err != nil {
+		return err
+	}
+	log.Info().Str("message", msg).Msg("wip committed, undo with `fastgit commit wip pop`")
+
+	if !push {
+		return nil
+	}
+	if branch == "" || branch == "HEAD" {
+		return errors.New("detached HEAD, wip --push needs a branch")
+	}
+	res, err := utils.GitPush(ctx, "--force", "origin", "HEAD:"+wipRemoteRef(branch))
+	if err != nil {
+		return err
+	}
+	if strings.Contains(res, "failed to push some refs") {
+		return errors.Errorf("failed to push wip/%s:\n%s", branch, res)
+	}
+	log.Info().Str("ref", "origin/wip/"+branch).Msg("wip pushed")
+	return nil
+}
+
+// popWip 撤销 HEAD 上的 wip: 提交，改动保留在暂存区；HEAD 不是 wip 提交时拒绝，避免误撤正常提交
+func popWip(ctx context.Context) error {
+	subject, err := utils.ShellExecOutput(ctx, "git", "log", "-1", "--pretty=%s").UnwrapErr()
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(strings.TrimSpace(subject), wipPrefix) {
+		return errors.Errorf("HEAD is not a wip commit: %q", subject)
+	}
+	if parent := utils.ShellExecOutput(ctx, "git", "rev-parse", "--verify", "--quiet", "HEAD^").UnwrapOr(""); parent == "" {
+		return errors.New("the wip commit is the root commit, nothing to reset to")
+	}
+
+	if err := utils.ShellExec(ctx, "git", "reset", "--soft", "HEAD^"); err != nil {
+		return err
+	}
+	log.Info().Str("commit", subject).Msg("wip popped, changes are staged")
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/pubgo/fastgit/pkg/execlog"
 	"github.com/pubgo/fastgit/utils"
 )
 
@@ -388,7 +389,10 @@ func runGitCommand(ctx context.Context, args ...string) error {
 	cmd.Stderr = os.Stderr
 	cmd.Stdin = os.Stdin
 
-	if err := cmd.Run(); err != nil {
+	record := execlog.Track("", args)
+	err := cmd.Run()
+	record(err)
+	if err != nil {
 		return fmt.Errorf("git %s failed: %w", strings.Join(args, " "), err)
 	}
 
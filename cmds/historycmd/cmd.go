@@ -22,6 +22,8 @@ func New() *redant.Command {
 		Use:   "history",
 		Short: "shell history command management",
 		Children: []*redant.Command{
+			newPickCommand(),
+			newInitCommand(),
 			{
 				Use: "rewrite",
 				Handler: func(ctx context.Context, command *redant.Invocation) error {
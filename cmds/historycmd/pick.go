@@ -0,0 +1,143 @@
+package historycmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pubgo/redant"
+
+	"github.com/pubgo/fastgit/utils"
+	"github.com/pubgo/fastgit/utils/fzfutil"
+)
+
+// zshExtended 匹配 zsh EXTENDED_HISTORY 的行首 ": <时间戳>:<耗时>;"
+var zshExtended = regexp.MustCompile(`^: \d+:\d+;`)
+
+func newPickCommand() *redant.Command {
+	var (
+		query  string
+		file   string
+		toClip bool
+	)
+
+	return &redant.Command{
+		Use:   "pick",
+		Short: "用 fzf 从 shell 历史中选择命令并输出到 stdout，可选复制到剪贴板",
+		Long:  "历史按时间倒序去重；--copy 写入系统剪贴板，SSH 会话中通过 OSC52 写入本地终端剪贴板。配合 `fastgit history init zsh|bash` 的快捷键使用。",
+		Options: redant.OptionSet{
+			{Flag: "query", Description: "初始搜索词（快捷键会传入当前命令行）", Value: redant.StringOf(&query)},
+			{Flag: "file", Description: "历史文件（默认 $HISTFILE，否则 ~/.zsh_history 或 ~/.bash_history）", Value: redant.StringOf(&file)},
+			{Flag: "copy", Description: "把选中的命令复制到剪贴板", Value: redant.BoolOf(&toClip)},
+		},
+		Handler: func(ctx context.Context, inv *redant.Invocation) error {
+			path, err := historyFile(file)
+			if err != nil {
+				return err
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			commands := parseHistory(data)
+			if len(commands) == 0 {
+				return fmt.Errorf("no commands in %s", path)
+			}
+
+			selected, err := fzfutil.Pick(ctx, strings.NewReader(strings.Join(commands, "\n")), "history> ", query)
+			if err != nil {
+				return err
+			}
+			if toClip {
+				method, err := utils.CopyToClipboard(selected)
+				if err != nil {
+					return fmt.Errorf("copy to clipboard: %w", err)
+				}
+				_, _ = fmt.Fprintf(inv.Stderr, "copied to clipboard (%s)\n", method)
+			}
+			_, _ = fmt.Fprintln(inv.Stdout, selected)
+			return nil
+		},
+	}
+}
+
+// historyFile 按 --file、$HISTFILE、~/.zsh_history、~/.bash_history 的顺序查找历史文件
+func historyFile(flag string) (string, error) {
+	if flag = strings.TrimSpace(flag); flag != "" {
+		return flag, nil
+	}
+	if env := strings.TrimSpace(os.Getenv("HISTFILE")); env != "" {
+		return env, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	for _, name := range []string{".zsh_history", ".bash_history"} {
+		path := filepath.Join(home, name)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("no shell history file found, use --file or set HISTFILE")
+}
+
+// parseHistory 解析 zsh/bash 历史：去掉 zsh 扩展格式的时间戳，合并 \ 续行，按时间倒序去重
+func parseHistory(data []byte) []string {
+	data = unmetafy(data)
+
+	var entries []string
+	var pending []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if len(pending) == 0 {
+			line = zshExtended.ReplaceAllString(line, "")
+			// bash HISTTIMEFORMAT 写入的时间戳行
+			if strings.HasPrefix(line, "#") && isNumber(strings.TrimPrefix(line, "#")) {
+				continue
+			}
+		}
+		// fzf 按行选择，续行与多行命令合并为一行
+		if strings.HasSuffix(line, "\\") {
+			pending = append(pending, strings.TrimRight(strings.TrimSuffix(line, "\\"), " \t"))
+			continue
+		}
+		pending = append(pending, strings.TrimSpace(line))
+		if entry := strings.TrimSpace(strings.Join(pending, " ")); entry != "" {
+			entries = append(entries, entry)
+		}
+		pending = nil
+	}
+
+	seen := make(map[string]bool, len(entries))
+	out := make([]string, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if !seen[entry] {
+			seen[entry] = true
+			out = append(out, entry)
+		}
+	}
+	return out
+}
+
+// unmetafy 还原 zsh 历史文件中的 meta 编码（0x83 后一字节异或 32），否则非 ASCII 命令会乱码
+func unmetafy(data []byte) []byte {
+	const meta = 0x83
+	if !bytes.Contains(data, []byte{meta}) {
+		return data
+	}
+	out := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		if data[i] == meta && i+1 < len(data) {
+			i++
+			out = append(out, data[i]^32)
+			continue
+		}
+		out = append(out, data[i])
+	}
+	return out
+}
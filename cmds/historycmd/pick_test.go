@@ -0,0 +1,45 @@
+package historycmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseHistory(t *testing.T) {
+	data := ": 1700000000:0;git status\n: 1700000001:0;go test \\\n./...\n#1700000002\ngit status\n\n: 1700000003:0;echo done\n"
+	got := parseHistory([]byte(data))
+	want := []string{"echo done", "git status", "go test ./..."}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("parseHistory() = %q, want %q", got, want)
+	}
+
+	// zsh 以 0x83 meta 编码非 ASCII 字节
+	meta := []byte("echo ")
+	for _, b := range []byte("é") {
+		if b >= 0x83 && b <= 0xa2 {
+			meta = append(meta, 0x83, b^32)
+		} else {
+			meta = append(meta, b)
+		}
+	}
+	if got := parseHistory(meta); len(got) != 1 || got[0] != "echo é" {
+		t.Fatalf("unexpected unmetafied history: %q", got)
+	}
+}
+
+func TestWidgetScript(t *testing.T) {
+	zsh, err := widgetScript("zsh", "ctrl-r")
+	if err != nil || !strings.Contains(zsh, "bindkey '^R' fastgit-history-widget") {
+		t.Fatalf("unexpected zsh widget (%v):\n%s", err, zsh)
+	}
+	bash, err := widgetScript("bash", "ctrl-g")
+	if err != nil || !strings.Contains(bash, `bind -x '"\C-g": __fastgit_history_widget'`) {
+		t.Fatalf("unexpected bash widget (%v):\n%s", err, bash)
+	}
+	if _, err := widgetScript("fish", "ctrl-g"); err == nil {
+		t.Fatal("expected error for unsupported shell")
+	}
+	if _, err := widgetScript("zsh", "alt-x"); err == nil {
+		t.Fatal("expected error for unsupported key")
+	}
+}
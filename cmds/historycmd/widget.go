@@ -0,0 +1,71 @@
+package historycmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pubgo/redant"
+)
+
+const zshWidget = `# fastgit history widget: add to ~/.zshrc
+#   eval "$(fastgit history init zsh)"
+fastgit-history-widget() {
+  local selected
+  selected="$(fastgit history pick --query "$LBUFFER" </dev/tty)" || { zle reset-prompt; return; }
+  LBUFFER="$selected"
+  zle reset-prompt
+}
+zle -N fastgit-history-widget
+bindkey '%s' fastgit-history-widget
+`
+
+const bashWidget = `# fastgit history widget: add to ~/.bashrc
+#   eval "$(fastgit history init bash)"
+__fastgit_history_widget() {
+  local selected
+  selected="$(fastgit history pick --query "$READLINE_LINE" </dev/tty)" || return
+  READLINE_LINE="$selected"
+  READLINE_POINT=${#selected}
+}
+bind -x '"%s": __fastgit_history_widget'
+`
+
+func newInitCommand() *redant.Command {
+	var key string
+
+	return &redant.Command{
+		Use:   "init <zsh|bash>",
+		Short: "输出 shell 快捷键脚本，按键调用 history pick 并把选中的命令插入当前命令行",
+		Long:  "用法：eval \"$(fastgit history init zsh)\"；--key 指定快捷键，如 ctrl-g（默认）、ctrl-r。",
+		Options: redant.OptionSet{
+			{Flag: "key", Description: "快捷键，格式 ctrl-<字母>", Value: redant.StringOf(&key), Default: "ctrl-g"},
+		},
+		Handler: func(ctx context.Context, inv *redant.Invocation) error {
+			if len(inv.Args) != 1 {
+				return redant.DefaultHelpFn()(ctx, inv)
+			}
+			script, err := widgetScript(inv.Args[0], key)
+			if err != nil {
+				return err
+			}
+			_, _ = fmt.Fprint(inv.Stdout, script)
+			return nil
+		},
+	}
+}
+
+// widgetScript 生成指定 shell 的快捷键脚本，key 形如 ctrl-g
+func widgetScript(shell, key string) (string, error) {
+	letter, ok := strings.CutPrefix(strings.ToLower(strings.TrimSpace(key)), "ctrl-")
+	if !ok || len(letter) != 1 || letter[0] < 'a' || letter[0] > 'z' {
+		return "", fmt.Errorf("unsupported key %q, use ctrl-<letter>", key)
+	}
+	switch shell {
+	case "zsh":
+		return fmt.Sprintf(zshWidget, "^"+strings.ToUpper(letter)), nil
+	case "bash":
+		return fmt.Sprintf(bashWidget, `\C-`+letter), nil
+	}
+	return "", fmt.Errorf("unsupported shell %q, use zsh or bash", shell)
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"github.com/pubgo/fastgit/configs"
+	"github.com/pubgo/fastgit/pkg/envcrypt"
 	"github.com/pubgo/funk/v2/assert"
 	"github.com/pubgo/funk/v2/config"
 	"github.com/pubgo/funk/v2/log"
@@ -40,7 +41,9 @@ func New() *redant.Command {
 				log.Info().Msgf("env template exists: %s", envPath)
 			}
 
-			if pathutil.IsNotExist(localPath) {
+			if encrypted, _ := envcrypt.Find(localPath); encrypted != "" {
+				log.Info().Msgf("local env exists (encrypted): %s", encrypted)
+			} else if pathutil.IsNotExist(localPath) {
 				file := assert.Exit1(os.Create(localPath))
 				defer func() { _ = file.Close() }()
 				for name, cfg := range config.LoadEnvMap(cfgPath) {
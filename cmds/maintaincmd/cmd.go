@@ -0,0 +1,232 @@
+package maintaincmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/olekukonko/tablewriter"
+	"github.com/pubgo/redant"
+
+	"github.com/pubgo/fastgit/configs"
+	"github.com/pubgo/fastgit/pkg/maintain"
+	"github.com/pubgo/fastgit/utils"
+)
+
+// report 是 maintain --json 的输出
+type report struct {
+	Before maintain.Stats `json:"before"`
+	After  maintain.Stats `json:"after"`
+	Steps  []stepReport   `json:"steps"`
+}
+
+type stepReport struct {
+	Step     string `json:"step"`
+	Command  string `json:"command"`
+	Duration string `json:"duration"`
+}
+
+func New() *redant.Command {
+	var (
+		opts    maintain.Options
+		dryRun  bool
+		jsonOut bool
+	)
+
+	return &redant.Command{
+		Use:   "maintain",
+		Short: "仓库维护：git gc、repack（写 bitmap）、commit-graph，并显示前后对象库大小",
+		Long: "让大仓库的 status、log、fetch 等操作保持快速。示例：fastgit maintain；fastgit maintain --aggressive；" +
+			"fastgit maintain --skip repack；fastgit maintain schedule 交给 git maintenance 定期在后台执行。",
+		Metadata: utils.NoTTYMetadata(),
+		Options: redant.OptionSet{
+			{Flag: "aggressive", Description: "git gc --aggressive：更慢，包更小", Value: redant.BoolOf(&opts.Aggressive)},
+			{Flag: "prune", Description: "git gc --prune 的过期时间，如 now、1.week.ago", Value: redant.StringOf(&opts.Prune), Default: maintain.DefaultPrune},
+			{Flag: "skip", Description: "跳过的步骤：gc、repack、commit-graph（逗号分隔或可重复）", Value: redant.StringArrayOf(&opts.Skip)},
+			{Flag: "dry-run", Description: "只列出将执行的命令与当前大小", Value: redant.BoolOf(&dryRun)},
+			{Flag: "json", Description: "以 JSON 输出前后统计与各步骤耗时", Value: redant.BoolOf(&jsonOut)},
+		},
+		Children: []*redant.Command{
+			newStatsCommand(),
+			newScheduleCommand(),
+			newUnscheduleCommand(),
+		},
+		Handler: func(ctx context.Context, inv *redant.Invocation) error {
+			for _, step := range opts.Skip {
+				if !slices.Contains(maintain.Steps, step) {
+					return fmt.Errorf("unknown step %q, choose from %s", step, strings.Join(maintain.Steps, ", "))
+				}
+			}
+			root, err := configs.RepoPath()
+			if err != nil {
+				return err
+			}
+			before, err := maintain.Collect(ctx, root)
+			if err != nil {
+				return err
+			}
+
+			if dryRun {
+				for _, cmd := range maintain.Plan(opts) {
+					_, _ = fmt.Fprintln(inv.Stdout, cmd)
+				}
+				_, _ = fmt.Fprintf(inv.Stdout, "object store: %s\n", units.BytesSize(float64(before.Size())))
+				return nil
+			}
+
+			results, runErr := maintain.Run(ctx, root, opts, func(cmd maintain.Command) {
+				if !jsonOut {
+					_, _ = fmt.Fprintf(inv.Stderr, "%s: %s\n", cmd.Step, cmd)
+				}
+			})
+			after, err := maintain.Collect(ctx, root)
+			if err != nil {
+				return err
+			}
+
+			if jsonOut {
+				out := report{Before: before, After: after, Steps: []stepReport{}}
+				for _, r := range results {
+					out.Steps = append(out.Steps, stepReport{Step: r.Step, Command: r.String(), Duration: r.Duration.Round(time.Millisecond).String()})
+				}
+				enc := json.NewEncoder(inv.Stdout)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(out); err != nil {
+					return err
+				}
+				return runErr
+			}
+
+			for _, r := range results {
+				_, _ = fmt.Fprintf(inv.Stdout, "%-13s %s\n", r.Step, r.Duration.Round(time.Millisecond))
+			}
+			if err := printStats(inv, before, &after); err != nil {
+				return err
+			}
+			return runErr
+		},
+	}
+}
+
+func newStatsCommand() *redant.Command {
+	return &redant.Command{
+		Use:      "stats",
+		Metadata: utils.NoTTYMetadata(),
+		Short:    "查看对象库大小、包数量、commit-graph 与后台维护状态",
+		Handler: func(ctx context.Context, inv *redant.Invocation) error {
+			root, err := configs.RepoPath()
+			if err != nil {
+				return err
+			}
+			stats, err := maintain.Collect(ctx, root)
+			if err != nil {
+				return err
+			}
+			if err := printStats(inv, stats, nil); err != nil {
+				return err
+			}
+			scheduled := "off (fastgit maintain schedule)"
+			if maintain.Scheduled(ctx, root) {
+				scheduled = "on"
+			}
+			_, _ = fmt.Fprintf(inv.Stdout, "background maintenance: %s\n", scheduled)
+			return nil
+		},
+	}
+}
+
+func newScheduleCommand() *redant.Command {
+	return &redant.Command{
+		Use:      "schedule",
+		Metadata: utils.NoTTYMetadata(),
+		Short:    "注册到 git maintenance 并启动系统调度器，后台定期 prefetch、写 commit-graph、整理松散对象与增量 repack",
+		Handler: func(ctx context.Context, inv *redant.Invocation) error {
+			root, err := configs.RepoPath()
+			if err != nil {
+				return err
+			}
+			if err := maintain.Schedule(ctx, root); err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(inv.Stdout, "background maintenance scheduled for %s\n", root)
+			return nil
+		},
+	}
+}
+
+func newUnscheduleCommand() *redant.Command {
+	return &redant.Command{
+		Use:      "unschedule",
+		Metadata: utils.NoTTYMetadata(),
+		Short:    "把当前仓库移出 git maintenance 后台维护，其它已注册仓库不受影响",
+		Handler: func(ctx context.Context, inv *redant.Invocation) error {
+			root, err := configs.RepoPath()
+			if err != nil {
+				return err
+			}
+			if !maintain.Scheduled(ctx, root) {
+				_, _ = fmt.Fprintf(inv.Stdout, "%s is not scheduled\n", root)
+				return nil
+			}
+			if err := maintain.Unschedule(ctx, root); err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(inv.Stdout, "background maintenance removed for %s\n", root)
+			return nil
+		},
+	}
+}
+
+// printStats 输出对象库统计；after 不为空时并列显示前后两列与大小变化
+func printStats(inv *redant.Invocation, before maintain.Stats, after *maintain.Stats) error {
+	objects := func(n int, size int64) string {
+		return fmt.Sprintf("%d (%s)", n, units.BytesSize(float64(size)))
+	}
+	rows := [][]string{
+		{"loose objects", objects(before.LooseObjects, before.LooseSize)},
+		{"packs", objects(before.Packs, before.PackSize)},
+		{"packed objects", fmt.Sprint(before.PackedObjects)},
+		{"garbage", objects(before.Garbage, before.GarbageSize)},
+		{"commit-graph", fmt.Sprint(before.CommitGraph)},
+		{"total", units.BytesSize(float64(before.Size()))},
+	}
+	header := []string{"", "size"}
+	if after != nil {
+		header = []string{"", "before", "after"}
+		for i, v := range []string{
+			objects(after.LooseObjects, after.LooseSize),
+			objects(after.Packs, after.PackSize),
+			fmt.Sprint(after.PackedObjects),
+			objects(after.Garbage, after.GarbageSize),
+			fmt.Sprint(after.CommitGraph),
+			units.BytesSize(float64(after.Size())) + sizeChange(before.Size(), after.Size()),
+		} {
+			rows[i] = append(rows[i], v)
+		}
+	}
+
+	tt := tablewriter.NewWriter(inv.Stdout)
+	tt.Header(header)
+	for _, row := range rows {
+		if err := tt.Append(row); err != nil {
+			return err
+		}
+	}
+	return tt.Render()
+}
+
+func sizeChange(before, after int64) string {
+	if before == 0 || before == after {
+		return ""
+	}
+	diff := after - before
+	sign := "+"
+	if diff < 0 {
+		sign, diff = "-", -diff
+	}
+	return fmt.Sprintf(" (%s%s, %s%.1f%%)", sign, units.BytesSize(float64(diff)), sign, float64(diff)*100/float64(before))
+}
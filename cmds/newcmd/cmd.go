@@ -0,0 +1,206 @@
+package newcmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pubgo/dix/v2"
+	"github.com/pubgo/dix/v2/dixcontext"
+	"github.com/pubgo/funk/v2/errors"
+	"github.com/pubgo/funk/v2/log"
+	"github.com/pubgo/redant"
+	"github.com/yarlson/tap"
+
+	"github.com/pubgo/fastgit/pkg/repoconfig"
+	"github.com/pubgo/fastgit/pkg/scaffold"
+	"github.com/pubgo/fastgit/pkg/timing"
+)
+
+type cmdParams struct {
+	ScaffoldCfg []*scaffold.Config
+}
+
+type flagOptions struct {
+	name        string
+	module      string
+	description string
+	license     string
+	author      string
+	remote      string
+	noCommit    bool
+	list        bool
+}
+
+// New creates the new command.
+func New() *redant.Command {
+	var flags flagOptions
+
+	return &redant.Command{
+		Use:   "new <template> [dir]",
+		Short: "从模板创建新仓库（config.yaml 中 new.templates）",
+		Long: "拷贝模板（内置、本地目录或 go-getter 地址，如 github.com/acme/templates//go）到新目录，" +
+			"渲染 *.tmpl 文件，生成 LICENSE 与 .fastgit 配置，然后 git init 并以 conventional 信息完成首次提交。",
+		Options: redant.OptionSet{
+			{Flag: "name", Description: "项目名称（默认取目录名）", Value: redant.StringOf(&flags.name)},
+			{Flag: "module", Description: "模块路径，供模板中的 {{.Module}} 使用（默认等于名称）", Value: redant.StringOf(&flags.module)},
+			{Flag: "description", Description: "项目描述", Value: redant.StringOf(&flags.description)},
+			{Flag: "license", Description: "许可证：" + strings.Join(scaffold.Licenses(), "|"), Value: redant.StringOf(&flags.license)},
+			{Flag: "author", Description: "LICENSE 中的作者（默认 new.author 或 git config user.name）", Value: redant.StringOf(&flags.author)},
+			{Flag: "remote", Description: "添加为 origin 的远程仓库地址", Value: redant.StringOf(&flags.remote)},
+			{Flag: "no-commit", Description: "只 git init，不做首次提交", Value: redant.BoolOf(&flags.noCommit)},
+			{Flag: "list", Description: "列出可用模板", Value: redant.BoolOf(&flags.list)},
+		},
+		Handler: func(ctx context.Context, inv *redant.Invocation) error {
+			var params cmdParams
+			if di := dixcontext.GetOrNil(ctx); di != nil {
+				params = dix.Inject(di, params)
+			}
+
+			if flags.list || len(inv.Args) == 0 {
+				return listTemplates(inv, params.ScaffoldCfg)
+			}
+			return run(ctx, inv, params.ScaffoldCfg, flags)
+		},
+	}
+}
+
+func listTemplates(inv *redant.Invocation, cfgs []*scaffold.Config) error {
+	w := tabwriter.NewWriter(inv.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "NAME\tDESCRIPTION\tSOURCE")
+	for _, tpl := range scaffold.Templates(cfgs) {
+		source := tpl.Source
+		if source == "" {
+			source = "(builtin)"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", tpl.Name, tpl.Description, source)
+	}
+	return w.Flush()
+}
+
+func run(ctx context.Context, inv *redant.Invocation, cfgs []*scaffold.Config, flags flagOptions) error {
+	tpl, err := scaffold.Lookup(cfgs, inv.Args[0])
+	if err != nil {
+		return err
+	}
+
+	dir := ""
+	if len(inv.Args) > 1 {
+		dir = strings.TrimSpace(inv.Args[1])
+	}
+	if dir == "" {
+		dir = strings.TrimSpace(flags.name)
+	}
+	if dir == "" {
+		done := timing.Track(ctx, timing.PhaseUI, "project directory")
+		dir = strings.TrimSpace(tap.Text(ctx, tap.TextOptions{Message: "Project directory:", Placeholder: "my-project"}))
+		done()
+		if dir == "" {
+			return nil
+		}
+	}
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	vars := buildVars(ctx, cfgs, dir, flags)
+	if !slices.Contains(scaffold.Licenses(), vars.License) {
+		return errors.Errorf("unknown license %q, use one of %s", vars.License, strings.Join(scaffold.Licenses(), ", "))
+	}
+	if err := ensureEmpty(dir); err != nil {
+		return err
+	}
+
+	if err := scaffold.Fetch(ctx, tpl, dir); err != nil {
+		return err
+	}
+	if err := scaffold.Render(dir, vars); err != nil {
+		return err
+	}
+	if _, err := scaffold.WriteLicense(dir, vars); err != nil {
+		return err
+	}
+	if _, err := repoconfig.InitScaffold(dir); err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("chore: initial commit from %s template", tpl.Name)
+	if flags.noCommit {
+		message = ""
+	}
+	if err := scaffold.InitRepo(ctx, dir, message, flags.remote); err != nil {
+		return err
+	}
+
+	log.Info().Str("template", tpl.Name).Str("dir", dir).Msg("repository created")
+	if flags.remote != "" {
+		_, _ = fmt.Fprintf(inv.Stdout, "next: cd %s && fastgit push\n", dir)
+	}
+	return nil
+}
+
+// ensureEmpty 目标目录不存在或为空时才允许创建，避免覆盖已有文件
+func ensureEmpty(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return os.MkdirAll(dir, 0o755)
+	}
+	if err != nil {
+		return err
+	}
+	if len(entries) > 0 {
+		return errors.Errorf("%s already exists and is not empty", dir)
+	}
+	return nil
+}
+
+// buildVars 合并参数、配置与 git 用户信息，得到模板变量
+func buildVars(ctx context.Context, cfgs []*scaffold.Config, dir string, flags flagOptions) scaffold.Vars {
+	name := strings.TrimSpace(flags.name)
+	if name == "" {
+		name = filepath.Base(dir)
+	}
+	vars := scaffold.NewVars(name)
+	vars.Description = strings.TrimSpace(flags.description)
+	if m := strings.TrimSpace(flags.module); m != "" {
+		vars.Module = m
+	}
+
+	for _, cfg := range cfgs {
+		if cfg == nil {
+			continue
+		}
+		if cfg.Author != "" {
+			vars.Author = cfg.Author
+		}
+		if cfg.License != "" {
+			vars.License = cfg.License
+		}
+	}
+	if a := strings.TrimSpace(flags.author); a != "" {
+		vars.Author = a
+	}
+	if l := strings.TrimSpace(flags.license); l != "" {
+		vars.License = l
+	}
+	vars.License = strings.ToLower(vars.License)
+	if vars.Author == "" {
+		vars.Author = gitConfig(ctx, "user.name")
+	}
+	vars.Email = gitConfig(ctx, "user.email")
+	return vars
+}
+
+func gitConfig(ctx context.Context, key string) string {
+	out, err := exec.CommandContext(ctx, "git", "config", "--get", key).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
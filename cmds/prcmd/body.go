@@ -76,6 +76,11 @@ func renderBody(commits, diffStat, diffNames string, rc RepoContext) string {
 		b.WriteByte('\n')
 	}
 
+	if rc.Ticket != nil {
+		b.WriteString("\n## Ticket\n\n")
+		b.WriteString(renderTicket(rc.Ticket))
+	}
+
 	b.WriteString("\n## Changed files\n\n")
 	if strings.TrimSpace(diffNames) == "" {
 		b.WriteString("_No file changes detected against base._\n")
@@ -9,6 +9,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/pubgo/fastgit/pkg/ticket"
 )
 
 func TestSuggestTitleFromCommits(t *testing.T) {
@@ -93,3 +95,14 @@ func writeFile(t *testing.T, dir, name, content string) {
 	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
 	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
 }
+
+func TestRenderBodyIncludesTicket(t *testing.T) {
+	rc := RepoContext{Branch: "feature/ABC-1", BaseRef: "origin/main", Ticket: &ticket.Ticket{
+		Key: "ABC-1", Title: "Fix login", Status: "In Progress", URL: "https://acme.atlassian.net/browse/ABC-1",
+	}}
+	body := renderBody("- fix: login (dev)", "", "", rc)
+	require.Contains(t, body, "## Ticket\n\n- [ABC-1](https://acme.atlassian.net/browse/ABC-1) Fix login (In Progress)\n")
+
+	rc.Ticket = nil
+	require.NotContains(t, renderBody("", "", "", rc), "## Ticket")
+}
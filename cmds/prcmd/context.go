@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os/exec"
 	"strings"
+
+	"github.com/pubgo/fastgit/pkg/ticket"
 )
 
 // RepoContext holds git metadata needed for PR operations.
@@ -13,6 +15,7 @@ type RepoContext struct {
 	Branch   string
 	Upstream string
 	BaseRef  string
+	Ticket   *ticket.Ticket
 }
 
 // LoadRepoContext resolves branch, upstream, and base ref for the current repo.
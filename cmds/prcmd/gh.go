@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os/exec"
 	"strings"
+
+	"github.com/pubgo/fastgit/pkg/execlog"
 )
 
 // GhClient wraps the GitHub CLI.
@@ -103,7 +105,12 @@ func (g *GhClient) MergePR(ctx context.Context, method string, dryRun bool) (str
 func execInRepo(ctx context.Context, repoRoot string, name string, args ...string) error {
 	cmd := exec.CommandContext(ctx, name, args...)
 	cmd.Dir = repoRoot
+	record := func(error) {}
+	if name == "git" {
+		record = execlog.Track(repoRoot, args)
+	}
 	out, err := cmd.CombinedOutput()
+	record(err)
 	if err != nil {
 		return fmt.Errorf("%s %s: %w\n%s", name, strings.Join(args, " "), err, strings.TrimSpace(string(out)))
 	}
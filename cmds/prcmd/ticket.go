@@ -0,0 +1,47 @@
+package prcmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/pubgo/dix/v2"
+	"github.com/pubgo/dix/v2/dixcontext"
+
+	"github.com/pubgo/fastgit/pkg/ticket"
+)
+
+type ticketParams struct {
+	TicketCfg []*ticket.Config
+}
+
+// attachTicket 查询分支关联的工单并写入 rc；失败只输出 warning
+func attachTicket(ctx context.Context, rc *RepoContext, w io.Writer) {
+	di := dixcontext.GetOrNil(ctx)
+	if di == nil {
+		return
+	}
+	var params ticketParams
+	params = dix.Inject(di, params)
+
+	tk, err := ticket.Lookup(ctx, params.TicketCfg, rc.Branch)
+	if err != nil {
+		_, _ = fmt.Fprintf(w, "ticket warning: %v\n", err)
+		return
+	}
+	rc.Ticket = tk
+}
+
+func renderTicket(t *ticket.Ticket) string {
+	line := t.Key
+	if t.URL != "" {
+		line = fmt.Sprintf("[%s](%s)", t.Key, t.URL)
+	}
+	if t.Title != "" {
+		line += " " + t.Title
+	}
+	if t.Status != "" {
+		line += fmt.Sprintf(" (%s)", t.Status)
+	}
+	return "- " + line + "\n"
+}
@@ -0,0 +1,57 @@
+package previewcmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/pubgo/redant"
+
+	"github.com/pubgo/fastgit/pkg/gitshell"
+)
+
+func New() *redant.Command {
+	var (
+		ref  string
+		run  string
+		keep bool
+	)
+
+	return &redant.Command{
+		Use:   "preview <ref>",
+		Short: "在临时 worktree 中检出任意历史版本，可选执行构建/测试后自动清理",
+		Long:  "不会改动当前工作区、暂存区和 HEAD。示例：fastgit preview v1.2.0 --run 'go test ./...'",
+		Args: redant.ArgSet{
+			{Name: "ref", Description: "要预览的 tag / 分支 / commit", Value: redant.StringOf(&ref)},
+		},
+		Options: redant.OptionSet{
+			{Flag: "run", Description: "在预览 worktree 中执行的命令", Value: redant.StringOf(&run)},
+			{Flag: "keep", Description: "结束后保留 worktree（默认自动删除）", Value: redant.BoolOf(&keep)},
+		},
+		Handler: func(ctx context.Context, inv *redant.Invocation) error {
+			if ref == "" {
+				return redant.DefaultHelpFn()(ctx, inv)
+			}
+
+			repoRoot, err := gitshell.Run(ctx, ".", "rev-parse", "--show-toplevel")
+			if err != nil {
+				return fmt.Errorf("not in a git repository: %w", err)
+			}
+
+			// Ctrl+C 只中断命令，清理仍然执行
+			ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+			defer stop()
+
+			res, err := Preview(ctx, Options{RepoRoot: repoRoot, Ref: ref, Run: run, Keep: keep, Output: inv.Stdout})
+			switch {
+			case res.Dir == "":
+			case keep:
+				_, _ = fmt.Fprintf(inv.Stdout, "worktree kept at %s (remove with: fastgit worktree remove --path %s)\n", res.Dir, res.Dir)
+			case res.Removed:
+				_, _ = fmt.Fprintln(inv.Stdout, "preview worktree removed")
+			}
+			return err
+		},
+	}
+}
@@ -0,0 +1,88 @@
+package previewcmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/pubgo/fastgit/pkg/gitshell"
+	"github.com/pubgo/fastgit/pkg/tempworktree"
+)
+
+// Options controls a preview run.
+type Options struct {
+	// RepoRoot is the repository the worktree is created from.
+	RepoRoot string
+	// Ref is any commit-ish: tag, branch, sha, HEAD~3 ...
+	Ref string
+	// Run is an optional shell command executed inside the worktree.
+	Run string
+	// Keep leaves the worktree on disk instead of removing it.
+	Keep   bool
+	Output io.Writer
+}
+
+// Result describes the previewed worktree.
+type Result struct {
+	Dir     string
+	Commit  string
+	Subject string
+	Removed bool
+}
+
+// Preview checks Ref out into a detached temporary worktree, optionally runs a
+// command there, and removes the worktree afterwards unless Keep is set.
+// The main worktree, index and HEAD are never touched.
+func Preview(ctx context.Context, opts Options) (res Result, err error) {
+	out := opts.Output
+	if out == nil {
+		out = io.Discard
+	}
+
+	ref := strings.TrimSpace(opts.Ref)
+	if ref == "" {
+		return res, fmt.Errorf("ref is required")
+	}
+	commit, err := gitshell.Run(ctx, opts.RepoRoot, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil {
+		return res, fmt.Errorf("unknown ref %q", ref)
+	}
+	res.Commit = commit
+	res.Subject, _ = gitshell.Run(ctx, opts.RepoRoot, "log", "-1", "--format=%s", commit)
+
+	dir, err := tempworktree.Add(ctx, opts.RepoRoot, "fastgit-preview-", commit)
+	if err != nil {
+		return res, fmt.Errorf("create preview worktree: %w", err)
+	}
+	res.Dir = dir
+
+	defer func() {
+		if opts.Keep {
+			return
+		}
+		cerr := tempworktree.Remove(opts.RepoRoot, dir)
+		res.Removed = cerr == nil
+		if cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+
+	_, _ = fmt.Fprintf(out, "preview %s (%s %s)\nworktree: %s\n", ref, gitshell.ShortHash(commit), res.Subject, dir)
+	if strings.TrimSpace(opts.Run) == "" {
+		return res, nil
+	}
+
+	_, _ = fmt.Fprintf(out, "--> %s\n", opts.Run)
+	cmd := exec.CommandContext(ctx, "bash", "-c", opts.Run)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "FASTGIT_PREVIEW_REF="+ref, "FASTGIT_PREVIEW_COMMIT="+commit)
+	cmd.Stdout = out
+	cmd.Stderr = out
+	if err := cmd.Run(); err != nil {
+		return res, fmt.Errorf("command failed at %s: %w", gitshell.ShortHash(commit), err)
+	}
+	return res, nil
+}
@@ -0,0 +1,59 @@
+package previewcmd
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pubgo/fastgit/pkg/gitshell"
+)
+
+func TestPreviewRunsCommandAtRefAndCleansUp(t *testing.T) {
+	dir := t.TempDir()
+	run := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	run("init", "-q")
+	run("config", "user.email", "t@example.com")
+	run("config", "user.name", "t")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "v.txt"), []byte("one\n"), 0o644))
+	run("add", ".")
+	run("commit", "-qm", "first")
+	run("tag", "v1")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "v.txt"), []byte("two\n"), 0o644))
+	run("commit", "-qam", "second")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "dirty.txt"), []byte("wip\n"), 0o644))
+
+	var out strings.Builder
+	res, err := Preview(context.Background(), Options{RepoRoot: dir, Ref: "v1", Run: "cat v.txt", Output: &out})
+	require.NoError(t, err)
+	require.Equal(t, "first", res.Subject)
+	require.Contains(t, out.String(), "one")
+	require.True(t, res.Removed)
+	require.NoDirExists(t, res.Dir)
+
+	// 主工作区保持不变
+	data, err := os.ReadFile(filepath.Join(dir, "v.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "two\n", string(data))
+	require.FileExists(t, filepath.Join(dir, "dirty.txt"))
+
+	list, err := gitshell.Run(context.Background(), dir, "worktree", "list")
+	require.NoError(t, err)
+	require.Len(t, strings.Split(list, "\n"), 1)
+
+	res, err = Preview(context.Background(), Options{RepoRoot: dir, Ref: "HEAD~1", Run: "exit 2"})
+	require.Error(t, err)
+	require.True(t, res.Removed)
+
+	_, err = Preview(context.Background(), Options{RepoRoot: dir, Ref: "nope"})
+	require.Error(t, err)
+}
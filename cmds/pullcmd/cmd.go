@@ -9,6 +9,7 @@ import (
 	"path"
 	"strings"
 
+	"github.com/pubgo/fastgit/cmds/conflictcmd"
 	"github.com/pubgo/fastgit/pkg/gitconflict"
 	"github.com/pubgo/fastgit/pkg/workflow"
 	"github.com/pubgo/fastgit/utils"
@@ -16,7 +17,6 @@ import (
 	"github.com/pubgo/funk/v2/log"
 	"github.com/pubgo/funk/v2/result"
 	"github.com/pubgo/redant"
-	"mvdan.cc/sh/v3/shell"
 )
 
 func New() *redant.Command {
@@ -63,13 +63,20 @@ func New() *redant.Command {
 			if flagData.pullAll && flagData.hard {
 				return errors.New("--hard cannot be used with --all")
 			}
+			if err := conflictcmd.HandleInProgress(ctx, "", "pulling"); err != nil {
+				return err
+			}
 
 			if flagData.pullAll {
 				return utils.GitPull(ctx, "--all").GetErr()
 			}
 
+			branch, err := utils.BranchName()
+			if err != nil {
+				return err
+			}
 			if flagData.hard {
-				return hardSyncCurrentBranch(ctx, utils.GetBranchName())
+				return hardSyncCurrentBranch(ctx, branch)
 			}
 
 			isDirty := utils.IsDirty().Unwrap()
@@ -77,7 +84,7 @@ func New() *redant.Command {
 				return errors.New("working tree has uncommitted changes, use --hard to force sync or commit/stash first")
 			}
 
-			err := pullCurrentBranch(ctx, utils.GetBranchName())
+			err = pullCurrentBranch(ctx, branch)
 			if err != nil {
 				if gitconflict.HasConflicts(ctx, "") {
 					handleMergeConflict(ctx)
@@ -157,7 +164,7 @@ func handleMergeConflict(ctx context.Context) {
 	output, _ := cmd.Output()
 	files := strings.Split(strings.TrimSpace(string(output)), "\n")
 
-	editor := getEditor()
+	editor := utils.Editor(ctx)
 
 	for _, file := range files {
 		if file == "" {
@@ -165,14 +172,8 @@ func handleMergeConflict(ctx context.Context) {
 		}
 		fmt.Printf("📝 Conflict in file: %s\n", file)
 
-		editorArgs := buildEditorCommand(editor, file)
-		editCmd := exec.Command(editorArgs[0], editorArgs[1:]...)
-		editCmd.Stdin = os.Stdin
-		editCmd.Stdout = os.Stdout
-		editCmd.Stderr = os.Stderr
-
 		fmt.Printf("Opening editor '%s'...\n", editor)
-		if err := editCmd.Run(); err != nil {
+		if err := utils.EditorCommand(ctx, file).Run(); err != nil {
 			log.Printf("Failed to edit %s: %v", file, err)
 		}
 	}
@@ -181,37 +182,6 @@ func handleMergeConflict(ctx context.Context) {
 	informUserToAmendAndPush()
 }
 
-func getEditor() string {
-	if e := os.Getenv("EDITOR"); e != "" {
-		return e
-	}
-
-	if _, err := exec.LookPath("zed"); err == nil {
-		return "zed -w"
-	}
-
-	if _, err := exec.LookPath("code"); err == nil {
-		return "code -w"
-	}
-
-	if _, err := exec.LookPath("vim"); err == nil {
-		return "vim"
-	}
-
-	if _, err := exec.LookPath("nano"); err == nil {
-		return "nano"
-	}
-	return "vi"
-}
-
-func buildEditorCommand(editor, file string) []string {
-	fields, err := shell.Fields(editor, nil)
-	if err != nil || len(fields) == 0 {
-		return []string{editor, file}
-	}
-	return append(fields, file)
-}
-
 // 提示用户如何继续
 func informUserToAmendAndPush() {
 	fmt.Println("\n----------------------------------------")
@@ -6,18 +6,6 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-func TestBuildEditorCommand(t *testing.T) {
-	t.Run("editor with args", func(t *testing.T) {
-		args := buildEditorCommand("code -w", "a.txt")
-		require.Equal(t, []string{"code", "-w", "a.txt"}, args)
-	})
-
-	t.Run("editor without args", func(t *testing.T) {
-		args := buildEditorCommand("vim", "a.txt")
-		require.Equal(t, []string{"vim", "a.txt"}, args)
-	})
-}
-
 func TestSplitRemoteRef(t *testing.T) {
 	t.Run("standard origin branch", func(t *testing.T) {
 		remote, branch := splitRemoteRef("origin/main")
@@ -68,7 +68,10 @@ func New() *redant.Command {
 			if err != nil {
 				return err
 			}
-			branch := utils.GetBranchName()
+			branch, err := utils.BranchName()
+			if err != nil {
+				return err
+			}
 			bundle, err := repoconfig.Load(repoRoot)
 			if err != nil {
 				return err
@@ -81,18 +84,28 @@ func New() *redant.Command {
 				return errors.Errorf("--force cannot be used with --all")
 			}
 
-			var pushErr error
+			var pushArgs []string
 			if flagData.pushAll {
-				pushErr = utils.ShellExec(ctx, "git", "push", "--all", "origin")
+				pushArgs = []string{"--all", "origin"}
 			} else if flagData.pushForce {
-				pushErr = utils.ShellExec(ctx, "git", "push", "--force-with-lease", "--set-upstream", "origin", branch)
+				pushArgs = []string{"--force-with-lease", "--set-upstream", "origin", branch}
 			} else {
-				pushErr = utils.ShellExec(ctx, "git", "push", "--set-upstream", "origin", branch)
+				pushArgs = []string{"--set-upstream", "origin", branch}
 			}
-			if pushErr == nil {
-				workflow.PrintRecommendations(os.Stdout, "push")
+			out, err := utils.ShellExecOutput(ctx, append([]string{"git", "push"}, pushArgs...)...).UnwrapErr()
+			if err != nil {
+				return fmt.Errorf("%w\nauthentication problem? run `fastgit doctor auth` to diagnose", err)
+			}
+			if out != "" {
+				log.Info().Msgf("shell result: \n%s\n", out)
+			}
+			// origin 成功后再同步镜像，镜像失败单独报告
+			if err := utils.PushMirrors(ctx, i.Stderr, pushArgs...); err != nil {
+				return err
 			}
-			return pushErr
+			utils.OfferPullRequest(ctx, out)
+			workflow.PrintRecommendations(os.Stdout, "push")
+			return nil
 		},
 	}
 }
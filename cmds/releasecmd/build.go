@@ -0,0 +1,352 @@
+package releasecmd
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ManifestFile is written to the dist directory for the pipeline step that uploads the artifacts.
+const ManifestFile = "artifacts.json"
+
+// ChecksumFile uses the `sha256sum` format.
+const ChecksumFile = "checksums.txt"
+
+// Artifact is one built and packaged platform binary.
+type Artifact struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Os     string `json:"os"`
+	Arch   string `json:"arch"`
+	Format string `json:"format"`
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
+
+// Manifest describes a completed build.
+type Manifest struct {
+	Project   string     `json:"project"`
+	Version   string     `json:"version"`
+	Commit    string     `json:"commit"`
+	Date      string     `json:"date"`
+	Artifacts []Artifact `json:"artifacts"`
+	Checksums string     `json:"checksums"`
+}
+
+// BuildOptions controls a release build.
+type BuildOptions struct {
+	RepoRoot string
+	Version  string
+	Commit   string
+	Date     time.Time
+	// Platforms overrides Config.Platforms when set.
+	Platforms []string
+	Output    io.Writer
+}
+
+// Build cross-compiles every platform, packages the binaries, and writes
+// checksums.txt plus artifacts.json into the dist directory.
+func Build(ctx context.Context, cfg Config, opts BuildOptions) (Manifest, error) {
+	out := opts.Output
+	if out == nil {
+		out = io.Discard
+	}
+
+	dist, err := resolveDist(opts.RepoRoot, cfg.Dist)
+	if err != nil {
+		return Manifest{}, err
+	}
+	// dist 只存放本次产物，避免混入旧版本的归档
+	if err := os.RemoveAll(dist); err != nil {
+		return Manifest{}, err
+	}
+	if err := os.MkdirAll(filepath.Join(dist, ".bin"), 0o755); err != nil {
+		return Manifest{}, err
+	}
+
+	platforms := opts.Platforms
+	if len(platforms) == 0 {
+		platforms = cfg.Platforms
+	}
+
+	manifest := Manifest{
+		Project: cfg.Project,
+		Version: opts.Version,
+		Commit:  opts.Commit,
+		Date:    opts.Date.UTC().Format(time.RFC3339),
+	}
+	for _, platform := range platforms {
+		goos, goarch, err := ParsePlatform(platform)
+		if err != nil {
+			return manifest, err
+		}
+		vars := BuildVars{
+			Project:     cfg.Project,
+			Version:     opts.Version,
+			Commit:      opts.Commit,
+			ShortCommit: shortCommit(opts.Commit),
+			Date:        manifest.Date,
+			Os:          goos,
+			Arch:        goarch,
+		}
+
+		_, _ = fmt.Fprintf(out, "--> build %s/%s\n", goos, goarch)
+		binPath, err := compile(ctx, cfg, opts.RepoRoot, filepath.Join(dist, ".bin"), vars)
+		if err != nil {
+			return manifest, err
+		}
+
+		artifact, err := pack(cfg, opts.RepoRoot, dist, binPath, vars)
+		if err != nil {
+			return manifest, err
+		}
+		_, _ = fmt.Fprintf(out, "    %s (%d bytes)\n", artifact.Name, artifact.Size)
+		manifest.Artifacts = append(manifest.Artifacts, artifact)
+	}
+	if err := os.RemoveAll(filepath.Join(dist, ".bin")); err != nil {
+		return manifest, err
+	}
+
+	sort.Slice(manifest.Artifacts, func(i, j int) bool { return manifest.Artifacts[i].Name < manifest.Artifacts[j].Name })
+	var sums strings.Builder
+	for _, a := range manifest.Artifacts {
+		fmt.Fprintf(&sums, "%s  %s\n", a.SHA256, a.Name)
+	}
+	manifest.Checksums = filepath.Join(dist, ChecksumFile)
+	if err := os.WriteFile(manifest.Checksums, []byte(sums.String()), 0o644); err != nil {
+		return manifest, err
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return manifest, err
+	}
+	return manifest, os.WriteFile(filepath.Join(dist, ManifestFile), data, 0o644)
+}
+
+// resolveDist returns the absolute dist directory. Build empties it, so it must be a
+// subdirectory of the repository, never the repository itself, an ancestor or a path outside.
+func resolveDist(repoRoot, dist string) (string, error) {
+	root, err := filepath.Abs(repoRoot)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(dist) {
+		dist = filepath.Join(root, dist)
+	}
+	dist = filepath.Clean(dist)
+	rel, err := filepath.Rel(root, dist)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("release dist %q must be a subdirectory of the repository %s", dist, root)
+	}
+	return dist, nil
+}
+
+// ReadManifest loads artifacts.json from a dist directory.
+func ReadManifest(dist string) (Manifest, error) {
+	var m Manifest
+	data, err := os.ReadFile(filepath.Join(dist, ManifestFile))
+	if err != nil {
+		return m, fmt.Errorf("read release manifest (run `fastgit release build` first): %w", err)
+	}
+	return m, json.Unmarshal(data, &m)
+}
+
+func compile(ctx context.Context, cfg Config, repoRoot, binDir string, vars BuildVars) (string, error) {
+	ldflags, err := render("ldflags", cfg.Ldflags, vars)
+	if err != nil {
+		return "", err
+	}
+
+	binary := cfg.Binary
+	if vars.Os == "windows" {
+		binary += ".exe"
+	}
+	binPath := filepath.Join(binDir, vars.Os+"_"+vars.Arch, binary)
+
+	args := append([]string{"build"}, cfg.Flags...)
+	if ldflags != "" {
+		args = append(args, "-ldflags", ldflags)
+	}
+	args = append(args, "-o", binPath, cfg.Main)
+
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = repoRoot
+	cmd.Env = append(append(os.Environ(), cfg.Env...), "GOOS="+vars.Os, "GOARCH="+vars.Arch)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("go build %s/%s: %w\n%s", vars.Os, vars.Arch, err, strings.TrimSpace(string(output)))
+	}
+	return binPath, nil
+}
+
+func pack(cfg Config, repoRoot, dist, binPath string, vars BuildVars) (Artifact, error) {
+	name, err := render("archive name", cfg.Archive.Name, vars)
+	if err != nil {
+		return Artifact{}, err
+	}
+
+	format := cfg.Archive.FormatFor(vars.Os)
+	// 附加文件在归档中保留仓库内的相对路径，不同目录下的同名文件不会互相覆盖
+	files := map[string]string{filepath.Base(binPath): binPath}
+	for _, f := range cfg.Archive.Files {
+		rel := filepath.Clean(f)
+		if filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return Artifact{}, fmt.Errorf("archive file %q must be a path inside the repository", f)
+		}
+		files[filepath.ToSlash(rel)] = filepath.Join(repoRoot, rel)
+	}
+
+	switch format {
+	case "tar.gz":
+		name += ".tar.gz"
+		err = writeTarGz(filepath.Join(dist, name), files)
+	case "zip":
+		name += ".zip"
+		err = writeZip(filepath.Join(dist, name), files)
+	case "binary":
+		if vars.Os == "windows" {
+			name += ".exe"
+		}
+		err = copyFile(binPath, filepath.Join(dist, name))
+	default:
+		err = fmt.Errorf("unsupported archive format %q (tar.gz|zip|binary)", format)
+	}
+	if err != nil {
+		return Artifact{}, err
+	}
+
+	path := filepath.Join(dist, name)
+	sum, size, err := checksum(path)
+	if err != nil {
+		return Artifact{}, err
+	}
+	return Artifact{Name: name, Path: path, Os: vars.Os, Arch: vars.Arch, Format: format, SHA256: sum, Size: size}, nil
+}
+
+func writeTarGz(dst string, files map[string]string) error {
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	for _, name := range sortedKeys(files) {
+		info, err := os.Stat(files[name])
+		if err != nil {
+			return err
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = name
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if err := appendFile(tw, files[name]); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func writeZip(dst string, files map[string]string) error {
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+
+	for _, name := range sortedKeys(files) {
+		info, err := os.Stat(files[name])
+		if err != nil {
+			return err
+		}
+		hdr, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		hdr.Name = name
+		hdr.Method = zip.Deflate
+		w, err := zw.CreateHeader(hdr)
+		if err != nil {
+			return err
+		}
+		if err := appendFile(w, files[name]); err != nil {
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func appendFile(w io.Writer, path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	_, err = io.Copy(w, src)
+	return err
+}
+
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, 0o755)
+}
+
+func checksum(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func shortCommit(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
@@ -0,0 +1,97 @@
+package releasecmd
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfigDefaults(t *testing.T) {
+	dir := t.TempDir()
+	cfg, err := LoadConfig(dir)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Base(dir), cfg.Binary)
+	require.Equal(t, "zip", cfg.Archive.FormatFor("windows"))
+	require.Equal(t, "tar.gz", cfg.Archive.FormatFor("linux"))
+
+	_, _, err = ParsePlatform("linux")
+	require.Error(t, err)
+}
+
+func TestBuildPackagesAndChecksums(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/hello\n\ngo 1.21\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nvar version string\n\nfunc main() { println(version) }\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("hello\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "docs"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "docs", "README.md"), []byte("docs\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".fastgit"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".fastgit", "release.yaml"), []byte(`project: hello
+ldflags: "-X main.version={{ .Version }}"
+flags: [-trimpath]
+archive:
+  files: [README.md, docs/README.md]
+`), 0o644))
+
+	cfg, err := LoadConfig(dir)
+	require.NoError(t, err)
+	platform := runtime.GOOS + "/" + runtime.GOARCH
+	manifest, err := Build(context.Background(), cfg, BuildOptions{
+		RepoRoot:  dir,
+		Version:   "v1.0.0",
+		Commit:    "0123456789abcdef",
+		Date:      time.Unix(0, 0),
+		Platforms: []string{platform},
+	})
+	require.NoError(t, err)
+	require.Len(t, manifest.Artifacts, 1)
+
+	a := manifest.Artifacts[0]
+	require.True(t, strings.HasPrefix(a.Name, "hello-v1.0.0-"+runtime.GOOS+"-"+runtime.GOARCH))
+	sums, err := os.ReadFile(manifest.Checksums)
+	require.NoError(t, err)
+	require.Equal(t, a.SHA256+"  "+a.Name+"\n", string(sums))
+	require.NoDirExists(t, filepath.Join(dir, "dist", ".bin"))
+
+	read, err := ReadManifest(filepath.Join(dir, "dist"))
+	require.NoError(t, err)
+	require.Equal(t, manifest.Artifacts, read.Artifacts)
+
+	if a.Format == "tar.gz" {
+		f, err := os.Open(a.Path)
+		require.NoError(t, err)
+		defer f.Close()
+		gz, err := gzip.NewReader(f)
+		require.NoError(t, err)
+		tr := tar.NewReader(gz)
+		var names []string
+		for {
+			hdr, err := tr.Next()
+			if err != nil {
+				break
+			}
+			names = append(names, hdr.Name)
+		}
+		require.Equal(t, []string{"README.md", "docs/README.md", "hello"}, names)
+	}
+}
+
+func TestResolveDistStaysInsideRepo(t *testing.T) {
+	root := t.TempDir()
+	dist, err := resolveDist(root, "dist")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(root, "dist"), dist)
+
+	for _, bad := range []string{".", "..", "../out", "dist/../..", filepath.Dir(root), os.TempDir()} {
+		_, err := resolveDist(root, bad)
+		require.Error(t, err, bad)
+	}
+}
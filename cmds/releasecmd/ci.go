@@ -0,0 +1,41 @@
+package releasecmd
+
+import (
+	"context"
+	"os"
+	"strings"
+)
+
+// VersionEnv overrides the release version in pipelines when --version is not given.
+const VersionEnv = "FASTGIT_RELEASE_VERSION"
+
+// CIResult is the JSON document printed by `release build --ci`.
+type CIResult struct {
+	Manifest
+	Dist       string `json:"dist"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// resolveVersion 取版本号：--version > FASTGIT_RELEASE_VERSION > CI 的 tag 变量 > git describe
+func resolveVersion(ctx context.Context, repoRoot, flag string) string {
+	if v := strings.TrimSpace(flag); v != "" {
+		return v
+	}
+	if v := strings.TrimSpace(os.Getenv(VersionEnv)); v != "" {
+		return v
+	}
+	if v := ciTag(); v != "" {
+		return v
+	}
+	return detectVersion(ctx, repoRoot)
+}
+
+// ciTag 读取 GitHub Actions / GitLab CI 在 tag 流水线中提供的 tag 名
+func ciTag() string {
+	if os.Getenv("GITHUB_REF_TYPE") == "tag" {
+		if v := strings.TrimSpace(os.Getenv("GITHUB_REF_NAME")); v != "" {
+			return v
+		}
+	}
+	return strings.TrimSpace(os.Getenv("CI_COMMIT_TAG"))
+}
@@ -0,0 +1,31 @@
+package releasecmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveVersion(t *testing.T) {
+	for _, env := range []string{VersionEnv, "GITHUB_REF_TYPE", "GITHUB_REF_NAME", "CI_COMMIT_TAG"} {
+		t.Setenv(env, "")
+	}
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	require.Equal(t, "snapshot", resolveVersion(ctx, dir, ""))
+
+	t.Setenv("CI_COMMIT_TAG", "v0.9.0")
+	require.Equal(t, "v0.9.0", resolveVersion(ctx, dir, ""))
+
+	t.Setenv("GITHUB_REF_NAME", "main")
+	require.Equal(t, "v0.9.0", resolveVersion(ctx, dir, ""), "branch refs are not versions")
+	t.Setenv("GITHUB_REF_TYPE", "tag")
+	t.Setenv("GITHUB_REF_NAME", "v1.0.0")
+	require.Equal(t, "v1.0.0", resolveVersion(ctx, dir, ""))
+
+	t.Setenv(VersionEnv, "v1.1.0")
+	require.Equal(t, "v1.1.0", resolveVersion(ctx, dir, ""))
+	require.Equal(t, "v2.0.0", resolveVersion(ctx, dir, " v2.0.0 "))
+}
@@ -0,0 +1,103 @@
+package releasecmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/pubgo/redant"
+
+	"github.com/pubgo/fastgit/pkg/gitshell"
+	"github.com/pubgo/fastgit/utils"
+)
+
+// New creates the release command group.
+func New() *redant.Command {
+	return &redant.Command{
+		Use:   "release",
+		Short: "发布产物：交叉编译、打包并生成校验和（配置见 .fastgit/release.yaml）",
+		Children: []*redant.Command{
+			newBuildCommand(),
+		},
+	}
+}
+
+func newBuildCommand() *redant.Command {
+	var (
+		version   string
+		platforms []string
+		local     bool
+		ci        bool
+	)
+
+	return &redant.Command{
+		Use:   "build",
+		Short: "按 platforms 交叉编译并打包，输出 checksums.txt 与 artifacts.json",
+		Long: "版本依次取 --version、FASTGIT_RELEASE_VERSION、CI 的 tag 变量（GITHUB_REF_NAME / CI_COMMIT_TAG）、HEAD 上的 tag，否则为 git describe 结果。" +
+			"产物写入 dist 目录（会先清空），由流水线自行上传。--ci 时不需要终端，构建日志写 stderr，stdout 只输出 JSON 结果。",
+		Metadata: utils.NoTTYMetadata(),
+		Options: redant.OptionSet{
+			{Flag: "version", Description: "产物版本号（默认 HEAD 上的 tag）", Value: redant.StringOf(&version)},
+			{Flag: "platform", Description: "只构建指定平台 os/arch（可重复）", Value: redant.StringArrayOf(&platforms)},
+			{Flag: "local", Description: "只构建当前平台，便于本地验证", Value: redant.BoolOf(&local)},
+			{Flag: "ci", Description: "流水线模式：stdout 输出 JSON 结果，日志写 stderr", Value: redant.BoolOf(&ci), Envs: []string{"FASTGIT_CI"}},
+		},
+		Handler: func(ctx context.Context, inv *redant.Invocation) error {
+			repoRoot, err := gitshell.Run(ctx, ".", "rev-parse", "--show-toplevel")
+			if err != nil {
+				return fmt.Errorf("not in a git repository: %w", err)
+			}
+			cfg, err := LoadConfig(repoRoot)
+			if err != nil {
+				return err
+			}
+
+			version = resolveVersion(ctx, repoRoot, version)
+			if local {
+				platforms = []string{runtime.GOOS + "/" + runtime.GOARCH}
+			}
+			commit, _ := gitshell.Run(ctx, repoRoot, "rev-parse", "HEAD")
+
+			logOut := inv.Stdout
+			if ci {
+				logOut = inv.Stderr
+			}
+			start := time.Now()
+			manifest, err := Build(ctx, cfg, BuildOptions{
+				RepoRoot:  repoRoot,
+				Version:   version,
+				Commit:    commit,
+				Date:      start,
+				Platforms: platforms,
+				Output:    logOut,
+			})
+			if err != nil {
+				return err
+			}
+
+			if ci {
+				result := CIResult{Manifest: manifest, Dist: filepath.Dir(manifest.Checksums), DurationMs: time.Since(start).Milliseconds()}
+				enc := json.NewEncoder(inv.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(result)
+			}
+
+			_, _ = fmt.Fprintf(inv.Stdout, "\nbuilt %d artifacts for %s %s in %s\n", len(manifest.Artifacts), manifest.Project, manifest.Version, time.Since(start).Round(time.Millisecond))
+			_, _ = fmt.Fprintf(inv.Stdout, "checksums: %s\n", manifest.Checksums)
+			return nil
+		},
+	}
+}
+
+func detectVersion(ctx context.Context, repoRoot string) string {
+	if tag, err := gitshell.Run(ctx, repoRoot, "describe", "--tags", "--exact-match", "HEAD"); err == nil && tag != "" {
+		return tag
+	}
+	if desc, err := gitshell.Run(ctx, repoRoot, "describe", "--tags", "--always", "--dirty"); err == nil && desc != "" {
+		return desc
+	}
+	return "snapshot"
+}
@@ -0,0 +1,126 @@
+package releasecmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is `.fastgit/release.yaml`, a goreleaser-lite build description.
+type Config struct {
+	Project string `yaml:"project"`
+	// Main is the package to build, relative to the repo root.
+	Main   string `yaml:"main"`
+	Binary string `yaml:"binary"`
+	// Dist is the output directory for archives, checksums and the manifest; it is emptied
+	// on every build and must be a subdirectory of the repository.
+	Dist      string   `yaml:"dist"`
+	Platforms []string `yaml:"platforms"`
+	// Ldflags is a text/template rendered with BuildVars.
+	Ldflags string   `yaml:"ldflags"`
+	Flags   []string `yaml:"flags"`
+	Env     []string `yaml:"env"`
+	Archive Archive  `yaml:"archive"`
+}
+
+// Archive describes how each binary is packaged.
+type Archive struct {
+	// Format is tar.gz, zip or binary (raw executable, no archive).
+	Format string `yaml:"format"`
+	// Name is a text/template rendered with BuildVars, without extension.
+	Name string `yaml:"name"`
+	// Files are extra repo files (README, LICENSE ...) added next to the binary, keeping
+	// their path relative to the repository root.
+	Files           []string          `yaml:"files"`
+	FormatOverrides map[string]string `yaml:"format_overrides"`
+}
+
+// BuildVars are the template variables available in ldflags and archive names.
+type BuildVars struct {
+	Project     string
+	Version     string
+	Commit      string
+	ShortCommit string
+	Date        string
+	Os          string
+	Arch        string
+}
+
+var defaultPlatforms = []string{"linux/amd64", "linux/arm64", "darwin/amd64", "darwin/arm64", "windows/amd64"}
+
+// LoadConfig reads `.fastgit/release.yaml` and fills defaults; a missing file yields the defaults.
+func LoadConfig(repoRoot string) (Config, error) {
+	var cfg Config
+	data, err := os.ReadFile(filepath.Join(repoRoot, ".fastgit", "release.yaml"))
+	if err != nil && !os.IsNotExist(err) {
+		return cfg, err
+	}
+	if err == nil {
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return cfg, fmt.Errorf("parse .fastgit/release.yaml: %w", err)
+		}
+	}
+
+	if strings.TrimSpace(cfg.Project) == "" {
+		cfg.Project = filepath.Base(repoRoot)
+	}
+	if strings.TrimSpace(cfg.Main) == "" {
+		cfg.Main = "."
+	}
+	if strings.TrimSpace(cfg.Binary) == "" {
+		cfg.Binary = cfg.Project
+	}
+	if strings.TrimSpace(cfg.Dist) == "" {
+		cfg.Dist = "dist"
+	}
+	if len(cfg.Platforms) == 0 {
+		cfg.Platforms = defaultPlatforms
+	}
+	if len(cfg.Env) == 0 {
+		cfg.Env = []string{"CGO_ENABLED=0"}
+	}
+	if strings.TrimSpace(cfg.Archive.Format) == "" {
+		cfg.Archive.Format = "tar.gz"
+	}
+	if strings.TrimSpace(cfg.Archive.Name) == "" {
+		cfg.Archive.Name = "{{ .Project }}-{{ .Version }}-{{ .Os }}-{{ .Arch }}"
+	}
+	if cfg.Archive.FormatOverrides == nil {
+		cfg.Archive.FormatOverrides = map[string]string{"windows": "zip"}
+	}
+	return cfg, nil
+}
+
+// FormatFor returns the archive format for goos, honouring format_overrides.
+func (a Archive) FormatFor(goos string) string {
+	if f := strings.TrimSpace(a.FormatOverrides[goos]); f != "" {
+		return f
+	}
+	return a.Format
+}
+
+// ParsePlatform splits "os/arch".
+func ParsePlatform(platform string) (string, string, error) {
+	goos, goarch, ok := strings.Cut(strings.TrimSpace(platform), "/")
+	if !ok || goos == "" || goarch == "" {
+		return "", "", fmt.Errorf("invalid platform %q, expected os/arch", platform)
+	}
+	return goos, goarch, nil
+}
+
+func render(name, text string, vars BuildVars) (string, error) {
+	tpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("parse %s template: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, vars); err != nil {
+		return "", fmt.Errorf("render %s template: %w", name, err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
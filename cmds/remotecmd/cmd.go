@@ -0,0 +1,108 @@
+package remotecmd
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/pubgo/funk/v2/errors"
+	"github.com/pubgo/redant"
+
+	"github.com/pubgo/fastgit/pkg/gitshell"
+	"github.com/pubgo/fastgit/utils"
+)
+
+const defaultMirrorName = "mirror"
+
+// New creates the remote command group.
+func New() *redant.Command {
+	return &redant.Command{
+		Use:   "remote",
+		Short: "远端管理：镜像 remote 与推送扇出",
+		Children: []*redant.Command{
+			newMirrorCommand(),
+		},
+	}
+}
+
+func newMirrorCommand() *redant.Command {
+	var (
+		url    string
+		name   string
+		remove bool
+	)
+
+	return &redant.Command{
+		Use:   "mirror [url]",
+		Short: "登记镜像 remote，push/tag 推送 origin 后同步推送到镜像",
+		Long:  "无参数时列出已登记的镜像。镜像登记在仓库 git config 的 fastgit.mirror 中，可重复执行以添加多个镜像。",
+		Args: redant.ArgSet{
+			{Name: "url", Description: "镜像仓库地址", Value: redant.StringOf(&url)},
+		},
+		Options: redant.OptionSet{
+			{Flag: "name", Description: "镜像 remote 名称", Value: redant.StringOf(&name), Default: defaultMirrorName},
+			{Flag: "remove", Description: "取消登记镜像（保留 git remote）", Value: redant.BoolOf(&remove)},
+		},
+		Handler: func(ctx context.Context, inv *redant.Invocation) error {
+			name = strings.TrimSpace(name)
+			url = strings.TrimSpace(url)
+			if name == "" {
+				return errors.New("mirror name is required")
+			}
+			if name == "origin" {
+				return errors.New("origin is the primary remote and cannot be a mirror")
+			}
+
+			switch {
+			case remove:
+				if !slices.Contains(utils.MirrorRemotes(ctx), name) {
+					return errors.Errorf("%s is not a registered mirror", name)
+				}
+				if _, err := gitshell.Run(ctx, ".", "config", "--unset", utils.MirrorConfigKey, "^"+regexp.QuoteMeta(name)+"$"); err != nil {
+					return err
+				}
+				_, _ = fmt.Fprintf(inv.Stdout, "mirror %s removed (remote kept, use `git remote remove %s` to delete it)\n", name, name)
+				return nil
+			case url == "":
+				return listMirrors(ctx, inv)
+			}
+
+			if existing, err := gitshell.Run(ctx, ".", "remote", "get-url", name); err == nil {
+				if existing != url {
+					if _, err := gitshell.Run(ctx, ".", "remote", "set-url", name, url); err != nil {
+						return err
+					}
+					_, _ = fmt.Fprintf(inv.Stdout, "remote %s: %s -> %s\n", name, existing, url)
+				}
+			} else if _, err := gitshell.Run(ctx, ".", "remote", "add", name, url); err != nil {
+				return err
+			}
+
+			if !slices.Contains(utils.MirrorRemotes(ctx), name) {
+				if _, err := gitshell.Run(ctx, ".", "config", "--add", utils.MirrorConfigKey, name); err != nil {
+					return err
+				}
+			}
+			_, _ = fmt.Fprintf(inv.Stdout, "mirror %s -> %s\npush/tag will now also push to %s\n", name, url, name)
+			return nil
+		},
+	}
+}
+
+func listMirrors(ctx context.Context, inv *redant.Invocation) error {
+	mirrors := utils.MirrorRemotes(ctx)
+	if len(mirrors) == 0 {
+		_, _ = fmt.Fprintln(inv.Stdout, "no mirrors, add one with `fastgit remote mirror <url>`")
+		return nil
+	}
+	for _, m := range mirrors {
+		url, err := gitshell.Run(ctx, ".", "remote", "get-url", m)
+		if err != nil {
+			url = "(remote missing)"
+		}
+		_, _ = fmt.Fprintf(inv.Stdout, "%s\t%s\n", m, url)
+	}
+	return nil
+}
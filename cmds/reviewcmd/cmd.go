@@ -6,11 +6,20 @@ import (
 	"os"
 	"strings"
 
+	"github.com/pubgo/dix/v2"
+	"github.com/pubgo/dix/v2/dixcontext"
+	"github.com/pubgo/redant"
+
+	"github.com/pubgo/fastgit/cmds/fastcommitcmd"
 	"github.com/pubgo/fastgit/pkg/aiprovider"
+	"github.com/pubgo/fastgit/pkg/repoconfig"
 	"github.com/pubgo/fastgit/utils"
-	"github.com/pubgo/redant"
 )
 
+type cmdParams struct {
+	CommitCfg []*fastcommitcmd.Config
+}
+
 // New creates the review command group.
 func New() *redant.Command {
 	root := &redant.Command{
@@ -33,12 +42,12 @@ func newStagedCommand() *redant.Command {
 		Short: "对 staged diff 输出 Blockers/Suggestions/Nits",
 		Options: redant.OptionSet{
 			{Flag: "dry-run", Description: "只说明将评审的内容，不调用 AI", Value: redant.BoolOf(&dryRun)},
-			{Flag: "ai-provider", Description: "AI 提供方 auto|openai|copilot", Value: redant.StringOf(&aiProvider), Default: "auto"},
+			{Flag: "ai-provider", Description: "AI 提供方 auto|openai|gemini|anthropic|ollama|copilot", Value: redant.StringOf(&aiProvider), Default: "auto"},
 		},
 		Handler: func(ctx context.Context, inv *redant.Invocation) error {
-			diffResult := utils.GetStagedDiff(ctx).Unwrap()
-			if diffResult == nil || strings.TrimSpace(diffResult.Diff) == "" {
-				return fmt.Errorf("no staged changes; stage files before review")
+			var params cmdParams
+			if di := dixcontext.GetOrNil(ctx); di != nil {
+				params = dix.Inject(di, params)
 			}
 
 			repoRoot, err := os.Getwd()
@@ -46,6 +55,12 @@ func newStagedCommand() *redant.Command {
 				return err
 			}
 
+			repoCfg, _ := repoconfig.Load(repoRoot)
+			diffResult := utils.GetStagedDiff(ctx, fastcommitcmd.DiffExcludes(params.CommitCfg, repoCfg)...).Unwrap()
+			if diffResult == nil || strings.TrimSpace(diffResult.Diff) == "" {
+				return fmt.Errorf("no staged changes; stage files before review")
+			}
+
 			provider := aiprovider.ResolveProvider(aiProvider, repoRoot)
 			report, err := ReviewStaged(ctx, provider, diffResult.Diff, dryRun)
 			if err != nil && !dryRun {
@@ -0,0 +1,159 @@
+package rewordcmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pubgo/dix/v2"
+	"github.com/pubgo/dix/v2/dixcontext"
+	"github.com/pubgo/redant"
+	"github.com/yarlson/tap"
+
+	"github.com/pubgo/fastgit/cmds/scorecmd"
+	"github.com/pubgo/fastgit/pkg/aiprovider"
+	"github.com/pubgo/fastgit/pkg/commitmsg"
+	"github.com/pubgo/fastgit/pkg/gitshell"
+	"github.com/pubgo/fastgit/pkg/repoconfig"
+)
+
+type cmdParams struct {
+	AI aiprovider.Provider
+}
+
+func New() *redant.Command {
+	var flags = new(struct {
+		rng    string
+		yes    bool
+		dryRun bool
+	})
+
+	return &redant.Command{
+		Use:   "reword",
+		Short: "用 AI 批量改写历史提交信息（conventional），确认后重写历史",
+		Long:  "示例：fastgit reword --range v1.2.0..HEAD。只改提交信息，不改代码内容；会改变提交 hash，已推送的分支需要 force push。旧 HEAD 保存在 ORIG_HEAD。",
+		Options: redant.OptionSet{
+			{Flag: "range", Description: "提交范围 <from>..<to>（不含 from），省略 to 时为 HEAD", Value: redant.StringOf(&flags.rng)},
+			{Flag: "yes", Description: "不逐条选择，直接应用所有有变化的建议", Value: redant.BoolOf(&flags.yes)},
+			{Flag: "dry-run", Description: "只展示新旧对比，不改写历史", Value: redant.BoolOf(&flags.dryRun)},
+		},
+		Handler: func(ctx context.Context, inv *redant.Invocation) error {
+			if strings.TrimSpace(flags.rng) == "" {
+				return redant.DefaultHelpFn()(ctx, inv)
+			}
+
+			var params cmdParams
+			if di := dixcontext.GetOrNil(ctx); di != nil {
+				params = dix.Inject(di, params)
+			}
+			if params.AI == nil {
+				params.AI = aiprovider.NewRuleFallback()
+			}
+
+			repoRoot, err := gitshell.Run(ctx, ".", "rev-parse", "--show-toplevel")
+			if err != nil {
+				return fmt.Errorf("not in a git repository: %w", err)
+			}
+			r, err := ResolveRange(ctx, repoRoot, flags.rng)
+			if err != nil {
+				return err
+			}
+			if len(r.Commits) == 0 {
+				_, _ = fmt.Fprintln(inv.Stdout, "no commits in range")
+				return nil
+			}
+
+			repoCfg, _ := repoconfig.Load(repoRoot)
+			locale, maxLength := "en", 72
+			if repoCfg.Commit.Locale != "" {
+				locale = repoCfg.Commit.Locale
+			}
+			if repoCfg.Commit.MaxLength > 0 {
+				maxLength = repoCfg.Commit.MaxLength
+			}
+			rules := scorecmd.Rules{Types: repoCfg.Commit.Types, MaxLength: maxLength}
+			system := commitmsg.AppendAllowedTypes(commitmsg.GeneratePrompt(locale, maxLength, commitmsg.ConventionalCommitType), repoCfg.Commit.Types)
+
+			_, _ = fmt.Fprintf(inv.Stdout, "generating messages for %d commits ...\n", len(r.Commits))
+			proposals, err := Propose(ctx, params.AI, repoRoot, r.Commits, system, rules)
+			if err != nil {
+				return err
+			}
+
+			changed := make([]Proposal, 0, len(proposals))
+			for _, p := range proposals {
+				if p.Changed() {
+					changed = append(changed, p)
+				}
+			}
+			printProposals(inv.Stdout, proposals)
+			if len(changed) == 0 {
+				_, _ = fmt.Fprintln(inv.Stdout, "nothing to reword")
+				return nil
+			}
+			if flags.dryRun {
+				return nil
+			}
+
+			selected := changed
+			if !flags.yes {
+				selected = selectProposals(ctx, changed)
+				if len(selected) == 0 {
+					_, _ = fmt.Fprintln(inv.Stdout, "no commits selected, history untouched")
+					return nil
+				}
+			}
+
+			messages := make(map[string]string, len(selected))
+			for _, p := range selected {
+				messages[p.Hash] = p.New
+			}
+			newHead, err := Rewrite(ctx, repoRoot, r.Base, messages)
+			if err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(inv.Stdout, "reworded %d commits, HEAD is now %s (previous tip saved in ORIG_HEAD)\n", len(selected), short(newHead))
+			_, _ = fmt.Fprintln(inv.Stdout, "undo: git reset --hard ORIG_HEAD; already pushed branches need: git push --force-with-lease")
+			return nil
+		},
+	}
+}
+
+func printProposals(w io.Writer, proposals []Proposal) {
+	for _, p := range proposals {
+		if !p.Changed() {
+			_, _ = fmt.Fprintf(w, "\n%s  (unchanged)\n    %s\n", short(p.Hash), subject(p.Old))
+			continue
+		}
+		_, _ = fmt.Fprintf(w, "\n%s  score %d -> %d\n", short(p.Hash), p.OldScore, p.NewScore)
+		for _, line := range strings.Split(strings.TrimSpace(p.Old), "\n") {
+			_, _ = fmt.Fprintf(w, "  - %s\n", line)
+		}
+		for _, line := range strings.Split(strings.TrimSpace(p.New), "\n") {
+			_, _ = fmt.Fprintf(w, "  + %s\n", line)
+		}
+	}
+	_, _ = fmt.Fprintln(w)
+}
+
+func selectProposals(ctx context.Context, changed []Proposal) []Proposal {
+	options := make([]tap.SelectOption[Proposal], 0, len(changed))
+	initial := make([]Proposal, 0, len(changed))
+	for _, p := range changed {
+		options = append(options, tap.SelectOption[Proposal]{
+			Value: p,
+			Label: short(p.Hash) + " " + subject(p.New),
+			Hint:  subject(p.Old),
+		})
+		// 新信息评分不低于原信息时默认勾选
+		if p.NewScore >= p.OldScore {
+			initial = append(initial, p)
+		}
+	}
+	return tap.MultiSelect(ctx, tap.MultiSelectOptions[Proposal]{
+		Message:       "选择要应用的新提交信息",
+		Options:       options,
+		InitialValues: initial,
+	})
+}
@@ -0,0 +1,205 @@
+package rewordcmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pubgo/fastgit/cmds/scorecmd"
+	"github.com/pubgo/fastgit/pkg/aiprovider"
+	"github.com/pubgo/fastgit/pkg/gitshell"
+)
+
+// maxDiffBytes 单个提交送入模型的 diff 上限，避免超长提交撑爆上下文
+const maxDiffBytes = 12000
+
+// Proposal is an old-vs-new message pair for one commit.
+type Proposal struct {
+	Hash     string
+	Old      string
+	New      string
+	OldScore int
+	NewScore int
+}
+
+// Changed reports whether the proposal actually differs from the original message.
+func (p Proposal) Changed() bool {
+	return p.New != "" && strings.TrimSpace(p.New) != strings.TrimSpace(p.Old)
+}
+
+// Range is a resolved `<from>..<to>` commit range; To is always an ancestor of HEAD.
+type Range struct {
+	Base    string
+	To      string
+	Commits []string
+}
+
+// ResolveRange parses `<from>..<to>` (or `<from>`, meaning `<from>..HEAD`) into oldest-first commits.
+func ResolveRange(ctx context.Context, repoRoot, raw string) (Range, error) {
+	from, to, found := strings.Cut(strings.TrimSpace(raw), "..")
+	if strings.HasPrefix(to, ".") {
+		return Range{}, fmt.Errorf("symmetric range %q is not supported, use <from>..<to>", raw)
+	}
+	if !found || strings.TrimSpace(to) == "" {
+		to = "HEAD"
+	}
+	if strings.TrimSpace(from) == "" {
+		return Range{}, fmt.Errorf("range %q has no start commit", raw)
+	}
+
+	var (
+		r   Range
+		err error
+	)
+	if r.Base, err = gitshell.Run(ctx, repoRoot, "rev-parse", "--verify", "--quiet", from+"^{commit}"); err != nil {
+		return r, fmt.Errorf("unknown commit %q", from)
+	}
+	if r.To, err = gitshell.Run(ctx, repoRoot, "rev-parse", "--verify", "--quiet", to+"^{commit}"); err != nil {
+		return r, fmt.Errorf("unknown commit %q", to)
+	}
+	if _, err := gitshell.Run(ctx, repoRoot, "merge-base", "--is-ancestor", r.To, "HEAD"); err != nil {
+		return r, fmt.Errorf("%s is not on the current branch; only history reachable from HEAD can be reworded", to)
+	}
+	if _, err := gitshell.Run(ctx, repoRoot, "merge-base", "--is-ancestor", r.Base, r.To); err != nil {
+		return r, fmt.Errorf("%s is not an ancestor of %s", from, to)
+	}
+
+	// 按 rebase 的方式线性重放，遇到 merge 提交无法保证拓扑不变，直接拒绝
+	merges, err := gitshell.Run(ctx, repoRoot, "rev-list", "--merges", r.Base+"..HEAD")
+	if err != nil {
+		return r, err
+	}
+	if merges != "" {
+		return r, fmt.Errorf("history after %s contains merge commits; reword only supports linear history", from)
+	}
+
+	list, err := gitshell.Run(ctx, repoRoot, "rev-list", "--reverse", r.Base+".."+r.To)
+	if err != nil {
+		return r, err
+	}
+	if list != "" {
+		r.Commits = strings.Split(list, "\n")
+	}
+	return r, nil
+}
+
+// Propose asks the provider for an improved message for every commit, using system as the prompt.
+func Propose(ctx context.Context, ai aiprovider.Provider, repoRoot string, commits []string, system string, rules scorecmd.Rules) ([]Proposal, error) {
+	proposals := make([]Proposal, 0, len(commits))
+	for _, hash := range commits {
+		old, err := gitshell.Run(ctx, repoRoot, "log", "-1", "--format=%B", hash)
+		if err != nil {
+			return nil, err
+		}
+		diff, err := gitshell.Run(ctx, repoRoot, "show", "--format=", "--patch", "--no-color", hash)
+		if err != nil {
+			return nil, err
+		}
+		if len(diff) > maxDiffBytes {
+			diff = diff[:maxDiffBytes] + "\n... (diff truncated)"
+		}
+
+		rsp, err := ai.Complete(ctx, aiprovider.CompleteRequest{
+			System: system,
+			User:   fmt.Sprintf("Original commit message (improve it, keep its intent):\n%s\n\nDiff:\n%s", old, diff),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("propose message for %s: %w", short(hash), err)
+		}
+
+		p := Proposal{Hash: hash, Old: old, New: cleanMessage(rsp.Text)}
+		p.OldScore = score(p.Old, rules)
+		p.NewScore = score(p.New, rules)
+		proposals = append(proposals, p)
+	}
+	return proposals, nil
+}
+
+// Rewrite replays base..HEAD with the given messages (keyed by original hash),
+// keeping trees and authorship untouched, then moves the current branch to the
+// new tip. The previous tip is saved in ORIG_HEAD.
+func Rewrite(ctx context.Context, repoRoot, base string, messages map[string]string) (string, error) {
+	dirty, err := gitshell.Run(ctx, repoRoot, "status", "--porcelain", "--untracked-files=no")
+	if err != nil {
+		return "", err
+	}
+	if dirty != "" {
+		return "", fmt.Errorf("working tree has uncommitted changes; commit or stash them before rewording")
+	}
+
+	oldHead, err := gitshell.Run(ctx, repoRoot, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	list, err := gitshell.Run(ctx, repoRoot, "rev-list", "--reverse", base+"..HEAD")
+	if err != nil {
+		return "", err
+	}
+	if list == "" {
+		return oldHead, nil
+	}
+
+	parent := base
+	for _, hash := range strings.Split(list, "\n") {
+		meta, err := gitshell.Run(ctx, repoRoot, "log", "-1", "--format=%T%x00%an%x00%ae%x00%ad", "--date=raw", hash)
+		if err != nil {
+			return "", err
+		}
+		fields := strings.Split(meta, "\x00")
+		if len(fields) != 4 {
+			return "", fmt.Errorf("unexpected metadata for %s", short(hash))
+		}
+
+		msg, ok := messages[hash]
+		if !ok {
+			if msg, err = gitshell.Run(ctx, repoRoot, "log", "-1", "--format=%B", hash); err != nil {
+				return "", err
+			}
+		}
+
+		env := []string{
+			"GIT_AUTHOR_NAME=" + fields[1],
+			"GIT_AUTHOR_EMAIL=" + fields[2],
+			"GIT_AUTHOR_DATE=" + fields[3],
+		}
+		parent, err = gitshell.RunWithEnv(ctx, repoRoot, env, []byte(strings.TrimSpace(msg)+"\n"), "commit-tree", fields[0], "-p", parent, "-F", "-")
+		if err != nil {
+			return "", fmt.Errorf("rewrite %s: %w", short(hash), err)
+		}
+	}
+
+	if _, err := gitshell.Run(ctx, repoRoot, "update-ref", "ORIG_HEAD", oldHead); err != nil {
+		return "", err
+	}
+	if _, err := gitshell.Run(ctx, repoRoot, "update-ref", "-m", "fastgit reword", "HEAD", parent, oldHead); err != nil {
+		return "", err
+	}
+	return parent, nil
+}
+
+// cleanMessage strips markdown fences and quotes some models wrap around the message.
+func cleanMessage(text string) string {
+	text = strings.TrimSpace(text)
+	text = strings.TrimPrefix(text, "```text")
+	text = strings.TrimPrefix(text, "```")
+	text = strings.TrimSuffix(text, "```")
+	text = strings.TrimSpace(text)
+	return strings.Trim(text, "\"`")
+}
+
+func score(msg string, rules scorecmd.Rules) int {
+	subject, body, _ := strings.Cut(strings.TrimSpace(msg), "\n")
+	return scorecmd.ScoreCommit(scorecmd.Commit{Subject: subject, Body: strings.TrimSpace(body)}, rules).Score
+}
+
+func subject(msg string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(msg), "\n")
+	return line
+}
+
+func short(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
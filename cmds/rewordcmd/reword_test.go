@@ -0,0 +1,73 @@
+package rewordcmd
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pubgo/fastgit/cmds/scorecmd"
+	"github.com/pubgo/fastgit/pkg/aiprovider"
+)
+
+type stubProvider struct{}
+
+func (stubProvider) Name() string    { return "stub" }
+func (stubProvider) Available() bool { return true }
+func (stubProvider) Complete(_ context.Context, req aiprovider.CompleteRequest) (aiprovider.CompleteResponse, error) {
+	if strings.Contains(req.User, "+b") {
+		return aiprovider.CompleteResponse{Text: "```\nfeat: add b file\n```"}, nil
+	}
+	return aiprovider.CompleteResponse{Text: "wip"}, nil
+}
+
+func TestProposeAndRewrite(t *testing.T) {
+	dir := t.TempDir()
+	run := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+		return strings.TrimSpace(string(out))
+	}
+	commit := func(name, msg string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(strings.TrimSuffix(name, ".txt")+"\n"), 0o644))
+		run("add", name)
+		run("commit", "-qm", msg)
+	}
+	run("init", "-q")
+	run("config", "user.email", "dev@example.com")
+	run("config", "user.name", "dev")
+	commit("a.txt", "init")
+	commit("b.txt", "wip")
+	commit("c.txt", "wip")
+	commit("d.txt", "docs: add d")
+	treeBefore := run("rev-parse", "HEAD^{tree}")
+
+	ctx := context.Background()
+	r, err := ResolveRange(ctx, dir, "HEAD~3..HEAD~1")
+	require.NoError(t, err)
+	require.Len(t, r.Commits, 2)
+
+	proposals, err := Propose(ctx, stubProvider{}, dir, r.Commits, "system", scorecmd.Rules{MaxLength: 72})
+	require.NoError(t, err)
+	require.True(t, proposals[0].Changed())
+	require.Equal(t, "feat: add b file", proposals[0].New)
+	require.Greater(t, proposals[0].NewScore, proposals[0].OldScore)
+	require.False(t, proposals[1].Changed())
+
+	_, err = Rewrite(ctx, dir, r.Base, map[string]string{proposals[0].Hash: proposals[0].New})
+	require.NoError(t, err)
+
+	require.Equal(t, "docs: add d\nwip\nfeat: add b file\ninit", run("log", "--format=%s"))
+	require.Equal(t, treeBefore, run("rev-parse", "HEAD^{tree}"))
+	require.Equal(t, "dev", run("log", "-1", "--format=%an", "HEAD~2"))
+	require.NotEmpty(t, run("rev-parse", "ORIG_HEAD"))
+
+	_, err = ResolveRange(ctx, dir, "HEAD...HEAD~1")
+	require.Error(t, err)
+}
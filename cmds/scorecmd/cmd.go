@@ -0,0 +1,130 @@
+package scorecmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/pubgo/fastgit/pkg/repoconfig"
+	"github.com/pubgo/redant"
+)
+
+// New creates the commit message scoring command.
+func New() *redant.Command {
+	var (
+		limit   int64
+		jsonOut bool
+	)
+
+	return &redant.Command{
+		Use:   "score",
+		Short: "评估提交信息质量（conventional / 长度 / 祈使语气 / 正文）",
+		Long:  "用法：fastgit score [range]，range 为 git log 范围（如 v1.0.0..HEAD），缺省时评估最近 --limit 个提交",
+		Options: redant.OptionSet{
+			{Flag: "limit", Description: "未指定 range 时评估的提交数", Value: redant.Int64Of(&limit), Default: "20"},
+			{Flag: "json", Description: "以 JSON 输出结果，便于持续追踪", Value: redant.BoolOf(&jsonOut)},
+		},
+		Handler: func(ctx context.Context, inv *redant.Invocation) error {
+			var rangeArg string
+			for _, arg := range inv.Command.Args {
+				if v := strings.TrimSpace(arg.Value.String()); v != "" {
+					rangeArg = v
+					break
+				}
+			}
+
+			repoRoot, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+			bundle, err := repoconfig.Load(repoRoot)
+			if err != nil {
+				return err
+			}
+
+			commits, err := loadCommits(ctx, rangeArg, int(limit))
+			if err != nil {
+				return err
+			}
+
+			summary := Summarize(commits, Rules{Types: bundle.Commit.Types, MaxLength: bundle.Commit.MaxLength})
+			if jsonOut {
+				enc := json.NewEncoder(inv.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(summary)
+			}
+			printSummary(inv.Stdout, summary)
+			return nil
+		},
+	}
+}
+
+const (
+	fieldSep  = "\x1f"
+	recordSep = "\x1e"
+)
+
+func loadCommits(ctx context.Context, rangeArg string, limit int) ([]Commit, error) {
+	args := []string{"log", "--no-merges", "--format=%h" + fieldSep + "%s" + fieldSep + "%b" + recordSep}
+	if rangeArg != "" {
+		args = append(args, rangeArg)
+	} else {
+		if limit <= 0 {
+			limit = 20
+		}
+		args = append(args, "-n", strconv.Itoa(limit))
+	}
+
+	out, err := exec.CommandContext(ctx, "git", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git log failed: %w", err)
+	}
+	return parseLog(string(out)), nil
+}
+
+func parseLog(output string) []Commit {
+	var commits []Commit
+	for _, record := range strings.Split(output, recordSep) {
+		record = strings.TrimSpace(record)
+		if record == "" {
+			continue
+		}
+		parts := strings.SplitN(record, fieldSep, 3)
+		if len(parts) < 2 {
+			continue
+		}
+		c := Commit{Hash: parts[0], Subject: strings.TrimSpace(parts[1])}
+		if len(parts) == 3 {
+			c.Body = strings.TrimSpace(parts[2])
+		}
+		commits = append(commits, c)
+	}
+	return commits
+}
+
+func printSummary(w io.Writer, sum Summary) {
+	if sum.Commits == 0 {
+		_, _ = fmt.Fprintln(w, "no commits to score")
+		return
+	}
+
+	for _, res := range sum.Results {
+		subject := res.Subject
+		if r := []rune(subject); len(r) > 60 {
+			subject = string(r[:57]) + "..."
+		}
+		_, _ = fmt.Fprintf(w, "%3d  %s  %s\n", res.Score, res.Hash, subject)
+		for _, issue := range res.Issues {
+			_, _ = fmt.Fprintf(w, "       - %s\n", issue)
+		}
+	}
+
+	_, _ = fmt.Fprintf(w, "\ncommits: %d  average: %d/100\n", sum.Commits, sum.Average)
+	_, _ = fmt.Fprintf(w, "conventional: %d%%  length: %d%%  imperative: %d%%  body: %d%%\n",
+		sum.ConventionalRate, sum.LengthRate, sum.ImperativeRate, sum.BodyRate)
+}
@@ -0,0 +1,189 @@
+package scorecmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// 各评分项权重，总分 100
+const (
+	weightConventional = 30
+	weightLength       = 25
+	weightImperative   = 25
+	weightBody         = 20
+)
+
+var conventionalPattern = regexp.MustCompile(`^([a-z]+)(\([^)]+\))?!?:\s+(.+)$`)
+
+// Commit 待评分的提交
+type Commit struct {
+	Hash    string `json:"hash"`
+	Subject string `json:"subject"`
+	Body    string `json:"body,omitempty"`
+}
+
+// Result 单个提交的评分结果
+type Result struct {
+	Commit
+	Score        int      `json:"score"`
+	Conventional bool     `json:"conventional"`
+	LengthOK     bool     `json:"length_ok"`
+	Imperative   bool     `json:"imperative"`
+	HasBody      bool     `json:"has_body"`
+	Issues       []string `json:"issues,omitempty"`
+}
+
+// Summary 汇总评分，各 *Rate 为满足该项的提交占比（0-100）
+type Summary struct {
+	Commits          int      `json:"commits"`
+	Average          int      `json:"average"`
+	ConventionalRate int      `json:"conventional_rate"`
+	LengthRate       int      `json:"length_rate"`
+	ImperativeRate   int      `json:"imperative_rate"`
+	BodyRate         int      `json:"body_rate"`
+	Results          []Result `json:"results"`
+}
+
+// Rules 评分规则，来自 .fastgit/commit.yaml
+type Rules struct {
+	Types     []string
+	MaxLength int
+}
+
+// ScoreCommit 按 conventional 格式、标题长度、祈使语气和正文四项给单个提交打分
+func ScoreCommit(c Commit, rules Rules) Result {
+	res := Result{Commit: c}
+	subject := strings.TrimSpace(c.Subject)
+	maxLength := rules.MaxLength
+	if maxLength <= 0 {
+		maxLength = 72
+	}
+
+	description := subject
+	if m := conventionalPattern.FindStringSubmatch(subject); m != nil {
+		description = m[3]
+		if len(rules.Types) == 0 || containsType(rules.Types, m[1]) {
+			res.Conventional = true
+			res.Score += weightConventional
+		} else {
+			res.Issues = append(res.Issues, fmt.Sprintf("type %q is not allowed", m[1]))
+		}
+	} else {
+		res.Issues = append(res.Issues, "not conventional (type(scope): subject)")
+	}
+
+	switch n := len([]rune(subject)); {
+	case n == 0:
+		res.Issues = append(res.Issues, "empty subject")
+	case n <= 50:
+		res.LengthOK = true
+		res.Score += weightLength
+	case n <= maxLength:
+		res.LengthOK = true
+		res.Score += weightLength * 3 / 5
+		res.Issues = append(res.Issues, fmt.Sprintf("subject is %d chars, prefer <= 50", n))
+	default:
+		res.Issues = append(res.Issues, fmt.Sprintf("subject is %d chars, exceeds %d", n, maxLength))
+	}
+
+	if IsImperative(description) {
+		res.Imperative = true
+		res.Score += weightImperative
+	} else {
+		res.Issues = append(res.Issues, "subject is not in imperative mood")
+	}
+
+	if strings.TrimSpace(c.Body) != "" {
+		res.HasBody = true
+		res.Score += weightBody
+	} else {
+		res.Issues = append(res.Issues, "no body")
+	}
+	return res
+}
+
+// Summarize 对一组提交评分并计算汇总数据
+func Summarize(commits []Commit, rules Rules) Summary {
+	sum := Summary{Commits: len(commits)}
+	if len(commits) == 0 {
+		return sum
+	}
+
+	var total, conventional, length, imperative, body int
+	for _, c := range commits {
+		res := ScoreCommit(c, rules)
+		total += res.Score
+		conventional += boolToInt(res.Conventional)
+		length += boolToInt(res.LengthOK)
+		imperative += boolToInt(res.Imperative)
+		body += boolToInt(res.HasBody)
+		sum.Results = append(sum.Results, res)
+	}
+
+	n := len(commits)
+	sum.Average = total / n
+	sum.ConventionalRate = conventional * 100 / n
+	sum.LengthRate = length * 100 / n
+	sum.ImperativeRate = imperative * 100 / n
+	sum.BodyRate = body * 100 / n
+	return sum
+}
+
+// IsImperative 粗略判断英文描述是否以祈使动词开头；非英文描述不做判断
+func IsImperative(description string) bool {
+	fields := strings.Fields(strings.TrimSpace(description))
+	if len(fields) == 0 {
+		return false
+	}
+
+	word := strings.ToLower(strings.TrimFunc(fields[0], func(r rune) bool { return !unicode.IsLetter(r) }))
+	if word == "" || !isASCIIWord(word) {
+		return true
+	}
+
+	if _, ok := imperativeExceptions[word]; ok {
+		return true
+	}
+	switch {
+	case strings.HasSuffix(word, "ed"), strings.HasSuffix(word, "ing"):
+		return false
+	case strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") &&
+		!strings.HasSuffix(word, "us") && !strings.HasSuffix(word, "is"):
+		return false
+	}
+	return true
+}
+
+// 以 ed/ing/s 结尾但本身就是祈使形式的常见动词
+var imperativeExceptions = map[string]struct{}{
+	"embed": {}, "feed": {}, "seed": {}, "speed": {}, "need": {}, "shred": {},
+	"bring": {}, "sing": {}, "ring": {}, "string": {},
+	"alias": {}, "canvas": {}, "bias": {},
+}
+
+func isASCIIWord(s string) bool {
+	for _, r := range s {
+		if r > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
+
+func containsType(types []string, typ string) bool {
+	for _, t := range types {
+		if strings.EqualFold(strings.TrimSpace(t), typ) {
+			return true
+		}
+	}
+	return false
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,57 @@
+package scorecmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testRules = Rules{Types: []string{"feat", "fix", "chore"}, MaxLength: 72}
+
+func TestScoreCommitPerfect(t *testing.T) {
+	res := ScoreCommit(Commit{Subject: "feat(tag): add remote listing", Body: "Explain why."}, testRules)
+	assert.Equal(t, 100, res.Score)
+	assert.Empty(t, res.Issues)
+}
+
+func TestScoreCommitIssues(t *testing.T) {
+	res := ScoreCommit(Commit{Subject: "Added some stuff"}, testRules)
+	assert.False(t, res.Conventional)
+	assert.False(t, res.Imperative)
+	assert.False(t, res.HasBody)
+	assert.Equal(t, weightLength, res.Score)
+	assert.Len(t, res.Issues, 3)
+}
+
+func TestScoreCommitDisallowedType(t *testing.T) {
+	res := ScoreCommit(Commit{Subject: "perf: speed up diff"}, testRules)
+	assert.False(t, res.Conventional)
+	assert.True(t, res.Imperative)
+}
+
+func TestIsImperative(t *testing.T) {
+	for word, want := range map[string]bool{
+		"add tests":        true,
+		"fixes bug":        false,
+		"adding tests":     false,
+		"embed assets":     true,
+		"process requests": true,
+		"修复 tag 排序":        true,
+		"":                 false,
+	} {
+		assert.Equal(t, want, IsImperative(word), word)
+	}
+}
+
+func TestSummarizeAndParseLog(t *testing.T) {
+	commits := parseLog("abc\x1ffeat: add x\x1fbody\n\x1e\ndef\x1fwip\x1f\x1e\n")
+	require.Len(t, commits, 2)
+	assert.Equal(t, "body", commits[0].Body)
+
+	sum := Summarize(commits, testRules)
+	assert.Equal(t, 2, sum.Commits)
+	assert.Equal(t, 50, sum.ConventionalRate)
+	assert.Equal(t, 50, sum.BodyRate)
+	assert.Equal(t, (100+weightLength+weightImperative)/2, sum.Average)
+}
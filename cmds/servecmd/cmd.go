@@ -0,0 +1,75 @@
+package servecmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/pubgo/dix/v2"
+	"github.com/pubgo/dix/v2/dixcontext"
+	"github.com/pubgo/funk/v2/errors"
+	"github.com/pubgo/redant"
+
+	"github.com/pubgo/fastgit/cmds/fastcommitcmd"
+	"github.com/pubgo/fastgit/configs"
+	"github.com/pubgo/fastgit/pkg/aiprovider"
+	"github.com/pubgo/fastgit/pkg/editorrpc"
+	"github.com/pubgo/fastgit/utils"
+)
+
+const defaultAddr = "127.0.0.1:7733"
+
+type cmdParams struct {
+	AI        aiprovider.Provider
+	CommitCfg []*fastcommitcmd.Config
+}
+
+func New() *redant.Command {
+	var flags = new(struct {
+		editor bool
+		addr   string
+		token  string
+	})
+
+	return &redant.Command{
+		Use:      "serve",
+		Short:    "启动本地服务，供 VS Code / Neovim 等编辑器插件复用 fastgit 的能力",
+		Metadata: utils.NoTTYMetadata(),
+		Options: redant.OptionSet{
+			{Flag: "editor", Description: "启动编辑器 JSON-RPC 接口（POST /rpc）", Value: redant.BoolOf(&flags.editor)},
+			{Flag: "addr", Description: "监听地址，仅允许回环地址；端口为 0 时随机分配", Value: redant.StringOf(&flags.addr), Default: defaultAddr},
+			{Flag: "token", Description: "要求请求携带 Authorization: Bearer <token>", Value: redant.StringOf(&flags.token), Envs: []string{"FASTGIT_SERVE_TOKEN"}},
+		},
+		Handler: func(ctx context.Context, inv *redant.Invocation) error {
+			if !flags.editor {
+				return errors.New("nothing to serve, use --editor")
+			}
+			if !editorrpc.IsLoopback(flags.addr) {
+				return errors.Errorf("editor API only listens on loopback addresses, got %q", flags.addr)
+			}
+
+			var params cmdParams
+			params = dix.Inject(dixcontext.Get(ctx), params)
+
+			srv := editorrpc.NewServer()
+			srv.Token = flags.token
+			repoRoot, err := configs.RepoPath()
+			if err != nil {
+				return err
+			}
+			registerMethods(srv, params, repoRoot)
+
+			ln, err := net.Listen("tcp", flags.addr)
+			if err != nil {
+				return err
+			}
+
+			// 第一行输出监听地址，插件以子进程启动时读取它即可连接（适用于 --addr 127.0.0.1:0）
+			addr := ln.Addr().String()
+			info, _ := json.Marshal(map[string]string{"addr": addr, "url": "http://" + addr + "/rpc"})
+			_, _ = fmt.Fprintln(inv.Stdout, string(info))
+			return srv.Serve(ctx, ln)
+		},
+	}
+}
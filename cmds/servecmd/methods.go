@@ -0,0 +1,151 @@
+package servecmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/pubgo/fastgit/cmds/checkcmd"
+	"github.com/pubgo/fastgit/cmds/fastcommitcmd"
+	"github.com/pubgo/fastgit/cmds/tagcmd"
+	"github.com/pubgo/fastgit/pkg/aiprovider"
+	"github.com/pubgo/fastgit/pkg/editorrpc"
+)
+
+type generateParams struct {
+	Diff       string `json:"diff"`
+	Candidates int    `json:"candidates"`
+	Locale     string `json:"locale"`
+	MaxLength  int    `json:"maxLength"`
+}
+
+type generateResult struct {
+	Message    string      `json:"message"`
+	Candidates []candidate `json:"candidates,omitempty"`
+	Provider   string      `json:"provider,omitempty"`
+	Fallback   bool        `json:"fallback"`
+}
+
+type candidate struct {
+	Style   string `json:"style"`
+	Message string `json:"message"`
+}
+
+type lintResult struct {
+	Valid bool   `json:"valid"`
+	Error string `json:"error,omitempty"`
+}
+
+type checkStep struct {
+	Name    string `json:"name"`
+	Command string `json:"command"`
+	Skipped bool   `json:"skipped"`
+	Reason  string `json:"reason,omitempty"`
+	OK      bool   `json:"ok"`
+	Error   string `json:"error,omitempty"`
+	Output  string `json:"output,omitempty"`
+}
+
+type checkResult struct {
+	OK      bool        `json:"ok"`
+	Summary string      `json:"summary,omitempty"`
+	Steps   []checkStep `json:"steps"`
+}
+
+// registerMethods 注册编辑器插件可调用的方法，repoRoot 为 serve 启动时所在仓库
+func registerMethods(srv *editorrpc.Server, params cmdParams, repoRoot string) {
+	srv.Handle("commit.generate", func(ctx context.Context, raw json.RawMessage) (any, error) {
+		var p generateParams
+		if err := editorrpc.DecodeParams(raw, &p); err != nil {
+			return nil, err
+		}
+		if p.Candidates > aiprovider.MaxCandidateCount {
+			p.Candidates = aiprovider.MaxCandidateCount
+		}
+
+		res, err := fastcommitcmd.GenerateMessage(ctx, params.AI, params.CommitCfg, repoRoot, fastcommitcmd.MessageRequest{
+			Diff:       p.Diff,
+			Candidates: p.Candidates,
+			Locale:     p.Locale,
+			MaxLength:  p.MaxLength,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		out := generateResult{Message: res.Message, Provider: res.Provider, Fallback: res.Fallback}
+		for _, c := range res.Candidates {
+			out.Candidates = append(out.Candidates, candidate{Style: c.Style, Message: c.Message})
+		}
+		return out, nil
+	})
+
+	srv.Handle("commit.lint", func(_ context.Context, raw json.RawMessage) (any, error) {
+		var p struct {
+			Message string `json:"message"`
+		}
+		if err := editorrpc.DecodeParams(raw, &p); err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(p.Message) == "" {
+			return nil, editorrpc.InvalidParams(errors.New("message is required"))
+		}
+
+		if err := fastcommitcmd.LintMessage(params.CommitCfg, repoRoot, p.Message); err != nil {
+			return lintResult{Error: err.Error()}, nil
+		}
+		return lintResult{Valid: true}, nil
+	})
+
+	srv.Handle("check.run", func(ctx context.Context, raw json.RawMessage) (any, error) {
+		var p struct {
+			StagedOnly bool `json:"stagedOnly"`
+			Fix        bool `json:"fix"`
+		}
+		if err := editorrpc.DecodeParams(raw, &p); err != nil {
+			return nil, err
+		}
+
+		results, runErr := checkcmd.Run(ctx, checkcmd.LoadConfig(repoRoot), checkcmd.RunOptions{
+			StagedOnly: p.StagedOnly,
+			Fix:        p.Fix,
+			RepoRoot:   repoRoot,
+		})
+		out := checkResult{OK: runErr == nil}
+		if runErr != nil {
+			out.Summary = checkcmd.FormatFailureSummary(results, runErr)
+		}
+		for _, r := range results {
+			step := checkStep{
+				Name:    r.Step.Name,
+				Command: r.Step.Command,
+				Skipped: r.Skipped,
+				Reason:  r.Reason,
+				OK:      r.Err == nil,
+				Output:  r.Output,
+			}
+			if r.Err != nil {
+				step.Error = r.Err.Error()
+			}
+			out.Steps = append(out.Steps, step)
+		}
+		return out, nil
+	})
+
+	srv.Handle("version.next", func(ctx context.Context, raw json.RawMessage) (any, error) {
+		var p struct {
+			Module string `json:"module"`
+			Pre    string `json:"pre"`
+		}
+		if err := editorrpc.DecodeParams(raw, &p); err != nil {
+			return nil, err
+		}
+
+		next, err := tagcmd.NextVersion(ctx, repoRoot, p.Module, p.Pre)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]string{"next": next}, nil
+	})
+}
@@ -0,0 +1,137 @@
+package sparsecmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pubgo/redant"
+
+	"github.com/pubgo/fastgit/configs"
+	"github.com/pubgo/fastgit/pkg/sparse"
+)
+
+func New() *redant.Command {
+	var (
+		filter string
+		paths  []string
+	)
+
+	return &redant.Command{
+		Use:   "sparse",
+		Short: "sparse-checkout（锥形模式）与部分克隆，只检出需要的目录",
+		Long: "大仓库中只检出关心的目录，并按需下载文件内容。示例：fastgit sparse init services/api docs --filter blob:none；" +
+			"fastgit sparse add web；fastgit sparse clone <url> --path services/api。",
+		Children: []*redant.Command{
+			{
+				Use:   "init [paths...]",
+				Short: "在当前仓库开启锥形 sparse-checkout，可选转为部分克隆",
+				Long:  "不指定目录时只保留根目录下的文件。--filter 会把 origin 设为 promisor 并按过滤器重新 fetch。",
+				Options: redant.OptionSet{
+					{Flag: "filter", Description: "部分克隆过滤器，如 blob:none、tree:0（默认不改变克隆方式）", Value: redant.StringOf(&filter)},
+				},
+				Handler: func(ctx context.Context, inv *redant.Invocation) error {
+					root, err := configs.RepoPath()
+					if err != nil {
+						return err
+					}
+					if err := sparse.Init(ctx, root, inv.Args, strings.TrimSpace(filter)); err != nil {
+						return err
+					}
+					printStatus(inv, sparse.Status(ctx, root))
+					return nil
+				},
+			},
+			{
+				Use:   "add <paths...>",
+				Short: "把目录加入 sparse-checkout 锥形范围",
+				Handler: func(ctx context.Context, inv *redant.Invocation) error {
+					if len(inv.Args) == 0 {
+						return redant.DefaultHelpFn()(ctx, inv)
+					}
+					root, err := configs.RepoPath()
+					if err != nil {
+						return err
+					}
+					if err := sparse.Add(ctx, root, inv.Args); err != nil {
+						return err
+					}
+					printStatus(inv, sparse.Status(ctx, root))
+					return nil
+				},
+			},
+			{
+				Use:   "list",
+				Short: "查看 sparse-checkout 目录与部分克隆过滤器",
+				Handler: func(ctx context.Context, inv *redant.Invocation) error {
+					root, err := configs.RepoPath()
+					if err != nil {
+						return err
+					}
+					printStatus(inv, sparse.Status(ctx, root))
+					return nil
+				},
+			},
+			{
+				Use:   "disable",
+				Short: "关闭 sparse-checkout，恢复完整检出（保留部分克隆过滤器）",
+				Handler: func(ctx context.Context, inv *redant.Invocation) error {
+					root, err := configs.RepoPath()
+					if err != nil {
+						return err
+					}
+					if err := sparse.Disable(ctx, root); err != nil {
+						return err
+					}
+					_, _ = fmt.Fprintln(inv.Stdout, "sparse-checkout disabled, full tree checked out")
+					return nil
+				},
+			},
+			{
+				Use:   "clone <url> [dir]",
+				Short: "部分克隆并只检出指定目录（git clone --filter --sparse）",
+				Options: redant.OptionSet{
+					{Flag: "filter", Description: "部分克隆过滤器", Value: redant.StringOf(&filter), Default: sparse.DefaultFilter},
+					{Flag: "path", Description: "要检出的目录，可重复指定", Value: redant.StringArrayOf(&paths)},
+				},
+				Handler: func(ctx context.Context, inv *redant.Invocation) error {
+					if len(inv.Args) == 0 || len(inv.Args) > 2 {
+						return redant.DefaultHelpFn()(ctx, inv)
+					}
+					var target string
+					if len(inv.Args) == 2 {
+						target = inv.Args[1]
+					}
+					dir, err := sparse.Clone(ctx, inv.Args[0], target, strings.TrimSpace(filter), paths)
+					if err != nil {
+						return err
+					}
+					_, _ = fmt.Fprintf(inv.Stdout, "cloned into %s\n", dir)
+					printStatus(inv, sparse.Status(ctx, dir))
+					return nil
+				},
+			},
+		},
+	}
+}
+
+func printStatus(inv *redant.Invocation, info sparse.Info) {
+	if !info.Sparse {
+		_, _ = fmt.Fprintln(inv.Stdout, "sparse-checkout: off")
+	} else {
+		mode := "pattern"
+		if info.Cone {
+			mode = "cone"
+		}
+		_, _ = fmt.Fprintf(inv.Stdout, "sparse-checkout: %s\n", mode)
+		if len(info.Paths) == 0 {
+			_, _ = fmt.Fprintln(inv.Stdout, "  (top-level files only)")
+		}
+		for _, p := range info.Paths {
+			_, _ = fmt.Fprintf(inv.Stdout, "  %s\n", p)
+		}
+	}
+	if info.Filter != "" {
+		_, _ = fmt.Fprintf(inv.Stdout, "partial clone filter: %s (missing objects are fetched on demand)\n", info.Filter)
+	}
+}
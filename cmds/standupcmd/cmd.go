@@ -0,0 +1,126 @@
+package standupcmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pubgo/funk/v2/log"
+	"github.com/pubgo/redant"
+
+	"github.com/pubgo/fastgit/pkg/aiprovider"
+	"github.com/pubgo/fastgit/pkg/gitshell"
+	"github.com/pubgo/fastgit/pkg/timing"
+	"github.com/pubgo/fastgit/utils"
+)
+
+const polishSystemPrompt = `You turn a developer's git commits into a short daily standup update.
+
+Write markdown with these sections:
+## Done
+3-7 bullets grouping related commits by theme, in plain language a teammate understands; mention the repository when there are several.
+## Next
+1-3 bullets only if the commits clearly imply follow-up work, otherwise write "-".
+
+Do not invent work that is not in the commits. Keep it under 150 words.`
+
+// New creates the standup command.
+func New() *redant.Command {
+	var (
+		since      string
+		until      string
+		author     string
+		workspace  string
+		polish     bool
+		aiProvider string
+		jsonOut    bool
+	)
+
+	return &redant.Command{
+		Use:   "standup",
+		Short: "汇总自己近期的提交，生成站会日报",
+		Long: "按时间范围汇总当前仓库（或 --workspace 下所有仓库）中自己的提交，输出 markdown；" +
+			"--ai 时交给 AI 润色成 Done / Next。--since 默认 yesterday，周一自动回到上周五。",
+		Metadata: utils.NoTTYMetadata(),
+		Options: redant.OptionSet{
+			{Flag: "since", Description: "起始时间，git log --since 语法（yesterday、2 days ago、2026-01-02）", Value: redant.StringOf(&since), Default: "yesterday"},
+			{Flag: "until", Description: "截止时间，git log --until 语法", Value: redant.StringOf(&until)},
+			{Flag: "author", Description: "作者，me 表示各仓库的 git config user.email；留空则不过滤", Value: redant.StringOf(&author), Default: "me"},
+			{Flag: "workspace", Description: "汇总该目录下一级子目录中的所有 git 仓库", Value: redant.StringOf(&workspace)},
+			{Flag: "ai", Description: "用 AI 润色为人读的日报", Value: redant.BoolOf(&polish)},
+			{Flag: "ai-provider", Description: "AI 提供方 auto|openai|gemini|anthropic|ollama|copilot", Value: redant.StringOf(&aiProvider), Default: "auto"},
+			{Flag: "json", Description: "以 JSON 输出原始提交", Value: redant.BoolOf(&jsonOut)},
+		},
+		Handler: func(ctx context.Context, inv *redant.Invocation) error {
+			dir := strings.TrimSpace(workspace)
+			if dir == "" {
+				wd, err := os.Getwd()
+				if err != nil {
+					return err
+				}
+				root, err := gitshell.Output(ctx, wd, "rev-parse", "--show-toplevel")
+				if err != nil {
+					return fmt.Errorf("not in a git repository, use --workspace <dir>: %w", err)
+				}
+				dir = strings.TrimSpace(root)
+			}
+			repos, err := gitshell.FindRepos(dir)
+			if err != nil {
+				return err
+			}
+			if len(repos) == 0 {
+				return fmt.Errorf("no git repositories found in %s", dir)
+			}
+
+			sinceArg := resolveSince(since, time.Now())
+			done := timing.Track(ctx, timing.PhaseGit, "collect commits")
+			logs := make([]RepoLog, 0, len(repos))
+			for _, repo := range repos {
+				rl, err := loadRepoLog(ctx, repo, sinceArg, until, strings.TrimSpace(author))
+				if err != nil {
+					log.Warn().Err(err).Str("repo", repo).Msg("skip repository")
+					continue
+				}
+				logs = append(logs, rl)
+			}
+			done()
+
+			if jsonOut {
+				enc := json.NewEncoder(inv.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(logs)
+			}
+
+			var report bytes.Buffer
+			render(&report, logs, sinceArg)
+			if !polish || !hasCommits(logs) {
+				_, err := inv.Stdout.Write(report.Bytes())
+				return err
+			}
+
+			provider := aiprovider.ResolveProvider(aiProvider, dir)
+			text, ok, err := aiprovider.EnhanceText(ctx, provider, polishSystemPrompt, report.String(), report.String())
+			if err != nil {
+				log.Warn().Err(err).Msg("AI polish failed, printing the plain report")
+			}
+			if !ok {
+				text = report.String()
+			}
+			_, err = fmt.Fprintln(inv.Stdout, strings.TrimSpace(text))
+			return err
+		},
+	}
+}
+
+func hasCommits(logs []RepoLog) bool {
+	for _, l := range logs {
+		if len(l.Commits) > 0 {
+			return true
+		}
+	}
+	return false
+}
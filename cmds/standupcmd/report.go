@@ -0,0 +1,129 @@
+package standupcmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pubgo/fastgit/pkg/gitshell"
+)
+
+const (
+	fieldSep  = "\x1f"
+	recordSep = "\x1e"
+)
+
+// Commit 一条待汇报的提交
+type Commit struct {
+	Hash    string    `json:"hash"`
+	Subject string    `json:"subject"`
+	Body    string    `json:"body,omitempty"`
+	Date    time.Time `json:"date"`
+}
+
+// RepoLog 单个仓库在时间范围内的提交
+type RepoLog struct {
+	Repo    string   `json:"repo"`
+	Path    string   `json:"path"`
+	Commits []Commit `json:"commits"`
+}
+
+// resolveSince 把 "yesterday" 解析为上一个工作日零点，周一时回到上周五；其它取值原样交给 git
+func resolveSince(since string, now time.Time) string {
+	if strings.TrimSpace(since) != "yesterday" {
+		return since
+	}
+	day := now.AddDate(0, 0, -1)
+	for day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+		day = day.AddDate(0, 0, -1)
+	}
+	return day.Format(time.DateOnly) + " 00:00"
+}
+
+// loadRepoLog 读取仓库所有分支上 author 在时间范围内的非合并提交；author 为 "me" 时取该仓库的 user.email
+func loadRepoLog(ctx context.Context, dir, since, until, author string) (RepoLog, error) {
+	rl := RepoLog{Repo: filepath.Base(dir), Path: dir}
+	if author == "me" {
+		out, err := gitshell.Output(ctx, dir, "config", "user.email")
+		if err != nil || strings.TrimSpace(out) == "" {
+			return rl, fmt.Errorf("%s: git config user.email is empty, pass --author", rl.Repo)
+		}
+		author = strings.TrimSpace(out)
+	}
+
+	args := []string{"log", "--all", "--no-merges", "--date=iso-strict",
+		"--format=%h" + fieldSep + "%ad" + fieldSep + "%s" + fieldSep + "%b" + recordSep}
+	if since != "" {
+		args = append(args, "--since="+since)
+	}
+	if until != "" {
+		args = append(args, "--until="+until)
+	}
+	if author != "" {
+		args = append(args, "--author="+author)
+	}
+	out, err := gitshell.Output(ctx, dir, args...)
+	if err != nil {
+		// 空仓库没有任何提交，不算错误
+		if strings.Contains(err.Error(), "does not have any commits") {
+			return rl, nil
+		}
+		return rl, fmt.Errorf("%s: %w", rl.Repo, err)
+	}
+	rl.Commits = parseLog(out)
+	return rl, nil
+}
+
+func parseLog(output string) []Commit {
+	var commits []Commit
+	for _, record := range strings.Split(output, recordSep) {
+		record = strings.TrimSpace(record)
+		if record == "" {
+			continue
+		}
+		parts := strings.SplitN(record, fieldSep, 4)
+		if len(parts) < 3 {
+			continue
+		}
+		c := Commit{Hash: parts[0], Subject: strings.TrimSpace(parts[2])}
+		c.Date, _ = time.Parse(time.RFC3339, strings.TrimSpace(parts[1]))
+		if len(parts) == 4 {
+			c.Body = strings.TrimSpace(parts[3])
+		}
+		commits = append(commits, c)
+	}
+	// 汇报按时间正序阅读更自然
+	sort.SliceStable(commits, func(i, j int) bool { return commits[i].Date.Before(commits[j].Date) })
+	return commits
+}
+
+// render 输出 markdown 报告：按仓库分组，单仓库时省略仓库标题
+func render(w io.Writer, logs []RepoLog, since string) {
+	total := 0
+	for _, l := range logs {
+		total += len(l.Commits)
+	}
+	_, _ = fmt.Fprintf(w, "# Standup (since %s)\n\n", since)
+	if total == 0 {
+		_, _ = fmt.Fprintln(w, "No commits in this period.")
+		return
+	}
+
+	for _, l := range logs {
+		if len(l.Commits) == 0 {
+			continue
+		}
+		if len(logs) > 1 {
+			_, _ = fmt.Fprintf(w, "## %s\n\n", l.Repo)
+		}
+		for _, c := range l.Commits {
+			_, _ = fmt.Fprintf(w, "- %s (%s)\n", c.Subject, c.Hash)
+		}
+		_, _ = fmt.Fprintln(w)
+	}
+	_, _ = fmt.Fprintf(w, "%d commits\n", total)
+}
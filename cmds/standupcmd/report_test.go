@@ -0,0 +1,79 @@
+package standupcmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pubgo/fastgit/pkg/gitshell"
+)
+
+func TestResolveSince(t *testing.T) {
+	monday := time.Date(2026, 10, 12, 9, 0, 0, 0, time.Local)
+	assert.Equal(t, "2026-10-09 00:00", resolveSince("yesterday", monday))
+	assert.Equal(t, "2026-10-12 00:00", resolveSince("yesterday", monday.AddDate(0, 0, 1)))
+	assert.Equal(t, "2 days ago", resolveSince("2 days ago", monday))
+}
+
+func TestParseLogSortsByDate(t *testing.T) {
+	out := "b2" + fieldSep + "2026-10-12T10:00:00+00:00" + fieldSep + "fix: later" + fieldSep + recordSep + "\n" +
+		"a1" + fieldSep + "2026-10-12T09:00:00+00:00" + fieldSep + "feat: earlier" + fieldSep + "body" + recordSep + "\n"
+	commits := parseLog(out)
+	require.Len(t, commits, 2)
+	assert.Equal(t, "feat: earlier", commits[0].Subject)
+	assert.Equal(t, "body", commits[0].Body)
+	assert.Equal(t, "b2", commits[1].Hash)
+}
+
+func TestWorkspaceReport(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	ws := t.TempDir()
+	for _, name := range []string{"api", "web"} {
+		dir := filepath.Join(ws, name)
+		require.NoError(t, os.MkdirAll(dir, 0o755))
+		git(t, dir, "init", "-q")
+		git(t, dir, "config", "user.email", "me@example.com")
+		git(t, dir, "config", "user.name", "me")
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte(name), 0o644))
+		git(t, dir, "add", ".")
+		git(t, dir, "commit", "-qm", "feat: init "+name)
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(ws, "not-a-repo"), 0o755))
+
+	repos, err := gitshell.FindRepos(ws)
+	require.NoError(t, err)
+	require.Len(t, repos, 2)
+
+	var logs []RepoLog
+	for _, repo := range repos {
+		rl, err := loadRepoLog(context.Background(), repo, "1 hour ago", "", "me")
+		require.NoError(t, err)
+		logs = append(logs, rl)
+	}
+	var buf bytes.Buffer
+	render(&buf, logs, "1 hour ago")
+	assert.Contains(t, buf.String(), "## api")
+	assert.Contains(t, buf.String(), "- feat: init web (")
+	assert.Contains(t, buf.String(), "2 commits")
+
+	rl, err := loadRepoLog(context.Background(), repos[0], "1 hour ago", "", "someone-else")
+	require.NoError(t, err)
+	assert.Empty(t, rl.Commits)
+}
+
+func git(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+}
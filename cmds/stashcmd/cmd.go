@@ -0,0 +1,105 @@
+package stashcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/pubgo/redant"
+
+	"github.com/pubgo/fastgit/configs"
+	"github.com/pubgo/fastgit/utils"
+	"github.com/pubgo/fastgit/utils/fzfutil"
+)
+
+// previewCommand 预览选中 stash 的改动
+const previewCommand = "git stash show -p --include-untracked --color=always {1}"
+
+func New() *redant.Command {
+	return &redant.Command{
+		Use:   "stash",
+		Short: "stash 辅助命令",
+		Children: []*redant.Command{
+			newBranchCommand(),
+		},
+	}
+}
+
+func newBranchCommand() *redant.Command {
+	var (
+		name  string
+		stash string
+	)
+
+	return &redant.Command{
+		Use:   "branch <name> [stash]",
+		Short: "在 stash 所基于的提交上新建分支并恢复 stash，成功后删除该 stash：一步救回 stash 在错误分支上的改动",
+		Long: "stash 缺省为 stash@{0}；终端中有多个 stash 且未指定时用 fzf 选择。工作区需干净；" +
+			"stash 恢复冲突时分支已创建并检出，stash 保留，解决冲突后 git stash drop。示例：fastgit stash branch feat/login stash@{1}",
+		Metadata: utils.NoTTYMetadata(),
+		Args: redant.ArgSet{
+			{Name: "name", Description: "新分支名", Value: redant.StringOf(&name)},
+			{Name: "stash", Description: "stash 条目，如 stash@{1} 或 1", Value: redant.StringOf(&stash)},
+		},
+		Handler: func(ctx context.Context, inv *redant.Invocation) error {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				return redant.DefaultHelpFn()(ctx, inv)
+			}
+			repoRoot, err := configs.RepoPath()
+			if err != nil {
+				return err
+			}
+
+			ref, err := resolveStash(ctx, repoRoot, strings.TrimSpace(stash))
+			if err != nil {
+				return err
+			}
+			res, err := Branch(ctx, repoRoot, name, ref)
+			if err != nil {
+				return err
+			}
+			if !res.Applied {
+				return fmt.Errorf("created branch %s at %s, but %s does not apply cleanly: resolve the conflicts, then run `git stash drop %s`",
+					res.Branch, res.Base, res.Stash, res.Stash)
+			}
+			_, _ = fmt.Fprintf(inv.Stdout, "switched to new branch %s at %s\napplied and dropped %s\n", res.Branch, res.Base, res.Stash)
+			return nil
+		},
+	}
+}
+
+// resolveStash 返回要使用的 stash：纯数字补全为 stash@{n}；未指定时取 stash@{0}，终端中有多个 stash 时用 fzf 选择
+func resolveStash(ctx context.Context, repoRoot, stash string) (string, error) {
+	if stash != "" {
+		if strings.Trim(stash, "0123456789") == "" {
+			return "stash@{" + stash + "}", nil
+		}
+		return stash, nil
+	}
+
+	stashes, err := ListStashes(ctx, repoRoot)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case len(stashes) == 0:
+		return "", errors.New("no stash entries")
+	case len(stashes) == 1 || !term.IsTerminal(os.Stdin.Fd()) || utils.NonInteractive():
+		return stashes[0].Ref, nil
+	}
+
+	var lines strings.Builder
+	for _, s := range stashes {
+		lines.WriteString(s.String() + "\n")
+	}
+	line, err := fzfutil.PickPreview(ctx, strings.NewReader(lines.String()), "stash: ", "", previewCommand)
+	if err != nil {
+		return "", err
+	}
+	ref, _, _ := strings.Cut(line, "\t")
+	return ref, nil
+}
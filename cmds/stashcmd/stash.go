@@ -0,0 +1,89 @@
+package stashcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pubgo/fastgit/pkg/gitshell"
+)
+
+// Stash is one entry of `git stash list`.
+type Stash struct {
+	// Ref is the reflog selector, e.g. stash@{0}.
+	Ref     string
+	Date    string
+	Message string
+}
+
+func (s Stash) String() string {
+	return fmt.Sprintf("%s\t%s\t%s", s.Ref, s.Date, s.Message)
+}
+
+const stashFormat = "%gd%x09%cr%x09%gs"
+
+// ListStashes returns the stash entries of the repository at dir, newest first.
+func ListStashes(ctx context.Context, dir string) ([]Stash, error) {
+	out, err := gitshell.Run(ctx, dir, "stash", "list", "--format="+stashFormat)
+	if err != nil {
+		return nil, err
+	}
+	var stashes []Stash
+	for _, line := range strings.Split(out, "\n") {
+		parts := strings.SplitN(line, "\t", 3)
+		if len(parts) < 3 {
+			continue
+		}
+		stashes = append(stashes, Stash{Ref: parts[0], Date: parts[1], Message: parts[2]})
+	}
+	return stashes, nil
+}
+
+// BranchResult describes a branch created from a stash.
+type BranchResult struct {
+	Branch string
+	Stash  string
+	// Base is the short hash and subject of the commit the stash was made on.
+	Base string
+	// Applied is false when the stash did not apply cleanly: the branch exists and
+	// is checked out, the conflicts are in the work tree and the stash is kept.
+	Applied bool
+}
+
+// Branch creates branch name at the commit the stash was made on, checks it out,
+// applies the stash (restoring the index too) and drops it on success, like
+// `git stash branch`. The work tree must be clean so nothing else is carried over.
+func Branch(ctx context.Context, dir, name, stash string) (BranchResult, error) {
+	res := BranchResult{Branch: name, Stash: stash}
+	if _, err := gitshell.Run(ctx, dir, "check-ref-format", "--branch", name); err != nil {
+		return res, fmt.Errorf("invalid branch name %q", name)
+	}
+	if _, err := gitshell.Run(ctx, dir, "rev-parse", "--verify", "--quiet", "refs/heads/"+name); err == nil {
+		return res, fmt.Errorf("branch %s already exists", name)
+	}
+	if _, err := gitshell.Run(ctx, dir, "rev-parse", "--verify", "--quiet", stash+"^{commit}"); err != nil {
+		return res, fmt.Errorf("%s is not a stash entry", stash)
+	}
+	status, err := gitshell.Run(ctx, dir, "status", "--porcelain")
+	if err != nil {
+		return res, err
+	}
+	if status != "" {
+		return res, errors.New("working tree has uncommitted changes, commit or stash them first")
+	}
+	if res.Base, err = gitshell.Run(ctx, dir, "log", "-1", "--format=%h %s", stash+"^1"); err != nil {
+		return res, err
+	}
+
+	if _, err := gitshell.Run(ctx, dir, "stash", "branch", name, stash); err != nil {
+		// git 先建分支再 apply；分支已检出说明只是 apply 冲突，stash 保留
+		current, _ := gitshell.Run(ctx, dir, "symbolic-ref", "--quiet", "--short", "HEAD")
+		if current != name {
+			return res, err
+		}
+		return res, nil
+	}
+	res.Applied = true
+	return res, nil
+}
@@ -0,0 +1,65 @@
+package stashcmd
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBranchFromStash(t *testing.T) {
+	t.Setenv("FASTGIT_EXEC_LOG", "0")
+	dir := t.TempDir()
+	run := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+		return strings.TrimSpace(string(out))
+	}
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	run("init", "-q", "-b", "main")
+	run("config", "user.email", "t@example.com")
+	run("config", "user.name", "t")
+	write("a.txt", "a\n")
+	run("add", ".")
+	run("commit", "-qm", "init")
+	base := run("rev-parse", "HEAD")
+
+	write("a.txt", "a\nwip\n")
+	write("new.txt", "staged\n")
+	run("add", "new.txt")
+	run("stash", "push", "-m", "wip on wrong branch")
+	write("a.txt", "a\nmain moved on\n")
+	run("commit", "-qam", "main change")
+
+	ctx := context.Background()
+	stashes, err := ListStashes(ctx, dir)
+	require.NoError(t, err)
+	require.Len(t, stashes, 1)
+	require.Equal(t, "stash@{0}", stashes[0].Ref)
+	require.Equal(t, "On main: wip on wrong branch", stashes[0].Message)
+
+	_, err = Branch(ctx, dir, "main", "stash@{0}")
+	require.ErrorContains(t, err, "already exists")
+	write("dirty.txt", "x\n")
+	_, err = Branch(ctx, dir, "feat/rescue", "stash@{0}")
+	require.ErrorContains(t, err, "uncommitted changes")
+	require.NoError(t, os.Remove(filepath.Join(dir, "dirty.txt")))
+
+	res, err := Branch(ctx, dir, "feat/rescue", "stash@{0}")
+	require.NoError(t, err)
+	require.True(t, res.Applied)
+	require.True(t, strings.HasSuffix(res.Base, " init"), res.Base)
+	require.Equal(t, "feat/rescue", run("symbolic-ref", "--short", "HEAD"))
+	require.Equal(t, base, run("rev-parse", "HEAD"))
+	require.Equal(t, "new.txt", run("diff", "--cached", "--name-only"))
+	require.Equal(t, "a.txt", run("diff", "--name-only"))
+	require.Empty(t, run("stash", "list"))
+}
@@ -0,0 +1,222 @@
+package tagcmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	semver "github.com/hashicorp/go-version"
+	"github.com/pubgo/funk/v2/errors"
+	"github.com/pubgo/redant"
+
+	"github.com/pubgo/fastgit/cmds/chglogcmd"
+	"github.com/pubgo/fastgit/configs"
+	"github.com/pubgo/fastgit/pkg/versionfile"
+	"github.com/pubgo/fastgit/utils"
+)
+
+// driftIssue 版本文件、tag 与 changelog 之间的一处不一致；Fixable 表示 --fix 可以通过改写版本文件修复
+type driftIssue struct {
+	Message string
+	Fixable bool
+}
+
+type driftReport struct {
+	Version string
+	Latest  string
+	Issues  []driftIssue
+	// FixVersion 为 --fix 时写入所有版本文件的版本，空表示无需改写
+	FixVersion string
+}
+
+func newCheckCommand() *redant.Command {
+	var (
+		fix    bool
+		module string
+	)
+
+	return &redant.Command{
+		Use:      "check",
+		Short:    "检查版本文件、tag 与 changelog 是否一致，--fix 对齐版本文件",
+		Metadata: utils.NoTTYMetadata(),
+		Options: redant.OptionSet{
+			{Flag: "fix", Description: "把版本文件改写为一致的版本（不会创建 tag 或 changelog）", Value: redant.BoolOf(&fix)},
+			{Flag: "module", Description: "检查 .fastgit/modules.yaml 中的模块", Value: redant.StringOf(&module)},
+		},
+		Handler: func(ctx context.Context, inv *redant.Invocation) error {
+			repoRoot, err := configs.RepoPath()
+			if err != nil {
+				return err
+			}
+			target, err := resolveTagTarget(repoRoot, module)
+			if err != nil {
+				return err
+			}
+
+			cfg := versionfile.MustLoadConfig(repoRoot)
+			if strings.TrimSpace(module) != "" {
+				cfg = versionfile.Config{Files: []versionfile.File{target.VersionFile}}
+			}
+
+			changelogDir := target.ChangelogDir
+			if changelogDir != "" && !filepath.IsAbs(changelogDir) {
+				changelogDir = filepath.Join(repoRoot, changelogDir)
+			}
+			var released []string
+			if _, err := os.Stat(changelogDir); err == nil {
+				if released, err = chglogcmd.ListReleaseVersions(changelogDir); err != nil {
+					return err
+				}
+			}
+
+			tags, err := utils.GetPrefixedGitTags(ctx, target.Prefix)
+			if err != nil {
+				return err
+			}
+			report := checkDrift(cfg.Show(), tags, released, target.Prefix)
+			printDrift(inv.Stderr, repoRoot, cfg, report)
+			if len(report.Issues) == 0 {
+				return nil
+			}
+
+			if fix && report.FixVersion != "" {
+				updated, err := cfg.Write(report.FixVersion)
+				for _, p := range updated {
+					_, _ = fmt.Fprintf(inv.Stderr, "  fixed %s -> %s\n", relPath(repoRoot, p), report.FixVersion)
+				}
+				if err != nil {
+					return err
+				}
+				report = checkDrift(cfg.Show(), tags, released, target.Prefix)
+				if len(report.Issues) == 0 {
+					return nil
+				}
+			}
+
+			hint := ""
+			if !fix && report.FixVersion != "" {
+				hint = ", run `fastgit tag check --fix` to align version files"
+			}
+			return errors.Errorf("%d version drift issue(s) found%s", len(report.Issues), hint)
+		},
+	}
+}
+
+// checkDrift 比较主版本文件与其它版本文件、最新 tag、changelog 中已落版的版本
+//
+// 约定：tag 发布后主版本文件应等于该 tag 或已进入下一个开发版本；changelog 中比最新 tag 新的版本
+// 只能是当前版本（已落版待打 tag）
+func checkDrift(values []versionfile.Value, tags []*semver.Version, released []string, prefix string) driftReport {
+	var report driftReport
+	if len(values) == 0 {
+		return report
+	}
+
+	var latest *semver.Version
+	for _, tag := range tags {
+		if tag.Prerelease() != "" {
+			continue
+		}
+		if latest == nil || tag.GreaterThan(latest) {
+			latest = tag
+		}
+	}
+	if latest != nil {
+		report.Latest = prefix + latest.Original()
+	}
+
+	primary := values[0]
+	current, err := semver.NewVersion(primary.Version)
+	switch {
+	case primary.Err != nil:
+		report.Issues = append(report.Issues, driftIssue{Message: fmt.Sprintf("cannot read %s: %v", primary.File.Path, primary.Err), Fixable: latest != nil})
+		current = nil
+	case err != nil:
+		report.Issues = append(report.Issues, driftIssue{Message: fmt.Sprintf("%s is not a valid semver: %q", primary.File.Path, primary.Version), Fixable: latest != nil})
+		current = nil
+	default:
+		report.Version = primary.Version
+	}
+
+	for _, v := range values[1:] {
+		if v.Err != nil || strings.TrimPrefix(v.Version, "v") != strings.TrimPrefix(primary.Version, "v") {
+			got := v.Version
+			if v.Err != nil {
+				got = v.Err.Error()
+			}
+			report.Issues = append(report.Issues, driftIssue{Message: fmt.Sprintf("%s (%s) differs from %s", v.File.Path, got, primary.File.Path), Fixable: current != nil})
+			if current != nil {
+				report.FixVersion = primary.Version
+			}
+		}
+	}
+
+	if latest != nil && (current == nil || current.Core().LessThan(latest.Core())) {
+		next, _ := versionfile.Bump(latest.Core().Original(), "patch")
+		if current != nil {
+			report.Issues = append(report.Issues, driftIssue{Message: fmt.Sprintf("version %s is behind latest tag %s", primary.Version, report.Latest), Fixable: true})
+		}
+		report.FixVersion = next
+	}
+
+	for _, r := range released {
+		rv, err := semver.NewVersion(r)
+		if err != nil {
+			continue
+		}
+		if latest != nil && !rv.GreaterThan(latest) {
+			break
+		}
+		if current != nil && rv.Equal(current) {
+			continue
+		}
+		if current != nil && rv.GreaterThan(current) {
+			report.Issues = append(report.Issues, driftIssue{Message: fmt.Sprintf("changelog %s.md is ahead of version %s", r, primary.Version)})
+			continue
+		}
+		report.Issues = append(report.Issues, driftIssue{Message: fmt.Sprintf("changelog %s.md was released but %s%s is not tagged", r, prefix, r)})
+	}
+
+	if latest != nil && len(released) > 0 && !containsVersion(released, latest) {
+		report.Issues = append(report.Issues, driftIssue{Message: fmt.Sprintf("latest tag %s has no changelog entry, run `fastgit changelog release`", report.Latest)})
+	}
+	return report
+}
+
+func containsVersion(versions []string, want *semver.Version) bool {
+	for _, v := range versions {
+		if sv, err := semver.NewVersion(v); err == nil && sv.Equal(want) {
+			return true
+		}
+	}
+	return false
+}
+
+func printDrift(w io.Writer, repoRoot string, cfg versionfile.Config, report driftReport) {
+	latest := report.Latest
+	if latest == "" {
+		latest = "(none)"
+	}
+	_, _ = fmt.Fprintf(w, "version: %s  latest tag: %s  (%s)\n", report.Version, latest, relPath(repoRoot, cfg.Primary().Path))
+	if len(report.Issues) == 0 {
+		_, _ = fmt.Fprintln(w, "  ✓ version files, tags and changelog are consistent")
+		return
+	}
+	for _, is := range report.Issues {
+		mark := "✗"
+		if is.Fixable {
+			mark = "✗ (fixable)"
+		}
+		_, _ = fmt.Fprintf(w, "  %s %s\n", mark, strings.ReplaceAll(is.Message, repoRoot+string(filepath.Separator), ""))
+	}
+}
+
+func relPath(repoRoot, path string) string {
+	if r, err := filepath.Rel(repoRoot, path); err == nil {
+		return r
+	}
+	return path
+}
@@ -0,0 +1,60 @@
+package tagcmd
+
+import (
+	"errors"
+	"testing"
+
+	semver "github.com/hashicorp/go-version"
+	"github.com/pubgo/fastgit/pkg/versionfile"
+	"github.com/stretchr/testify/require"
+)
+
+func tagVersions(tags ...string) []*semver.Version {
+	var out []*semver.Version
+	for _, t := range tags {
+		out = append(out, semver.Must(semver.NewVersion(t)))
+	}
+	return out
+}
+
+func TestCheckDriftConsistent(t *testing.T) {
+	values := []versionfile.Value{{File: versionfile.File{Path: "VERSION"}, Version: "v1.3.0"}}
+	report := checkDrift(values, tagVersions("v1.2.0", "v1.1.0"), []string{"v1.2.0", "v1.1.0"}, "")
+	require.Empty(t, report.Issues)
+	require.Equal(t, "v1.2.0", report.Latest)
+	require.Empty(t, report.FixVersion)
+}
+
+func TestCheckDriftVersionBehindTag(t *testing.T) {
+	values := []versionfile.Value{
+		{File: versionfile.File{Path: "VERSION"}, Version: "v1.1.0"},
+		{File: versionfile.File{Path: "package.json"}, Version: "1.1.0"},
+	}
+	report := checkDrift(values, tagVersions("v1.2.0", "v1.3.0-rc.1"), []string{"v1.2.0"}, "")
+	require.Len(t, report.Issues, 1)
+	require.True(t, report.Issues[0].Fixable)
+	require.Equal(t, "v1.2.1", report.FixVersion)
+}
+
+func TestCheckDriftFilesDiffer(t *testing.T) {
+	values := []versionfile.Value{
+		{File: versionfile.File{Path: "VERSION"}, Version: "v1.2.0"},
+		{File: versionfile.File{Path: "Chart.yaml"}, Err: errors.New("key not found")},
+	}
+	report := checkDrift(values, nil, nil, "")
+	require.Len(t, report.Issues, 1)
+	require.Equal(t, "v1.2.0", report.FixVersion)
+}
+
+func TestCheckDriftChangelog(t *testing.T) {
+	values := []versionfile.Value{{File: versionfile.File{Path: "VERSION"}, Version: "v1.4.0"}}
+	report := checkDrift(values, tagVersions("v1.2.0"), []string{"v1.5.0", "v1.4.0", "v1.3.0", "v1.1.0"}, "api/")
+	require.Len(t, report.Issues, 3)
+	require.Contains(t, report.Issues[0].Message, "v1.5.0.md is ahead")
+	require.Contains(t, report.Issues[1].Message, "api/v1.3.0 is not tagged")
+	require.Contains(t, report.Issues[2].Message, "latest tag api/v1.2.0 has no changelog entry")
+	for _, is := range report.Issues {
+		require.False(t, is.Fixable)
+	}
+	require.Empty(t, report.FixVersion)
+}
@@ -5,16 +5,18 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
-	"sort"
+	"path/filepath"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	semver "github.com/hashicorp/go-version"
 	"github.com/pubgo/dix/v2"
 	"github.com/pubgo/dix/v2/dixcontext"
+	"github.com/pubgo/fastgit/cmds/conflictcmd"
 	"github.com/pubgo/fastgit/cmds/fastcommitcmd"
 	"github.com/pubgo/funk/v2/assert"
 	"github.com/pubgo/funk/v2/errors"
+	"github.com/pubgo/funk/v2/log"
 	"github.com/pubgo/funk/v2/pathutil"
 	"github.com/pubgo/funk/v2/recovery"
 	"github.com/pubgo/funk/v2/result"
@@ -22,6 +24,11 @@ import (
 	"github.com/samber/lo"
 	"github.com/yarlson/tap"
 
+	"github.com/pubgo/fastgit/configs"
+	"github.com/pubgo/fastgit/pkg/notify"
+	"github.com/pubgo/fastgit/pkg/repoconfig"
+	"github.com/pubgo/fastgit/pkg/tagcheck"
+	"github.com/pubgo/fastgit/pkg/versionfile"
 	"github.com/pubgo/fastgit/utils"
 	"github.com/pubgo/fastgit/utils/fzfutil"
 )
@@ -29,11 +36,17 @@ import (
 type cmdParams struct {
 	OpenaiClient *utils.OpenaiClient
 	CommitCfg    []*fastcommitcmd.Config
+	NotifyCfg    []*notify.Config
+	TagCfg       []*tagcheck.Config
 }
 
 func New() *redant.Command {
 	var flags = new(struct {
 		fastCommit bool
+		skipNotify bool
+		module     string
+		sign       bool
+		skipChecks bool
 	})
 
 	return &redant.Command{
@@ -44,10 +57,11 @@ func New() *redant.Command {
 				Use:   "list",
 				Short: "list all tags",
 				Handler: func(ctx context.Context, command *redant.Invocation) error {
-					utils.Spin("fetch git tag: ", func() (r result.Result[any]) {
-						utils.GitFetchAll(ctx)
-						return
-					})
+					if err := utils.Spin("fetch git tag: ", func() (r result.Result[any]) {
+						return r.WithErr(utils.GitFetchAll(ctx))
+					}).GetErr(); err != nil {
+						return err
+					}
 
 					var tagText = strings.TrimSpace(utils.ShellExecOutput(ctx, "git", "tag", "-n", "--sort=-committerdate").Unwrap())
 					tag, err := fzfutil.SelectWithFzf(ctx, strings.NewReader(tagText))
@@ -59,6 +73,8 @@ func New() *redant.Command {
 					return nil
 				},
 			},
+			newCheckCommand(),
+			newShowCommand(),
 		},
 		Options: []redant.Option{
 			{
@@ -66,6 +82,26 @@ func New() *redant.Command {
 				Description: "Quickly generate tag.",
 				Value:       redant.BoolOf(&flags.fastCommit),
 			},
+			{
+				Flag:        "skip-notify",
+				Description: "Do not send release notifications configured in notify.",
+				Value:       redant.BoolOf(&flags.skipNotify),
+			},
+			{
+				Flag:        "module",
+				Description: "Tag a monorepo module from .fastgit/modules.yaml using its tag prefix.",
+				Value:       redant.StringOf(&flags.module),
+			},
+			{
+				Flag:        "sign",
+				Description: "Create a signed annotated tag (git tag -s); defaults to commit.sign or tag.gpgsign.",
+				Value:       redant.BoolOf(&flags.sign),
+			},
+			{
+				Flag:        "skip-checks",
+				Description: "Create the tag without running the tag.checks commands.",
+				Value:       redant.BoolOf(&flags.skipChecks),
+			},
 		},
 		Handler: func(ctx context.Context, i *redant.Invocation) error {
 			defer recovery.Exit()
@@ -74,16 +110,31 @@ func New() *redant.Command {
 			var params cmdParams
 			params = dix.Inject(di, params)
 
+			repoRoot, err := configs.RepoPath()
+			if err != nil {
+				return err
+			}
+			target, err := resolveTagTarget(repoRoot, flags.module)
+			if err != nil {
+				return err
+			}
+			// rebase 中途 HEAD 是分离的，打出的 tag 会指向半成品提交
+			if err := conflictcmd.HandleInProgress(ctx, repoRoot, "tagging"); err != nil {
+				return err
+			}
+
 			utils.LogConfigAndBranch()
-			utils.Spin("fetch git tag: ", func() (r result.Result[any]) {
-				utils.GitFetchAll(ctx)
-				return
-			})
+			if err := utils.Spin("fetch git tag: ", func() (r result.Result[any]) {
+				return r.WithErr(utils.GitFetchAll(ctx))
+			}).GetErr(); err != nil {
+				return err
+			}
 
 			if flags.fastCommit {
-				tags := utils.GetAllGitTags(ctx)
-				sort.Slice(tags, func(i, j int) bool { return tags[i].GreaterThan(tags[j]) })
-
+				tags, err := utils.GetPrefixedGitTags(ctx, target.Prefix)
+				if err != nil {
+					return err
+				}
 				selectTags := lo.Map(tags, func(item *semver.Version, _ int) tap.SelectOption[*semver.Version] {
 					return tap.SelectOption[*semver.Version]{
 						Value: item,
@@ -124,7 +175,13 @@ func New() *redant.Command {
 				if tagName == "" {
 					return fmt.Errorf("tag name is empty")
 				}
-				return validateAndPublishTag(ctx, tagName, ".version/VERSION", params.CommitCfg)
+				if err := validateAndPublishTag(ctx, repoRoot, target, tagName, params, flags.sign, flags.skipChecks); err != nil {
+					return err
+				}
+				if !flags.skipNotify {
+					notifyRelease(ctx, target, tagName, params.NotifyCfg)
+				}
+				return nil
 			}
 
 			p := tea.NewProgram(initialModel())
@@ -134,21 +191,14 @@ func New() *redant.Command {
 				return nil
 			}
 
-			tags := utils.GetAllGitTags(ctx)
-			verFile := ".version/VERSION"
-			var ver *semver.Version
-			if selected != envRelease {
-				ver = utils.GetNextTag(selected, tags)
-			} else {
-				if pathutil.IsExist(verFile) {
-					ver = lo.Must(semver.NewSemver(strings.TrimSpace(string(lo.Must1(os.ReadFile(verFile))))))
-				} else {
-					ver = utils.GetNextReleaseTag(tags)
-				}
-				ver = ver.Core()
+			tags, err := utils.GetPrefixedGitTags(ctx, target.Prefix)
+			if err != nil {
+				return err
+			}
+			tagName, err := nextVersion(target, selected, tags)
+			if err != nil {
+				return err
 			}
-
-			tagName := "v" + strings.TrimPrefix(ver.Original(), "v")
 			p1 := tea.NewProgram(InitialTextInputModel(tagName))
 			m1 := assert.Must1(p1.Run()).(model2)
 			if m1.exit {
@@ -156,29 +206,111 @@ func New() *redant.Command {
 			}
 
 			tagName = m1.Value()
-			return validateAndPublishTag(ctx, tagName, verFile, params.CommitCfg)
+			if err := validateAndPublishTag(ctx, repoRoot, target, tagName, params, flags.sign, flags.skipChecks); err != nil {
+				return err
+			}
+			if !flags.skipNotify {
+				notifyRelease(ctx, target, tagName, params.NotifyCfg)
+			}
+			return nil
 		},
 	}
 }
 
-func validateAndPublishTag(ctx context.Context, tagName, verFile string, commitCfg []*fastcommitcmd.Config) error {
-	ver, err := semver.NewVersion(tagName)
+// tagTarget 描述 tag 作用对象：整个仓库，或 .fastgit/modules.yaml 中的某个模块
+type tagTarget struct {
+	Prefix       string
+	VersionFile  versionfile.File
+	ChangelogDir string
+}
+
+func resolveTagTarget(repoRoot, module string) (tagTarget, error) {
+	module = strings.TrimSpace(module)
+	if module == "" {
+		return tagTarget{VersionFile: versionfile.MustLoadConfig(repoRoot).Primary(), ChangelogDir: ".version/changelog"}, nil
+	}
+
+	bundle, err := repoconfig.Load(repoRoot)
 	if err != nil {
-		return errors.Errorf("tag name is not valid: %s", tagName)
+		return tagTarget{}, err
+	}
+	m, ok := bundle.FindModule(module)
+	if !ok {
+		return tagTarget{}, errors.Errorf("module %q is not defined in .fastgit/modules.yaml", module)
 	}
 
+	target := tagTarget{Prefix: m.TagPrefix}
+	if target.Prefix == "" {
+		target.Prefix = m.Name + "/"
+	}
+	if m.Changelog != "" {
+		target.ChangelogDir = filepath.Join(repoRoot, m.Changelog)
+		target.VersionFile = versionfile.File{Path: filepath.Join(filepath.Dir(target.ChangelogDir), "VERSION")}
+	}
+	return target, nil
+}
+
+func validateAndPublishTag(ctx context.Context, repoRoot string, target tagTarget, version string, params cmdParams, sign, skipChecks bool) error {
+	ver, err := semver.NewVersion(version)
+	if err != nil {
+		return errors.Errorf("tag name is not valid: %s", version)
+	}
+	tagName := target.Prefix + version
+	verFile := target.VersionFile
+
 	if utils.IsDirty().Unwrap() {
 		return errors.New("working tree has uncommitted changes, please commit or stash before tagging")
 	}
 
-	if err := ensureVersionAligned(verFile, ver, commitCfg); err != nil {
+	if err := ensureVersionAligned(verFile, ver, params.CommitCfg); err != nil {
+		return err
+	}
+
+	if err := runTagChecks(ctx, repoRoot, params.TagCfg, skipChecks); err != nil {
 		return err
 	}
 
-	return publishTag(ctx, tagName)
+	sign, err = signTag(params.CommitCfg, sign)
+	if err != nil {
+		return err
+	}
+	return publishTag(ctx, tagName, sign)
+}
+
+// runTagChecks 在创建 tag 前依次运行 tag.checks 中的命令（如 go test ./...），任一失败即中止；--skip-checks 跳过
+func runTagChecks(ctx context.Context, repoRoot string, cfgs []*tagcheck.Config, skip bool) error {
+	checks := tagcheck.Checks(cfgs)
+	if len(checks) == 0 {
+		return nil
+	}
+	if skip {
+		log.Warn().Int("checks", len(checks)).Msg("tag.checks skipped by --skip-checks")
+		return nil
+	}
+	if err := tagcheck.Run(ctx, repoRoot, checks, os.Stderr); err != nil {
+		return fmt.Errorf("%w\nhint: fix the failure before tagging, or use --skip-checks to bypass", err)
+	}
+	return nil
+}
+
+// signTag 决定是否创建签名 tag：--sign、commit.sign 或 git 的 tag.gpgsign；签名前检查密钥是否可用
+func signTag(commitCfg []*fastcommitcmd.Config, sign bool) (bool, error) {
+	for _, cfg := range commitCfg {
+		if cfg != nil && cfg.Sign {
+			sign = true
+		}
+	}
+	signing := utils.LoadSigningConfig()
+	if !sign && !signing.TagSign {
+		return false, nil
+	}
+	if err := signing.Check(); err != nil {
+		return false, errors.Wrap(err, "tag signing is not ready")
+	}
+	return true, nil
 }
 
-func ensureVersionAligned(verFile string, tag *semver.Version, commitCfg []*fastcommitcmd.Config) error {
+func ensureVersionAligned(verFile versionfile.File, tag *semver.Version, commitCfg []*fastcommitcmd.Config) error {
 	needsVersionAlign := false
 	for _, cfg := range commitCfg {
 		if cfg.GenVersion {
@@ -187,28 +319,31 @@ func ensureVersionAligned(verFile string, tag *semver.Version, commitCfg []*fast
 		}
 	}
 
-	if !needsVersionAlign || !pathutil.IsExist(verFile) {
+	if !needsVersionAlign || verFile.Path == "" || !pathutil.IsExist(verFile.Path) {
 		return nil
 	}
 
-	raw := strings.TrimSpace(string(lo.Must1(os.ReadFile(verFile))))
+	raw, err := verFile.Read()
+	if err != nil {
+		return err
+	}
 	if raw == "" {
 		return nil
 	}
 
 	fileVer, err := semver.NewVersion(raw)
 	if err != nil {
-		return errors.Errorf("%s content is invalid semver: %s", verFile, raw)
+		return errors.Errorf("%s content is invalid semver: %s", verFile.Path, raw)
 	}
 
 	if fileVer.Core().String() != tag.Core().String() {
-		return errors.Errorf("%s (%s) is not aligned with tag core (%s), please update and commit first", verFile, fileVer.Core().String(), tag.Core().String())
+		return errors.Errorf("%s (%s) is not aligned with tag core (%s), please update and commit first (fastgit version set %s)", verFile.Path, fileVer.Core().String(), tag.Core().String(), tag.Original())
 	}
 
 	return nil
 }
 
-func publishTag(ctx context.Context, tagName string) error {
+func publishTag(ctx context.Context, tagName string, sign bool) error {
 	exists, err := remoteTagExists(ctx, tagName)
 	if err != nil {
 		return err
@@ -221,10 +356,18 @@ func publishTag(ctx context.Context, tagName string) error {
 		return errors.Errorf("local tag already exists: %s", tagName)
 	}
 
-	if err := utils.ShellExec(ctx, "git", "tag", tagName); err != nil {
+	// 签名 tag 必须是附注 tag，以 tag 名作为说明
+	tagArgs := []string{"git", "tag", tagName}
+	if sign {
+		tagArgs = []string{"git", "tag", "-s", "-m", tagName, tagName}
+	}
+	if err := utils.ShellExec(ctx, tagArgs...); err != nil {
+		return err
+	}
+	if err := utils.ShellExec(ctx, "git", "push", "origin", tagName); err != nil {
 		return err
 	}
-	return utils.ShellExec(ctx, "git", "push", "origin", tagName)
+	return utils.PushMirrors(ctx, os.Stderr, "origin", tagName)
 }
 
 func remoteTagExists(ctx context.Context, tagName string) (bool, error) {
@@ -7,6 +7,7 @@ import (
 
 	semver "github.com/hashicorp/go-version"
 	"github.com/pubgo/fastgit/cmds/fastcommitcmd"
+	"github.com/pubgo/fastgit/pkg/versionfile"
 	"github.com/stretchr/testify/require"
 )
 
@@ -16,7 +17,7 @@ func TestEnsureVersionAligned(t *testing.T) {
 	require.NoError(t, os.WriteFile(verFile, []byte("v1.2.3\n"), 0o644))
 
 	tag := semver.Must(semver.NewVersion("v1.2.3"))
-	err := ensureVersionAligned(verFile, tag, []*fastcommitcmd.Config{{GenVersion: true}})
+	err := ensureVersionAligned(versionfile.File{Path: verFile}, tag, []*fastcommitcmd.Config{{GenVersion: true}})
 	require.NoError(t, err)
 }
 
@@ -26,6 +27,6 @@ func TestEnsureVersionAlignedMismatch(t *testing.T) {
 	require.NoError(t, os.WriteFile(verFile, []byte("v1.2.3\n"), 0o644))
 
 	tag := semver.Must(semver.NewVersion("v1.2.4"))
-	err := ensureVersionAligned(verFile, tag, []*fastcommitcmd.Config{{GenVersion: true}})
+	err := ensureVersionAligned(versionfile.File{Path: verFile}, tag, []*fastcommitcmd.Config{{GenVersion: true}})
 	require.Error(t, err)
 }
@@ -0,0 +1,62 @@
+package tagcmd
+
+import (
+	"context"
+	"strings"
+
+	semver "github.com/hashicorp/go-version"
+	"github.com/pubgo/funk/v2/errors"
+	"github.com/pubgo/funk/v2/pathutil"
+
+	"github.com/pubgo/fastgit/pkg/semtag"
+	"github.com/pubgo/fastgit/utils"
+)
+
+// NextVersion 返回下一个 tag（含模块前缀）；env 为 alpha/beta 时生成预发布版本，空或 release 时生成正式版本
+func NextVersion(ctx context.Context, repoRoot, module, env string) (string, error) {
+	env = strings.TrimSpace(env)
+	if env == "" {
+		env = envRelease
+	}
+
+	target, err := resolveTagTarget(repoRoot, module)
+	if err != nil {
+		return "", err
+	}
+	tags, err := utils.GetPrefixedGitTags(ctx, target.Prefix)
+	if err != nil {
+		return "", err
+	}
+	ver, err := nextVersion(target, env, tags)
+	if err != nil {
+		return "", err
+	}
+	return target.Prefix + ver, nil
+}
+
+// nextVersion 计算不含前缀的下一个版本：正式版本优先取版本文件，否则在最新 tag 上递增 patch
+func nextVersion(target tagTarget, env string, tags []*semver.Version) (string, error) {
+	var ver *semver.Version
+	if env != envRelease {
+		next, err := semtag.NextPrerelease(env, tags)
+		if err != nil {
+			return "", err
+		}
+		ver = next
+	} else {
+		verFile := target.VersionFile
+		if verFile.Path != "" && pathutil.IsExist(verFile.Path) {
+			raw, err := verFile.Read()
+			if err != nil {
+				return "", err
+			}
+			if ver, err = semver.NewSemver(raw); err != nil {
+				return "", errors.Errorf("invalid version %q in %s", raw, verFile.Path)
+			}
+		} else {
+			ver = semtag.NextRelease(tags)
+		}
+		ver = ver.Core()
+	}
+	return "v" + strings.TrimPrefix(ver.Original(), "v"), nil
+}
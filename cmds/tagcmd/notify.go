@@ -0,0 +1,57 @@
+package tagcmd
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pubgo/fastgit/pkg/notify"
+	"github.com/pubgo/fastgit/utils"
+	"github.com/pubgo/funk/v2/log"
+)
+
+// notifyRelease 推送发布通知；失败只记录日志，不影响已完成的 tag 发布
+func notifyRelease(ctx context.Context, target tagTarget, version string, cfgs []*notify.Config) {
+	active := false
+	for _, cfg := range cfgs {
+		active = active || cfg.Active()
+	}
+	if !active {
+		return
+	}
+
+	tagName := target.Prefix + version
+	repoName, _ := utils.GetRepositoryName()
+	rel := notify.Release{
+		Repo:      repoName,
+		Version:   tagName,
+		Changelog: releaseNotes(ctx, target, version),
+	}
+	if err := notify.Send(ctx, cfgs, rel); err != nil {
+		log.Err(err).Str("tag", tagName).Msg("failed to send release notification")
+		return
+	}
+	log.Info().Str("tag", tagName).Msg("release notification sent")
+}
+
+// releaseNotes 优先使用 <changelog>/<version>.md，否则退化为上一个同前缀 tag 以来的提交标题
+func releaseNotes(ctx context.Context, target tagTarget, version string) string {
+	if target.ChangelogDir != "" {
+		if data, err := os.ReadFile(filepath.Join(target.ChangelogDir, version+".md")); err == nil {
+			return strings.TrimSpace(string(data))
+		}
+	}
+
+	tagName := target.Prefix + version
+	rangeArg := tagName
+	if prev, err := exec.CommandContext(ctx, "git", "describe", "--tags", "--abbrev=0", "--match", target.Prefix+"v*", tagName+"^").Output(); err == nil {
+		rangeArg = strings.TrimSpace(string(prev)) + ".." + tagName
+	}
+	out, err := exec.CommandContext(ctx, "git", "log", "--no-merges", "--pretty=format:- %s", rangeArg).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,294 @@
+package tagcmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	semver "github.com/hashicorp/go-version"
+	"github.com/pubgo/redant"
+
+	"github.com/pubgo/fastgit/configs"
+	"github.com/pubgo/fastgit/pkg/gitdiff"
+	"github.com/pubgo/fastgit/pkg/gitshell"
+	"github.com/pubgo/fastgit/pkg/semtag"
+	"github.com/pubgo/fastgit/utils"
+)
+
+// 签名状态
+const (
+	signatureNone = "none"
+	signatureGood = "good"
+	signatureBad  = "bad"
+)
+
+// TagInfo 是 tag show 的结果
+type TagInfo struct {
+	Name      string `json:"name"`
+	Annotated bool   `json:"annotated"`
+	Commit    string `json:"commit"`
+	Subject   string `json:"subject"`
+	Tagger    string `json:"tagger,omitempty"`
+	Date      string `json:"date,omitempty"`
+	Message   string `json:"message,omitempty"`
+	// Signature 为 none|good|bad，SignatureDetail 是 git verify-tag 输出中的签名者说明
+	Signature       string `json:"signature"`
+	SignatureDetail string `json:"signature_detail,omitempty"`
+	// Previous 是同前缀的上一个版本 tag；正式版本只与正式版本比较
+	Previous   string            `json:"previous,omitempty"`
+	Commits    int               `json:"commits"`
+	Files      []gitdiff.NumStat `json:"files"`
+	Insertions int               `json:"insertions"`
+	Deletions  int               `json:"deletions"`
+	ReleaseURL string            `json:"release_url,omitempty"`
+}
+
+func newShowCommand() *redant.Command {
+	var (
+		tag     string
+		jsonOut bool
+	)
+
+	return &redant.Command{
+		Use:      "show [tag]",
+		Short:    "查看 tag 的说明、打标人、签名状态、相对上一个 tag 的 diffstat 与发布页链接",
+		Long:     "tag 缺省为当前提交可达的最近 tag。输出为不含颜色的纯文本，可直接交给 less 等分页器；--json 输出结构化结果。",
+		Metadata: utils.NoTTYMetadata(),
+		Args: redant.ArgSet{
+			{Name: "tag", Description: "要查看的 tag", Value: redant.StringOf(&tag)},
+		},
+		Options: redant.OptionSet{
+			{Flag: "json", Description: "以 JSON 输出", Value: redant.BoolOf(&jsonOut)},
+		},
+		Handler: func(ctx context.Context, inv *redant.Invocation) error {
+			repoRoot, err := configs.RepoPath()
+			if err != nil {
+				return err
+			}
+			if tag = strings.TrimSpace(tag); tag == "" {
+				if tag, err = gitshell.Run(ctx, repoRoot, "describe", "--tags", "--abbrev=0"); err != nil {
+					return fmt.Errorf("no tag given and none reachable from HEAD: %w", err)
+				}
+			}
+
+			info, err := ShowTag(ctx, repoRoot, tag)
+			if err != nil {
+				return err
+			}
+			if jsonOut {
+				enc := json.NewEncoder(inv.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(info)
+			}
+			renderTag(inv.Stdout, info)
+			return nil
+		},
+	}
+}
+
+const tagFormat = "%(objecttype)%00%(*objectname)%00%(objectname)%00%(taggername) %(taggeremail)%00%(taggerdate:iso)%00%(contents:subject)%00%(contents:body)%00%(contents:signature)"
+
+// ShowTag 读取 tag 的说明、签名与相对上一个 tag 的改动
+func ShowTag(ctx context.Context, repoRoot, tag string) (*TagInfo, error) {
+	out, err := gitshell.Run(ctx, repoRoot, "for-each-ref", "--format="+tagFormat, "refs/tags/"+tag)
+	if err != nil {
+		return nil, err
+	}
+	if out == "" {
+		return nil, fmt.Errorf("tag %q not found", tag)
+	}
+	fields := strings.SplitN(out, "\x00", 8)
+	if len(fields) != 8 {
+		return nil, fmt.Errorf("unexpected git for-each-ref output for %s", tag)
+	}
+
+	info := &TagInfo{Name: tag, Annotated: fields[0] == "tag", Signature: signatureNone}
+	if info.Annotated {
+		info.Commit = fields[1]
+		info.Tagger, info.Date = strings.TrimSpace(fields[3]), fields[4]
+		info.Message = strings.TrimSpace(fields[5] + "\n\n" + fields[6])
+		if strings.TrimSpace(fields[7]) != "" {
+			info.Signature, info.SignatureDetail = verifyTag(ctx, repoRoot, tag)
+		}
+	} else {
+		info.Commit = fields[2]
+	}
+	if info.Subject, err = gitshell.Run(ctx, repoRoot, "log", "-1", "--format=%s", info.Commit); err != nil {
+		return nil, err
+	}
+
+	if info.Previous, err = previousTag(ctx, repoRoot, tag); err != nil {
+		return nil, err
+	}
+	if info.Previous != "" {
+		count, err := gitshell.Run(ctx, repoRoot, "rev-list", "--count", info.Previous+".."+tag)
+		if err != nil {
+			return nil, err
+		}
+		info.Commits, _ = strconv.Atoi(count)
+		numstat, err := gitshell.Run(ctx, repoRoot, "diff", "--numstat", info.Previous, tag)
+		if err != nil {
+			return nil, err
+		}
+		info.Files = gitdiff.ParseNumstat(numstat)
+		for _, f := range info.Files {
+			info.Insertions += max(f.Additions, 0)
+			info.Deletions += max(f.Deletions, 0)
+		}
+	}
+
+	if remote, err := gitshell.Run(ctx, repoRoot, "remote", "get-url", "origin"); err == nil {
+		info.ReleaseURL = releaseURL(remote, tag)
+	}
+	return info, nil
+}
+
+// verifyTag 运行 git verify-tag；gpg 与 ssh 签名的结果都写在 stderr
+func verifyTag(ctx context.Context, repoRoot, tag string) (status, detail string) {
+	cmd := exec.CommandContext(ctx, "git", "verify-tag", tag)
+	cmd.Dir = repoRoot
+	out, err := cmd.CombinedOutput()
+	detail = signatureLine(string(out))
+	if err != nil {
+		return signatureBad, detail
+	}
+	return signatureGood, detail
+}
+
+// signatureLine 取出签名结果的那一行，如 gpg: Good signature from "Alice <a@example.com>"
+func signatureLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	for _, line := range lines {
+		lower := strings.ToLower(line)
+		if strings.Contains(lower, "good") || strings.Contains(lower, "bad") || strings.Contains(lower, "can't check") {
+			return strings.TrimSpace(line)
+		}
+	}
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+// previousTag 返回同前缀的上一个 semver tag；不是 <prefix>v* 形式的 tag 退回 git describe
+func previousTag(ctx context.Context, repoRoot, tag string) (string, error) {
+	prefix, name := splitTagPrefix(tag)
+	cur, err := semver.NewSemver(name)
+	if err != nil || !strings.HasPrefix(name, "v") {
+		prev, err := gitshell.Run(ctx, repoRoot, "describe", "--tags", "--abbrev=0", tag+"^")
+		if err != nil {
+			// 没有更早的 tag
+			return "", nil
+		}
+		return prev, nil
+	}
+
+	tags, err := semtag.List(ctx, repoRoot, prefix)
+	if err != nil {
+		return "", err
+	}
+	if prev := previousVersion(cur, tags); prev != nil {
+		return prefix + prev.Original(), nil
+	}
+	return "", nil
+}
+
+// previousVersion 在 tags 中找低于 cur 的最高版本；cur 为正式版本时跳过预发布版本
+func previousVersion(cur *semver.Version, tags []*semver.Version) *semver.Version {
+	var prev *semver.Version
+	for _, v := range tags {
+		if !v.LessThan(cur) || cur.Prerelease() == "" && v.Prerelease() != "" {
+			continue
+		}
+		if prev == nil || v.GreaterThan(prev) {
+			prev = v
+		}
+	}
+	return prev
+}
+
+// splitTagPrefix 拆出模块前缀，如 api/v1.2.0 → api/、v1.2.0
+func splitTagPrefix(tag string) (prefix, name string) {
+	if i := strings.LastIndex(tag, "/"); i >= 0 {
+		return tag[:i+1], tag[i+1:]
+	}
+	return "", tag
+}
+
+// releaseURL 由 origin 地址拼出发布页；GitLab 使用 /-/releases/<tag>，GitHub、Gitea 等使用 /releases/tag/<tag>
+func releaseURL(remote, tag string) string {
+	remote = strings.TrimSuffix(strings.TrimSpace(remote), ".git")
+	var host, path string
+	if u, err := url.Parse(remote); err == nil && u.Host != "" {
+		host, path = u.Hostname(), strings.TrimPrefix(u.Path, "/")
+		if u.Scheme == "http" || u.Scheme == "https" {
+			// 保留 https 远端上的非默认端口
+			host = u.Host
+		}
+	} else if at, rest, ok := strings.Cut(remote, ":"); ok && !strings.Contains(at, "/") {
+		// scp 风格：git@github.com:owner/repo
+		_, host, _ = strings.Cut(at, "@")
+		if host == "" {
+			host = at
+		}
+		path = rest
+	}
+	if host == "" || strings.Count(path, "/") < 1 {
+		return ""
+	}
+	if strings.Contains(host, "gitlab") {
+		return fmt.Sprintf("https://%s/%s/-/releases/%s", host, path, url.PathEscape(tag))
+	}
+	return fmt.Sprintf("https://%s/%s/releases/tag/%s", host, path, url.PathEscape(tag))
+}
+
+func renderTag(w io.Writer, info *TagInfo) {
+	kind := "lightweight"
+	if info.Annotated {
+		kind = "annotated"
+	}
+	_, _ = fmt.Fprintf(w, "tag        %s (%s)\n", info.Name, kind)
+	_, _ = fmt.Fprintf(w, "commit     %s %s\n", gitshell.ShortHash(info.Commit), info.Subject)
+	if info.Tagger != "" {
+		_, _ = fmt.Fprintf(w, "tagger     %s\n", info.Tagger)
+		_, _ = fmt.Fprintf(w, "date       %s\n", info.Date)
+	}
+	signature := info.Signature
+	if info.SignatureDetail != "" {
+		signature += ": " + info.SignatureDetail
+	}
+	_, _ = fmt.Fprintf(w, "signature  %s\n", signature)
+	if info.Previous != "" {
+		_, _ = fmt.Fprintf(w, "previous   %s (%d commits)\n", info.Previous, info.Commits)
+		_, _ = fmt.Fprintf(w, "diffstat   %d files changed, %d insertions(+), %d deletions(-)\n", len(info.Files), info.Insertions, info.Deletions)
+	} else {
+		_, _ = fmt.Fprintln(w, "previous   (first tag)")
+	}
+	if info.ReleaseURL != "" {
+		_, _ = fmt.Fprintf(w, "release    %s\n", info.ReleaseURL)
+	}
+
+	if info.Message != "" {
+		_, _ = fmt.Fprintln(w)
+		for _, line := range strings.Split(info.Message, "\n") {
+			_, _ = fmt.Fprintln(w, strings.TrimRight("    "+line, " "))
+		}
+	}
+
+	if len(info.Files) > 0 {
+		_, _ = fmt.Fprintln(w)
+		width := 0
+		for _, f := range info.Files {
+			width = max(width, len(f.Path))
+		}
+		for _, f := range info.Files {
+			stat := "binary"
+			if !f.Binary() {
+				stat = fmt.Sprintf("+%d -%d", f.Additions, f.Deletions)
+			}
+			_, _ = fmt.Fprintf(w, " %-*s | %s\n", width, f.Path, stat)
+		}
+	}
+}
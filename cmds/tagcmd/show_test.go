@@ -0,0 +1,106 @@
+package tagcmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pubgo/fastgit/pkg/gitdiff"
+)
+
+func TestReleaseURL(t *testing.T) {
+	cases := map[string]string{
+		"git@github.com:o/r.git":             "https://github.com/o/r/releases/tag/v1.0.0",
+		"https://github.com/o/r":             "https://github.com/o/r/releases/tag/v1.0.0",
+		"ssh://git@gitlab.example.com/g/s/r": "https://gitlab.example.com/g/s/r/-/releases/v1.0.0",
+		"https://git.example.com:3000/o/r":   "https://git.example.com:3000/o/r/releases/tag/v1.0.0",
+		"/srv/git/r.git":                     "",
+	}
+	for remote, want := range cases {
+		require.Equal(t, want, releaseURL(remote, "v1.0.0"), remote)
+	}
+	require.Equal(t, "https://github.com/o/r/releases/tag/api%2Fv1.0.0", releaseURL("git@github.com:o/r", "api/v1.0.0"))
+}
+
+func TestPreviousVersion(t *testing.T) {
+	tags := tagVersions("v1.2.0", "v1.2.0-rc.2", "v1.2.0-rc.1", "v1.1.0", "v1.0.0")
+	prev := func(cur string) string {
+		v := previousVersion(tagVersions(cur)[0], tags)
+		if v == nil {
+			return ""
+		}
+		return v.Original()
+	}
+	require.Equal(t, "v1.1.0", prev("v1.2.0"))
+	require.Equal(t, "v1.2.0-rc.1", prev("v1.2.0-rc.2"))
+	require.Equal(t, "v1.1.0", prev("v1.2.0-rc.1"))
+	require.Equal(t, "", prev("v1.0.0"))
+}
+
+func TestSignatureLine(t *testing.T) {
+	gpg := "gpg: Signature made Tue Jan  2 03:04:05 2026 UTC\n" +
+		"gpg:                using RSA key ABCDEF\n" +
+		"gpg: Good signature from \"Alice <a@example.com>\" [ultimate]\n"
+	require.Equal(t, `gpg: Good signature from "Alice <a@example.com>" [ultimate]`, signatureLine(gpg))
+	require.Equal(t, `Good "git" signature for a@example.com with ED25519 key SHA256:x`,
+		signatureLine(`Good "git" signature for a@example.com with ED25519 key SHA256:x`))
+	require.Equal(t, "error: no signature found", signatureLine("error: no signature found\n"))
+}
+
+func TestShowTag(t *testing.T) {
+	dir := t.TempDir()
+	run := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	run("init", "-q", "-b", "main")
+	run("config", "user.email", "t@example.com")
+	run("config", "user.name", "t")
+	run("config", "tag.gpgsign", "false")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a\n"), 0o644))
+	run("add", ".")
+	run("commit", "-qm", "feat: a")
+	run("tag", "-a", "v1.0.0", "-m", "v1.0.0")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a\nb\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "c.bin"), []byte{0, 1, 2}, 0o644))
+	run("add", ".")
+	run("commit", "-qm", "fix: b")
+	run("tag", "v1.1.0-rc.1")
+	run("tag", "-a", "v1.1.0", "-m", "Release v1.1.0", "-m", "Highlights")
+	run("remote", "add", "origin", "git@github.com:o/r.git")
+
+	info, err := ShowTag(context.Background(), dir, "v1.1.0")
+	require.NoError(t, err)
+	require.True(t, info.Annotated)
+	require.Equal(t, "fix: b", info.Subject)
+	require.Equal(t, "t <t@example.com>", info.Tagger)
+	require.Equal(t, "Release v1.1.0\n\nHighlights", info.Message)
+	require.Equal(t, signatureNone, info.Signature)
+	require.Equal(t, "v1.0.0", info.Previous)
+	require.Equal(t, 1, info.Commits)
+	require.Equal(t, []gitdiff.NumStat{{Path: "a.txt", Additions: 1}, {Path: "c.bin", Additions: -1, Deletions: -1}}, info.Files)
+	require.Equal(t, 1, info.Insertions)
+	require.Equal(t, "https://github.com/o/r/releases/tag/v1.1.0", info.ReleaseURL)
+
+	var out bytes.Buffer
+	renderTag(&out, info)
+	require.Contains(t, out.String(), "previous   v1.0.0 (1 commits)\n")
+	require.Contains(t, out.String(), "\n    Release v1.1.0\n\n    Highlights\n")
+	require.Contains(t, out.String(), " c.bin | binary\n")
+
+	light, err := ShowTag(context.Background(), dir, "v1.1.0-rc.1")
+	require.NoError(t, err)
+	require.False(t, light.Annotated)
+	require.Empty(t, light.Tagger)
+	require.Equal(t, "v1.0.0", light.Previous)
+
+	_, err = ShowTag(context.Background(), dir, "v9.9.9")
+	require.ErrorContains(t, err, `tag "v9.9.9" not found`)
+}
@@ -0,0 +1,86 @@
+package templatecmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pubgo/dix/v2"
+	"github.com/pubgo/dix/v2/dixcontext"
+	"github.com/pubgo/funk/v2/errors"
+	"github.com/pubgo/redant"
+
+	"github.com/pubgo/fastgit/cmds/fastcommitcmd"
+)
+
+type cmdParams struct {
+	CommitCfg []*fastcommitcmd.Config
+}
+
+func New() *redant.Command {
+	var (
+		name      string
+		printOnly bool
+	)
+
+	return &redant.Command{
+		Use:   "template",
+		Short: "提交信息模板（config.yaml 中 commit.templates）",
+		Children: []*redant.Command{
+			{
+				Use:   "list",
+				Short: "列出可用的提交信息模板",
+				Handler: func(ctx context.Context, inv *redant.Invocation) error {
+					templates := fastcommitcmd.Templates(loadParams(ctx).CommitCfg)
+					if len(templates) == 0 {
+						_, _ = fmt.Fprintln(inv.Stdout, "no templates, add them under commit.templates in config.yaml (fastgit config edit)")
+						return nil
+					}
+
+					w := tabwriter.NewWriter(inv.Stdout, 0, 0, 2, ' ', 0)
+					_, _ = fmt.Fprintln(w, "NAME\tDESCRIPTION\tMESSAGE")
+					for _, tpl := range templates {
+						subject, _, _ := strings.Cut(strings.TrimSpace(tpl.Message), "\n")
+						_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", tpl.Name, tpl.Description, subject)
+					}
+					return w.Flush()
+				},
+			},
+			{
+				Use:   "use <name>",
+				Short: "使用模板生成提交信息并提交（等价于 commit --template）",
+				Args: redant.ArgSet{
+					{Name: "name", Description: "模板名称", Value: redant.StringOf(&name)},
+				},
+				Options: redant.OptionSet{
+					{Flag: "print", Description: "只输出渲染后的提交信息，不提交", Value: redant.BoolOf(&printOnly)},
+				},
+				Handler: func(ctx context.Context, inv *redant.Invocation) error {
+					name = strings.TrimSpace(name)
+					if name == "" {
+						return errors.New("template name is required, see `fastgit template list`")
+					}
+
+					if printOnly {
+						msg, err := fastcommitcmd.RenderTemplate(ctx, loadParams(ctx).CommitCfg, name)
+						if err != nil {
+							return err
+						}
+						_, _ = fmt.Fprintln(inv.Stdout, msg)
+						return nil
+					}
+					return fastcommitcmd.RunTemplateCommit(ctx, name)
+				},
+			},
+		},
+	}
+}
+
+func loadParams(ctx context.Context) cmdParams {
+	var params cmdParams
+	if di := dixcontext.GetOrNil(ctx); di != nil {
+		params = dix.Inject(di, params)
+	}
+	return params
+}
@@ -0,0 +1,91 @@
+package ticketcmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pubgo/dix/v2"
+	"github.com/pubgo/dix/v2/dixcontext"
+	"github.com/pubgo/funk/v2/errors"
+	"github.com/pubgo/redant"
+
+	"github.com/pubgo/fastgit/pkg/ticket"
+	"github.com/pubgo/fastgit/utils"
+)
+
+type cmdParams struct {
+	TicketCfg []*ticket.Config
+}
+
+func New() *redant.Command {
+	var key string
+
+	keyArgs := redant.ArgSet{
+		{Name: "key", Description: "工单号（默认从当前分支名提取）", Value: redant.StringOf(&key)},
+	}
+
+	return &redant.Command{
+		Use:   "ticket",
+		Short: "Jira / Linear 工单集成（config.yaml 中 ticket）",
+		Children: []*redant.Command{
+			{
+				Use:   "show [key]",
+				Short: "显示当前分支关联工单的标题与状态",
+				Args:  keyArgs,
+				Handler: func(ctx context.Context, inv *redant.Invocation) error {
+					cfg, k, err := resolve(ctx, key)
+					if err != nil {
+						return err
+					}
+					tk, err := cfg.Fetch(ctx, k)
+					if err != nil {
+						return err
+					}
+					_, _ = fmt.Fprintf(inv.Stdout, "%s\n  title:  %s\n  status: %s\n  url:    %s\n", tk.Key, tk.Title, tk.Status, tk.URL)
+					return nil
+				},
+			},
+			{
+				Use:   "open [key]",
+				Short: "在浏览器中打开当前分支关联的工单",
+				Args:  keyArgs,
+				Handler: func(ctx context.Context, inv *redant.Invocation) error {
+					cfg, k, err := resolve(ctx, key)
+					if err != nil {
+						return err
+					}
+					link := cfg.BrowseURL(k)
+					if link == "" {
+						return errors.Errorf("cannot build url for %s, check ticket.base_url", k)
+					}
+					_, _ = fmt.Fprintln(inv.Stdout, link)
+					return utils.OpenBrowser(ctx, link)
+				},
+			},
+		},
+	}
+}
+
+// resolve 返回第一个启用的工单配置，以及参数或分支名中的工单号
+func resolve(ctx context.Context, key string) (*ticket.Config, string, error) {
+	var params cmdParams
+	if di := dixcontext.GetOrNil(ctx); di != nil {
+		params = dix.Inject(di, params)
+	}
+
+	for _, cfg := range params.TicketCfg {
+		if !cfg.Enabled() {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		if key == "" {
+			key = cfg.Key(utils.GetBranchName())
+		}
+		if key == "" {
+			return nil, "", errors.New("no ticket key found in branch name, pass it explicitly: fastgit ticket open ABC-123")
+		}
+		return cfg, key, nil
+	}
+	return nil, "", errors.New("ticket provider is not configured, set ticket.provider (jira|linear) in config.yaml")
+}
@@ -0,0 +1,111 @@
+package tutorialcmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pubgo/dix/v2"
+	"github.com/pubgo/dix/v2/dixcontext"
+	"github.com/pubgo/funk/v2/recovery"
+	"github.com/pubgo/redant"
+	"github.com/yarlson/tap"
+
+	"github.com/pubgo/fastgit/pkg/aiprovider"
+)
+
+type cmdParams struct {
+	AI aiprovider.Provider
+}
+
+func New() *redant.Command {
+	var flags = new(struct {
+		keep bool
+		yes  bool
+	})
+
+	return &redant.Command{
+		Use:   "tutorial",
+		Short: "在临时仓库中演示 暂存 → AI 提交 → changelog → tag 的完整流程",
+		Options: []redant.Option{
+			{
+				Flag:        "keep",
+				Description: "保留演示仓库，便于结束后继续练习",
+				Value:       redant.BoolOf(&flags.keep),
+			},
+			{
+				Flag:        "yes",
+				Description: "不在步骤之间暂停确认",
+				Value:       redant.BoolOf(&flags.yes),
+			},
+		},
+		Handler: func(ctx context.Context, i *redant.Invocation) error {
+			defer recovery.Exit()
+
+			var params cmdParams
+			if di := dixcontext.GetOrNil(ctx); di != nil {
+				params = dix.Inject(di, params)
+			}
+			if params.AI == nil {
+				params.AI = aiprovider.NewRuleFallback()
+			}
+
+			s, err := newSession(params.AI)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				if flags.keep {
+					fmt.Printf("\n演示仓库已保留：%s\n", s.dir)
+					return
+				}
+				_ = os.RemoveAll(s.dir)
+			}()
+
+			tap.Intro("fastgit tutorial")
+			fmt.Printf("演示仓库：%s（不会影响当前仓库）\n", s.dir)
+
+			steps := s.steps()
+			for idx, st := range steps {
+				fmt.Printf("\n[%d/%d] %s\n", idx+1, len(steps), st.Title)
+				fmt.Println(st.Explain)
+				if st.Command != "" {
+					fmt.Printf("  $ %s\n", st.Command)
+				}
+
+				if !flags.yes && !tap.Confirm(ctx, tap.ConfirmOptions{
+					Message:      "执行这一步？",
+					InitialValue: true,
+				}) {
+					tap.Cancel("教程已退出")
+					return nil
+				}
+
+				if err := st.Run(ctx); err != nil {
+					return fmt.Errorf("%s: %w", st.Title, err)
+				}
+			}
+
+			printReport(s.report)
+			tap.Outro("完成！在真实仓库中直接使用上面的命令即可")
+			return nil
+		},
+	}
+}
+
+func printReport(r report) {
+	fmt.Println("\n环境自检：")
+	if r.Fallback {
+		fmt.Printf("  AI 提交：%s（规则 fallback，AI 不可用；检查 `fastgit config` 中的 openai 配置或 OPENAI_API_KEY）\n", r.Provider)
+	} else {
+		fmt.Printf("  AI 提交：%s ✓\n", r.Provider)
+	}
+	fmt.Printf("  提交信息：%s\n", r.Message)
+	if r.Changelog != "" {
+		fmt.Printf("  changelog：%s ✓\n", r.Changelog)
+	}
+	if r.Tag != "" {
+		fmt.Printf("  tag：%s ✓\n", strings.TrimSpace(r.Tag))
+	}
+}
@@ -0,0 +1,208 @@
+package tutorialcmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pubgo/fastgit/pkg/aiprovider"
+	"github.com/pubgo/fastgit/pkg/commitmsg"
+)
+
+const demoVersion = "v0.1.0"
+
+type step struct {
+	Title   string
+	Explain string
+	Command string
+	Run     func(ctx context.Context) error
+}
+
+// report 汇总演示过程中验证到的环境状态
+type report struct {
+	Provider  string
+	Fallback  bool
+	Message   string
+	Changelog string
+	Tag       string
+}
+
+// session 持有一个临时演示仓库；fastgit 用于以子进程方式调用真实的 fastgit 命令
+type session struct {
+	dir     string
+	ai      aiprovider.Provider
+	fastgit func(ctx context.Context, args ...string) error
+	report  report
+}
+
+func newSession(ai aiprovider.Provider) (*session, error) {
+	dir, err := os.MkdirTemp("", "fastgit-tutorial-")
+	if err != nil {
+		return nil, err
+	}
+	return &session{dir: dir, ai: ai, fastgit: runSelf}, nil
+}
+
+func (s *session) steps() []step {
+	return []step{
+		{
+			Title:   "准备演示仓库",
+			Explain: "初始化一个只包含 README 的 git 仓库，作为练习场。",
+			Command: "git init && git commit -m 'chore: init'",
+			Run:     s.prepare,
+		},
+		{
+			Title:   "暂存改动",
+			Explain: "新增 hello.go 并加入暂存区；fastgit commit 只会读取 staged diff。",
+			Command: "git add hello.go",
+			Run:     s.stage,
+		},
+		{
+			Title:   "AI 生成提交信息",
+			Explain: "把 staged diff 交给 AI 生成 conventional commit；真实仓库中运行 fastgit commit 会额外提供候选选择与编辑。",
+			Command: "fastgit commit",
+			Run:     s.commit,
+		},
+		{
+			Title:   "记录 changelog",
+			Explain: "初始化 .version/changelog，把本次提交写入 Unreleased.md 并落版；真实仓库中可用 changelog draft 让 Copilot 草拟。",
+			Command: "fastgit changelog init && fastgit changelog release",
+			Run:     s.changelog,
+		},
+		{
+			Title:   "打 tag",
+			Explain: "为落版结果打上版本 tag；真实仓库中 fastgit tag 会计算下一个版本并推送到 origin。",
+			Command: "fastgit tag",
+			Run:     s.tag,
+		},
+	}
+}
+
+func (s *session) prepare(ctx context.Context) error {
+	if err := s.git(ctx, "init", "-q"); err != nil {
+		return err
+	}
+	if err := s.git(ctx, "config", "user.name", "fastgit tutorial"); err != nil {
+		return err
+	}
+	if err := s.git(ctx, "config", "user.email", "tutorial@fastgit.local"); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(s.dir, "README.md"), []byte("# demo\n"), 0o644); err != nil {
+		return err
+	}
+	if err := s.git(ctx, "add", "README.md"); err != nil {
+		return err
+	}
+	return s.git(ctx, "commit", "-q", "-m", "chore: init")
+}
+
+func (s *session) stage(ctx context.Context) error {
+	content := "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hello fastgit\")\n}\n"
+	if err := os.WriteFile(filepath.Join(s.dir, "hello.go"), []byte(content), 0o644); err != nil {
+		return err
+	}
+	if err := s.git(ctx, "add", "hello.go"); err != nil {
+		return err
+	}
+	stat, err := s.gitOutput(ctx, "diff", "--cached", "--stat")
+	if err != nil {
+		return err
+	}
+	fmt.Println(stat)
+	return nil
+}
+
+func (s *session) commit(ctx context.Context) error {
+	diff, err := s.gitOutput(ctx, "diff", "--cached")
+	if err != nil {
+		return err
+	}
+
+	rsp, err := s.ai.Complete(ctx, aiprovider.CompleteRequest{
+		System: commitmsg.GeneratePrompt("en", 50, commitmsg.ConventionalCommitType),
+		User:   diff,
+	})
+	if err != nil {
+		return err
+	}
+
+	msg := strings.TrimSpace(rsp.Text)
+	if msg == "" {
+		msg = "feat: add hello command"
+	}
+	s.report.Provider = rsp.Provider
+	s.report.Fallback = rsp.Fallback
+	s.report.Message = strings.SplitN(msg, "\n", 2)[0]
+	fmt.Printf("生成的提交信息：%s\n", msg)
+
+	return s.git(ctx, "commit", "-q", "-m", msg)
+}
+
+func (s *session) changelog(ctx context.Context) error {
+	if err := s.fastgit(ctx, "changelog", "init", "--repo", s.dir, "--version", demoVersion); err != nil {
+		return err
+	}
+
+	unreleased := filepath.Join(s.dir, ".version", "changelog", "Unreleased.md")
+	data, err := os.ReadFile(unreleased)
+	if err != nil {
+		return err
+	}
+	content := strings.Replace(string(data), "## 新增\n\n暂无", "## 新增\n\n- "+s.report.Message, 1)
+	if err := os.WriteFile(unreleased, []byte(content), 0o644); err != nil {
+		return err
+	}
+
+	if err := s.fastgit(ctx, "changelog", "release", "--repo", s.dir, "--skip-validate", "--skip-bump-check", "--skip-notify"); err != nil {
+		return err
+	}
+	s.report.Changelog = filepath.Join(".version", "changelog", demoVersion+".md")
+
+	if err := s.git(ctx, "add", "-A"); err != nil {
+		return err
+	}
+	return s.git(ctx, "commit", "-q", "-m", "docs: release "+demoVersion)
+}
+
+func (s *session) tag(ctx context.Context) error {
+	if err := s.git(ctx, "tag", "-a", demoVersion, "-m", "release "+demoVersion); err != nil {
+		return err
+	}
+	out, err := s.gitOutput(ctx, "tag", "-n", demoVersion)
+	if err != nil {
+		return err
+	}
+	s.report.Tag = out
+	fmt.Println(out)
+	return nil
+}
+
+func (s *session) git(ctx context.Context, args ...string) error {
+	_, err := s.gitOutput(ctx, args...)
+	return err
+}
+
+func (s *session) gitOutput(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", s.dir}, args...)...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w\n%s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// runSelf 以子进程运行当前 fastgit 可执行文件，保证演示走的是真实命令
+func runSelf(ctx context.Context, args ...string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, exe, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
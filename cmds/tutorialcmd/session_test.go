@@ -0,0 +1,53 @@
+package tutorialcmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pubgo/fastgit/cmds/chglogcmd"
+	"github.com/pubgo/fastgit/pkg/aiprovider"
+)
+
+func TestSessionRunsAllSteps(t *testing.T) {
+	s := &session{
+		dir: t.TempDir(),
+		ai:  aiprovider.NewRuleFallback(),
+		fastgit: func(ctx context.Context, args ...string) error {
+			if len(args) == 0 || args[0] != "changelog" {
+				t.Fatalf("unexpected fastgit invocation %v", args)
+			}
+			return chglogcmd.NewCommand().Invoke(args[1:]...).WithContext(ctx).Run()
+		},
+	}
+
+	ctx := context.Background()
+	for _, st := range s.steps() {
+		if err := st.Run(ctx); err != nil {
+			t.Fatalf("%s: %v", st.Title, err)
+		}
+	}
+
+	if !s.report.Fallback || s.report.Provider != "rule-fallback" {
+		t.Fatalf("unexpected provider report %+v", s.report)
+	}
+	if s.report.Message != "feat: add hello.go" {
+		t.Fatalf("unexpected commit message %q", s.report.Message)
+	}
+
+	release, err := os.ReadFile(filepath.Join(s.dir, s.report.Changelog))
+	if err != nil {
+		t.Fatalf("read release changelog: %v", err)
+	}
+	if !strings.Contains(string(release), "- feat: add hello.go") {
+		t.Fatalf("release changelog missing commit entry:\n%s", release)
+	}
+	if !strings.HasPrefix(s.report.Tag, demoVersion) {
+		t.Fatalf("unexpected tag report %q", s.report.Tag)
+	}
+	if status, _ := s.gitOutput(ctx, "status", "--porcelain"); status != "" {
+		t.Fatalf("expected clean demo repo, got:\n%s", status)
+	}
+}
@@ -10,7 +10,8 @@ import (
 
 	"github.com/hashicorp/go-getter"
 	"github.com/hashicorp/go-version"
-	"github.com/olekukonko/tablewriter"
+	"github.com/pubgo/dix/v2"
+	"github.com/pubgo/dix/v2/dixcontext"
 	"github.com/pubgo/funk/v2/assert"
 	"github.com/pubgo/funk/v2/errors"
 	"github.com/pubgo/funk/v2/log"
@@ -21,47 +22,29 @@ import (
 	"github.com/samber/lo"
 	"github.com/yarlson/tap"
 
+	"github.com/pubgo/fastgit/utils"
 	"github.com/pubgo/fastgit/utils/githubclient"
 )
 
+type proxyParams struct {
+	OpenaiCfg []*utils.OpenaiConfig
+}
+
+// configuredProxy 返回 openai.proxy，查询 release 与下载产物都经同一代理
+func configuredProxy(ctx context.Context) string {
+	di := dixcontext.GetOrNil(ctx)
+	if di == nil {
+		return ""
+	}
+	return utils.ConfiguredProxy(dix.Inject(di, proxyParams{}).OpenaiCfg)
+}
+
 func New() *redant.Command {
 	return &redant.Command{
 		Use:   "upgrade",
 		Short: "self upgrade management",
 		Children: []*redant.Command{
-			{
-				Use: "list",
-				Handler: func(ctx context.Context, i *redant.Invocation) error {
-					client := githubclient.NewPublicRelease("pubgo", "fastgit")
-					releases := assert.Must1(client.List(ctx))
-
-					tt := tablewriter.NewWriter(os.Stdout)
-					tt.Header([]string{"Name", "Size", "Url"})
-
-					for _, r := range releases {
-						for _, a := range githubclient.GetAssets(r) {
-							if a.IsChecksumFile() {
-								continue
-							}
-
-							if a.OS != runtime.GOOS {
-								continue
-							}
-
-							if a.Arch != runtime.GOARCH {
-								continue
-							}
-
-							assert.Must(tt.Append([]string{
-								a.Name,
-								githubclient.GetSizeFormat(a.Size),
-								a.URL,
-							}))
-						}
-					}
-					return tt.Render()
-				},
-			},
+			newListCommand(),
 		},
 		Handler: func(ctx context.Context, i *redant.Invocation) (gErr error) {
 			defer result.RecoveryErr(&gErr, func(err error) error {
@@ -72,7 +55,8 @@ func New() *redant.Command {
 				return err
 			})
 
-			client := githubclient.NewPublicRelease("pubgo", "fastgit")
+			proxy := configuredProxy(ctx)
+			client := githubclient.NewPublicRelease("pubgo", "fastgit", proxy)
 			r := assert.Must1(client.List(ctx))
 
 			assets := githubclient.GetAssetList(r)
@@ -120,6 +104,7 @@ func New() *redant.Command {
 				e.Msgf("start download %s", downloadURL)
 			})
 
+			httpGetter := &getter.HttpGetter{Client: utils.NewHTTPClient(proxy)}
 			c := &getter.Client{
 				Ctx:              ctx,
 				Src:              downloadURL,
@@ -127,6 +112,7 @@ func New() *redant.Command {
 				Pwd:              pwd,
 				Mode:             getter.ClientModeDir,
 				ProgressListener: defaultProgressBar,
+				Getters:          map[string]getter.Getter{"http": httpGetter, "https": httpGetter},
 			}
 			assert.Must(c.Get())
 			assert.Must(os.Rename(downloadDir+"/fastgit", execFile))
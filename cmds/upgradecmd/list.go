@@ -0,0 +1,137 @@
+package upgradecmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/google/go-github/v71/github"
+	"github.com/hashicorp/go-version"
+	"github.com/olekukonko/tablewriter"
+	buildversion "github.com/pubgo/funk/v2/buildinfo/version"
+	"github.com/pubgo/redant"
+
+	"github.com/pubgo/fastgit/utils/githubclient"
+)
+
+// notesExcerptLen 是列表中发布说明摘要的最大字符数
+const notesExcerptLen = 60
+
+var newerStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("42")).Bold(true)
+
+// releaseInfo 是 upgrade list 的一行：当前平台可用的一个发布版本
+type releaseInfo struct {
+	Version   string    `json:"version"`
+	Published time.Time `json:"published"`
+	Current   bool      `json:"current"`
+	Newer     bool      `json:"newer"`
+	Notes     string    `json:"notes,omitempty"`
+	Size      int       `json:"size"`
+	URL       string    `json:"url"`
+}
+
+func newListCommand() *redant.Command {
+	var jsonOut bool
+	return &redant.Command{
+		Use:   "list",
+		Short: "列出当前平台可用的发布版本：标记已安装版本、高亮更新的版本，附发布日期与说明摘要",
+		Options: redant.OptionSet{
+			{Flag: "json", Description: "以 JSON 输出", Value: redant.BoolOf(&jsonOut)},
+		},
+		Handler: func(ctx context.Context, inv *redant.Invocation) error {
+			client := githubclient.NewPublicRelease("pubgo", "fastgit", configuredProxy(ctx))
+			releases, err := client.List(ctx)
+			if err != nil {
+				return fmt.Errorf("list releases: %w", err)
+			}
+
+			infos := listReleases(releases, buildversion.Version(), runtime.GOOS, runtime.GOARCH)
+			if jsonOut {
+				enc := json.NewEncoder(inv.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(infos)
+			}
+			if len(infos) == 0 {
+				_, _ = fmt.Fprintf(inv.Stdout, "no releases for %s/%s\n", runtime.GOOS, runtime.GOARCH)
+				return nil
+			}
+
+			tt := tablewriter.NewWriter(inv.Stdout)
+			tt.Header([]string{"", "Version", "Date", "Size", "Notes"})
+			for _, r := range infos {
+				mark, name := "", r.Version
+				switch {
+				case r.Current:
+					mark = "*"
+				case r.Newer:
+					mark, name = "↑", newerStyle.Render(name)
+				}
+				if err := tt.Append([]string{mark, name, r.Published.Format(time.DateOnly), githubclient.GetSizeFormat(r.Size), r.Notes}); err != nil {
+					return err
+				}
+			}
+			return tt.Render()
+		},
+	}
+}
+
+// listReleases 返回带有 goos/goarch 安装包的发布版本，按语义化版本从新到旧排列；无法解析的版本排在最后
+func listReleases(releases []*github.RepositoryRelease, current, goos, goarch string) []releaseInfo {
+	cur, _ := version.NewSemver(strings.TrimSpace(current))
+	var infos []releaseInfo
+	for _, r := range releases {
+		for _, a := range githubclient.GetAssets(r) {
+			if a.IsChecksumFile() || a.OS != goos || a.Arch != goarch {
+				continue
+			}
+			info := releaseInfo{
+				Version:   a.Name,
+				Published: r.GetPublishedAt().Time,
+				Notes:     notesExcerpt(r.GetBody(), notesExcerptLen),
+				Size:      a.Size,
+				URL:       a.URL,
+			}
+			if v, err := version.NewSemver(a.Name); err == nil && cur != nil {
+				info.Current = v.Equal(cur)
+				info.Newer = v.GreaterThan(cur)
+			}
+			infos = append(infos, info)
+			break
+		}
+	}
+
+	sort.SliceStable(infos, func(i, j int) bool {
+		vi, ei := version.NewSemver(infos[i].Version)
+		vj, ej := version.NewSemver(infos[j].Version)
+		if ei != nil || ej != nil {
+			return ei == nil
+		}
+		return vi.GreaterThan(vj)
+	})
+	return infos
+}
+
+// notesExcerpt 取发布说明中第一行正文（跳过标题与空行，去掉列表符号），超出 limit 个字符时截断
+func notesExcerpt(body string, limit int) string {
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "<!--") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimLeft(line, "-*+ "))
+		if line == "" {
+			continue
+		}
+		if utf8.RuneCountInString(line) > limit {
+			line = string([]rune(line)[:limit-1]) + "…"
+		}
+		return line
+	}
+	return ""
+}
@@ -0,0 +1,84 @@
+package upgradecmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v71/github"
+	"github.com/stretchr/testify/require"
+)
+
+func testRelease(tag, body string, published time.Time, assets ...string) *github.RepositoryRelease {
+	r := &github.RepositoryRelease{
+		TagName:     github.Ptr(tag),
+		Body:        github.Ptr(body),
+		PublishedAt: &github.Timestamp{Time: published},
+	}
+	for _, name := range assets {
+		r.Assets = append(r.Assets, &github.ReleaseAsset{
+			Name:               github.Ptr(name),
+			Size:               github.Ptr(1024),
+			BrowserDownloadURL: github.Ptr("https://example.com/" + tag + "/" + name),
+		})
+	}
+	return r
+}
+
+func TestListReleases(t *testing.T) {
+	day := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	releases := []*github.RepositoryRelease{
+		testRelease("v1.2.0", "## Changes\n- add list", day, "checksums.txt", "fastgit_linux_amd64.tar.gz"),
+		testRelease("v1.10.0", "", day.AddDate(0, 1, 0), "fastgit_darwin_arm64.tar.gz", "fastgit_linux_amd64.tar.gz"),
+		testRelease("nightly", "", day, "fastgit_linux_amd64.tar.gz"),
+		testRelease("v1.3.0", "", day, "fastgit_darwin_arm64.tar.gz"),
+		testRelease("v1.1.0", "", day, "fastgit_linux_amd64.tar.gz"),
+	}
+
+	infos := listReleases(releases, "v1.2.0", "linux", "amd64")
+	var versions []string
+	for _, info := range infos {
+		versions = append(versions, info.Version)
+	}
+	// 按语义化版本排序（v1.10.0 在 v1.2.0 之前），无法解析的 nightly 排在最后，没有 linux/amd64 安装包的 v1.3.0 被跳过
+	require.Equal(t, []string{"v1.10.0", "v1.2.0", "v1.1.0", "nightly"}, versions)
+
+	require.True(t, infos[0].Newer)
+	require.False(t, infos[0].Current)
+	require.Equal(t, "https://example.com/v1.10.0/fastgit_linux_amd64.tar.gz", infos[0].URL)
+
+	require.True(t, infos[1].Current)
+	require.False(t, infos[1].Newer)
+	require.Equal(t, "add list", infos[1].Notes)
+	require.Equal(t, day, infos[1].Published)
+	require.Equal(t, 1024, infos[1].Size)
+
+	require.False(t, infos[2].Current || infos[2].Newer)
+	require.False(t, infos[3].Current || infos[3].Newer)
+
+	// 当前版本无法解析时不标记任何版本
+	for _, info := range listReleases(releases, "dev", "linux", "amd64") {
+		require.False(t, info.Current || info.Newer, info.Version)
+	}
+	require.Empty(t, listReleases(releases, "v1.2.0", "windows", "amd64"))
+}
+
+func TestNotesExcerpt(t *testing.T) {
+	cases := map[string]struct {
+		body  string
+		limit int
+		want  string
+	}{
+		"empty":            {body: "", limit: 10, want: ""},
+		"headings only":    {body: "# v1.0.0\n\n## Changes\n", limit: 10, want: ""},
+		"skips comments":   {body: "<!-- generated -->\n\nFirst line\nsecond", limit: 20, want: "First line"},
+		"strips bullets":   {body: "## 新增\n\n- * add list command", limit: 20, want: "add list command"},
+		"skips bare marks": {body: "-\n* fix crash", limit: 20, want: "fix crash"},
+		"truncates runes":  {body: "新增发布列表命令", limit: 5, want: "新增发布…"},
+		"fits exactly":     {body: "abcde", limit: 5, want: "abcde"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.want, notesExcerpt(tc.body, tc.limit))
+		})
+	}
+}
@@ -3,11 +3,17 @@ package versioncmd
 import (
 	"context"
 	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
 
 	"github.com/pubgo/funk/v2/buildinfo/version"
 	"github.com/pubgo/funk/v2/recovery"
 	"github.com/pubgo/funk/v2/running"
 	"github.com/pubgo/redant"
+
+	"github.com/pubgo/fastgit/pkg/versionfile"
+	"github.com/pubgo/fastgit/utils"
 )
 
 func New() *redant.Command {
@@ -15,6 +21,12 @@ func New() *redant.Command {
 		Use:     "version",
 		Aliases: []string{"v"},
 		Short:   "version info",
+		Long:    "无子命令时输出 fastgit 构建信息；show/set/bump 管理项目版本文件（位置与格式见 .fastgit/version.yaml），可在 CI 中直接使用。",
+		Children: []*redant.Command{
+			newShowCommand(),
+			newSetCommand(),
+			newBumpCommand(),
+		},
 		Handler: func(ctx context.Context, i *redant.Invocation) error {
 			defer recovery.Exit()
 			fmt.Println("project:", version.Project())
@@ -26,3 +38,140 @@ func New() *redant.Command {
 		},
 	}
 }
+
+func newShowCommand() *redant.Command {
+	return &redant.Command{
+		Use:      "show",
+		Short:    "显示项目当前版本及各版本文件中的值",
+		Metadata: utils.NoTTYMetadata(),
+		Handler: func(ctx context.Context, inv *redant.Invocation) error {
+			repoRoot, cfg, err := loadConfig(ctx)
+			if err != nil {
+				return err
+			}
+
+			values := cfg.Show()
+			primary := values[0]
+			if primary.Err != nil {
+				return fmt.Errorf("read %s: %w", rel(repoRoot, primary.File.Path), primary.Err)
+			}
+			_, _ = fmt.Fprintln(inv.Stdout, primary.Version)
+			if len(values) == 1 {
+				return nil
+			}
+
+			var drifted int
+			for _, v := range values {
+				switch {
+				case v.Err != nil:
+					drifted++
+					_, _ = fmt.Fprintf(inv.Stderr, "  ✗ %s: %v\n", rel(repoRoot, v.File.Path), v.Err)
+				case !sameVersion(v.Version, primary.Version):
+					drifted++
+					_, _ = fmt.Fprintf(inv.Stderr, "  ✗ %s: %s\n", rel(repoRoot, v.File.Path), v.Version)
+				default:
+					_, _ = fmt.Fprintf(inv.Stderr, "  ✓ %s: %s\n", rel(repoRoot, v.File.Path), v.Version)
+				}
+			}
+			if drifted > 0 {
+				return fmt.Errorf("%d version file(s) differ from %s, run `fastgit version set %s` to align", drifted, rel(repoRoot, primary.File.Path), primary.Version)
+			}
+			return nil
+		},
+	}
+}
+
+func newSetCommand() *redant.Command {
+	var target string
+
+	return &redant.Command{
+		Use:      "set",
+		Short:    "将所有版本文件设置为指定版本",
+		Metadata: utils.NoTTYMetadata(),
+		Args: redant.ArgSet{
+			{Name: "version", Description: "目标版本，如 v1.2.3", Value: redant.StringOf(&target)},
+		},
+		Handler: func(ctx context.Context, inv *redant.Invocation) error {
+			repoRoot, cfg, err := loadConfig(ctx)
+			if err != nil {
+				return err
+			}
+			if err := versionfile.Validate(target); err != nil {
+				return err
+			}
+			return writeVersion(inv, repoRoot, cfg, strings.TrimSpace(target))
+		},
+	}
+}
+
+func newBumpCommand() *redant.Command {
+	var (
+		level  string
+		dryRun bool
+	)
+
+	return &redant.Command{
+		Use:      "bump",
+		Short:    "按 major/minor/patch 递增版本并写入所有版本文件",
+		Metadata: utils.NoTTYMetadata(),
+		Args: redant.ArgSet{
+			{Name: "level", Description: "major | minor | patch", Value: redant.StringOf(&level)},
+		},
+		Options: redant.OptionSet{
+			{Flag: "dry-run", Description: "只输出下一个版本，不写文件", Value: redant.BoolOf(&dryRun)},
+		},
+		Handler: func(ctx context.Context, inv *redant.Invocation) error {
+			repoRoot, cfg, err := loadConfig(ctx)
+			if err != nil {
+				return err
+			}
+			current, err := cfg.Current()
+			if err != nil {
+				return fmt.Errorf("read current version: %w", err)
+			}
+			next, err := versionfile.Bump(current, level)
+			if err != nil {
+				return err
+			}
+			if dryRun {
+				_, _ = fmt.Fprintln(inv.Stdout, next)
+				return nil
+			}
+			return writeVersion(inv, repoRoot, cfg, next)
+		},
+	}
+}
+
+func writeVersion(inv *redant.Invocation, repoRoot string, cfg versionfile.Config, next string) error {
+	updated, err := cfg.Write(next)
+	for _, p := range updated {
+		_, _ = fmt.Fprintf(inv.Stderr, "  ✓ %s\n", rel(repoRoot, p))
+	}
+	if err != nil {
+		return err
+	}
+	// stdout 只输出版本号，便于 CI 中 $(fastgit version bump patch) 捕获
+	_, _ = fmt.Fprintln(inv.Stdout, next)
+	return nil
+}
+
+func loadConfig(ctx context.Context) (string, versionfile.Config, error) {
+	out, err := exec.CommandContext(ctx, "git", "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return "", versionfile.Config{}, fmt.Errorf("not in a git repository: %w", err)
+	}
+	repoRoot := strings.TrimSpace(string(out))
+	cfg, err := versionfile.LoadConfig(repoRoot)
+	return repoRoot, cfg, err
+}
+
+func sameVersion(a, b string) bool {
+	return strings.TrimPrefix(a, "v") == strings.TrimPrefix(b, "v")
+}
+
+func rel(repoRoot, path string) string {
+	if r, err := filepath.Rel(repoRoot, path); err == nil {
+		return r
+	}
+	return path
+}
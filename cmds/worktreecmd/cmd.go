@@ -3,6 +3,7 @@ package worktreecmd
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	"github.com/pubgo/fastgit/utils"
@@ -88,6 +89,24 @@ func New() *redant.Command {
 					return nil
 				},
 			},
+			{
+				Use:   "open",
+				Short: "Open a worktree by issue id/branch or path in the configured editor",
+				Handler: func(ctx context.Context, i *redant.Invocation) error {
+					args := commandArgs(i)
+					if len(args) != 1 {
+						return redant.DefaultHelpFn()(ctx, i)
+					}
+
+					path, err := findWorktree(args[0])
+					if err != nil {
+						return err
+					}
+
+					fmt.Printf("opening worktree: %s\n", path)
+					return utils.EditFiles(ctx, path)
+				},
+			},
 			{
 				Use:   "remove",
 				Short: "Remove a worktree by issue id/branch or by path",
@@ -134,3 +153,20 @@ func commandArgs(i *redant.Invocation) []string {
 	}
 	return args
 }
+
+// findWorktree 按分支名（issue id 按 create 的规则补全为 <id>/impl）或路径查找 worktree
+func findWorktree(input string) (string, error) {
+	worktrees, err := utils.ListWorktrees()
+	if err != nil {
+		return "", err
+	}
+
+	branch, _ := utils.DetermineWorktreeNames(input)
+	abs, _ := filepath.Abs(input)
+	for _, wt := range worktrees {
+		if wt.Branch == input || wt.Branch == branch || wt.Path == abs {
+			return wt.Path, nil
+		}
+	}
+	return "", fmt.Errorf("worktree %q not found, see `fastgit worktree list`", input)
+}
@@ -0,0 +1,107 @@
+package wscmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pubgo/dix/v2"
+	"github.com/pubgo/dix/v2/dixcontext"
+	"github.com/pubgo/redant"
+
+	"github.com/pubgo/fastgit/pkg/gitshell"
+	"github.com/pubgo/fastgit/pkg/timing"
+	"github.com/pubgo/fastgit/utils"
+)
+
+// Config 是 config.yaml 的 workspace 段
+type Config struct {
+	// Repos 是 ws status 汇总的仓库目录，支持 ~/；留空时自动发现同级仓库
+	Repos []string `yaml:"repos"`
+}
+
+type cmdParams struct {
+	WorkspaceCfg []*Config
+}
+
+// New creates the ws command.
+func New() *redant.Command {
+	var (
+		dir     string
+		fetch   bool
+		jsonOut bool
+	)
+
+	return &redant.Command{
+		Use:   "ws",
+		Short: "多仓库工作区",
+		Children: []*redant.Command{
+			{
+				Use:   "status",
+				Short: "汇总工作区内各仓库的分支、未提交改动、ahead/behind 与最近提交",
+				Long: "仓库列表取自 config.yaml 的 workspace.repos；未配置时自动发现：在仓库内运行时扫描仓库的同级目录，" +
+					"否则扫描当前目录下一级子目录。--dir 指定扫描目录。",
+				Metadata: utils.NoTTYMetadata(),
+				Options: redant.OptionSet{
+					{Flag: "dir", Description: "扫描该目录下一级子目录中的 git 仓库（忽略 workspace.repos）", Value: redant.StringOf(&dir)},
+					{Flag: "fetch", Description: "先 git fetch 各仓库，ahead/behind 基于最新的远端", Value: redant.BoolOf(&fetch)},
+					{Flag: "json", Description: "以 JSON 输出", Value: redant.BoolOf(&jsonOut)},
+				},
+				Handler: func(ctx context.Context, inv *redant.Invocation) error {
+					var params cmdParams
+					if di := dixcontext.GetOrNil(ctx); di != nil {
+						params = dix.Inject(di, params)
+					}
+
+					repos, err := resolveRepos(ctx, strings.TrimSpace(dir), params.WorkspaceCfg)
+					if err != nil {
+						return err
+					}
+					if len(repos) == 0 {
+						return fmt.Errorf("no git repositories found, set workspace.repos in config.yaml or pass --dir")
+					}
+
+					done := timing.Track(ctx, timing.PhaseGit, "collect repo status")
+					statuses := collect(ctx, repos, fetch)
+					done()
+
+					if jsonOut {
+						enc := json.NewEncoder(inv.Stdout)
+						enc.SetIndent("", "  ")
+						return enc.Encode(statuses)
+					}
+					render(inv.Stdout, statuses)
+					return nil
+				},
+			},
+		},
+	}
+}
+
+// resolveRepos 决定要汇总的仓库：--dir > workspace.repos（后出现的非空配置生效）> 自动发现
+func resolveRepos(ctx context.Context, dir string, cfgs []*Config) ([]string, error) {
+	if dir != "" {
+		return gitshell.FindRepos(dir)
+	}
+	var configured []string
+	for _, cfg := range cfgs {
+		if cfg != nil && len(cfg.Repos) > 0 {
+			configured = cfg.Repos
+		}
+	}
+	if len(configured) > 0 {
+		repos := make([]string, 0, len(configured))
+		for _, repo := range configured {
+			repos = append(repos, expandHome(strings.TrimSpace(repo)))
+		}
+		return repos, nil
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	return discoverRepos(ctx, wd)
+}
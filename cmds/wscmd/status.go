@@ -0,0 +1,197 @@
+package wscmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pubgo/fastgit/pkg/gitshell"
+)
+
+const fieldSep = "\x1f"
+
+// RepoStatus 单个仓库的概况；Err 非空时其余字段可能不完整
+type RepoStatus struct {
+	Repo     string `json:"repo"`
+	Path     string `json:"path"`
+	Branch   string `json:"branch"`
+	Upstream string `json:"upstream,omitempty"`
+	Ahead    int    `json:"ahead"`
+	Behind   int    `json:"behind"`
+	// Staged / Unstaged / Untracked 是 git status 中对应的文件数
+	Staged    int       `json:"staged"`
+	Unstaged  int       `json:"unstaged"`
+	Untracked int       `json:"untracked"`
+	Conflicts int       `json:"conflicts"`
+	Commit    string    `json:"commit,omitempty"`
+	Subject   string    `json:"subject,omitempty"`
+	Date      time.Time `json:"date,omitzero"`
+	Err       string    `json:"error,omitempty"`
+}
+
+// Dirty 报告工作区是否有未提交的改动
+func (s RepoStatus) Dirty() bool {
+	return s.Staged+s.Unstaged+s.Untracked+s.Conflicts > 0
+}
+
+// discoverRepos 在仓库内运行时返回该仓库及其同级仓库，否则返回 dir 下一级子目录中的仓库
+func discoverRepos(ctx context.Context, dir string) ([]string, error) {
+	if top, err := gitshell.Output(ctx, dir, "rev-parse", "--show-toplevel"); err == nil {
+		return gitshell.FindRepos(filepath.Dir(strings.TrimSpace(top)))
+	}
+	return gitshell.FindRepos(dir)
+}
+
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
+
+// collect 并发读取各仓库状态，结果保持 repos 的顺序
+func collect(ctx context.Context, repos []string, fetch bool) []RepoStatus {
+	statuses := make([]RepoStatus, len(repos))
+	var wg sync.WaitGroup
+	for i, repo := range repos {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			statuses[i] = loadStatus(ctx, repo, fetch)
+		}()
+	}
+	wg.Wait()
+	return statuses
+}
+
+func loadStatus(ctx context.Context, dir string, fetch bool) RepoStatus {
+	st := RepoStatus{Repo: filepath.Base(dir), Path: dir}
+	if fetch {
+		if _, err := gitshell.Output(ctx, dir, "fetch", "--quiet"); err != nil {
+			st.Err = err.Error()
+		}
+	}
+	out, err := gitshell.Output(ctx, dir, "status", "--porcelain=v2", "--branch")
+	if err != nil {
+		st.Err = err.Error()
+		return st
+	}
+	parseStatus(out, &st)
+
+	// 空仓库没有提交，不算错误
+	if out, err := gitshell.Output(ctx, dir, "log", "-1", "--date=iso-strict", "--format=%h"+fieldSep+"%cd"+fieldSep+"%s"); err == nil {
+		if parts := strings.SplitN(strings.TrimSpace(out), fieldSep, 3); len(parts) == 3 {
+			st.Commit, st.Subject = parts[0], parts[2]
+			st.Date, _ = time.Parse(time.RFC3339, parts[1])
+		}
+	}
+	return st
+}
+
+// parseStatus 解析 git status --porcelain=v2 --branch 的分支头与文件条目
+func parseStatus(output string, st *RepoStatus) {
+	for _, line := range strings.Split(output, "\n") {
+		switch {
+		case strings.HasPrefix(line, "# branch.head "):
+			st.Branch = strings.TrimPrefix(line, "# branch.head ")
+		case strings.HasPrefix(line, "# branch.upstream "):
+			st.Upstream = strings.TrimPrefix(line, "# branch.upstream ")
+		case strings.HasPrefix(line, "# branch.ab "):
+			fields := strings.Fields(strings.TrimPrefix(line, "# branch.ab "))
+			if len(fields) == 2 {
+				st.Ahead, _ = strconv.Atoi(strings.TrimPrefix(fields[0], "+"))
+				st.Behind, _ = strconv.Atoi(strings.TrimPrefix(fields[1], "-"))
+			}
+		case strings.HasPrefix(line, "1 "), strings.HasPrefix(line, "2 "):
+			// 第二列是 XY：X 为暂存区状态，Y 为工作区状态，"." 表示未改动
+			if xy := strings.Fields(line); len(xy) > 1 && len(xy[1]) == 2 {
+				if xy[1][0] != '.' {
+					st.Staged++
+				}
+				if xy[1][1] != '.' {
+					st.Unstaged++
+				}
+			}
+		case strings.HasPrefix(line, "u "):
+			st.Conflicts++
+		case strings.HasPrefix(line, "? "):
+			st.Untracked++
+		}
+	}
+}
+
+// render 输出表格：REPO BRANCH CHANGES SYNC LAST COMMIT
+func render(w io.Writer, statuses []RepoStatus) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "REPO\tBRANCH\tCHANGES\tSYNC\tLAST COMMIT")
+	for _, st := range statuses {
+		if st.Err != "" && st.Branch == "" {
+			_, _ = fmt.Fprintf(tw, "%s\t-\t-\t-\terror: %s\n", st.Repo, firstLine(st.Err))
+			continue
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", st.Repo, st.Branch, changes(st), syncState(st), lastCommit(st, time.Now()))
+	}
+	_ = tw.Flush()
+}
+
+func changes(st RepoStatus) string {
+	if !st.Dirty() {
+		return "clean"
+	}
+	var parts []string
+	for _, c := range []struct {
+		n     int
+		label string
+	}{{st.Conflicts, "conflicts"}, {st.Staged, "staged"}, {st.Unstaged, "modified"}, {st.Untracked, "untracked"}} {
+		if c.n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", c.n, c.label))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func syncState(st RepoStatus) string {
+	switch {
+	case st.Upstream == "":
+		return "no upstream"
+	case st.Ahead == 0 && st.Behind == 0:
+		return "up to date"
+	}
+	return fmt.Sprintf("↑%d ↓%d", st.Ahead, st.Behind)
+}
+
+func lastCommit(st RepoStatus, now time.Time) string {
+	if st.Commit == "" {
+		return "-"
+	}
+	subject := st.Subject
+	if r := []rune(subject); len(r) > 50 {
+		subject = string(r[:49]) + "…"
+	}
+	return fmt.Sprintf("%s %s (%s)", st.Commit, subject, age(now.Sub(st.Date)))
+}
+
+// age 把时间差格式化为 5m、3h、2d 这样的短形式
+func age(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd", int(d.Hours()/24))
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return line
+}
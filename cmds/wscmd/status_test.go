@@ -0,0 +1,80 @@
+package wscmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStatus(t *testing.T) {
+	out := "# branch.oid 1234\n# branch.head main\n# branch.upstream origin/main\n# branch.ab +2 -1\n" +
+		"1 M. N... 100644 100644 100644 a b staged.go\n" +
+		"1 .M N... 100644 100644 100644 a b modified.go\n" +
+		"1 MM N... 100644 100644 100644 a b both.go\n" +
+		"u UU N... 100644 100644 100644 100644 a b c conflict.go\n" +
+		"? new.go\n"
+	var st RepoStatus
+	parseStatus(out, &st)
+	assert.Equal(t, "main", st.Branch)
+	assert.Equal(t, "origin/main", st.Upstream)
+	assert.Equal(t, 2, st.Ahead)
+	assert.Equal(t, 1, st.Behind)
+	assert.Equal(t, 2, st.Staged)
+	assert.Equal(t, 2, st.Unstaged)
+	assert.Equal(t, 1, st.Conflicts)
+	assert.Equal(t, 1, st.Untracked)
+	assert.Equal(t, "1 conflicts, 2 staged, 2 modified, 1 untracked", changes(st))
+	assert.Equal(t, "↑2 ↓1", syncState(st))
+}
+
+func TestWorkspaceStatus(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	ws := t.TempDir()
+	for _, name := range []string{"api", "web"} {
+		dir := filepath.Join(ws, name)
+		require.NoError(t, os.MkdirAll(dir, 0o755))
+		git(t, dir, "init", "-q", "-b", "main")
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0o644))
+		git(t, dir, "add", ".")
+		git(t, dir, "-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "-q", "-m", "feat: init "+name)
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(ws, "web", "b.txt"), []byte("b"), 0o644))
+
+	repos, err := discoverRepos(context.Background(), filepath.Join(ws, "api"))
+	require.NoError(t, err)
+	require.Len(t, repos, 2)
+
+	statuses := collect(context.Background(), repos, false)
+	assert.False(t, statuses[0].Dirty())
+	assert.Equal(t, 1, statuses[1].Untracked)
+	assert.Equal(t, "feat: init web", statuses[1].Subject)
+
+	var buf bytes.Buffer
+	render(&buf, statuses)
+	assert.Contains(t, buf.String(), "api   main    clean")
+	assert.Contains(t, buf.String(), "1 untracked")
+	assert.Contains(t, buf.String(), "no upstream")
+}
+
+func TestAge(t *testing.T) {
+	assert.Equal(t, "5m", age(5*time.Minute))
+	assert.Equal(t, "3h", age(3*time.Hour+10*time.Minute))
+	assert.Equal(t, "2d", age(49*time.Hour))
+}
+
+func git(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+}
@@ -2,13 +2,15 @@ package configs
 
 import (
 	_ "embed"
+	"fmt"
+	"os/exec"
 	"path"
+	"path/filepath"
 	"strings"
 	"sync"
 
 	"github.com/adrg/xdg"
-	"github.com/bitfield/script"
-	"github.com/pubgo/funk/v2/assert"
+	"github.com/pubgo/funk/v2/log"
 )
 
 type Version struct {
@@ -21,13 +23,37 @@ var defaultConfig []byte
 //go:embed env.yaml
 var envConfig []byte
 
+// ConfigPath 返回 ~/.config/fastgit/config.yaml，并确保其目录存在
+var ConfigPath = sync.OnceValues(func() (string, error) {
+	return xdg.ConfigFile("fastgit/config.yaml")
+})
+
+// RepoPath 返回当前仓库根目录，不在 git 仓库中时返回错误
+var RepoPath = sync.OnceValues(func() (string, error) {
+	out, err := exec.Command("git", "rev-parse", "--show-toplevel").CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse --show-toplevel: %s: %w", strings.TrimSpace(string(out)), err)
+	}
+	return strings.TrimSpace(string(out)), nil
+})
+
+// GetConfigPath 同 ConfigPath；目录无法创建时记录日志并退回 $XDG_CONFIG_HOME 下的路径
 var GetConfigPath = sync.OnceValue(func() string {
-	return assert.Exit1(xdg.ConfigFile("fastgit/config.yaml"))
+	cfgPath, err := ConfigPath()
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to prepare config dir")
+		return filepath.Join(xdg.ConfigHome, "fastgit", "config.yaml")
+	}
+	return cfgPath
 })
 
+// GetRepoPath 同 RepoPath，不在仓库中时返回空串；必须在仓库中执行的命令应使用 RepoPath 处理错误
 var GetRepoPath = sync.OnceValue(func() string {
-	repoPath := assert.Exit1(script.Exec("git rev-parse --show-toplevel").String())
-	return strings.TrimSpace(repoPath)
+	repoPath, err := RepoPath()
+	if err != nil {
+		log.Debug().Err(err).Msg("not in a git repository")
+	}
+	return repoPath
 })
 
 var GetEnvPath = sync.OnceValue(func() string {
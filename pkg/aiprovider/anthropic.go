@@ -0,0 +1,131 @@
+package aiprovider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	defaultAnthropicBaseURL = "https://api.anthropic.com"
+	defaultAnthropicModel   = "claude-3-5-haiku-latest"
+	anthropicVersion        = "2023-06-01"
+	anthropicMaxTokens      = 1024
+)
+
+// AnthropicProvider implements Provider using the Anthropic Messages API.
+type AnthropicProvider struct {
+	apiKey  string
+	baseURL string
+	model   string
+	client  *http.Client
+}
+
+// NewAnthropic returns an Anthropic provider; empty baseURL and model use the API defaults.
+func NewAnthropic(apiKey, baseURL, model string) *AnthropicProvider {
+	return &AnthropicProvider{
+		apiKey:  strings.TrimSpace(apiKey),
+		baseURL: strings.TrimRight(firstNonEmpty(baseURL, defaultAnthropicBaseURL), "/"),
+		model:   strings.TrimSpace(model),
+		client:  http.DefaultClient,
+	}
+}
+
+func (p *AnthropicProvider) Name() string { return ProviderAnthropic }
+
+func (p *AnthropicProvider) Available() bool {
+	return p != nil && p.apiKey != ""
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicRequest struct {
+	Model     string             `json:"model"`
+	MaxTokens int                `json:"max_tokens"`
+	System    string             `json:"system,omitempty"`
+	Messages  []anthropicMessage `json:"messages"`
+}
+
+type anthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Usage *struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (p *AnthropicProvider) Complete(ctx context.Context, req CompleteRequest) (CompleteResponse, error) {
+	if !p.Available() {
+		return CompleteResponse{}, fmt.Errorf("anthropic provider unavailable: missing API key")
+	}
+
+	model := firstNonEmpty(req.Model, p.model, defaultAnthropicModel)
+	body, err := json.Marshal(anthropicRequest{
+		Model:     model,
+		MaxTokens: anthropicMaxTokens,
+		System:    req.System,
+		Messages:  []anthropicMessage{{Role: "user", Content: req.User}},
+	})
+	if err != nil {
+		return CompleteResponse{}, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/v1/messages", bytes.NewReader(body))
+	if err != nil {
+		return CompleteResponse{}, err
+	}
+	httpReq.Header.Set("content-type", "application/json")
+	httpReq.Header.Set("x-api-key", p.apiKey)
+	httpReq.Header.Set("anthropic-version", anthropicVersion)
+
+	httpResp, err := p.client.Do(httpReq)
+	if err != nil {
+		return CompleteResponse{}, fmt.Errorf("anthropic completion: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return CompleteResponse{}, fmt.Errorf("anthropic completion: %w", err)
+	}
+	var resp anthropicResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return CompleteResponse{}, fmt.Errorf("anthropic completion: status %d: %s", httpResp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if resp.Error != nil {
+		return CompleteResponse{}, fmt.Errorf("anthropic completion: %s: %s", resp.Error.Type, resp.Error.Message)
+	}
+	if httpResp.StatusCode/100 != 2 {
+		return CompleteResponse{}, fmt.Errorf("anthropic completion: status %d", httpResp.StatusCode)
+	}
+
+	var text strings.Builder
+	for _, c := range resp.Content {
+		if c.Type == "text" {
+			text.WriteString(c.Text)
+		}
+	}
+	if strings.TrimSpace(text.String()) == "" {
+		return CompleteResponse{}, fmt.Errorf("anthropic completion: empty response")
+	}
+	return CompleteResponse{
+		Text:     strings.TrimSpace(text.String()),
+		Provider: p.Name(),
+		Model:    model,
+		Usage:    resp.Usage,
+	}, nil
+}
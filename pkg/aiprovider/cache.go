@@ -59,13 +59,17 @@ func (p *cachedProvider) Complete(ctx context.Context, req CompleteRequest) (Com
 	if err != nil || resp.Fallback || strings.TrimSpace(resp.Text) == "" {
 		return resp, err
 	}
-	_ = p.save(key, cacheEntry{
+	_ = p.save(key, newCacheEntry(resp))
+	return resp, err
+}
+
+func newCacheEntry(resp CompleteResponse) cacheEntry {
+	return cacheEntry{
 		Text:     resp.Text,
 		Provider: resp.Provider,
 		Model:    resp.Model,
 		SavedAt:  time.Now(),
-	})
-	return resp, err
+	}
 }
 
 func cacheKey(req CompleteRequest) string {
@@ -13,30 +13,44 @@ type CommitCandidate struct {
 	Message string
 }
 
-const multiCandidateSystemPrompt = `Generate exactly 3 git commit message candidates for the provided diff.
+// DefaultCandidateCount is the number of commit message options generated when none is requested.
+const DefaultCandidateCount = 3
+
+// MaxCandidateCount caps how many options a single request may ask for.
+const MaxCandidateCount = 9
+
+const multiCandidateSystemPrompt = `Generate exactly %d git commit message candidates for the provided diff.
 Use present tense and conventional commit style where appropriate.
 
-Return exactly 3 lines in this format:
+Return exactly %d lines, one candidate per line, in this format:
 SHORT: <max 40 chars, minimal>
 MEDIUM: <max 72 chars, descriptive>
 CONVENTIONAL: <type>(optional scope): <message>
+ALT: <another wording with a different emphasis; repeat for further candidates>
 
+Use the styles in the order above and stop after %d lines.
 If the change is breaking, append ! after the type in CONVENTIONAL (e.g. feat!: ...).`
 
-var candidateLinePattern = regexp.MustCompile(`^(SHORT|MEDIUM|CONVENTIONAL):\s*(.+)$`)
+var candidateLinePattern = regexp.MustCompile(`^(SHORT|MEDIUM|CONVENTIONAL|ALT):\s*(.+)$`)
 
-// GenerateCommitCandidates asks the provider for 3 commit message options.
-func GenerateCommitCandidates(ctx context.Context, provider Provider, diff string) ([]CommitCandidate, error) {
+// GenerateCommitCandidates asks the provider for n commit message options (clamped to 1..MaxCandidateCount).
+// A non-empty guidance, e.g. a team prompt template, is appended as conventions every candidate must follow.
+func GenerateCommitCandidates(ctx context.Context, provider Provider, diff string, n int, guidance string) ([]CommitCandidate, error) {
+	n = max(1, min(n, MaxCandidateCount))
 	if provider == nil || !provider.Available() {
-		return ruleCommitCandidates(diff), nil
+		return ruleCommitCandidates(diff, n), nil
 	}
 
+	system := fmt.Sprintf(multiCandidateSystemPrompt, n, n, n)
+	if guidance = strings.TrimSpace(guidance); guidance != "" {
+		system += "\n\nFollow these conventions for every candidate, keeping the line format above:\n" + guidance
+	}
 	resp, err := provider.Complete(ctx, CompleteRequest{
-		System: multiCandidateSystemPrompt,
+		System: system,
 		User:   diff,
 	})
 	if err != nil || strings.TrimSpace(resp.Text) == "" {
-		return ruleCommitCandidates(diff), err
+		return ruleCommitCandidates(diff, n), err
 	}
 
 	candidates := parseCommitCandidates(resp.Text)
@@ -44,6 +58,9 @@ func GenerateCommitCandidates(ctx context.Context, provider Provider, diff strin
 		fallback := CommitMessageFromDiff(diff)
 		return []CommitCandidate{{Style: "fallback", Message: fallback}}, nil
 	}
+	if len(candidates) > n {
+		candidates = candidates[:n]
+	}
 	return candidates, nil
 }
 
@@ -66,13 +83,15 @@ func parseCommitCandidates(text string) []CommitCandidate {
 	return out
 }
 
-func ruleCommitCandidates(diff string) []CommitCandidate {
+// ruleCommitCandidates 规则 fallback 最多只能给出 3 种写法
+func ruleCommitCandidates(diff string, n int) []CommitCandidate {
 	msg := CommitMessageFromDiff(diff)
-	return []CommitCandidate{
+	candidates := []CommitCandidate{
 		{Style: "short", Message: truncateRunes(msg, 40)},
 		{Style: "medium", Message: truncateRunes(msg, 72)},
 		{Style: "conventional", Message: msg},
 	}
+	return candidates[:min(max(n, 1), len(candidates))]
 }
 
 // DetectBreakingChange heuristically flags potentially breaking diffs.
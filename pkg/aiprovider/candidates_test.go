@@ -1,6 +1,7 @@
 package aiprovider
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -9,9 +10,11 @@ import (
 func TestParseCommitCandidates(t *testing.T) {
 	text := `SHORT: quick fix
 MEDIUM: fix parser edge case in auth module
-CONVENTIONAL: fix(auth): handle empty token`
+CONVENTIONAL: fix(auth): handle empty token
+ALT: fix(auth): reject blank bearer tokens`
 	candidates := parseCommitCandidates(text)
-	require.Len(t, candidates, 3)
+	require.Len(t, candidates, 4)
+	require.Equal(t, "alt", candidates[3].Style)
 	require.Equal(t, "short", candidates[0].Style)
 	require.Equal(t, "fix(auth): handle empty token", candidates[2].Message)
 }
@@ -23,7 +26,16 @@ func TestDetectBreakingChange(t *testing.T) {
 
 func TestRuleCommitCandidates(t *testing.T) {
 	diff := "diff --git a/main.go b/main.go\n"
-	candidates := ruleCommitCandidates(diff)
+	candidates := ruleCommitCandidates(diff, 5)
 	require.Len(t, candidates, 3)
 	require.Contains(t, candidates[2].Message, "main.go")
+	require.Len(t, ruleCommitCandidates(diff, 2), 2)
+}
+
+func TestGenerateCommitCandidatesLimitsCount(t *testing.T) {
+	stub := &stubProvider{text: "SHORT: a\nMEDIUM: b\nCONVENTIONAL: fix: c\nALT: fix: d\nALT: fix: e"}
+	candidates, err := GenerateCommitCandidates(context.Background(), stub, "diff", 4, "")
+	require.NoError(t, err)
+	require.Len(t, candidates, 4)
+	require.Equal(t, "fix: d", candidates[3].Message)
 }
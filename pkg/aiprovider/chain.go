@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"strings"
+
+	"github.com/pubgo/fastgit/pkg/timing"
 )
 
 // Chain tries providers in order until one succeeds.
@@ -36,6 +38,7 @@ func (c *Chain) Complete(ctx context.Context, req CompleteRequest) (CompleteResp
 	}
 
 	var lastErr error
+	var skipped []string
 	for _, provider := range c.providers {
 		if provider == nil {
 			continue
@@ -43,13 +46,17 @@ func (c *Chain) Complete(ctx context.Context, req CompleteRequest) (CompleteResp
 		if !provider.Available() {
 			continue
 		}
+		done := timing.Track(ctx, timing.PhaseLLM, provider.Name())
 		resp, err := provider.Complete(ctx, req)
+		done()
 		if err == nil && strings.TrimSpace(resp.Text) != "" {
+			resp.Skipped = append(skipped, resp.Skipped...)
 			return resp, nil
 		}
 		if err != nil {
 			lastErr = err
 		}
+		skipped = append(skipped, provider.Name())
 	}
 
 	if lastErr != nil {
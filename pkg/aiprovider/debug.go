@@ -0,0 +1,114 @@
+package aiprovider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pubgo/funk/v2/log"
+
+	"github.com/pubgo/fastgit/pkg/gitdiff"
+)
+
+type debugProvider struct {
+	inner Provider
+	path  string
+	mu    sync.Mutex
+}
+
+// WithDebugLog wraps a provider so every completion is appended to the file at path:
+// the request exactly as sent (after redaction and truncation), its estimated token
+// count, the provider and model that answered, the latency, the usage reported by the
+// backend and the raw response text. It is meant for reproducing bad generations.
+func WithDebugLog(inner Provider, path string) Provider {
+	if inner == nil || path == "" {
+		return inner
+	}
+	return &debugProvider{inner: inner, path: path}
+}
+
+func (p *debugProvider) Name() string { return p.inner.Name() }
+
+func (p *debugProvider) Available() bool { return p.inner.Available() }
+
+func (p *debugProvider) Complete(ctx context.Context, req CompleteRequest) (CompleteResponse, error) {
+	start := time.Now()
+	resp, err := p.inner.Complete(ctx, req)
+	p.record(start, req, resp, err)
+	return resp, err
+}
+
+func (p *debugProvider) Stream(ctx context.Context, req CompleteRequest, onDelta func(string)) (CompleteResponse, error) {
+	start := time.Now()
+	resp, err := Stream(ctx, p.inner, req, onDelta)
+	p.record(start, req, resp, err)
+	return resp, err
+}
+
+// record appends one exchange; a failed write only warns so debugging never breaks generation.
+func (p *debugProvider) record(start time.Time, req CompleteRequest, resp CompleteResponse, err error) {
+	entry := formatDebugEntry(start, time.Since(start), req, resp, err)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	werr := os.MkdirAll(filepath.Dir(p.path), 0o755)
+	if werr == nil {
+		var f *os.File
+		if f, werr = os.OpenFile(p.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600); werr == nil {
+			_, werr = f.WriteString(entry)
+			if cerr := f.Close(); werr == nil {
+				werr = cerr
+			}
+		}
+	}
+	if werr != nil {
+		log.Warn().Err(werr).Str("path", p.path).Msg("failed to write LLM debug log")
+	}
+}
+
+func formatDebugEntry(start time.Time, latency time.Duration, req CompleteRequest, resp CompleteResponse, err error) string {
+	model := resp.Model
+	if model == "" {
+		model = req.Model
+	}
+	var b strings.Builder
+	_, _ = fmt.Fprintf(&b, "=== %s provider=%s model=%s latency=%s\n",
+		start.Format(time.RFC3339), orUnknown(resp.Provider), orUnknown(model), latency.Round(time.Millisecond))
+	_, _ = fmt.Fprintf(&b, "tokens: system=%d user=%d response=%d (estimated, cl100k)\n",
+		gitdiff.CountTokens(req.System), gitdiff.CountTokens(req.User), gitdiff.CountTokens(resp.Text))
+	if resp.Usage != nil {
+		if raw, jerr := json.Marshal(resp.Usage); jerr == nil && string(raw) != "null" {
+			_, _ = fmt.Fprintf(&b, "usage: %s\n", raw)
+		}
+	}
+	if resp.Fallback {
+		b.WriteString("fallback: rule-based\n")
+	}
+	if len(resp.Skipped) > 0 {
+		_, _ = fmt.Fprintf(&b, "skipped: %s\n", strings.Join(resp.Skipped, ", "))
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(&b, "error: %v\n", err)
+	}
+	for _, part := range []struct{ name, text string }{
+		{"system", req.System},
+		{"user", req.User},
+		{"response", resp.Text},
+	} {
+		_, _ = fmt.Fprintf(&b, "--- %s\n%s\n", part.name, strings.TrimRight(part.text, "\n"))
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+func orUnknown(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
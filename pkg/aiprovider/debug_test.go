@@ -0,0 +1,34 @@
+package aiprovider
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithDebugLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "llm-debug.log")
+	p := WithDebugLog(NewChain(NewOpenAI(nil), NewRuleFallback()), path)
+
+	var streamed string
+	resp, err := Stream(context.Background(), p, CompleteRequest{System: "write a commit message", User: "diff --git a/main.go b/main.go\n"}, func(d string) {
+		streamed += d
+	})
+	require.NoError(t, err)
+	require.Equal(t, resp.Text, streamed)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	out := string(data)
+	require.Contains(t, out, "=== ")
+	require.Contains(t, out, "latency=")
+	require.Contains(t, out, "fallback: rule-based")
+	require.Contains(t, out, "--- system\nwrite a commit message\n")
+	require.Contains(t, out, "--- user\ndiff --git a/main.go b/main.go\n")
+	require.Contains(t, out, "--- response\n"+resp.Text+"\n")
+
+	require.Nil(t, WithDebugLog(nil, path))
+}
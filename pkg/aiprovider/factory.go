@@ -5,20 +5,144 @@ import (
 	"strings"
 
 	"github.com/pubgo/fastgit/utils"
+	"github.com/pubgo/fastgit/utils/genaiclient"
 )
 
-// Default builds the standard provider chain: OpenAI-compatible API, then rule fallback.
-func Default(client *utils.OpenaiClient) Provider {
-	chain := NewChain(
-		NewOpenAI(client),
-		NewRuleFallback(),
-	)
+// Backends selectable via `openai.provider`.
+const (
+	ProviderOpenAI    = "openai"
+	ProviderGemini    = "gemini"
+	ProviderAnthropic = "anthropic"
+	ProviderOllama    = "ollama"
+)
+
+// Default builds the standard provider chain: the configured backend, then rule fallback.
+func Default(client *utils.OpenaiClient, gemini *genaiclient.Client) Provider {
+	var backends []Provider
+	if client != nil && client.Cfg != nil {
+		var geminiCfg *genaiclient.Config
+		if gemini != nil {
+			geminiCfg = gemini.Cfg
+		}
+		backends = Backends(client.Cfg, gemini, geminiCfg)
+		if backendName(client.Cfg.Provider) == ProviderOpenAI {
+			backends[0] = NewOpenAI(client)
+		}
+	} else {
+		backends = []Provider{NewOpenAI(client)}
+	}
+	chain := NewChain(append(backends, NewRuleFallback())...)
 	if cacheEnabled() {
 		return WithCache(chain)
 	}
 	return chain
 }
 
+// NewFromConfig returns the backend selected by cfg.Provider; api_key, base_url and model apply to the
+// OpenAI-compatible backend, ollama reads the `openai.ollama` section, anthropic the `openai.anthropic`
+// section and gemini uses the `genai` client.
+func NewFromConfig(cfg *utils.OpenaiConfig, gemini *genaiclient.Client) Provider {
+	if cfg == nil {
+		cfg = &utils.OpenaiConfig{}
+	}
+	switch backendName(cfg.Provider) {
+	case ProviderGemini:
+		return NewGemini(gemini)
+	case ProviderAnthropic:
+		// openai.base_url/model 缺省指向 OpenAI 兼容服务，anthropic 只读自己的配置段
+		ac := utils.AnthropicConfig{}
+		if cfg.Anthropic != nil {
+			ac = *cfg.Anthropic
+		}
+		p := NewAnthropic(ac.ApiKey, ac.BaseURL, ac.Model)
+		p.client = utils.NewHTTPClient(cfg.Proxy)
+		return p
+	case ProviderOllama:
+		return NewOllama(utils.NewOllamaClient(cfg.Ollama))
+	default:
+		return NewOpenAI(utils.NewOpenaiClient(cfg))
+	}
+}
+
+// Backends returns the primary backend of cfg followed by one backend per `openai.fallbacks` entry.
+// gemini serves the primary; a gemini fallback builds its own client from geminiCfg and its overrides.
+func Backends(cfg *utils.OpenaiConfig, gemini *genaiclient.Client, geminiCfg *genaiclient.Config) []Provider {
+	if cfg == nil {
+		cfg = &utils.OpenaiConfig{}
+	}
+	backends := []Provider{NewFromConfig(cfg, gemini)}
+	for _, fb := range cfg.Fallbacks {
+		backends = append(backends, newFallback(cfg, fb, geminiCfg))
+	}
+	return backends
+}
+
+func newFallback(primary *utils.OpenaiConfig, fb utils.FallbackConfig, geminiCfg *genaiclient.Config) Provider {
+	name := backendName(fb.Provider)
+	cfg := &utils.OpenaiConfig{
+		Provider: name,
+		ApiKey:   strings.TrimSpace(fb.ApiKey),
+		BaseURL:  strings.TrimSpace(fb.BaseURL),
+		Model:    strings.TrimSpace(fb.Model),
+		Retry:    primary.Retry,
+		Proxy:    primary.Proxy,
+	}
+	switch name {
+	case ProviderOllama:
+		// ollama 的默认地址与模型来自 openai.ollama，而非主后端的 base_url
+		oc := utils.OllamaConfig{}
+		if primary.Ollama != nil {
+			oc = *primary.Ollama
+		}
+		oc.BaseURL = firstNonEmpty(cfg.BaseURL, oc.BaseURL)
+		oc.Model = firstNonEmpty(cfg.Model, oc.Model)
+		cfg.Ollama = &oc
+		return NewFromConfig(cfg, nil)
+	case ProviderAnthropic:
+		ac := utils.AnthropicConfig{}
+		if primary.Anthropic != nil {
+			ac = *primary.Anthropic
+		}
+		ac.ApiKey = firstNonEmpty(cfg.ApiKey, ac.ApiKey)
+		ac.BaseURL = firstNonEmpty(cfg.BaseURL, ac.BaseURL)
+		ac.Model = firstNonEmpty(cfg.Model, ac.Model)
+		cfg.Anthropic = &ac
+		return NewFromConfig(cfg, nil)
+	case ProviderGemini:
+		gc := genaiclient.Config{}
+		if geminiCfg != nil {
+			gc = *geminiCfg
+		}
+		gc.ApiKey = firstNonEmpty(cfg.ApiKey, gc.ApiKey)
+		gc.BaseURL = firstNonEmpty(cfg.BaseURL, gc.BaseURL)
+		gc.Model = firstNonEmpty(cfg.Model, gc.Model)
+		gc.Proxy = firstNonEmpty(gc.Proxy, cfg.Proxy)
+		return NewFromConfig(cfg, genaiclient.New(&gc))
+	}
+	if name == backendName(primary.Provider) {
+		cfg.ApiKey = firstNonEmpty(cfg.ApiKey, primary.ApiKey)
+		cfg.BaseURL = firstNonEmpty(cfg.BaseURL, primary.BaseURL)
+	}
+	return NewFromConfig(cfg, nil)
+}
+
+func backendName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return ProviderOpenAI
+	}
+	return name
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
 func cacheEnabled() bool {
 	v := strings.ToLower(strings.TrimSpace(os.Getenv("FASTGIT_AI_CACHE")))
 	return v == "1" || v == "true" || v == "yes"
@@ -0,0 +1,136 @@
+package aiprovider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pubgo/fastgit/utils"
+	"github.com/pubgo/fastgit/utils/genaiclient"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFromConfigSelectsBackend(t *testing.T) {
+	cases := map[string]string{
+		"":          ProviderOpenAI,
+		"OpenAI":    ProviderOpenAI,
+		"gemini":    ProviderGemini,
+		"anthropic": ProviderAnthropic,
+		"ollama":    ProviderOllama,
+	}
+	for provider, want := range cases {
+		cfg := &utils.OpenaiConfig{Provider: provider, ApiKey: "k", Anthropic: &utils.AnthropicConfig{ApiKey: "k"}}
+		p := NewFromConfig(cfg, genaiclient.New(&genaiclient.Config{ApiKey: "k"}))
+		require.Equal(t, want, p.Name(), provider)
+		require.True(t, p.Available(), provider)
+	}
+
+	// ollama 不需要 key
+	require.True(t, NewFromConfig(&utils.OpenaiConfig{Provider: "ollama"}, nil).Available())
+	require.False(t, NewFromConfig(&utils.OpenaiConfig{Provider: "anthropic"}, nil).Available())
+	t.Setenv("GEMINI_API_KEY", "")
+	t.Setenv("GOOGLE_API_KEY", "")
+	require.False(t, NewFromConfig(&utils.OpenaiConfig{Provider: "gemini"}, genaiclient.New(nil)).Available())
+}
+
+func TestAnthropicComplete(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/messages", r.URL.Path)
+		require.Equal(t, "sk-ant", r.Header.Get("x-api-key"))
+
+		var req anthropicRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, "system", req.System)
+		require.Equal(t, "claude-test", req.Model)
+		require.Equal(t, "diff", req.Messages[0].Content)
+
+		_, _ = w.Write([]byte(`{"content":[{"type":"text","text":" feat: add x \n"}],"usage":{"input_tokens":3,"output_tokens":4}}`))
+	}))
+	defer srv.Close()
+
+	p := NewAnthropic("sk-ant", srv.URL, "claude-test")
+	resp, err := p.Complete(context.Background(), CompleteRequest{System: "system", User: "diff"})
+	require.NoError(t, err)
+	require.Equal(t, "feat: add x", resp.Text)
+	require.Equal(t, ProviderAnthropic, resp.Provider)
+}
+
+func TestAnthropicCompleteError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"type":"error","error":{"type":"rate_limit_error","message":"slow down"}}`))
+	}))
+	defer srv.Close()
+
+	_, err := NewAnthropic("sk-ant", srv.URL, "").Complete(context.Background(), CompleteRequest{User: "diff"})
+	require.ErrorContains(t, err, "rate_limit_error")
+}
+
+func TestAnthropicIgnoresOpenAISettings(t *testing.T) {
+	p := NewFromConfig(&utils.OpenaiConfig{
+		Provider: "anthropic",
+		ApiKey:   "sk-deepseek",
+		BaseURL:  "https://api.deepseek.com/v1",
+		Model:    "deepseek-chat",
+	}, nil).(*AnthropicProvider)
+	require.False(t, p.Available(), "the OpenAI key is not sent to Anthropic")
+	require.Equal(t, defaultAnthropicBaseURL, p.baseURL)
+	require.Empty(t, p.model)
+
+	p = NewFromConfig(&utils.OpenaiConfig{
+		Provider:  "anthropic",
+		Model:     "deepseek-chat",
+		Anthropic: &utils.AnthropicConfig{ApiKey: "sk-ant", Model: "claude-test"},
+	}, nil).(*AnthropicProvider)
+	require.True(t, p.Available())
+	require.Equal(t, defaultAnthropicBaseURL, p.baseURL)
+	require.Equal(t, "claude-test", p.model)
+}
+
+func TestBackendsAppendFallbacks(t *testing.T) {
+	cfg := &utils.OpenaiConfig{
+		ApiKey: "k",
+		Model:  "gpt-4o-mini",
+		Ollama: &utils.OllamaConfig{BaseURL: "http://ollama:11434", Model: "qwen"},
+		Fallbacks: []utils.FallbackConfig{
+			{Model: "gpt-4o"},
+			{Provider: "ollama"},
+			{Provider: "anthropic", Model: "claude"},
+		},
+	}
+	backends := Backends(cfg, nil, nil)
+	require.Len(t, backends, 4)
+
+	second := backends[1].(*OpenAIProvider)
+	require.Equal(t, "k", second.client.Cfg.ApiKey, "same provider inherits the primary key")
+	require.Equal(t, "gpt-4o", second.client.Cfg.Model)
+
+	ollama := backends[2].(*OllamaProvider)
+	require.Equal(t, "http://ollama:11434", ollama.client.Cfg.BaseURL)
+	require.Equal(t, "qwen", ollama.client.Cfg.Model)
+
+	require.Equal(t, ProviderAnthropic, backends[3].Name())
+	require.False(t, backends[3].Available(), "other providers do not inherit the primary key")
+}
+
+func TestChainReportsSkippedProviders(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":{"message":"model not found"}}`))
+	}))
+	defer srv.Close()
+
+	primary := NewOpenAI(utils.NewOpenaiClient(&utils.OpenaiConfig{ApiKey: "k", BaseURL: srv.URL}))
+	chain := NewChain(primary, &stubProvider{text: "feat: fallback"})
+
+	resp, err := chain.Complete(context.Background(), CompleteRequest{User: "diff"})
+	require.NoError(t, err)
+	require.Equal(t, "feat: fallback", resp.Text)
+	require.Equal(t, []string{ProviderOpenAI}, resp.Skipped)
+
+	resp, err = chain.Stream(context.Background(), CompleteRequest{User: "diff"}, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{ProviderOpenAI}, resp.Skipped)
+}
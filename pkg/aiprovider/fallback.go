@@ -2,13 +2,9 @@ package aiprovider
 
 import (
 	"context"
-	"fmt"
-	"regexp"
 	"strings"
 )
 
-var diffFilePattern = regexp.MustCompile(`(?m)^diff --git a/(.+?) b/`)
-
 // RuleFallback generates deterministic text when no AI provider is available.
 type RuleFallback struct{}
 
@@ -28,41 +24,10 @@ func (p *RuleFallback) Complete(ctx context.Context, req CompleteRequest) (Compl
 	}, nil
 }
 
-// CommitMessageFromDiff builds a conventional-style message from a git diff.
+// CommitMessageFromDiff builds a conventional-style message from a git diff, see
+// HeuristicCommitMessage.
 func CommitMessageFromDiff(diff string) string {
-	files := filesFromDiff(diff)
-	switch len(files) {
-	case 0:
-		return "chore: update changes"
-	case 1:
-		return fmt.Sprintf("chore: update %s", trimPath(files[0]))
-	default:
-		return fmt.Sprintf("chore: update %d files", len(files))
-	}
-}
-
-func filesFromDiff(diff string) []string {
-	matches := diffFilePattern.FindAllStringSubmatch(diff, -1)
-	if len(matches) == 0 {
-		return nil
-	}
-	seen := make(map[string]struct{}, len(matches))
-	var files []string
-	for _, match := range matches {
-		if len(match) < 2 {
-			continue
-		}
-		file := strings.TrimSpace(match[1])
-		if file == "" {
-			continue
-		}
-		if _, ok := seen[file]; ok {
-			continue
-		}
-		seen[file] = struct{}{}
-		files = append(files, file)
-	}
-	return files
+	return HeuristicCommitMessage(diff)
 }
 
 func trimPath(path string) string {
@@ -21,7 +21,7 @@ index 111..222 100644
 --- a/pkg/b.go
 +++ b/pkg/b.go
 `
-	require.Equal(t, "chore: update 2 files", CommitMessageFromDiff(multi))
+	require.Equal(t, "chore: update 2 files in pkg", CommitMessageFromDiff(multi))
 }
 
 func TestChainUsesFallbackWhenOpenAIUnavailable(t *testing.T) {
@@ -0,0 +1,46 @@
+package aiprovider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pubgo/fastgit/utils/genaiclient"
+)
+
+// GeminiProvider implements Provider using the Google Gemini API.
+type GeminiProvider struct {
+	client *genaiclient.Client
+}
+
+// NewGemini wraps an existing genaiclient.Client.
+func NewGemini(client *genaiclient.Client) *GeminiProvider {
+	return &GeminiProvider{client: client}
+}
+
+func (p *GeminiProvider) Name() string { return ProviderGemini }
+
+func (p *GeminiProvider) Available() bool {
+	return p != nil && p.client.Available()
+}
+
+func (p *GeminiProvider) Complete(ctx context.Context, req CompleteRequest) (CompleteResponse, error) {
+	if !p.Available() {
+		return CompleteResponse{}, fmt.Errorf("gemini provider unavailable: missing API key")
+	}
+
+	resp, err := p.client.Generate(ctx, req.Model, req.System, req.User)
+	if err != nil {
+		return CompleteResponse{}, err
+	}
+	text := strings.TrimSpace(resp.Text)
+	if text == "" {
+		return CompleteResponse{}, fmt.Errorf("gemini completion: empty response")
+	}
+	return CompleteResponse{
+		Text:     text,
+		Provider: p.Name(),
+		Model:    resp.Model,
+		Usage:    resp.Usage,
+	}, nil
+}
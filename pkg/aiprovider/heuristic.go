@@ -0,0 +1,315 @@
+package aiprovider
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// heuristicMaxLength keeps offline subjects within the default commit.max_length.
+const heuristicMaxLength = 72
+
+// symbolPattern matches added or removed declarations: Go funcs/methods/types,
+// Python/Ruby def/class, JS/TS functions and classes, Rust fns/structs.
+var symbolPattern = regexp.MustCompile(`^[+-]\s*(?:export\s+)?(?:default\s+)?(?:pub(?:\([^)]*\))?\s+)?(?:async\s+)?` +
+	`(?:func\s+(?:\([^)]*\)\s*)?|type\s+|def\s+|class\s+|function\*?\s+|fn\s+|struct\s+|enum\s+|trait\s+|interface\s+)` +
+	`([A-Za-z_]\w*)`)
+
+// fileChange is one file of a diff as seen by the heuristic generator.
+type fileChange struct {
+	path    string
+	oldPath string
+	added   int
+	removed int
+	created bool
+	deleted bool
+	// symbols declared on + and - lines, in diff order
+	addedSymbols   []string
+	removedSymbols []string
+}
+
+func (f fileChange) renamed() bool { return f.oldPath != "" && f.oldPath != f.path }
+
+// parseDiffFiles splits a unified diff into per-file changes.
+func parseDiffFiles(diff string) []fileChange {
+	var (
+		files   []fileChange
+		current *fileChange
+	)
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			files = append(files, fileChange{})
+			current = &files[len(files)-1]
+			if idx := strings.LastIndex(line, " b/"); idx >= 0 {
+				// "(excluded)" / "(omitted ...)" markers from the diff collector follow the path
+				current.path, _, _ = strings.Cut(line[idx+3:], " (")
+			}
+		case current == nil:
+		case strings.HasPrefix(line, "new file mode"):
+			current.created = true
+		case strings.HasPrefix(line, "deleted file mode"):
+			current.deleted = true
+		case strings.HasPrefix(line, "rename from "):
+			current.oldPath = strings.TrimPrefix(line, "rename from ")
+		case strings.HasPrefix(line, "rename to "):
+			current.path = strings.TrimPrefix(line, "rename to ")
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+		case strings.HasPrefix(line, "+"):
+			current.added++
+			if m := symbolPattern.FindStringSubmatch(line); len(m) == 2 {
+				current.addedSymbols = append(current.addedSymbols, m[1])
+			}
+		case strings.HasPrefix(line, "-"):
+			current.removed++
+			if m := symbolPattern.FindStringSubmatch(line); len(m) == 2 {
+				current.removedSymbols = append(current.removedSymbols, m[1])
+			}
+		}
+	}
+	return files
+}
+
+// HeuristicCommitMessage writes a conventional commit message for diff without a
+// model: the type comes from the kind of files touched (docs, tests, CI, build,
+// new code), the subject from renamed files or symbols, added or removed
+// declarations, and otherwise the diffstat.
+func HeuristicCommitMessage(diff string) string {
+	files := parseDiffFiles(diff)
+	if len(files) == 0 {
+		return "chore: update changes"
+	}
+	return truncateRunes(heuristicType(files)+": "+heuristicSubject(files), heuristicMaxLength)
+}
+
+func heuristicType(files []fileChange) string {
+	switch {
+	case allFiles(files, isDocFile):
+		return "docs"
+	case allFiles(files, isTestFile):
+		return "test"
+	case allFiles(files, isCIFile):
+		return "ci"
+	case allFiles(files, isBuildFile):
+		return "build"
+	}
+
+	var created, deleted, renamed, changed, symbolsAdded, symbolsRemoved int
+	for _, f := range files {
+		switch {
+		case f.created:
+			created++
+		case f.deleted:
+			deleted++
+		case f.renamed() && f.added == 0 && f.removed == 0:
+			renamed++
+		default:
+			changed++
+		}
+		symbolsAdded += len(f.addedSymbols)
+		symbolsRemoved += len(f.removedSymbols)
+	}
+	switch {
+	case renamed > 0 && created+deleted+changed == 0:
+		return "refactor"
+	case len(symbolRenames(files)) > 0 && symbolsAdded == symbolsRemoved:
+		return "refactor"
+	case created > 0 && deleted == 0 && !allFiles(filterFiles(files, func(f fileChange) bool { return f.created }), isSupportFile):
+		return "feat"
+	case symbolsAdded > 0 && symbolsRemoved == 0 && deleted == 0:
+		return "feat"
+	case deleted > 0 && created == 0 && changed == 0:
+		return "refactor"
+	}
+	return "chore"
+}
+
+func heuristicSubject(files []fileChange) string {
+	if len(files) == 1 && files[0].renamed() {
+		return fmt.Sprintf("rename %s to %s", trimPath(files[0].oldPath), trimPath(files[0].path))
+	}
+	if renames := symbolRenames(files); len(renames) > 0 {
+		return "rename " + joinLimited(renames, 2)
+	}
+
+	var added, removed []string
+	for _, f := range files {
+		added = append(added, f.addedSymbols...)
+		removed = append(removed, f.removedSymbols...)
+	}
+	added, removed = subtract(added, removed), subtract(removed, added)
+	onlyCreated := allFiles(files, func(f fileChange) bool { return f.created })
+	onlyDeleted := allFiles(files, func(f fileChange) bool { return f.deleted })
+
+	switch {
+	case onlyCreated && len(files) == 1:
+		return "add " + trimPath(files[0].path)
+	case onlyDeleted && len(files) == 1:
+		return "remove " + trimPath(files[0].path)
+	case len(added) > 0 && len(removed) == 0:
+		return "add " + joinLimited(added, 3)
+	case len(removed) > 0 && len(added) == 0:
+		return "remove " + joinLimited(removed, 3)
+	case onlyCreated:
+		return fmt.Sprintf("add %d files%s", len(files), inDir(files))
+	case onlyDeleted:
+		return fmt.Sprintf("remove %d files%s", len(files), inDir(files))
+	case len(files) == 1:
+		return "update " + trimPath(files[0].path)
+	}
+	return fmt.Sprintf("update %d files%s", len(files), inDir(files))
+}
+
+// symbolRenames pairs a single removed declaration with a single added one in the
+// same file, e.g. "OldName to NewName".
+func symbolRenames(files []fileChange) []string {
+	var renames []string
+	for _, f := range files {
+		added, removed := subtract(f.addedSymbols, f.removedSymbols), subtract(f.removedSymbols, f.addedSymbols)
+		if len(added) == 1 && len(removed) == 1 {
+			renames = append(renames, removed[0]+" to "+added[0])
+		}
+	}
+	return renames
+}
+
+// inDir names the deepest directory shared by all files, if any.
+func inDir(files []fileChange) string {
+	dir := path.Dir(files[0].path)
+	for _, f := range files[1:] {
+		for dir != "." && dir != "/" && f.path != dir && !strings.HasPrefix(f.path, dir+"/") {
+			dir = path.Dir(dir)
+		}
+	}
+	if dir == "." || dir == "/" {
+		return ""
+	}
+	return " in " + trimPath(dir)
+}
+
+// subtract returns the distinct items of a that are not in b, keeping their order.
+func subtract(a, b []string) []string {
+	drop := make(map[string]bool, len(b))
+	for _, s := range b {
+		drop[s] = true
+	}
+	var out []string
+	for _, s := range a {
+		if !drop[s] {
+			drop[s] = true
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func joinLimited(items []string, n int) string {
+	if len(items) <= n {
+		return strings.Join(items, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(items[:n], ", "), len(items)-n)
+}
+
+func allFiles(files []fileChange, match func(fileChange) bool) bool {
+	if len(files) == 0 {
+		return false
+	}
+	for _, f := range files {
+		if !match(f) {
+			return false
+		}
+	}
+	return true
+}
+
+func filterFiles(files []fileChange, keep func(fileChange) bool) []fileChange {
+	var out []fileChange
+	for _, f := range files {
+		if keep(f) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+var (
+	docExts    = []string{".md", ".mdx", ".rst", ".adoc", ".txt"}
+	docNames   = []string{"LICENSE", "NOTICE", "AUTHORS", "CONTRIBUTORS"}
+	buildNames = []string{
+		"go.mod", "go.sum", "go.work", "go.work.sum", "Makefile", "Dockerfile", "Taskfile.yml",
+		"package.json", "package-lock.json", "yarn.lock", "pnpm-lock.yaml", "Cargo.toml", "Cargo.lock",
+		"pyproject.toml", "poetry.lock", "requirements.txt", "setup.py", "build.gradle", "pom.xml",
+		".goreleaser.yml", ".goreleaser.yaml",
+	}
+)
+
+func isDocFile(f fileChange) bool {
+	base := path.Base(f.path)
+	if strings.HasPrefix(f.path, "docs/") || strings.Contains(f.path, "/docs/") {
+		return true
+	}
+	if strings.HasPrefix(base, "requirements") {
+		return false
+	}
+	for _, name := range docNames {
+		if strings.TrimSuffix(base, path.Ext(base)) == name {
+			return true
+		}
+	}
+	return hasExt(base, docExts)
+}
+
+func isTestFile(f fileChange) bool {
+	base := path.Base(f.path)
+	switch {
+	case strings.HasSuffix(base, "_test.go"), strings.HasPrefix(base, "test_") && strings.HasSuffix(base, ".py"),
+		strings.HasSuffix(base, "_test.py"), strings.Contains(base, ".test."), strings.Contains(base, ".spec."),
+		strings.HasSuffix(base, "_spec.rb"):
+		return true
+	}
+	for _, dir := range []string{"testdata/", "tests/", "__tests__/", "test/"} {
+		if strings.HasPrefix(f.path, dir) || strings.Contains(f.path, "/"+dir) {
+			return true
+		}
+	}
+	return false
+}
+
+func isCIFile(f fileChange) bool {
+	for _, prefix := range []string{".github/workflows/", ".github/actions/", ".circleci/", ".buildkite/"} {
+		if strings.HasPrefix(f.path, prefix) {
+			return true
+		}
+	}
+	switch path.Base(f.path) {
+	case ".gitlab-ci.yml", ".travis.yml", "Jenkinsfile", "azure-pipelines.yml", ".drone.yml":
+		return true
+	}
+	return false
+}
+
+func isBuildFile(f fileChange) bool {
+	base := path.Base(f.path)
+	for _, name := range buildNames {
+		if base == name {
+			return true
+		}
+	}
+	return strings.HasPrefix(base, "Dockerfile") || strings.HasPrefix(base, "requirements") && strings.HasSuffix(base, ".txt")
+}
+
+// isSupportFile reports files that accompany a change rather than make it a feature.
+func isSupportFile(f fileChange) bool {
+	return isDocFile(f) || isTestFile(f) || isCIFile(f) || isBuildFile(f)
+}
+
+func hasExt(base string, exts []string) bool {
+	ext := strings.ToLower(path.Ext(base))
+	for _, e := range exts {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package aiprovider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeuristicCommitMessage(t *testing.T) {
+	cases := []struct {
+		name string
+		diff string
+		want string
+	}{
+		{
+			name: "docs",
+			diff: "diff --git a/README.md b/README.md\n+more\ndiff --git a/docs/usage.txt b/docs/usage.txt\n+x\n",
+			want: "docs: update 2 files",
+		},
+		{
+			name: "tests",
+			diff: "diff --git a/pkg/a/a_test.go b/pkg/a/a_test.go\n+func TestA(t *testing.T) {}\n",
+			want: "test: add TestA",
+		},
+		{
+			name: "ci",
+			diff: "diff --git a/.github/workflows/go.yml b/.github/workflows/go.yml\n+  - run: go test\n",
+			want: "ci: update .github/workflows/go.yml",
+		},
+		{
+			name: "build",
+			diff: "diff --git a/go.mod b/go.mod\n-require x v1\n+require x v2\ndiff --git a/go.sum b/go.sum\n+x v2 h1:\n",
+			want: "build: update 2 files",
+		},
+		{
+			name: "new source file",
+			diff: "diff --git a/pkg/cache/cache.go b/pkg/cache/cache.go\nnew file mode 100644\n+package cache\n+type Store struct{}\n",
+			want: "feat: add pkg/cache/cache.go",
+		},
+		{
+			name: "added declarations",
+			diff: "diff --git a/pkg/a.go b/pkg/a.go\n+func Load() {}\n+func (s *Store) Save() error {\ndiff --git a/pkg/b.go b/pkg/b.go\n+\treturn nil\n",
+			want: "feat: add Load, Save",
+		},
+		{
+			name: "renamed symbol",
+			diff: "diff --git a/pkg/a.go b/pkg/a.go\n-func OldName() {}\n+func NewName() {}\n",
+			want: "refactor: rename OldName to NewName",
+		},
+		{
+			name: "renamed file",
+			diff: "diff --git a/old.go b/new.go\nsimilarity index 100%\nrename from old.go\nrename to new.go\n",
+			want: "refactor: rename old.go to new.go",
+		},
+		{
+			name: "removed file",
+			diff: "diff --git a/pkg/legacy.go b/pkg/legacy.go\ndeleted file mode 100644\n-package pkg\n-func Legacy() {}\n",
+			want: "refactor: remove pkg/legacy.go",
+		},
+		{
+			name: "signature change",
+			diff: "diff --git a/pkg/a.go b/pkg/a.go\n-func Load() {}\n+func Load(ctx context.Context) {}\n",
+			want: "chore: update pkg/a.go",
+		},
+		{
+			name: "collector markers",
+			diff: "diff --git a/pkg/x/a.go b/pkg/x/a.go\n+x\ndiff --git a/pkg/x/b.go b/pkg/x/b.go (excluded)\n",
+			want: "chore: update 2 files in pkg/x",
+		},
+		{
+			name: "no diff",
+			diff: "summary of the change",
+			want: "chore: update changes",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, HeuristicCommitMessage(tc.diff))
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package aiprovider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pubgo/fastgit/utils"
+)
+
+// OllamaProvider implements Provider using a local Ollama server.
+type OllamaProvider struct {
+	client *utils.OllamaClient
+}
+
+// NewOllama wraps an existing OllamaClient.
+func NewOllama(client *utils.OllamaClient) *OllamaProvider {
+	return &OllamaProvider{client: client}
+}
+
+func (p *OllamaProvider) Name() string { return ProviderOllama }
+
+// Available 本地服务无需 key，是否可达交给 Complete 报错后由 Chain 降级
+func (p *OllamaProvider) Available() bool {
+	return p != nil && p.client != nil
+}
+
+func (p *OllamaProvider) Complete(ctx context.Context, req CompleteRequest) (CompleteResponse, error) {
+	if !p.Available() {
+		return CompleteResponse{}, fmt.Errorf("ollama provider unavailable")
+	}
+
+	resp, err := p.client.Chat(ctx, req.Model, ollamaMessages(req))
+	if err != nil {
+		return CompleteResponse{}, err
+	}
+	return ollamaResponse(p.Name(), resp)
+}
+
+func ollamaMessages(req CompleteRequest) []utils.OllamaMessage {
+	var messages []utils.OllamaMessage
+	if strings.TrimSpace(req.System) != "" {
+		messages = append(messages, utils.OllamaMessage{Role: "system", Content: req.System})
+	}
+	return append(messages, utils.OllamaMessage{Role: "user", Content: req.User})
+}
+
+func ollamaResponse(name string, resp utils.OllamaChatResponse) (CompleteResponse, error) {
+	text := strings.TrimSpace(resp.Message.Content)
+	if text == "" {
+		return CompleteResponse{}, fmt.Errorf("ollama completion: empty response")
+	}
+
+	out := CompleteResponse{Text: text, Provider: name, Model: resp.Model}
+	if resp.PromptEvalCount > 0 || resp.EvalCount > 0 {
+		out.Usage = map[string]int{"prompt_tokens": resp.PromptEvalCount, "completion_tokens": resp.EvalCount}
+	}
+	return out, nil
+}
@@ -12,14 +12,15 @@ import (
 // OpenAIProvider implements Provider using an OpenAI-compatible API.
 type OpenAIProvider struct {
 	client *utils.OpenaiClient
+	name   string
 }
 
 // NewOpenAI wraps an existing OpenaiClient.
 func NewOpenAI(client *utils.OpenaiClient) *OpenAIProvider {
-	return &OpenAIProvider{client: client}
+	return &OpenAIProvider{client: client, name: ProviderOpenAI}
 }
 
-func (p *OpenAIProvider) Name() string { return "openai" }
+func (p *OpenAIProvider) Name() string { return p.name }
 
 func (p *OpenAIProvider) Available() bool {
 	return p != nil &&
@@ -28,9 +29,13 @@ func (p *OpenAIProvider) Available() bool {
 		strings.TrimSpace(p.client.Cfg.ApiKey) != ""
 }
 
+func (p *OpenAIProvider) retryPolicy() RetryPolicy {
+	return NewRetryPolicy(p.client.Cfg.Retry)
+}
+
 func (p *OpenAIProvider) Complete(ctx context.Context, req CompleteRequest) (CompleteResponse, error) {
 	if !p.Available() {
-		return CompleteResponse{}, fmt.Errorf("openai provider unavailable: missing API key")
+		return CompleteResponse{}, fmt.Errorf("%s provider unavailable: missing API key", p.Name())
 	}
 
 	model := strings.TrimSpace(req.Model)
@@ -38,18 +43,26 @@ func (p *OpenAIProvider) Complete(ctx context.Context, req CompleteRequest) (Com
 		model = strings.TrimSpace(p.client.Cfg.Model)
 	}
 
-	resp, err := p.client.Client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
-		Model: model,
-		Messages: []openai.ChatCompletionMessage{
-			{Role: openai.ChatMessageRoleSystem, Content: req.System},
-			{Role: openai.ChatMessageRoleUser, Content: req.User},
-		},
+	policy := p.retryPolicy()
+	resp, err := withRetry(ctx, policy, func(ctx context.Context) (openai.ChatCompletionResponse, error) {
+		if policy.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, policy.Timeout)
+			defer cancel()
+		}
+		return p.client.Client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+			Model: model,
+			Messages: []openai.ChatCompletionMessage{
+				{Role: openai.ChatMessageRoleSystem, Content: req.System},
+				{Role: openai.ChatMessageRoleUser, Content: req.User},
+			},
+		})
 	})
 	if err != nil {
-		return CompleteResponse{}, fmt.Errorf("openai completion: %w", err)
+		return CompleteResponse{}, fmt.Errorf("%s completion: %w", p.Name(), err)
 	}
 	if len(resp.Choices) == 0 {
-		return CompleteResponse{}, fmt.Errorf("openai completion: empty response")
+		return CompleteResponse{}, fmt.Errorf("%s completion: empty response", p.Name())
 	}
 
 	return CompleteResponse{
@@ -23,4 +23,6 @@ type CompleteResponse struct {
 	Model    string
 	Usage    any
 	Fallback bool
+	// Skipped names the providers of a Chain that failed before this one answered.
+	Skipped []string
 }
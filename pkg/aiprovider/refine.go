@@ -0,0 +1,14 @@
+package aiprovider
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RefineRequest builds a follow-up request that rewrites previous according to instruction.
+// The original system prompt and diff are kept so the model still sees the change itself.
+func RefineRequest(base CompleteRequest, previous, instruction string) CompleteRequest {
+	user := fmt.Sprintf("%s\n\n---\nPrevious commit message:\n%s\n\nRevise it: %s\nReturn only the new commit message.",
+		base.User, strings.TrimSpace(previous), strings.TrimSpace(instruction))
+	return CompleteRequest{System: base.System, User: user, Model: base.Model}
+}
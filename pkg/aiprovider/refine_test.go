@@ -0,0 +1,15 @@
+package aiprovider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefineRequestKeepsDiffAndPrompt(t *testing.T) {
+	req := RefineRequest(CompleteRequest{System: "sys", User: "diff", Model: "m"}, " feat: add x \n", "make it shorter")
+	require.Equal(t, "sys", req.System)
+	require.Equal(t, "m", req.Model)
+	require.Contains(t, req.User, "diff\n\n---\nPrevious commit message:\nfeat: add x\n")
+	require.Contains(t, req.User, "Revise it: make it shorter")
+}
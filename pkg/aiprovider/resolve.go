@@ -8,31 +8,55 @@ import (
 
 	"github.com/pubgo/fastgit/configs"
 	"github.com/pubgo/fastgit/utils"
+	"github.com/pubgo/fastgit/utils/genaiclient"
 	"gopkg.in/yaml.v3"
 )
 
 type openAIConfigFile struct {
 	Openai *utils.OpenaiConfig `yaml:"openai"`
+	Genai  *genaiclient.Config `yaml:"genai"`
 }
 
 // OpenAIProviderFromConfig loads OpenAI settings from the fastgit config file and env.
 func OpenAIProviderFromConfig() *OpenAIProvider {
+	cfg, _ := loadAIConfig()
+	return NewOpenAI(utils.NewOpenaiClient(cfg))
+}
+
+// ProviderFromConfig returns the backend selected by `openai.provider` (or FASTGIT_AI_PROVIDER),
+// followed by the `openai.fallbacks` backends.
+func ProviderFromConfig() Provider {
+	cfg, gemini := loadAIConfig()
+	return NewChain(Backends(cfg, genaiclient.New(gemini), gemini)...)
+}
+
+func loadAIConfig() (*utils.OpenaiConfig, *genaiclient.Config) {
 	cfg := &utils.OpenaiConfig{
-		ApiKey:  strings.TrimSpace(os.Getenv("OPENAI_API_KEY")),
-		BaseURL: strings.TrimSpace(os.Getenv("OPENAI_BASE_URL")),
-		Model:   strings.TrimSpace(os.Getenv("OPENAI_MODEL")),
+		Provider: strings.TrimSpace(os.Getenv("FASTGIT_AI_PROVIDER")),
+		ApiKey:   strings.TrimSpace(os.Getenv("OPENAI_API_KEY")),
+		BaseURL:  strings.TrimSpace(os.Getenv("OPENAI_BASE_URL")),
+		Model:    strings.TrimSpace(os.Getenv("OPENAI_MODEL")),
+		Anthropic: &utils.AnthropicConfig{
+			ApiKey:  strings.TrimSpace(os.Getenv("ANTHROPIC_API_KEY")),
+			BaseURL: strings.TrimSpace(os.Getenv("ANTHROPIC_BASE_URL")),
+			Model:   strings.TrimSpace(os.Getenv("ANTHROPIC_MODEL")),
+		},
 	}
 
+	var gemini *genaiclient.Config
 	configPath := configs.GetConfigPath()
 	if data, err := os.ReadFile(configPath); err == nil {
 		var file openAIConfigFile
-		if err := yaml.Unmarshal(data, &file); err == nil && file.Openai != nil {
-			merged := mergeOpenAIConfig(cfg, file.Openai)
-			cfg = &merged
+		// 默认配置以 ${ENV} 引用环境变量，与 config.Load 保持一致
+		if err := yaml.Unmarshal([]byte(os.ExpandEnv(string(data))), &file); err == nil {
+			if file.Openai != nil {
+				merged := mergeOpenAIConfig(cfg, file.Openai)
+				cfg = &merged
+			}
+			gemini = file.Genai
 		}
 	}
-
-	return NewOpenAI(utils.NewOpenaiClient(cfg))
+	return cfg, gemini
 }
 
 func mergeOpenAIConfig(base, from *utils.OpenaiConfig) utils.OpenaiConfig {
@@ -43,6 +67,9 @@ func mergeOpenAIConfig(base, from *utils.OpenaiConfig) utils.OpenaiConfig {
 	if from == nil {
 		return out
 	}
+	if strings.TrimSpace(from.Provider) != "" {
+		out.Provider = from.Provider
+	}
 	if strings.TrimSpace(from.ApiKey) != "" {
 		out.ApiKey = from.ApiKey
 	}
@@ -52,20 +79,38 @@ func mergeOpenAIConfig(base, from *utils.OpenaiConfig) utils.OpenaiConfig {
 	if strings.TrimSpace(from.Model) != "" {
 		out.Model = from.Model
 	}
+	if from.Ollama != nil {
+		out.Ollama = from.Ollama
+	}
+	if from.Anthropic != nil {
+		out.Anthropic = from.Anthropic
+	}
+	if from.Retry != nil {
+		out.Retry = from.Retry
+	}
+	if strings.TrimSpace(from.Proxy) != "" {
+		out.Proxy = from.Proxy
+	}
+	if len(from.Fallbacks) > 0 {
+		out.Fallbacks = from.Fallbacks
+	}
 	return out
 }
 
-// ResolveProvider picks a provider chain by name: auto|openai|copilot.
+// ResolveProvider picks a provider chain by name: auto|openai|gemini|anthropic|ollama|copilot.
+// auto uses the configured backend, then Copilot.
 func ResolveProvider(name, workingDir string) Provider {
 	var provider Provider
-	switch strings.ToLower(strings.TrimSpace(name)) {
-	case "openai":
-		provider = NewChain(OpenAIProviderFromConfig(), NewRuleFallback())
+	switch n := strings.ToLower(strings.TrimSpace(name)); n {
+	case ProviderOpenAI, ProviderGemini, ProviderAnthropic, ProviderOllama:
+		cfg, gemini := loadAIConfig()
+		cfg.Provider = n
+		provider = NewChain(append(Backends(cfg, genaiclient.New(gemini), gemini), NewRuleFallback())...)
 	case "copilot":
 		provider = NewCopilot(DefaultCopilotConfig(workingDir))
 	default:
 		provider = NewChain(
-			OpenAIProviderFromConfig(),
+			ProviderFromConfig(),
 			NewCopilot(DefaultCopilotConfig(workingDir)),
 			NewRuleFallback(),
 		)
@@ -0,0 +1,132 @@
+package aiprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pubgo/funk/v2/log"
+	"github.com/sashabaranov/go-openai"
+
+	"github.com/pubgo/fastgit/utils"
+)
+
+const (
+	defaultMaxRetries = 3
+	defaultTimeout    = 60 * time.Second
+	defaultBaseDelay  = time.Second
+	maxRetryDelay     = 30 * time.Second
+)
+
+// RetryPolicy controls how a request is retried on rate limits and server errors.
+type RetryPolicy struct {
+	MaxRetries int
+	// Timeout bounds a single attempt; zero disables it.
+	Timeout   time.Duration
+	BaseDelay time.Duration
+}
+
+// NewRetryPolicy resolves `openai.retry`, falling back to the defaults for unset or invalid values.
+func NewRetryPolicy(cfg *utils.RetryConfig) RetryPolicy {
+	p := RetryPolicy{MaxRetries: defaultMaxRetries, Timeout: defaultTimeout, BaseDelay: defaultBaseDelay}
+	if cfg == nil {
+		return p
+	}
+	if cfg.MaxRetries != nil && *cfg.MaxRetries >= 0 {
+		p.MaxRetries = *cfg.MaxRetries
+	}
+	if d, err := time.ParseDuration(strings.TrimSpace(cfg.Timeout)); err == nil && d >= 0 {
+		p.Timeout = d
+	}
+	if d, err := time.ParseDuration(strings.TrimSpace(cfg.BaseDelay)); err == nil && d > 0 {
+		p.BaseDelay = d
+	}
+	return p
+}
+
+// delay returns the jittered backoff before retry attempt n (0-based): half of the exponential
+// delay is fixed and the other half random, so concurrent clients do not retry in lockstep.
+func (p RetryPolicy) delay(n int) time.Duration {
+	d := p.BaseDelay << n
+	if d <= 0 || d > maxRetryDelay {
+		d = maxRetryDelay
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+var errIdleTimeout = errors.New("idle timeout")
+
+// finalError marks a failure that must not be retried, e.g. after part of a stream was emitted.
+type finalError struct{ error }
+
+func (e finalError) Unwrap() error { return e.error }
+
+type retryNoticeKey struct{}
+
+// WithRetryNotice returns a context whose retried requests report a human readable reason
+// such as "rate limited, retrying in 4s" to notice, e.g. to show it next to a spinner.
+// Without a notice the reason is logged as a warning.
+func WithRetryNotice(ctx context.Context, notice func(msg string)) context.Context {
+	return context.WithValue(ctx, retryNoticeKey{}, notice)
+}
+
+// NotifyRetry reports msg to the notice registered with WithRetryNotice.
+func NotifyRetry(ctx context.Context, msg string) {
+	if notice, ok := ctx.Value(retryNoticeKey{}).(func(string)); ok && notice != nil {
+		notice(msg)
+		return
+	}
+	log.Warn().Msg(msg)
+}
+
+// withRetry runs attempt until it succeeds, fails with a non-retryable error or the retries are
+// used up. attempt receives a context that is cancelled when the attempt should give up.
+func withRetry[T any](ctx context.Context, p RetryPolicy, attempt func(ctx context.Context) (T, error)) (T, error) {
+	for n := 0; ; n++ {
+		val, err := attempt(ctx)
+		if err == nil || ctx.Err() != nil || n >= p.MaxRetries {
+			return val, err
+		}
+		reason, ok := retryReason(err)
+		if !ok {
+			return val, err
+		}
+
+		wait := p.delay(n)
+		NotifyRetry(ctx, fmt.Sprintf("%s, retrying in %s (%d/%d)", reason, wait.Round(time.Second), n+1, p.MaxRetries))
+		select {
+		case <-ctx.Done():
+			return val, err
+		case <-time.After(wait):
+		}
+	}
+}
+
+// retryReason reports whether err is worth retrying: HTTP 429 or 5xx. A timed out attempt is not
+// retried, so a Chain can move on to its next provider instead of waiting out the same backend.
+func retryReason(err error) (string, bool) {
+	var final finalError
+	if errors.As(err, &final) {
+		return "", false
+	}
+	var apiErr *openai.APIError
+	var reqErr *openai.RequestError
+	status := 0
+	switch {
+	case errors.As(err, &apiErr):
+		status = apiErr.HTTPStatusCode
+	case errors.As(err, &reqErr):
+		status = reqErr.HTTPStatusCode
+	}
+	switch {
+	case status == http.StatusTooManyRequests:
+		return "rate limited", true
+	case status >= 500:
+		return fmt.Sprintf("server error %d", status), true
+	}
+	return "", false
+}
@@ -0,0 +1,89 @@
+package aiprovider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pubgo/fastgit/utils"
+)
+
+func retryTestProvider(t *testing.T, handler http.HandlerFunc, retry *utils.RetryConfig) *OpenAIProvider {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return NewOpenAI(utils.NewOpenaiClient(&utils.OpenaiConfig{ApiKey: "test", BaseURL: srv.URL, Model: "m", Retry: retry}))
+}
+
+func TestCompleteRetriesRateLimit(t *testing.T) {
+	var calls atomic.Int32
+	p := retryTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = fmt.Fprint(w, `{"error":{"message":"slow down","type":"rate_limit"}}`)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"feat: ok"}}]}`)
+	}, &utils.RetryConfig{BaseDelay: "1ms"})
+
+	var notices []string
+	ctx := WithRetryNotice(context.Background(), func(msg string) { notices = append(notices, msg) })
+	resp, err := p.Complete(ctx, CompleteRequest{User: "diff"})
+	require.NoError(t, err)
+	require.Equal(t, "feat: ok", resp.Text)
+	require.EqualValues(t, 3, calls.Load())
+	require.Len(t, notices, 2)
+	require.Contains(t, notices[0], "rate limited, retrying in")
+	require.Contains(t, notices[1], "(2/3)")
+}
+
+func TestCompleteDoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	p := retryTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = fmt.Fprint(w, `{"error":{"message":"bad key"}}`)
+	}, &utils.RetryConfig{BaseDelay: "1ms"})
+
+	_, err := p.Complete(context.Background(), CompleteRequest{User: "diff"})
+	require.Error(t, err)
+	require.EqualValues(t, 1, calls.Load())
+}
+
+func TestCompleteTimeoutIsNotRetried(t *testing.T) {
+	var calls atomic.Int32
+	one := 1
+	p := retryTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		select {
+		case <-r.Context().Done():
+		case <-time.After(200 * time.Millisecond):
+		}
+	}, &utils.RetryConfig{MaxRetries: &one, Timeout: "20ms", BaseDelay: "1ms"})
+
+	_, err := p.Complete(context.Background(), CompleteRequest{User: "diff"})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.EqualValues(t, 1, calls.Load())
+}
+
+func TestNewRetryPolicy(t *testing.T) {
+	p := NewRetryPolicy(nil)
+	require.Equal(t, RetryPolicy{MaxRetries: 3, Timeout: time.Minute, BaseDelay: time.Second}, p)
+
+	none := 0
+	p = NewRetryPolicy(&utils.RetryConfig{MaxRetries: &none, Timeout: "0s", BaseDelay: "bogus"})
+	require.Equal(t, RetryPolicy{MaxRetries: 0, Timeout: 0, BaseDelay: time.Second}, p)
+
+	for n := range 10 {
+		d := NewRetryPolicy(nil).delay(n)
+		require.Greater(t, d, time.Duration(0))
+		require.LessOrEqual(t, d, maxRetryDelay)
+	}
+}
@@ -0,0 +1,193 @@
+package aiprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+
+	"github.com/pubgo/fastgit/pkg/timing"
+)
+
+// Streamer is implemented by providers that can emit a completion incrementally.
+// onDelta receives each text fragment as it arrives; the returned response holds the full text.
+type Streamer interface {
+	Stream(ctx context.Context, req CompleteRequest, onDelta func(string)) (CompleteResponse, error)
+}
+
+// Stream completes req with p, streaming fragments to onDelta when p supports it.
+// Providers without streaming report their whole answer as a single fragment.
+func Stream(ctx context.Context, p Provider, req CompleteRequest, onDelta func(string)) (CompleteResponse, error) {
+	if s, ok := p.(Streamer); ok {
+		return s.Stream(ctx, req, onDelta)
+	}
+	resp, err := p.Complete(ctx, req)
+	if err == nil && onDelta != nil {
+		onDelta(resp.Text)
+	}
+	return resp, err
+}
+
+// Stream tries providers in order like Complete. Once a provider has emitted text it is not
+// abandoned for the next one, so the caller never sees two answers interleaved.
+func (c *Chain) Stream(ctx context.Context, req CompleteRequest, onDelta func(string)) (CompleteResponse, error) {
+	if c == nil || len(c.providers) == 0 {
+		return CompleteResponse{}, fmt.Errorf("no AI providers configured")
+	}
+
+	var lastErr error
+	var skipped []string
+	for _, provider := range c.providers {
+		if provider == nil || !provider.Available() {
+			continue
+		}
+		emitted := false
+		done := timing.Track(ctx, timing.PhaseLLM, provider.Name())
+		resp, err := Stream(ctx, provider, req, func(delta string) {
+			emitted = true
+			if onDelta != nil {
+				onDelta(delta)
+			}
+		})
+		done()
+		if err == nil && strings.TrimSpace(resp.Text) != "" {
+			resp.Skipped = append(skipped, resp.Skipped...)
+			return resp, nil
+		}
+		if err != nil {
+			lastErr = err
+		}
+		if emitted || ctx.Err() != nil {
+			break
+		}
+		skipped = append(skipped, provider.Name())
+	}
+
+	if lastErr != nil {
+		return CompleteResponse{}, fmt.Errorf("all AI providers failed: %w", lastErr)
+	}
+	return CompleteResponse{}, fmt.Errorf("all AI providers failed")
+}
+
+func (p *cachedProvider) Stream(ctx context.Context, req CompleteRequest, onDelta func(string)) (CompleteResponse, error) {
+	key := cacheKey(req)
+	if entry, ok := p.load(key); ok {
+		if onDelta != nil {
+			onDelta(entry.Text)
+		}
+		return CompleteResponse{Text: entry.Text, Provider: entry.Provider, Model: entry.Model}, nil
+	}
+
+	resp, err := Stream(ctx, p.inner, req, onDelta)
+	if err == nil && !resp.Fallback && strings.TrimSpace(resp.Text) != "" {
+		_ = p.save(key, newCacheEntry(resp))
+	}
+	return resp, err
+}
+
+func (p *OpenAIProvider) Stream(ctx context.Context, req CompleteRequest, onDelta func(string)) (CompleteResponse, error) {
+	if !p.Available() {
+		return CompleteResponse{}, fmt.Errorf("%s provider unavailable: missing API key", p.Name())
+	}
+
+	model := strings.TrimSpace(req.Model)
+	if model == "" {
+		model = strings.TrimSpace(p.client.Cfg.Model)
+	}
+
+	policy := p.retryPolicy()
+	return withRetry(ctx, policy, func(ctx context.Context) (CompleteResponse, error) {
+		emitted := false
+		resp, err := p.streamOnce(ctx, model, req, policy.Timeout, func(delta string) {
+			emitted = true
+			if onDelta != nil {
+				onDelta(delta)
+			}
+		})
+		// 已输出的片段无法撤回，中途失败时不再重试，避免调用方看到重复内容
+		if err != nil && emitted {
+			return resp, finalError{err}
+		}
+		return resp, err
+	})
+}
+
+// streamOnce runs one streaming request. idle bounds the wait for the first and every following
+// chunk, so a long answer is not cut off while a stalled connection still times out.
+func (p *OpenAIProvider) streamOnce(ctx context.Context, model string, req CompleteRequest, idle time.Duration, onDelta func(string)) (CompleteResponse, error) {
+	parent := ctx
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	if idle > 0 {
+		timer := time.AfterFunc(idle, func() { cancel(errIdleTimeout) })
+		defer timer.Stop()
+		inner := onDelta
+		onDelta = func(delta string) {
+			timer.Reset(idle)
+			inner(delta)
+		}
+	}
+	wrap := func(err error) error {
+		if parent.Err() == nil && errors.Is(context.Cause(ctx), errIdleTimeout) {
+			return fmt.Errorf("%s completion: no output for %s: %w", p.Name(), idle, context.DeadlineExceeded)
+		}
+		return fmt.Errorf("%s completion: %w", p.Name(), err)
+	}
+
+	stream, err := p.client.Client.CreateChatCompletionStream(ctx, openai.ChatCompletionRequest{
+		Model: model,
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: req.System},
+			{Role: openai.ChatMessageRoleUser, Content: req.User},
+		},
+		Stream:        true,
+		StreamOptions: &openai.StreamOptions{IncludeUsage: true},
+	})
+	if err != nil {
+		return CompleteResponse{}, wrap(err)
+	}
+	defer stream.Close()
+
+	out := CompleteResponse{Provider: p.Name(), Model: model}
+	var text strings.Builder
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return CompleteResponse{}, wrap(err)
+		}
+		if chunk.Usage != nil {
+			out.Usage = *chunk.Usage
+		}
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+		if delta := chunk.Choices[0].Delta.Content; delta != "" {
+			text.WriteString(delta)
+			onDelta(delta)
+		}
+	}
+
+	out.Text = strings.TrimSpace(text.String())
+	if out.Text == "" {
+		return CompleteResponse{}, fmt.Errorf("%s completion: empty response", p.Name())
+	}
+	return out, nil
+}
+
+func (p *OllamaProvider) Stream(ctx context.Context, req CompleteRequest, onDelta func(string)) (CompleteResponse, error) {
+	if !p.Available() {
+		return CompleteResponse{}, fmt.Errorf("ollama provider unavailable")
+	}
+	resp, err := p.client.ChatStream(ctx, req.Model, ollamaMessages(req), onDelta)
+	if err != nil {
+		return CompleteResponse{}, err
+	}
+	return ollamaResponse(p.Name(), resp)
+}
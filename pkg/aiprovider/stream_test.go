@@ -0,0 +1,46 @@
+package aiprovider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pubgo/fastgit/utils"
+)
+
+func TestOpenAIStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, delta := range []string{"feat: ", "add ", "x"} {
+			_, _ = fmt.Fprintf(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":%q}}]}\n\n", delta)
+		}
+		_, _ = fmt.Fprint(w, "data: {\"choices\":[],\"usage\":{\"prompt_tokens\":3,\"completion_tokens\":4,\"total_tokens\":7}}\n\n")
+		_, _ = fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer srv.Close()
+
+	p := NewOpenAI(utils.NewOpenaiClient(&utils.OpenaiConfig{ApiKey: "k", BaseURL: srv.URL, Model: "m"}))
+	var deltas []string
+	resp, err := Stream(context.Background(), NewChain(p, NewRuleFallback()), CompleteRequest{User: "diff"}, func(d string) {
+		deltas = append(deltas, d)
+	})
+	require.NoError(t, err)
+	require.Equal(t, "feat: add x", resp.Text)
+	require.Equal(t, []string{"feat: ", "add ", "x"}, deltas)
+	require.NotNil(t, resp.Usage)
+}
+
+func TestStreamFallsBackToComplete(t *testing.T) {
+	var got strings.Builder
+	resp, err := Stream(context.Background(), NewChain(NewOpenAI(nil), NewRuleFallback()), CompleteRequest{User: "diff --git a/main.go b/main.go\n"}, func(d string) {
+		got.WriteString(d)
+	})
+	require.NoError(t, err)
+	require.True(t, resp.Fallback)
+	require.Equal(t, resp.Text, got.String())
+}
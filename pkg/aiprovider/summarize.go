@@ -0,0 +1,60 @@
+package aiprovider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+const diffChunkSystemPrompt = `You summarize one part of a large staged git diff so a later pass can write the commit message.
+Return 2-6 short bullet points naming the files and what changed in them (new behavior, fixes, renames, removed code).
+Mention breaking API changes explicitly. Do not write a commit message.`
+
+// maxSummaryWorkers caps concurrent chunk summaries so large diffs do not hit provider rate limits.
+const maxSummaryWorkers = 4
+
+// SummarizeDiff summarizes each diff chunk in parallel and joins the results into a text that
+// replaces the diff as model input. ok is false when the provider is unavailable or fell back to
+// rules, in which case callers should keep using the (truncated) diff.
+func SummarizeDiff(ctx context.Context, provider Provider, chunks []string) (string, bool, error) {
+	if provider == nil || !provider.Available() || len(chunks) == 0 {
+		return "", false, nil
+	}
+
+	var (
+		wg        sync.WaitGroup
+		sem       = make(chan struct{}, maxSummaryWorkers)
+		summaries = make([]string, len(chunks))
+		errs      = make([]error, len(chunks))
+		fallback  = make([]bool, len(chunks))
+	)
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			resp, err := provider.Complete(ctx, CompleteRequest{
+				System: diffChunkSystemPrompt,
+				User:   fmt.Sprintf("Part %d of %d:\n\n%s", i+1, len(chunks), chunk),
+			})
+			summaries[i], errs[i], fallback[i] = strings.TrimSpace(resp.Text), err, resp.Fallback
+		}()
+	}
+	wg.Wait()
+
+	var out strings.Builder
+	out.WriteString("The staged diff is too large to include in full. Summaries of its parts:\n")
+	for i, summary := range summaries {
+		if errs[i] != nil {
+			return "", false, errs[i]
+		}
+		if fallback[i] || summary == "" {
+			return "", false, nil
+		}
+		_, _ = fmt.Fprintf(&out, "\nPart %d:\n%s\n", i+1, summary)
+	}
+	return out.String(), true, nil
+}
@@ -0,0 +1,32 @@
+package aiprovider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type summaryStub struct {
+	fallback bool
+}
+
+func (s summaryStub) Name() string    { return "summary" }
+func (s summaryStub) Available() bool { return true }
+func (s summaryStub) Complete(ctx context.Context, req CompleteRequest) (CompleteResponse, error) {
+	part, _, _ := strings.Cut(req.User, ":")
+	return CompleteResponse{Text: "- changed " + part, Fallback: s.fallback}, nil
+}
+
+func TestSummarizeDiff(t *testing.T) {
+	summary, ok, err := SummarizeDiff(context.Background(), summaryStub{}, []string{"a", "b", "c"})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Contains(t, summary, "Part 1:\n- changed Part 1 of 3")
+	require.Contains(t, summary, "Part 3:\n- changed Part 3 of 3")
+
+	_, ok, err = SummarizeDiff(context.Background(), summaryStub{fallback: true}, []string{"a"})
+	require.NoError(t, err)
+	require.False(t, ok)
+}
@@ -0,0 +1,156 @@
+package changelog
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pubgo/fastgit/pkg/gitshell"
+)
+
+// cacheVersion is bumped whenever ParseCommit changes so stale parses are discarded.
+const cacheVersion = 3
+
+// logFormat separates fields with NUL and records with RS, so bodies may contain anything.
+const logFormat = "%H%x00%an%x00%aI%x00%B%x1e"
+
+type cacheFile struct {
+	Version int                       `json:"version"`
+	Entries map[string]ChangelogEntry `json:"entries"`
+}
+
+// Cache stores parsed entries keyed by commit hash under `.git/fastgit/`.
+// Commits are immutable, so a hit never needs revalidation.
+type Cache struct {
+	path    string
+	entries map[string]ChangelogEntry
+	dirty   bool
+}
+
+// Stats reports how much of a Collect call was served from the cache.
+type Stats struct {
+	Total  int
+	Cached int
+	Parsed int
+}
+
+// OpenCache loads the cache of the repository at repoRoot; a missing or
+// incompatible cache file starts empty.
+func OpenCache(ctx context.Context, repoRoot string) (*Cache, error) {
+	gitDir, err := gitshell.Run(ctx, repoRoot, "rev-parse", "--path-format=absolute", "--git-common-dir")
+	if err != nil {
+		return nil, err
+	}
+	c := &Cache{
+		path:    filepath.Join(gitDir, "fastgit", "changelog-cache.json"),
+		entries: make(map[string]ChangelogEntry),
+	}
+
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return c, nil
+	}
+	var file cacheFile
+	if json.Unmarshal(data, &file) == nil && file.Version == cacheVersion && file.Entries != nil {
+		c.entries = file.Entries
+	}
+	return c, nil
+}
+
+// Path returns the cache file location.
+func (c *Cache) Path() string { return c.path }
+
+// Len returns the number of cached entries.
+func (c *Cache) Len() int { return len(c.entries) }
+
+// Save writes the cache back when new entries were added.
+func (c *Cache) Save() error {
+	if !c.dirty {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(cacheFile{Version: cacheVersion, Entries: c.entries})
+	if err != nil {
+		return err
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	c.dirty = false
+	return os.Rename(tmp, c.path)
+}
+
+// Collect returns entries for every non-merge commit in revRange (newest first),
+// parsing only commits missing from the cache. A nil cache parses everything.
+// pathspecs, when given, keep only commits touching them.
+func Collect(ctx context.Context, repoRoot, revRange string, c *Cache, pathspecs ...string) ([]ChangelogEntry, Stats, error) {
+	var stats Stats
+	args := []string{"rev-list", "--no-merges", revRange}
+	if len(pathspecs) > 0 {
+		args = append(append(args, "--"), pathspecs...)
+	}
+	list, err := gitshell.Run(ctx, repoRoot, args...)
+	if err != nil {
+		return nil, stats, err
+	}
+	if list == "" {
+		return nil, stats, nil
+	}
+	hashes := strings.Split(list, "\n")
+	stats.Total = len(hashes)
+
+	var missing []string
+	for _, h := range hashes {
+		if c == nil {
+			missing = append(missing, h)
+			continue
+		}
+		if _, ok := c.entries[h]; !ok {
+			missing = append(missing, h)
+		}
+	}
+	stats.Cached = stats.Total - len(missing)
+
+	parsed := make(map[string]ChangelogEntry, len(missing))
+	if len(missing) > 0 {
+		// 通过 stdin 传入 hash，避免数千个提交时超出命令行长度限制
+		out, err := gitshell.RunWithInput(ctx, repoRoot, []byte(strings.Join(missing, "\n")+"\n"),
+			"log", "--no-walk=unsorted", "--stdin", "--format="+logFormat)
+		if err != nil {
+			return nil, stats, err
+		}
+		for _, record := range strings.Split(out, "\x1e") {
+			fields := strings.SplitN(strings.TrimLeft(record, "\n"), "\x00", 4)
+			if len(fields) != 4 {
+				continue
+			}
+			date, _ := time.Parse(time.RFC3339, fields[2])
+			parsed[fields[0]] = ParseCommit(fields[0], fields[3], fields[1], date)
+		}
+		stats.Parsed = len(parsed)
+	}
+
+	entries := make([]ChangelogEntry, 0, len(hashes))
+	for _, h := range hashes {
+		if e, ok := parsed[h]; ok {
+			entries = append(entries, e)
+			if c != nil {
+				c.entries[h] = e
+				c.dirty = true
+			}
+			continue
+		}
+		if c != nil {
+			if e, ok := c.entries[h]; ok {
+				entries = append(entries, e)
+			}
+		}
+	}
+	return entries, stats, nil
+}
@@ -0,0 +1,535 @@
+package changelog
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCommit(t *testing.T) {
+	e := ParseCommit("0123456789", "feat(api)!: add v2 endpoint\n\nbody", "dev", time.Time{})
+	if e.Type != "feat" || e.Scope != "api" || !e.Breaking || e.Subject != "add v2 endpoint" {
+		t.Fatalf("unexpected entry: %+v", e)
+	}
+	if e.Section() != SectionAdded {
+		t.Fatalf("expected section %s, got %s", SectionAdded, e.Section())
+	}
+	if got := e.Line(); got != "- **BREAKING** **api**: add v2 endpoint (0123456)" {
+		t.Fatalf("unexpected line: %s", got)
+	}
+
+	e = ParseCommit("abc", "update readme\n\nBREAKING CHANGE: drop config", "", time.Time{})
+	if e.Type != "" || !e.Breaking || e.Section() != SectionChanged {
+		t.Fatalf("unexpected entry: %+v", e)
+	}
+}
+
+func TestCollectReusesCache(t *testing.T) {
+	repo := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+	run("init", "-q")
+	run("config", "user.email", "test@example.com")
+	run("config", "user.name", "tester")
+	run("commit", "-q", "--allow-empty", "-m", "feat: first")
+	run("commit", "-q", "--allow-empty", "-m", "fix(ui): second\n\nwith body")
+
+	ctx := context.Background()
+	cache, err := OpenCache(ctx, repo)
+	if err != nil {
+		t.Fatalf("OpenCache() error = %v", err)
+	}
+	entries, stats, err := Collect(ctx, repo, "HEAD", cache)
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if stats.Total != 2 || stats.Parsed != 2 || len(entries) != 2 {
+		t.Fatalf("unexpected stats %+v entries %+v", stats, entries)
+	}
+	if entries[0].Type != "fix" || entries[0].Scope != "ui" || entries[0].Author != "tester" {
+		t.Fatalf("unexpected newest entry: %+v", entries[0])
+	}
+	if err := cache.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if _, err := os.Stat(cache.Path()); err != nil {
+		t.Fatalf("cache file missing: %v", err)
+	}
+
+	run("commit", "-q", "--allow-empty", "-m", "docs: third")
+	cache, _ = OpenCache(ctx, repo)
+	entries, stats, err = Collect(ctx, repo, "HEAD", cache)
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if stats.Cached != 2 || stats.Parsed != 1 || len(entries) != 3 {
+		t.Fatalf("expected incremental parse, got %+v", stats)
+	}
+
+	groups := Render(Group(entries))
+	if !strings.Contains(groups[SectionFixed], "**ui**: second") || groups[SectionChanged] != "暂无" {
+		t.Fatalf("unexpected render: %+v", groups)
+	}
+}
+
+func TestParseCommitRefsAndEnrichedLine(t *testing.T) {
+	e := ParseCommit("0123456789", "fix: handle timeout\n\nRefs: ABC-12, #7", "", time.Time{})
+	if strings.Join(e.Refs, " ") != "ABC-12 #7" {
+		t.Fatalf("unexpected refs: %v", e.Refs)
+	}
+	e.PR = 42
+	e.Notes = []string{"ABC-12 Login timeout"}
+	if got := e.Line(); got != "- handle timeout (#42) — ABC-12 Login timeout (0123456)" {
+		t.Fatalf("unexpected line: %s", got)
+	}
+}
+
+func TestPipelineApply(t *testing.T) {
+	upper := EnricherFunc("upper", func(_ context.Context, e ChangelogEntry) (ChangelogEntry, error) {
+		e.Subject = strings.ToUpper(e.Subject)
+		return e, nil
+	})
+	calls := 0
+	broken := EnricherFunc("broken", func(_ context.Context, e ChangelogEntry) (ChangelogEntry, error) {
+		calls++
+		e.Subject = "lost"
+		return e, errors.New("offline")
+	})
+
+	in := []ChangelogEntry{{Subject: "a"}, {Subject: "b"}}
+	out, errs := Pipeline{upper, broken}.Apply(context.Background(), in)
+	if out[0].Subject != "A" || out[1].Subject != "B" || in[0].Subject != "a" {
+		t.Fatalf("unexpected entries: %+v (input %+v)", out, in)
+	}
+	if len(errs) != 1 || errs[0].Error() != "broken: offline" || calls != 2 {
+		t.Fatalf("unexpected errors: %v (calls %d)", errs, calls)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	repo := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+	run("init", "-q")
+	run("config", "user.email", "test@example.com")
+	run("config", "user.name", "tester")
+	run("commit", "-q", "--allow-empty", "-m", "feat: first")
+	run("tag", "v0.1.0")
+	run("commit", "-q", "--allow-empty", "-m", "docs: readme")
+	run("commit", "-q", "--allow-empty", "-m", "fix: crash")
+
+	res, err := Generate(context.Background(), repo, Options{NoCache: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Range != "v0.1.0..HEAD" || len(res.Entries) != 2 || res.Entries[0].Subject != "crash" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if !strings.Contains(res.Sections[SectionDocs], "readme") {
+		t.Fatalf("docs section missing entry: %q", res.Sections[SectionDocs])
+	}
+	if _, err := os.Stat(repo + "/.git/fastgit/changelog-cache.json"); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("NoCache must not write the cache, stat err = %v", err)
+	}
+
+	last, err := Generate(context.Background(), repo, Options{From: "HEAD~1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last.Range != "HEAD~1..HEAD" || len(last.Entries) != 1 || last.Entries[0].Subject != "crash" {
+		t.Fatalf("unexpected result from HEAD~1: %+v", last)
+	}
+
+	first, err := Generate(context.Background(), repo, Options{To: "v0.1.0", FullHistory: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Range != "v0.1.0" || len(first.Entries) != 1 || first.Entries[0].Subject != "first" {
+		t.Fatalf("unexpected result for the first tag: %+v", first)
+	}
+}
+
+func TestGenerateCommit(t *testing.T) {
+	repo := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+	run("init", "-q", "-b", "main")
+	run("config", "user.email", "test@example.com")
+	run("config", "user.name", "tester")
+	run("commit", "-q", "--allow-empty", "-m", "feat: first")
+	run("commit", "-q", "--allow-empty", "-m", "docs: readme")
+	run("checkout", "-q", "-b", "topic")
+	run("commit", "-q", "--allow-empty", "-m", "fix: crash")
+	run("commit", "-q", "--allow-empty", "-m", "fix(api): nil body")
+	run("checkout", "-q", "main")
+	run("merge", "-q", "--no-ff", "-m", "Merge branch 'topic'", "topic")
+
+	subjects := func(opts Options) []string {
+		t.Helper()
+		opts.NoCache = true
+		res, err := Generate(context.Background(), repo, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out []string
+		for _, e := range res.Entries {
+			out = append(out, e.Subject)
+		}
+		return out
+	}
+
+	if got := subjects(Options{Commit: "HEAD^1"}); len(got) != 1 || got[0] != "readme" {
+		t.Fatalf("single commit: %v", got)
+	}
+	if got := subjects(Options{Commit: "HEAD~2"}); len(got) != 1 || got[0] != "first" {
+		t.Fatalf("root commit: %v", got)
+	}
+	if got := subjects(Options{Commit: "HEAD", From: "ignored"}); len(got) != 2 || got[0] != "nil body" || got[1] != "crash" {
+		t.Fatalf("merge commit: %v", got)
+	}
+	if _, err := Generate(context.Background(), repo, Options{Commit: "nope"}); err == nil {
+		t.Fatal("expected an error for an unknown commit")
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	res := Result{Range: "v1.0.0..HEAD", Entries: []ChangelogEntry{
+		ParseCommit("abcdef0123", "fix: nil map", "", time.Time{}),
+		ParseCommit("0123456789", "feat(api)!: add v2", "", time.Time{}),
+	}}
+	cl := NewChangelog(res, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC))
+	if len(cl.Entries) != 2 || cl.Entries[0].Hash != "0123456789" {
+		t.Fatalf("entries not in section order: %+v", cl.Entries)
+	}
+	text := `# {{.Range}} ({{date "2006-01-02" .Date}})
+{{range .Sections}}{{if .Entries}}{{.Title}}:{{range .Entries}} [{{short .Hash}}] {{.Subject}}{{end}}
+{{end}}{{end}}breaking: {{len .Breaking}}`
+	got, err := RenderTemplate("t", text, cl)
+	if err != nil {
+		t.Fatalf("RenderTemplate() error = %v", err)
+	}
+	want := "# v1.0.0..HEAD (2024-05-01)\n新增: [0123456] add v2\n修复: [abcdef0] nil map\nbreaking: 1"
+	if got != want {
+		t.Fatalf("unexpected output:\n%s", got)
+	}
+
+	if _, err := RenderTemplate("t", "{{.Missing}}", cl); err == nil {
+		t.Fatal("expected an error for an unknown field")
+	}
+}
+
+func TestGenerateAll(t *testing.T) {
+	repo := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+	run("init", "-q")
+	run("config", "user.email", "test@example.com")
+	run("config", "user.name", "tester")
+	run("commit", "-q", "--allow-empty", "-m", "feat: first")
+	run("tag", "v1.0.0")
+	run("commit", "-q", "--allow-empty", "-m", "fix: crash")
+	run("tag", "-a", "v1.1.0", "-m", "v1.1.0")
+	run("tag", "not-a-version")
+	run("commit", "-q", "--allow-empty", "-m", "docs: readme")
+
+	releases, err := GenerateAll(context.Background(), repo, Options{NoCache: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range releases {
+		got = append(got, r.Version+" "+r.Range+" "+r.Entries[0].Subject)
+	}
+	want := []string{"Unreleased v1.1.0..HEAD readme", "v1.1.0 v1.0.0..v1.1.0 crash", "v1.0.0 v1.0.0 first"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("unexpected releases: %v", got)
+	}
+	if !releases[0].Date.IsZero() || releases[1].Date.IsZero() {
+		t.Fatalf("unexpected dates: %v %v", releases[0].Date, releases[1].Date)
+	}
+
+	md := RenderReleases(releases)
+	if !strings.Contains(md, "## v1.1.0 - "+releases[1].Date.Format(time.DateOnly)+"\n\n### 修复\n\n- crash") ||
+		!strings.HasPrefix(md, "# Changelog\n\n## Unreleased\n") {
+		t.Fatalf("unexpected markdown:\n%s", md)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	unreleased := Release{Version: Unreleased, Result: Result{Sections: map[string]string{"新增": "- watch mode"}}}
+	existing := "# Changelog\n\nAll notable changes.\n\n## [Unreleased]\n\n### 新增\n\n- old draft\n\n## [v1.0.0] - 2024-01-02\n\n### 新增\n\n- first\n"
+
+	got := Update(existing, []Release{unreleased})
+	want := "# Changelog\n\nAll notable changes.\n\n## [Unreleased]\n\n### 新增\n\n- watch mode\n\n## [v1.0.0] - 2024-01-02\n\n### 新增\n\n- first\n"
+	if got != want {
+		t.Fatalf("unexpected update:\n%s", got)
+	}
+
+	v11 := Release{Version: "v1.1.0", Date: time.Date(2024, 2, 3, 0, 0, 0, 0, time.UTC), Result: Result{Sections: map[string]string{"修复": "- crash"}}}
+	v10 := Release{Version: "v1.0.0", Result: Result{Sections: map[string]string{"新增": "- regenerated"}}}
+	got = Update("# Changelog\n\n## 1.0.0\n\n- hand written\n", []Release{unreleased, v11, v10})
+	want = "# Changelog\n\n## Unreleased\n\n### 新增\n\n- watch mode\n\n## v1.1.0 - 2024-02-03\n\n### 修复\n\n- crash\n\n## 1.0.0\n\n- hand written\n"
+	if got != want {
+		t.Fatalf("unexpected merge:\n%s", got)
+	}
+
+	if got := Update("", []Release{unreleased}); got != "# Changelog\n\n## [Unreleased]\n\n### 新增\n\n- watch mode\n" {
+		t.Fatalf("unexpected new file:\n%s", got)
+	}
+}
+
+func TestFilterScopes(t *testing.T) {
+	entries := []ChangelogEntry{
+		{Subject: "a", Scope: "api"},
+		{Subject: "b", Scope: "API/users"},
+		{Subject: "c", Scope: "cli,docs"},
+		{Subject: "d", Scope: "apis"},
+		{Subject: "e"},
+	}
+	var got []string
+	for _, e := range FilterScopes(entries, []string{"api", " cli"}, nil) {
+		got = append(got, e.Subject)
+	}
+	if strings.Join(got, ",") != "a,b,c" {
+		t.Fatalf("unexpected entries: %v", got)
+	}
+	if len(FilterScopes(entries, nil, nil)) != len(entries) {
+		t.Fatalf("empty scopes must keep every entry")
+	}
+
+	got = nil
+	for _, e := range FilterScopes(entries, nil, []string{"api", "docs"}) {
+		got = append(got, e.Subject)
+	}
+	if strings.Join(got, ",") != "c,d,e" {
+		t.Fatalf("unexpected entries after exclude: %v", got)
+	}
+	got = nil
+	for _, e := range FilterScopes(entries, []string{"cli", "api"}, []string{"api/users"}) {
+		got = append(got, e.Subject)
+	}
+	if strings.Join(got, ",") != "a,c" {
+		t.Fatalf("unexpected entries with include and exclude: %v", got)
+	}
+}
+
+func TestGenerateMonorepoPackage(t *testing.T) {
+	repo := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s failed: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+	commit := func(file, msg string) {
+		t.Helper()
+		if err := os.MkdirAll(repo+"/"+file[:strings.LastIndex(file, "/")], 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(repo+"/"+file, []byte(msg), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		run("add", "-A")
+		run("commit", "-q", "-m", msg)
+	}
+	run("init", "-q")
+	run("config", "user.email", "test@example.com")
+	run("config", "user.name", "tester")
+	commit("pkg/foo/a.go", "feat: foo first")
+	run("tag", "foo/v1.0.0")
+	commit("pkg/bar/a.go", "feat: bar first")
+	run("tag", "v2.0.0")
+	commit("pkg/foo/b/c.go", "fix: foo crash")
+	commit("pkg/bar/b.go", "fix: bar crash")
+
+	opts := Options{NoCache: true, Paths: []string{"pkg/foo/..."}, TagPrefix: "foo/"}
+	res, err := Generate(context.Background(), repo, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Range != "foo/v1.0.0..HEAD" || len(res.Entries) != 1 || res.Entries[0].Subject != "foo crash" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+
+	releases, err := GenerateAll(context.Background(), repo, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range releases {
+		got = append(got, r.Version+" "+r.Range+" "+r.Entries[0].Subject)
+	}
+	want := []string{"Unreleased foo/v1.0.0..HEAD foo crash", "foo/v1.0.0 foo/v1.0.0 foo first"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("unexpected releases: %v", got)
+	}
+}
+
+func TestFragments(t *testing.T) {
+	dir := t.TempDir() + "/" + FragmentDir
+	if got, err := ReadFragments(dir); err != nil || got != nil {
+		t.Fatalf("missing dir must have no fragments: %v %v", got, err)
+	}
+
+	first, err := WriteFragment(dir, "feat", "Add --watch mode", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := WriteFragment(dir, "feat", "Add --watch mode", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(first, "/add-watch-mode.feat.md") || !strings.HasSuffix(second, "/add-watch-mode-2.feat.md") {
+		t.Fatalf("unexpected paths: %s %s", first, second)
+	}
+	if _, err := WriteFragment(dir, "fix", "修复崩溃\n\n细节说明", "123"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := WriteFragment(dir, "Bug fix", "x", ""); err == nil {
+		t.Fatalf("invalid type must be rejected")
+	}
+	if err := os.WriteFile(dir+"/README.md", []byte("how to"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	fragments, err := ReadFragments(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := FromFragments(fragments, Layout{})
+	if res.Stats.Total != 3 || res.Sections[SectionAdded] != "- Add --watch mode\n- Add --watch mode" ||
+		res.Sections[SectionFixed] != "- 修复崩溃\n\n  细节说明" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+
+	if err := os.WriteFile(dir+"/notes.txt", []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadFragments(dir); err == nil {
+		t.Fatalf("misnamed fragment must be reported")
+	}
+}
+
+func TestNewLayout(t *testing.T) {
+	layout, err := NewLayout([]SectionRule{
+		{Title: "安全", Types: []string{"sec", "Security"}},
+		{Title: "基础设施", Pattern: `^(infra|ops)$`},
+		{Hidden: true, Types: []string{"chore", "ci"}},
+		{Title: SectionFixed, Types: []string{"hotfix"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"安全", "基础设施", SectionFixed, SectionAdded, SectionChanged, SectionDocs}
+	if strings.Join(layout.Titles(), ",") != strings.Join(want, ",") {
+		t.Fatalf("unexpected order: %v", layout.Titles())
+	}
+	if strings.Join(Sections, ",") != "新增,修复,变更,文档" {
+		t.Fatalf("the standard sections must not change: %v", Sections)
+	}
+
+	entries := []ChangelogEntry{
+		{Type: "security", Subject: "a"},
+		{Type: "ops", Subject: "b"},
+		{Type: "chore", Subject: "c"},
+		{Type: "ci", Subject: "d", Breaking: true},
+		{Type: "hotfix", Subject: "e"},
+		{Type: "feat", Subject: "f"},
+	}
+	groups := layout.Group(entries)
+	got := func(title string) string {
+		var subjects []string
+		for _, e := range groups[title] {
+			subjects = append(subjects, e.Subject)
+		}
+		return strings.Join(subjects, ",")
+	}
+	if got("安全") != "a" || got("基础设施") != "b" || got(SectionFixed) != "e" || got(SectionAdded) != "f" || got(SectionChanged) != "d" {
+		t.Fatalf("unexpected groups: %+v", groups)
+	}
+	if sections := layout.Render(groups); sections["安全"] != "- a" || sections[SectionDocs] != "暂无" {
+		t.Fatalf("unexpected render: %+v", sections)
+	}
+	if entries[0].Section() != SectionChanged || layout.Section(entries[2]) != "" {
+		t.Fatalf("rules apply only to their layout")
+	}
+
+	if _, err := NewLayout([]SectionRule{{Title: "x"}}); err == nil {
+		t.Fatalf("rule without types or pattern must be rejected")
+	}
+	if _, err := NewLayout([]SectionRule{{Title: "x", Pattern: "("}}); err == nil {
+		t.Fatalf("invalid pattern must be rejected")
+	}
+	if standard, err := NewLayout(nil); err != nil || strings.Join(standard.Titles(), ",") != "新增,修复,变更,文档" {
+		t.Fatalf("no rules is the standard layout: %v %v", standard.Titles(), err)
+	}
+}
+
+func TestExclude(t *testing.T) {
+	parse := func(message string) ChangelogEntry { return ParseCommit("", message, "", time.Time{}) }
+	entries := []ChangelogEntry{
+		parse("feat: keep me"),
+		parse("chore(deps): bump x from 1 to 2"),
+		parse("build(deps-dev): bump y"),
+		parse("fix(deps): patch vulnerable z"),
+		parse("Merge branch 'main' into feat/login"),
+		parse("chore: merge back release/1.2"),
+		parse("fix: internal tweak\n\n[skip changelog]"),
+		parse("chore(release): v1.2.0"),
+	}
+	subjects := func(entries []ChangelogEntry) string {
+		var out []string
+		for _, e := range entries {
+			out = append(out, e.Subject)
+		}
+		return strings.Join(out, "|")
+	}
+
+	got, err := Exclude(entries, Exclusions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(entries)-1 || strings.Contains(subjects(got), "internal tweak") {
+		t.Fatalf("[skip changelog] must be dropped by default: %s", subjects(got))
+	}
+	if got, _ := Exclude(entries, Exclusions{KeepSkipped: true}); len(got) != len(entries) {
+		t.Fatalf("KeepSkipped must keep every entry: %s", subjects(got))
+	}
+
+	got, err = Exclude(entries, Exclusions{Deps: true, MergeBack: true, Patterns: []string{`^chore\(release\)`}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subjects(got) != "keep me|patch vulnerable z" {
+		t.Fatalf("unexpected entries: %s", subjects(got))
+	}
+
+	if _, err := Exclude(entries, Exclusions{Patterns: []string{"("}}); err == nil {
+		t.Fatalf("invalid pattern must be rejected")
+	}
+}
@@ -0,0 +1,59 @@
+package changelog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Enricher augments a changelog entry, e.g. with its pull request, ticket summaries or a
+// reworded subject. It returns the entry unchanged when it has nothing to add.
+type Enricher interface {
+	Name() string
+	Enrich(ctx context.Context, e ChangelogEntry) (ChangelogEntry, error)
+}
+
+// EnricherFunc adapts a function to Enricher.
+func EnricherFunc(name string, fn func(ctx context.Context, e ChangelogEntry) (ChangelogEntry, error)) Enricher {
+	return enricherFunc{name: name, fn: fn}
+}
+
+type enricherFunc struct {
+	name string
+	fn   func(ctx context.Context, e ChangelogEntry) (ChangelogEntry, error)
+}
+
+func (f enricherFunc) Name() string { return f.name }
+
+func (f enricherFunc) Enrich(ctx context.Context, e ChangelogEntry) (ChangelogEntry, error) {
+	return f.fn(ctx, e)
+}
+
+// Pipeline runs enrichers in order, each one seeing the output of the previous one.
+type Pipeline []Enricher
+
+// Apply enriches every entry. A failing enricher keeps the entry as it was; only its first
+// error is returned so one unreachable service does not flood the output. Cancellation stops
+// the pipeline and returns what was enriched so far.
+func (p Pipeline) Apply(ctx context.Context, entries []ChangelogEntry) ([]ChangelogEntry, []error) {
+	out := append([]ChangelogEntry(nil), entries...)
+	var errs []error
+	for _, enricher := range p {
+		failed := false
+		for i, e := range out {
+			enriched, err := enricher.Enrich(ctx, e)
+			if errors.Is(err, context.Canceled) || ctx.Err() != nil {
+				return out, append(errs, ctx.Err())
+			}
+			if err != nil {
+				if !failed {
+					errs = append(errs, fmt.Errorf("%s: %w", enricher.Name(), err))
+					failed = true
+				}
+				continue
+			}
+			out[i] = enriched
+		}
+	}
+	return out, errs
+}
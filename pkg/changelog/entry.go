@@ -0,0 +1,119 @@
+package changelog
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Section titles match the standard sections of `.version/changelog/Unreleased.md`.
+const (
+	SectionAdded   = "新增"
+	SectionFixed   = "修复"
+	SectionChanged = "变更"
+	SectionDocs    = "文档"
+)
+
+// Sections lists the standard sections in render order.
+var Sections = []string{SectionAdded, SectionFixed, SectionChanged, SectionDocs}
+
+var conventionalPattern = regexp.MustCompile(`^([a-zA-Z]+)(?:\(([^)]*)\))?(!)?:\s*(.+)$`)
+
+// ChangelogEntry is the parsed form of one commit.
+type ChangelogEntry struct {
+	Hash     string    `json:"hash"`
+	Type     string    `json:"type,omitempty"`
+	Scope    string    `json:"scope,omitempty"`
+	Subject  string    `json:"subject"`
+	Breaking bool      `json:"breaking,omitempty"`
+	Author   string    `json:"author,omitempty"`
+	Date     time.Time `json:"date"`
+	// Refs are the references of `Refs:` trailers, e.g. `ABC-123` or `#42`.
+	Refs []string `json:"refs,omitempty"`
+	// Skip is set when the message carries a `[skip changelog]` marker.
+	Skip bool `json:"skip,omitempty"`
+
+	// PR and Notes are filled by enrichers: the pull request number and extra context
+	// such as ticket summaries, rendered after the subject.
+	PR    int      `json:"pr,omitempty"`
+	Notes []string `json:"notes,omitempty"`
+}
+
+// ParseCommit parses a commit message into an entry. Non-conventional subjects keep an empty Type.
+func ParseCommit(hash, message, author string, date time.Time) ChangelogEntry {
+	message = strings.TrimSpace(message)
+	subject, body, _ := strings.Cut(message, "\n")
+	subject = strings.TrimSpace(subject)
+
+	entry := ChangelogEntry{Hash: hash, Subject: subject, Author: author, Date: date}
+	if m := conventionalPattern.FindStringSubmatch(subject); m != nil {
+		entry.Type = strings.ToLower(m[1])
+		entry.Scope = strings.TrimSpace(m[2])
+		entry.Breaking = m[3] == "!"
+		entry.Subject = strings.TrimSpace(m[4])
+	}
+	if strings.Contains(body, "BREAKING CHANGE:") || strings.Contains(body, "BREAKING-CHANGE:") {
+		entry.Breaking = true
+	}
+	entry.Skip = skipPattern.MatchString(message)
+	for _, line := range strings.Split(body, "\n") {
+		if refs, ok := strings.CutPrefix(strings.TrimSpace(line), "Refs:"); ok {
+			entry.Refs = append(entry.Refs, strings.FieldsFunc(refs, func(r rune) bool { return r == ',' || r == ' ' })...)
+		}
+	}
+	return entry
+}
+
+// Section maps the entry to a section of the standard layout, see Layout.Section.
+func (e ChangelogEntry) Section() string {
+	return Layout{}.Section(e)
+}
+
+// Header rebuilds the subject line of the commit: `type(scope)!: subject`.
+func (e ChangelogEntry) Header() string {
+	if e.Type == "" {
+		return e.Subject
+	}
+	h := e.Type
+	if e.Scope != "" {
+		h += "(" + e.Scope + ")"
+	}
+	if e.Breaking {
+		h += "!"
+	}
+	return h + ": " + e.Subject
+}
+
+// Line renders the entry as a markdown bullet.
+func (e ChangelogEntry) Line() string {
+	var b strings.Builder
+	b.WriteString("- ")
+	if e.Breaking {
+		b.WriteString("**BREAKING** ")
+	}
+	if e.Scope != "" {
+		b.WriteString("**" + e.Scope + "**: ")
+	}
+	b.WriteString(e.Subject)
+	if e.PR > 0 {
+		fmt.Fprintf(&b, " (#%d)", e.PR)
+	}
+	if len(e.Notes) > 0 {
+		b.WriteString(" — " + strings.Join(e.Notes, "; "))
+	}
+	if len(e.Hash) >= 7 {
+		b.WriteString(" (" + e.Hash[:7] + ")")
+	}
+	return b.String()
+}
+
+// Group buckets entries by the sections of the standard layout, see Layout.Group.
+func Group(entries []ChangelogEntry) map[string][]ChangelogEntry {
+	return Layout{}.Group(entries)
+}
+
+// Render converts entries grouped in the standard layout to section bodies, see Layout.Render.
+func Render(groups map[string][]ChangelogEntry) map[string]string {
+	return Layout{}.Render(groups)
+}
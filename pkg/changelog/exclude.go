@@ -0,0 +1,74 @@
+package changelog
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// skipPattern marks a commit to leave out of the changelog, in its subject or body.
+	skipPattern = regexp.MustCompile(`(?i)\[(?:skip changelog|changelog skip|no changelog)\]`)
+	// mergeBackPattern matches merge-back subjects, including merges that were squashed or
+	// rebased and so survive `--no-merges`.
+	mergeBackPattern = regexp.MustCompile(`(?i)^(?:merge (?:remote-tracking )?branch '[^']+'(?: of \S+)? into \S+|merge[- ]back\b)`)
+)
+
+// Exclusions drop commits from a changelog before grouping.
+type Exclusions struct {
+	// Patterns are regular expressions matched against the full subject line, e.g.
+	// `^chore\(release\)` or `typo`.
+	Patterns []string
+	// Deps drops dependency bumps: chore(deps), chore(deps-dev) and build(deps).
+	Deps bool
+	// MergeBack drops merge-back commits such as "Merge branch 'main' into feature".
+	MergeBack bool
+	// KeepSkipped keeps commits marked `[skip changelog]`, which are dropped otherwise.
+	KeepSkipped bool
+}
+
+// Exclude returns the entries not dropped by x.
+func Exclude(entries []ChangelogEntry, x Exclusions) ([]ChangelogEntry, error) {
+	patterns := make([]*regexp.Regexp, 0, len(x.Patterns))
+	for _, p := range x.Patterns {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("exclude pattern %q: %w", p, err)
+		}
+		patterns = append(patterns, re)
+	}
+
+	out := entries[:0:0]
+	for _, e := range entries {
+		if !excluded(e, x, patterns) {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func excluded(e ChangelogEntry, x Exclusions, patterns []*regexp.Regexp) bool {
+	if e.Skip && !x.KeepSkipped {
+		return true
+	}
+	if x.Deps && (e.Type == "chore" || e.Type == "build") {
+		for _, part := range scopeParts(e.Scope) {
+			if part = strings.ToLower(part); part == "deps" || part == "deps-dev" {
+				return true
+			}
+		}
+	}
+	header := e.Header()
+	if x.MergeBack && (mergeBackPattern.MatchString(header) || mergeBackPattern.MatchString(e.Subject)) {
+		return true
+	}
+	for _, re := range patterns {
+		if re.MatchString(header) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+package changelog
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FragmentDir is the directory, relative to the repository root, holding changelog
+// fragments that are written by hand instead of derived from commit messages.
+const FragmentDir = "changelog.d"
+
+var (
+	fragmentTypePattern = regexp.MustCompile(`^[a-z]+$`)
+	fragmentNamePattern = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// Fragment is one changelog.d file named `<name>.<type>.md`; type is a conventional
+// commit type and picks the section like it does for commits.
+type Fragment struct {
+	Path string
+	Name string
+	Type string
+	Text string
+}
+
+// Entry converts the fragment to a changelog entry; continuation lines are indented
+// under the bullet.
+func (f Fragment) Entry() ChangelogEntry {
+	lines := strings.Split(strings.TrimSpace(f.Text), "\n")
+	for i := 1; i < len(lines); i++ {
+		if line := strings.TrimSpace(lines[i]); line != "" {
+			lines[i] = "  " + line
+		} else {
+			lines[i] = ""
+		}
+	}
+	return ChangelogEntry{Type: f.Type, Subject: strings.Join(lines, "\n")}
+}
+
+// WriteFragment writes text as a `<name>.<typ>.md` fragment in dir and returns its path.
+// An empty name is derived from the text (or the time for non-ASCII text); a numeric
+// suffix avoids overwriting an existing fragment.
+func WriteFragment(dir, typ, text, name string) (string, error) {
+	typ = strings.ToLower(strings.TrimSpace(typ))
+	if !fragmentTypePattern.MatchString(typ) {
+		return "", fmt.Errorf("invalid fragment type %q, use a conventional type such as feat, fix, docs", typ)
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", errors.New("fragment text is empty")
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = fragmentName(text, time.Now())
+	}
+	if strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("invalid fragment name %q", name)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	for i := 1; ; i++ {
+		base := name
+		if i > 1 {
+			base = fmt.Sprintf("%s-%d", name, i)
+		}
+		path := filepath.Join(dir, base+"."+typ+".md")
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		_, err = f.WriteString(text + "\n")
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		return path, err
+	}
+}
+
+func fragmentName(text string, now time.Time) string {
+	subject, _, _ := strings.Cut(text, "\n")
+	name := strings.Trim(fragmentNamePattern.ReplaceAllString(strings.ToLower(subject), "-"), "-")
+	if len(name) > 40 {
+		name = strings.TrimRight(name[:40], "-")
+	}
+	if name == "" {
+		name = now.Format("20060102-150405")
+	}
+	return name
+}
+
+// ReadFragments returns the fragments in dir sorted by file name; a missing dir has none.
+// Hidden files and README files are ignored, other files not named `<name>.<type>.md`
+// are an error so a misnamed fragment is not silently dropped.
+func ReadFragments(dir string) ([]Fragment, error) {
+	items, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var fragments []Fragment
+	for _, item := range items {
+		fileName := item.Name()
+		if item.IsDir() || strings.HasPrefix(fileName, ".") || strings.HasPrefix(strings.ToUpper(fileName), "README") {
+			continue
+		}
+		base, ok := strings.CutSuffix(fileName, ".md")
+		dot := strings.LastIndex(base, ".")
+		if !ok || dot <= 0 || !fragmentTypePattern.MatchString(base[dot+1:]) {
+			return nil, fmt.Errorf("%s: fragment files are named <name>.<type>.md", filepath.Join(dir, fileName))
+		}
+		path := filepath.Join(dir, fileName)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(string(content)) == "" {
+			continue
+		}
+		fragments = append(fragments, Fragment{Path: path, Name: base[:dot], Type: base[dot+1:], Text: strings.TrimSpace(string(content))})
+	}
+	sort.Slice(fragments, func(i, j int) bool { return fragments[i].Path < fragments[j].Path })
+	return fragments, nil
+}
+
+// FromFragments groups and renders fragments in layout like Generate does for commits.
+func FromFragments(fragments []Fragment, layout Layout) Result {
+	entries := make([]ChangelogEntry, 0, len(fragments))
+	for _, f := range fragments {
+		entries = append(entries, f.Entry())
+	}
+	return layout.result(FragmentDir, entries, Stats{Total: len(fragments)})
+}
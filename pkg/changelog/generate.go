@@ -0,0 +1,201 @@
+package changelog
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pubgo/fastgit/pkg/gitshell"
+)
+
+// Options select the commits Generate reads.
+type Options struct {
+	// From is the exclusive start ref; empty means the latest tag reachable from To,
+	// or the whole history when there is none.
+	From string
+	// To is the end ref, HEAD when empty.
+	To string
+	// FullHistory reads every commit reachable from To and ignores From, e.g. for the
+	// first release, whose tag has no predecessor.
+	FullHistory bool
+	// NoCache parses every commit instead of reusing `.git/fastgit/changelog-cache.json`.
+	NoCache bool
+	// Commit limits the changelog to a single commit, see CommitRange; From and To are ignored.
+	Commit string
+	// Scopes keeps only entries whose conventional scope matches one of them, see MatchScope.
+	Scopes []string
+	// ExcludeScopes drops entries whose every scope matches one of them, e.g. `deps`.
+	ExcludeScopes []string
+	// Exclude drops commits by subject pattern, dependency bumps, merge-backs and
+	// `[skip changelog]` markers.
+	Exclude Exclusions
+	// Paths limits the commits to those touching the git pathspecs, for per-package
+	// changelogs in a monorepo; a Go-style `pkg/foo/...` selects the directory.
+	Paths []string
+	// TagPrefix selects the package's tags `<prefix>v*` (e.g. `foo/` for `foo/v1.2.0`)
+	// when resolving the latest tag and walking releases.
+	TagPrefix string
+	// Layout maps entries to sections, the standard layout when zero.
+	Layout Layout
+}
+
+// Result is a generated changelog.
+type Result struct {
+	// Range is the revision range that was read, e.g. `v1.2.0..HEAD`.
+	Range string
+	// Entries are grouped in Layout.Titles order.
+	Entries []ChangelogEntry
+	// Sections maps each section title to its rendered markdown list.
+	Sections map[string]string
+	Stats    Stats
+	// Layout is the section layout the entries were grouped with.
+	Layout Layout
+}
+
+// Generate collects, groups and renders the conventional commits of the repository
+// at repoRoot for opts.
+func Generate(ctx context.Context, repoRoot string, opts Options) (Result, error) {
+	revRange, err := resolveRange(ctx, repoRoot, opts)
+	if err != nil {
+		return Result{}, err
+	}
+
+	var cache *Cache
+	if !opts.NoCache {
+		c, err := OpenCache(ctx, repoRoot)
+		if err != nil {
+			return Result{}, err
+		}
+		cache = c
+	}
+
+	entries, stats, err := Collect(ctx, repoRoot, revRange, cache, Pathspecs(opts.Paths)...)
+	if err != nil {
+		return Result{}, err
+	}
+	entries = FilterScopes(entries, opts.Scopes, opts.ExcludeScopes)
+	if entries, err = Exclude(entries, opts.Exclude); err != nil {
+		return Result{}, err
+	}
+	if cache != nil {
+		if err := cache.Save(); err != nil {
+			return Result{}, fmt.Errorf("save changelog cache: %w", err)
+		}
+	}
+
+	return opts.Layout.result(revRange, entries, stats), nil
+}
+
+// FilterScopes returns the entries whose scope matches one of scopes (all entries when
+// scopes is empty) minus those excluded: an entry is excluded when each of its scopes
+// matches one of exclude, so `feat(api,deps)` survives excluding `deps`.
+func FilterScopes(entries []ChangelogEntry, scopes, exclude []string) []ChangelogEntry {
+	if len(scopes) == 0 && len(exclude) == 0 {
+		return entries
+	}
+	var out []ChangelogEntry
+	for _, e := range entries {
+		if len(scopes) > 0 && !MatchScope(e.Scope, scopes) {
+			continue
+		}
+		if len(exclude) > 0 && excludedScope(e.Scope, exclude) {
+			continue
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+func excludedScope(scope string, exclude []string) bool {
+	parts := scopeParts(scope)
+	for _, part := range parts {
+		if !MatchScope(part, exclude) {
+			return false
+		}
+	}
+	return len(parts) > 0
+}
+
+func scopeParts(scope string) []string {
+	return strings.FieldsFunc(scope, func(r rune) bool { return r == ',' || r == ' ' })
+}
+
+// MatchScope reports whether a commit scope matches one of scopes, ignoring case. Multi-scope
+// commits (`feat(api,cli): …`) match on any part and nested scopes match their parent:
+// `api` matches `api/users`. Unscoped commits never match.
+func MatchScope(scope string, scopes []string) bool {
+	for _, part := range scopeParts(scope) {
+		for _, want := range scopes {
+			want = strings.TrimSpace(want)
+			if want == "" {
+				continue
+			}
+			if strings.EqualFold(part, want) || strings.HasPrefix(strings.ToLower(part), strings.ToLower(want)+"/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Pathspecs converts paths to git pathspecs: a trailing Go-style `/...` is dropped since a
+// directory pathspec already matches everything below it, and empty entries are skipped.
+func Pathspecs(paths []string) []string {
+	var specs []string
+	for _, p := range paths {
+		p = strings.TrimSpace(p)
+		if p == "..." || p == "./..." {
+			p = "."
+		} else {
+			p = strings.TrimSuffix(p, "/...")
+		}
+		if p != "" {
+			specs = append(specs, p)
+		}
+	}
+	return specs
+}
+
+func resolveRange(ctx context.Context, repoRoot string, opts Options) (string, error) {
+	if commit := strings.TrimSpace(opts.Commit); commit != "" {
+		return CommitRange(ctx, repoRoot, commit)
+	}
+
+	to := strings.TrimSpace(opts.To)
+	if to == "" {
+		to = "HEAD"
+	}
+	if opts.FullHistory {
+		return to, nil
+	}
+	from := strings.TrimSpace(opts.From)
+	if from == "" {
+		args := []string{"describe", "--tags", "--abbrev=0"}
+		if opts.TagPrefix != "" {
+			args = append(args, "--match", opts.TagPrefix+"v*")
+		}
+		from, _ = gitshell.Run(ctx, repoRoot, append(args, to)...)
+	}
+	if from == "" {
+		return to, nil
+	}
+	return from + ".." + to, nil
+}
+
+// CommitRange returns the revision range holding just rev: the commit itself, or
+// for a merge commit the commits it brought in from its other parents.
+func CommitRange(ctx context.Context, repoRoot, rev string) (string, error) {
+	out, err := gitshell.Run(ctx, repoRoot, "rev-list", "--parents", "-n", "1", rev+"^{commit}", "--")
+	if err != nil {
+		return "", fmt.Errorf("unknown commit %q: %w", rev, err)
+	}
+	fields := strings.Fields(out)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("unknown commit %q", rev)
+	}
+	sha := fields[0]
+	if len(fields) > 2 {
+		return sha + "^1.." + sha, nil
+	}
+	return sha + "^!", nil
+}
@@ -0,0 +1,153 @@
+package changelog
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pubgo/fastgit/pkg/gitshell"
+	"github.com/pubgo/fastgit/pkg/semtag"
+)
+
+// Unreleased is the Version of the release holding the commits after the newest tag.
+const Unreleased = "Unreleased"
+
+// Release is the changelog of one tagged version.
+type Release struct {
+	// Version is the tag name, or Unreleased.
+	Version string
+	// Date is the tag date (tagger date for annotated tags, commit date otherwise);
+	// zero for Unreleased.
+	Date time.Time
+	Result
+}
+
+// GenerateAll walks every `<opts.TagPrefix>v*` tag reachable from opts.To and generates one release per
+// tag pair (v1.0.0 is the history up to its tag, then v1.0.0..v1.1.0, …), newest first.
+// Commits after the newest tag are returned first as Unreleased; opts.Paths limits every
+// release to the commits touching them. opts.From and opts.Commit are ignored.
+func GenerateAll(ctx context.Context, repoRoot string, opts Options) ([]Release, error) {
+	to := strings.TrimSpace(opts.To)
+	if to == "" {
+		to = "HEAD"
+	}
+	tags, err := releaseTags(ctx, repoRoot, to, opts.TagPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var cache *Cache
+	if !opts.NoCache {
+		if cache, err = OpenCache(ctx, repoRoot); err != nil {
+			return nil, err
+		}
+	}
+
+	var releases []Release
+	prev := ""
+	for _, tag := range append(tags, Release{Version: Unreleased}) {
+		end := tag.Version
+		if end == Unreleased {
+			end = to
+		}
+		revRange := end
+		if prev != "" {
+			revRange = prev + ".." + end
+		}
+		entries, stats, err := Collect(ctx, repoRoot, revRange, cache, Pathspecs(opts.Paths)...)
+		if err != nil {
+			return nil, err
+		}
+		entries = FilterScopes(entries, opts.Scopes, opts.ExcludeScopes)
+		if entries, err = Exclude(entries, opts.Exclude); err != nil {
+			return nil, err
+		}
+		prev = tag.Version
+		if tag.Version == Unreleased && len(entries) == 0 {
+			continue
+		}
+
+		tag.Result = opts.Layout.result(revRange, entries, stats)
+		releases = append(releases, tag)
+	}
+	if cache != nil {
+		if err := cache.Save(); err != nil {
+			return nil, fmt.Errorf("save changelog cache: %w", err)
+		}
+	}
+
+	slices.Reverse(releases)
+	return releases, nil
+}
+
+// releaseTags returns the semver `<prefix>v*` tags merged into to with their dates, oldest
+// first. Versions keep the prefix since they double as tag names.
+func releaseTags(ctx context.Context, repoRoot, to, prefix string) ([]Release, error) {
+	out, err := gitshell.Run(ctx, repoRoot, "-c", "versionsort.suffix=-", "for-each-ref", "--sort=-v:refname",
+		"--merged="+to, "--format=%(refname:lstrip=2)%09%(creatordate:iso-strict)", "refs/tags/"+prefix+"v*")
+	if err != nil {
+		return nil, fmt.Errorf("list tags: %w", err)
+	}
+
+	dates := make(map[string]time.Time)
+	var names []string
+	for _, line := range strings.Split(out, "\n") {
+		name, date, ok := strings.Cut(strings.TrimSpace(line), "\t")
+		if !ok {
+			continue
+		}
+		names = append(names, name)
+		dates[name], _ = time.Parse(time.RFC3339, date)
+	}
+
+	versions, _ := semtag.Parse(strings.Join(names, "\n"), prefix)
+	tags := make([]Release, 0, len(versions))
+	for _, v := range versions {
+		name := prefix + v.Original()
+		tags = append(tags, Release{Version: name, Date: dates[name]})
+	}
+	slices.Reverse(tags)
+	return tags, nil
+}
+
+// RenderReleases renders releases as a complete CHANGELOG.md: one `## version - date`
+// heading per release and its non-empty sections.
+func RenderReleases(releases []Release) string {
+	var b strings.Builder
+	b.WriteString("# Changelog\n")
+	for _, r := range releases {
+		b.WriteString("\n" + renderRelease(r, false))
+	}
+	return b.String()
+}
+
+// renderRelease renders one release heading and its non-empty sections; bracket writes
+// the keep-a-changelog form `## [version] - date`.
+func renderRelease(r Release, bracket bool) string {
+	var b strings.Builder
+	if bracket {
+		b.WriteString("## [" + r.Version + "]")
+	} else {
+		b.WriteString("## " + r.Version)
+	}
+	if !r.Date.IsZero() {
+		b.WriteString(" - " + r.Date.Format(time.DateOnly))
+	}
+	b.WriteString("\n")
+
+	empty := true
+	for _, title := range r.Layout.Titles() {
+		body := r.Sections[title]
+		if body == "" || body == "暂无" {
+			continue
+		}
+		empty = false
+		fmt.Fprintf(&b, "\n### %s\n\n%s\n", title, body)
+	}
+	if empty {
+		b.WriteString("\n暂无变更\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,169 @@
+package changelog
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// SectionRule maps conventional commit types to a section, e.g. `sec` to "安全".
+type SectionRule struct {
+	// Title is the section heading; a standard title moves that section or adds types to it.
+	Title string `yaml:"title"`
+	// Types are matched case-insensitively against the commit type.
+	Types []string `yaml:"types"`
+	// Pattern is a regular expression matched against the lower-cased commit type,
+	// e.g. `^(sec|security)$`; `^$` matches non-conventional commits.
+	Pattern string `yaml:"pattern"`
+	// Hidden drops matching entries instead of listing them; breaking changes are kept
+	// and fall through to the next rule.
+	Hidden bool `yaml:"hidden"`
+}
+
+type sectionRule struct {
+	SectionRule
+	re *regexp.Regexp
+}
+
+func (r sectionRule) match(typ string) bool {
+	for _, t := range r.Types {
+		if strings.EqualFold(strings.TrimSpace(t), typ) {
+			return true
+		}
+	}
+	return r.re != nil && r.re.MatchString(typ)
+}
+
+// Layout maps entries to sections and orders the sections. The zero value is the standard
+// layout: feat, fix and docs to their sections and everything else to SectionChanged.
+type Layout struct {
+	rules  []sectionRule
+	titles []string
+}
+
+// NewLayout builds a layout from configured rules: rules are tried in order before the
+// built-in feat/fix/docs mapping, and Titles lists the titles of the rules in order
+// followed by the standard sections not mentioned. No rules is the standard layout.
+func NewLayout(rules []SectionRule) (Layout, error) {
+	if len(rules) == 0 {
+		return Layout{}, nil
+	}
+	compiled := make([]sectionRule, 0, len(rules))
+	order := make([]string, 0, len(rules)+len(Sections))
+	for i, r := range rules {
+		r.Title = strings.TrimSpace(r.Title)
+		if len(r.Types) == 0 && r.Pattern == "" {
+			return Layout{}, fmt.Errorf("changelog section rule %d: set types or pattern", i+1)
+		}
+		if r.Title == "" && !r.Hidden {
+			return Layout{}, fmt.Errorf("changelog section rule %d: title is required unless hidden", i+1)
+		}
+		rule := sectionRule{SectionRule: r}
+		if r.Pattern != "" {
+			re, err := regexp.Compile(r.Pattern)
+			if err != nil {
+				return Layout{}, fmt.Errorf("changelog section rule %d: %w", i+1, err)
+			}
+			rule.re = re
+		}
+		compiled = append(compiled, rule)
+		if !r.Hidden && !slices.Contains(order, r.Title) {
+			order = append(order, r.Title)
+		}
+	}
+	for _, title := range Sections {
+		if !slices.Contains(order, title) {
+			order = append(order, title)
+		}
+	}
+	return Layout{rules: compiled, titles: order}, nil
+}
+
+// Titles returns the section titles in render order.
+func (l Layout) Titles() []string {
+	if len(l.titles) == 0 {
+		return Sections
+	}
+	return l.titles
+}
+
+// Section maps e to its section: the first matching rule, then feat, fix and docs to
+// their standard sections and everything else to SectionChanged. It is empty for
+// entries hidden by a rule.
+func (l Layout) Section(e ChangelogEntry) string {
+	if title, ok := l.configuredSection(e); ok {
+		return title
+	}
+	switch e.Type {
+	case "feat":
+		return SectionAdded
+	case "fix":
+		return SectionFixed
+	case "docs":
+		return SectionDocs
+	default:
+		return SectionChanged
+	}
+}
+
+// Group buckets entries by section, keeping input order inside each section.
+// Merge commits, release bookkeeping commits and hidden entries are skipped.
+func (l Layout) Group(entries []ChangelogEntry) map[string][]ChangelogEntry {
+	out := make(map[string][]ChangelogEntry, len(l.Titles()))
+	for _, e := range entries {
+		if strings.HasPrefix(e.Subject, "Merge ") && e.Type == "" {
+			continue
+		}
+		if section := l.Section(e); section != "" {
+			out[section] = append(out[section], e)
+		}
+	}
+	return out
+}
+
+// Render converts grouped entries to section bodies suitable for Unreleased.md.
+func (l Layout) Render(groups map[string][]ChangelogEntry) map[string]string {
+	out := make(map[string]string, len(l.Titles()))
+	for _, title := range l.Titles() {
+		lines := make([]string, 0, len(groups[title]))
+		for _, e := range groups[title] {
+			lines = append(lines, e.Line())
+		}
+		if len(lines) == 0 {
+			out[title] = "暂无"
+			continue
+		}
+		out[title] = strings.Join(lines, "\n")
+	}
+	return out
+}
+
+// result groups entries into a Result whose Entries follow the section order.
+func (l Layout) result(revRange string, entries []ChangelogEntry, stats Stats) Result {
+	groups := l.Group(entries)
+	var grouped []ChangelogEntry
+	for _, title := range l.Titles() {
+		grouped = append(grouped, groups[title]...)
+	}
+	return Result{Range: revRange, Entries: grouped, Sections: l.Render(groups), Stats: stats, Layout: l}
+}
+
+// configuredSection returns the section of the first rule matching e, "" when that rule
+// hides it; ok is false when no rule matches.
+func (l Layout) configuredSection(e ChangelogEntry) (title string, ok bool) {
+	typ := strings.ToLower(e.Type)
+	for _, r := range l.rules {
+		if !r.match(typ) {
+			continue
+		}
+		if r.Hidden {
+			if e.Breaking {
+				continue
+			}
+			return "", true
+		}
+		return r.Title, true
+	}
+	return "", false
+}
@@ -0,0 +1,94 @@
+package changelog
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Changelog is the data of a user-supplied changelog template (`changelog generate --template`).
+type Changelog struct {
+	// Range is the revision range that was read, e.g. `v1.2.0..HEAD`.
+	Range string
+	Date  time.Time
+	// Sections are the sections in render order, including empty ones.
+	Sections []Section
+	// Entries are the entries of Sections concatenated, so they follow the section order even
+	// when res.Entries was reordered, e.g. by an enricher.
+	Entries []ChangelogEntry
+}
+
+// Section is one changelog section and its entries.
+type Section struct {
+	Title   string
+	Entries []ChangelogEntry
+}
+
+// NewChangelog builds the template data of a generated result, grouping res.Entries by
+// res.Layout; entries of hidden sections are left out.
+func NewChangelog(res Result, now time.Time) Changelog {
+	groups := make(map[string][]ChangelogEntry, len(res.Layout.Titles()))
+	for _, e := range res.Entries {
+		section := res.Layout.Section(e)
+		groups[section] = append(groups[section], e)
+	}
+	cl := Changelog{Range: res.Range, Date: now}
+	for _, title := range res.Layout.Titles() {
+		cl.Sections = append(cl.Sections, Section{Title: title, Entries: groups[title]})
+		cl.Entries = append(cl.Entries, groups[title]...)
+	}
+	return cl
+}
+
+// Breaking returns the entries marked as breaking changes.
+func (c Changelog) Breaking() []ChangelogEntry {
+	var out []ChangelogEntry
+	for _, e := range c.Entries {
+		if e.Breaking {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// templateFuncs are available to changelog templates besides the text/template builtins.
+var templateFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"trim":  strings.TrimSpace,
+	"short": func(hash string) string {
+		if len(hash) > 7 {
+			return hash[:7]
+		}
+		return hash
+	},
+	"date": func(layout string, t time.Time) string { return t.Format(layout) },
+}
+
+// RenderTemplate executes the Go text/template text with cl; join, upper, lower, trim,
+// short (7-char hash) and date (`date "2006-01-02" .Date`) are available.
+func RenderTemplate(name, text string, cl Changelog) (string, error) {
+	tpl, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("parse changelog template %s: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, cl); err != nil {
+		return "", fmt.Errorf("render changelog template %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// RenderTemplateFile reads the template at path and executes it with cl.
+func RenderTemplateFile(path string, cl Changelog) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read changelog template: %w", err)
+	}
+	return RenderTemplate(filepath.Base(path), string(data), cl)
+}
@@ -0,0 +1,107 @@
+package changelog
+
+import (
+	"os"
+	"strings"
+)
+
+// releaseBlock is one `## ` section of an existing changelog file.
+type releaseBlock struct {
+	version string
+	text    string
+}
+
+// Update merges releases (newest first) into the content of an existing changelog:
+// the Unreleased section is replaced or inserted below the title, releases whose
+// version has no heading yet are added in order, and every other section, including
+// hand-written ones, is kept verbatim. Headings follow the file's style (`## [v1.2.0]`
+// or `## v1.2.0`); an empty file gets a `# Changelog` title.
+func Update(existing string, releases []Release) string {
+	title, blocks := splitReleases(existing)
+	if strings.TrimSpace(title) == "" {
+		title = "# Changelog"
+	}
+	bracket := len(blocks) == 0 || strings.HasPrefix(blocks[0].text, "## [")
+
+	found := make(map[string]int, len(blocks))
+	for i, b := range blocks {
+		found[b.version] = i
+	}
+	used := make(map[int]bool)
+	var out []string
+	for _, r := range releases {
+		i, ok := found[normalizeVersion(r.Version)]
+		switch {
+		case ok && r.Version != Unreleased:
+			used[i] = true
+			out = append(out, blocks[i].text)
+		default:
+			if ok {
+				used[i] = true
+			}
+			out = append(out, renderRelease(r, bracket))
+		}
+	}
+	for i, b := range blocks {
+		if !used[i] {
+			out = append(out, b.text)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(strings.TrimRight(title, "\n") + "\n")
+	for _, text := range out {
+		sb.WriteString("\n" + strings.TrimRight(text, "\n") + "\n")
+	}
+	return sb.String()
+}
+
+// UpdateFile applies Update to the changelog at path, creating it when missing.
+func UpdateFile(path string, releases []Release) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.WriteFile(path, []byte(Update(string(data), releases)), 0o644)
+}
+
+// splitReleases splits a changelog into the part before the first `## ` heading and one
+// block per `## ` heading; headings inside code fences are ignored.
+func splitReleases(content string) (string, []releaseBlock) {
+	var (
+		title  strings.Builder
+		blocks []releaseBlock
+		fence  bool
+	)
+	for _, line := range strings.SplitAfter(content, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			fence = !fence
+		}
+		if !fence && strings.HasPrefix(line, "## ") {
+			blocks = append(blocks, releaseBlock{version: headingVersion(line)})
+		}
+		if len(blocks) == 0 {
+			title.WriteString(line)
+		} else {
+			blocks[len(blocks)-1].text += line
+		}
+	}
+	return title.String(), blocks
+}
+
+// headingVersion returns the normalized version of a `## [v1.2.0] - 2024-01-02` heading.
+func headingVersion(line string) string {
+	fields := strings.Fields(strings.TrimPrefix(line, "## "))
+	if len(fields) == 0 {
+		return ""
+	}
+	return normalizeVersion(strings.Trim(fields[0], "[]"))
+}
+
+// normalizeVersion makes `v1.2.0`, `1.2.0` and `unreleased` compare equal to their variants.
+func normalizeVersion(v string) string {
+	if strings.EqualFold(v, Unreleased) {
+		return strings.ToLower(Unreleased)
+	}
+	return strings.TrimPrefix(v, "v")
+}
@@ -0,0 +1,236 @@
+// Package commitlint checks commit messages against the commitlint rules fastgit understands
+// (type-enum, subject-case, header-max-length, body-max-line-length), read from `.commitlintrc`
+// or fastgit's own config.
+package commitlint
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	RuleTypeEnum          = "type-enum"
+	RuleSubjectCase       = "subject-case"
+	RuleHeaderMaxLength   = "header-max-length"
+	RuleBodyMaxLineLength = "body-max-line-length"
+	RuleTrailerExists     = "trailer-exists"
+)
+
+// Level is the commitlint severity: 1 reports a warning, 2 blocks the commit.
+type Level int
+
+const (
+	LevelWarning Level = 1
+	LevelError   Level = 2
+)
+
+// Rules is a commitlint rule set. Zero values disable a rule.
+type Rules struct {
+	Types []string `yaml:"types"`
+	// SubjectCase lists the cases the subject must use (lower-case, sentence-case, start-case,
+	// pascal-case, upper-case, camel-case, kebab-case, snake-case), or must not use with SubjectCaseNever.
+	SubjectCase       []string `yaml:"subject_case"`
+	SubjectCaseNever  bool     `yaml:"subject_case_never"`
+	HeaderMaxLength   int      `yaml:"header_max_length"`
+	BodyMaxLineLength int      `yaml:"body_max_line_length"`
+	// Trailers lists keys that must appear as `Key:` lines after the header, e.g. Signed-off-by
+	// or the sections of a git commit template.
+	Trailers []string `yaml:"trailers"`
+	// Warn lists rule names that are reported without blocking the commit (commitlint level 1).
+	Warn []string `yaml:"warn"`
+}
+
+// Violation is one failed rule.
+type Violation struct {
+	Rule    string
+	Level   Level
+	Message string
+}
+
+func (v Violation) String() string {
+	mark := "✖"
+	if v.Level == LevelWarning {
+		mark = "⚠"
+	}
+	return fmt.Sprintf("%s %s [%s]", mark, v.Message, v.Rule)
+}
+
+var headerPattern = regexp.MustCompile(`^(\w+)(\([^)]*\))?!?:\s*(.*)$`)
+
+// Empty reports whether no rule is enabled.
+func (r Rules) Empty() bool {
+	return len(r.Types) == 0 && len(r.SubjectCase) == 0 && r.HeaderMaxLength <= 0 && r.BodyMaxLineLength <= 0 && len(r.Trailers) == 0
+}
+
+// Merge returns r with every rule set in o overriding the one in r.
+func (r Rules) Merge(o Rules) Rules {
+	if len(o.Types) > 0 {
+		r.Types = o.Types
+	}
+	if len(o.SubjectCase) > 0 {
+		r.SubjectCase, r.SubjectCaseNever = o.SubjectCase, o.SubjectCaseNever
+	}
+	if o.HeaderMaxLength > 0 {
+		r.HeaderMaxLength = o.HeaderMaxLength
+	}
+	if o.BodyMaxLineLength > 0 {
+		r.BodyMaxLineLength = o.BodyMaxLineLength
+	}
+	if len(o.Trailers) > 0 {
+		r.Trailers = o.Trailers
+	}
+	if len(o.Warn) > 0 {
+		r.Warn = o.Warn
+	}
+	return r
+}
+
+// Lint checks message and returns the violations in rule order.
+func (r Rules) Lint(message string) []Violation {
+	message = strings.TrimSpace(message)
+	header, rest, _ := strings.Cut(message, "\n")
+	header = strings.TrimSpace(header)
+
+	var out []Violation
+	add := func(rule, format string, args ...any) {
+		level := LevelError
+		if slices.Contains(r.Warn, rule) {
+			level = LevelWarning
+		}
+		out = append(out, Violation{Rule: rule, Level: level, Message: fmt.Sprintf(format, args...)})
+	}
+
+	m := headerPattern.FindStringSubmatch(header)
+	if len(r.Types) > 0 {
+		switch {
+		case m == nil:
+			add(RuleTypeEnum, "header must look like `type(scope): subject` with type one of [%s]", strings.Join(r.Types, ", "))
+		case !slices.Contains(r.Types, m[1]):
+			add(RuleTypeEnum, "type %q must be one of [%s]", m[1], strings.Join(r.Types, ", "))
+		}
+	}
+
+	subject := header
+	if m != nil {
+		subject = m[3]
+	}
+	if len(r.SubjectCase) > 0 && hasCasedLetter(subject) {
+		matched := slices.ContainsFunc(r.SubjectCase, func(c string) bool { return matchesCase(subject, c) })
+		if r.SubjectCaseNever && matched {
+			add(RuleSubjectCase, "subject must not be %s", strings.Join(r.SubjectCase, ", "))
+		}
+		if !r.SubjectCaseNever && !matched {
+			add(RuleSubjectCase, "subject must be %s", strings.Join(r.SubjectCase, ", "))
+		}
+	}
+
+	if n := utf8.RuneCountInString(header); r.HeaderMaxLength > 0 && n > r.HeaderMaxLength {
+		add(RuleHeaderMaxLength, "header must not be longer than %d characters, current length is %d", r.HeaderMaxLength, n)
+	}
+
+	if r.BodyMaxLineLength > 0 {
+		for _, line := range strings.Split(rest, "\n") {
+			if utf8.RuneCountInString(line) > r.BodyMaxLineLength {
+				add(RuleBodyMaxLineLength, "body lines must not be longer than %d characters", r.BodyMaxLineLength)
+				break
+			}
+		}
+	}
+
+	for _, key := range r.Trailers {
+		if !hasTrailer(rest, key) {
+			add(RuleTrailerExists, "message must contain a %q line", key+":")
+		}
+	}
+	return out
+}
+
+// hasTrailer reports whether body has a `key:` line, ignoring case and indentation.
+func hasTrailer(body, key string) bool {
+	prefix := strings.ToLower(key) + ":"
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(line)), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Errors returns the violations that block the commit.
+func Errors(violations []Violation) []Violation {
+	var out []Violation
+	for _, v := range violations {
+		if v.Level == LevelError {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// Format renders violations one per line.
+func Format(violations []Violation) string {
+	lines := make([]string, 0, len(violations))
+	for _, v := range violations {
+		lines = append(lines, v.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// FixPrompt is the system prompt used to repair a message that violates the rules.
+const FixPrompt = `You fix git commit messages so they pass commitlint.
+Keep the meaning, type and scope unless a rule requires changing them; change as little as possible.
+Return only the corrected commit message, without quotes or explanations.`
+
+// FixRequest builds the user message asking the model to fix the given violations.
+func FixRequest(message string, violations []Violation) string {
+	var b strings.Builder
+	b.WriteString("Commit message:\n")
+	b.WriteString(strings.TrimSpace(message))
+	b.WriteString("\n\nViolations:\n")
+	for _, v := range violations {
+		fmt.Fprintf(&b, "- %s (%s)\n", v.Message, v.Rule)
+	}
+	return b.String()
+}
+
+func hasCasedLetter(s string) bool {
+	return strings.ContainsFunc(s, func(r rune) bool { return unicode.IsUpper(r) || unicode.IsLower(r) })
+}
+
+// matchesCase 对应 commitlint 的 case 名称（lower-case 或 lowercase）；未知名称视为匹配，避免误报
+func matchesCase(s, name string) bool {
+	words := strings.Fields(s)
+	switch strings.TrimSuffix(strings.TrimSuffix(name, "case"), "-") {
+	case "lower":
+		return s == strings.ToLower(s)
+	case "upper":
+		return s == strings.ToUpper(s)
+	case "sentence":
+		return startsUpper(s)
+	case "start":
+		return len(words) > 0 && !slices.ContainsFunc(words, func(w string) bool { return !startsUpper(w) })
+	case "pascal":
+		return len(words) == 1 && startsUpper(s) && !strings.ContainsAny(s, "-_")
+	case "camel":
+		return len(words) == 1 && !startsUpper(s) && !strings.ContainsAny(s, "-_")
+	case "kebab":
+		return kebabPattern.MatchString(s)
+	case "snake":
+		return snakePattern.MatchString(s)
+	}
+	return true
+}
+
+var (
+	kebabPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	snakePattern = regexp.MustCompile(`^[a-z0-9]+(_[a-z0-9]+)*$`)
+)
+
+func startsUpper(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return unicode.IsUpper(r)
+}
@@ -0,0 +1,99 @@
+package commitlint
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func rules(vs []Violation) []string {
+	var out []string
+	for _, v := range vs {
+		out = append(out, v.Rule)
+	}
+	return out
+}
+
+func TestLintConventional(t *testing.T) {
+	r := Conventional
+	require.Empty(t, r.Lint("feat(api): add retry to client"))
+	require.Empty(t, r.Lint("fix: 修复缓存失效"))
+	require.Empty(t, r.Lint("feat: support the OAuth flow"))
+
+	require.Equal(t, []string{RuleTypeEnum}, rules(r.Lint("feature: add retry")))
+	require.Equal(t, []string{RuleTypeEnum}, rules(r.Lint("add retry")))
+	require.Equal(t, []string{RuleSubjectCase}, rules(r.Lint("feat: Add retry")))
+	require.Equal(t, []string{RuleSubjectCase}, rules(r.Lint("feat: ADD RETRY")))
+	require.Equal(t, []string{RuleHeaderMaxLength}, rules(r.Lint("feat: "+strings.Repeat("a", 100))))
+	require.Equal(t, []string{RuleBodyMaxLineLength}, rules(r.Lint("feat: add retry\n\n"+strings.Repeat("b", 101))))
+}
+
+func TestLintAlwaysCaseAndWarn(t *testing.T) {
+	r := Rules{SubjectCase: []string{"lower-case"}, HeaderMaxLength: 20, Warn: []string{RuleHeaderMaxLength}}
+	require.Empty(t, r.Lint("fix: lower subject"))
+
+	vs := r.Lint("fix: Mixed Case Subject here")
+	require.Equal(t, []string{RuleSubjectCase, RuleHeaderMaxLength}, rules(vs))
+	require.Equal(t, []string{RuleSubjectCase}, rules(Errors(vs)))
+	require.Contains(t, Format(vs), "⚠ header must not be longer than 20 characters")
+}
+
+func TestLintTrailers(t *testing.T) {
+	r := Rules{Trailers: []string{"Ticket", "Reviewed-by"}}
+	require.Empty(t, r.Lint("feat: add retry\n\nwhy\n\nticket: ABC-1\nReviewed-by:"))
+
+	vs := r.Lint("feat: add retry\n\nTicket: ABC-1")
+	require.Equal(t, []string{RuleTrailerExists}, rules(vs))
+	require.Contains(t, Format(vs), `message must contain a "Reviewed-by:" line`)
+	// a `Ticket:` header does not count
+	require.Len(t, r.Lint("Ticket: x"), 2)
+}
+
+func TestParse(t *testing.T) {
+	r, err := Parse([]byte(`{
+  "extends": ["@commitlint/config-conventional"],
+  "rules": {
+    "type-enum": [2, "always", ["feat", "fix"]],
+    "header-max-length": [1, "always", 72],
+    "body-max-line-length": [0, "always", 100],
+    "scope-case": [2, "always", "lower-case"]
+  }
+}`))
+	require.NoError(t, err)
+	require.Equal(t, []string{"feat", "fix"}, r.Types)
+	require.Equal(t, 72, r.HeaderMaxLength)
+	require.Zero(t, r.BodyMaxLineLength)
+	require.True(t, r.SubjectCaseNever)
+	require.Equal(t, []string{RuleHeaderMaxLength}, r.Warn)
+
+	r, err = Parse([]byte("rules:\n  subject-case: [2, always, lower-case]\n"))
+	require.NoError(t, err)
+	require.Equal(t, Rules{SubjectCase: []string{"lower-case"}}, r)
+
+	r, err = Parse([]byte("rules:\n  trailer-exists: [2, always, 'Signed-off-by:']\n"))
+	require.NoError(t, err)
+	require.Equal(t, Rules{Trailers: []string{"Signed-off-by"}}, r)
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	r, path, err := Load(dir)
+	require.NoError(t, err)
+	require.Empty(t, path)
+	require.True(t, r.Empty())
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".commitlintrc.yml"), []byte("extends: '@commitlint/config-conventional'\n"), 0o644))
+	r, path, err = Load(dir)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, ".commitlintrc.yml"), path)
+	require.Equal(t, 100, r.HeaderMaxLength)
+}
+
+func TestMerge(t *testing.T) {
+	base := Rules{Types: []string{"feat"}, HeaderMaxLength: 72}
+	got := base.Merge(Rules{HeaderMaxLength: 50, SubjectCase: []string{"lower-case"}})
+	require.Equal(t, Rules{Types: []string{"feat"}, HeaderMaxLength: 50, SubjectCase: []string{"lower-case"}}, got)
+}
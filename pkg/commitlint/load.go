@@ -0,0 +1,141 @@
+package commitlint
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RCFiles are the commitlint config files read from the repo root, in lookup order.
+// JavaScript configs (commitlint.config.js) cannot be evaluated and are ignored.
+var RCFiles = []string{".commitlintrc", ".commitlintrc.json", ".commitlintrc.yaml", ".commitlintrc.yml"}
+
+// Conventional mirrors the rules of @commitlint/config-conventional that Rules supports.
+var Conventional = Rules{
+	Types:             []string{"build", "chore", "ci", "docs", "feat", "fix", "perf", "refactor", "revert", "style", "test"},
+	SubjectCase:       []string{"sentence-case", "start-case", "pascal-case", "upper-case"},
+	SubjectCaseNever:  true,
+	HeaderMaxLength:   100,
+	BodyMaxLineLength: 100,
+}
+
+type rcFile struct {
+	// Extends is a string or a list of shareable configs.
+	Extends any              `yaml:"extends"`
+	Rules   map[string][]any `yaml:"rules"`
+}
+
+// Load reads the first commitlint config in repoRoot. It returns empty rules and an empty
+// path when none exists. JSON configs are parsed as YAML, which is a superset of JSON.
+func Load(repoRoot string) (Rules, string, error) {
+	for _, name := range RCFiles {
+		path := filepath.Join(repoRoot, name)
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return Rules{}, "", err
+		}
+		rules, err := Parse(data)
+		if err != nil {
+			return Rules{}, "", fmt.Errorf("parse %s: %w", name, err)
+		}
+		return rules, path, nil
+	}
+	return Rules{}, "", nil
+}
+
+// Parse converts a commitlint config into Rules. Rule entries are `[level, "always"|"never", value]`;
+// level 0 disables the rule and level 1 turns it into a warning.
+func Parse(data []byte) (Rules, error) {
+	var rc rcFile
+	if err := yaml.Unmarshal(data, &rc); err != nil {
+		return Rules{}, err
+	}
+
+	var rules Rules
+	if slices.ContainsFunc(extendsList(rc.Extends), func(s string) bool { return strings.Contains(s, "config-conventional") }) {
+		rules = Conventional
+		rules.Types = slices.Clone(Conventional.Types)
+		rules.SubjectCase = slices.Clone(Conventional.SubjectCase)
+	}
+
+	for name, entry := range rc.Rules {
+		if len(entry) == 0 {
+			continue
+		}
+		level, _ := entry[0].(int)
+		never := len(entry) > 1 && entry[1] == "never"
+		var value any
+		if len(entry) > 2 {
+			value = entry[2]
+		}
+
+		switch name {
+		case RuleTypeEnum:
+			rules.Types = nil
+			if level > 0 && !never {
+				rules.Types = stringList(value)
+			}
+		case RuleSubjectCase:
+			rules.SubjectCase, rules.SubjectCaseNever = nil, false
+			if level > 0 {
+				rules.SubjectCase, rules.SubjectCaseNever = stringList(value), never
+			}
+		case RuleHeaderMaxLength:
+			rules.HeaderMaxLength = 0
+			if n, ok := value.(int); ok && level > 0 {
+				rules.HeaderMaxLength = n
+			}
+		case RuleBodyMaxLineLength:
+			rules.BodyMaxLineLength = 0
+			if n, ok := value.(int); ok && level > 0 {
+				rules.BodyMaxLineLength = n
+			}
+		case RuleTrailerExists:
+			rules.Trailers = nil
+			if level > 0 && !never {
+				for _, key := range stringList(value) {
+					if key = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(key), ":")); key != "" {
+						rules.Trailers = append(rules.Trailers, key)
+					}
+				}
+			}
+		default:
+			continue
+		}
+		if level == int(LevelWarning) {
+			rules.Warn = append(rules.Warn, name)
+		}
+	}
+	slices.Sort(rules.Warn)
+	return rules, nil
+}
+
+func extendsList(v any) []string {
+	if s, ok := v.(string); ok {
+		return []string{s}
+	}
+	return stringList(v)
+}
+
+func stringList(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
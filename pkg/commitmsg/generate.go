@@ -0,0 +1,146 @@
+package commitmsg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pubgo/fastgit/pkg/aiprovider"
+	"github.com/pubgo/fastgit/pkg/gitdiff"
+)
+
+// Defaults applied by Generate when Options leave them unset.
+const (
+	DefaultLocale    = "en"
+	DefaultMaxLength = 72
+)
+
+// Options configure Generate; the zero value produces a plain single message in
+// DefaultLocale within DefaultMaxLength characters.
+type Options struct {
+	Locale    string
+	MaxLength int
+	// Type is the format of the built-in prompt, e.g. ConventionalCommitType.
+	Type CommitType
+	// Types restricts the allowed conventional types.
+	Types []string
+	Scope string
+	// Ticket is a reference such as ABC-123 the change belongs to.
+	Ticket string
+	// Body asks for a wrapped body after the subject line.
+	Body bool
+	// Prompt replaces the built-in system prompt, e.g. a rendered team template.
+	Prompt string
+	// Guidance are the conventions every candidate must follow; derived from Locale
+	// and MaxLength when empty.
+	Guidance string
+	// Candidates above 1 generate that many alternatives instead of a single message.
+	Candidates int
+	// TokenBudget summarizes larger diffs in chunks before generating;
+	// gitdiff.DefaultTokenBudget when zero, negative never summarizes.
+	TokenBudget int
+}
+
+// Result is a generated message. Message is the model output as is: callers apply
+// their own scope and style formatting.
+type Result struct {
+	Message    string
+	Candidates []aiprovider.CommitCandidate
+	Provider   string
+	Fallback   bool
+	// Summarized reports that the diff exceeded TokenBudget and the model saw a summary.
+	Summarized bool
+}
+
+// Prompts returns the system prompt for a single message and the guidance for
+// candidates built from opts.
+func Prompts(opts Options) (prompt, guidance string) {
+	locale, maxLength := opts.Locale, opts.MaxLength
+	if strings.TrimSpace(locale) == "" {
+		locale = DefaultLocale
+	}
+	if maxLength <= 0 {
+		maxLength = DefaultMaxLength
+	}
+
+	prompt = opts.Prompt
+	if strings.TrimSpace(prompt) == "" {
+		prompt = GeneratePrompt(locale, maxLength, opts.Type)
+		if opts.Type != EmptyCommitType {
+			prompt = AppendScope(AppendAllowedTypes(prompt, opts.Types), opts.Scope)
+		}
+		prompt = AppendTicket(prompt, opts.Ticket)
+		if opts.Body {
+			prompt = AppendBody(prompt)
+		}
+	}
+
+	guidance = opts.Guidance
+	if strings.TrimSpace(guidance) == "" {
+		guidance = fmt.Sprintf("Message language: %s\nNo candidate may exceed %d characters.", locale, maxLength)
+	}
+	return prompt, guidance
+}
+
+// Input returns what is sent to the model for diff: the diff itself within budget,
+// otherwise a summary of its chunks followed by per-file stats. When summarizing
+// fails the diff is truncated to budget and err reports why; the input is usable
+// either way.
+func Input(ctx context.Context, ai aiprovider.Provider, diff *gitdiff.Diff, budget int) (input string, summarized bool, err error) {
+	if budget <= 0 || diff.Tokens <= budget || ai == nil || !ai.Available() {
+		return diff.Diff, false, nil
+	}
+
+	summary, ok, err := aiprovider.SummarizeDiff(ctx, ai, diff.Chunks(budget))
+	if !ok {
+		return gitdiff.Ellipse(diff.Diff, budget), false, err
+	}
+
+	var stat strings.Builder
+	for _, st := range diff.Stats {
+		_, _ = fmt.Fprintf(&stat, "%s +%d -%d\n", st.Path, st.Added, st.Removed)
+	}
+	return summary + "\nChanged files:\n" + stat.String(), true, err
+}
+
+// Generate writes a commit message for diff with ai. Candidates fall back to
+// rule-based messages when ai is unavailable; a single message requires a provider.
+func Generate(ctx context.Context, ai aiprovider.Provider, diff *gitdiff.Diff, opts Options) (Result, error) {
+	if diff == nil || (strings.TrimSpace(diff.Diff) == "" && len(diff.Files) == 0) {
+		return Result{}, errors.New("empty diff")
+	}
+
+	budget := opts.TokenBudget
+	if budget == 0 {
+		budget = gitdiff.DefaultTokenBudget
+	}
+	input, summarized, _ := Input(ctx, ai, diff, budget)
+	prompt, guidance := Prompts(opts)
+
+	if opts.Candidates > 1 {
+		candidates, err := aiprovider.GenerateCommitCandidates(ctx, ai, input, opts.Candidates, guidance)
+		if err != nil {
+			return Result{}, err
+		}
+		result := Result{Candidates: candidates, Summarized: summarized}
+		if len(candidates) > 0 {
+			result.Message = candidates[0].Message
+		}
+		return result, nil
+	}
+
+	if ai == nil {
+		return Result{}, errors.New("no AI provider configured")
+	}
+	resp, err := ai.Complete(ctx, aiprovider.CompleteRequest{System: prompt, User: input})
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{
+		Message:    strings.TrimSpace(resp.Text),
+		Provider:   resp.Provider,
+		Fallback:   resp.Fallback,
+		Summarized: summarized,
+	}, nil
+}
@@ -0,0 +1,64 @@
+package commitmsg
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pubgo/fastgit/pkg/aiprovider"
+	"github.com/pubgo/fastgit/pkg/gitdiff"
+)
+
+type stubProvider struct {
+	reply    string
+	requests []aiprovider.CompleteRequest
+}
+
+func (p *stubProvider) Name() string    { return "stub" }
+func (p *stubProvider) Available() bool { return true }
+
+func (p *stubProvider) Complete(_ context.Context, req aiprovider.CompleteRequest) (aiprovider.CompleteResponse, error) {
+	p.requests = append(p.requests, req)
+	return aiprovider.CompleteResponse{Text: p.reply, Provider: "stub"}, nil
+}
+
+func TestPrompts(t *testing.T) {
+	prompt, guidance := Prompts(Options{Type: ConventionalCommitType, Types: []string{"feat", "fix"}, Scope: "api", Ticket: "ABC-1"})
+	assert.Contains(t, prompt, "Message language: en")
+	assert.Contains(t, prompt, "maximum of 72 characters")
+	assert.Contains(t, prompt, "feat, fix")
+	assert.Contains(t, prompt, `"ABC-1"`)
+	assert.Contains(t, guidance, "72 characters")
+
+	prompt, guidance = Prompts(Options{Prompt: "team prompt", Guidance: "team rules"})
+	assert.Equal(t, "team prompt", prompt)
+	assert.Equal(t, "team rules", guidance)
+}
+
+func TestGenerate(t *testing.T) {
+	diff, err := gitdiff.Read(strings.NewReader("diff --git a/a.go b/a.go\n+++ b/a.go\n+x\n"), gitdiff.Limits{})
+	require.NoError(t, err)
+	diff.Files = []string{"a.go"}
+
+	ai := &stubProvider{reply: "  feat: add x \n"}
+	res, err := Generate(context.Background(), ai, diff, Options{Locale: "zh", MaxLength: 50})
+	require.NoError(t, err)
+	assert.Equal(t, "feat: add x", res.Message)
+	assert.Equal(t, "stub", res.Provider)
+	require.Len(t, ai.requests, 1)
+	assert.Contains(t, ai.requests[0].System, "Message language: zh")
+	assert.Equal(t, diff.Diff, ai.requests[0].User)
+
+	_, err = Generate(context.Background(), nil, diff, Options{})
+	assert.Error(t, err)
+	_, err = Generate(context.Background(), ai, &gitdiff.Diff{}, Options{})
+	assert.Error(t, err)
+
+	candidates, err := Generate(context.Background(), nil, diff, Options{Candidates: 2})
+	require.NoError(t, err)
+	assert.Len(t, candidates.Candidates, 2)
+	assert.Equal(t, candidates.Candidates[0].Message, candidates.Message)
+}
@@ -0,0 +1,117 @@
+package commitmsg
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// sectionPattern matches a `Key:` line of a commit template, e.g. `Ticket:` or `Reviewed-by: <name>`.
+var sectionPattern = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9 _-]*):(\s.*)?$`)
+
+// trailerToken matches a git trailer key such as `Signed-off-by`; prose lines like
+// `Why this change:` are sections but not trailers.
+var trailerToken = regexp.MustCompile(`^[A-Za-z-]+$`)
+
+// GitTemplate is a parsed git commit template (`commit.template` or `.gitmessage`).
+type GitTemplate struct {
+	// Text is the template with comment lines removed; its first line is the subject.
+	Text string
+	// Hints are the comment lines without the leading '#', usually instructions for the author.
+	Hints []string
+	// Sections are the `Key` names of the `Key:` lines after the subject, in template order.
+	// Teams use them for mandatory footers such as ticket or reviewers.
+	Sections []string
+}
+
+// ParseGitTemplate parses a commit template the way git uses it: lines starting with '#' are
+// comments. It returns nil when the template has neither content nor hints.
+func ParseGitTemplate(text string) *GitTemplate {
+	var (
+		lines []string
+		tpl   GitTemplate
+	)
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if hint, ok := strings.CutPrefix(line, "#"); ok {
+			if hint = strings.TrimSpace(hint); hint != "" {
+				tpl.Hints = append(tpl.Hints, hint)
+			}
+			continue
+		}
+		lines = append(lines, strings.TrimRight(line, " \t"))
+	}
+	tpl.Text = strings.TrimRight(strings.Join(lines, "\n"), "\n")
+
+	seen := make(map[string]bool)
+	for i, line := range lines {
+		if i == 0 {
+			continue
+		}
+		m := sectionPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil || seen[strings.ToLower(m[1])] {
+			continue
+		}
+		seen[strings.ToLower(m[1])] = true
+		tpl.Sections = append(tpl.Sections, m[1])
+	}
+
+	if strings.TrimSpace(tpl.Text) == "" && len(tpl.Hints) == 0 {
+		return nil
+	}
+	return &tpl
+}
+
+// HasBody reports whether the template has content after the subject line, i.e. the
+// generated message is multi-line.
+func (t *GitTemplate) HasBody() bool {
+	if t == nil {
+		return false
+	}
+	_, body, _ := strings.Cut(t.Text, "\n")
+	return strings.TrimSpace(body) != ""
+}
+
+// Trailers returns the sections whose key is a real trailer token (letters and dashes, no spaces).
+func (t *GitTemplate) Trailers() []string {
+	if t == nil {
+		return nil
+	}
+	var keys []string
+	for _, key := range t.Sections {
+		if trailerToken.MatchString(key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// AppendGitTemplate asks the model to fill in the team commit template instead of writing a
+// free-form message, keeping its structure and every `Key:` section.
+func AppendGitTemplate(prompt string, tpl *GitTemplate) string {
+	if tpl == nil {
+		return prompt
+	}
+	var sb strings.Builder
+	sb.WriteString(prompt)
+	sb.WriteString("\nThe repository has a commit template (git commit.template). Fill it in instead of writing a free-form message.")
+	if strings.TrimSpace(tpl.Text) != "" {
+		fmt.Fprintf(&sb, "\nTemplate:\n```\n%s\n```", tpl.Text)
+		sb.WriteString("\nKeep the template's lines in order and replace placeholders such as <...> or empty values with content from the diff. The first line is the subject line.")
+	}
+	if len(tpl.Hints) > 0 {
+		sb.WriteString("\nTemplate guidance for the author:\n- " + strings.Join(tpl.Hints, "\n- "))
+	}
+	if len(tpl.Sections) > 0 {
+		keys := make([]string, len(tpl.Sections))
+		for i, key := range tpl.Sections {
+			keys[i] = key + ":"
+		}
+		fmt.Fprintf(&sb, "\nThe message must keep each of these sections on its own line: %s. Fill in a value when the diff or ticket gives one, otherwise keep the line as in the template.",
+			strings.Join(keys, ", "))
+	}
+	if tpl.HasBody() {
+		sb.WriteString("\nThe length limit applies to the subject line only.")
+	}
+	sb.WriteString("\nDo not output comment lines starting with '#' or the ``` fences.")
+	return sb.String()
+}
@@ -0,0 +1,41 @@
+package commitmsg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGitTemplate(t *testing.T) {
+	tpl := ParseGitTemplate("<type>: <summary>\n\n# Why is this change needed?\n<body>\n\nTicket: \nReviewers:\nticket: dup\n#\n")
+	if assert.NotNil(t, tpl) {
+		assert.Equal(t, "<type>: <summary>\n\n<body>\n\nTicket:\nReviewers:\nticket: dup", tpl.Text)
+		assert.Equal(t, []string{"Why is this change needed?"}, tpl.Hints)
+		assert.Equal(t, []string{"Ticket", "Reviewers"}, tpl.Sections)
+		assert.True(t, tpl.HasBody())
+	}
+
+	subjectOnly := ParseGitTemplate("fix: \n# subject only\n")
+	if assert.NotNil(t, subjectOnly) {
+		assert.Empty(t, subjectOnly.Sections)
+		assert.False(t, subjectOnly.HasBody())
+	}
+
+	assert.Nil(t, ParseGitTemplate("\n#\n\n"))
+
+	prose := ParseGitTemplate("<summary>\n\nWhy this change:\nSigned-off-by:\nTicket_ID: x\n")
+	if assert.NotNil(t, prose) {
+		assert.Equal(t, []string{"Why this change", "Signed-off-by", "Ticket_ID"}, prose.Sections)
+		assert.Equal(t, []string{"Signed-off-by"}, prose.Trailers())
+	}
+}
+
+func TestAppendGitTemplate(t *testing.T) {
+	assert.Equal(t, "BASE", AppendGitTemplate("BASE", nil))
+
+	prompt := AppendGitTemplate("BASE", ParseGitTemplate("\n\nTicket:\nReviewed-by:\n# keep it short"))
+	assert.Contains(t, prompt, "Template:\n```\n\n\nTicket:\nReviewed-by:\n```")
+	assert.Contains(t, prompt, "Template guidance for the author:\n- keep it short")
+	assert.Contains(t, prompt, "on its own line: Ticket:, Reviewed-by:.")
+	assert.Contains(t, prompt, "The length limit applies to the subject line only.")
+}
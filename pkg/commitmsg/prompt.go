@@ -0,0 +1,203 @@
+package commitmsg
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+type CommitType string
+
+const (
+	EmptyCommitType        CommitType = ""
+	ConventionalCommitType CommitType = "conventional"
+	GitmojiCommitType      CommitType = "gitmoji"
+)
+
+const conventionalData = `
+Choose a type from the type-to-description JSON below that best describes the git diff:
+{
+  "docs": "Documentation only changes",
+  "style": "Changes that do not affect the meaning of the code (white-space, formatting, missing semi-colons, etc)",
+  "refactor": "A code change that neither fixes a bug nor adds a feature",
+  "perf": "A code change that improves performance",
+  "test": "Adding missing tests or correcting existing tests",
+  "build": "Changes that affect the build system or external dependencies",
+  "ci": "Changes to our CI configuration files and scripts",
+  "chore": "Other changes that don't modify src or test files",
+  "revert": "Reverts a previous commit",
+  "feat": "A new feature",
+  "fix": "A bug fix"
+}`
+
+var commitTypeFormats = map[CommitType]string{
+	EmptyCommitType:        "<commit message>",
+	ConventionalCommitType: "<type>(<optional scope>): <commit message>",
+	GitmojiCommitType:      "<gitmoji> <type>(<optional scope>): <commit message>",
+}
+
+func specifyCommitFormat(commitType CommitType) string {
+	return fmt.Sprintf("The output response must be in format:\n%s", commitTypeFormats[commitType])
+}
+
+var commitTypes = map[CommitType]string{
+	ConventionalCommitType: conventionalData,
+	GitmojiCommitType:      conventionalData,
+	EmptyCommitType:        "",
+}
+
+func GeneratePrompt(locale string, maxLength int, commitType CommitType) string {
+	promptParts := []string{
+		"Generate a concise git commit message written in present tense for the following code diff with the given specifications below:",
+		fmt.Sprintf("Message language: %s", locale),
+		fmt.Sprintf("Commit message must be a maximum of %d characters.", maxLength),
+		"Exclude anything unnecessary such as translation. Your entire response will be passed directly into git commit.",
+		commitTypes[commitType],
+		specifyCommitFormat(commitType),
+	}
+
+	// Filter out empty strings
+	var filteredParts []string
+	for _, part := range promptParts {
+		if part != "" {
+			filteredParts = append(filteredParts, part)
+		}
+	}
+
+	return strings.Join(filteredParts, "\n")
+}
+
+// AppendAllowedTypes adds team commit type constraints to a prompt.
+func AppendAllowedTypes(prompt string, allowedTypes []string) string {
+	if len(allowedTypes) == 0 {
+		return prompt
+	}
+	return prompt + "\nPrefer commit types from this team list: " + strings.Join(allowedTypes, ", ")
+}
+
+// AppendRequiredTypes restricts the commit type to types (`commit.allowed_types`); unlike
+// AppendAllowedTypes the list is a hard constraint checked again before commit.
+func AppendRequiredTypes(prompt string, types []string) string {
+	if len(types) == 0 {
+		return prompt
+	}
+	return prompt + "\nThe commit type must be one of: " + strings.Join(types, ", ") + ". Never use any other type."
+}
+
+// AppendType fixes the conventional commit type, e.g. from `--type fix`.
+func AppendType(prompt, typ string) string {
+	typ = strings.TrimSpace(typ)
+	if typ == "" {
+		return prompt
+	}
+	return prompt + fmt.Sprintf("\nUse %q as the commit type and word the subject to fit it.", typ)
+}
+
+// AppendScope asks for a fixed conventional commit scope, e.g. the monorepo module name.
+func AppendScope(prompt, scope string) string {
+	scope = strings.TrimSpace(scope)
+	if scope == "" {
+		return prompt
+	}
+	return prompt + fmt.Sprintf("\nUse %q as the commit scope.", scope)
+}
+
+// AppendTicket gives the model the linked ticket as intent context.
+func AppendTicket(prompt, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return prompt
+	}
+	return prompt + fmt.Sprintf("\nThe change belongs to ticket %q; reflect its intent only where the diff supports it.", ref)
+}
+
+// BodyWidth is the column generated commit bodies are wrapped at.
+const BodyWidth = 72
+
+// AppendBody asks for a wrapped what/why body and a BREAKING CHANGE footer after the subject line.
+func AppendBody(prompt string) string {
+	return prompt + fmt.Sprintf("\nAfter the subject line add a blank line and a body wrapped at %d characters explaining what changed and why, not how."+
+		"\nThe length limit applies to the subject line only."+
+		"\nIf the change breaks backward compatibility, end with a blank line and a footer `BREAKING CHANGE: <what breaks and how to migrate>`.", BodyWidth)
+}
+
+// WrapBody wraps every line after the subject at width columns; list items keep a hanging indent.
+func WrapBody(message string, width int) string {
+	subject, body, ok := strings.Cut(strings.TrimSpace(message), "\n")
+	if !ok {
+		return subject
+	}
+
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(body), "\n") {
+		line = strings.TrimRight(line, " \t")
+		if len(line) <= width {
+			lines = append(lines, line)
+			continue
+		}
+		indent := ""
+		if trimmed := strings.TrimLeft(line, " "); strings.HasPrefix(trimmed, "- ") || strings.HasPrefix(trimmed, "* ") {
+			indent = strings.Repeat(" ", len(line)-len(trimmed)+2)
+		}
+		lines = append(lines, wrapLine(line, indent, width)...)
+	}
+	return subject + "\n\n" + strings.Join(lines, "\n")
+}
+
+func wrapLine(line, indent string, width int) []string {
+	var out []string
+	current := ""
+	for _, word := range strings.Fields(line) {
+		switch {
+		case current == "":
+			current = strings.Repeat(" ", len(line)-len(strings.TrimLeft(line, " "))) + word
+		case len(current)+1+len(word) > width:
+			out = append(out, current)
+			current = indent + word
+		default:
+			current += " " + word
+		}
+	}
+	return append(out, current)
+}
+
+// PromptVars are the values available to a custom commit prompt template (`commit.prompt_template`).
+type PromptVars struct {
+	Branch    string
+	Repo      string
+	Locale    string
+	MaxLength int
+	// Style is the commit.style value: conventional, gitmoji or plain.
+	Style   string
+	Types   []string
+	Scope   string
+	Ticket  string
+	Files   []string
+	Added   int
+	Removed int
+	// Stat is one "path +added -removed" line per staged file.
+	Stat string
+	// Default is the built-in prompt, so a template can extend it instead of replacing it.
+	Default string
+}
+
+// RenderPrompt executes a Go template prompt with vars; `join` is available for slices.
+func RenderPrompt(name, text string, vars PromptVars) (string, error) {
+	tpl, err := template.New(name).
+		Funcs(template.FuncMap{"join": strings.Join}).
+		Option("missingkey=error").
+		Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("parse prompt template %s: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, vars); err != nil {
+		return "", fmt.Errorf("render prompt template %s: %w", name, err)
+	}
+	prompt := strings.TrimSpace(buf.String())
+	if prompt == "" {
+		return "", fmt.Errorf("prompt template %s rendered an empty prompt", name)
+	}
+	return prompt, nil
+}
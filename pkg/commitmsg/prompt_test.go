@@ -0,0 +1,48 @@
+package commitmsg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderPrompt(t *testing.T) {
+	vars := PromptVars{
+		Branch:    "feature/ABC-1-login",
+		Repo:      "fastgit",
+		Locale:    "zh-CN",
+		MaxLength: 60,
+		Types:     []string{"feat", "fix"},
+		Added:     3,
+		Removed:   1,
+		Default:   "BASE",
+	}
+	prompt, err := RenderPrompt("team.tmpl", "{{.Default}}\nRepo {{.Repo}} on {{.Branch}}, {{.Locale}}, <= {{.MaxLength}} chars, types: {{join .Types \"|\"}} (+{{.Added}}/-{{.Removed}})\n", vars)
+	assert.NoError(t, err)
+	assert.Equal(t, "BASE\nRepo fastgit on feature/ABC-1-login, zh-CN, <= 60 chars, types: feat|fix (+3/-1)", prompt)
+
+	_, err = RenderPrompt("bad.tmpl", "{{.Nope}}", vars)
+	assert.Error(t, err)
+	_, err = RenderPrompt("empty.tmpl", "  ", vars)
+	assert.Error(t, err)
+}
+
+func TestWrapBody(t *testing.T) {
+	msg := "feat: add body\n\nexplain why the cache key now includes the locale so that switching languages does not reuse stale messages\n- list items wrap with a hanging indent when they get longer than the width\n\nBREAKING CHANGE: cache files move"
+	assert.Equal(t, "feat: add body\n\n"+
+		"explain why the cache key now includes the locale so\n"+
+		"that switching languages does not reuse stale messages\n"+
+		"- list items wrap with a hanging indent when they get\n"+
+		"  longer than the width\n"+
+		"\n"+
+		"BREAKING CHANGE: cache files move", WrapBody(msg, 56))
+
+	assert.Equal(t, "fix: subject only", WrapBody("fix: subject only\n", 56))
+}
+
+func TestAppendTypes(t *testing.T) {
+	assert.Equal(t, "BASE", AppendRequiredTypes("BASE", nil))
+	assert.Equal(t, "BASE\nThe commit type must be one of: feat, fix. Never use any other type.", AppendRequiredTypes("BASE", []string{"feat", "fix"}))
+	assert.Equal(t, "BASE", AppendType("BASE", ""))
+	assert.Equal(t, "BASE\nUse \"fix\" as the commit type and word the subject to fit it.", AppendType("BASE", "fix"))
+}
@@ -0,0 +1,123 @@
+package commitmsg
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ListMarker matches a leading bullet, number or markdown heading marker models put before
+// a line despite being told not to, e.g. "- ", "2) " or "## ".
+var ListMarker = regexp.MustCompile(`^(?:[-*+•]|\d+[.)]|#{1,6})\s+`)
+
+var (
+	fencePattern = regexp.MustCompile("(?s)```[\\w-]*\\n(.*?)\\n?```")
+	// leadPattern and trailPattern match chatty lines models put before and after the message.
+	leadPattern    = regexp.MustCompile(`(?i)^(?:here(?:'s| is| are)\b|(?:sure|okay|certainly|of course)[,.!]|below is\b)`)
+	trailPattern   = regexp.MustCompile(`(?i)^(?:this (?:commit )?message\b|the (?:commit )?message above\b|note:)`)
+	labelPattern   = regexp.MustCompile(`(?i)^(?:\*\*)?(?:suggested |generated |git )?(?:commit(?: message)?|subject|message)(?:\*\*)?\s*:\s*(?:\*\*)?\s*`)
+	headerPatterns = map[CommitType]*regexp.Regexp{
+		ConventionalCommitType: regexp.MustCompile(`^[A-Za-z]+(?:\([^()]*\))?!?: \S`),
+		GitmojiCommitType:      regexp.MustCompile(`^(?:(?::\w+:|[^\sA-Za-z0-9]+)\s*)?[A-Za-z]+(?:\([^()]*\))?!?: \S`),
+	}
+)
+
+// Repair strips what models commonly wrap around a commit message: markdown code
+// fences, "Here is the commit message:" preambles and trailing explanations, "Commit
+// message:" labels, list markers, bold markers and surrounding quotes. Without body only
+// the subject line is kept, preferring the first line in commitType's format.
+func Repair(text string, commitType CommitType, body bool) string {
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+	if m := fencePattern.FindStringSubmatch(text); m != nil && strings.TrimSpace(m[1]) != "" {
+		text = strings.TrimSpace(m[1])
+	}
+
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			continue
+		}
+		lines = append(lines, strings.TrimRight(line, " \t"))
+	}
+
+	subject := -1
+	for i, line := range lines {
+		cleaned := cleanSubject(line)
+		if cleaned == "" || strings.HasSuffix(cleaned, ":") || leadPattern.MatchString(cleaned) {
+			continue
+		}
+		if subject < 0 {
+			subject = i
+		}
+		if re := headerPatterns[commitType]; re == nil || re.MatchString(cleaned) {
+			subject = i
+			break
+		}
+	}
+	if subject < 0 {
+		return ""
+	}
+
+	msg := cleanSubject(lines[subject])
+	if !body {
+		return msg
+	}
+	rest := lines[subject+1:]
+	// 去掉正文后模型附加的说明，如 "This commit message follows ..."
+	for len(rest) > 0 && (strings.TrimSpace(rest[len(rest)-1]) == "" || trailPattern.MatchString(strings.TrimSpace(rest[len(rest)-1]))) {
+		rest = rest[:len(rest)-1]
+	}
+	if len(rest) == 0 {
+		return msg
+	}
+	return strings.TrimSpace(msg + "\n" + strings.Join(rest, "\n"))
+}
+
+func cleanSubject(line string) string {
+	line = strings.TrimSpace(line)
+	line = ListMarker.ReplaceAllString(line, "")
+	line = labelPattern.ReplaceAllString(line, "")
+	line = strings.TrimSpace(strings.Trim(line, "*"))
+	for _, quote := range []string{"`", `"`, "'", "“"} {
+		end := quote
+		if quote == "“" {
+			end = "”"
+		}
+		if len(line) > len(quote)+len(end) && strings.HasPrefix(line, quote) && strings.HasSuffix(line, end) {
+			line = strings.TrimSpace(line[len(quote) : len(line)-len(end)])
+		}
+	}
+	return line
+}
+
+// Validate reports why msg is not a bare commit message in commitType's format: empty,
+// markdown left over, more than one line without body, or a subject that does not read
+// `<type>(<scope>): <subject>` for the conventional and gitmoji formats.
+func Validate(msg string, commitType CommitType, body bool) error {
+	msg = strings.TrimSpace(msg)
+	if msg == "" {
+		return errors.New("empty commit message")
+	}
+	if strings.Contains(msg, "```") {
+		return errors.New("commit message contains markdown code fences")
+	}
+	subject, _, multiline := strings.Cut(msg, "\n")
+	if multiline && !body {
+		return errors.New("commit message must be a single subject line")
+	}
+	if re := headerPatterns[commitType]; re != nil && !re.MatchString(subject) {
+		return fmt.Errorf("subject %q is not in the format %s", subject, commitTypeFormats[commitType])
+	}
+	return nil
+}
+
+// CorrectionInstruction is the follow-up instruction for a response that failed Validate.
+func CorrectionInstruction(err error, commitType CommitType, body bool) string {
+	instruction := fmt.Sprintf("Your previous answer was rejected: %s. Reply with the commit message only, in the format %s, "+
+		"without markdown, code fences, quotes, explanations or any other text.", err, commitTypeFormats[commitType])
+	if !body {
+		instruction += " Use exactly one line."
+	}
+	return instruction
+}
@@ -0,0 +1,62 @@
+package commitmsg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepair(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		in   string
+		typ  CommitType
+		body bool
+		want string
+	}{
+		{"bare", "feat(cli): add --type flag", ConventionalCommitType, false, "feat(cli): add --type flag"},
+		{"fenced", "```text\nfix: handle empty diff\n```", ConventionalCommitType, false, "fix: handle empty diff"},
+		{"preamble", "Here is the commit message:\n\n`fix(api): retry on 502`\n\nThis fixes the flaky deploys.", ConventionalCommitType, false, "fix(api): retry on 502"},
+		{"label", "**Commit message:** \"docs: explain editor order\"", ConventionalCommitType, false, "docs: explain editor order"},
+		{"prose first", "The diff adds caching\n- perf: cache changelog entries", ConventionalCommitType, false, "perf: cache changelog entries"},
+		{"gitmoji", "Sure!\n:sparkles: feat: add watch mode", GitmojiCommitType, false, ":sparkles: feat: add watch mode"},
+		{"plain", "Add caching for changelog entries\n\nIt avoids rereading commits.", EmptyCommitType, false, "Add caching for changelog entries"},
+		{"body", "```\nfeat: add body\n\nexplain why\n```\nThis message follows the conventional format.", ConventionalCommitType, true, "feat: add body\n\nexplain why"},
+		{"empty", "Here is the commit message:", ConventionalCommitType, false, ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Repair(tc.in, tc.typ, tc.body))
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate("feat(cli)!: drop --old", ConventionalCommitType, false))
+	assert.NoError(t, Validate("✨ feat: add watch mode", GitmojiCommitType, false))
+	assert.NoError(t, Validate("Add caching", EmptyCommitType, false))
+	assert.NoError(t, Validate("fix: a\n\nbody", ConventionalCommitType, true))
+
+	assert.Error(t, Validate(" ", EmptyCommitType, false))
+	assert.Error(t, Validate("fix: a\n\nbody", ConventionalCommitType, false))
+	assert.Error(t, Validate("Add caching", ConventionalCommitType, false))
+	assert.Error(t, Validate("```\nfix: a\n```", ConventionalCommitType, true))
+
+	err := Validate("Add caching", ConventionalCommitType, false)
+	assert.Contains(t, CorrectionInstruction(err, ConventionalCommitType, false), "<type>(<optional scope>): <commit message>")
+}
+
+func TestListMarker(t *testing.T) {
+	for in, want := range map[string]string{
+		"- feat: a":    "feat: a",
+		"* feat: a":    "feat: a",
+		"• feat: a":    "feat: a",
+		"12. feat: a":  "feat: a",
+		"3) feat: a":   "feat: a",
+		"## feat: a":   "feat: a",
+		"feat: a - b":  "feat: a - b",
+		"-feat: a":     "-feat: a",
+		"2.0 released": "2.0 released",
+	} {
+		assert.Equal(t, want, ListMarker.ReplaceAllString(in, ""), in)
+	}
+}
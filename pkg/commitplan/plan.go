@@ -0,0 +1,177 @@
+package commitplan
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pubgo/fastgit/pkg/commitmsg"
+	"github.com/pubgo/fastgit/pkg/gitshell"
+	"github.com/pubgo/fastgit/pkg/repoconfig"
+)
+
+// SystemPrompt asks the model for a commit breakdown in the line format understood by ParseSteps.
+const SystemPrompt = `You plan how to split a development task into a sequence of small, reviewable git commits.
+Return between 2 and 8 lines, one per commit, in the order they should be made, with no numbering, markdown or extra text.
+Each line has the form: <conventional commit message> | <one sentence describing what the commit changes>
+Every commit must build on its own; put refactors and tests before or with the change that needs them.`
+
+// Step is one planned commit. Commit holds the hash once a matching commit was made.
+type Step struct {
+	Message string `json:"message"`
+	Detail  string `json:"detail,omitempty"`
+	Commit  string `json:"commit,omitempty"`
+}
+
+// Done reports whether a commit was recorded for the step.
+func (s Step) Done() bool { return s.Commit != "" }
+
+// Plan is a commit breakdown for a task, stored per worktree so later commits on the
+// same branch can reference it.
+type Plan struct {
+	Task      string    `json:"task"`
+	Branch    string    `json:"branch"`
+	CreatedAt time.Time `json:"created_at"`
+	Steps     []Step    `json:"steps"`
+}
+
+// Path returns the plan file of the worktree at repoRoot: `<git-dir>/fastgit/plan.json`.
+func Path(repoRoot string) (string, error) {
+	gitDir, err := gitshell.RunInDir(repoRoot, "rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(gitDir, "fastgit", "plan.json"), nil
+}
+
+// Load reads the plan of the worktree at repoRoot; it returns nil without error when none exists.
+func Load(repoRoot string) (*Plan, error) {
+	path, err := Path(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var p Plan
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("invalid commit plan %s: %w", path, err)
+	}
+	return &p, nil
+}
+
+// Save writes p as the plan of the worktree at repoRoot and returns the file path.
+func Save(repoRoot string, p *Plan) (string, error) {
+	path, err := Path(repoRoot)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return path, os.WriteFile(path, data, 0o644)
+}
+
+// Clear removes the plan of the worktree at repoRoot. A missing plan is not an error.
+func Clear(repoRoot string) error {
+	path, err := Path(repoRoot)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// ParseSteps parses model output in the "<message> | <detail>" line format.
+func ParseSteps(text string) []Step {
+	var steps []Step
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(commitmsg.ListMarker.ReplaceAllString(strings.TrimSpace(line), ""))
+		if line == "" || strings.HasPrefix(line, "```") {
+			continue
+		}
+		message, detail, _ := strings.Cut(line, "|")
+		message = strings.Trim(strings.TrimSpace(message), "`")
+		if message == "" {
+			continue
+		}
+		steps = append(steps, Step{Message: message, Detail: strings.TrimSpace(detail)})
+	}
+	return steps
+}
+
+// Pending returns the number of steps without a recorded commit.
+func (p *Plan) Pending() int {
+	var n int
+	for _, s := range p.Steps {
+		if !s.Done() {
+			n++
+		}
+	}
+	return n
+}
+
+// Context renders the plan as extra prompt text for generating the message of a later commit.
+func (p *Plan) Context() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "This commit is part of a planned task: %s\nPlanned commits ([x] = already committed):\n", p.Task)
+	for _, s := range p.Steps {
+		mark := "[ ]"
+		if s.Done() {
+			mark = "[x]"
+		}
+		fmt.Fprintf(&b, "%s %s", mark, s.Message)
+		if s.Detail != "" {
+			fmt.Fprintf(&b, " — %s", s.Detail)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("If the staged diff implements an open planned commit, reuse its message, refining the subject only where the diff differs.")
+	return b.String()
+}
+
+// MarkDone records hash on the first open step whose subject matches message and
+// reports whether one matched. Type, scope, gitmoji and case are ignored when matching.
+func (p *Plan) MarkDone(message, hash string) bool {
+	subject := normalizeSubject(message)
+	if subject == "" {
+		return false
+	}
+	for i := range p.Steps {
+		if !p.Steps[i].Done() && normalizeSubject(p.Steps[i].Message) == subject {
+			p.Steps[i].Commit = hash
+			return true
+		}
+	}
+	return false
+}
+
+// conventionalHeader matches "type(scope)!: " at the start of a subject.
+var conventionalHeader = regexp.MustCompile(`^[a-zA-Z]+(?:\([^)]*\))?!?:\s*`)
+
+// issueSuffix matches a trailing issue reference such as " (#1234)" added by commit.issue_ref.
+var issueSuffix = regexp.MustCompile(`\s*\(#\d+\)$`)
+
+func normalizeSubject(message string) string {
+	subject, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	subject = repoconfig.StripGitmoji(subject)
+	subject = conventionalHeader.ReplaceAllString(subject, "")
+	subject = issueSuffix.ReplaceAllString(subject, "")
+	return strings.ToLower(strings.TrimRight(strings.TrimSpace(subject), "."))
+}
@@ -0,0 +1,76 @@
+package commitplan
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestParseSteps(t *testing.T) {
+	text := "```\n1. feat(api): add user endpoint | expose GET /users\n- test: cover user endpoint|table tests\n\nrefactor: split store\n```"
+	steps := ParseSteps(text)
+	if len(steps) != 3 {
+		t.Fatalf("expected 3 steps, got %d: %+v", len(steps), steps)
+	}
+	if steps[0].Message != "feat(api): add user endpoint" || steps[0].Detail != "expose GET /users" {
+		t.Fatalf("unexpected first step: %+v", steps[0])
+	}
+	if steps[1].Message != "test: cover user endpoint" || steps[1].Detail != "table tests" {
+		t.Fatalf("unexpected second step: %+v", steps[1])
+	}
+	if steps[2].Message != "refactor: split store" || steps[2].Detail != "" {
+		t.Fatalf("unexpected third step: %+v", steps[2])
+	}
+}
+
+func TestMarkDone(t *testing.T) {
+	p := &Plan{Task: "users", Steps: []Step{
+		{Message: "feat: add user endpoint"},
+		{Message: "test: cover user endpoint"},
+	}}
+
+	if p.MarkDone("docs: unrelated", "abc") {
+		t.Fatal("unrelated message should not match")
+	}
+	if !p.MarkDone("✨ feat(api): Add user endpoint.\n\nbody", "abc") {
+		t.Fatal("expected match ignoring gitmoji, scope and case")
+	}
+	if p.Steps[0].Commit != "abc" || p.Pending() != 1 {
+		t.Fatalf("unexpected steps after mark: %+v", p.Steps)
+	}
+	if p.MarkDone("feat: add user endpoint", "def") {
+		t.Fatal("done steps must not match again")
+	}
+
+	ctx := p.Context()
+	if !strings.Contains(ctx, "[x] feat: add user endpoint") || !strings.Contains(ctx, "[ ] test: cover user endpoint") {
+		t.Fatalf("unexpected context:\n%s", ctx)
+	}
+}
+
+func TestSaveLoadClear(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found in PATH")
+	}
+	dir := t.TempDir()
+	if out, err := exec.Command("git", "init", "-q", dir).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v: %s", err, out)
+	}
+
+	if p, err := Load(dir); err != nil || p != nil {
+		t.Fatalf("expected no plan, got %+v, %v", p, err)
+	}
+	if _, err := Save(dir, &Plan{Task: "t", Branch: "main", Steps: []Step{{Message: "feat: a"}}}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	p, err := Load(dir)
+	if err != nil || p == nil || p.Task != "t" || len(p.Steps) != 1 {
+		t.Fatalf("unexpected loaded plan %+v, %v", p, err)
+	}
+	if err := Clear(dir); err != nil {
+		t.Fatalf("clear: %v", err)
+	}
+	if p, _ := Load(dir); p != nil {
+		t.Fatalf("plan should be removed, got %+v", p)
+	}
+}
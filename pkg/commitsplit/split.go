@@ -0,0 +1,162 @@
+// Package commitsplit groups the files of a staged change into several logical commits.
+package commitsplit
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pubgo/fastgit/pkg/commitmsg"
+	"github.com/pubgo/fastgit/pkg/gitshell"
+)
+
+// SystemPrompt asks the model for file groups in the line format understood by ParseGroups.
+const SystemPrompt = `You split a staged git change that touches unrelated areas into a sequence of logical commits.
+Return one line per commit, in the order they should be made, with no numbering, markdown or extra text.
+Each line has the form: <conventional commit message> | <comma separated file paths>
+Use every file path from the list exactly once. Keep files that depend on each other in the same commit;
+if the change is a single logical unit, return a single line.`
+
+// Group is one commit of a split: its message and the files it stages.
+type Group struct {
+	Message string
+	Files   []string
+}
+
+// Input renders the staged file list and diff as the user prompt.
+func Input(files []string, diff string) string {
+	return "Files:\n" + strings.Join(files, "\n") + "\n\nDiff:\n" + diff
+}
+
+// ParseGroups parses model output in the "<message> | <files>" line format. Paths not in
+// files are dropped and repeated paths keep their first group; files the model left out
+// are appended to the last group so the split always commits the whole staged change.
+func ParseGroups(text string, files []string) []Group {
+	known := make(map[string]bool, len(files))
+	for _, f := range files {
+		known[f] = true
+	}
+
+	var groups []Group
+	used := make(map[string]bool, len(files))
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(commitmsg.ListMarker.ReplaceAllString(strings.TrimSpace(line), ""))
+		if line == "" || strings.HasPrefix(line, "```") {
+			continue
+		}
+		i := strings.LastIndex(line, "|")
+		if i < 0 {
+			continue
+		}
+		message := strings.Trim(strings.TrimSpace(line[:i]), "`")
+		if message == "" {
+			continue
+		}
+
+		var g Group
+		g.Message = message
+		for _, f := range strings.Split(line[i+1:], ",") {
+			f = strings.Trim(strings.TrimSpace(f), "`")
+			if known[f] && !used[f] {
+				used[f] = true
+				g.Files = append(g.Files, f)
+			}
+		}
+		if len(g.Files) > 0 {
+			groups = append(groups, g)
+		}
+	}
+	if len(groups) == 0 {
+		return nil
+	}
+
+	for _, f := range files {
+		if !used[f] {
+			last := &groups[len(groups)-1]
+			last.Files = append(last.Files, f)
+		}
+	}
+	return groups
+}
+
+// Summary renders the groups for confirmation before they are applied.
+func Summary(groups []Group) string {
+	var b strings.Builder
+	for i, g := range groups {
+		fmt.Fprintf(&b, "%d. %s\n", i+1, g.Message)
+		for _, f := range g.Files {
+			fmt.Fprintf(&b, "     %s\n", f)
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// StagedFiles lists the staged paths of the worktree at repoRoot. Renames are reported as a
+// deletion plus an addition so each side can land in its own group.
+func StagedFiles(repoRoot string) ([]string, error) {
+	out, err := gitshell.RunInDir(repoRoot, "diff", "--cached", "--name-only", "--no-renames", "-z")
+	if err != nil {
+		return nil, err
+	}
+	return splitNUL(out), nil
+}
+
+// PartiallyStaged returns the files that also have unstaged changes; re-staging them per
+// group would pick up those changes, so a split refuses to run while any exist.
+func PartiallyStaged(repoRoot string, files []string) ([]string, error) {
+	out, err := gitshell.RunInDir(repoRoot, "diff", "--name-only", "--no-renames", "-z")
+	if err != nil {
+		return nil, err
+	}
+	unstaged := make(map[string]bool)
+	for _, f := range splitNUL(out) {
+		unstaged[f] = true
+	}
+	var partial []string
+	for _, f := range files {
+		if unstaged[f] {
+			partial = append(partial, f)
+		}
+	}
+	return partial, nil
+}
+
+// Apply unstages everything, then stages and commits the groups one by one. When commit
+// fails the files of the remaining groups are staged again, leaving the index as it was
+// minus the groups already committed.
+func Apply(repoRoot string, groups []Group, commit func(Group) error) error {
+	if _, err := gitshell.RunInDir(repoRoot, "reset", "-q"); err != nil {
+		return err
+	}
+	for i, g := range groups {
+		err := stage(repoRoot, g.Files)
+		if err == nil {
+			err = commit(g)
+		}
+		if err != nil {
+			var rest []string
+			for _, r := range groups[i:] {
+				rest = append(rest, r.Files...)
+			}
+			if restageErr := stage(repoRoot, rest); restageErr != nil {
+				return fmt.Errorf("commit %d/%d: %w (restaging the remaining files also failed: %v)", i+1, len(groups), err, restageErr)
+			}
+			return fmt.Errorf("commit %d/%d: %w", i+1, len(groups), err)
+		}
+	}
+	return nil
+}
+
+func stage(repoRoot string, files []string) error {
+	_, err := gitshell.RunInDir(repoRoot, append([]string{"--literal-pathspecs", "add", "-A", "--"}, files...)...)
+	return err
+}
+
+func splitNUL(out string) []string {
+	var files []string
+	for _, f := range strings.Split(out, "\x00") {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+	return files
+}
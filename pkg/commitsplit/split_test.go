@@ -0,0 +1,99 @@
+package commitsplit
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseGroups(t *testing.T) {
+	files := []string{"api/user.go", "api/user_test.go", "docs/README.md", "go.mod"}
+	text := "```\n1. feat(api): add user endpoint | api/user.go, `api/user_test.go`\n- docs: describe users | docs/README.md, api/user.go, missing.go\nchore: no files |\n```"
+	groups := ParseGroups(text, files)
+	if len(groups) != 2 {
+		t.Fatalf("expected 2 groups, got %d: %+v", len(groups), groups)
+	}
+	if groups[0].Message != "feat(api): add user endpoint" || strings.Join(groups[0].Files, ",") != "api/user.go,api/user_test.go" {
+		t.Fatalf("unexpected first group: %+v", groups[0])
+	}
+	// api/user.go 已分配给第一组，遗漏的 go.mod 归入最后一组
+	if groups[1].Message != "docs: describe users" || strings.Join(groups[1].Files, ",") != "docs/README.md,go.mod" {
+		t.Fatalf("unexpected second group: %+v", groups[1])
+	}
+
+	if ParseGroups("no groups here", files) != nil {
+		t.Fatal("expected nil without parsable lines")
+	}
+}
+
+func TestApply(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("FASTGIT_EXEC_LOG", "0")
+	dir := t.TempDir()
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git("init", "-q")
+	git("config", "user.email", "dev@example.com")
+	git("config", "user.name", "dev")
+	write("old.txt", "old\n")
+	git("add", "-A")
+	git("commit", "-q", "-m", "init")
+
+	write("a b.txt", "a\n")
+	write("pkg/c.go", "package pkg\n")
+	git("rm", "-q", "old.txt")
+	git("add", "-A")
+
+	files, err := StagedFiles(dir)
+	if err != nil || len(files) != 3 {
+		t.Fatalf("unexpected staged files %v: %v", files, err)
+	}
+	if partial, _ := PartiallyStaged(dir, files); len(partial) != 0 {
+		t.Fatalf("unexpected partially staged files: %v", partial)
+	}
+
+	groups := []Group{
+		{Message: "feat: add a", Files: []string{"a b.txt", "old.txt"}},
+		{Message: "feat: add c", Files: []string{"pkg/c.go"}},
+	}
+	commit := func(g Group) error {
+		if g.Message == "feat: add c" {
+			return errors.New("hook rejected")
+		}
+		git("commit", "-q", "-m", g.Message)
+		return nil
+	}
+	if err := Apply(dir, groups, commit); err == nil || !strings.Contains(err.Error(), "commit 2/2") {
+		t.Fatalf("expected failure on the second commit, got %v", err)
+	}
+	if got := git("log", "--format=%s"); got != "feat: add a\ninit" {
+		t.Fatalf("unexpected history:\n%s", got)
+	}
+	// 失败时剩余分组的文件重新暂存
+	if got := git("diff", "--cached", "--name-only"); got != "pkg/c.go" {
+		t.Fatalf("expected remaining files staged again, got %q", got)
+	}
+}
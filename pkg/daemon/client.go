@@ -0,0 +1,82 @@
+package daemon
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"path/filepath"
+	"time"
+
+	"github.com/adrg/xdg"
+)
+
+// DialTimeout keeps CLI invocations fast when no daemon is running.
+var DialTimeout = 200 * time.Millisecond
+
+// SocketPath returns the per-repository socket under the XDG runtime directory.
+func SocketPath(root string) (string, error) {
+	sum := sha1.Sum([]byte(filepath.Clean(root)))
+	return xdg.RuntimeFile(filepath.Join("fastgit", "daemon-"+hex.EncodeToString(sum[:])[:12]+".sock"))
+}
+
+// Query returns the warm snapshot for root, or an error when no daemon serves it.
+func Query(root string) (*Snapshot, error) {
+	return snapshotCall(root, opSnapshot)
+}
+
+// Refresh forces the daemon to reload all state before answering.
+func Refresh(root string) (*Snapshot, error) {
+	return snapshotCall(root, opRefresh)
+}
+
+// Ping checks whether a daemon is serving root.
+func Ping(root string) error {
+	_, err := call(root, opPing)
+	return err
+}
+
+// Stop asks the daemon serving root to exit.
+func Stop(root string) error {
+	_, err := call(root, opStop)
+	return err
+}
+
+func snapshotCall(root, op string) (*Snapshot, error) {
+	rsp, err := call(root, op)
+	if err != nil {
+		return nil, err
+	}
+	if rsp.Snapshot == nil {
+		return nil, errors.New("daemon returned empty snapshot")
+	}
+	return rsp.Snapshot, nil
+}
+
+func call(root, op string) (*response, error) {
+	sock, err := SocketPath(root)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.DialTimeout("unix", sock, DialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(30 * time.Second))
+
+	if err := json.NewEncoder(conn).Encode(request{Op: op}); err != nil {
+		return nil, err
+	}
+
+	var rsp response
+	if err := json.NewDecoder(conn).Decode(&rsp); err != nil {
+		return nil, err
+	}
+	if rsp.Error != "" {
+		return nil, errors.New(rsp.Error)
+	}
+	return &rsp, nil
+}
@@ -0,0 +1,147 @@
+package daemon
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/adrg/xdg"
+)
+
+func TestServerServesSnapshot(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found in PATH")
+	}
+	setRuntimeDir(t)
+
+	tmp := t.TempDir()
+	runGitForTest(t, tmp, "init", "-b", "main")
+	runGitForTest(t, tmp, "config", "user.email", "daemon-test@example.com")
+	runGitForTest(t, tmp, "config", "user.name", "daemon-test")
+	runGitForTest(t, tmp, "commit", "--allow-empty", "-m", "init")
+	runGitForTest(t, tmp, "tag", "v1.2.0")
+	runGitForTest(t, tmp, "tag", "v1.10.0")
+
+	srv, err := NewServer(tmp)
+	if err != nil {
+		t.Fatalf("new server: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ctx) }()
+	waitForDaemon(t, tmp)
+
+	snap, err := Query(tmp)
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if snap.Branch != "main" {
+		t.Fatalf("expected main branch, got %q", snap.Branch)
+	}
+	if len(snap.Tags) != 2 || snap.Tags[0] != "v1.10.0" {
+		t.Fatalf("expected version-sorted tags, got %v", snap.Tags)
+	}
+	if snap.Dirty() {
+		t.Fatalf("expected clean status, got %q", snap.Status)
+	}
+
+	runGitForTest(t, tmp, "tag", "v2.0.0")
+	snap, err = Query(tmp)
+	if err != nil {
+		t.Fatalf("query after tag: %v", err)
+	}
+	if len(snap.Tags) != 3 || snap.Tags[0] != "v2.0.0" {
+		t.Fatalf("expected new tag to be visible, got %v", snap.Tags)
+	}
+
+	if err := Stop(tmp); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("serve returned error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("daemon did not stop")
+	}
+}
+
+func TestQueryWithoutDaemon(t *testing.T) {
+	setRuntimeDir(t)
+	if _, err := Query(t.TempDir()); err == nil {
+		t.Fatalf("expected error when no daemon is running")
+	}
+}
+
+func waitForDaemon(t *testing.T, root string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if Ping(root) == nil {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("daemon did not start")
+}
+
+func runGitForTest(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_CONFIG_NOSYSTEM=1", "HOME="+filepath.Dir(dir))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, out)
+	}
+}
+
+func setRuntimeDir(t *testing.T) {
+	t.Helper()
+	t.Cleanup(xdg.Reload)
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	xdg.Reload()
+}
+
+func TestFingerprintSeesNestedRefs(t *testing.T) {
+	gitDir := t.TempDir()
+	nested := filepath.Join(gitDir, "refs", "heads", "feat")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	ref := filepath.Join(nested, "x")
+	if err := os.WriteFile(ref, []byte("1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Hour)
+	for _, p := range []string{ref, nested, filepath.Dir(nested)} {
+		if err := os.Chtimes(p, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+	before := fingerprint(gitDir)
+
+	// 只更新 refs/heads/feat/x，refs/heads 本身的 mtime 不变
+	if err := os.WriteFile(ref, []byte("2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if after := fingerprint(gitDir); after == before {
+		t.Fatalf("expected fingerprint to change after a nested ref update, got %q", after)
+	}
+
+	before = fingerprint(gitDir)
+	if err := os.Remove(ref); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(nested, old, old); err != nil {
+		t.Fatal(err)
+	}
+	if after := fingerprint(gitDir); after == before {
+		t.Fatalf("expected fingerprint to change after a nested ref is deleted, got %q", after)
+	}
+}
@@ -0,0 +1,229 @@
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pubgo/funk/v2/log"
+
+	"github.com/pubgo/fastgit/pkg/gitshell"
+)
+
+const (
+	opPing     = "ping"
+	opSnapshot = "snapshot"
+	opRefresh  = "refresh"
+	opStop     = "stop"
+)
+
+type request struct {
+	Op string `json:"op"`
+}
+
+type response struct {
+	Snapshot *Snapshot `json:"snapshot,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// Server keeps the repository snapshot warm and answers requests on a unix socket.
+type Server struct {
+	root   string
+	gitDir string
+	sock   string
+
+	// StatusTTL bounds how old the cached porcelain status may be when served.
+	StatusTTL time.Duration
+	// Interval controls how often the background loop checks for ref changes.
+	Interval time.Duration
+	// WIPInterval enables periodic wip checkpoints of the working tree; zero disables them.
+	WIPInterval time.Duration
+
+	mu    sync.Mutex
+	snap  *Snapshot
+	fp    string
+	wip   string
+	wipAt time.Time
+
+	stop chan struct{}
+	once sync.Once
+}
+
+// NewServer prepares a daemon for the repository rooted at root.
+func NewServer(root string) (*Server, error) {
+	gitDir, err := gitshell.RunInDir(root, "rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return nil, err
+	}
+
+	sock, err := SocketPath(root)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Server{
+		root:      root,
+		gitDir:    gitDir,
+		sock:      sock,
+		StatusTTL: 2 * time.Second,
+		Interval:  time.Second,
+		stop:      make(chan struct{}),
+	}, nil
+}
+
+// Socket returns the unix socket path the server listens on.
+func (s *Server) Socket() string { return s.sock }
+
+// Serve blocks until ctx is cancelled or a stop request is received.
+func (s *Server) Serve(ctx context.Context) error {
+	if Ping(s.root) == nil {
+		return errors.New("daemon already running: " + s.sock)
+	}
+	_ = os.Remove(s.sock)
+
+	if _, err := s.snapshot(true); err != nil {
+		return err
+	}
+
+	ln, err := net.Listen("unix", s.sock)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(s.sock)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-s.stop:
+		}
+		_ = ln.Close()
+	}()
+
+	go s.warm(ctx)
+	if s.WIPInterval > 0 {
+		go s.checkpoints(ctx)
+	}
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-s.stop:
+				return nil
+			default:
+			}
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go s.handle(conn)
+	}
+}
+
+// warm refreshes refs in the background so requests rarely pay for git calls.
+func (s *Server) warm(ctx context.Context) {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			_, _ = s.snapshot(false)
+		}
+	}
+}
+
+// checkpoints records a wip checkpoint every WIPInterval while the working tree changes.
+func (s *Server) checkpoints(ctx context.Context) {
+	ticker := time.NewTicker(s.WIPInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			commit, err := Checkpoint(s.root)
+			if err != nil {
+				log.Warn().Err(err).Msg("failed to create wip checkpoint")
+				continue
+			}
+			if commit != "" {
+				s.mu.Lock()
+				s.wip, s.wipAt = commit, time.Now()
+				s.mu.Unlock()
+			}
+		}
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(30 * time.Second))
+
+	var req request
+	if err := json.NewDecoder(bufio.NewReader(conn)).Decode(&req); err != nil {
+		_ = json.NewEncoder(conn).Encode(response{Error: err.Error()})
+		return
+	}
+
+	var rsp response
+	switch req.Op {
+	case opPing:
+	case opSnapshot, opRefresh:
+		snap, err := s.snapshot(req.Op == opRefresh)
+		if err != nil {
+			rsp.Error = err.Error()
+		} else {
+			rsp.Snapshot = snap
+		}
+	case opStop:
+		s.once.Do(func() { close(s.stop) })
+	default:
+		rsp.Error = "unknown op: " + req.Op
+	}
+	_ = json.NewEncoder(conn).Encode(rsp)
+}
+
+// snapshot returns a copy of the cached state, reloading refs when git metadata
+// changed and status when it is older than StatusTTL.
+func (s *Server) snapshot(force bool) (*Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fp := fingerprint(s.gitDir)
+	if force || s.snap == nil || fp != s.fp {
+		snap, err := collectRefs(s.root)
+		if err != nil {
+			return nil, err
+		}
+		s.snap = snap
+		s.fp = fp
+		force = true
+	}
+
+	if force || time.Since(s.snap.StatusAt) > s.StatusTTL {
+		status, err := collectStatus(s.root)
+		if err != nil {
+			return nil, err
+		}
+		s.snap.Status = status
+		s.snap.StatusAt = time.Now()
+	}
+
+	cp := *s.snap
+	cp.WIP, cp.WIPAt = s.wip, s.wipAt
+	return &cp, nil
+}
@@ -0,0 +1,117 @@
+package daemon
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pubgo/fastgit/pkg/gitshell"
+)
+
+// Snapshot is the warm repository state served to CLI invocations.
+type Snapshot struct {
+	Repo      string    `json:"repo"`
+	Branch    string    `json:"branch"`
+	Branches  []string  `json:"branches,omitempty"`
+	Tags      []string  `json:"tags,omitempty"`
+	Status    string    `json:"status,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+	StatusAt  time.Time `json:"status_at"`
+	// WIP is the latest checkpoint created by this daemon, empty when checkpoints are off.
+	WIP   string    `json:"wip,omitempty"`
+	WIPAt time.Time `json:"wip_at,omitempty"`
+}
+
+// Dirty reports whether the cached status contains changes.
+func (s *Snapshot) Dirty() bool {
+	return s != nil && strings.TrimSpace(s.Status) != ""
+}
+
+// collectRefs loads branch and tag state. Tags are sorted by version, newest first.
+func collectRefs(root string) (*Snapshot, error) {
+	snap := &Snapshot{Repo: root, UpdatedAt: time.Now()}
+	snap.Branch = gitshell.DetectBranch(root)
+
+	branches, err := gitshell.RunInDir(root, "for-each-ref", "--format=%(refname:short)", "refs/heads", "refs/remotes")
+	if err != nil {
+		return nil, err
+	}
+	snap.Branches = splitLines(branches, func(line string) bool { return !strings.HasSuffix(line, "/HEAD") && line != "origin" })
+
+	tags, err := gitshell.RunInDir(root, "-c", "versionsort.suffix=-", "for-each-ref",
+		"--sort=-v:refname", "--format=%(refname:lstrip=2)", "refs/tags")
+	if err != nil {
+		return nil, err
+	}
+	snap.Tags = splitLines(tags, nil)
+	return snap, nil
+}
+
+func collectStatus(root string) (string, error) {
+	return gitshell.RunInDir(root, "status", "--porcelain")
+}
+
+// fingerprint summarizes the mtimes of git metadata touched by commits, checkouts,
+// fetches and ref updates, so a stale snapshot can be detected with a few stats.
+func fingerprint(gitDir string) string {
+	var b strings.Builder
+	for _, name := range []string{"HEAD", "index", "packed-refs", "FETCH_HEAD", "ORIG_HEAD", "logs/HEAD"} {
+		info, err := os.Stat(filepath.Join(gitDir, name))
+		if err != nil {
+			b.WriteString("-;")
+			continue
+		}
+		b.WriteString(info.ModTime().Format(time.RFC3339Nano))
+		b.WriteByte(';')
+	}
+	for _, name := range []string{"refs/heads", "refs/tags", "refs/remotes"} {
+		b.WriteString(treeStamp(filepath.Join(gitDir, name)))
+		b.WriteByte(';')
+	}
+	return b.String()
+}
+
+// treeStamp returns the entry count and latest mtime under a loose ref directory. Nested
+// refs such as refs/heads/feat/x only touch their own subdirectory, so the whole tree is walked.
+func treeStamp(dir string) string {
+	var (
+		count  int
+		latest time.Time
+	)
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		count++
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+		return nil
+	})
+	if err != nil || count == 0 {
+		return "-"
+	}
+	return strconv.Itoa(count) + "@" + latest.Format(time.RFC3339Nano)
+}
+
+func splitLines(output string, keep func(string) bool) []string {
+	var out []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if keep != nil && !keep(line) {
+			continue
+		}
+		out = append(out, line)
+	}
+	return out
+}
@@ -0,0 +1,121 @@
+package daemon
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pubgo/fastgit/pkg/gitshell"
+)
+
+// WIPRefPrefix is the namespace of the shadow branches holding checkpoints. It lives
+// outside refs/heads so checkpoints never show up in branch lists or get pushed.
+const WIPRefPrefix = "refs/fastgit/wip/"
+
+// WIPRef returns the shadow ref holding the checkpoint of branch.
+func WIPRef(branch string) string {
+	return WIPRefPrefix + branch
+}
+
+// Checkpoint snapshots the working tree, including untracked files not ignored by
+// .gitignore, into a single "wip:" commit on top of HEAD stored at WIPRef(branch).
+// The index, HEAD and the files are left untouched; previous checkpoints stay
+// reachable through the ref's reflog.
+//
+// It returns an empty hash when there is nothing to record: the tree matches HEAD
+// (the stale checkpoint is dropped then), or nothing changed since the last checkpoint.
+func Checkpoint(root string) (string, error) {
+	branch := gitshell.DetectBranch(root)
+	if branch == "" || strings.HasPrefix(branch, "detached@") {
+		return "", nil
+	}
+	head, err := gitshell.RunInDir(root, "rev-parse", "--verify", "-q", "HEAD")
+	if err != nil {
+		// 尚无提交的仓库没有可挂靠的父提交
+		return "", nil
+	}
+
+	tree, err := worktreeTree(root)
+	if err != nil {
+		return "", err
+	}
+
+	ref := WIPRef(branch)
+	if headTree, _ := gitshell.RunInDir(root, "rev-parse", "HEAD^{tree}"); tree == headTree {
+		return "", ClearCheckpoint(root, branch)
+	}
+	if prev, err := gitshell.RunInDir(root, "rev-parse", "--verify", "-q", ref+"^{tree}"); err == nil && prev == tree {
+		if parent, _ := gitshell.RunInDir(root, "rev-parse", ref+"^"); parent == head {
+			return "", nil
+		}
+	}
+
+	msg := fmt.Sprintf("wip: checkpoint %s at %s", branch, time.Now().Format(time.RFC3339))
+	commit, err := gitshell.RunInDir(root, "commit-tree", "--no-gpg-sign", "-p", head, "-m", msg, tree)
+	if err != nil {
+		return "", fmt.Errorf("commit-tree: %w", err)
+	}
+	if _, err := gitshell.RunInDir(root, "update-ref", "--create-reflog", "-m", "fastgit: wip checkpoint", ref, commit); err != nil {
+		return "", fmt.Errorf("update-ref %s: %w", ref, err)
+	}
+	return commit, nil
+}
+
+// ClearCheckpoint removes the checkpoint of branch once its changes are committed.
+// It is a no-op when there is none.
+func ClearCheckpoint(root, branch string) error {
+	ref := WIPRef(branch)
+	if _, err := gitshell.RunInDir(root, "rev-parse", "--verify", "-q", ref); err != nil {
+		return nil
+	}
+	_, err := gitshell.RunInDir(root, "update-ref", "-d", ref)
+	return err
+}
+
+// worktreeTree writes the working tree as a tree object through a private index,
+// seeded from the real one so unchanged files keep their cached stat data.
+func worktreeTree(root string) (string, error) {
+	gitDir, err := gitshell.RunInDir(root, "rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return "", err
+	}
+	index := filepath.Join(gitDir, "fastgit", "wip-index")
+	if err := os.MkdirAll(filepath.Dir(index), 0o755); err != nil {
+		return "", err
+	}
+	defer os.Remove(index)
+
+	if data, err := os.ReadFile(filepath.Join(gitDir, "index")); err == nil {
+		if err := os.WriteFile(index, data, 0o644); err != nil {
+			return "", err
+		}
+	}
+
+	if _, err := gitWithIndex(root, index, "add", "-A"); err != nil {
+		return "", fmt.Errorf("add -A: %w", err)
+	}
+	tree, err := gitWithIndex(root, index, "write-tree")
+	if err != nil {
+		return "", fmt.Errorf("write-tree: %w", err)
+	}
+	return tree, nil
+}
+
+func gitWithIndex(root, index string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", root}, args...)...)
+	cmd.Env = append(os.Environ(), "GIT_INDEX_FILE="+index)
+	var out, stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return strings.TrimSpace(out.String()), nil
+}
@@ -0,0 +1,74 @@
+package daemon
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckpoint(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found in PATH")
+	}
+
+	tmp := t.TempDir()
+	runGitForTest(t, tmp, "init", "-b", "main")
+	runGitForTest(t, tmp, "config", "user.email", "daemon-test@example.com")
+	runGitForTest(t, tmp, "config", "user.name", "daemon-test")
+	writeFileForTest(t, tmp, "a.txt", "a\n")
+	runGitForTest(t, tmp, "add", "a.txt")
+	runGitForTest(t, tmp, "commit", "-m", "init")
+
+	commit, err := Checkpoint(tmp)
+	if err != nil || commit != "" {
+		t.Fatalf("expected no checkpoint for a clean tree, got %q, %v", commit, err)
+	}
+
+	writeFileForTest(t, tmp, "a.txt", "changed\n")
+	writeFileForTest(t, tmp, "new.txt", "untracked\n")
+	commit, err = Checkpoint(tmp)
+	if err != nil || commit == "" {
+		t.Fatalf("expected a checkpoint, got %q, %v", commit, err)
+	}
+	if got := gitOutputForTest(t, tmp, "show", WIPRef("main")+":new.txt"); got != "untracked" {
+		t.Fatalf("expected untracked file in checkpoint, got %q", got)
+	}
+	if got := gitOutputForTest(t, tmp, "log", "-1", "--format=%s", WIPRef("main")); !strings.HasPrefix(got, "wip: ") {
+		t.Fatalf("expected wip subject, got %q", got)
+	}
+	if got := gitOutputForTest(t, tmp, "status", "--porcelain"); got != "M a.txt\n?? new.txt" {
+		t.Fatalf("expected index untouched, got %q", got)
+	}
+
+	again, err := Checkpoint(tmp)
+	if err != nil || again != "" {
+		t.Fatalf("expected no checkpoint without changes, got %q, %v", again, err)
+	}
+
+	runGitForTest(t, tmp, "add", "-A")
+	runGitForTest(t, tmp, "commit", "-m", "work")
+	if commit, err := Checkpoint(tmp); err != nil || commit != "" {
+		t.Fatalf("expected no checkpoint after commit, got %q, %v", commit, err)
+	}
+	if err := exec.Command("git", "-C", tmp, "rev-parse", "--verify", "-q", WIPRef("main")).Run(); err == nil {
+		t.Fatalf("expected stale checkpoint to be dropped")
+	}
+}
+
+func writeFileForTest(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+}
+
+func gitOutputForTest(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).Output()
+	if err != nil {
+		t.Fatalf("git %v failed: %v", args, err)
+	}
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,184 @@
+// Package debugscan flags staged hunks that add leftovers usually not meant to be
+// committed: debug prints, debugger statements, TODO/FIXME markers and blocks of
+// commented-out code.
+package debugscan
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/pubgo/fastgit/pkg/hunk"
+)
+
+// RuleCommentedCode is the built-in check for two or more consecutive added comment
+// lines that look like code.
+const RuleCommentedCode = "commented-code"
+
+// Rule is a named pattern matched against added lines. Files limits it to file globs
+// (matched against the base name); empty means every file.
+type Rule struct {
+	Name    string   `yaml:"name"`
+	Pattern string   `yaml:"pattern"`
+	Files   []string `yaml:"files"`
+}
+
+var scriptFiles = []string{"*.js", "*.jsx", "*.mjs", "*.cjs", "*.ts", "*.tsx", "*.vue", "*.svelte"}
+
+// sourceFiles limits the todo rule to code, so TODO lists in docs and changelogs are not flagged.
+var sourceFiles = append([]string{
+	"*.go", "*.py", "*.rb", "*.rs", "*.java", "*.kt", "*.swift", "*.c", "*.h", "*.cc", "*.cpp", "*.hpp",
+	"*.cs", "*.php", "*.scala", "*.sh", "*.bash", "*.lua", "*.dart", "*.ex", "*.exs",
+}, scriptFiles...)
+
+// DefaultRules cover Go and JavaScript debug prints, debugger statements and TODO/FIXME markers in source files.
+var DefaultRules = []Rule{
+	{Name: "go-print", Pattern: `\bfmt\.Print(?:ln|f)?\(|^\s*print(?:ln)?\(`, Files: []string{"*.go"}},
+	{Name: "console-log", Pattern: `\bconsole\.(?:log|debug|trace|dir)\(`, Files: scriptFiles},
+	{Name: "debugger", Pattern: `^\s*debugger;?\s*$|\bbreakpoint\(\)|\bpdb\.set_trace\(\)|\bbinding\.pry\b|\bdbg!\(`},
+	{Name: "todo", Pattern: `\b(?:TODO|FIXME|XXX)\b`, Files: sourceFiles},
+}
+
+// Options configure a Scanner.
+type Options struct {
+	// Rules are added to DefaultRules.
+	Rules []Rule
+	// Disable names rules to skip, including built-in ones and RuleCommentedCode.
+	Disable []string
+	// AllowPaths are file globs (or directory prefixes) whose changes are not scanned.
+	AllowPaths []string
+}
+
+// Finding is one flagged added line.
+type Finding struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+	Rule string `json:"rule"`
+	Text string `json:"text"`
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s:%d %s: %s", f.File, f.Line, f.Rule, strings.TrimSpace(f.Text))
+}
+
+type compiledRule struct {
+	name  string
+	re    *regexp.Regexp
+	files []string
+}
+
+// Scanner matches added hunk lines against rules.
+type Scanner struct {
+	rules         []compiledRule
+	commentedCode bool
+	allowPaths    []string
+}
+
+// New compiles the default and configured rules.
+func New(opts Options) (*Scanner, error) {
+	s := &Scanner{allowPaths: opts.AllowPaths, commentedCode: !slices.Contains(opts.Disable, RuleCommentedCode)}
+	for _, r := range append(slices.Clone(DefaultRules), opts.Rules...) {
+		if slices.Contains(opts.Disable, r.Name) {
+			continue
+		}
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("debug rule %q: %w", r.Name, err)
+		}
+		s.rules = append(s.rules, compiledRule{name: r.Name, re: re, files: r.Files})
+	}
+	return s, nil
+}
+
+// ScanHunk reports the flagged added lines of h in file, with new-file line numbers.
+func (s *Scanner) ScanHunk(file string, h *hunk.Hunk) []Finding {
+	if matchPath(s.allowPaths, file) {
+		return nil
+	}
+	var (
+		findings []Finding
+		line     = h.NewStart
+		comments []Finding
+	)
+	flushComments := func() {
+		if len(comments) >= 2 {
+			findings = append(findings, comments[0])
+		}
+		comments = nil
+	}
+	for _, l := range h.Lines {
+		switch l.Op {
+		case '+':
+		case ' ':
+			flushComments()
+			line++
+			continue
+		default:
+			continue
+		}
+
+		for _, r := range s.rules {
+			if (len(r.files) == 0 || matchBase(r.files, file)) && r.re.MatchString(l.Text) {
+				findings = append(findings, Finding{File: file, Line: line, Rule: r.name, Text: l.Text})
+			}
+		}
+		if s.commentedCode && commentedCode(file, l.Text) {
+			comments = append(comments, Finding{File: file, Line: line, Rule: RuleCommentedCode, Text: l.Text})
+		} else {
+			flushComments()
+		}
+		line++
+	}
+	flushComments()
+	return findings
+}
+
+var (
+	codeStatement = regexp.MustCompile(`^(?:return|if|for|func|var|const|let|import|def|class|else)\b|^[\w.\[\]]+\s*(?::=|=|\+=|-=)\s*\S|^[\w.]+\(.*\)$`)
+	hashComment   = []string{"*.py", "*.rb", "*.sh", "*.bash"}
+)
+
+// commentedCode reports whether an added line is a `//` comment (`#` for scripts)
+// whose content reads like a statement rather than prose.
+func commentedCode(file, text string) bool {
+	text = strings.TrimSpace(text)
+	body, ok := strings.CutPrefix(text, "//")
+	if !ok && matchBase(hashComment, file) && !strings.HasPrefix(text, "#!") {
+		body, ok = strings.CutPrefix(text, "#")
+	}
+	body = strings.TrimSpace(body)
+	if !ok || body == "" || strings.HasPrefix(body, "/") {
+		return false
+	}
+	return strings.HasSuffix(body, ";") || strings.HasSuffix(body, "{") || body == "}" || codeStatement.MatchString(body)
+}
+
+func matchBase(patterns []string, file string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, filepath.Base(file)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func matchPath(patterns []string, file string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if ok, _ := filepath.Match(pattern, file); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, filepath.Base(file)); ok {
+			return true
+		}
+		if strings.HasPrefix(file, strings.TrimSuffix(pattern, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package debugscan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pubgo/fastgit/pkg/hunk"
+)
+
+const testDiff = `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -10,3 +10,9 @@ func main() {
+ 	x := 1
++	fmt.Println("debug", x)
++	// TODO: remove
++	// y := compute(x)
++	// if y > 0 {
++	// Prose explains the next step.
+ 	run(x)
++	log.Printf("x=%d", x)
+diff --git a/web/app.ts b/web/app.ts
+index 1111111..2222222 100644
+--- a/web/app.ts
++++ b/web/app.ts
+@@ -1,1 +1,2 @@
+ const a = 1;
++console.log(a);
+diff --git a/README.md b/README.md
+index 1111111..2222222 100644
+--- a/README.md
++++ b/README.md
+@@ -1,1 +1,2 @@
+ # Project
++- TODO: document the flags
+`
+
+func scan(t *testing.T, opts Options) []Finding {
+	t.Helper()
+	files, err := hunk.Parse(testDiff)
+	require.NoError(t, err)
+
+	s, err := New(opts)
+	require.NoError(t, err)
+	var findings []Finding
+	for _, f := range files {
+		for _, h := range f.Hunks {
+			findings = append(findings, s.ScanHunk(f.Path, h)...)
+		}
+	}
+	return findings
+}
+
+func TestScanHunk(t *testing.T) {
+	findings := scan(t, Options{})
+	require.Len(t, findings, 4)
+	assert.Equal(t, Finding{File: "main.go", Line: 11, Rule: "go-print", Text: "\tfmt.Println(\"debug\", x)"}, findings[0])
+	assert.Equal(t, "todo", findings[1].Rule)
+	assert.Equal(t, Finding{File: "main.go", Line: 13, Rule: RuleCommentedCode, Text: "\t// y := compute(x)"}, findings[2])
+	assert.Equal(t, Finding{File: "web/app.ts", Line: 2, Rule: "console-log", Text: "console.log(a);"}, findings[3])
+	assert.Equal(t, "web/app.ts:2 console-log: console.log(a);", findings[3].String())
+}
+
+func TestScanHunkOptions(t *testing.T) {
+	findings := scan(t, Options{
+		Rules:      []Rule{{Name: "log-printf", Pattern: `\blog\.Printf\(`, Files: []string{"*.go"}}},
+		Disable:    []string{"go-print", RuleCommentedCode},
+		AllowPaths: []string{"web/"},
+	})
+	require.Len(t, findings, 2)
+	assert.Equal(t, "todo", findings[0].Rule)
+	assert.Equal(t, Finding{File: "main.go", Line: 17, Rule: "log-printf", Text: "\tlog.Printf(\"x=%d\", x)"}, findings[1])
+
+	_, err := New(Options{Rules: []Rule{{Name: "bad", Pattern: `(`}}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,229 @@
+package editorrpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Standard JSON-RPC 2.0 error codes.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeServerError    = -32000
+)
+
+// maxBodyBytes bounds a single request; diffs larger than this should be read from the index instead.
+const maxBodyBytes = 8 << 20
+
+// Handler serves one method. params is the raw "params" member and may be empty.
+type Handler func(ctx context.Context, params json.RawMessage) (any, error)
+
+// Error is a JSON-RPC error object. Handlers may return it to control the code sent to clients.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string { return e.Message }
+
+// InvalidParams wraps err as a CodeInvalidParams error.
+func InvalidParams(err error) error {
+	return &Error{Code: CodeInvalidParams, Message: err.Error()}
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Server is a JSON-RPC 2.0 over HTTP endpoint for editor plugins. It only accepts
+// requests addressed to a loopback host and without a browser Origin, so web pages
+// cannot reach it through DNS rebinding or cross-site requests.
+type Server struct {
+	// Token, when set, must be sent as "Authorization: Bearer <token>".
+	Token string
+
+	mu       sync.Mutex
+	methods  map[string]Handler
+	serialMu sync.Mutex
+}
+
+// NewServer returns a server with the built-in "rpc.methods" method registered.
+func NewServer() *Server {
+	s := &Server{methods: map[string]Handler{}}
+	s.Handle("rpc.methods", func(context.Context, json.RawMessage) (any, error) {
+		return s.Methods(), nil
+	})
+	return s
+}
+
+// Handle registers fn for method, replacing any previous handler.
+func (s *Server) Handle(method string, fn Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.methods[method] = fn
+}
+
+// Methods returns the registered method names in sorted order.
+func (s *Server) Methods() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, 0, len(s.methods))
+	for name := range s.methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ServeHTTP implements POST /rpc and GET /health.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !isLoopbackHost(r.Host) || r.Header.Get("Origin") != "" {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	if !s.authorized(r) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	switch r.URL.Path {
+	case "/health":
+		writeJSON(w, map[string]any{"ok": true, "methods": s.Methods()})
+	case "/rpc":
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(w, s.dispatch(r.Context(), http.MaxBytesReader(w, r.Body, maxBodyBytes)))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// Serve listens on ln until ctx is cancelled.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	srv := &http.Server{Handler: s, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (s *Server) dispatch(ctx context.Context, body io.Reader) response {
+	var req request
+	if err := json.NewDecoder(body).Decode(&req); err != nil {
+		return response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &Error{Code: CodeParseError, Message: err.Error()}}
+	}
+
+	rsp := response{JSONRPC: "2.0", ID: req.ID}
+	if len(rsp.ID) == 0 {
+		rsp.ID = json.RawMessage("null")
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		rsp.Error = &Error{Code: CodeInvalidRequest, Message: `expected "jsonrpc": "2.0" and a method`}
+		return rsp
+	}
+
+	s.mu.Lock()
+	fn, ok := s.methods[req.Method]
+	s.mu.Unlock()
+	if !ok {
+		rsp.Error = &Error{Code: CodeMethodNotFound, Message: "method not found: " + req.Method}
+		return rsp
+	}
+
+	// methods share the git index and working tree, so they run one at a time
+	s.serialMu.Lock()
+	result, err := fn(ctx, req.Params)
+	s.serialMu.Unlock()
+	if err != nil {
+		var rpcErr *Error
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &Error{Code: CodeServerError, Message: err.Error()}
+		}
+		rsp.Error = rpcErr
+		return rsp
+	}
+	rsp.Result = result
+	if rsp.Result == nil {
+		rsp.Result = struct{}{}
+	}
+	return rsp
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	if s.Token == "" {
+		return true
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.Token)) == 1
+}
+
+// DecodeParams unmarshals params into v; empty params leave v untouched.
+func DecodeParams(params json.RawMessage, v any) error {
+	if len(params) == 0 || string(params) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return InvalidParams(err)
+	}
+	return nil
+}
+
+// IsLoopback reports whether addr ("host:port") binds to a loopback interface.
+func IsLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	return isLoopbackName(host)
+}
+
+func isLoopbackHost(hostport string) bool {
+	host := hostport
+	if h, _, err := net.SplitHostPort(hostport); err == nil {
+		host = h
+	}
+	return isLoopbackName(strings.Trim(host, "[]"))
+}
+
+func isLoopbackName(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,116 @@
+package editorrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestServer() *Server {
+	s := NewServer()
+	s.Handle("echo", func(_ context.Context, params json.RawMessage) (any, error) {
+		var p struct {
+			Text string `json:"text"`
+		}
+		if err := DecodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		if p.Text == "" {
+			return nil, errors.New("text is required")
+		}
+		return map[string]string{"text": p.Text}, nil
+	})
+	return s
+}
+
+func call(t *testing.T, s *Server, body string, mutate func(*http.Request)) (*httptest.ResponseRecorder, response) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "http://127.0.0.1:7733/rpc", strings.NewReader(body))
+	if mutate != nil {
+		mutate(req)
+	}
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+
+	var rsp response
+	if rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), &rsp); err != nil {
+			t.Fatalf("decode response %q: %v", rec.Body.String(), err)
+		}
+	}
+	return rec, rsp
+}
+
+func TestDispatch(t *testing.T) {
+	s := newTestServer()
+
+	_, rsp := call(t, s, `{"jsonrpc":"2.0","id":7,"method":"echo","params":{"text":"hi"}}`, nil)
+	if rsp.Error != nil || string(rsp.ID) != "7" {
+		t.Fatalf("unexpected response: %+v", rsp)
+	}
+	if got := rsp.Result.(map[string]any)["text"]; got != "hi" {
+		t.Fatalf("result text = %v, want hi", got)
+	}
+
+	cases := []struct {
+		name string
+		body string
+		code int
+	}{
+		{"parse error", `{`, CodeParseError},
+		{"missing version", `{"id":1,"method":"echo"}`, CodeInvalidRequest},
+		{"unknown method", `{"jsonrpc":"2.0","id":1,"method":"nope"}`, CodeMethodNotFound},
+		{"bad params", `{"jsonrpc":"2.0","id":1,"method":"echo","params":{"text":1}}`, CodeInvalidParams},
+		{"handler error", `{"jsonrpc":"2.0","id":1,"method":"echo","params":{}}`, CodeServerError},
+	}
+	for _, tc := range cases {
+		_, rsp := call(t, s, tc.body, nil)
+		if rsp.Error == nil || rsp.Error.Code != tc.code {
+			t.Fatalf("%s: error = %+v, want code %d", tc.name, rsp.Error, tc.code)
+		}
+	}
+}
+
+func TestRejectsBrowserAndRemoteRequests(t *testing.T) {
+	s := newTestServer()
+	body := `{"jsonrpc":"2.0","id":1,"method":"rpc.methods"}`
+
+	rec, _ := call(t, s, body, func(r *http.Request) { r.Header.Set("Origin", "https://evil.example") })
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("origin request status = %d, want 403", rec.Code)
+	}
+
+	rec, _ = call(t, s, body, func(r *http.Request) { r.Host = "evil.example:7733" })
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("rebinding request status = %d, want 403", rec.Code)
+	}
+
+	s.Token = "secret"
+	rec, _ = call(t, s, body, nil)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("missing token status = %d, want 401", rec.Code)
+	}
+	rec, _ = call(t, s, body, func(r *http.Request) { r.Header.Set("Authorization", "Bearer secret") })
+	if rec.Code != http.StatusOK {
+		t.Fatalf("authorized request status = %d, want 200", rec.Code)
+	}
+}
+
+func TestIsLoopback(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"127.0.0.1:7733": true,
+		"localhost:0":    true,
+		"[::1]:7733":     true,
+		"0.0.0.0:7733":   false,
+		":7733":          false,
+		"10.0.0.1:7733":  false,
+	} {
+		if got := IsLoopback(addr); got != want {
+			t.Fatalf("IsLoopback(%q) = %v, want %v", addr, got, want)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+// Package envcrypt keeps the repository-local env file (`.git/fastgit.env`) encrypted
+// with gpg or age and decrypts it transparently when fastgit starts.
+package envcrypt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/adrg/xdg"
+)
+
+const (
+	MethodGPG = "gpg"
+	MethodAge = "age"
+)
+
+// recipientsSuffix names the file next to the env file that remembers the recipients,
+// so the file can be re-encrypted after `config edit local` without asking again.
+const recipientsSuffix = ".recipients"
+
+// Methods lists the supported encryption methods in lookup order.
+var Methods = []string{MethodGPG, MethodAge}
+
+// EncryptedPath returns the encrypted file name of plainPath for method, e.g. `fastgit.env.gpg`.
+func EncryptedPath(plainPath, method string) string {
+	return plainPath + "." + method
+}
+
+// Find returns the encrypted file of plainPath and its method; path is empty when none exists.
+func Find(plainPath string) (path, method string) {
+	for _, m := range Methods {
+		if _, err := os.Stat(EncryptedPath(plainPath, m)); err == nil {
+			return EncryptedPath(plainPath, m), m
+		}
+	}
+	return "", ""
+}
+
+// Read returns the env file content, decrypting it when an encrypted file exists.
+// It returns nil without error when neither file exists.
+func Read(plainPath string) ([]byte, error) {
+	if path, method := Find(plainPath); path != "" {
+		return decrypt(path, method)
+	}
+	data, err := os.ReadFile(plainPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// Encrypt encrypts plainPath with method and removes the plaintext. Without recipients gpg
+// falls back to a passphrase (--symmetric) and age to `age -p`.
+func Encrypt(plainPath, method string, recipients []string) (string, error) {
+	if path, _ := Find(plainPath); path != "" {
+		return "", fmt.Errorf("%s is already encrypted", path)
+	}
+	data, err := os.ReadFile(plainPath)
+	if err != nil {
+		return "", err
+	}
+	path, err := write(plainPath, method, recipients, data)
+	if err != nil {
+		return "", err
+	}
+	if err := saveRecipients(plainPath, recipients); err != nil {
+		return "", err
+	}
+	return path, shred(plainPath)
+}
+
+// Decrypt restores the plaintext env file and removes the encrypted one.
+func Decrypt(plainPath string) error {
+	path, method := Find(plainPath)
+	if path == "" {
+		return fmt.Errorf("%s is not encrypted", plainPath)
+	}
+	data, err := decrypt(path, method)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(plainPath, data, 0o600); err != nil {
+		return err
+	}
+	_ = os.Remove(plainPath + recipientsSuffix)
+	return os.Remove(path)
+}
+
+// Edit decrypts the env file into a private temporary file, runs edit on it and
+// re-encrypts the result for the recipients recorded by Encrypt.
+func Edit(plainPath string, edit func(path string) error) error {
+	path, method := Find(plainPath)
+	if path == "" {
+		return edit(plainPath)
+	}
+	data, err := decrypt(path, method)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(plainPath), "fastgit-env-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = shred(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := edit(tmp.Name()); err != nil {
+		return err
+	}
+	edited, err := os.ReadFile(tmp.Name())
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(edited, data) {
+		return nil
+	}
+	_, err = write(plainPath, method, loadRecipients(plainPath), edited)
+	return err
+}
+
+func write(plainPath, method string, recipients []string, data []byte) (string, error) {
+	// 先写临时文件再改名，加密失败时不破坏已有的密文
+	path := EncryptedPath(plainPath, method)
+	tmp := path + ".tmp"
+	var args []string
+	switch method {
+	case MethodGPG:
+		args = []string{"--yes", "--output", tmp}
+		if len(recipients) == 0 {
+			args = append(args, "--symmetric")
+		} else {
+			args = append(args, "--encrypt")
+			for _, r := range recipients {
+				args = append(args, "--recipient", r)
+			}
+		}
+	case MethodAge:
+		args = []string{"--encrypt", "--output", tmp}
+		if len(recipients) == 0 {
+			args = append(args, "--passphrase")
+		}
+		for _, r := range recipients {
+			args = append(args, "--recipient", r)
+		}
+	default:
+		return "", fmt.Errorf("unknown encryption method %q, use %s", method, strings.Join(Methods, " or "))
+	}
+
+	if _, err := run(method, data, args...); err != nil {
+		_ = os.Remove(tmp)
+		return "", err
+	}
+	return path, os.Rename(tmp, path)
+}
+
+func decrypt(path, method string) ([]byte, error) {
+	switch method {
+	case MethodGPG:
+		return run(method, nil, "--quiet", "--decrypt", path)
+	case MethodAge:
+		args := []string{"--decrypt"}
+		if identity := ageIdentity(); identity != "" {
+			args = append(args, "--identity", identity)
+		}
+		return run(method, nil, append(args, path)...)
+	}
+	return nil, fmt.Errorf("unknown encryption method %q", method)
+}
+
+// ageIdentity 返回 age 私钥文件：FASTGIT_AGE_IDENTITY > ~/.config/age/keys.txt；都没有时按口令解密
+func ageIdentity() string {
+	if identity := strings.TrimSpace(os.Getenv("FASTGIT_AGE_IDENTITY")); identity != "" {
+		return identity
+	}
+	if path, err := xdg.SearchConfigFile("age/keys.txt"); err == nil {
+		return path
+	}
+	return ""
+}
+
+// run 执行 gpg/age，stdin 写入 input（为空时交给终端，便于 pinentry/口令输入），返回 stdout
+func run(name string, input []byte, args ...string) ([]byte, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return nil, fmt.Errorf("%s not found in PATH: %w", name, err)
+	}
+	cmd := exec.Command(name, args...)
+	if input != nil {
+		cmd.Stdin = bytes.NewReader(input)
+	} else {
+		cmd.Stdin = os.Stdin
+	}
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s %s: %w", name, args[0], err)
+	}
+	return stdout.Bytes(), nil
+}
+
+func saveRecipients(plainPath string, recipients []string) error {
+	if len(recipients) == 0 {
+		return nil
+	}
+	return os.WriteFile(plainPath+recipientsSuffix, []byte(strings.Join(recipients, "\n")+"\n"), 0o600)
+}
+
+func loadRecipients(plainPath string) []string {
+	data, err := os.ReadFile(plainPath + recipientsSuffix)
+	if err != nil {
+		return nil
+	}
+	return strings.Fields(string(data))
+}
+
+// shred 删除前先覆写明文，避免密钥残留在磁盘块中
+func shred(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, make([]byte, info.Size()), 0o600); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
@@ -0,0 +1,74 @@
+package envcrypt
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestGPGRoundTrip(t *testing.T) {
+	if _, err := exec.LookPath("gpg"); err != nil {
+		t.Skip("gpg not found in PATH")
+	}
+	home, err := os.MkdirTemp("", "gpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = exec.Command("gpgconf", "--homedir", home, "--kill", "gpg-agent").Run()
+		_ = os.RemoveAll(home)
+	})
+	t.Setenv("GNUPGHOME", home)
+	if out, err := exec.Command("gpg", "--batch", "--passphrase", "", "--quick-gen-key", "fastgit-test@example.com", "default", "default", "never").CombinedOutput(); err != nil {
+		t.Skipf("cannot create gpg key: %v: %s", err, out)
+	}
+
+	plain := filepath.Join(t.TempDir(), "fastgit.env")
+	if err := os.WriteFile(plain, []byte("OPENAI_API_KEY=\"sk-1\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := Encrypt(plain, MethodGPG, []string{"fastgit-test@example.com"})
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	if path != plain+".gpg" {
+		t.Fatalf("unexpected encrypted path %s", path)
+	}
+	if _, err := os.Stat(plain); !os.IsNotExist(err) {
+		t.Fatalf("plaintext should be removed, stat err: %v", err)
+	}
+	if found, method := Find(plain); found != path || method != MethodGPG {
+		t.Fatalf("unexpected Find result %s %s", found, method)
+	}
+
+	err = Edit(plain, func(p string) error {
+		return os.WriteFile(p, []byte("OPENAI_API_KEY=\"sk-2\"\n"), 0o600)
+	})
+	if err != nil {
+		t.Fatalf("edit: %v", err)
+	}
+	data, err := Read(plain)
+	if err != nil || string(data) != "OPENAI_API_KEY=\"sk-2\"\n" {
+		t.Fatalf("unexpected decrypted data %q, %v", data, err)
+	}
+
+	if err := Decrypt(plain); err != nil {
+		t.Fatalf("decrypt: %v", err)
+	}
+	if found, _ := Find(plain); found != "" {
+		t.Fatalf("encrypted file should be removed, found %s", found)
+	}
+	data, err = os.ReadFile(plain)
+	if err != nil || string(data) != "OPENAI_API_KEY=\"sk-2\"\n" {
+		t.Fatalf("unexpected plaintext %q, %v", data, err)
+	}
+}
+
+func TestReadMissing(t *testing.T) {
+	data, err := Read(filepath.Join(t.TempDir(), "fastgit.env"))
+	if err != nil || data != nil {
+		t.Fatalf("expected no data, got %q, %v", data, err)
+	}
+}
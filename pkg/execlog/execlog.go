@@ -0,0 +1,227 @@
+// Package execlog keeps an append-only log of the git mutations fastgit performs
+// (commits, amends, pushes, tags, deletions, resets) for review with `fastgit audit`.
+package execlog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adrg/xdg"
+)
+
+// Entry is one recorded git mutation.
+type Entry struct {
+	Time time.Time `json:"time"`
+	Repo string    `json:"repo,omitempty"`
+	// Op is commit, amend, push, tag, delete, rename, reset, rebase, merge, pull, cherry-pick,
+	// revert, stash or update-ref.
+	Op   string   `json:"op"`
+	Args []string `json:"args"`
+	// Command is the fastgit invocation that triggered the mutation.
+	Command string `json:"command,omitempty"`
+	// Before / After are HEAD around operations that move it, so they can be undone.
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// headOps move HEAD; their entries record HEAD before and after.
+var headOps = map[string]bool{
+	"commit": true, "amend": true, "reset": true, "rebase": true, "merge": true,
+	"pull": true, "cherry-pick": true, "revert": true, "update-ref": true,
+}
+
+var mu sync.Mutex
+
+// Path returns the log file, ~/.config/fastgit/exec.log.
+func Path() string {
+	return filepath.Join(xdg.ConfigHome, "fastgit", "exec.log")
+}
+
+// Track is called before running git with args in dir (empty for the working
+// directory). For mutating commands it returns a function that appends the entry
+// once the command finished; for everything else it returns a no-op.
+func Track(dir string, args []string) func(err error) {
+	op, ok := Classify(args)
+	if !ok || os.Getenv("FASTGIT_EXEC_LOG") == "0" {
+		return func(error) {}
+	}
+
+	entry := Entry{Op: op, Args: append([]string(nil), args...), Command: strings.Join(os.Args, " ")}
+	if headOps[op] {
+		entry.Before = revParse(dir, "HEAD")
+	}
+	return func(err error) {
+		entry.Time = time.Now()
+		entry.Repo = revParse(dir, "--show-toplevel")
+		if headOps[op] {
+			entry.After = revParse(dir, "HEAD")
+		}
+		if err != nil {
+			entry.Error = err.Error()
+		}
+		_ = Append(entry)
+	}
+}
+
+// Append writes entry as one JSON line.
+func Append(entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	path := Path()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = fmt.Fprintln(f, string(data))
+	return err
+}
+
+// Read returns all entries in the order they were written; a missing log is empty.
+// Lines that cannot be parsed are skipped.
+func Read() ([]Entry, error) {
+	data, err := os.ReadFile(Path())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		var e Entry
+		if json.Unmarshal(sc.Bytes(), &e) == nil {
+			entries = append(entries, e)
+		}
+	}
+	return entries, sc.Err()
+}
+
+// Classify reports whether git args mutate history or refs, and names the operation.
+// Leading global options such as -C dir and -c key=value are skipped.
+func Classify(args []string) (string, bool) {
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") {
+		if args[0] == "-C" || args[0] == "-c" {
+			args = args[min(2, len(args)):]
+			continue
+		}
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return "", false
+	}
+
+	sub, rest := args[0], args[1:]
+	switch sub {
+	case "commit":
+		if has(rest, "--amend") {
+			return "amend", true
+		}
+		return "commit", true
+	case "push":
+		if has(rest, "--delete", "-d") || hasPrefix(rest, ":") {
+			return "delete", true
+		}
+		return "push", true
+	case "tag":
+		if has(rest, "-d", "--delete") {
+			return "delete", true
+		}
+		if len(positional(rest)) == 0 || has(rest, "-l", "--list", "-n", "--contains", "--points-at", "--verify") {
+			return "", false
+		}
+		return "tag", true
+	case "branch":
+		if has(rest, "-d", "-D", "--delete") {
+			return "delete", true
+		}
+		if has(rest, "-m", "-M", "--move") {
+			return "rename", true
+		}
+		return "", false
+	case "reset", "rebase", "merge", "pull", "cherry-pick", "revert":
+		return sub, true
+	case "stash":
+		if has(rest, "drop", "clear") {
+			return "stash", true
+		}
+		return "", false
+	case "update-ref":
+		// 只记录移动分支或 HEAD 的 update-ref；-d 与 refs/fastgit/* 等内部引用不算
+		refs := positional(rest)
+		if has(rest, "-d", "--stdin") || len(refs) == 0 {
+			return "", false
+		}
+		if refs[0] == "HEAD" || strings.HasPrefix(refs[0], "refs/heads/") {
+			return sub, true
+		}
+		return "", false
+	}
+	return "", false
+}
+
+func has(args []string, flags ...string) bool {
+	for _, a := range args {
+		for _, f := range flags {
+			if a == f {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hasPrefix(args []string, prefix string) bool {
+	for _, a := range args {
+		if strings.HasPrefix(a, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func positional(args []string) []string {
+	var out []string
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if a == "-m" || a == "-F" || a == "--sort" || a == "-u" {
+			i++
+			continue
+		}
+		if !strings.HasPrefix(a, "-") {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+func revParse(dir string, arg string) string {
+	cmd := exec.Command("git", "rev-parse", arg)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
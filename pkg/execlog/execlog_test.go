@@ -0,0 +1,97 @@
+package execlog
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/adrg/xdg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassify(t *testing.T) {
+	cases := []struct {
+		args []string
+		op   string
+	}{
+		{[]string{"commit", "-m", "feat: x"}, "commit"},
+		{[]string{"commit", "--amend", "--no-edit"}, "amend"},
+		{[]string{"-C", "/repo", "push", "origin", "main"}, "push"},
+		{[]string{"push", "origin", "--delete", "feat/x"}, "delete"},
+		{[]string{"push", "origin", ":refs/tags/v1.0.0"}, "delete"},
+		{[]string{"tag", "-s", "-m", "v1.0.0", "v1.0.0"}, "tag"},
+		{[]string{"tag", "-d", "v1.0.0"}, "delete"},
+		{[]string{"branch", "-D", "old"}, "delete"},
+		{[]string{"reset", "--soft", "HEAD~1"}, "reset"},
+		{[]string{"stash", "drop"}, "stash"},
+		{[]string{"update-ref", "-m", "fastgit reword", "HEAD", "new", "old"}, "update-ref"},
+		{[]string{"update-ref", "-m", "backport", "refs/heads/release/1.2", "new", "old"}, "update-ref"},
+		{[]string{"update-ref", "ORIG_HEAD", "old"}, ""},
+		{[]string{"update-ref", "--create-reflog", "-m", "wip", "refs/fastgit/wip/main", "c"}, ""},
+		{[]string{"update-ref", "-d", "refs/heads/tmp"}, ""},
+		{[]string{"tag", "-n", "--sort=-committerdate"}, ""},
+		{[]string{"tag"}, ""},
+		{[]string{"branch", "--show-current"}, ""},
+		{[]string{"status", "--porcelain"}, ""},
+		{[]string{"-c", "core.quotepath=off", "log"}, ""},
+	}
+	for _, c := range cases {
+		op, ok := Classify(c.args)
+		assert.Equal(t, c.op, op, c.args)
+		assert.Equal(t, c.op != "", ok, c.args)
+	}
+}
+
+func TestTrackRecordsMutations(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found in PATH")
+	}
+	t.Cleanup(xdg.Reload)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	xdg.Reload()
+
+	dir := t.TempDir()
+	git := func(args ...string) error {
+		record := Track(dir, args)
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_CONFIG_NOSYSTEM=1", "HOME="+dir,
+			"GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com")
+		err := cmd.Run()
+		record(err)
+		return err
+	}
+	require.NoError(t, git("init", "-b", "main"))
+	require.NoError(t, git("commit", "--allow-empty", "-m", "init"))
+	require.NoError(t, git("status"))
+	require.Error(t, git("tag", "-d", "missing"))
+
+	entries, err := Read()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, "commit", entries[0].Op)
+	assert.Empty(t, entries[0].Before)
+	assert.Len(t, entries[0].After, 40)
+	real, _ := filepath.EvalSymlinks(dir)
+	assert.Equal(t, real, entries[0].Repo)
+
+	assert.Equal(t, "delete", entries[1].Op)
+	assert.NotEmpty(t, entries[1].Error)
+	assert.Equal(t, Path(), filepath.Join(xdg.ConfigHome, "fastgit", "exec.log"))
+}
+
+func TestReadMissingLog(t *testing.T) {
+	t.Cleanup(xdg.Reload)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	xdg.Reload()
+
+	entries, err := Read()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+	_, statErr := os.Stat(Path())
+	assert.True(t, errors.Is(statErr, os.ErrNotExist))
+}
@@ -0,0 +1,61 @@
+// Package fswatch watches directory trees with fsnotify, which only watches single directories.
+package fswatch
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/pubgo/funk/v2/log"
+)
+
+// Watcher is an fsnotify.Watcher over whole directory trees: AddTree walks a root and Track
+// adds directories created below it later.
+type Watcher struct {
+	*fsnotify.Watcher
+	// Ignore reports whether the directory at path is skipped together with its subtree; nil
+	// watches every directory.
+	Ignore func(path string) bool
+}
+
+// New returns a Watcher that skips the directories ignore reports.
+func New(ignore func(path string) bool) (*Watcher, error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	return &Watcher{Watcher: w, Ignore: ignore}, nil
+}
+
+// AddTree watches root and every directory below it that is not ignored, and returns the
+// number of directories added. Unreadable directories are skipped.
+func (w *Watcher) AddTree(root string) int {
+	count := 0
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if w.Ignore != nil && w.Ignore(path) {
+			return filepath.SkipDir
+		}
+		if err := w.Add(path); err != nil {
+			log.Debug().Err(err).Str("dir", path).Msg("failed to watch dir")
+			return nil
+		}
+		count++
+		return nil
+	})
+	return count
+}
+
+// Track watches the tree of a directory that event reports as created, so files written into
+// new directories (e.g. by `mkdir -p a/b`) are seen as well. Other events are ignored.
+func (w *Watcher) Track(event fsnotify.Event) {
+	if !event.Has(fsnotify.Create) {
+		return
+	}
+	if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+		w.AddTree(event.Name)
+	}
+}
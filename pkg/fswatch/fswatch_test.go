@@ -0,0 +1,44 @@
+package fswatch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatcher(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "a", "b"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "node_modules", "x"), 0o755))
+
+	w, err := New(func(path string) bool { return filepath.Base(path) == "node_modules" })
+	require.NoError(t, err)
+	defer func() { _ = w.Close() }()
+	require.Equal(t, 3, w.AddTree(root))
+
+	// 新建的多级目录整体加入监控，其中写入的文件同样产生事件
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "c", "d"), 0o755))
+	want := filepath.Join(root, "c", "d", "f.txt")
+	deadline := time.After(5 * time.Second)
+	wrote := false
+	for {
+		select {
+		case event := <-w.Events:
+			w.Track(event)
+			if event.Name == want {
+				return
+			}
+			if !wrote && event.Name == filepath.Join(root, "c") {
+				wrote = true
+				require.NoError(t, os.WriteFile(want, []byte("x"), 0o644))
+			}
+		case err := <-w.Errors:
+			t.Fatal(err)
+		case <-deadline:
+			t.Fatal("no event for a file in a new nested directory")
+		}
+	}
+}
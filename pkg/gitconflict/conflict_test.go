@@ -1,6 +1,8 @@
 package gitconflict
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -30,3 +32,22 @@ func TestRenderSummary(t *testing.T) {
 	require.Contains(t, summary, "2 conflicted file")
 	require.Contains(t, summary, "a.go")
 }
+
+func TestDetectOperation(t *testing.T) {
+	dir := t.TempDir()
+	require.Equal(t, Operation(""), detectOperation(dir))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "CHERRY_PICK_HEAD"), nil, 0o644))
+	require.Equal(t, OpCherryPick, detectOperation(dir))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "MERGE_HEAD"), nil, 0o644))
+	require.Equal(t, OpMerge, detectOperation(dir))
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "rebase-apply"), 0o755))
+	require.Equal(t, OpRebase, detectOperation(dir))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "rebase-apply", "applying"), nil, 0o644))
+	require.Equal(t, OpAm, detectOperation(dir))
+
+	require.Equal(t, []string{ActionContinue, ActionAbort}, InProgress{Op: OpMerge}.Actions())
+	require.Contains(t, InProgress{Op: OpRebase}.Actions(), ActionSkip)
+	require.Equal(t, "rebase in progress, 1 conflicted file(s) remain", InProgress{Op: OpRebase, Conflicts: []string{"a.go"}}.String())
+}
@@ -0,0 +1,111 @@
+package gitconflict
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Operation is a git operation stopped half way (merge, rebase, cherry-pick, revert or am).
+type Operation string
+
+const (
+	OpMerge      Operation = "merge"
+	OpRebase     Operation = "rebase"
+	OpCherryPick Operation = "cherry-pick"
+	OpRevert     Operation = "revert"
+	OpAm         Operation = "am"
+)
+
+// Continuation actions, passed to git as `--<action>`.
+const (
+	ActionContinue = "continue"
+	ActionAbort    = "abort"
+	ActionSkip     = "skip"
+)
+
+// InProgress describes the interrupted operation found in a repository.
+type InProgress struct {
+	Op Operation
+	// Conflicts lists paths that are still unmerged.
+	Conflicts []string
+}
+
+// Actions returns the continuation options git accepts for the operation; merge has no --skip.
+func (p InProgress) Actions() []string {
+	if p.Op == OpMerge {
+		return []string{ActionContinue, ActionAbort}
+	}
+	return []string{ActionContinue, ActionAbort, ActionSkip}
+}
+
+func (p InProgress) String() string {
+	if len(p.Conflicts) == 0 {
+		return fmt.Sprintf("%s in progress, all conflicts resolved", p.Op)
+	}
+	return fmt.Sprintf("%s in progress, %d conflicted file(s) remain", p.Op, len(p.Conflicts))
+}
+
+// DetectInProgress inspects the git dir for MERGE_HEAD, rebase-merge/rebase-apply, CHERRY_PICK_HEAD
+// and REVERT_HEAD. It returns nil when no operation is in progress.
+func DetectInProgress(ctx context.Context, repoRoot string) (*InProgress, error) {
+	if strings.TrimSpace(repoRoot) == "" {
+		repoRoot = "."
+	}
+	gitDir, err := gitOutput(ctx, repoRoot, "rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return nil, err
+	}
+	op := detectOperation(gitDir)
+	if op == "" {
+		return nil, nil
+	}
+	files, err := ListFiles(ctx, repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	return &InProgress{Op: op, Conflicts: files}, nil
+}
+
+func detectOperation(gitDir string) Operation {
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(gitDir, name))
+		return err == nil
+	}
+	switch {
+	case exists("rebase-merge"):
+		return OpRebase
+	case exists("rebase-apply"):
+		// git am 与旧式 rebase 共用 rebase-apply，applying 标记 am
+		if exists(filepath.Join("rebase-apply", "applying")) {
+			return OpAm
+		}
+		return OpRebase
+	case exists("MERGE_HEAD"):
+		return OpMerge
+	case exists("CHERRY_PICK_HEAD"):
+		return OpCherryPick
+	case exists("REVERT_HEAD"):
+		return OpRevert
+	}
+	return ""
+}
+
+// Resume runs `git <op> --<action>` attached to the terminal, since --continue may open the editor.
+func Resume(ctx context.Context, repoRoot string, op Operation, action string) error {
+	if !slices.Contains((InProgress{Op: op}).Actions(), action) {
+		return fmt.Errorf("git %s does not support --%s", op, action)
+	}
+	cmd := exec.CommandContext(ctx, "git", "-C", repoRoot, string(op), "--"+action)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git %s --%s: %w", op, action, err)
+	}
+	return nil
+}
@@ -0,0 +1,296 @@
+// Package gitdiff collects the staged diff of a repository for commit message
+// generation: it streams `git diff --cached`, truncates oversized files, records
+// per-file stats and counts tokens. It never exits the process; every failure is
+// returned as an error.
+package gitdiff
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/pubgo/fastgit/pkg/gitshell"
+	"github.com/pubgo/fastgit/pkg/timing"
+)
+
+// EmptyTree is git's empty tree object, the base for diffs of a root commit.
+const EmptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// Limits control how a diff is truncated while it is streamed.
+type Limits struct {
+	// MaxFileBytes keeps only the first MaxFileBytes of a single file's diff.
+	MaxFileBytes int
+	// MaxTotalBytes summarizes the remaining files as +/- line counts once reached.
+	MaxTotalBytes int
+}
+
+// DefaultLimits cover regular commits without loading huge diffs into memory.
+var DefaultLimits = Limits{
+	MaxFileBytes:  64 << 10,
+	MaxTotalBytes: 1 << 20,
+}
+
+// FileStat is the size and line counts of one file in a diff.
+type FileStat struct {
+	Path      string `json:"path"`
+	Bytes     int    `json:"bytes"`
+	Added     int    `json:"added"`
+	Removed   int    `json:"removed"`
+	Truncated bool   `json:"truncated,omitempty"`
+	Omitted   bool   `json:"omitted,omitempty"`
+
+	kept int
+}
+
+// Diff is a collected diff ready to be sent to a model.
+type Diff struct {
+	Files []string `json:"files"`
+	Diff  string   `json:"diff"`
+
+	// Stats are per-file sizes and line counts in diff order.
+	Stats []FileStat `json:"stats,omitempty"`
+	// Truncated reports that some files in Diff were cut or only summarized.
+	Truncated bool `json:"truncated,omitempty"`
+	// Excluded are staged files left out of Diff by exclude pathspecs; they are still in Files.
+	Excluded []string `json:"excluded,omitempty"`
+	// Tokens is the cl100k_base token count of Diff.
+	Tokens int `json:"tokens,omitempty"`
+}
+
+// Chunks splits Diff by file into parts of at most budget tokens; a diff within
+// budget is a single chunk.
+func (d *Diff) Chunks(budget int) []string {
+	if budget <= 0 || d.Tokens <= budget {
+		return []string{d.Diff}
+	}
+	return SplitByTokens(d.Diff, budget)
+}
+
+// Options select what Staged collects.
+type Options struct {
+	// Base compares the index against this revision instead of HEAD, e.g. AmendBase.
+	Base string
+	// Exclude are pathspecs such as `:(exclude)go.sum`, see ExcludePathspecs.
+	Exclude []string
+	// Limits default to DefaultLimits when zero.
+	Limits Limits
+}
+
+// Staged returns the staged changes of the repository at dir (the working
+// directory when empty). An empty index yields a Diff without files.
+func Staged(ctx context.Context, dir string, opts Options) (*Diff, error) {
+	limits := opts.Limits
+	if limits == (Limits{}) {
+		limits = DefaultLimits
+	}
+
+	nameOnly := []string{"diff", "--cached", "--diff-algorithm=minimal", "--name-only"}
+	if opts.Base != "" {
+		nameOnly = append(nameOnly, opts.Base)
+	}
+	out, err := gitshell.Output(ctx, dir, nameOnly...)
+	if err != nil {
+		return nil, err
+	}
+	files := splitLines(out)
+	if len(files) == 0 {
+		return new(Diff), nil
+	}
+
+	var excluded []string
+	if len(opts.Exclude) > 0 {
+		out, err := gitshell.Output(ctx, dir, append(nameOnly, opts.Exclude...)...)
+		if err != nil {
+			return nil, err
+		}
+		kept := make(map[string]bool, len(files))
+		for _, file := range splitLines(out) {
+			kept[file] = true
+		}
+		for _, file := range files {
+			if !kept[file] {
+				excluded = append(excluded, file)
+			}
+		}
+	}
+
+	diff, err := Stream(ctx, dir, opts.Base, limits, opts.Exclude...)
+	if err != nil {
+		return nil, err
+	}
+	if len(excluded) > 0 {
+		var b strings.Builder
+		b.WriteString(diff.Diff)
+		if diff.Diff != "" && !strings.HasSuffix(diff.Diff, "\n") {
+			b.WriteString("\n")
+		}
+		for _, file := range excluded {
+			_, _ = fmt.Fprintf(&b, "diff --git a/%s b/%s (excluded)\n", file, file)
+		}
+		diff.Diff = b.String()
+	}
+
+	diff.Files = files
+	diff.Excluded = excluded
+	diff.Tokens = CountTokens(diff.Diff)
+	return diff, nil
+}
+
+// AmendBase returns the base that makes Staged show what `git commit --amend`
+// would record: the parent of HEAD, or EmptyTree when HEAD is a root commit.
+func AmendBase(ctx context.Context, dir string) string {
+	parent, err := gitshell.Output(ctx, dir, "rev-parse", "--verify", "--quiet", "HEAD^")
+	if err != nil || strings.TrimSpace(parent) == "" {
+		return EmptyTree
+	}
+	return strings.TrimSpace(parent)
+}
+
+// Stream reads `git diff --cached [base]` incrementally, truncating as it goes so
+// the whole diff is never held in memory. It does not fill Files, Excluded or Tokens.
+func Stream(ctx context.Context, dir, base string, limits Limits, exclude ...string) (*Diff, error) {
+	args := []string{"diff", "--cached", "--diff-algorithm=minimal"}
+	if base != "" {
+		args = append(args, base)
+	}
+	args = append(args, exclude...)
+	defer timing.Track(ctx, timing.PhaseGit, "git "+strings.Join(args, " "))()
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	diff, readErr := Read(stdout, limits)
+	if readErr != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return nil, readErr
+	}
+
+	if err := cmd.Wait(); err != nil {
+		return nil, fmt.Errorf("git %s failed: %s: %w", strings.Join(args, " "), strings.TrimSpace(stderr.String()), err)
+	}
+	return diff, nil
+}
+
+// Read parses a unified diff per "diff --git" section, truncating or summarizing
+// files according to limits. Zero limits keep everything.
+func Read(r io.Reader, limits Limits) (*Diff, error) {
+	var (
+		out     strings.Builder
+		total   int
+		current *FileStat
+		stats   []*FileStat
+		diff    = new(Diff)
+	)
+
+	flush := func() {
+		if current == nil {
+			return
+		}
+		if current.Truncated && !current.Omitted {
+			fmt.Fprintf(&out, "... (truncated %d bytes)\n", current.Bytes-current.kept)
+		}
+		if current.Omitted {
+			fmt.Fprintf(&out, "diff --git %s (omitted: +%d -%d, %d bytes)\n", current.Path, current.Added, current.Removed, current.Bytes)
+		}
+		stats = append(stats, current)
+		current = nil
+	}
+
+	reader := bufio.NewReaderSize(r, 64<<10)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			if strings.HasPrefix(line, "diff --git ") {
+				flush()
+				current = &FileStat{Path: pathFromHeader(line)}
+				current.Omitted = limits.MaxTotalBytes > 0 && total >= limits.MaxTotalBytes
+			}
+
+			if current == nil {
+				current = &FileStat{}
+			}
+			current.Bytes += len(line)
+			switch {
+			case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			case strings.HasPrefix(line, "+"):
+				current.Added++
+			case strings.HasPrefix(line, "-"):
+				current.Removed++
+			}
+
+			if limits.MaxFileBytes > 0 && current.Bytes > limits.MaxFileBytes {
+				current.Truncated = true
+			}
+			if !current.Omitted && !current.Truncated {
+				out.WriteString(line)
+				current.kept += len(line)
+				total += len(line)
+			}
+		}
+
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	flush()
+
+	for _, stat := range stats {
+		if stat.Truncated || stat.Omitted {
+			diff.Truncated = true
+		}
+		diff.Stats = append(diff.Stats, *stat)
+	}
+	diff.Diff = strings.TrimSpace(out.String())
+	return diff, nil
+}
+
+// ExcludePathspecs turns paths or globs into `:(exclude)` pathspecs, skipping
+// blanks and duplicates.
+func ExcludePathspecs(patterns ...string) []string {
+	seen := make(map[string]bool, len(patterns))
+	specs := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		pattern = strings.TrimPrefix(strings.TrimSpace(pattern), "./")
+		if pattern == "" || seen[pattern] {
+			continue
+		}
+		seen[pattern] = true
+		specs = append(specs, ":(exclude)"+pattern)
+	}
+	return specs
+}
+
+// pathFromHeader extracts the b-side path from "diff --git a/x b/x".
+func pathFromHeader(line string) string {
+	line = strings.TrimSpace(strings.TrimPrefix(line, "diff --git "))
+	if idx := strings.LastIndex(line, " b/"); idx >= 0 {
+		return line[idx+3:]
+	}
+	return line
+}
+
+func splitLines(output string) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
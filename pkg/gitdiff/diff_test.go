@@ -0,0 +1,112 @@
+package gitdiff
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pubgo/fastgit/pkg/gitshell"
+)
+
+const sampleDiff = `diff --git a/a.go b/a.go
+index 1..2 100644
+--- a/a.go
++++ b/a.go
+@@ -1 +1,2 @@
+-old
++new
++more
+diff --git a/big.txt b/big.txt
+index 1..2 100644
+--- a/big.txt
++++ b/big.txt
+@@ -0,0 +1,3 @@
++xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx
++xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx
++xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx
+diff --git a/c.go b/c.go
+--- a/c.go
++++ b/c.go
+@@ -1 +1 @@
+-x
++y
+`
+
+func TestReadNoLimits(t *testing.T) {
+	rsp, err := Read(strings.NewReader(sampleDiff), Limits{})
+	require.NoError(t, err)
+	assert.False(t, rsp.Truncated)
+	assert.Equal(t, strings.TrimSpace(sampleDiff), rsp.Diff)
+	require.Len(t, rsp.Stats, 3)
+	assert.Equal(t, FileStat{Path: "a.go", Bytes: rsp.Stats[0].Bytes, Added: 2, Removed: 1, kept: rsp.Stats[0].Bytes}, rsp.Stats[0])
+}
+
+func TestReadTruncatesLargeFile(t *testing.T) {
+	rsp, err := Read(strings.NewReader(sampleDiff), Limits{MaxFileBytes: 120})
+	require.NoError(t, err)
+	assert.True(t, rsp.Truncated)
+	assert.True(t, rsp.Stats[1].Truncated)
+	assert.Equal(t, 3, rsp.Stats[1].Added)
+	assert.Contains(t, rsp.Diff, "... (truncated")
+	assert.Contains(t, rsp.Diff, "diff --git a/c.go b/c.go")
+}
+
+func TestReadSummarizesAfterTotalLimit(t *testing.T) {
+	rsp, err := Read(strings.NewReader(sampleDiff), Limits{MaxTotalBytes: 50})
+	require.NoError(t, err)
+	assert.True(t, rsp.Truncated)
+	assert.False(t, rsp.Stats[0].Omitted)
+	assert.True(t, rsp.Stats[1].Omitted)
+	assert.Contains(t, rsp.Diff, "diff --git big.txt (omitted: +3 -0")
+	assert.Contains(t, rsp.Diff, "diff --git c.go (omitted: +1 -1")
+}
+
+func TestExcludePathspecs(t *testing.T) {
+	assert.Equal(t,
+		[]string{":(exclude)go.sum", ":(exclude)*.pb.go", ":(exclude)dist/"},
+		ExcludePathspecs("go.sum", " ", "*.pb.go", "./dist/", "go.sum"),
+	)
+	assert.Empty(t, ExcludePathspecs())
+}
+
+func TestStaged(t *testing.T) {
+	dir := t.TempDir()
+	run := func(args ...string) {
+		out, err := gitshell.Output(context.Background(), dir, args...)
+		require.NoError(t, err, out)
+	}
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+		run("add", name)
+	}
+	run("init", "-q")
+	run("config", "user.email", "dev@example.com")
+	run("config", "user.name", "dev")
+
+	empty, err := Staged(context.Background(), dir, Options{})
+	require.NoError(t, err)
+	assert.Empty(t, empty.Files)
+
+	write("a.go", "package a\n")
+	run("commit", "-q", "-m", "init")
+	assert.Equal(t, EmptyTree, AmendBase(context.Background(), dir))
+
+	write("b.go", "package b\n")
+	write("go.sum", "x v1\n")
+	diff, err := Staged(context.Background(), dir, Options{Exclude: ExcludePathspecs("go.sum")})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b.go", "go.sum"}, diff.Files)
+	assert.Equal(t, []string{"go.sum"}, diff.Excluded)
+	assert.Contains(t, diff.Diff, "+package b")
+	assert.Contains(t, diff.Diff, "diff --git a/go.sum b/go.sum (excluded)")
+	assert.Positive(t, diff.Tokens)
+
+	amend, err := Staged(context.Background(), dir, Options{Base: AmendBase(context.Background(), dir)})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.go", "b.go", "go.sum"}, amend.Files)
+}
@@ -0,0 +1,38 @@
+package gitdiff
+
+import (
+	"strconv"
+	"strings"
+)
+
+// NumStat is one line of `git diff --numstat`; binary files have -1 additions and deletions.
+type NumStat struct {
+	Path      string `json:"path"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+}
+
+// Binary reports whether git reported the file as binary ("-" counts).
+func (s NumStat) Binary() bool {
+	return s.Additions < 0
+}
+
+// ParseNumstat parses the output of `git diff --numstat`.
+func ParseNumstat(output string) []NumStat {
+	var files []NumStat
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.SplitN(strings.TrimSpace(line), "\t", 3)
+		if len(parts) < 3 {
+			continue
+		}
+		s := NumStat{Path: parts[2], Additions: -1, Deletions: -1}
+		if n, err := strconv.Atoi(parts[0]); err == nil {
+			s.Additions = n
+		}
+		if n, err := strconv.Atoi(parts[1]); err == nil {
+			s.Deletions = n
+		}
+		files = append(files, s)
+	}
+	return files
+}
@@ -0,0 +1,18 @@
+package gitdiff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNumstat(t *testing.T) {
+	files := ParseNumstat("3\t1\tmain.go\n-\t-\tlogo.png\n\n")
+	require.Len(t, files, 2)
+	assert.Equal(t, NumStat{Path: "main.go", Additions: 3, Deletions: 1}, files[0])
+	assert.False(t, files[0].Binary())
+	assert.Equal(t, NumStat{Path: "logo.png", Additions: -1, Deletions: -1}, files[1])
+	assert.True(t, files[1].Binary())
+	assert.Empty(t, ParseNumstat(""))
+}
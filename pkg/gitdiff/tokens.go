@@ -0,0 +1,115 @@
+package gitdiff
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/pubgo/funk/v2/log"
+	"github.com/tiktoken-go/tokenizer"
+)
+
+// DefaultTokenBudget is the default token limit for a diff sent to a model; larger
+// diffs are summarized in chunks first.
+const DefaultTokenBudget = 12000
+
+// cl100k_base matches the gpt-4/gpt-4o family and is close enough for other backends.
+var tokenCodec = sync.OnceValue(func() tokenizer.Codec {
+	codec, err := tokenizer.Get(tokenizer.Cl100kBase)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to load tokenizer, estimating tokens by length")
+		return nil
+	}
+	return codec
+})
+
+// CountTokens counts tokens in texts, estimating 4 bytes per token when the tokenizer is unavailable.
+func CountTokens(texts ...string) int {
+	var tokens int
+	for _, text := range texts {
+		if text == "" {
+			continue
+		}
+		if codec := tokenCodec(); codec != nil {
+			if n, err := codec.Count(text); err == nil {
+				tokens += n
+				continue
+			}
+		}
+		tokens += (len(text) + 3) / 4
+	}
+	return tokens
+}
+
+// Ellipse truncates s to at most maxTokens tokens and appends "..." when it cuts.
+func Ellipse(s string, maxTokens int) string {
+	if maxTokens <= 0 || CountTokens(s) <= maxTokens {
+		return s
+	}
+
+	codec := tokenCodec()
+	if codec == nil {
+		return s[:min(len(s), maxTokens*4)] + "..."
+	}
+	ids, _, err := codec.Encode(s)
+	if err != nil {
+		return s[:min(len(s), maxTokens*4)] + "..."
+	}
+	truncated, err := codec.Decode(ids[:maxTokens])
+	if err != nil {
+		return s[:min(len(s), maxTokens*4)] + "..."
+	}
+	return truncated + "..."
+}
+
+// SplitByTokens groups a unified diff by file into chunks of at most budget tokens;
+// a single file over budget is truncated to it.
+func SplitByTokens(diff string, budget int) []string {
+	if strings.TrimSpace(diff) == "" {
+		return nil
+	}
+	if budget <= 0 || CountTokens(diff) <= budget {
+		return []string{diff}
+	}
+
+	var (
+		chunks  []string
+		current strings.Builder
+		used    int
+	)
+	for _, file := range splitDiffFiles(diff) {
+		n := CountTokens(file)
+		if n > budget {
+			file, n = Ellipse(file, budget)+"\n", budget
+		}
+		if used > 0 && used+n > budget {
+			chunks = append(chunks, current.String())
+			current.Reset()
+			used = 0
+		}
+		current.WriteString(file)
+		used += n
+	}
+	if current.Len() > 0 {
+		chunks = append(chunks, current.String())
+	}
+	return chunks
+}
+
+// splitDiffFiles splits a diff into one part per "diff --git" header.
+func splitDiffFiles(diff string) []string {
+	var (
+		files   []string
+		current strings.Builder
+	)
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		if strings.HasPrefix(line, "diff --git ") && current.Len() > 0 {
+			files = append(files, current.String())
+			current.Reset()
+		}
+		current.WriteString(line)
+	}
+	if current.Len() > 0 {
+		files = append(files, current.String())
+	}
+	return files
+}
@@ -0,0 +1,35 @@
+package gitdiff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountTokens(t *testing.T) {
+	assert.Equal(t, 0, CountTokens(""))
+	assert.Equal(t, 2, CountTokens("hello world"))
+	assert.Equal(t, 4, CountTokens("hello world", "hello world"))
+}
+
+func TestEllipse(t *testing.T) {
+	assert.Equal(t, "hello world", Ellipse("hello world", 5))
+	assert.Equal(t, "hello...", Ellipse("hello world", 1))
+}
+
+func TestSplitByTokens(t *testing.T) {
+	file := func(name string, lines int) string {
+		return "diff --git a/" + name + " b/" + name + "\n" + strings.Repeat("+some added line of code\n", lines)
+	}
+	diff := file("a.go", 10) + file("b.go", 10) + file("c.go", 200)
+
+	assert.Equal(t, []string{diff}, SplitByTokens(diff, 0))
+
+	chunks := SplitByTokens(diff, 200)
+	assert.Len(t, chunks, 2)
+	assert.True(t, strings.HasPrefix(chunks[0], "diff --git a/a.go"))
+	assert.Contains(t, chunks[0], "diff --git a/b.go")
+	assert.True(t, strings.HasPrefix(chunks[1], "diff --git a/c.go"))
+	assert.LessOrEqual(t, CountTokens(chunks[1]), 202)
+}
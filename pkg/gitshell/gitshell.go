@@ -2,16 +2,57 @@ package gitshell
 
 import (
 	"bytes"
+	"context"
 	"errors"
+	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
+
+	"github.com/pubgo/fastgit/pkg/execlog"
+	"github.com/pubgo/fastgit/pkg/timing"
 )
 
+// ShortHash abbreviates an object name to 12 hex digits for display.
+func ShortHash(hash string) string {
+	return hash[:min(len(hash), 12)]
+}
+
 // RunInDir executes git command in the provided directory and returns trimmed stdout.
 func RunInDir(dir string, args ...string) (string, error) {
 	if strings.TrimSpace(dir) == "" {
 		return "", errors.New("empty start dir")
 	}
+	return Run(context.Background(), dir, args...)
+}
+
+// Run executes `git -C dir args` with ctx and returns trimmed stdout; an empty dir runs
+// in the current directory. A failed command's error includes the arguments and git's stderr.
+func Run(ctx context.Context, dir string, args ...string) (string, error) {
+	out, err := run(ctx, dir, nil, nil, args)
+	return strings.TrimSpace(out), err
+}
+
+// Output is Run without trimming, for porcelain output whose leading spaces matter.
+func Output(ctx context.Context, dir string, args ...string) (string, error) {
+	return run(ctx, dir, nil, nil, args)
+}
+
+// RunWithInput is Run with stdin fed to git, e.g. for `cat-file --batch`.
+func RunWithInput(ctx context.Context, dir string, stdin []byte, args ...string) (string, error) {
+	out, err := run(ctx, dir, nil, stdin, args)
+	return strings.TrimSpace(out), err
+}
+
+// RunWithEnv is RunWithInput with extra environment variables such as GIT_AUTHOR_DATE,
+// e.g. for `commit-tree` keeping the original author.
+func RunWithEnv(ctx context.Context, dir string, env []string, stdin []byte, args ...string) (string, error) {
+	out, err := run(ctx, dir, env, stdin, args)
+	return strings.TrimSpace(out), err
+}
+
+func run(ctx context.Context, dir string, env []string, stdin []byte, args []string) (string, error) {
 	if len(args) == 0 {
 		return "", errors.New("empty git args")
 	}
@@ -19,20 +60,31 @@ func RunInDir(dir string, args ...string) (string, error) {
 	if _, err := exec.LookPath("git"); err != nil {
 		return "", err
 	}
+	defer timing.Track(ctx, timing.PhaseGit, "git "+strings.Join(args, " "))()
 
-	cmdArgs := make([]string, 0, len(args)+2)
-	cmdArgs = append(cmdArgs, "-C", dir)
-	cmdArgs = append(cmdArgs, args...)
-
-	cmd := exec.Command("git", cmdArgs...)
-	var out bytes.Buffer
-	cmd.Stdout = &out
-	cmd.Stderr = nil
-	if err := cmd.Run(); err != nil {
-		return "", err
+	gitArgs := args
+	if strings.TrimSpace(dir) != "" {
+		gitArgs = append([]string{"-C", dir}, args...)
 	}
-
-	return strings.TrimSpace(out.String()), nil
+	cmd := exec.CommandContext(ctx, "git", gitArgs...)
+	if env != nil {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	record := execlog.Track(dir, args)
+	err := cmd.Run()
+	record(err)
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, msg)
+		}
+		return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	return stdout.String(), nil
 }
 
 // DetectBranch returns current branch name when available.
@@ -72,3 +124,31 @@ func IsDirty(startDir string) bool {
 
 	return strings.TrimSpace(output) != ""
 }
+
+// FindRepos returns dir itself when it is a git repository, otherwise the repositories
+// among its direct, non-hidden subdirectories.
+func FindRepos(dir string) ([]string, error) {
+	if IsRepo(dir) {
+		return []string{dir}, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var repos []string
+	for _, e := range entries {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		if p := filepath.Join(dir, e.Name()); IsRepo(p) {
+			repos = append(repos, p)
+		}
+	}
+	return repos, nil
+}
+
+// IsRepo reports whether dir is the top level of a git repository or worktree.
+func IsRepo(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	return err == nil
+}
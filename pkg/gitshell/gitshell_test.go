@@ -127,3 +127,66 @@ func runGitForTest(t *testing.T, dir string, args ...string) {
 		t.Fatalf("git %v failed: %v, output=%s", args, err, strings.TrimSpace(string(out)))
 	}
 }
+
+func TestRun_EmptyDirUsesWorkingDirectory(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found in PATH")
+	}
+
+	tmp := t.TempDir()
+	runGitForTest(t, tmp, "init")
+	runGitForTest(t, tmp, "checkout", "-b", "feat/cwd")
+	t.Chdir(tmp)
+
+	got, err := Run(t.Context(), "", "branch", "--show-current")
+	if err != nil || got != "feat/cwd" {
+		t.Fatalf("expected feat/cwd, got %q, err %v", got, err)
+	}
+	if _, err := RunInDir("", "status"); err == nil {
+		t.Fatal("expected RunInDir to reject an empty dir")
+	}
+}
+
+func TestFindRepos(t *testing.T) {
+	ws := t.TempDir()
+	for _, dir := range []string{"a/.git", "b/.git", ".hidden/.git", "plain"} {
+		if err := os.MkdirAll(filepath.Join(ws, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	repos, err := FindRepos(ws)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(ws, "a"), filepath.Join(ws, "b")}
+	if strings.Join(repos, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected %v, got %v", want, repos)
+	}
+
+	if repos, err = FindRepos(filepath.Join(ws, "a")); err != nil || len(repos) != 1 || repos[0] != filepath.Join(ws, "a") {
+		t.Fatalf("expected the repository itself, got %v, err %v", repos, err)
+	}
+	if _, err := FindRepos(filepath.Join(ws, "missing")); err == nil {
+		t.Fatal("expected an error for a missing dir")
+	}
+}
+
+func TestRunWithEnv(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found in PATH")
+	}
+
+	tmp := t.TempDir()
+	runGitForTest(t, tmp, "init")
+	env := []string{"GIT_AUTHOR_NAME=a", "GIT_AUTHOR_EMAIL=a@example.com", "GIT_AUTHOR_DATE=1700000000 +0000",
+		"GIT_COMMITTER_NAME=c", "GIT_COMMITTER_EMAIL=c@example.com"}
+	commit, err := RunWithEnv(t.Context(), tmp, env, []byte("msg\n"), "commit-tree", "4b825dc642cb6eb9a060e54bf8d69288fbee4904")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Run(t.Context(), tmp, "log", "-1", "--format=%an %at %B", commit)
+	if err != nil || got != "a 1700000000 msg" {
+		t.Fatalf("expected author from env, got %q, err %v", got, err)
+	}
+}
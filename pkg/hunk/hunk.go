@@ -0,0 +1,430 @@
+package hunk
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var headerPattern = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@ ?(.*)$`)
+
+// Line is a single diff line; Op is ' ', '-', '+' or '\\' ("\ No newline at end of file").
+type Line struct {
+	Op   byte
+	Text string
+}
+
+func (l Line) String() string { return string(l.Op) + l.Text }
+
+// IsChange reports whether the line adds or removes content.
+func (l Line) IsChange() bool { return l.Op == '-' || l.Op == '+' }
+
+// Hunk is one @@ section of a file diff.
+type Hunk struct {
+	OldStart, OldLines int
+	NewStart, NewLines int
+	Section            string
+	Lines              []Line
+}
+
+// File is the diff of a single path.
+type File struct {
+	Path    string
+	Header  []string
+	Hunks   []*Hunk
+	Binary  bool
+	Deleted bool
+}
+
+// Piece is one selectable unit: a whole hunk or a split part of it.
+// It owns the changed lines in Hunk.Lines[Start:End]; lines outside are shown as context.
+type Piece struct {
+	File  *File
+	Hunk  *Hunk
+	Start int
+	End   int
+}
+
+// Diff returns the unstaged diff (worktree against index) for the given pathspecs.
+func Diff(ctx context.Context, dir string, paths ...string) (string, error) {
+	args := []string{"diff", "--no-color", "--no-ext-diff", "--no-relative", "--src-prefix=a/", "--dst-prefix=b/"}
+	if len(paths) > 0 {
+		args = append(append(args, "--"), paths...)
+	}
+	return run(ctx, dir, "", args...)
+}
+
+// CachedDiff returns the staged diff (index against HEAD) for the given pathspecs.
+func CachedDiff(ctx context.Context, dir string, paths ...string) (string, error) {
+	args := []string{"diff", "--cached", "--no-color", "--no-ext-diff", "--no-relative", "--src-prefix=a/", "--dst-prefix=b/"}
+	if len(paths) > 0 {
+		args = append(append(args, "--"), paths...)
+	}
+	return run(ctx, dir, "", args...)
+}
+
+// Parse splits unified diff output into files and hunks.
+func Parse(diff string) ([]*File, error) {
+	var files []*File
+	var file *File
+	var cur *Hunk
+	for _, raw := range strings.Split(strings.TrimRight(diff, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(raw, "diff --git "):
+			file = &File{Header: []string{raw}, Path: pathFromHeader(raw)}
+			files = append(files, file)
+			cur = nil
+		case file == nil:
+			continue
+		case strings.HasPrefix(raw, "@@"):
+			m := headerPattern.FindStringSubmatch(raw)
+			if m == nil {
+				return nil, fmt.Errorf("invalid hunk header in %s: %q", file.Path, raw)
+			}
+			cur = &Hunk{
+				OldStart: atoi(m[1]), OldLines: count(m[2]),
+				NewStart: atoi(m[3]), NewLines: count(m[4]),
+				Section: m[5],
+			}
+			file.Hunks = append(file.Hunks, cur)
+		case cur == nil:
+			file.Header = append(file.Header, raw)
+			switch {
+			case strings.HasPrefix(raw, "Binary files "), strings.HasPrefix(raw, "GIT binary patch"):
+				file.Binary = true
+			case strings.HasPrefix(raw, "deleted file mode"):
+				file.Deleted = true
+			case strings.HasPrefix(raw, "+++ b/"):
+				file.Path = strings.TrimPrefix(raw, "+++ b/")
+			}
+		case raw == "":
+			// 部分工具会去掉空上下文行的前导空格
+			cur.Lines = append(cur.Lines, Line{Op: ' '})
+		default:
+			cur.Lines = append(cur.Lines, Line{Op: raw[0], Text: raw[1:]})
+		}
+	}
+	return files, nil
+}
+
+// Pieces returns one piece per hunk, skipping binary files.
+func Pieces(files []*File) []*Piece {
+	var pieces []*Piece
+	for _, f := range files {
+		if f.Binary {
+			continue
+		}
+		for _, h := range f.Hunks {
+			pieces = append(pieces, &Piece{File: f, Hunk: h, Start: 0, End: len(h.Lines)})
+		}
+	}
+	return pieces
+}
+
+// Split breaks the piece into one piece per run of consecutive changes.
+// It returns nil when the piece cannot be split further.
+func (p *Piece) Split() []*Piece {
+	if p.File.Deleted {
+		return nil
+	}
+	var parts []*Piece
+	start := -1
+	for i := p.Start; i < p.End; i++ {
+		change := p.Hunk.Lines[i].IsChange() || p.Hunk.Lines[i].Op == '\\'
+		switch {
+		case change && start < 0:
+			start = i
+		case !change && start >= 0:
+			parts = append(parts, &Piece{File: p.File, Hunk: p.Hunk, Start: start, End: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		parts = append(parts, &Piece{File: p.File, Hunk: p.Hunk, Start: start, End: p.End})
+	}
+	if len(parts) < 2 {
+		return nil
+	}
+	return parts
+}
+
+// Lines returns the piece's own lines plus up to context surrounding lines of the hunk.
+// Changes owned by other pieces are rendered as they would look in the index.
+func (p *Piece) Lines(context int) []Line {
+	from, to := p.Start, p.End
+	for n := 0; n < context && from > 0; n++ {
+		from--
+	}
+	for n := 0; n < context && to < len(p.Hunk.Lines); n++ {
+		to++
+	}
+	var lines []Line
+	for i := from; i < to; i++ {
+		l := p.Hunk.Lines[i]
+		if i < p.Start || i >= p.End {
+			switch l.Op {
+			case '+', '\\':
+				continue
+			case '-':
+				l.Op = ' '
+			}
+		}
+		lines = append(lines, l)
+	}
+	return lines
+}
+
+// Whole reports whether the piece covers its entire hunk.
+func (p *Piece) Whole() bool { return p.Start == 0 && p.End == len(p.Hunk.Lines) }
+
+// ParseEdited reads a hunk body edited by the user: '#' lines are dropped, empty lines are context.
+func ParseEdited(text string) ([]Line, error) {
+	var lines []Line
+	for _, raw := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(raw, "#"), strings.HasPrefix(raw, "@@"):
+			continue
+		case raw == "":
+			lines = append(lines, Line{Op: ' '})
+		case strings.ContainsRune(" -+\\", rune(raw[0])):
+			lines = append(lines, Line{Op: raw[0], Text: raw[1:]})
+		default:
+			return nil, fmt.Errorf("invalid line %q: must start with ' ', '-' or '+'", raw)
+		}
+	}
+	return lines, nil
+}
+
+// BuildPatch renders a patch containing only the selected pieces.
+// Unselected removals become context and unselected additions are dropped,
+// so the patch applies cleanly to the index.
+func BuildPatch(files []*File, selected []*Piece) string {
+	owned := make(map[*Hunk][]bool)
+	for _, p := range selected {
+		mask := owned[p.Hunk]
+		if mask == nil {
+			mask = make([]bool, len(p.Hunk.Lines))
+			owned[p.Hunk] = mask
+		}
+		for i := p.Start; i < p.End; i++ {
+			mask[i] = true
+		}
+	}
+
+	var buf strings.Builder
+	for _, f := range files {
+		offset := 0
+		wroteHeader := false
+		for _, h := range f.Hunks {
+			mask, ok := owned[h]
+			if !ok {
+				continue
+			}
+			lines, oldLines, newLines := selectLines(h, mask)
+			if !hasChange(lines) {
+				continue
+			}
+			if !wroteHeader {
+				for _, l := range f.Header {
+					buf.WriteString(l + "\n")
+				}
+				wroteHeader = true
+			}
+			newStart := h.OldStart + offset
+			if h.OldStart == 0 && newLines > 0 {
+				// 新文件：旧侧为空，新侧从第 1 行开始
+				newStart = 1
+			}
+			fmt.Fprintf(&buf, "@@ -%d,%d +%d,%d @@\n", h.OldStart, oldLines, newStart, newLines)
+			for _, l := range lines {
+				buf.WriteString(l.String() + "\n")
+			}
+			offset += newLines - oldLines
+		}
+	}
+	return buf.String()
+}
+
+// BuildUnstagePatch renders a patch of a staged diff (see CachedDiff) containing only
+// the selected pieces, for Unstage. Unselected additions become context and unselected
+// removals are dropped, so the patch reverse-applies cleanly to the index.
+func BuildUnstagePatch(files []*File, selected []*Piece) string {
+	owned := make(map[*Hunk][]bool)
+	for _, p := range selected {
+		mask := owned[p.Hunk]
+		if mask == nil {
+			mask = make([]bool, len(p.Hunk.Lines))
+			owned[p.Hunk] = mask
+		}
+		for i := p.Start; i < p.End; i++ {
+			mask[i] = true
+		}
+	}
+
+	var buf strings.Builder
+	for _, f := range files {
+		offset := 0
+		wroteHeader := false
+		for _, h := range f.Hunks {
+			mask, ok := owned[h]
+			if !ok {
+				continue
+			}
+			lines, oldLines, newLines := selectReverseLines(h, mask)
+			if !hasChange(lines) {
+				continue
+			}
+			if !wroteHeader {
+				for _, l := range f.Header {
+					buf.WriteString(l + "\n")
+				}
+				wroteHeader = true
+			}
+			oldStart := h.NewStart - offset
+			if oldLines == 0 {
+				// 撤销整个新增文件：旧侧为空
+				oldStart = 0
+			}
+			fmt.Fprintf(&buf, "@@ -%d,%d +%d,%d @@\n", oldStart, oldLines, h.NewStart, newLines)
+			for _, l := range lines {
+				buf.WriteString(l.String() + "\n")
+			}
+			offset += newLines - oldLines
+		}
+	}
+	return buf.String()
+}
+
+// Unstage reverse-applies a patch built by BuildUnstagePatch to the index; the worktree is untouched.
+func Unstage(ctx context.Context, dir, patch string) error {
+	_, err := run(ctx, dir, patch, "apply", "--cached", "--reverse", "--recount", "--whitespace=nowarn", "-")
+	return err
+}
+
+// Apply stages the patch into the index.
+func Apply(ctx context.Context, dir, patch string) error {
+	_, err := run(ctx, dir, patch, "apply", "--cached", "--recount", "--whitespace=nowarn", "-")
+	return err
+}
+
+// Check verifies that the patch would apply to the index without changing it.
+func Check(ctx context.Context, dir, patch string) error {
+	_, err := run(ctx, dir, patch, "apply", "--cached", "--recount", "--check", "--whitespace=nowarn", "-")
+	return err
+}
+
+func selectLines(h *Hunk, mask []bool) (lines []Line, oldLines, newLines int) {
+	dropped := false
+	for i, l := range h.Lines {
+		keep := mask[i]
+		switch l.Op {
+		case '-':
+			if !keep {
+				l.Op = ' '
+			}
+		case '+':
+			if !keep {
+				dropped = true
+				continue
+			}
+		case '\\':
+			// 标记跟随上一行；上一行被丢弃时一并丢弃
+			if dropped {
+				continue
+			}
+		}
+		dropped = false
+		lines = append(lines, l)
+		if l.Op == ' ' || l.Op == '-' {
+			oldLines++
+		}
+		if l.Op == ' ' || l.Op == '+' {
+			newLines++
+		}
+	}
+	return lines, oldLines, newLines
+}
+
+// selectReverseLines 与 selectLines 相反：未选中的新增行已在暂存区中，保留为上下文；未选中的删除行丢弃
+func selectReverseLines(h *Hunk, mask []bool) (lines []Line, oldLines, newLines int) {
+	dropped := false
+	for i, l := range h.Lines {
+		keep := mask[i]
+		switch l.Op {
+		case '+':
+			if !keep {
+				l.Op = ' '
+			}
+		case '-':
+			if !keep {
+				dropped = true
+				continue
+			}
+		case '\\':
+			if dropped {
+				continue
+			}
+		}
+		dropped = false
+		lines = append(lines, l)
+		if l.Op == ' ' || l.Op == '-' {
+			oldLines++
+		}
+		if l.Op == ' ' || l.Op == '+' {
+			newLines++
+		}
+	}
+	return lines, oldLines, newLines
+}
+
+func hasChange(lines []Line) bool {
+	for _, l := range lines {
+		if l.IsChange() {
+			return true
+		}
+	}
+	return false
+}
+
+func pathFromHeader(line string) string {
+	rest := strings.TrimPrefix(line, "diff --git ")
+	if idx := strings.Index(rest, " b/"); idx >= 0 {
+		return rest[idx+3:]
+	}
+	return rest
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+func count(s string) int {
+	if s == "" {
+		return 1
+	}
+	return atoi(s)
+}
+
+func run(ctx context.Context, dir, stdin string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+	var out, stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			return "", fmt.Errorf("git %s: %w", args[0], err)
+		}
+		return "", fmt.Errorf("git %s: %s", args[0], msg)
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,183 @@
+package hunk
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSplitAndBuildPatch(t *testing.T) {
+	diff := `diff --git a/a.txt b/a.txt
+index 1111111..2222222 100644
+--- a/a.txt
++++ b/a.txt
+@@ -1,7 +1,7 @@ func main() {
+-one
++ONE
+ two
+ three
+ four
+ five
+-six
++SIX
+ seven
+`
+	files, err := Parse(diff)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	pieces := Pieces(files)
+	if len(pieces) != 1 || pieces[0].Hunk.Section != "func main() {" {
+		t.Fatalf("unexpected pieces: %+v", pieces)
+	}
+
+	parts := pieces[0].Split()
+	if len(parts) != 2 {
+		t.Fatalf("expected 2 parts, got %d", len(parts))
+	}
+	if parts[1].Split() != nil {
+		t.Fatalf("single change run must not split further")
+	}
+
+	got := BuildPatch(files, parts[1:])
+	want := `diff --git a/a.txt b/a.txt
+index 1111111..2222222 100644
+--- a/a.txt
++++ b/a.txt
+@@ -1,7 +1,7 @@
+ one
+ two
+ three
+ four
+ five
+-six
++SIX
+ seven
+`
+	if got != want {
+		t.Fatalf("patch mismatch:\n%s", got)
+	}
+
+	if BuildPatch(files, nil) != "" {
+		t.Fatalf("empty selection must produce empty patch")
+	}
+}
+
+func TestApplyStagesSelectedHunk(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found in PATH")
+	}
+
+	dir := t.TempDir()
+	runGitForTest(t, dir, "init", "-q")
+	path := filepath.Join(dir, "a.txt")
+	writeForTest(t, path, "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n")
+	runGitForTest(t, dir, "add", "a.txt")
+	writeForTest(t, path, "one\n2\n3\n4\n5\n6\n7\n8\n9\nten\n")
+
+	ctx := context.Background()
+	diff, err := Diff(ctx, dir)
+	if err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+	files, err := Parse(diff)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	pieces := Pieces(files)
+	if len(pieces) != 2 {
+		t.Fatalf("expected 2 hunks, got %d", len(pieces))
+	}
+
+	patch := BuildPatch(files, pieces[1:])
+	if err := Check(ctx, dir, patch); err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	if err := Apply(ctx, dir, patch); err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+
+	out, err := exec.Command("git", "-C", dir, "show", ":a.txt").Output()
+	if err != nil {
+		t.Fatalf("git show: %v", err)
+	}
+	if got := string(out); got != "1\n2\n3\n4\n5\n6\n7\n8\n9\nten\n" {
+		t.Fatalf("unexpected index content: %q", got)
+	}
+}
+
+func TestUnstageSelectedHunk(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found in PATH")
+	}
+
+	dir := t.TempDir()
+	runGitForTest(t, dir, "init", "-q")
+	path := filepath.Join(dir, "a.txt")
+	writeForTest(t, path, "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n")
+	runGitForTest(t, dir, "add", "a.txt")
+	runGitForTest(t, dir, "-c", "user.name=t", "-c", "user.email=t@t", "commit", "-q", "-m", "init")
+	writeForTest(t, path, "0\n1\n2\n3\n4\n5\n6\n7\n8\n9\nten\n")
+	runGitForTest(t, dir, "add", "a.txt")
+
+	ctx := context.Background()
+	diff, err := CachedDiff(ctx, dir)
+	if err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+	files, err := Parse(diff)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	pieces := Pieces(files)
+	if len(pieces) != 2 {
+		t.Fatalf("expected 2 hunks, got %d", len(pieces))
+	}
+
+	if err := Unstage(ctx, dir, BuildUnstagePatch(files, pieces[1:])); err != nil {
+		t.Fatalf("unstage: %v", err)
+	}
+
+	out, err := exec.Command("git", "-C", dir, "show", ":a.txt").Output()
+	if err != nil {
+		t.Fatalf("git show: %v", err)
+	}
+	if got := string(out); got != "0\n1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n" {
+		t.Fatalf("unexpected index content: %q", got)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "0\n1\n2\n3\n4\n5\n6\n7\n8\n9\nten\n" {
+		t.Fatalf("worktree must be untouched: %q, %v", data, err)
+	}
+}
+
+func TestParseEdited(t *testing.T) {
+	lines, err := ParseEdited("# comment\n a\n-b\n\n+c\n")
+	if err != nil {
+		t.Fatalf("parse edited: %v", err)
+	}
+	if len(lines) != 4 || lines[2].Op != ' ' || lines[3].String() != "+c" {
+		t.Fatalf("unexpected lines: %+v", lines)
+	}
+
+	if _, err := ParseEdited("oops\n"); err == nil {
+		t.Fatalf("expected error for line without prefix")
+	}
+}
+
+func runGitForTest(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v failed: %v, output=%s", args, err, strings.TrimSpace(string(out)))
+	}
+}
+
+func writeForTest(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
@@ -0,0 +1,193 @@
+// Package maintain runs repository housekeeping (gc, repack, commit-graph) and registers
+// repositories for git's background maintenance, reporting the object store size before
+// and after so the effect is visible.
+package maintain
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pubgo/fastgit/pkg/gitshell"
+)
+
+// Step names accepted by Options.Skip.
+const (
+	StepGC          = "gc"
+	StepRepack      = "repack"
+	StepCommitGraph = "commit-graph"
+)
+
+// Steps are the maintenance steps in the order Run executes them.
+var Steps = []string{StepGC, StepRepack, StepCommitGraph}
+
+// DefaultPrune is the expiry passed to `git gc --prune`, git's own default.
+const DefaultPrune = "2.weeks.ago"
+
+// Stats is the object store of a repository as reported by `git count-objects -v`.
+type Stats struct {
+	LooseObjects  int   `json:"loose_objects"`
+	LooseSize     int64 `json:"loose_size"`
+	PackedObjects int   `json:"packed_objects"`
+	Packs         int   `json:"packs"`
+	PackSize      int64 `json:"pack_size"`
+	Garbage       int   `json:"garbage"`
+	GarbageSize   int64 `json:"garbage_size"`
+	// CommitGraph is set when a commit-graph file or chain exists.
+	CommitGraph bool `json:"commit_graph"`
+}
+
+// Size is the total on-disk size in bytes.
+func (s Stats) Size() int64 { return s.LooseSize + s.PackSize + s.GarbageSize }
+
+// Options configure Run.
+type Options struct {
+	// Aggressive passes --aggressive to git gc: slower, smaller packs.
+	Aggressive bool
+	// Prune is the --prune expiry of git gc, DefaultPrune when empty.
+	Prune string
+	// Skip names steps not to run.
+	Skip []string
+}
+
+// Command is one git invocation of a step.
+type Command struct {
+	Step string
+	Args []string
+}
+
+func (c Command) String() string { return "git " + strings.Join(c.Args, " ") }
+
+// Plan returns the commands Run executes for opts.
+func Plan(opts Options) []Command {
+	prune := strings.TrimSpace(opts.Prune)
+	if prune == "" {
+		prune = DefaultPrune
+	}
+	var cmds []Command
+	for _, step := range Steps {
+		if slices.Contains(opts.Skip, step) {
+			continue
+		}
+		switch step {
+		case StepGC:
+			args := []string{"gc", "--quiet", "--prune=" + prune}
+			if opts.Aggressive {
+				args = append(args, "--aggressive")
+			}
+			cmds = append(cmds, Command{Step: step, Args: args})
+		case StepRepack:
+			// gc 不为非 bare 仓库写 bitmap；bitmap 加快 fetch、clone 与对象计数，只能随全量 repack 写出。
+			// gc 之后只剩一个包，复用已有 delta 的全量 repack 很快
+			cmds = append(cmds, Command{Step: step, Args: []string{"repack", "-a", "-d", "-l", "--write-bitmap-index", "--quiet"}})
+		case StepCommitGraph:
+			cmds = append(cmds, Command{Step: step, Args: []string{"commit-graph", "write", "--reachable", "--changed-paths"}})
+		}
+	}
+	return cmds
+}
+
+// Result is the outcome of one executed command.
+type Result struct {
+	Command
+	Duration time.Duration
+}
+
+// Run executes Plan(opts) in the repository at dir and stops at the first failing step.
+// progress, when set, is called before each command.
+func Run(ctx context.Context, dir string, opts Options, progress func(Command)) ([]Result, error) {
+	var results []Result
+	for _, cmd := range Plan(opts) {
+		if progress != nil {
+			progress(cmd)
+		}
+		start := time.Now()
+		if _, err := gitshell.Run(ctx, dir, cmd.Args...); err != nil {
+			return results, fmt.Errorf("%s: %w", cmd.Step, err)
+		}
+		results = append(results, Result{Command: cmd, Duration: time.Since(start)})
+	}
+	return results, nil
+}
+
+// Collect reads the object store statistics of the repository at dir.
+func Collect(ctx context.Context, dir string) (Stats, error) {
+	out, err := gitshell.Run(ctx, dir, "count-objects", "-v")
+	if err != nil {
+		return Stats{}, err
+	}
+	values := make(map[string]int64)
+	for _, line := range strings.Split(out, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		n, _ := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		values[strings.TrimSpace(key)] = n
+	}
+	stats := Stats{
+		LooseObjects:  int(values["count"]),
+		LooseSize:     values["size"] * 1024,
+		PackedObjects: int(values["in-pack"]),
+		Packs:         int(values["packs"]),
+		PackSize:      values["size-pack"] * 1024,
+		Garbage:       int(values["garbage"]),
+		GarbageSize:   values["size-garbage"] * 1024,
+	}
+
+	if objects, err := gitshell.Run(ctx, dir, "rev-parse", "--path-format=absolute", "--git-path", "objects"); err == nil {
+		for _, name := range []string{"info/commit-graph", "info/commit-graphs/commit-graph-chain"} {
+			if _, err := os.Stat(filepath.Join(objects, name)); err == nil {
+				stats.CommitGraph = true
+			}
+		}
+	}
+	return stats, nil
+}
+
+// Schedule registers the repository at dir for `git maintenance` and starts the
+// background scheduler (cron, launchd, systemd timers or Task Scheduler).
+func Schedule(ctx context.Context, dir string) error {
+	_, err := gitshell.Run(ctx, dir, "maintenance", "start")
+	return err
+}
+
+// Unschedule removes the repository at dir from background maintenance; the scheduler
+// keeps running for other registered repositories.
+func Unschedule(ctx context.Context, dir string) error {
+	_, err := gitshell.Run(ctx, dir, "maintenance", "unregister")
+	return err
+}
+
+// Scheduled reports whether the repository at dir is registered for background maintenance.
+func Scheduled(ctx context.Context, dir string) bool {
+	top, err := gitshell.Run(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return false
+	}
+	out, _ := gitshell.Run(ctx, dir, "config", "--global", "--get-all", "maintenance.repo")
+	for _, repo := range strings.Split(out, "\n") {
+		if samePath(strings.TrimSpace(repo), top) {
+			return true
+		}
+	}
+	return false
+}
+
+func samePath(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	if ra, err := filepath.EvalSymlinks(a); err == nil {
+		a = ra
+	}
+	if rb, err := filepath.EvalSymlinks(b); err == nil {
+		b = rb
+	}
+	return filepath.Clean(a) == filepath.Clean(b)
+}
@@ -0,0 +1,73 @@
+package maintain
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pubgo/fastgit/pkg/gitshell"
+)
+
+func TestPlan(t *testing.T) {
+	var got []string
+	for _, cmd := range Plan(Options{Aggressive: true, Skip: []string{StepRepack}}) {
+		got = append(got, cmd.String())
+	}
+	want := []string{
+		"git gc --quiet --prune=2.weeks.ago --aggressive",
+		"git commit-graph write --reachable --changed-paths",
+	}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("unexpected plan: %v", got)
+	}
+}
+
+func TestRunPacksLooseObjects(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	ctx := context.Background()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.email", "dev@example.com"},
+		{"config", "user.name", "dev"},
+		{"add", "-A"},
+		{"commit", "-q", "-m", "init"},
+	} {
+		if _, err := gitshell.Run(ctx, dir, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	before, err := Collect(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if before.LooseObjects == 0 || before.Packs != 0 || before.CommitGraph {
+		t.Fatalf("unexpected stats before: %+v", before)
+	}
+
+	var steps []string
+	results, err := Run(ctx, dir, Options{Prune: "now"}, func(c Command) { steps = append(steps, c.Step) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != len(Steps) || strings.Join(steps, ",") != strings.Join(Steps, ",") {
+		t.Fatalf("unexpected steps: %v", steps)
+	}
+
+	after, err := Collect(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.LooseObjects != 0 || after.Packs == 0 || after.PackedObjects == 0 || !after.CommitGraph {
+		t.Fatalf("unexpected stats after: %+v", after)
+	}
+}
@@ -0,0 +1,73 @@
+package msgtemplate
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/pubgo/fastgit/pkg/ticket"
+)
+
+// Template is a reusable commit message stored under `commit.templates` in config.yaml.
+type Template struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Message     string `yaml:"message"`
+}
+
+// Vars are the values available to a template, e.g. `chore(release): {{.Branch}} {{.Date}}`.
+type Vars struct {
+	Branch string
+	Ticket string
+	Date   string
+	Time   string
+	User   string
+	Repo   string
+	// Issue is the numeric issue in the branch name, e.g. `1234` from `feat/1234-login`.
+	Issue string
+	// Files is the number of changed files; only the `commit --fast` message sets it.
+	Files int
+}
+
+// NewVars fills the date fields and derives the ticket key from the branch.
+func NewVars(branch, user, repo string, now time.Time) Vars {
+	return Vars{
+		Branch: branch,
+		Ticket: ticket.KeyFromBranch(branch),
+		Issue:  ticket.IssueFromBranch(branch),
+		Date:   now.Format(time.DateOnly),
+		Time:   now.Format(time.TimeOnly),
+		User:   user,
+		Repo:   repo,
+	}
+}
+
+// Find returns the template with the given name.
+func Find(templates []Template, name string) (Template, bool) {
+	name = strings.TrimSpace(name)
+	for _, t := range templates {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Template{}, false
+}
+
+// Render executes the template message with vars.
+func (t Template) Render(vars Vars) (string, error) {
+	tpl, err := template.New(t.Name).Option("missingkey=error").Parse(t.Message)
+	if err != nil {
+		return "", fmt.Errorf("parse template %q: %w", t.Name, err)
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, vars); err != nil {
+		return "", fmt.Errorf("render template %q: %w", t.Name, err)
+	}
+	msg := strings.TrimSpace(buf.String())
+	if msg == "" {
+		return "", fmt.Errorf("template %q rendered an empty message", t.Name)
+	}
+	return msg, nil
+}
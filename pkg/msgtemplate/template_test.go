@@ -0,0 +1,42 @@
+package msgtemplate
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	now := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	vars := NewVars("feature/ABC-9-sync", "dev", "fastgit", now)
+
+	msg, err := Template{Name: "sync", Message: "chore: sync {{.Ticket}} on {{.Branch}} ({{.Date}})\n"}.Render(vars)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if msg != "chore: sync ABC-9 on feature/ABC-9-sync (2026-03-04)" {
+		t.Fatalf("unexpected message %q", msg)
+	}
+
+	issue := NewVars("feat/1234-login", "dev", "fastgit", now)
+	msg, err = Template{Name: "fast", Message: "chore: {{.Files}} files (#{{.Issue}})"}.Render(issue)
+	if err != nil || msg != "chore: 0 files (#1234)" {
+		t.Fatalf("unexpected message %q, err %v", msg, err)
+	}
+
+	if _, err := (Template{Name: "bad", Message: "{{.Nope}}"}).Render(vars); err == nil {
+		t.Fatalf("expected unknown variable to fail")
+	}
+	if _, err := (Template{Name: "empty", Message: "  "}).Render(vars); err == nil {
+		t.Fatalf("expected empty message to fail")
+	}
+}
+
+func TestFind(t *testing.T) {
+	list := []Template{{Name: "release"}, {Name: "sync"}}
+	if tpl, ok := Find(list, " sync "); !ok || tpl.Name != "sync" {
+		t.Fatalf("expected to find sync, got %+v %v", tpl, ok)
+	}
+	if _, ok := Find(list, "missing"); ok {
+		t.Fatalf("expected missing template not found")
+	}
+}
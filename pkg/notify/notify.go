@@ -0,0 +1,207 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// DefaultTemplate renders the message when notify.template is empty.
+const DefaultTemplate = `{{.Repo}} {{.Version}} released
+
+{{.Changelog}}`
+
+// discordMaxLength is the content limit for a single Discord webhook message.
+const discordMaxLength = 2000
+
+// Config is the `notify` section of ~/.config/fastgit/config.yaml.
+type Config struct {
+	Enabled  bool      `yaml:"enabled"`
+	Template string    `yaml:"template"`
+	Webhooks []Webhook `yaml:"webhooks"`
+	Email    *Email    `yaml:"email"`
+}
+
+// Webhook is a chat integration target.
+type Webhook struct {
+	// Type is one of slack|discord|teams.
+	Type string `yaml:"type"`
+	URL  string `yaml:"url"`
+}
+
+// Email sends the release message over SMTP.
+type Email struct {
+	Host     string   `yaml:"host"`
+	Port     int      `yaml:"port"`
+	Username string   `yaml:"username"`
+	Password string   `yaml:"password"`
+	From     string   `yaml:"from"`
+	To       []string `yaml:"to"`
+	Subject  string   `yaml:"subject"`
+}
+
+// Release describes a published release; its fields are available to templates.
+type Release struct {
+	Repo      string
+	Version   string
+	Changelog string
+	Date      string
+}
+
+// Active reports whether any target is configured and notifications are on.
+func (c *Config) Active() bool {
+	if c == nil || !c.Enabled {
+		return false
+	}
+	return len(c.Webhooks) > 0 || (c.Email != nil && strings.TrimSpace(c.Email.Host) != "")
+}
+
+// Render executes the configured template for the release.
+func (c *Config) Render(rel Release) (string, error) {
+	tpl := DefaultTemplate
+	if c != nil && strings.TrimSpace(c.Template) != "" {
+		tpl = c.Template
+	}
+	if rel.Date == "" {
+		rel.Date = time.Now().Format(time.DateOnly)
+	}
+	return renderText("notify", tpl, rel)
+}
+
+// Send posts the rendered release message to every configured target and
+// returns the joined errors of targets that failed.
+func Send(ctx context.Context, cfgs []*Config, rel Release) error {
+	var errs []string
+	for _, cfg := range cfgs {
+		if !cfg.Active() {
+			continue
+		}
+
+		msg, err := cfg.Render(rel)
+		if err != nil {
+			return err
+		}
+
+		for _, hook := range cfg.Webhooks {
+			if err := postWebhook(ctx, hook, msg); err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", hook.Type, err))
+			}
+		}
+		if cfg.Email != nil && strings.TrimSpace(cfg.Email.Host) != "" {
+			if err := sendEmail(cfg.Email, rel, msg); err != nil {
+				errs = append(errs, fmt.Sprintf("email: %v", err))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("release notification failed: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// WebhookPayload builds the JSON body expected by each chat service.
+func WebhookPayload(kind, msg string) (map[string]string, error) {
+	switch strings.ToLower(strings.TrimSpace(kind)) {
+	case "slack", "teams":
+		return map[string]string{"text": msg}, nil
+	case "discord":
+		if r := []rune(msg); len(r) > discordMaxLength {
+			msg = string(r[:discordMaxLength-3]) + "..."
+		}
+		return map[string]string{"content": msg}, nil
+	default:
+		return nil, fmt.Errorf("unsupported webhook type %q (want slack|discord|teams)", kind)
+	}
+}
+
+func postWebhook(ctx context.Context, hook Webhook, msg string) error {
+	if strings.TrimSpace(hook.URL) == "" {
+		return fmt.Errorf("webhook url is empty")
+	}
+
+	payload, err := WebhookPayload(hook.Type, msg)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	rsp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(rsp.Body, 512))
+		return fmt.Errorf("status %d: %s", rsp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+func sendEmail(cfg *Email, rel Release, msg string) error {
+	if len(cfg.To) == 0 {
+		return fmt.Errorf("email.to is empty")
+	}
+
+	subjectTpl := cfg.Subject
+	if strings.TrimSpace(subjectTpl) == "" {
+		subjectTpl = "{{.Repo}} {{.Version}} released"
+	}
+	subject, err := renderText("subject", subjectTpl, rel)
+	if err != nil {
+		return err
+	}
+
+	port := cfg.Port
+	if port == 0 {
+		port = 587
+	}
+	from := cfg.From
+	if from == "" {
+		from = cfg.Username
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(cfg.To, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", strings.TrimSpace(subject))
+	buf.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n")
+	buf.WriteString(strings.ReplaceAll(msg, "\n", "\r\n"))
+
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+	return smtp.SendMail(cfg.Host+":"+strconv.Itoa(port), auth, from, cfg.To, buf.Bytes())
+}
+
+func renderText(name, tpl string, data any) (string, error) {
+	t, err := template.New(name).Parse(tpl)
+	if err != nil {
+		return "", fmt.Errorf("parse %s template: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("render %s template: %w", name, err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
@@ -0,0 +1,60 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRenderDefaultTemplate(t *testing.T) {
+	msg, err := (&Config{}).Render(Release{Repo: "fastgit", Version: "v1.2.0", Changelog: "- feat: x"})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if msg != "fastgit v1.2.0 released\n\n- feat: x" {
+		t.Fatalf("unexpected message %q", msg)
+	}
+}
+
+func TestWebhookPayload(t *testing.T) {
+	if p, _ := WebhookPayload("slack", "hi"); p["text"] != "hi" {
+		t.Fatalf("unexpected slack payload %v", p)
+	}
+	p, _ := WebhookPayload("discord", strings.Repeat("x", discordMaxLength+10))
+	if len(p["content"]) != discordMaxLength {
+		t.Fatalf("expected discord content truncated to %d, got %d", discordMaxLength, len(p["content"]))
+	}
+	if _, err := WebhookPayload("irc", "hi"); err == nil {
+		t.Fatalf("expected error for unsupported type")
+	}
+}
+
+func TestSendPostsToWebhooks(t *testing.T) {
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer srv.Close()
+
+	cfg := &Config{
+		Enabled:  true,
+		Template: "{{.Version}}: {{.Changelog}}",
+		Webhooks: []Webhook{{Type: "teams", URL: srv.URL}},
+	}
+	if err := Send(context.Background(), []*Config{cfg}, Release{Version: "v1.0.0", Changelog: "notes"}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if got["text"] != "v1.0.0: notes" {
+		t.Fatalf("unexpected payload %v", got)
+	}
+}
+
+func TestSendSkipsDisabled(t *testing.T) {
+	cfg := &Config{Webhooks: []Webhook{{Type: "slack", URL: "http://127.0.0.1:0"}}}
+	if err := Send(context.Background(), []*Config{cfg, nil}, Release{}); err != nil {
+		t.Fatalf("expected disabled config to be skipped, got %v", err)
+	}
+}
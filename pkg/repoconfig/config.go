@@ -32,6 +32,14 @@ type CommitSettings struct {
 	RequireScope      bool     `yaml:"require_scope"`
 	CandidatesDefault bool     `yaml:"candidates_default"`
 	Types             []string `yaml:"types"`
+	// PromptTemplate is a Go template file (relative to the repo root) that replaces the AI commit prompt.
+	PromptTemplate string `yaml:"prompt_template"`
+	// Style is conventional (default), gitmoji (`✨ feat: ...`) or plain.
+	Style string `yaml:"style"`
+	// Gitmoji overrides the type-to-emoji table used by the gitmoji style.
+	Gitmoji map[string]string `yaml:"gitmoji"`
+	// Exclude lists paths or globs (e.g. go.sum, *.pb.go, dist/) left out of the diff sent to AI.
+	Exclude []string `yaml:"exclude"`
 }
 
 // Bundle contains repository-local fastgit settings.
@@ -39,9 +47,14 @@ type Bundle struct {
 	RepoRoot string
 	Policy   Policy
 	Commit   CommitSettings
+	Modules  []Module
+
+	// localeSet / maxLengthSet record whether commit.yaml set the value or Load filled in the default.
+	localeSet    bool
+	maxLengthSet bool
 }
 
-// Load reads `.fastgit/policy.yaml` and `.fastgit/commit.yaml` when present.
+// Load reads `.fastgit/policy.yaml`, `.fastgit/commit.yaml` and `.fastgit/modules.yaml` when present.
 func Load(repoRoot string) (Bundle, error) {
 	repoRoot = strings.TrimSpace(repoRoot)
 	if repoRoot == "" {
@@ -51,9 +64,7 @@ func Load(repoRoot string) (Bundle, error) {
 	bundle := Bundle{
 		RepoRoot: repoRoot,
 		Commit: CommitSettings{
-			Locale:    "en",
-			MaxLength: 72,
-			Types:     []string{"feat", "fix", "chore", "docs", "refactor", "test", "build", "ci"},
+			Types: []string{"feat", "fix", "chore", "docs", "refactor", "test", "build", "ci"},
 		},
 	}
 
@@ -67,6 +78,15 @@ func Load(repoRoot string) (Bundle, error) {
 		return Bundle{}, err
 	}
 
+	var modules modulesFile
+	if err := readYAML(filepath.Join(repoRoot, repoConfigDir, "modules.yaml"), &modules); err != nil {
+		return Bundle{}, err
+	}
+	bundle.Modules = modules.Modules
+
+	bundle.Commit.Style = strings.ToLower(strings.TrimSpace(bundle.Commit.Style))
+	bundle.localeSet = strings.TrimSpace(bundle.Commit.Locale) != ""
+	bundle.maxLengthSet = bundle.Commit.MaxLength > 0
 	if bundle.Commit.MaxLength <= 0 {
 		bundle.Commit.MaxLength = 72
 	}
@@ -106,6 +126,25 @@ func InitScaffold(repoRoot string) ([]string, error) {
 	return created, nil
 }
 
+// CommitStyle returns the locale and max subject length for generated commit messages.
+// Values set in `.fastgit/commit.yaml` win over the given fallbacks (e.g. the user's
+// config.yaml), which win over the built-in defaults.
+func (b Bundle) CommitStyle(locale string, maxLength int) (string, int) {
+	if b.localeSet || strings.TrimSpace(locale) == "" {
+		locale = b.Commit.Locale
+	}
+	if b.maxLengthSet || maxLength <= 0 {
+		maxLength = b.Commit.MaxLength
+	}
+	if strings.TrimSpace(locale) == "" {
+		locale = "en"
+	}
+	if maxLength <= 0 {
+		maxLength = 72
+	}
+	return strings.TrimSpace(locale), maxLength
+}
+
 // ValidateBranch checks the current branch against policy.
 func (b Bundle) ValidateBranch(branch string) error {
 	branch = strings.TrimSpace(branch)
@@ -199,10 +238,14 @@ func (b Bundle) ValidateCommitMessage(message string) error {
 	if message == "" {
 		return fmt.Errorf("commit message is empty")
 	}
+	// gitmoji 前缀不计入长度，也不参与 conventional 格式校验
+	if b.Commit.Style == StyleGitmoji {
+		message = StripGitmoji(message)
+	}
 	if len(message) > b.Commit.MaxLength {
 		return fmt.Errorf("commit message exceeds max_length %d", b.Commit.MaxLength)
 	}
-	if !b.Policy.Commit.Conventional {
+	if !b.Policy.Commit.Conventional || b.Commit.Style == StylePlain {
 		return nil
 	}
 	if !regexp.MustCompile(`^[a-z]+(\([^)]+\))?!?:\s+.+`).MatchString(message) {
@@ -1,6 +1,8 @@
 package repoconfig
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -50,3 +52,23 @@ func TestValidateBranch(t *testing.T) {
 	require.NoError(t, bundle.ValidateBranch("feature/add-conflict"))
 	require.Error(t, bundle.ValidateBranch("main"))
 }
+
+func TestCommitStyle(t *testing.T) {
+	root := t.TempDir()
+	bundle, err := Load(root)
+	require.NoError(t, err)
+	locale, maxLength := bundle.CommitStyle("", 0)
+	require.Equal(t, "en", locale)
+	require.Equal(t, 72, maxLength)
+	locale, maxLength = bundle.CommitStyle("zh-CN", 60)
+	require.Equal(t, "zh-CN", locale)
+	require.Equal(t, 60, maxLength)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ".fastgit"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".fastgit", "commit.yaml"), []byte("max_length: 50\n"), 0o644))
+	bundle, err = Load(root)
+	require.NoError(t, err)
+	locale, maxLength = bundle.CommitStyle("zh-CN", 60)
+	require.Equal(t, "zh-CN", locale)
+	require.Equal(t, 50, maxLength)
+}
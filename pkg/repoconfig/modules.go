@@ -0,0 +1,157 @@
+package repoconfig
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Module maps repository paths to a named unit with its own scope, tags and changelog.
+type Module struct {
+	Name      string   `yaml:"name"`
+	Paths     []string `yaml:"paths"`
+	TagPrefix string   `yaml:"tag_prefix"`
+	Changelog string   `yaml:"changelog"`
+}
+
+type modulesFile struct {
+	Modules []Module `yaml:"modules"`
+}
+
+// Matches reports whether a repo-relative path belongs to the module.
+// Patterns support `*`, `?` and `**`; a pattern without wildcards matches the directory prefix.
+func (m Module) Matches(path string) bool {
+	path = strings.TrimPrefix(filepath.ToSlash(strings.TrimSpace(path)), "./")
+	for _, pattern := range m.Paths {
+		if matchModulePath(pattern, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// Pathspecs returns git pathspecs selecting the module's files.
+func (m Module) Pathspecs() []string {
+	specs := make([]string, 0, len(m.Paths))
+	for _, pattern := range m.Paths {
+		pattern = strings.TrimSuffix(strings.TrimPrefix(filepath.ToSlash(strings.TrimSpace(pattern)), "./"), "/")
+		if pattern == "" {
+			continue
+		}
+		if strings.ContainsAny(pattern, "*?[") {
+			specs = append(specs, ":(glob)"+pattern)
+		} else {
+			specs = append(specs, pattern)
+		}
+	}
+	return specs
+}
+
+// FindModule returns the module with the given name.
+func (b Bundle) FindModule(name string) (Module, bool) {
+	name = strings.TrimSpace(name)
+	for _, m := range b.Modules {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return Module{}, false
+}
+
+// ModuleFor returns the first module whose paths match path.
+func (b Bundle) ModuleFor(path string) (Module, bool) {
+	for _, m := range b.Modules {
+		if m.Matches(path) {
+			return m, true
+		}
+	}
+	return Module{}, false
+}
+
+// InferScope returns the module name when every file belongs to the same module.
+func (b Bundle) InferScope(files []string) string {
+	scope := ""
+	for _, file := range files {
+		if strings.TrimSpace(file) == "" {
+			continue
+		}
+		m, ok := b.ModuleFor(file)
+		if !ok {
+			return ""
+		}
+		if scope != "" && scope != m.Name {
+			return ""
+		}
+		scope = m.Name
+	}
+	return scope
+}
+
+var conventionalHeaderPattern = regexp.MustCompile(`^([a-z]+)(\([^)]*\))?(!?):\s*(.*)$`)
+
+// WithScope inserts scope into a conventional commit subject that has none; a leading gitmoji is kept.
+func WithScope(message, scope string) string {
+	scope = strings.TrimSpace(scope)
+	if scope == "" {
+		return message
+	}
+	subject, rest, _ := strings.Cut(message, "\n")
+	subject = strings.TrimSpace(subject)
+	header := StripGitmoji(subject)
+	emoji := strings.TrimSpace(strings.TrimSuffix(subject, header))
+	m := conventionalHeaderPattern.FindStringSubmatch(header)
+	if m == nil || m[2] != "" {
+		return message
+	}
+	subject = m[1] + "(" + scope + ")" + m[3] + ": " + m[4]
+	if emoji != "" {
+		subject = emoji + " " + subject
+	}
+	if rest != "" {
+		return subject + "\n" + rest
+	}
+	return subject
+}
+
+func matchModulePath(pattern, path string) bool {
+	pattern = strings.TrimPrefix(filepath.ToSlash(strings.TrimSpace(pattern)), "./")
+	if pattern == "" {
+		return false
+	}
+	if !strings.ContainsAny(pattern, "*?[") {
+		prefix := strings.TrimSuffix(pattern, "/")
+		return path == prefix || strings.HasPrefix(path, prefix+"/")
+	}
+	re, err := regexp.Compile(globToRegexp(pattern))
+	if err != nil {
+		return false
+	}
+	return re.MatchString(path)
+}
+
+func globToRegexp(pattern string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					i++
+					b.WriteString("(.*/)?")
+				} else {
+					b.WriteString(".*")
+				}
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
@@ -0,0 +1,68 @@
+package repoconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testModulesBundle() Bundle {
+	return Bundle{Modules: []Module{
+		{Name: "api", Paths: []string{"services/api"}, TagPrefix: "api/"},
+		{Name: "web", Paths: []string{"apps/web/**", "packages/ui-*/**"}},
+	}}
+}
+
+func TestModuleMatches(t *testing.T) {
+	bundle := testModulesBundle()
+
+	m, ok := bundle.ModuleFor("services/api/main.go")
+	require.True(t, ok)
+	require.Equal(t, "api", m.Name)
+
+	m, ok = bundle.ModuleFor("packages/ui-kit/button.tsx")
+	require.True(t, ok)
+	require.Equal(t, "web", m.Name)
+
+	_, ok = bundle.ModuleFor("services/apiv2/main.go")
+	require.False(t, ok)
+}
+
+func TestInferScope(t *testing.T) {
+	bundle := testModulesBundle()
+	require.Equal(t, "api", bundle.InferScope([]string{"services/api/a.go", "services/api/b/c.go"}))
+	require.Equal(t, "", bundle.InferScope([]string{"services/api/a.go", "apps/web/index.ts"}))
+	require.Equal(t, "", bundle.InferScope([]string{"services/api/a.go", "README.md"}))
+}
+
+func TestWithScope(t *testing.T) {
+	require.Equal(t, "feat(api): add route", WithScope("feat: add route", "api"))
+	require.Equal(t, "fix(api)!: drop v1\n\nbody", WithScope("fix!: drop v1\n\nbody", "api"))
+	require.Equal(t, "feat(core): keep", WithScope("feat(core): keep", "api"))
+	require.Equal(t, "update things", WithScope("update things", "api"))
+	require.Equal(t, "✨ feat(api): add route", WithScope("✨ feat: add route", "api"))
+}
+
+func TestModulePathspecs(t *testing.T) {
+	require.Equal(t, []string{"services/api", ":(glob)apps/web/**"}, Module{Paths: []string{"./services/api/", "apps/web/**"}}.Pathspecs())
+}
+
+func TestLoadModules(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ".fastgit"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".fastgit", "modules.yaml"), []byte(`modules:
+  - name: api
+    paths: ["services/api"]
+    tag_prefix: api/
+    changelog: services/api/CHANGELOG
+`), 0o644))
+
+	bundle, err := Load(root)
+	require.NoError(t, err)
+	m, ok := bundle.FindModule("api")
+	require.True(t, ok)
+	require.Equal(t, "api/", m.TagPrefix)
+	require.Equal(t, "services/api/CHANGELOG", m.Changelog)
+}
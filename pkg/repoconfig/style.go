@@ -0,0 +1,150 @@
+package repoconfig
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Commit message styles for `commit.style`.
+const (
+	StyleConventional = "conventional"
+	StyleGitmoji      = "gitmoji"
+	StylePlain        = "plain"
+)
+
+// DefaultGitmoji maps conventional commit types to their gitmoji; `commit.gitmoji` overrides entries.
+var DefaultGitmoji = map[string]string{
+	"feat":     "✨",
+	"fix":      "🐛",
+	"docs":     "📝",
+	"style":    "🎨",
+	"refactor": "♻️",
+	"perf":     "⚡️",
+	"test":     "✅",
+	"build":    "📦️",
+	"ci":       "👷",
+	"chore":    "🔧",
+	"revert":   "⏪️",
+}
+
+var gitmojiShortcodePattern = regexp.MustCompile(`^:[a-z0-9_+-]+:$`)
+
+// ValidStyle reports whether style is a supported `commit.style` value; empty means conventional.
+func ValidStyle(style string) bool {
+	switch strings.ToLower(strings.TrimSpace(style)) {
+	case "", StyleConventional, StyleGitmoji, StylePlain:
+		return true
+	}
+	return false
+}
+
+// WithMessageStyle fills the commit style and gitmoji table from the user's config.yaml.
+// `.fastgit/commit.yaml` wins for the style; gitmoji entries merge as defaults < config.yaml < repo.
+func (b Bundle) WithMessageStyle(style string, gitmoji map[string]string) Bundle {
+	if strings.TrimSpace(b.Commit.Style) == "" {
+		b.Commit.Style = style
+	}
+	b.Commit.Style = strings.ToLower(strings.TrimSpace(b.Commit.Style))
+	if b.Commit.Style == "" {
+		b.Commit.Style = StyleConventional
+	}
+
+	table := maps.Clone(DefaultGitmoji)
+	maps.Copy(table, gitmoji)
+	maps.Copy(table, b.Commit.Gitmoji)
+	b.Commit.Gitmoji = table
+	return b
+}
+
+// GitmojiGuide lists the type-to-gitmoji table for the AI prompt.
+func (b Bundle) GitmojiGuide() string {
+	types := slices.Sorted(maps.Keys(b.Commit.Gitmoji))
+	lines := make([]string, 0, len(types)+1)
+	lines = append(lines, "Start the subject with the gitmoji of its type:")
+	for _, typ := range types {
+		lines = append(lines, fmt.Sprintf("%s %s", b.Commit.Gitmoji[typ], typ))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// FormatMessage rewrites the subject line of a conventional message into the configured style:
+// gitmoji prepends the emoji of its type, plain drops the `type(scope):` header.
+func (b Bundle) FormatMessage(message string) string {
+	subject, rest, hasRest := strings.Cut(strings.TrimSpace(message), "\n")
+	subject = StripGitmoji(subject)
+
+	switch b.Commit.Style {
+	case StyleGitmoji:
+		if m := conventionalHeaderPattern.FindStringSubmatch(subject); m != nil {
+			if emoji := b.Commit.Gitmoji[m[1]]; emoji != "" {
+				subject = emoji + " " + subject
+			}
+		}
+	case StylePlain:
+		if m := conventionalHeaderPattern.FindStringSubmatch(subject); m != nil && m[4] != "" {
+			subject = upperFirst(m[4])
+		}
+	default:
+		return message
+	}
+
+	if hasRest {
+		return subject + "\n" + rest
+	}
+	return subject
+}
+
+// WithType replaces the conventional type of the subject with typ, keeping scope, `!` and any
+// gitmoji prefix; a non-conventional subject gets `typ: ` prepended.
+func WithType(message, typ string) string {
+	typ = strings.ToLower(strings.TrimSpace(typ))
+	if typ == "" {
+		return message
+	}
+	subject, rest, hasRest := strings.Cut(strings.TrimSpace(message), "\n")
+	header := StripGitmoji(subject)
+	emoji := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(subject), header))
+	if m := conventionalHeaderPattern.FindStringSubmatch(header); m != nil {
+		header = typ + m[2] + m[3] + ": " + m[4]
+	} else {
+		header = typ + ": " + header
+	}
+	if emoji != "" {
+		header = emoji + " " + header
+	}
+	if hasRest {
+		return header + "\n" + rest
+	}
+	return header
+}
+
+// StripGitmoji removes a leading emoji or `:shortcode:` from a commit subject.
+func StripGitmoji(subject string) string {
+	subject = strings.TrimSpace(subject)
+	head, tail, ok := strings.Cut(subject, " ")
+	if !ok || head == "" {
+		return subject
+	}
+	if gitmojiShortcodePattern.MatchString(head) || !strings.ContainsFunc(head, notEmojiRune) {
+		return strings.TrimSpace(tail)
+	}
+	return subject
+}
+
+// notEmojiRune 表情由符号、变体选择符与零宽连接符组成，其它字符（如中文标题）不能当作 gitmoji
+func notEmojiRune(r rune) bool {
+	return !unicode.In(r, unicode.So, unicode.Sk, unicode.Mn) && r != 0x200D && r != 0xFE0F
+}
+
+func upperFirst(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if size == 0 {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[size:]
+}
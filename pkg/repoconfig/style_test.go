@@ -0,0 +1,46 @@
+package repoconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatMessage(t *testing.T) {
+	gitmoji := Bundle{}.WithMessageStyle(StyleGitmoji, map[string]string{"chore": "🔨"})
+	require.Equal(t, "✨ feat(api): add route", gitmoji.FormatMessage("feat(api): add route"))
+	require.Equal(t, "🐛 fix: handle nil\n\nbody", gitmoji.FormatMessage(":bug: fix: handle nil\n\nbody"))
+	require.Equal(t, "🔨 chore: bump deps", gitmoji.FormatMessage("🔧 chore: bump deps"))
+	require.Equal(t, "update things", gitmoji.FormatMessage("update things"))
+
+	repo := Bundle{Commit: CommitSettings{Style: StyleConventional}}.WithMessageStyle(StyleGitmoji, nil)
+	require.Equal(t, "feat: add route", repo.FormatMessage("feat: add route"))
+
+	plain := Bundle{}.WithMessageStyle(StylePlain, nil)
+	require.Equal(t, "Add route", plain.FormatMessage("✨ feat(api): add route"))
+	require.Equal(t, "修复缓存", plain.FormatMessage("修复缓存"))
+}
+
+func TestWithType(t *testing.T) {
+	require.Equal(t, "fix(api)!: drop v1\n\nbody", WithType("feat(api)!: drop v1\n\nbody", "fix"))
+	require.Equal(t, "✨ chore: bump deps", WithType("✨ feat: bump deps", "Chore"))
+	require.Equal(t, "fix: handle nil", WithType("handle nil", "fix"))
+	require.Equal(t, "feat: add", WithType("feat: add", " "))
+}
+
+func TestStripGitmoji(t *testing.T) {
+	require.Equal(t, "feat: add", StripGitmoji("♻️ feat: add"))
+	require.Equal(t, "feat: add", StripGitmoji(":sparkles: feat: add"))
+	require.Equal(t, "修复 缓存", StripGitmoji("修复 缓存"))
+	require.Equal(t, "feat: add", StripGitmoji("feat: add"))
+}
+
+func TestValidateCommitMessageStyle(t *testing.T) {
+	bundle := Bundle{Commit: CommitSettings{MaxLength: 20, Style: StyleGitmoji}}
+	bundle.Policy.Commit.Conventional = true
+	require.NoError(t, bundle.ValidateCommitMessage("✨ feat: add command"))
+	require.Error(t, bundle.ValidateCommitMessage("✨ add command"))
+
+	bundle.Commit.Style = StylePlain
+	require.NoError(t, bundle.ValidateCommitMessage("Add command"))
+}
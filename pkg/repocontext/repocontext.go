@@ -0,0 +1,207 @@
+// Package repocontext collects repository metadata — recent commit subjects, the README
+// introduction and the detected language/framework — as extra commit prompt context.
+package repocontext
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pubgo/fastgit/pkg/gitshell"
+)
+
+// DefaultSubjects is the number of recent commit subjects included by default.
+const DefaultSubjects = 10
+
+// maxIntro caps the README paragraph so it does not crowd out the diff.
+const maxIntro = 400
+
+// Context is the metadata of one repository.
+type Context struct {
+	Subjects []string
+	Intro    string
+	Stack    []string
+}
+
+// Load reads the context of the repository at repoRoot; missing pieces are left empty.
+func Load(repoRoot string, subjects int) Context {
+	var c Context
+	if subjects > 0 {
+		out, err := gitshell.RunInDir(repoRoot, "log", "-n", strconv.Itoa(subjects), "--no-merges", "--format=%s")
+		if err == nil && out != "" {
+			c.Subjects = strings.Split(out, "\n")
+		}
+	}
+	c.Intro = readmeIntro(repoRoot)
+	c.Stack = detectStack(repoRoot)
+	return c
+}
+
+// Empty reports whether nothing was found.
+func (c Context) Empty() bool {
+	return len(c.Subjects) == 0 && c.Intro == "" && len(c.Stack) == 0
+}
+
+// Prompt renders the context as prompt text; it is empty when nothing was found.
+func (c Context) Prompt() string {
+	if c.Empty() {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("Repository context (match the style of the existing history):")
+	if len(c.Stack) > 0 {
+		b.WriteString("\nLanguage/framework: " + strings.Join(c.Stack, ", "))
+	}
+	if c.Intro != "" {
+		b.WriteString("\nProject description: " + c.Intro)
+	}
+	if len(c.Subjects) > 0 {
+		b.WriteString("\nRecent commit subjects:")
+		for _, s := range c.Subjects {
+			b.WriteString("\n- " + s)
+		}
+	}
+	return b.String()
+}
+
+// readmeIntro returns the first prose paragraph of the README, skipping headings, badges and HTML.
+func readmeIntro(repoRoot string) string {
+	var data []byte
+	for _, name := range []string{"README.md", "README", "README.rst", "readme.md", "README.txt"} {
+		if b, err := os.ReadFile(filepath.Join(repoRoot, name)); err == nil {
+			data = b
+			break
+		}
+	}
+
+	var para []string
+	inFence := false
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if line == "" {
+			if len(para) > 0 {
+				break
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "#") || strings.HasPrefix(line, "<") || strings.HasPrefix(line, "[![") ||
+			strings.HasPrefix(line, "![") || strings.Trim(line, "=-") == "" {
+			continue
+		}
+		para = append(para, line)
+	}
+
+	intro := strings.Join(para, " ")
+	if r := []rune(intro); len(r) > maxIntro {
+		intro = string(r[:maxIntro]) + "…"
+	}
+	return intro
+}
+
+// stackMarker maps a manifest file to the language it implies and the dependencies whose
+// presence names a framework.
+type stackMarker struct {
+	file       string
+	language   string
+	frameworks map[string]string
+}
+
+var stackMarkers = []stackMarker{
+	{file: "go.mod", language: "Go", frameworks: map[string]string{
+		"github.com/gin-gonic/gin": "Gin", "github.com/labstack/echo": "Echo", "github.com/gofiber/fiber": "Fiber",
+		"google.golang.org/grpc": "gRPC", "github.com/spf13/cobra": "Cobra", "gorm.io/gorm": "GORM",
+	}},
+	{file: "package.json", language: "JavaScript"},
+	{file: "Cargo.toml", language: "Rust", frameworks: map[string]string{
+		"tokio": "Tokio", "actix-web": "Actix", "axum": "Axum",
+	}},
+	{file: "pyproject.toml", language: "Python", frameworks: pythonFrameworks},
+	{file: "requirements.txt", language: "Python", frameworks: pythonFrameworks},
+	{file: "pom.xml", language: "Java", frameworks: map[string]string{"spring-boot": "Spring Boot"}},
+	{file: "build.gradle", language: "Java/Kotlin", frameworks: map[string]string{"org.springframework.boot": "Spring Boot"}},
+	{file: "Gemfile", language: "Ruby", frameworks: map[string]string{"rails": "Rails"}},
+	{file: "composer.json", language: "PHP", frameworks: map[string]string{"laravel/framework": "Laravel"}},
+}
+
+var pythonFrameworks = map[string]string{"django": "Django", "flask": "Flask", "fastapi": "FastAPI"}
+
+var jsFrameworks = map[string]string{
+	"react": "React", "vue": "Vue", "next": "Next.js", "svelte": "Svelte",
+	"@angular/core": "Angular", "express": "Express", "@nestjs/core": "NestJS",
+}
+
+// detectStack lists the languages and frameworks named by manifest files at the repository root.
+func detectStack(repoRoot string) []string {
+	var stack []string
+	seen := map[string]bool{}
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			stack = append(stack, name)
+		}
+	}
+
+	for _, m := range stackMarkers {
+		data, err := os.ReadFile(filepath.Join(repoRoot, m.file))
+		if err != nil {
+			continue
+		}
+		if m.file == "package.json" {
+			for _, name := range packageJSONStack(repoRoot, data) {
+				add(name)
+			}
+			continue
+		}
+		add(m.language)
+		content := string(data)
+		for _, dep := range sortedKeys(m.frameworks) {
+			if strings.Contains(content, dep) {
+				add(m.frameworks[dep])
+			}
+		}
+	}
+	return stack
+}
+
+func packageJSONStack(repoRoot string, data []byte) []string {
+	language := "JavaScript"
+	if _, err := os.Stat(filepath.Join(repoRoot, "tsconfig.json")); err == nil {
+		language = "TypeScript"
+	}
+	stack := []string{language}
+
+	var pkg struct {
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if json.Unmarshal(data, &pkg) != nil {
+		return stack
+	}
+	for _, dep := range sortedKeys(jsFrameworks) {
+		_, ok := pkg.Dependencies[dep]
+		_, dev := pkg.DevDependencies[dep]
+		if ok || dev {
+			stack = append(stack, jsFrameworks[dep])
+		}
+	}
+	return stack
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,57 @@
+package repocontext
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git := func(args ...string) {
+		t.Helper()
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	write("README.md", "# demo\n\n[![ci](badge.svg)](ci)\n\nDemo is a tiny\nHTTP service.\n\nMore text.\n")
+	write("go.mod", "module example.com/demo\n\nrequire (\n\tgithub.com/gin-gonic/gin v1.9.0\n\tgoogle.golang.org/grpc v1.60.0\n)\n")
+	write("package.json", `{"devDependencies": {"react": "^18.0.0"}}`)
+	write("tsconfig.json", "{}")
+	git("init", "-q")
+	git("config", "user.email", "dev@example.com")
+	git("config", "user.name", "dev")
+	git("add", "-A")
+	git("commit", "-q", "-m", "feat: first")
+	git("commit", "-q", "--allow-empty", "-m", "fix(api): second")
+
+	c := Load(dir, 5)
+	if strings.Join(c.Subjects, "|") != "fix(api): second|feat: first" {
+		t.Fatalf("unexpected subjects: %v", c.Subjects)
+	}
+	if c.Intro != "Demo is a tiny HTTP service." {
+		t.Fatalf("unexpected intro: %q", c.Intro)
+	}
+	if strings.Join(c.Stack, ",") != "Go,Gin,gRPC,TypeScript,React" {
+		t.Fatalf("unexpected stack: %v", c.Stack)
+	}
+	if p := c.Prompt(); !strings.Contains(p, "- fix(api): second") || !strings.Contains(p, "Language/framework: Go, Gin") {
+		t.Fatalf("unexpected prompt:\n%s", p)
+	}
+
+	if (Context{}).Prompt() != "" {
+		t.Fatal("expected empty prompt for empty context")
+	}
+}
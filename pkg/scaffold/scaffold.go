@@ -0,0 +1,253 @@
+package scaffold
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	getter "github.com/hashicorp/go-getter"
+)
+
+const templateSuffix = ".tmpl"
+
+//go:embed all:templates
+var builtinFS embed.FS
+
+// Config is the `new` section of ~/.config/fastgit/config.yaml.
+type Config struct {
+	// Author is written into LICENSE; empty falls back to git config user.name.
+	Author string `yaml:"author"`
+	// License is the default license id: mit, isc, bsd-3-clause or none.
+	License string `yaml:"license"`
+	// Templates adds or overrides templates by name.
+	Templates []Template `yaml:"templates"`
+}
+
+// Template describes where the files of a new repository come from.
+type Template struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	// Source is a local directory or any go-getter address, e.g.
+	// github.com/acme/templates//go or git::https://git.example.com/tpl.git?ref=v1.
+	// Empty means the builtin template with the same name.
+	Source string `yaml:"source"`
+}
+
+// Vars are the values available to `.tmpl` files.
+type Vars struct {
+	Name        string
+	Module      string
+	Description string
+	Author      string
+	Email       string
+	License     string
+	Year        int
+	Date        string
+}
+
+// NewVars returns Vars for a project called name with date fields set to now.
+func NewVars(name string) Vars {
+	now := time.Now()
+	return Vars{Name: name, Module: name, License: "mit", Year: now.Year(), Date: now.Format(time.DateOnly)}
+}
+
+// Builtin lists the templates shipped with fastgit.
+func Builtin() []Template {
+	entries, _ := fs.ReadDir(builtinFS, "templates")
+	out := make([]Template, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() {
+			out = append(out, Template{Name: e.Name(), Description: "builtin"})
+		}
+	}
+	return out
+}
+
+// Templates merges the builtin templates with the configured ones; configured names win.
+func Templates(cfgs []*Config) []Template {
+	byName := make(map[string]Template)
+	for _, tpl := range Builtin() {
+		byName[tpl.Name] = tpl
+	}
+	for _, cfg := range cfgs {
+		if cfg == nil {
+			continue
+		}
+		for _, tpl := range cfg.Templates {
+			if name := strings.TrimSpace(tpl.Name); name != "" {
+				tpl.Name = name
+				byName[name] = tpl
+			}
+		}
+	}
+
+	out := make([]Template, 0, len(byName))
+	for _, tpl := range byName {
+		out = append(out, tpl)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Lookup resolves name against the configured and builtin templates. A name that is
+// not known but looks like a path or URL is used as an ad-hoc source.
+func Lookup(cfgs []*Config, name string) (Template, error) {
+	name = strings.TrimSpace(name)
+	for _, tpl := range Templates(cfgs) {
+		if tpl.Name == name {
+			return tpl, nil
+		}
+	}
+	if strings.ContainsAny(name, "/:.") {
+		return Template{Name: path.Base(strings.TrimSuffix(name, "/")), Source: name}, nil
+	}
+	return Template{}, fmt.Errorf("unknown template %q, see fastgit new --list", name)
+}
+
+// Fetch copies the files of tpl into dst, which must not exist yet or be empty.
+func Fetch(ctx context.Context, tpl Template, dst string) error {
+	src := strings.TrimSpace(tpl.Source)
+	if src == "" {
+		sub, err := fs.Sub(builtinFS, path.Join("templates", tpl.Name))
+		if err != nil {
+			return err
+		}
+		if _, err := fs.Stat(sub, "."); err != nil {
+			return fmt.Errorf("builtin template %q not found", tpl.Name)
+		}
+		return copyFS(sub, dst)
+	}
+
+	if info, err := os.Stat(expandHome(src)); err == nil && info.IsDir() {
+		return copyFS(os.DirFS(expandHome(src)), dst)
+	}
+
+	pwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	c := &getter.Client{
+		Ctx:  ctx,
+		Src:  src,
+		Dst:  dst,
+		Pwd:  pwd,
+		Mode: getter.ClientModeDir,
+	}
+	if err := c.Get(); err != nil {
+		return fmt.Errorf("fetch template %s: %w", src, err)
+	}
+	// 远程模板带来的历史不属于新仓库
+	return os.RemoveAll(filepath.Join(dst, ".git"))
+}
+
+// Render expands every `.tmpl` file under dir with vars and drops the suffix.
+// Files named `gitignore` become `.gitignore` so templates can ship one without
+// it applying to the repository that hosts them.
+func Render(dir string, vars Vars) error {
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		target := p
+		if d.Name() == "gitignore" || d.Name() == "gitignore"+templateSuffix {
+			target = filepath.Join(filepath.Dir(p), "."+d.Name())
+		}
+		if strings.HasSuffix(target, templateSuffix) {
+			target = strings.TrimSuffix(target, templateSuffix)
+			if err := renderFile(p, vars); err != nil {
+				return err
+			}
+		}
+		if target == p {
+			return nil
+		}
+		return os.Rename(p, target)
+	})
+}
+
+func renderFile(file string, vars Vars) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	tpl, err := template.New(filepath.Base(file)).Option("missingkey=error").Parse(string(data))
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", file, err)
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, vars); err != nil {
+		return fmt.Errorf("render %s: %w", file, err)
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, buf.Bytes(), info.Mode().Perm())
+}
+
+// InitRepo runs git init in dir, commits everything with message and adds
+// remote as origin when it is not empty.
+func InitRepo(ctx context.Context, dir, message, remote string) error {
+	steps := [][]string{{"init", "-q", "-b", "main"}}
+	if message != "" {
+		steps = append(steps, []string{"add", "-A"}, []string{"commit", "-q", "-m", message})
+	}
+	if remote = strings.TrimSpace(remote); remote != "" {
+		steps = append(steps, []string{"remote", "add", "origin", remote})
+	}
+	for _, args := range steps {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			if msg := strings.TrimSpace(string(out)); msg != "" {
+				return fmt.Errorf("git %s: %s", args[0], msg)
+			}
+			return fmt.Errorf("git %s: %w", args[0], err)
+		}
+	}
+	return nil
+}
+
+func copyFS(src fs.FS, dst string) error {
+	return fs.WalkDir(src, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Name() == ".git" && d.IsDir() {
+			return fs.SkipDir
+		}
+		target := filepath.Join(dst, filepath.FromSlash(p))
+		if d.IsDir() {
+			return os.MkdirAll(target, 0o755)
+		}
+		data, err := fs.ReadFile(src, p)
+		if err != nil {
+			return err
+		}
+		mode := os.FileMode(0o644)
+		if info, err := d.Info(); err == nil && info.Mode().Perm()&0o111 != 0 {
+			mode = 0o755
+		}
+		return os.WriteFile(target, data, mode)
+	})
+}
+
+func expandHome(p string) string {
+	if rest, ok := strings.CutPrefix(p, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return p
+}
@@ -0,0 +1,88 @@
+package scaffold
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFetchRenderBuiltin(t *testing.T) {
+	dir := t.TempDir()
+	tpl, err := Lookup(nil, "go")
+	if err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	if err := Fetch(context.Background(), tpl, dir); err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+
+	vars := NewVars("demo")
+	vars.Module = "example.com/demo"
+	vars.Author = "Jane Doe"
+	if err := Render(dir, vars); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if _, err := WriteLicense(dir, vars); err != nil {
+		t.Fatalf("WriteLicense: %v", err)
+	}
+
+	gomod, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil || !strings.Contains(string(gomod), "module example.com/demo") {
+		t.Fatalf("go.mod = %q, %v", gomod, err)
+	}
+	for _, name := range []string{".gitignore", ".github/workflows/ci.yml", "README.md", "main.go", "LICENSE"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Fatalf("missing %s: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "main.go.tmpl")); !os.IsNotExist(err) {
+		t.Fatalf("template file not removed: %v", err)
+	}
+	license, _ := os.ReadFile(filepath.Join(dir, "LICENSE"))
+	if !strings.Contains(string(license), "Jane Doe") {
+		t.Fatalf("LICENSE missing author: %q", license)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	cfgs := []*Config{{Templates: []Template{{Name: "go", Source: "/srv/templates/go"}}}}
+	tpl, err := Lookup(cfgs, "go")
+	if err != nil || tpl.Source != "/srv/templates/go" {
+		t.Fatalf("configured template should override builtin: %+v, %v", tpl, err)
+	}
+	if tpl, err := Lookup(nil, "github.com/acme/tpl"); err != nil || tpl.Source != "github.com/acme/tpl" {
+		t.Fatalf("ad-hoc source: %+v, %v", tpl, err)
+	}
+	if _, err := Lookup(nil, "nope"); err == nil {
+		t.Fatal("expected unknown template error")
+	}
+}
+
+func TestInitRepo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("# x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "t")
+	t.Setenv("GIT_AUTHOR_EMAIL", "t@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "t")
+	t.Setenv("GIT_COMMITTER_EMAIL", "t@example.com")
+	if err := InitRepo(context.Background(), dir, "chore: initial commit", "git@example.com:acme/x.git"); err != nil {
+		t.Fatalf("InitRepo: %v", err)
+	}
+
+	out, err := exec.Command("git", "-C", dir, "log", "--format=%s").Output()
+	if err != nil || strings.TrimSpace(string(out)) != "chore: initial commit" {
+		t.Fatalf("log = %q, %v", out, err)
+	}
+	out, err = exec.Command("git", "-C", dir, "remote", "get-url", "origin").Output()
+	if err != nil || strings.TrimSpace(string(out)) != "git@example.com:acme/x.git" {
+		t.Fatalf("remote = %q, %v", out, err)
+	}
+}
@@ -0,0 +1,265 @@
+// Package secretscan finds credentials in unified diffs before they are committed or
+// sent to an AI provider: well-known token formats, private keys, and high-entropy
+// string literals assigned in code.
+package secretscan
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// DefaultEntropy is the Shannon entropy (bits per character) above which a quoted
+// literal of 20 or more characters is reported.
+const DefaultEntropy = 4.5
+
+// Rule is a named pattern. When the pattern has a capture group, the first group is
+// the secret; otherwise the whole match is.
+type Rule struct {
+	Name    string `yaml:"name"`
+	Pattern string `yaml:"pattern"`
+}
+
+// DefaultRules cover common cloud, VCS and AI provider tokens plus PEM private keys.
+var DefaultRules = []Rule{
+	{Name: "private-key", Pattern: `-----BEGIN[A-Z ]*PRIVATE KEY(?: BLOCK)?-----`},
+	{Name: "aws-access-key", Pattern: `\b((?:AKIA|ASIA)[0-9A-Z]{16})\b`},
+	{Name: "github-token", Pattern: `\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{50,})\b`},
+	{Name: "gitlab-token", Pattern: `\b(glpat-[A-Za-z0-9_-]{20,})\b`},
+	{Name: "slack-token", Pattern: `\b(xox[abprs]-[A-Za-z0-9-]{10,})\b`},
+	{Name: "google-api-key", Pattern: `\b(AIza[0-9A-Za-z_-]{35})\b`},
+	{Name: "openai-api-key", Pattern: `\b(sk-(?:proj-|ant-)?[A-Za-z0-9_-]{20,})\b`},
+	{Name: "stripe-key", Pattern: `\b((?:sk|rk)_live_[0-9A-Za-z]{24,})\b`},
+	{Name: "password-assignment", Pattern: `(?i)(?:password|passwd|secret|api[_-]?key|access[_-]?token)["']?\s*[:=]\s*["']([^"'\s]{8,})["']`},
+}
+
+// Options configure a Scanner.
+type Options struct {
+	// Rules are added to DefaultRules.
+	Rules []Rule
+	// Allow are regular expressions; a secret matching any of them is ignored.
+	Allow []string
+	// AllowPaths are file globs (or directory prefixes) whose changes are not scanned.
+	AllowPaths []string
+	// Entropy is the high-entropy threshold; zero means DefaultEntropy, negative disables it.
+	Entropy float64
+}
+
+// Finding is one suspected secret on an added line.
+type Finding struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Rule   string `json:"rule"`
+	Secret string `json:"-"`
+}
+
+// Masked returns the secret with everything but its first and last four characters hidden.
+func (f Finding) Masked() string {
+	return mask(f.Secret)
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s:%d %s %s", f.File, f.Line, f.Rule, f.Masked())
+}
+
+type compiledRule struct {
+	name string
+	re   *regexp.Regexp
+}
+
+// Scanner matches diff content against rules.
+type Scanner struct {
+	rules      []compiledRule
+	allow      []*regexp.Regexp
+	allowPaths []string
+	entropy    float64
+}
+
+var quotedLiteral = regexp.MustCompile(`["'\x60]([A-Za-z0-9+/=_\-]{20,})["'\x60]`)
+
+// New compiles the default and configured rules.
+func New(opts Options) (*Scanner, error) {
+	s := &Scanner{allowPaths: opts.AllowPaths, entropy: opts.Entropy}
+	if s.entropy == 0 {
+		s.entropy = DefaultEntropy
+	}
+	for _, r := range append(append([]Rule{}, DefaultRules...), opts.Rules...) {
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("secret rule %q: %w", r.Name, err)
+		}
+		s.rules = append(s.rules, compiledRule{name: r.Name, re: re})
+	}
+	for _, pattern := range opts.Allow {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("secret allow pattern %q: %w", pattern, err)
+		}
+		s.allow = append(s.allow, re)
+	}
+	return s, nil
+}
+
+// ScanDiff reports secrets on the added lines of a unified diff. Removed and context
+// lines are ignored: they are already in history.
+func (s *Scanner) ScanDiff(diff string) []Finding {
+	var (
+		findings []Finding
+		file     string
+		line     int
+	)
+	for _, text := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(text, "+++ "):
+			file = strings.TrimPrefix(strings.TrimPrefix(text, "+++ "), "b/")
+			continue
+		case strings.HasPrefix(text, "@@"):
+			line = hunkStart(text)
+			continue
+		case strings.HasPrefix(text, "-"), strings.HasPrefix(text, `\`):
+			continue
+		case !strings.HasPrefix(text, "+"):
+			line++
+			continue
+		}
+
+		if !s.pathAllowed(file) {
+			for _, secret := range s.lineSecrets(text[1:]) {
+				findings = append(findings, Finding{File: file, Line: line, Rule: secret.rule, Secret: secret.value})
+			}
+		}
+		line++
+	}
+	return findings
+}
+
+// Redact replaces every secret found anywhere in text, including context lines,
+// with a placeholder naming the rule. The body of a PEM private key is dropped up to
+// its END line.
+func (s *Scanner) Redact(text string) string {
+	lines := strings.Split(text, "\n")
+	inKey := false
+	for i, l := range lines {
+		if inKey {
+			if strings.Contains(l, "-----END") {
+				inKey = false
+			}
+			lines[i] = l[:min(len(l), 1)] + "[REDACTED:private-key]"
+			continue
+		}
+		for _, secret := range s.lineSecrets(l) {
+			l = strings.ReplaceAll(l, secret.value, "[REDACTED:"+secret.rule+"]")
+			inKey = inKey || secret.rule == "private-key"
+		}
+		lines[i] = l
+	}
+	return strings.Join(lines, "\n")
+}
+
+type lineSecret struct {
+	rule  string
+	value string
+}
+
+func (s *Scanner) lineSecrets(text string) []lineSecret {
+	var out []lineSecret
+	seen := map[string]bool{}
+	add := func(rule, value string) {
+		if value == "" || seen[value] || s.allowed(value) {
+			return
+		}
+		seen[value] = true
+		out = append(out, lineSecret{rule: rule, value: value})
+	}
+
+	for _, r := range s.rules {
+		for _, m := range r.re.FindAllStringSubmatch(text, -1) {
+			if len(m) > 1 && m[1] != "" {
+				add(r.name, m[1])
+			} else {
+				add(r.name, m[0])
+			}
+		}
+	}
+	if s.entropy > 0 {
+		for _, m := range quotedLiteral.FindAllStringSubmatch(text, -1) {
+			if mixed(m[1]) && Entropy(m[1]) >= s.entropy {
+				add("high-entropy", m[1])
+			}
+		}
+	}
+	return out
+}
+
+func (s *Scanner) allowed(secret string) bool {
+	for _, re := range s.allow {
+		if re.MatchString(secret) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Scanner) pathAllowed(file string) bool {
+	for _, pattern := range s.allowPaths {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if ok, _ := filepath.Match(pattern, file); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, filepath.Base(file)); ok {
+			return true
+		}
+		if strings.HasPrefix(file, strings.TrimSuffix(pattern, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Entropy returns the Shannon entropy of s in bits per character.
+func Entropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := map[rune]int{}
+	for _, r := range s {
+		counts[r]++
+	}
+	n := float64(len([]rune(s)))
+	var e float64
+	for _, c := range counts {
+		p := float64(c) / n
+		e -= p * math.Log2(p)
+	}
+	return e
+}
+
+// mixed reports whether a literal has both letters and digits, which keeps words,
+// paths and identifiers out of the entropy check.
+func mixed(s string) bool {
+	return strings.ContainsAny(s, "0123456789") &&
+		strings.IndexFunc(s, func(r rune) bool { return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') }) >= 0
+}
+
+// hunkStart returns the first new-file line number of a "@@ -a,b +c,d @@" header.
+func hunkStart(header string) int {
+	_, rest, ok := strings.Cut(header, "+")
+	if !ok {
+		return 0
+	}
+	var start int
+	_, _ = fmt.Sscanf(rest, "%d", &start)
+	return start
+}
+
+func mask(secret string) string {
+	if len(secret) <= 8 {
+		return strings.Repeat("*", len(secret))
+	}
+	return secret[:4] + strings.Repeat("*", len(secret)-8) + secret[len(secret)-4:]
+}
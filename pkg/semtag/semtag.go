@@ -0,0 +1,132 @@
+// Package semtag does the version math behind `fastgit tag`: parsing `<prefix>v*`
+// tags and computing the next release or prerelease version.
+package semtag
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	semver "github.com/hashicorp/go-version"
+)
+
+// Initial is the version suggested when a repository has no tags yet.
+const Initial = "v0.0.1"
+
+// List returns the `<prefix>v*` tags of the repository at dir (the working
+// directory when empty), newest first, with the prefix removed.
+func List(ctx context.Context, dir, prefix string) ([]*semver.Version, error) {
+	cmd := exec.CommandContext(ctx, "git", "-c", "versionsort.suffix=-", "for-each-ref",
+		"--sort=-v:refname", "--format=%(refname:lstrip=2)", "refs/tags/"+prefix+"v*")
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("list tags: %s: %w", strings.TrimSpace(stderr.String()), err)
+	}
+	versions, _ := Parse(stdout.String(), prefix)
+	return versions, nil
+}
+
+// Parse reads one tag per line, keeps those starting with prefix followed by "v"
+// and parses the rest as semver in input order. Tags that are not valid semver are
+// returned in invalid.
+func Parse(output, prefix string) (versions []*semver.Version, invalid []string) {
+	for _, tag := range strings.Split(strings.TrimSpace(output), "\n") {
+		tag = strings.Trim(strings.TrimSpace(tag), `"`)
+		if !strings.HasPrefix(tag, prefix) {
+			continue
+		}
+
+		name := strings.TrimPrefix(tag, prefix)
+		if !strings.HasPrefix(name, "v") {
+			continue
+		}
+
+		v, err := semver.NewSemver(name)
+		if err != nil {
+			invalid = append(invalid, tag)
+			continue
+		}
+		versions = append(versions, v)
+	}
+	return versions, invalid
+}
+
+// Max returns the highest version, or nil for no tags.
+func Max(tags []*semver.Version) *semver.Version {
+	var highest *semver.Version
+	for _, tag := range tags {
+		if highest == nil || tag.GreaterThan(highest) {
+			highest = tag
+		}
+	}
+	return highest
+}
+
+// NextRelease returns the next release: the core of the highest tag when it is a
+// prerelease, otherwise the highest tag with its patch bumped.
+func NextRelease(tags []*semver.Version) *semver.Version {
+	highest := Max(tags)
+	if highest == nil {
+		return semver.Must(semver.NewSemver(Initial))
+	}
+	if highest.Prerelease() != "" {
+		return highest.Core()
+	}
+	segments := highest.Core().Segments()
+	return core(segments[0], segments[1], segments[2]+1)
+}
+
+// NextCore returns the release the next prerelease builds towards: the highest tag
+// with its patch bumped, or its core when it already is a prerelease. Tags below
+// v0.0.1 are ignored.
+func NextCore(tags []*semver.Version) *semver.Version {
+	highest := semver.Must(semver.NewVersion(Initial))
+	for _, tag := range tags {
+		if tag.GreaterThan(highest) {
+			highest = tag
+		}
+	}
+
+	segments := highest.Segments()
+	patch := segments[2] + 1
+	if highest.Prerelease() != "" {
+		patch = segments[2]
+	}
+	return core(segments[0], segments[1], patch)
+}
+
+// NextPrerelease returns the next `<core>-<pre>.<n>` version: n is incremented when a
+// `pre` prerelease of NextCore (or later) exists, otherwise it starts at 1.
+func NextPrerelease(pre string, tags []*semver.Version) (*semver.Version, error) {
+	if len(tags) == 0 {
+		return semver.Must(semver.NewSemver(Initial)), nil
+	}
+
+	next := NextCore(tags)
+	var matching []*semver.Version
+	for _, tag := range tags {
+		if strings.Contains(tag.String(), pre) {
+			matching = append(matching, tag)
+		}
+	}
+	current := Max(matching)
+	if current == nil || !current.Core().GreaterThanOrEqual(next) {
+		return semver.NewSemver(fmt.Sprintf("v%s-%s.1", next.Core().String(), pre))
+	}
+
+	counter := strings.ReplaceAll(current.Prerelease(), pre+".", "")
+	n, err := strconv.Atoi(counter)
+	if err != nil {
+		return nil, fmt.Errorf("tag %s: prerelease %q has no numeric %s counter", current.Original(), current.Prerelease(), pre)
+	}
+	return semver.NewSemver(fmt.Sprintf("v%s-%s.%d", current.Core().String(), pre, n+1))
+}
+
+func core(major, minor, patch int) *semver.Version {
+	return semver.Must(semver.NewSemver(fmt.Sprintf("v%d.%d.%d", major, minor, patch)))
+}
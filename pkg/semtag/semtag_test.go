@@ -0,0 +1,78 @@
+package semtag
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+
+	semver "github.com/hashicorp/go-version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func versions(t *testing.T, tags ...string) []*semver.Version {
+	t.Helper()
+	var out []*semver.Version
+	for _, tag := range tags {
+		out = append(out, semver.Must(semver.NewSemver(tag)))
+	}
+	return out
+}
+
+func TestParse(t *testing.T) {
+	got, invalid := Parse("api/v1.2.0\nv0.9.0\napi/v1.3.0-beta.1\napi/vnext\napi/release", "api/")
+	require.Len(t, got, 2)
+	assert.Equal(t, "v1.2.0", got[0].Original())
+	assert.Equal(t, "v1.3.0-beta.1", got[1].Original())
+	assert.Equal(t, []string{"api/vnext"}, invalid)
+}
+
+func TestNextRelease(t *testing.T) {
+	assert.Equal(t, "0.0.1", NextRelease(nil).String())
+	assert.Equal(t, "1.2.4", NextRelease(versions(t, "v1.2.3", "v1.0.0")).String())
+	assert.Equal(t, "1.3.0", NextRelease(versions(t, "v1.2.3", "v1.3.0-alpha.2")).String())
+}
+
+func TestNextPrerelease(t *testing.T) {
+	next, err := NextPrerelease("alpha", versions(t, "v1.2.3"))
+	require.NoError(t, err)
+	assert.Equal(t, "v1.2.4-alpha.1", next.Original())
+
+	next, err = NextPrerelease("alpha", versions(t, "v1.2.3", "v1.2.4-alpha.1"))
+	require.NoError(t, err)
+	assert.Equal(t, "v1.2.4-alpha.2", next.Original())
+
+	next, err = NextPrerelease("beta", versions(t, "v1.2.3", "v1.2.4-alpha.3"))
+	require.NoError(t, err)
+	assert.Equal(t, "v1.2.4-beta.1", next.Original())
+
+	_, err = NextPrerelease("alpha", versions(t, "v1.2.4-alpha.x"))
+	assert.Error(t, err)
+}
+
+func TestList(t *testing.T) {
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"-c", "user.email=dev@example.com", "-c", "user.name=dev", "commit", "-q", "--allow-empty", "-m", "init"},
+		{"tag", "v1.0.0"},
+		{"tag", "v1.10.0"},
+		{"tag", "v1.2.0"},
+		{"tag", "api/v2.0.0"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	tags, err := List(context.Background(), dir, "")
+	require.NoError(t, err)
+	require.Len(t, tags, 3)
+	assert.Equal(t, "v1.10.0", tags[0].Original())
+
+	api, err := List(context.Background(), dir, "api/")
+	require.NoError(t, err)
+	require.Len(t, api, 1)
+	assert.Equal(t, "v2.0.0", api[0].Original())
+}
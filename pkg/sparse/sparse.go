@@ -0,0 +1,140 @@
+// Package sparse wraps git sparse-checkout (cone mode) and partial clone filters, and tells
+// other commands when a path they need lies outside the checked-out cone.
+package sparse
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/pubgo/fastgit/pkg/gitshell"
+)
+
+// DefaultFilter is the partial clone filter used when none is given: fetch commits and
+// trees, download file contents on demand.
+const DefaultFilter = "blob:none"
+
+// Info describes the sparse-checkout and partial clone state of a worktree.
+type Info struct {
+	// Sparse is set when core.sparseCheckout is enabled.
+	Sparse bool
+	// Cone is set when core.sparseCheckoutCone is enabled.
+	Cone bool
+	// Paths are the directories (cone mode) or patterns checked out.
+	Paths []string
+	// Filter is the partial clone filter of origin, e.g. blob:none; empty for a full clone.
+	Filter string
+}
+
+// Status reads the sparse-checkout and partial clone settings of the worktree at dir.
+func Status(ctx context.Context, dir string) Info {
+	info := Info{
+		Sparse: config(ctx, dir, "core.sparseCheckout") == "true",
+		Cone:   config(ctx, dir, "core.sparseCheckoutCone") == "true",
+		Filter: config(ctx, dir, "remote.origin.partialclonefilter"),
+	}
+	if info.Sparse {
+		if out, err := gitshell.Run(ctx, dir, "sparse-checkout", "list"); err == nil && out != "" {
+			info.Paths = strings.Split(out, "\n")
+		}
+	}
+	return info
+}
+
+// Init enables cone-mode sparse-checkout with paths (top-level files only when empty) and,
+// when filter is set, turns origin into a partial clone remote and refetches with the filter.
+func Init(ctx context.Context, dir string, paths []string, filter string) error {
+	if filter != "" {
+		for _, kv := range [][2]string{
+			{"remote.origin.promisor", "true"},
+			{"remote.origin.partialclonefilter", filter},
+		} {
+			if _, err := gitshell.Run(ctx, dir, "config", kv[0], kv[1]); err != nil {
+				return err
+			}
+		}
+		if _, err := gitshell.Run(ctx, dir, "fetch", "--filter="+filter, "origin"); err != nil {
+			return err
+		}
+	}
+	_, err := gitshell.Run(ctx, dir, append([]string{"sparse-checkout", "set", "--cone"}, paths...)...)
+	return err
+}
+
+// Add extends the sparse-checkout cone with paths.
+func Add(ctx context.Context, dir string, paths []string) error {
+	if len(paths) == 0 {
+		return fmt.Errorf("no paths to add")
+	}
+	if !Status(ctx, dir).Sparse {
+		return fmt.Errorf("sparse-checkout is not enabled, run `fastgit sparse init` first")
+	}
+	_, err := gitshell.Run(ctx, dir, append([]string{"sparse-checkout", "add"}, paths...)...)
+	return err
+}
+
+// Disable checks out the full tree again; the partial clone filter is kept.
+func Disable(ctx context.Context, dir string) error {
+	_, err := gitshell.Run(ctx, dir, "sparse-checkout", "disable")
+	return err
+}
+
+// Clone makes a partial clone of url into target without checking out any directory
+// besides the top-level files, then sets the cone to paths.
+func Clone(ctx context.Context, url, target, filter string, paths []string) (string, error) {
+	if filter == "" {
+		filter = DefaultFilter
+	}
+	args := []string{"clone", "--filter=" + filter, "--sparse", url}
+	if target != "" {
+		args = append(args, target)
+	} else {
+		target = strings.TrimSuffix(filepath.Base(strings.TrimRight(url, "/")), ".git")
+	}
+	if _, err := gitshell.Run(ctx, "", args...); err != nil {
+		return "", err
+	}
+	if len(paths) > 0 {
+		if _, err := gitshell.Run(ctx, target, append([]string{"sparse-checkout", "set", "--cone"}, paths...)...); err != nil {
+			return target, err
+		}
+	}
+	return target, nil
+}
+
+// Covers reports whether rel (slash separated, relative to the repository root) is checked
+// out. Outside cone mode every path is assumed to be present.
+func (i Info) Covers(rel string) bool {
+	if !i.Sparse || !i.Cone {
+		return true
+	}
+	rel = strings.Trim(filepath.ToSlash(rel), "/")
+	// 锥形模式总是检出根目录下的文件
+	if !strings.Contains(rel, "/") {
+		return true
+	}
+	for _, p := range i.Paths {
+		p = strings.Trim(p, "/")
+		if rel == p || strings.HasPrefix(rel, p+"/") || strings.HasPrefix(p, rel+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Hint returns guidance when rel is missing because of the sparse-checkout cone, or an
+// empty string when sparse-checkout does not explain it.
+func Hint(ctx context.Context, repoRoot, rel string) string {
+	info := Status(ctx, repoRoot)
+	if info.Covers(rel) {
+		return ""
+	}
+	dir := filepath.ToSlash(filepath.Dir(rel))
+	return fmt.Sprintf("%s is outside the sparse-checkout cone (%s); run `fastgit sparse add %s`", rel, strings.Join(info.Paths, ", "), dir)
+}
+
+func config(ctx context.Context, dir, key string) string {
+	out, _ := gitshell.Run(ctx, dir, "config", "--get", key)
+	return out
+}
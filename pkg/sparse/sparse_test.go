@@ -0,0 +1,78 @@
+package sparse
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pubgo/fastgit/pkg/gitshell"
+)
+
+func TestInitAddAndHint(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	ctx := context.Background()
+	dir := t.TempDir()
+	for _, name := range []string{"README.md", "api/main.go", "web/index.js", ".version/changelog/Unreleased.md"} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.email", "dev@example.com"},
+		{"config", "user.name", "dev"},
+		{"add", "-A"},
+		{"commit", "-q", "-m", "init"},
+	} {
+		if _, err := gitshell.Run(ctx, dir, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := Add(ctx, dir, []string{"web"}); err == nil {
+		t.Fatal("expected add to fail before init")
+	}
+	if Hint(ctx, dir, ".version/changelog/Unreleased.md") != "" {
+		t.Fatal("expected no hint without sparse-checkout")
+	}
+
+	if err := Init(ctx, dir, []string{"api"}, ""); err != nil {
+		t.Fatal(err)
+	}
+	info := Status(ctx, dir)
+	if !info.Sparse || !info.Cone || strings.Join(info.Paths, ",") != "api" {
+		t.Fatalf("unexpected status: %+v", info)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "web", "index.js")); !os.IsNotExist(err) {
+		t.Fatalf("web should not be checked out: %v", err)
+	}
+	if !info.Covers("README.md") || !info.Covers("api/main.go") || info.Covers("web/index.js") {
+		t.Fatalf("unexpected coverage for %+v", info)
+	}
+	hint := Hint(ctx, dir, ".version/changelog/Unreleased.md")
+	if !strings.Contains(hint, "fastgit sparse add .version/changelog") {
+		t.Fatalf("unexpected hint: %q", hint)
+	}
+
+	if err := Add(ctx, dir, []string{".version/changelog"}); err != nil {
+		t.Fatal(err)
+	}
+	if Hint(ctx, dir, ".version/changelog/Unreleased.md") != "" {
+		t.Fatal("expected no hint after adding the directory")
+	}
+	if err := Disable(ctx, dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "web", "index.js")); err != nil {
+		t.Fatalf("web should be checked out after disable: %v", err)
+	}
+}
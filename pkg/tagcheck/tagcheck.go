@@ -0,0 +1,63 @@
+// Package tagcheck runs the `tag.checks` commands that must pass before a tag or release is cut.
+package tagcheck
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Config is the `tag` section of ~/.config/fastgit/config.yaml.
+type Config struct {
+	// Checks run in order in the repository root; the first failure stops the tag.
+	Checks []Check `yaml:"checks"`
+}
+
+// Check is one verification command, run with `sh -c`.
+type Check struct {
+	Name    string `yaml:"name"`
+	Command string `yaml:"command"`
+}
+
+// Label returns the name shown in progress and errors, the command when no name is set.
+func (c Check) Label() string {
+	if name := strings.TrimSpace(c.Name); name != "" {
+		return name
+	}
+	return strings.TrimSpace(c.Command)
+}
+
+// Checks returns the configured checks of all configs in order, skipping empty commands.
+func Checks(cfgs []*Config) []Check {
+	var checks []Check
+	for _, cfg := range cfgs {
+		if cfg == nil {
+			continue
+		}
+		for _, c := range cfg.Checks {
+			if strings.TrimSpace(c.Command) != "" {
+				checks = append(checks, c)
+			}
+		}
+	}
+	return checks
+}
+
+// Run executes checks in dir, streaming their output to out, and returns the first failure.
+func Run(ctx context.Context, dir string, checks []Check, out io.Writer) error {
+	for i, c := range checks {
+		_, _ = fmt.Fprintf(out, "==> [%d/%d] %s\n", i+1, len(checks), c.Label())
+		cmd := exec.CommandContext(ctx, "sh", "-c", c.Command)
+		cmd.Dir = dir
+		cmd.Env = os.Environ()
+		cmd.Stdout = out
+		cmd.Stderr = out
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("tag check %q failed: %w", c.Label(), err)
+		}
+	}
+	return nil
+}
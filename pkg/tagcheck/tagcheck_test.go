@@ -0,0 +1,41 @@
+package tagcheck
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecks(t *testing.T) {
+	checks := Checks([]*Config{
+		nil,
+		{Checks: []Check{{Name: "test", Command: "go test ./..."}, {Name: "empty", Command: " "}}},
+		{Checks: []Check{{Command: "go vet ./..."}}},
+	})
+	require.Len(t, checks, 2)
+	require.Equal(t, "test", checks[0].Label())
+	require.Equal(t, "go vet ./...", checks[1].Label())
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	var out bytes.Buffer
+	err := Run(context.Background(), dir, []Check{
+		{Name: "write", Command: "echo ok > marker && echo wrote"},
+		{Name: "fail", Command: "echo broken >&2; exit 3"},
+		{Name: "never", Command: "touch never"},
+	}, &out)
+	require.ErrorContains(t, err, `tag check "fail" failed: exit status 3`)
+	require.Contains(t, out.String(), "==> [1/3] write\nwrote\n")
+	require.Contains(t, out.String(), "==> [2/3] fail\nbroken\n")
+	require.NotContains(t, out.String(), "never")
+
+	_, err = os.Stat(filepath.Join(dir, "marker"))
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Join(dir, "never"))
+	require.True(t, os.IsNotExist(err))
+}
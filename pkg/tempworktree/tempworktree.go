@@ -0,0 +1,40 @@
+// Package tempworktree checks commits out into throwaway detached worktrees, so commands
+// can build, test or cherry-pick without touching the main worktree, index and HEAD.
+package tempworktree
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/pubgo/fastgit/pkg/gitshell"
+)
+
+// Add checks commit out into a new detached worktree under os.TempDir, named after pattern
+// as in os.MkdirTemp; the caller must Remove it. A detached HEAD works even when the branch
+// is checked out in another worktree.
+func Add(ctx context.Context, repoRoot, pattern, commit string) (string, error) {
+	dir, err := os.MkdirTemp("", pattern)
+	if err != nil {
+		return "", err
+	}
+	// git worktree add 要求目标目录不存在或为空，这里直接复用空的临时目录
+	if _, err := gitshell.Run(ctx, repoRoot, "worktree", "add", "--detach", dir, commit); err != nil {
+		_ = os.RemoveAll(dir)
+		return "", err
+	}
+	return dir, nil
+}
+
+// Remove force-removes a worktree created by Add and prunes its administrative files.
+// It does not take the caller's context so that cleanup still runs after Ctrl+C.
+func Remove(repoRoot, dir string) error {
+	ctx := context.Background()
+	_, err := gitshell.Run(ctx, repoRoot, "worktree", "remove", "--force", dir)
+	_ = os.RemoveAll(dir)
+	_, _ = gitshell.Run(ctx, repoRoot, "worktree", "prune")
+	if err != nil {
+		return fmt.Errorf("remove worktree %s: %w", dir, err)
+	}
+	return nil
+}
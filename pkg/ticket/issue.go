@@ -0,0 +1,81 @@
+package ticket
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// DefaultIssueRef is the `commit.issue_ref` trailer used when the config does not set one.
+const DefaultIssueRef = "Refs: #{{.Issue}}"
+
+// issuePatterns match the explicit issue forms in a branch name: `issue-123`, `issues/123`, `gh-123`,
+// `#123` and `<prefix>/123-slug`. A bare trailing number such as `release/1.2` is not an issue.
+var issuePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)(?:^|[/_-])(?:issues?|gh)[-_/]#?(\d+)(?:[-_./]|$)`),
+	regexp.MustCompile(`(?:^|/)#(\d+)(?:[-_./]|$)`),
+	regexp.MustCompile(`/(\d+)-[A-Za-z]`),
+}
+
+var trailerPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9-]*: \S`)
+
+// IssueFromBranch extracts the issue number from branch, e.g. `1234` from `feat/1234-login`.
+func IssueFromBranch(branch string) string {
+	branch = strings.TrimSpace(branch)
+	for _, re := range issuePatterns {
+		if m := re.FindStringSubmatch(branch); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+// WithIssueRef appends the reference rendered from tmpl (`{{.Issue}}` is the number) to message.
+// A `Key: value` result is added as a trailer, anything else such as `(#{{.Issue}})` goes to the
+// end of the subject. The message is unchanged when it already mentions `#<issue>`.
+func WithIssueRef(message, issue, tmpl string) (string, error) {
+	message = strings.TrimSpace(message)
+	if issue == "" || strings.TrimSpace(tmpl) == "" || message == "" || strings.Contains(message, "#"+issue) {
+		return message, nil
+	}
+
+	tpl, err := template.New("issue_ref").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return message, fmt.Errorf("parse commit.issue_ref: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, struct{ Issue string }{issue}); err != nil {
+		return message, fmt.Errorf("render commit.issue_ref: %w", err)
+	}
+	ref := strings.TrimSpace(buf.String())
+	if ref == "" {
+		return message, nil
+	}
+
+	if !trailerPattern.MatchString(ref) {
+		subject, rest, ok := strings.Cut(message, "\n")
+		if ok {
+			return subject + " " + ref + "\n" + rest, nil
+		}
+		return subject + " " + ref, nil
+	}
+
+	// 已有尾注段落（如 Refs: ABC-1）时接在其后，不再空行
+	paragraphs := strings.Split(message, "\n\n")
+	last := paragraphs[len(paragraphs)-1]
+	if len(paragraphs) > 1 && isTrailerBlock(last) {
+		return message + "\n" + ref, nil
+	}
+	return message + "\n\n" + ref, nil
+}
+
+func isTrailerBlock(paragraph string) bool {
+	for _, line := range strings.Split(paragraph, "\n") {
+		if !trailerPattern.MatchString(strings.TrimSpace(line)) {
+			return false
+		}
+	}
+	return true
+}
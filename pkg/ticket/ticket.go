@@ -0,0 +1,277 @@
+package ticket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	ProviderJira   = "jira"
+	ProviderLinear = "linear"
+
+	defaultLinearAPI = "https://api.linear.app/graphql"
+	defaultLinearWeb = "https://linear.app"
+	lookupTimeout    = 5 * time.Second
+)
+
+var (
+	// defaultKeyPattern matches an upper-case key such as `ABC-123` delimited by non-alphanumerics.
+	defaultKeyPattern = regexp.MustCompile(`(?:^|[^A-Za-z0-9])([A-Z][A-Z0-9]+-\d+)(?:$|[^A-Za-z0-9])`)
+	// segmentKeyPattern matches a key in any case that makes up a whole branch path segment.
+	segmentKeyPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]+-\d+$`)
+)
+
+// Config is the `ticket` section of ~/.config/fastgit/config.yaml.
+type Config struct {
+	// Provider is one of jira|linear; empty disables the integration.
+	Provider string `yaml:"provider"`
+	// BaseURL is the Jira site (https://acme.atlassian.net) or the Linear
+	// workspace URL (https://linear.app/acme) used for browse links.
+	BaseURL string `yaml:"base_url"`
+	// APIURL overrides the Linear GraphQL endpoint.
+	APIURL string `yaml:"api_url"`
+	// Email enables Jira Cloud basic auth together with Token.
+	Email string `yaml:"email"`
+	Token string `yaml:"token"`
+	// Pattern overrides the regexp used to find the ticket key in a branch name.
+	Pattern string `yaml:"pattern"`
+}
+
+// Ticket is the subset of issue data fastgit injects into messages.
+type Ticket struct {
+	Key    string `json:"key"`
+	Title  string `json:"title"`
+	Status string `json:"status"`
+	URL    string `json:"url"`
+}
+
+// KeyFromBranch extracts a ticket key such as `ABC-123` from a branch name. Keys are matched
+// case-sensitively (`feat/ABC-123-login`); a lower-case key is only taken when it is a whole
+// path segment (`feat/abc-123`), so slugs like `fix/node-18-upgrade` or `feat/utf-8-decoder`
+// do not yield keys.
+func KeyFromBranch(branch string) string {
+	if m := defaultKeyPattern.FindStringSubmatch(branch); m != nil {
+		return m[1]
+	}
+	for _, segment := range strings.Split(branch, "/") {
+		if segmentKeyPattern.MatchString(segment) {
+			return strings.ToUpper(segment)
+		}
+	}
+	return ""
+}
+
+// Enabled reports whether a provider is configured.
+func (c *Config) Enabled() bool {
+	if c == nil {
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(c.Provider)) {
+	case ProviderJira, ProviderLinear:
+		return true
+	}
+	return false
+}
+
+// Key extracts the ticket key from branch using the configured pattern.
+func (c *Config) Key(branch string) string {
+	if c == nil || strings.TrimSpace(c.Pattern) == "" {
+		return KeyFromBranch(branch)
+	}
+	re, err := regexp.Compile(c.Pattern)
+	if err != nil {
+		return KeyFromBranch(branch)
+	}
+	if m := re.FindStringSubmatch(branch); len(m) > 1 {
+		return m[1]
+	} else if len(m) == 1 {
+		return m[0]
+	}
+	return ""
+}
+
+// BrowseURL returns the web link for key.
+func (c *Config) BrowseURL(key string) string {
+	base := strings.TrimRight(strings.TrimSpace(c.BaseURL), "/")
+	switch strings.ToLower(strings.TrimSpace(c.Provider)) {
+	case ProviderJira:
+		return base + "/browse/" + url.PathEscape(key)
+	case ProviderLinear:
+		if base == "" {
+			base = defaultLinearWeb
+		}
+		return base + "/issue/" + url.PathEscape(key)
+	}
+	return ""
+}
+
+// Fetch loads the ticket title and status from the configured provider.
+func (c *Config) Fetch(ctx context.Context, key string) (*Ticket, error) {
+	if !c.Enabled() {
+		return nil, fmt.Errorf("ticket provider is not configured")
+	}
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return nil, fmt.Errorf("ticket key is empty")
+	}
+
+	switch strings.ToLower(strings.TrimSpace(c.Provider)) {
+	case ProviderJira:
+		return c.fetchJira(ctx, key)
+	default:
+		return c.fetchLinear(ctx, key)
+	}
+}
+
+// Lookup finds the ticket referenced by branch using the first enabled config.
+// It returns nil without error when nothing is configured or the branch has no key.
+func Lookup(ctx context.Context, cfgs []*Config, branch string) (*Ticket, error) {
+	for _, cfg := range cfgs {
+		if !cfg.Enabled() {
+			continue
+		}
+		key := cfg.Key(branch)
+		if key == "" {
+			return nil, nil
+		}
+		ctx, cancel := context.WithTimeout(ctx, lookupTimeout)
+		defer cancel()
+		return cfg.Fetch(ctx, key)
+	}
+	return nil, nil
+}
+
+// Ref renders `KEY title` for prompts and changelog entries.
+func (t *Ticket) Ref() string {
+	if t == nil {
+		return ""
+	}
+	if t.Title == "" {
+		return t.Key
+	}
+	return t.Key + " " + t.Title
+}
+
+// WithRef appends a `Refs: KEY` trailer to message unless the key is already mentioned.
+func WithRef(message string, t *Ticket) string {
+	if t == nil || t.Key == "" || strings.TrimSpace(message) == "" || strings.Contains(message, t.Key) {
+		return message
+	}
+	return strings.TrimRight(message, "\n") + "\n\nRefs: " + t.Key
+}
+
+func (c *Config) fetchJira(ctx context.Context, key string) (*Ticket, error) {
+	base := strings.TrimRight(strings.TrimSpace(c.BaseURL), "/")
+	if base == "" {
+		return nil, fmt.Errorf("ticket.base_url is required for jira")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+"/rest/api/2/issue/"+url.PathEscape(key)+"?fields=summary,status", nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.Email != "" {
+		req.SetBasicAuth(c.Email, c.Token)
+	} else if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	var rsp struct {
+		Key    string `json:"key"`
+		Fields struct {
+			Summary string `json:"summary"`
+			Status  struct {
+				Name string `json:"name"`
+			} `json:"status"`
+		} `json:"fields"`
+	}
+	if err := doJSON(req, &rsp); err != nil {
+		return nil, fmt.Errorf("jira %s: %w", key, err)
+	}
+	if rsp.Key == "" {
+		rsp.Key = key
+	}
+	return &Ticket{Key: rsp.Key, Title: rsp.Fields.Summary, Status: rsp.Fields.Status.Name, URL: c.BrowseURL(rsp.Key)}, nil
+}
+
+const linearIssueQuery = `query($id: String!) { issue(id: $id) { identifier title url state { name } } }`
+
+func (c *Config) fetchLinear(ctx context.Context, key string) (*Ticket, error) {
+	endpoint := strings.TrimSpace(c.APIURL)
+	if endpoint == "" {
+		endpoint = defaultLinearAPI
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"query":     linearIssueQuery,
+		"variables": map[string]string{"id": key},
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.Token != "" {
+		req.Header.Set("Authorization", c.Token)
+	}
+
+	var rsp struct {
+		Data struct {
+			Issue *struct {
+				Identifier string `json:"identifier"`
+				Title      string `json:"title"`
+				URL        string `json:"url"`
+				State      struct {
+					Name string `json:"name"`
+				} `json:"state"`
+			} `json:"issue"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := doJSON(req, &rsp); err != nil {
+		return nil, fmt.Errorf("linear %s: %w", key, err)
+	}
+	if len(rsp.Errors) > 0 {
+		return nil, fmt.Errorf("linear %s: %s", key, rsp.Errors[0].Message)
+	}
+	issue := rsp.Data.Issue
+	if issue == nil {
+		return nil, fmt.Errorf("linear %s: issue not found", key)
+	}
+
+	t := &Ticket{Key: issue.Identifier, Title: issue.Title, Status: issue.State.Name, URL: issue.URL}
+	if t.Key == "" {
+		t.Key = key
+	}
+	if t.URL == "" {
+		t.URL = c.BrowseURL(t.Key)
+	}
+	return t, nil
+}
+
+func doJSON(req *http.Request, out any) error {
+	req.Header.Set("Accept", "application/json")
+	rsp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(rsp.Body, 512))
+		return fmt.Errorf("status %d: %s", rsp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return json.NewDecoder(rsp.Body).Decode(out)
+}
@@ -0,0 +1,149 @@
+package ticket
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestKey(t *testing.T) {
+	for branch, want := range map[string]string{
+		"feature/ABC-42-login": "ABC-42",
+		"ABC-42":               "ABC-42",
+		"alice/PROJ2-7_fix":    "PROJ2-7",
+		"feature/abc-42":       "ABC-42",
+		"fix/node-18-upgrade":  "",
+		"feat/utf-8-decoder":   "",
+		"chore/go-1-22":        "",
+		"feature/abc-42-login": "",
+		"feat/XABC-42x":        "",
+		"main":                 "",
+	} {
+		if got := KeyFromBranch(branch); got != want {
+			t.Fatalf("KeyFromBranch(%q) = %q, want %q", branch, got, want)
+		}
+	}
+	cfg := &Config{Provider: "jira", Pattern: `^[a-z]+/(\d+)-`}
+	if got := cfg.Key("fix/981-crash"); got != "981" {
+		t.Fatalf("unexpected key from custom pattern %q", got)
+	}
+	if got := (*Config)(nil).Key("main"); got != "" {
+		t.Fatalf("expected no key, got %q", got)
+	}
+}
+
+func TestFetchJira(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/api/2/issue/ABC-1" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if user, pass, ok := r.BasicAuth(); !ok || user != "me@x.io" || pass != "tok" {
+			t.Errorf("expected basic auth, got %q %q %v", user, pass, ok)
+		}
+		_, _ = w.Write([]byte(`{"key":"ABC-1","fields":{"summary":"Fix login","status":{"name":"In Progress"}}}`))
+	}))
+	defer srv.Close()
+
+	cfg := &Config{Provider: "jira", BaseURL: srv.URL, Email: "me@x.io", Token: "tok"}
+	tk, err := Lookup(context.Background(), []*Config{nil, cfg}, "feature/ABC-1-login")
+	if err != nil {
+		t.Fatalf("lookup: %v", err)
+	}
+	want := Ticket{Key: "ABC-1", Title: "Fix login", Status: "In Progress", URL: srv.URL + "/browse/ABC-1"}
+	if *tk != want {
+		t.Fatalf("unexpected ticket %+v", *tk)
+	}
+}
+
+func TestFetchLinear(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Variables map[string]string `json:"variables"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.Variables["id"] != "ENG-7" || r.Header.Get("Authorization") != "lin_key" {
+			t.Errorf("unexpected request %v %q", req.Variables, r.Header.Get("Authorization"))
+		}
+		_, _ = w.Write([]byte(`{"data":{"issue":{"identifier":"ENG-7","title":"Add SSO","url":"https://linear.app/acme/issue/ENG-7","state":{"name":"Todo"}}}}`))
+	}))
+	defer srv.Close()
+
+	cfg := &Config{Provider: "linear", APIURL: srv.URL, Token: "lin_key"}
+	tk, err := cfg.Fetch(context.Background(), "ENG-7")
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	if tk.Title != "Add SSO" || tk.Status != "Todo" || tk.Ref() != "ENG-7 Add SSO" {
+		t.Fatalf("unexpected ticket %+v", *tk)
+	}
+}
+
+func TestLookupWithoutConfigOrKey(t *testing.T) {
+	if tk, err := Lookup(context.Background(), nil, "feature/ABC-1"); tk != nil || err != nil {
+		t.Fatalf("expected nil lookup without config, got %v %v", tk, err)
+	}
+	cfg := &Config{Provider: "jira", BaseURL: "http://127.0.0.1:0"}
+	if tk, err := Lookup(context.Background(), []*Config{cfg}, "main"); tk != nil || err != nil {
+		t.Fatalf("expected nil lookup without key, got %v %v", tk, err)
+	}
+}
+
+func TestWithRef(t *testing.T) {
+	tk := &Ticket{Key: "ABC-1"}
+	if got := WithRef("feat: login", tk); got != "feat: login\n\nRefs: ABC-1" {
+		t.Fatalf("unexpected message %q", got)
+	}
+	if got := WithRef("feat: login (ABC-1)", tk); got != "feat: login (ABC-1)" {
+		t.Fatalf("expected existing ref kept, got %q", got)
+	}
+	if got := WithRef("feat: login", nil); got != "feat: login" {
+		t.Fatalf("expected unchanged message, got %q", got)
+	}
+}
+
+func TestIssueFromBranch(t *testing.T) {
+	cases := map[string]string{
+		"feat/1234-something": "1234",
+		"fix/#77":             "77",
+		"issue-9":             "9",
+		"fix/issue-9-crash":   "9",
+		"issues/88":           "88",
+		"GH-12_login":         "12",
+		"1234/impl":           "",
+		"feat/1234":           "",
+		"feature/ABC-42-x":    "",
+		"release/v1.2":        "",
+		"release/1.2":         "",
+		"hotfix/2024-10":      "",
+		"deps/bump-2":         "",
+		"main":                "",
+	}
+	for branch, want := range cases {
+		if got := IssueFromBranch(branch); got != want {
+			t.Errorf("IssueFromBranch(%q) = %q, want %q", branch, got, want)
+		}
+	}
+}
+
+func TestWithIssueRef(t *testing.T) {
+	cases := []struct {
+		message, tmpl, want string
+	}{
+		{"feat: add login", DefaultIssueRef, "feat: add login\n\nRefs: #12"},
+		{"feat: add login\n\nbody\n\nRefs: ABC-1", "Closes: #{{.Issue}}", "feat: add login\n\nbody\n\nRefs: ABC-1\nCloses: #12"},
+		{"feat: add login\n\nbody", "(#{{.Issue}})", "feat: add login (#12)\n\nbody"},
+		{"fix: crash (#12)", DefaultIssueRef, "fix: crash (#12)"},
+		{"fix: crash", "", "fix: crash"},
+	}
+	for _, c := range cases {
+		got, err := WithIssueRef(c.message, "12", c.tmpl)
+		if err != nil || got != c.want {
+			t.Errorf("WithIssueRef(%q, %q) = %q, %v; want %q", c.message, c.tmpl, got, err, c.want)
+		}
+	}
+	if _, err := WithIssueRef("fix: crash", "12", "{{.Nope}}"); err == nil {
+		t.Error("expected error for unknown template field")
+	}
+}
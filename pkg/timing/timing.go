@@ -0,0 +1,164 @@
+package timing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Well-known phases; callers may use any other name.
+const (
+	PhaseGit   = "git"
+	PhaseShell = "shell"
+	PhaseLLM   = "llm"
+	PhaseUI    = "ui wait"
+)
+
+// maxLabel keeps long shell command lines readable in the report.
+const maxLabel = 72
+
+// Span is one timed operation.
+type Span struct {
+	Phase    string
+	Label    string
+	Duration time.Duration
+}
+
+// Recorder collects spans for the global `--timings` flag; it is safe for concurrent use.
+type Recorder struct {
+	mu    sync.Mutex
+	start time.Time
+	spans []Span
+}
+
+type ctxKey struct{}
+
+// New starts a recorder whose total wall time is measured from now.
+func New() *Recorder {
+	return &Recorder{start: time.Now()}
+}
+
+// NewContext attaches r to ctx.
+func NewContext(ctx context.Context, r *Recorder) context.Context {
+	return context.WithValue(ctx, ctxKey{}, r)
+}
+
+// FromContext returns the recorder in ctx, or nil when timings are disabled.
+func FromContext(ctx context.Context) *Recorder {
+	if ctx == nil {
+		return nil
+	}
+	r, _ := ctx.Value(ctxKey{}).(*Recorder)
+	return r
+}
+
+// Track starts a span and returns the function that ends it. It is a no-op
+// when ctx carries no recorder, so call sites can use it unconditionally:
+//
+//	defer timing.Track(ctx, timing.PhaseGit, "git status")()
+func Track(ctx context.Context, phase, label string) func() {
+	r := FromContext(ctx)
+	if r == nil {
+		return func() {}
+	}
+	start := time.Now()
+	return func() { r.Add(phase, label, time.Since(start)) }
+}
+
+// Add records a finished span.
+func (r *Recorder) Add(phase, label string, d time.Duration) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, Span{Phase: phase, Label: label, Duration: d})
+}
+
+// Spans returns a copy of the recorded spans in completion order.
+func (r *Recorder) Spans() []Span {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Span(nil), r.spans...)
+}
+
+// Report writes per-phase totals followed by every span, slowest first.
+// Time not covered by any span is shown as "other" (fastgit's own work).
+func (r *Recorder) Report(w io.Writer) {
+	if r == nil {
+		return
+	}
+	total := time.Since(r.start)
+	spans := r.Spans()
+
+	type phaseTotal struct {
+		name  string
+		count int
+		dur   time.Duration
+	}
+	byPhase := map[string]*phaseTotal{}
+	var covered time.Duration
+	for _, s := range spans {
+		p, ok := byPhase[s.Phase]
+		if !ok {
+			p = &phaseTotal{name: s.Phase}
+			byPhase[s.Phase] = p
+		}
+		p.count++
+		p.dur += s.Duration
+		covered += s.Duration
+	}
+	phases := make([]*phaseTotal, 0, len(byPhase))
+	for _, p := range byPhase {
+		phases = append(phases, p)
+	}
+	sort.Slice(phases, func(i, j int) bool { return phases[i].dur > phases[j].dur })
+
+	_, _ = fmt.Fprintf(w, "\ntimings (total %s)\n", round(total))
+	for _, p := range phases {
+		_, _ = fmt.Fprintf(w, "  %-8s %10s %5.1f%%  (%d calls)\n", p.name, round(p.dur), percent(p.dur, total), p.count)
+	}
+	// span 可能并发重叠，other 只在未被覆盖时展示
+	if other := total - covered; other > 0 {
+		_, _ = fmt.Fprintf(w, "  %-8s %10s %5.1f%%\n", "other", round(other), percent(other, total))
+	}
+
+	if len(spans) == 0 {
+		return
+	}
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].Duration > spans[j].Duration })
+	_, _ = fmt.Fprintln(w, "  slowest:")
+	for i, s := range spans {
+		if i == 10 {
+			_, _ = fmt.Fprintf(w, "    ... %d more\n", len(spans)-i)
+			break
+		}
+		_, _ = fmt.Fprintf(w, "    %10s  %-8s %s\n", round(s.Duration), s.Phase, truncate(s.Label))
+	}
+}
+
+func round(d time.Duration) time.Duration {
+	if d < time.Second {
+		return d.Round(100 * time.Microsecond)
+	}
+	return d.Round(time.Millisecond)
+}
+
+func percent(d, total time.Duration) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return float64(d) * 100 / float64(total)
+}
+
+func truncate(label string) string {
+	label = strings.Join(strings.Fields(label), " ")
+	if len(label) > maxLabel {
+		return label[:maxLabel-3] + "..."
+	}
+	return label
+}
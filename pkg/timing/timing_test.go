@@ -0,0 +1,41 @@
+package timing
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTrackWithoutRecorderIsNoop(t *testing.T) {
+	Track(context.Background(), PhaseGit, "git status")()
+	if FromContext(context.Background()) != nil {
+		t.Fatalf("expected no recorder")
+	}
+}
+
+func TestReportGroupsByPhase(t *testing.T) {
+	rec := New()
+	ctx := NewContext(context.Background(), rec)
+
+	Track(ctx, PhaseGit, "git status")()
+	rec.Add(PhaseGit, "git diff --cached", 30*time.Millisecond)
+	rec.Add(PhaseLLM, "openai", 2*time.Second)
+	rec.Add(PhaseUI, "edit commit message", time.Second)
+
+	if got := len(rec.Spans()); got != 4 {
+		t.Fatalf("expected 4 spans, got %d", got)
+	}
+
+	var out strings.Builder
+	rec.Report(&out)
+	report := out.String()
+	for _, want := range []string{"timings (total", "llm", "(2 calls)", "ui wait", "slowest:", "openai"} {
+		if !strings.Contains(report, want) {
+			t.Fatalf("report missing %q:\n%s", want, report)
+		}
+	}
+	if strings.Index(report, "llm") > strings.Index(report, "ui wait") {
+		t.Fatalf("expected phases sorted by duration:\n%s", report)
+	}
+}
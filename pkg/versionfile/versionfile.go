@@ -0,0 +1,258 @@
+package versionfile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	semver "github.com/hashicorp/go-version"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultPath is the version file used when `.fastgit/version.yaml` is absent.
+const DefaultPath = ".version/VERSION"
+
+// Supported file formats.
+const (
+	FormatPlain = "plain"
+	FormatJSON  = "json"
+	FormatYAML  = "yaml"
+	FormatRegex = "regex"
+)
+
+// File is one place the project version is stored.
+type File struct {
+	// Path is relative to the repo root unless absolute.
+	Path string `yaml:"path"`
+	// Format is plain (whole file), json / yaml (top-level Key), or regex (Pattern).
+	Format string `yaml:"format"`
+	// Key is the field name for json / yaml files, default "version".
+	Key string `yaml:"key"`
+	// Pattern is a regexp whose first capture group is the version, for the regex format.
+	Pattern string `yaml:"pattern"`
+	// TrimV writes the version without its leading "v" (e.g. package.json).
+	TrimV bool `yaml:"trim_v"`
+}
+
+// Config is `.fastgit/version.yaml`. The first file is the source of truth.
+type Config struct {
+	Files []File `yaml:"files"`
+}
+
+// Value is the version found in one file.
+type Value struct {
+	File    File
+	Version string
+	Err     error
+}
+
+// LoadConfig reads `.fastgit/version.yaml`; a missing file yields the single default `.version/VERSION`.
+func LoadConfig(repoRoot string) (Config, error) {
+	var cfg Config
+	data, err := os.ReadFile(filepath.Join(repoRoot, ".fastgit", "version.yaml"))
+	if err != nil && !os.IsNotExist(err) {
+		return cfg, err
+	}
+	if err == nil {
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return cfg, fmt.Errorf("parse .fastgit/version.yaml: %w", err)
+		}
+	}
+	if len(cfg.Files) == 0 {
+		cfg.Files = []File{{Path: DefaultPath}}
+	}
+	for i := range cfg.Files {
+		if err := cfg.Files[i].normalize(repoRoot); err != nil {
+			return cfg, err
+		}
+	}
+	return cfg, nil
+}
+
+// MustLoadConfig is LoadConfig that falls back to the default file on error.
+func MustLoadConfig(repoRoot string) Config {
+	cfg, err := LoadConfig(repoRoot)
+	if err != nil {
+		cfg = Config{Files: []File{{Path: DefaultPath}}}
+		_ = cfg.Files[0].normalize(repoRoot)
+	}
+	return cfg
+}
+
+// Primary returns the source-of-truth file.
+func (c Config) Primary() File {
+	return c.Files[0]
+}
+
+// Current reads the version from the primary file.
+func (c Config) Current() (string, error) {
+	return c.Primary().Read()
+}
+
+// Show reads every configured file, so callers can spot files that drifted apart.
+func (c Config) Show() []Value {
+	values := make([]Value, 0, len(c.Files))
+	for _, f := range c.Files {
+		v, err := f.Read()
+		values = append(values, Value{File: f, Version: v, Err: err})
+	}
+	return values
+}
+
+// Write stores version in every configured file and returns the updated paths.
+// Plain files are created when missing; structured files must already exist.
+func (c Config) Write(version string) ([]string, error) {
+	if err := Validate(version); err != nil {
+		return nil, err
+	}
+	updated := make([]string, 0, len(c.Files))
+	for _, f := range c.Files {
+		if err := f.Write(version); err != nil {
+			return updated, err
+		}
+		updated = append(updated, f.Path)
+	}
+	return updated, nil
+}
+
+// Read returns the version stored in the file.
+func (f File) Read() (string, error) {
+	data, err := os.ReadFile(f.Path)
+	if err != nil {
+		return "", err
+	}
+	if f.plain() {
+		return strings.TrimSpace(string(data)), nil
+	}
+	m := f.regexp().FindSubmatch(data)
+	if m == nil {
+		return "", fmt.Errorf("%s: no version found (%s)", f.Path, f.describe())
+	}
+	return strings.TrimSpace(string(m[1])), nil
+}
+
+// Write replaces the version in the file, keeping the rest of its content untouched.
+func (f File) Write(version string) error {
+	version = strings.TrimSpace(version)
+	if f.TrimV {
+		version = strings.TrimPrefix(version, "v")
+	}
+
+	if f.plain() {
+		if err := os.MkdirAll(filepath.Dir(f.Path), 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile(f.Path, []byte(version+"\n"), 0o644)
+	}
+
+	data, err := os.ReadFile(f.Path)
+	if err != nil {
+		return err
+	}
+	re := f.regexp()
+	loc := re.FindSubmatchIndex(data)
+	if loc == nil {
+		return fmt.Errorf("%s: no version found (%s)", f.Path, f.describe())
+	}
+	out := append(append(append([]byte{}, data[:loc[2]]...), version...), data[loc[3]:]...)
+	return os.WriteFile(f.Path, out, 0o644)
+}
+
+func (f *File) normalize(repoRoot string) error {
+	f.Path = strings.TrimSpace(f.Path)
+	if f.Path == "" {
+		return errors.New("version file path is empty")
+	}
+	if !filepath.IsAbs(f.Path) {
+		f.Path = filepath.Join(repoRoot, f.Path)
+	}
+	f.Format = strings.ToLower(strings.TrimSpace(f.Format))
+	if f.Format == "" {
+		f.Format = FormatPlain
+	}
+	if strings.TrimSpace(f.Key) == "" {
+		f.Key = "version"
+	}
+
+	switch f.Format {
+	case FormatPlain, FormatJSON, FormatYAML:
+	case FormatRegex:
+		re, err := regexp.Compile(f.Pattern)
+		if err != nil {
+			return fmt.Errorf("%s: invalid pattern: %w", f.Path, err)
+		}
+		if re.NumSubexp() < 1 {
+			return fmt.Errorf("%s: pattern needs a capture group for the version", f.Path)
+		}
+	default:
+		return fmt.Errorf("%s: unsupported format %q (plain|json|yaml|regex)", f.Path, f.Format)
+	}
+	return nil
+}
+
+func (f File) plain() bool {
+	return f.Format == "" || f.Format == FormatPlain
+}
+
+// regexp 只替换版本号所在的子串，保留文件其余格式与注释
+func (f File) regexp() *regexp.Regexp {
+	key := regexp.QuoteMeta(f.Key)
+	switch f.Format {
+	case FormatJSON:
+		return regexp.MustCompile(`"` + key + `"\s*:\s*"([^"]*)"`)
+	case FormatYAML:
+		return regexp.MustCompile(`(?m)^` + key + `:[ \t]*["']?([^"'\s#]+)`)
+	default:
+		return regexp.MustCompile(f.Pattern)
+	}
+}
+
+func (f File) describe() string {
+	if f.Format == FormatRegex {
+		return "pattern " + f.Pattern
+	}
+	return f.Format + " key " + f.Key
+}
+
+// Validate checks that version is a semver string.
+func Validate(version string) error {
+	version = strings.TrimSpace(version)
+	if version == "" {
+		return errors.New("version cannot be empty")
+	}
+	if _, err := semver.NewVersion(version); err != nil {
+		return fmt.Errorf("invalid version %q: %w", version, err)
+	}
+	return nil
+}
+
+// Bump increments the major, minor or patch segment of current and returns a v-prefixed version.
+func Bump(current, level string) (string, error) {
+	v, err := semver.NewVersion(strings.TrimSpace(current))
+	if err != nil {
+		return "", fmt.Errorf("parse current version %q: %w", current, err)
+	}
+	segments := v.Segments()
+	if len(segments) < 3 {
+		return "", fmt.Errorf("invalid version: %s", current)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "patch":
+		segments[2]++
+	case "minor":
+		segments[1]++
+		segments[2] = 0
+	case "major":
+		segments[0]++
+		segments[1] = 0
+		segments[2] = 0
+	default:
+		return "", fmt.Errorf("unsupported bump level: %s", level)
+	}
+
+	return fmt.Sprintf("v%d.%d.%d", segments[0], segments[1], segments[2]), nil
+}
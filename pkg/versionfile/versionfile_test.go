@@ -0,0 +1,96 @@
+package versionfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfigWriteKeepsStructuredFiles(t *testing.T) {
+	dir := t.TempDir()
+	mustWrite(t, filepath.Join(dir, "package.json"), "{\n  \"name\": \"demo\",\n  \"version\": \"0.1.0\"\n}\n")
+	mustWrite(t, filepath.Join(dir, "chart.yaml"), "name: demo\nversion: v0.1.0 # keep\n")
+	mustWrite(t, filepath.Join(dir, "version.go"), "package main\n\nconst Version = \"v0.1.0\"\n")
+	mustWrite(t, filepath.Join(dir, ".fastgit", "version.yaml"), `files:
+  - path: .version/VERSION
+  - path: package.json
+    format: json
+    trim_v: true
+  - path: chart.yaml
+    format: yaml
+  - path: version.go
+    format: regex
+    pattern: 'Version = "([^"]+)"'
+`)
+
+	cfg, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	updated, err := cfg.Write("v1.2.3")
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if len(updated) != 4 {
+		t.Fatalf("updated = %v", updated)
+	}
+
+	want := map[string]string{
+		".version/VERSION": "v1.2.3\n",
+		"package.json":     "{\n  \"name\": \"demo\",\n  \"version\": \"1.2.3\"\n}\n",
+		"chart.yaml":       "name: demo\nversion: v1.2.3 # keep\n",
+		"version.go":       "package main\n\nconst Version = \"v1.2.3\"\n",
+	}
+	for name, content := range want {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("read %s: %v", name, err)
+		}
+		if string(data) != content {
+			t.Fatalf("%s = %q, want %q", name, data, content)
+		}
+	}
+
+	for _, v := range cfg.Show() {
+		if v.Err != nil || (v.Version != "v1.2.3" && v.Version != "1.2.3") {
+			t.Fatalf("Show %s = %q, %v", v.File.Path, v.Version, v.Err)
+		}
+	}
+}
+
+func TestLoadConfigDefault(t *testing.T) {
+	dir := t.TempDir()
+	cfg, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if got := cfg.Primary().Path; got != filepath.Join(dir, DefaultPath) {
+		t.Fatalf("Primary = %s", got)
+	}
+	if _, err := cfg.Write("not-a-version"); err == nil {
+		t.Fatal("expected invalid version error")
+	}
+}
+
+func TestBump(t *testing.T) {
+	cases := map[string]string{"patch": "v1.2.4", "minor": "v1.3.0", "major": "v2.0.0"}
+	for level, want := range cases {
+		got, err := Bump("v1.2.3", level)
+		if err != nil || got != want {
+			t.Fatalf("Bump(%s) = %q, %v; want %q", level, got, err, want)
+		}
+	}
+	if _, err := Bump("v1.2.3", "huge"); err == nil {
+		t.Fatal("expected unsupported level error")
+	}
+}
+
+func mustWrite(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
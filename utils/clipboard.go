@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"os"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+)
+
+// CopyToClipboard 把 text 写入系统剪贴板，返回使用的方式：本地优先系统剪贴板（pbcopy/xclip/wl-copy 等），
+// SSH 会话或没有剪贴板工具时通过终端的 OSC52 转义序列写入本地终端的剪贴板
+func CopyToClipboard(text string) (string, error) {
+	if os.Getenv("SSH_TTY") == "" && !clipboard.Unsupported {
+		if err := clipboard.WriteAll(text); err == nil {
+			return "clipboard", nil
+		}
+	}
+
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return "", err
+	}
+	defer tty.Close()
+
+	seq := osc52.New(text)
+	switch {
+	case os.Getenv("TMUX") != "":
+		seq = seq.Tmux()
+	case strings.HasPrefix(os.Getenv("TERM"), "screen"):
+		seq = seq.Screen()
+	}
+	if _, err := seq.WriteTo(tty); err != nil {
+		return "", err
+	}
+	return "osc52", nil
+}
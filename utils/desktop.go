@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// DesktopNotify 发送桌面通知：Linux 使用 notify-send，macOS 使用 osascript，Windows 使用 PowerShell 气泡提示
+// 没有可用的通知程序（如 SSH 会话、CI）时返回错误，调用方只需记录日志
+func DesktopNotify(ctx context.Context, title, body string) error {
+	name, args := desktopNotifyCommand(runtime.GOOS, title, body)
+	if name == "" {
+		return fmt.Errorf("desktop notifications are not supported on %s", runtime.GOOS)
+	}
+	if _, err := exec.LookPath(name); err != nil {
+		return fmt.Errorf("desktop notification: %w", err)
+	}
+	if out, err := exec.CommandContext(ctx, name, args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("desktop notification: %s: %w", strings.TrimSpace(string(out)), err)
+	}
+	return nil
+}
+
+func desktopNotifyCommand(goos, title, body string) (string, []string) {
+	switch goos {
+	case "linux", "freebsd", "openbsd", "netbsd":
+		return "notify-send", []string{"--app-name=fastgit", title, body}
+	case "darwin":
+		// AppleScript 字符串字面量与 Go 的转义规则兼容
+		return "osascript", []string{"-e", fmt.Sprintf("display notification %s with title %s", strconv.Quote(body), strconv.Quote(title))}
+	case "windows":
+		script := "Add-Type -AssemblyName System.Windows.Forms;" +
+			"$n = New-Object System.Windows.Forms.NotifyIcon;" +
+			"$n.Icon = [System.Drawing.SystemIcons]::Information;" +
+			"$n.Visible = $true;" +
+			fmt.Sprintf("$n.ShowBalloonTip(10000, %s, %s, 'Info');", psQuote(title), psQuote(body)) +
+			"Start-Sleep -Seconds 1"
+		return "powershell", []string{"-NoProfile", "-NonInteractive", "-Command", script}
+	}
+	return "", nil
+}
+
+// psQuote 转为 PowerShell 单引号字符串，单引号写两次
+func psQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDesktopNotifyCommand(t *testing.T) {
+	name, args := desktopNotifyCommand("linux", "fastgit", "feat: add x")
+	assert.Equal(t, "notify-send", name)
+	assert.Equal(t, []string{"--app-name=fastgit", "fastgit", "feat: add x"}, args)
+
+	name, args = desktopNotifyCommand("darwin", "fastgit", `fix: "quoted"`)
+	assert.Equal(t, "osascript", name)
+	assert.Equal(t, []string{"-e", `display notification "fix: \"quoted\"" with title "fastgit"`}, args)
+
+	name, args = desktopNotifyCommand("windows", "fastgit", "it's done")
+	assert.Equal(t, "powershell", name)
+	assert.Contains(t, args[len(args)-1], `ShowBalloonTip(10000, 'fastgit', 'it''s done', 'Info')`)
+
+	name, _ = desktopNotifyCommand("plan9", "fastgit", "x")
+	assert.Empty(t, name)
+}
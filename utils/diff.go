@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"context"
+	"io"
+
+	"github.com/pubgo/fastgit/pkg/gitdiff"
+)
+
+// DiffLimits 控制流式读取 diff 时的截断策略
+type DiffLimits = gitdiff.Limits
+
+// DefaultDiffLimits 默认限制，足够覆盖常规提交又不会让超大 diff 撑爆内存
+var DefaultDiffLimits = gitdiff.DefaultLimits
+
+// DiffFileStat 单个文件在 diff 中的统计信息
+type DiffFileStat = gitdiff.FileStat
+
+// StreamStagedDiff 以流的方式读取暂存区 diff，边读边统计并截断，避免一次性加载整个 diff
+func StreamStagedDiff(ctx context.Context, limits DiffLimits, excludeFiles ...string) (*GetStagedDiffRsp, error) {
+	return gitdiff.Stream(ctx, "", "", limits, excludeFiles...)
+}
+
+// ReadDiffStream 按 "diff --git" 分段读取 unified diff，按 limits 截断或摘要每个文件
+func ReadDiffStream(r io.Reader, limits DiffLimits) (*GetStagedDiffRsp, error) {
+	return gitdiff.Read(r, limits)
+}
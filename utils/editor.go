@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/pubgo/dix/v2"
+	"github.com/pubgo/dix/v2/dixcontext"
+	"mvdan.cc/sh/v3/shell"
+)
+
+// editorCandidates 是未配置编辑器时依次探测的命令，都会等待编辑器关闭后再返回
+var editorCandidates = []string{"zed -w", "code -w", "subl -w", "vim", "nano", "vi"}
+
+// EditorConfig 是 config.yaml 顶层的 editor，如 "code -w"
+type EditorConfig string
+
+type editorParams struct {
+	Editor []*EditorConfig
+}
+
+// configEditor 返回各层配置中的 editor，后出现的非空配置生效；ctx 中没有容器时为空
+func configEditor(ctx context.Context) string {
+	di := dixcontext.GetOrNil(ctx)
+	if di == nil {
+		return ""
+	}
+	var editor string
+	for _, cfg := range dix.Inject(di, editorParams{}).Editor {
+		if cfg != nil && strings.TrimSpace(string(*cfg)) != "" {
+			editor = strings.TrimSpace(string(*cfg))
+		}
+	}
+	return editor
+}
+
+// Editor 返回编辑器命令行（可带参数，如 "code -w"）：依次取 config.yaml 的 editor、$GIT_EDITOR、git 的 core.editor、
+// $VISUAL、$EDITOR，都为空时使用 editorCandidates 中第一个已安装的命令
+func Editor(ctx context.Context) string {
+	if e := configEditor(ctx); e != "" {
+		return e
+	}
+	if e := strings.TrimSpace(os.Getenv("GIT_EDITOR")); e != "" {
+		return e
+	}
+	if e := gitConfigValue("core.editor"); e != "" {
+		return e
+	}
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if e := strings.TrimSpace(os.Getenv(env)); e != "" {
+			return e
+		}
+	}
+	for _, candidate := range editorCandidates {
+		name, _, _ := strings.Cut(candidate, " ")
+		if _, err := exec.LookPath(name); err == nil {
+			return candidate
+		}
+	}
+	return "vi"
+}
+
+// EditorArgs 按 shell 规则拆分编辑器命令行（支持引号与带空格的路径）并追加 files
+func EditorArgs(editor string, files ...string) []string {
+	fields, err := shell.Fields(editor, nil)
+	if err != nil || len(fields) == 0 {
+		fields = []string{editor}
+	}
+	return append(fields, files...)
+}
+
+// EditorCommand 返回用 Editor() 打开 files 的命令，标准输入输出接到当前终端
+func EditorCommand(ctx context.Context, files ...string) *exec.Cmd {
+	args := EditorArgs(Editor(ctx), files...)
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd
+}
+
+// EditFiles 用 Editor() 打开 files 并等待编辑器退出
+func EditFiles(ctx context.Context, files ...string) error {
+	if err := EditorCommand(ctx, files...).Run(); err != nil {
+		return fmt.Errorf("editor %q: %w", Editor(ctx), err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEditorArgs(t *testing.T) {
+	t.Run("editor with args", func(t *testing.T) {
+		require.Equal(t, []string{"code", "-w", "a.txt"}, EditorArgs("code -w", "a.txt"))
+	})
+
+	t.Run("editor without args", func(t *testing.T) {
+		require.Equal(t, []string{"vim", "a.txt"}, EditorArgs("vim", "a.txt"))
+	})
+
+	t.Run("quoted path", func(t *testing.T) {
+		require.Equal(t, []string{"/Applications/My Editor/bin/edit", "--wait", "a.txt", "b.txt"},
+			EditorArgs(`"/Applications/My Editor/bin/edit" --wait`, "a.txt", "b.txt"))
+	})
+}
+
+func TestEditorCoreEditor(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv("FASTGIT_EXEC_LOG", "0")
+	t.Setenv("VISUAL", "nano")
+	for _, args := range [][]string{{"init", "-q"}, {"config", "core.editor", "hx"}} {
+		out, err := gitRun(args...)
+		require.NoError(t, err, out)
+	}
+
+	t.Setenv("GIT_EDITOR", "")
+	require.Equal(t, "hx", Editor(context.Background()))
+
+	t.Setenv("GIT_EDITOR", "code -w")
+	require.Equal(t, "code -w", Editor(context.Background()))
+}
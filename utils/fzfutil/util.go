@@ -53,3 +53,40 @@ func SelectWithFzf(ctx context.Context, input io.Reader) (string, error) {
 
 	return contextName, nil
 }
+
+// Pick 用 fzf 选择一行并原样返回（不去掉前缀），query 为初始搜索词；保持输入顺序，适合按时间倒序的历史记录
+func Pick(ctx context.Context, input io.Reader, prompt, query string) (string, error) {
+	return PickPreview(ctx, input, prompt, query, "")
+}
+
+// PickPreview 同 Pick，preview 非空时作为 fzf 的 --preview 命令，{1} 代表选中行的第一列
+func PickPreview(ctx context.Context, input io.Reader, prompt, query, preview string) (string, error) {
+	if !isFzfAvailable() {
+		return "", fmt.Errorf("fzf not available")
+	}
+
+	args := []string{
+		"--height", "40%",
+		"--reverse",
+		"--border",
+		"--no-sort",
+		"--prompt", prompt,
+		"--query", query,
+	}
+	if preview != "" {
+		args = append(args, "--ansi", "--preview", preview, "--preview-window", "right,60%")
+	}
+	cmd := exec.CommandContext(ctx, "fzf", args...)
+	cmd.Stdin = input
+	cmd.Stderr = os.Stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	selected := strings.TrimRight(string(output), "\n")
+	if selected == "" {
+		return "", fmt.Errorf("nothing selected")
+	}
+	return selected, nil
+}
@@ -1,31 +1,93 @@
-package main
+package genaiclient
 
 import (
 	"context"
 	"fmt"
-	"log"
+	"os"
+	"strings"
+	"sync"
 
 	"google.golang.org/genai"
+
+	"github.com/pubgo/fastgit/utils"
 )
 
-func main() {
-	ctx := context.Background()
-	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  "YOUR_API_KEY",
-		Backend: genai.BackendGeminiAPI,
+const DefaultModel = "gemini-2.5-flash"
+
+// Config 对应配置文件中的 genai 段
+type Config struct {
+	ApiKey  string `yaml:"api_key"`
+	Model   string `yaml:"model"`
+	BaseURL string `yaml:"base_url"`
+	// Proxy 访问 Gemini API 的代理，见 utils.NewHTTPClient
+	Proxy string `yaml:"proxy"`
+}
+
+// Client 包装 Gemini API 客户端，首次调用时才建立连接
+type Client struct {
+	Cfg *Config
+
+	once   sync.Once
+	client *genai.Client
+	err    error
+}
+
+// Result 是一次生成的结果
+type Result struct {
+	Text  string
+	Model string
+	Usage *genai.GenerateContentResponseUsageMetadata
+}
+
+func New(cfg *Config) *Client {
+	c := Config{}
+	if cfg != nil {
+		c = *cfg
+	}
+	c.ApiKey = strings.TrimSpace(c.ApiKey)
+	if c.ApiKey == "" {
+		c.ApiKey = strings.TrimSpace(os.Getenv("GEMINI_API_KEY"))
+	}
+	if c.ApiKey == "" {
+		c.ApiKey = strings.TrimSpace(os.Getenv("GOOGLE_API_KEY"))
+	}
+	if strings.TrimSpace(c.Model) == "" {
+		c.Model = DefaultModel
+	}
+	return &Client{Cfg: &c}
+}
+
+func (c *Client) Available() bool {
+	return c != nil && c.Cfg != nil && c.Cfg.ApiKey != ""
+}
+
+// Generate 以 system 作为系统指令生成文本；model 为空时使用配置中的模型
+func (c *Client) Generate(ctx context.Context, model, system, user string) (Result, error) {
+	if !c.Available() {
+		return Result{}, fmt.Errorf("gemini: missing API key (genai.api_key or GEMINI_API_KEY)")
+	}
+	c.once.Do(func() {
+		c.client, c.err = genai.NewClient(ctx, &genai.ClientConfig{
+			APIKey:      c.Cfg.ApiKey,
+			Backend:     genai.BackendGeminiAPI,
+			HTTPOptions: genai.HTTPOptions{BaseURL: strings.TrimSpace(c.Cfg.BaseURL)},
+			HTTPClient:  utils.NewHTTPClient(c.Cfg.Proxy),
+		})
 	})
-	if err != nil {
-		log.Fatal(err)
+	if c.err != nil {
+		return Result{}, fmt.Errorf("gemini client: %w", c.err)
 	}
 
-	result, err := client.Models.GenerateContent(
-		ctx,
-		"gemini-2.5-flash",
-		genai.Text("Explain how AI works in a few words"),
-		nil,
-	)
+	if strings.TrimSpace(model) == "" {
+		model = c.Cfg.Model
+	}
+	var genCfg *genai.GenerateContentConfig
+	if strings.TrimSpace(system) != "" {
+		genCfg = &genai.GenerateContentConfig{SystemInstruction: genai.NewContentFromText(system, genai.RoleUser)}
+	}
+	resp, err := c.client.Models.GenerateContent(ctx, model, genai.Text(user), genCfg)
 	if err != nil {
-		log.Fatal(err)
+		return Result{}, fmt.Errorf("gemini completion: %w", err)
 	}
-	fmt.Println(result.Text())
+	return Result{Text: resp.Text(), Model: model, Usage: resp.UsageMetadata}, nil
 }
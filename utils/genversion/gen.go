@@ -8,13 +8,14 @@ import (
 
 	"github.com/dave/jennifer/jen"
 	"github.com/pubgo/fastgit/utils"
-	"github.com/pubgo/funk/v2/assert"
 	"github.com/pubgo/funk/v2/pathutil"
 )
 
-func Gen(path string, version string) {
+func Gen(path string, version string) error {
 	pathDir := filepath.Dir(path)
-	assert.Must(pathutil.IsNotExistMkDir(pathDir))
+	if err := pathutil.IsNotExistMkDir(pathDir); err != nil {
+		return err
+	}
 
 	genFile := jen.NewFile("version")
 	genFile.HeaderComment("Code generated by version. DO NOT EDIT.")
@@ -36,8 +37,12 @@ func Gen(path string, version string) {
 	genFile.Const().Id("CommitID").Op("=").Lit("123")
 	genFile.Const().Id("BuildTime").Op("=").Lit(time.Now().UTC().Format(time.RFC3339))
 	genFile.Const().Id("Version").Op("=").Lit(strings.TrimSpace(version))
-	genFile.Const().Id("Branch").Op("=").Lit(strings.TrimSpace(utils.GetCurrentBranch().Unwrap()))
+	branch, err := utils.GetCurrentBranch().UnwrapErr()
+	if err != nil {
+		return err
+	}
+	genFile.Const().Id("Branch").Op("=").Lit(strings.TrimSpace(branch))
 	genFile.Const().Id("Project").Op("=").Lit("ffff")
 
-	assert.Must(os.WriteFile(path, []byte(genFile.GoString()), 0644))
+	return os.WriteFile(path, []byte(genFile.GoString()), 0644)
 }
@@ -13,11 +13,12 @@ import (
 	"time"
 
 	"github.com/bitfield/script"
-	"github.com/briandowns/spinner"
-	"github.com/pubgo/funk/v2/assert"
 	"github.com/pubgo/funk/v2/log"
 	"github.com/pubgo/funk/v2/log/logfields"
 	"github.com/pubgo/funk/v2/result"
+
+	"github.com/pubgo/fastgit/pkg/execlog"
+	"github.com/pubgo/fastgit/pkg/gitdiff"
 )
 
 // KnownError 是一个自定义错误类型
@@ -34,31 +35,48 @@ func ExcludeFromDiff(path string) string {
 	return fmt.Sprintf(":(exclude)%s", path)
 }
 
-type GetStagedDiffRsp struct {
-	Files []string `json:"files"`
-	Diff  string   `json:"diff"`
+// ExcludePathspecs 把 commit.exclude 中的路径/通配符转换为 :(exclude) pathspec，忽略空项与重复项
+func ExcludePathspecs(patterns ...string) []string {
+	return gitdiff.ExcludePathspecs(patterns...)
 }
 
-// GetStagedDiff 获取暂存区的差异
+// GetStagedDiffRsp 是暂存区 diff 的采集结果，见 gitdiff.Diff
+type GetStagedDiffRsp = gitdiff.Diff
+
+// GetStagedDiff 获取暂存区的差异，diff 以流式读取并按 DefaultDiffLimits 截断
+// excludeFiles 为 :(exclude) pathspec，只从 Diff 中排除，Files 仍列出全部暂存文件
 func GetStagedDiff(ctx context.Context, excludeFiles ...string) (r result.Result[*GetStagedDiffRsp]) {
-	defer result.Recovery(&r)
-	diffCached := []string{"git", "diff", "--cached", "--diff-algorithm=minimal"}
+	return getCachedDiff(ctx, "", excludeFiles...)
+}
 
-	// 获取暂存区文件的名称
-	filesOutput := ShellExecOutput(ctx, append(diffCached, append([]string{"--name-only"}, excludeFiles...)...)...).Unwrap()
+// GetAmendDiff 获取 amend 后的提交将包含的差异：暂存区相对 HEAD 父提交（根提交时相对空树），
+// 即 HEAD 本身的改动加上暂存的改动
+func GetAmendDiff(ctx context.Context, excludeFiles ...string) (r result.Result[*GetStagedDiffRsp]) {
+	return getCachedDiff(ctx, gitdiff.AmendBase(ctx, ""), excludeFiles...)
+}
 
-	files := strings.Split(strings.TrimSpace(filesOutput), "\n")
-	if len(files) == 0 || files[0] == "" {
-		return r.WithValue(new(GetStagedDiffRsp))
+func getCachedDiff(ctx context.Context, base string, excludeFiles ...string) (r result.Result[*GetStagedDiffRsp]) {
+	rsp, err := gitdiff.Staged(ctx, "", gitdiff.Options{Base: base, Exclude: excludeFiles, Limits: DefaultDiffLimits})
+	if err != nil {
+		return r.WithErr(err)
 	}
+	if rsp.Truncated {
+		log.Warn().Int("files", len(rsp.Stats)).Msg("staged diff is large, some files were truncated or summarized")
+	}
+	if len(rsp.Excluded) > 0 {
+		log.Info().Strs("files", rsp.Excluded).Msg("staged files excluded from the AI diff by commit.exclude")
+	}
+	return r.WithValue(rsp)
+}
 
-	// 获取暂存区的完整差异
-	diffOutput := ShellExecOutput(ctx, append(diffCached, excludeFiles...)...).Unwrap()
-
-	return r.WithValue(&GetStagedDiffRsp{
-		Files: files,
-		Diff:  strings.TrimSpace(diffOutput),
-	})
+func splitLines(output string) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
 }
 
 // GetDetectedMessage 生成检测到的文件数量的消息
@@ -71,29 +89,32 @@ func GetDetectedMessage(files []string) string {
 	return fmt.Sprintf("detected %d staged file%s", fileCount, pluralSuffix)
 }
 
-func GitPushTag(ctx context.Context, ver string) string {
+// GitPushTag 在 HEAD 上创建 tag ver 并推送到 origin
+func GitPushTag(ctx context.Context, ver string) (string, error) {
 	if ver == "" {
-		return ""
+		return "", nil
 	}
 
 	log.Info().Msg("git push tag " + ver)
-	assert.Must(ShellExec(ctx, "git", "tag", ver))
+	if err := ShellExec(ctx, "git", "tag", ver); err != nil {
+		return "", err
+	}
 	return GitPush(ctx, "origin", ver)
 }
 
-func GitFetchAll(ctx context.Context) {
-	assert.Must(ShellExec(ctx, "git", "fetch", "--prune", "--tags"))
+// GitFetchAll 拉取远端分支与 tag，并清理远端已删除的引用
+func GitFetchAll(ctx context.Context) error {
+	return ShellExec(ctx, "git", "fetch", "--prune", "--tags")
 }
 
+// IsDirty 读取共享的 RepoState 快照，同一次调用中多次判断只执行一次 git status
 func IsDirty() (r result.Result[bool]) {
-	output := result.Wrap(script.Exec("git status --porcelain").String()).
-		Log(func(e result.Event) {
-			e.Str(logfields.Msg, "failed to gitRun git")
-		})
-
-	return result.MapTo(output, func(output string) bool {
-		return len(strings.TrimSpace(output)) > 0
-	})
+	state, err := CurrentRepoState(context.Background())
+	if err != nil {
+		log.Err(err).Msg("failed to gitRun git")
+		return r.WithErr(err)
+	}
+	return r.WithValue(state.Dirty())
 }
 
 func GetCommitCount(branch string) (r result.Result[int]) {
@@ -552,72 +573,49 @@ func SanitizeBranchNameForDirectory(branchName string) string {
 }
 
 type FileChange struct {
-	Path    string
+	Path string
+	// OldPath is the previous path of a renamed file, empty otherwise.
+	OldPath string
 	Added   int
 	Removed int
 }
 
-// Status returns the output of git status.
+// Status returns the output of git status, from the shared RepoState snapshot.
 func Status() (string, error) {
-	return gitRun("status", "--porcelain")
+	state, err := CurrentRepoState(context.Background())
+	if err != nil {
+		return "", err
+	}
+	return state.Status, nil
 }
 
-// DiffStat returns statistics for all changed files (staged and unstaged).
+// DiffStat returns statistics for all changed files (staged and unstaged), from the shared RepoState snapshot.
 func DiffStat() ([]FileChange, error) {
-	// Get staged file stats
-	stagedOutput, err := gitRun("diff", "--numstat", "--cached")
+	state, err := CurrentRepoState(context.Background())
 	if err != nil {
 		return nil, err
 	}
+	return state.Changes, nil
+}
 
-	// Get unstaged file stats
-	unstagedOutput, err := gitRun("diff", "--numstat")
+// StagedDiffStat returns statistics for the staged changes only, i.e. what the next
+// commit records, from the shared RepoState snapshot.
+func StagedDiffStat() ([]FileChange, error) {
+	state, err := CurrentRepoState(context.Background())
 	if err != nil {
 		return nil, err
 	}
+	return state.Staged, nil
+}
 
-	// Parse both outputs
-	statsMap := make(map[string]*FileChange)
-
-	parseNumstat := func(output string) {
-		lines := strings.Split(strings.TrimSpace(output), "\n")
-		for _, line := range lines {
-			if line == "" {
-				continue
-			}
-
-			parts := strings.Fields(line)
-			if len(parts) < 3 {
-				continue
-			}
-
-			added, _ := strconv.Atoi(parts[0])
-			removed, _ := strconv.Atoi(parts[1])
-			path := parts[2]
-
-			if existing, ok := statsMap[path]; ok {
-				existing.Added += added
-				existing.Removed += removed
-			} else {
-				statsMap[path] = &FileChange{
-					Path:    path,
-					Added:   added,
-					Removed: removed,
-				}
-			}
-		}
-	}
-
-	parseNumstat(stagedOutput)
-	parseNumstat(unstagedOutput)
-
-	// Convert map to slice
-	var stats []FileChange
-	for _, stat := range statsMap {
-		stats = append(stats, *stat)
+// AmendDiffStat returns statistics for what `git commit --amend` would record: the index
+// against the parent of HEAD, see GetAmendDiff.
+func AmendDiffStat() ([]FileChange, error) {
+	output, err := gitRun("diff", "--numstat", "--cached", gitdiff.AmendBase(context.Background(), ""))
+	if err != nil {
+		return nil, err
 	}
-
-	return stats, nil
+	return mergeNumstat(output), nil
 }
 
 // Log returns recent commit messages (last 10).
@@ -639,16 +637,23 @@ func Add(files ...string) error {
 	return err
 }
 
-// Commit creates a commit with the given message.
-func Commit(message string) error {
-	_, err := gitRun("commit", "-m", message)
-	return err
+// Commit creates a commit with the given message; sign adds -S.
+func Commit(message string, sign bool) error {
+	_, err := gitRun(signArgs([]string{"commit", "-m", message}, sign)...)
+	return signingHint(err)
 }
 
-// CommitAmend amends the last commit with a new message.
-func CommitAmend(message string) error {
-	_, err := gitRun("commit", "--amend", "-m", message)
-	return err
+// CommitAmend amends the last commit with a new message; sign adds -S.
+func CommitAmend(message string, sign bool) error {
+	_, err := gitRun(signArgs([]string{"commit", "--amend", "-m", message}, sign)...)
+	return signingHint(err)
+}
+
+func signArgs(args []string, sign bool) []string {
+	if sign {
+		return append(args, "-S")
+	}
+	return args
 }
 
 // LastCommitAuthor returns the author name and email of the last commit.
@@ -684,7 +689,10 @@ func gitRun(args ...string) (string, error) {
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	if err := cmd.Run(); err != nil {
+	record := execlog.Track("", args)
+	err := cmd.Run()
+	record(err)
+	if err != nil {
 		if stderr.Len() > 0 {
 			return "", fmt.Errorf("git %s failed: %s", strings.Join(args, " "), stderr.String())
 		}
@@ -704,7 +712,7 @@ func GitPull(ctx context.Context, args ...string) (r result.Error) {
 	output := result.Async(func() result.Result[string] { return ShellExecOutput(ctx, args...) })
 	time.Sleep(time.Millisecond * 20)
 
-	spin := spinner.New(spinner.CharSets[35], 100*time.Millisecond, func(s *spinner.Spinner) { s.Prefix = "git pull: " })
+	spin := NewSpinner("git pull: ")
 	spin.Start()
 	res := output.Await(ctx).Unwrap()
 	spin.Stop()
@@ -1,6 +1,9 @@
 package utils
 
 import (
+	"context"
+	"os"
+	"path/filepath"
 	"testing"
 	
 	"github.com/stretchr/testify/assert"
@@ -9,3 +12,75 @@ import (
 func TestIsDirty(t *testing.T) {
 	assert.NoError(t, IsDirty().GetErr())
 }
+
+func TestGetAmendDiff(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv("FASTGIT_EXEC_LOG", "0")
+	run := func(args ...string) {
+		out, err := gitRun(args...)
+		assert.NoError(t, err, out)
+	}
+	run("init", "-q")
+	run("config", "user.email", "dev@example.com")
+	run("config", "user.name", "dev")
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a\n"), 0o644))
+	run("add", "a.txt")
+	run("commit", "-q", "-m", "init")
+
+	// 根提交与空树比较
+	root := GetAmendDiff(context.Background())
+	assert.NoError(t, root.GetErr())
+	assert.Equal(t, []string{"a.txt"}, root.Unwrap().Files)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "b.txt"), []byte("b\n"), 0o644))
+	run("add", "b.txt")
+	run("commit", "-q", "-m", "add b")
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "c.txt"), []byte("c\n"), 0o644))
+	run("add", "c.txt")
+
+	staged := GetStagedDiff(context.Background())
+	assert.NoError(t, staged.GetErr())
+	assert.Equal(t, []string{"c.txt"}, staged.Unwrap().Files)
+
+	amend := GetAmendDiff(context.Background())
+	assert.NoError(t, amend.GetErr())
+	rsp := amend.Unwrap()
+	assert.Equal(t, []string{"b.txt", "c.txt"}, rsp.Files)
+	assert.Contains(t, rsp.Diff, "+b")
+	assert.Contains(t, rsp.Diff, "+c")
+}
+
+func TestGetAllRemoteTags(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv("FASTGIT_EXEC_LOG", "0")
+	run := func(args ...string) {
+		out, err := gitRun(args...)
+		assert.NoError(t, err, out)
+	}
+	run("init", "-q")
+	run("config", "user.email", "dev@example.com")
+	run("config", "user.name", "dev")
+	run("commit", "-q", "--allow-empty", "-m", "init")
+
+	// 没有 origin 时不会退出进程
+	assert.NotPanics(t, func() { _, _ = GetAllRemoteTags(context.Background()) })
+
+	remote := filepath.Join(t.TempDir(), "origin.git")
+	run("init", "-q", "--bare", remote)
+	run("remote", "add", "origin", remote)
+	run("tag", "v1.0.0")
+	run("tag", "-a", "v1.1.0", "-m", "release")
+	run("tag", "latest")
+	run("push", "-q", "origin", "--tags")
+
+	tags, err := GetAllRemoteTags(context.Background())
+	assert.NoError(t, err)
+	var names []string
+	for _, tag := range tags {
+		names = append(names, tag.Original())
+	}
+	assert.ElementsMatch(t, []string{"v1.0.0", "v1.1.0"}, names)
+}
@@ -0,0 +1,108 @@
+package githubclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/google/go-github/v71/github"
+
+	"github.com/pubgo/fastgit/utils"
+)
+
+// ReleaseNotes 是要发布到 GitHub Release 的内容，按 Tag 对应到唯一的 release
+type ReleaseNotes struct {
+	Tag        string
+	Name       string
+	Body       string
+	Prerelease bool
+	Draft      bool
+	// Commit 是 tag 指向的提交，创建 release 时作为 target_commitish，避免 GitHub 在默认分支上另建 tag
+	Commit string
+}
+
+// PublishAction 表示 Publish 对 release 做了什么
+type PublishAction string
+
+const (
+	ReleaseCreated   PublishAction = "created"
+	ReleaseUpdated   PublishAction = "updated"
+	ReleaseUnchanged PublishAction = "unchanged"
+)
+
+// ReleasePublisher 用带 token 的客户端创建或更新 release
+type ReleasePublisher struct {
+	client      *github.Client
+	owner, repo string
+}
+
+// NewReleasePublisher 返回经 proxy（同 utils.NewHTTPClient）访问 GitHub 的 ReleasePublisher
+func NewReleasePublisher(owner, repo, token, proxy string) *ReleasePublisher {
+	client := github.NewClient(utils.NewHTTPClient(proxy))
+	if token != "" {
+		client = client.WithAuthToken(token)
+	}
+	return &ReleasePublisher{client: client, owner: owner, repo: repo}
+}
+
+// GetByTag 返回 tag 对应的 release，不存在时返回 nil；草稿只能通过列表查到
+func (p ReleasePublisher) GetByTag(ctx context.Context, tag string) (*github.RepositoryRelease, error) {
+	release, _, err := p.client.Repositories.GetReleaseByTag(ctx, p.owner, p.repo, tag)
+	var ghErr *github.ErrorResponse
+	switch {
+	case err == nil:
+		return release, nil
+	case errors.As(err, &ghErr) && ghErr.Response != nil && ghErr.Response.StatusCode == http.StatusNotFound:
+	default:
+		return nil, err
+	}
+
+	releases, _, err := p.client.Repositories.ListReleases(ctx, p.owner, p.repo, &github.ListOptions{PerPage: 100})
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range releases {
+		if r.GetTagName() == tag {
+			return r, nil
+		}
+	}
+	return nil, nil
+}
+
+// Publish 幂等地发布 notes：tag 没有 release 时创建，有则只在标题、正文或预发布/草稿标记变化时更新
+func (p ReleasePublisher) Publish(ctx context.Context, notes ReleaseNotes) (*github.RepositoryRelease, PublishAction, error) {
+	existing, err := p.GetByTag(ctx, notes.Tag)
+	if err != nil {
+		return nil, "", err
+	}
+	if existing == nil {
+		release, _, err := p.client.Repositories.CreateRelease(ctx, p.owner, p.repo, &github.RepositoryRelease{
+			TagName:         github.Ptr(notes.Tag),
+			TargetCommitish: optional(notes.Commit),
+			Name:            github.Ptr(notes.Name),
+			Body:            github.Ptr(notes.Body),
+			Prerelease:      github.Ptr(notes.Prerelease),
+			Draft:           github.Ptr(notes.Draft),
+		})
+		return release, ReleaseCreated, err
+	}
+
+	if existing.GetName() == notes.Name && existing.GetBody() == notes.Body &&
+		existing.GetPrerelease() == notes.Prerelease && existing.GetDraft() == notes.Draft {
+		return existing, ReleaseUnchanged, nil
+	}
+	release, _, err := p.client.Repositories.EditRelease(ctx, p.owner, p.repo, existing.GetID(), &github.RepositoryRelease{
+		Name:       github.Ptr(notes.Name),
+		Body:       github.Ptr(notes.Body),
+		Prerelease: github.Ptr(notes.Prerelease),
+		Draft:      github.Ptr(notes.Draft),
+	})
+	return release, ReleaseUpdated, err
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return github.Ptr(s)
+}
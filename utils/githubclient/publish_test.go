@@ -0,0 +1,79 @@
+package githubclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/google/go-github/v71/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeReleases 模拟 GitHub 的 release 接口，只保存一个 release
+type fakeReleases struct {
+	mu      sync.Mutex
+	release *github.RepositoryRelease
+	writes  int
+}
+
+func (f *fakeReleases) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/repos/o/r/releases/tags/v1.0.0":
+		if f.release == nil || f.release.GetDraft() {
+			http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(f.release)
+	case r.Method == http.MethodGet && r.URL.Path == "/repos/o/r/releases":
+		var list []*github.RepositoryRelease
+		if f.release != nil {
+			list = append(list, f.release)
+		}
+		_ = json.NewEncoder(w).Encode(list)
+	case r.Method == http.MethodPost && r.URL.Path == "/repos/o/r/releases",
+		r.Method == http.MethodPatch && r.URL.Path == "/repos/o/r/releases/1":
+		var in github.RepositoryRelease
+		_ = json.NewDecoder(r.Body).Decode(&in)
+		if f.release == nil {
+			f.release = &github.RepositoryRelease{ID: github.Ptr(int64(1)), TagName: in.TagName}
+		}
+		f.release.Name, f.release.Body, f.release.Prerelease, f.release.Draft = in.Name, in.Body, in.Prerelease, in.Draft
+		f.writes++
+		_ = json.NewEncoder(w).Encode(f.release)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestReleasePublisherPublish(t *testing.T) {
+	fake := &fakeReleases{}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	p := NewReleasePublisher("o", "r", "token", "")
+	p.client.BaseURL, _ = url.Parse(srv.URL + "/")
+
+	ctx := context.Background()
+	notes := ReleaseNotes{Tag: "v1.0.0", Name: "v1.0.0", Body: "- first", Draft: true}
+	_, action, err := p.Publish(ctx, notes)
+	require.NoError(t, err)
+	assert.Equal(t, ReleaseCreated, action)
+
+	_, action, err = p.Publish(ctx, notes)
+	require.NoError(t, err)
+	assert.Equal(t, ReleaseUnchanged, action)
+
+	notes.Body, notes.Draft = "- first\n- second", false
+	release, action, err := p.Publish(ctx, notes)
+	require.NoError(t, err)
+	assert.Equal(t, ReleaseUpdated, action)
+	assert.Equal(t, "- first\n- second", release.GetBody())
+	assert.Equal(t, 2, fake.writes)
+}
@@ -2,15 +2,17 @@ package githubclient
 
 import (
 	"context"
-	"net/http"
 
 	"github.com/google/go-github/v71/github"
 	"github.com/samber/lo"
+
+	"github.com/pubgo/fastgit/utils"
 )
 
-func NewPublicRelease(owner, repo string) *PublicRelease {
+// NewPublicRelease 匿名读取公开仓库的 release，proxy 同 utils.NewHTTPClient
+func NewPublicRelease(owner, repo, proxy string) *PublicRelease {
 	return &PublicRelease{
-		client: github.NewClient(http.DefaultClient),
+		client: github.NewClient(utils.NewHTTPClient(proxy)),
 		owner:  owner,
 		repo:   repo,
 	}
@@ -10,7 +10,7 @@ import (
 )
 
 func TestName(t *testing.T) {
-	rr := NewPublicRelease("pubgo", "fastgit")
+	rr := NewPublicRelease("pubgo", "fastgit", "")
 	ffff := lo.Must(rr.List(context.Background()))
 	t.Log(runtime.GOARCH, runtime.GOOS)
 	//pretty.Println(getAssets(ffff))
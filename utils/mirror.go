@@ -0,0 +1,195 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/pubgo/funk/v2/log"
+
+	"github.com/pubgo/fastgit/pkg/execlog"
+	"github.com/pubgo/fastgit/pkg/timing"
+)
+
+// MirrorConfigKey 是仓库 git config 中登记镜像 remote 的多值配置项
+const MirrorConfigKey = "fastgit.mirror"
+
+// MirrorRemotes 返回登记为镜像的 remote 名称，push/tag 推送 origin 后会同步推送到这些 remote
+func MirrorRemotes(ctx context.Context) []string {
+	out, err := exec.CommandContext(ctx, "git", "config", "--get-all", MirrorConfigKey).Output()
+	if err != nil {
+		return nil
+	}
+	var remotes []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			remotes = append(remotes, line)
+		}
+	}
+	return remotes
+}
+
+// MirrorError 汇总推送失败的镜像
+type MirrorError struct {
+	Failed map[string]error
+}
+
+func (e *MirrorError) Error() string {
+	parts := make([]string, 0, len(e.Failed))
+	for _, remote := range slices.Sorted(maps.Keys(e.Failed)) {
+		parts = append(parts, remote+": "+e.Failed[remote].Error())
+	}
+	return "mirror push failed: " + strings.Join(parts, "; ")
+}
+
+// PushMirrors 将一次推送 origin 的参数逐个重放到镜像 remote，并逐个报告结果。
+// args 与 `git push` 的参数相同，其中的 origin 会被替换为镜像名。镜像没有自己的 remote-tracking 分支，
+// 不带值的 --force-with-lease 改为显式的 --force-with-lease=<ref>:<expect>，期望值为推送前 origin 上的提交，
+// 镜像被单独推送过、与 origin 不一致时拒绝覆盖。
+func PushMirrors(ctx context.Context, out io.Writer, args ...string) error {
+	remotes := MirrorRemotes(ctx)
+	if len(remotes) == 0 {
+		return nil
+	}
+	if out == nil {
+		out = os.Stderr
+	}
+
+	leases := mirrorLeases(ctx, args)
+	failed := map[string]error{}
+	for _, remote := range remotes {
+		pushArgs := append([]string{"push"}, mirrorPushArgs(args, remote, leases)...)
+		done := timing.Track(ctx, timing.PhaseGit, "git "+strings.Join(pushArgs, " "))
+		record := execlog.Track("", pushArgs)
+		output, err := exec.CommandContext(ctx, "git", pushArgs...).CombinedOutput()
+		record(err)
+		done()
+		if err != nil {
+			msg := strings.TrimSpace(string(output))
+			if msg == "" {
+				msg = err.Error()
+			}
+			failed[remote] = errors.New(pushFailure(msg))
+			_, _ = fmt.Fprintf(out, "  ✗ mirror %s: %s\n", remote, failed[remote])
+			continue
+		}
+		_, _ = fmt.Fprintf(out, "  ✓ mirror %s\n", remote)
+	}
+	if len(failed) > 0 {
+		return &MirrorError{Failed: failed}
+	}
+	return nil
+}
+
+// mirrorPushArgs 把推送 origin 的参数改写为推送 remote；leases 为 ref 到期望值的映射，
+// 替换不带值的 --force-with-lease，为空时不强推，非快进的推送由镜像拒绝并报告
+func mirrorPushArgs(args []string, remote string, leases map[string]string) []string {
+	out := make([]string, 0, len(args)+1)
+	replaced := false
+	for _, arg := range args {
+		switch {
+		case arg == "origin" && !replaced:
+			out = append(out, remote)
+			replaced = true
+		case arg == "--force-with-lease":
+			for _, ref := range slices.Sorted(maps.Keys(leases)) {
+				out = append(out, "--force-with-lease="+ref+":"+leases[ref])
+			}
+		case arg == "--set-upstream" || arg == "-u":
+			// 上游保持指向 origin
+		default:
+			out = append(out, arg)
+		}
+	}
+	if !replaced {
+		out = append([]string{remote}, out...)
+	}
+	return out
+}
+
+// mirrorLeases 为推送 origin 的分支取推送前 origin 上的提交：origin 远程跟踪分支 reflog 的上一条；
+// 推送前 origin 没有该分支时期望值为空，即要求镜像上也不存在
+func mirrorLeases(ctx context.Context, args []string) map[string]string {
+	if !slices.Contains(args, "--force-with-lease") {
+		return nil
+	}
+	leases := map[string]string{}
+	for _, branch := range pushedBranches(ctx, args) {
+		expect, err := exec.CommandContext(ctx, "git", "rev-parse", "--verify", "--quiet", "refs/remotes/origin/"+branch+"@{1}").Output()
+		if err != nil {
+			expect = nil
+		}
+		leases["refs/heads/"+branch] = strings.TrimSpace(string(expect))
+	}
+	return leases
+}
+
+// pushedBranches 返回 git push 参数中 origin 之后的 refspec 所推送的分支名；HEAD 解析为当前分支
+func pushedBranches(ctx context.Context, args []string) []string {
+	var branches []string
+	afterRemote := false
+	for _, arg := range args {
+		switch {
+		case arg == "origin":
+			afterRemote = true
+			continue
+		case !afterRemote || strings.HasPrefix(arg, "-"):
+			continue
+		}
+		src, dst, ok := strings.Cut(strings.TrimPrefix(arg, "+"), ":")
+		if !ok {
+			dst = src
+		}
+		if dst == "HEAD" {
+			head, err := exec.CommandContext(ctx, "git", "symbolic-ref", "--quiet", "--short", "HEAD").Output()
+			if err != nil {
+				continue
+			}
+			dst = strings.TrimSpace(string(head))
+		}
+		if strings.HasPrefix(dst, "refs/") && !strings.HasPrefix(dst, "refs/heads/") {
+			continue
+		}
+		if branch := strings.TrimPrefix(dst, "refs/heads/"); branch != "" {
+			branches = append(branches, branch)
+		}
+	}
+	return branches
+}
+
+// pushesOrigin 判断 git push 参数是否以 origin 为目标
+func pushesOrigin(args []string) bool {
+	for _, arg := range args {
+		if arg == "origin" {
+			return true
+		}
+	}
+	return false
+}
+
+func warnMirrors(ctx context.Context, args ...string) {
+	if !pushesOrigin(args) {
+		return
+	}
+	if err := PushMirrors(ctx, os.Stderr, args...); err != nil {
+		log.Warn().Err(err).Msg("origin pushed, but some mirrors failed")
+	}
+}
+
+// pushFailure 取 git push 输出中最能说明原因的一行（rejected / fatal 优先于末尾的 "failed to push some refs"）
+func pushFailure(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "fatal:") || strings.HasPrefix(line, "! ") || strings.Contains(line, "[rejected]") {
+			return line
+		}
+	}
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMirrorPushArgs(t *testing.T) {
+	leases := map[string]string{"refs/heads/main": "abc123"}
+	assert.Equal(t, []string{"--force-with-lease=refs/heads/main:abc123", "backup", "main"},
+		mirrorPushArgs([]string{"--force-with-lease", "--set-upstream", "origin", "main"}, "backup", leases))
+	assert.Equal(t, []string{"backup", "main"}, mirrorPushArgs([]string{"--force-with-lease", "origin", "main"}, "backup", nil),
+		"without a known expected value the mirror push is not forced")
+	assert.Equal(t, []string{"backup", "v1.0.0"}, mirrorPushArgs([]string{"origin", "v1.0.0"}, "backup", nil))
+	assert.Equal(t, []string{"--all", "backup"}, mirrorPushArgs([]string{"--all", "origin"}, "backup", nil))
+	assert.Equal(t, []string{"backup", "--tags"}, mirrorPushArgs([]string{"--tags"}, "backup", nil))
+	assert.Equal(t, []string{"main", "dev"}, pushedBranches(context.Background(), []string{"--force-with-lease", "origin", "main", "+feat:refs/heads/dev", "v1:refs/tags/v1"}))
+}
+
+func TestPushFailure(t *testing.T) {
+	out := "To /tmp/m.git\n ! [rejected]        main -> main (fetch first)\nerror: failed to push some refs to '/tmp/m.git'"
+	assert.Equal(t, "! [rejected]        main -> main (fetch first)", pushFailure(out))
+	assert.Equal(t, "error: boom", pushFailure("error: boom"))
+}
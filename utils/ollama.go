@@ -0,0 +1,149 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const (
+	DefaultOllamaBaseURL = "http://localhost:11434"
+	DefaultOllamaModel   = "llama3.2"
+)
+
+// OllamaConfig 本地模型服务配置（Ollama 原生 API），适用于无法访问公网 API 的环境
+type OllamaConfig struct {
+	BaseURL string `yaml:"base_url"`
+	Model   string `yaml:"model"`
+}
+
+type OllamaClient struct {
+	Cfg  *OllamaConfig
+	HTTP *http.Client
+}
+
+type OllamaMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type ollamaChatRequest struct {
+	Model    string          `json:"model"`
+	Messages []OllamaMessage `json:"messages"`
+	Stream   bool            `json:"stream"`
+}
+
+// OllamaChatResponse 是合并后的回复；不返回统计的服务端（如部分 llama.cpp 兼容实现）计数为 0
+type OllamaChatResponse struct {
+	Model           string        `json:"model"`
+	Message         OllamaMessage `json:"message"`
+	Done            bool          `json:"done"`
+	PromptEvalCount int           `json:"prompt_eval_count"`
+	EvalCount       int           `json:"eval_count"`
+	Error           string        `json:"error"`
+}
+
+func NewOllamaClient(cfg *OllamaConfig) *OllamaClient {
+	c := OllamaConfig{}
+	if cfg != nil {
+		c = *cfg
+	}
+	c.BaseURL = strings.TrimSpace(c.BaseURL)
+	if c.BaseURL == "" {
+		c.BaseURL = strings.TrimSpace(os.Getenv("OLLAMA_HOST"))
+	}
+	if c.BaseURL == "" {
+		c.BaseURL = DefaultOllamaBaseURL
+	}
+	// OLLAMA_HOST 常写作 127.0.0.1:11434
+	if !strings.Contains(c.BaseURL, "://") {
+		c.BaseURL = "http://" + c.BaseURL
+	}
+	// 兼容填写了 OpenAI 兼容地址（.../v1）的配置
+	c.BaseURL = strings.TrimSuffix(strings.TrimRight(c.BaseURL, "/"), "/v1")
+	if strings.TrimSpace(c.Model) == "" {
+		c.Model = DefaultOllamaModel
+	}
+	return &OllamaClient{Cfg: &c, HTTP: http.DefaultClient}
+}
+
+// Chat 发送一次非流式对话；若服务端忽略 stream=false 仍返回 NDJSON 流，会把分片内容拼接起来
+func (c *OllamaClient) Chat(ctx context.Context, model string, messages []OllamaMessage) (OllamaChatResponse, error) {
+	return c.chat(ctx, model, messages, nil)
+}
+
+// ChatStream 以流式请求对话，每收到一个分片回调 onDelta，返回值与 Chat 相同
+func (c *OllamaClient) ChatStream(ctx context.Context, model string, messages []OllamaMessage, onDelta func(string)) (OllamaChatResponse, error) {
+	return c.chat(ctx, model, messages, onDelta)
+}
+
+func (c *OllamaClient) chat(ctx context.Context, model string, messages []OllamaMessage, onDelta func(string)) (OllamaChatResponse, error) {
+	if strings.TrimSpace(model) == "" {
+		model = c.Cfg.Model
+	}
+	body, err := json.Marshal(ollamaChatRequest{Model: model, Messages: messages, Stream: onDelta != nil})
+	if err != nil {
+		return OllamaChatResponse{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.Cfg.BaseURL+"/api/chat", bytes.NewReader(body))
+	if err != nil {
+		return OllamaChatResponse{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return OllamaChatResponse{}, fmt.Errorf("ollama chat %s: %w", c.Cfg.BaseURL, err)
+	}
+	defer resp.Body.Close()
+
+	out := OllamaChatResponse{Model: model}
+	var content strings.Builder
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var chunk OllamaChatResponse
+		if err := dec.Decode(&chunk); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return out, fmt.Errorf("ollama chat: status %d: decode response: %w", resp.StatusCode, err)
+		}
+		if chunk.Error != "" {
+			return out, fmt.Errorf("ollama chat: %s", chunk.Error)
+		}
+		content.WriteString(chunk.Message.Content)
+		if onDelta != nil && chunk.Message.Content != "" {
+			onDelta(chunk.Message.Content)
+		}
+		if chunk.Model != "" {
+			out.Model = chunk.Model
+		}
+		if chunk.Message.Role != "" {
+			out.Message.Role = chunk.Message.Role
+		}
+		// 统计字段只出现在最后一个分片，且可能缺失
+		if chunk.PromptEvalCount > 0 {
+			out.PromptEvalCount = chunk.PromptEvalCount
+		}
+		if chunk.EvalCount > 0 {
+			out.EvalCount = chunk.EvalCount
+		}
+		out.Done = out.Done || chunk.Done
+	}
+	if resp.StatusCode/100 != 2 {
+		return out, fmt.Errorf("ollama chat: status %d", resp.StatusCode)
+	}
+
+	out.Message.Content = content.String()
+	if out.Message.Role == "" {
+		out.Message.Role = "assistant"
+	}
+	return out, nil
+}
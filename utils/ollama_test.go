@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOllamaChat(t *testing.T) {
+	cases := map[string]struct {
+		body       string
+		content    string
+		evalCount  int
+		promptEval int
+	}{
+		"single": {
+			body:       `{"model":"qwen","message":{"role":"assistant","content":"feat: add x"},"done":true,"prompt_eval_count":12,"eval_count":5}`,
+			content:    "feat: add x",
+			evalCount:  5,
+			promptEval: 12,
+		},
+		"streamed": {
+			body: `{"model":"qwen","message":{"role":"assistant","content":"feat: "},"done":false}
+{"model":"qwen","message":{"role":"assistant","content":"add x"},"done":false}
+{"model":"qwen","message":{"role":"assistant","content":""},"done":true,"eval_count":7}
+`,
+			content:   "feat: add x",
+			evalCount: 7,
+		},
+		"no usage": {
+			body:    `{"message":{"content":"fix: y"},"done":true}`,
+			content: "fix: y",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/api/chat", r.URL.Path)
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			defer srv.Close()
+
+			client := NewOllamaClient(&OllamaConfig{BaseURL: srv.URL + "/v1/", Model: "qwen"})
+			resp, err := client.Chat(context.Background(), "", []OllamaMessage{{Role: "user", Content: "diff"}})
+			require.NoError(t, err)
+			assert.Equal(t, tc.content, resp.Message.Content)
+			assert.Equal(t, "assistant", resp.Message.Role)
+			assert.Equal(t, tc.evalCount, resp.EvalCount)
+			assert.Equal(t, tc.promptEval, resp.PromptEvalCount)
+			assert.True(t, resp.Done)
+		})
+	}
+}
+
+func TestOllamaChatStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ollamaChatRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.True(t, req.Stream)
+		_, _ = w.Write([]byte(`{"message":{"role":"assistant","content":"feat: "},"done":false}
+{"message":{"role":"assistant","content":"add x"},"done":true}
+`))
+	}))
+	defer srv.Close()
+
+	var deltas []string
+	resp, err := NewOllamaClient(&OllamaConfig{BaseURL: srv.URL}).ChatStream(context.Background(), "", []OllamaMessage{{Role: "user", Content: "diff"}}, func(d string) {
+		deltas = append(deltas, d)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"feat: ", "add x"}, deltas)
+	assert.Equal(t, "feat: add x", resp.Message.Content)
+}
+
+func TestOllamaChatError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":"model \"nope\" not found, try pulling it first"}`))
+	}))
+	defer srv.Close()
+
+	_, err := NewOllamaClient(&OllamaConfig{BaseURL: srv.URL}).Chat(context.Background(), "nope", nil)
+	require.ErrorContains(t, err, "not found")
+}
+
+func TestNewOllamaClientDefaults(t *testing.T) {
+	t.Setenv("OLLAMA_HOST", "127.0.0.1:9999")
+	c := NewOllamaClient(nil)
+	assert.Equal(t, "http://127.0.0.1:9999", c.Cfg.BaseURL)
+	assert.Equal(t, DefaultOllamaModel, c.Cfg.Model)
+}
@@ -8,14 +8,53 @@ type OpenaiClient struct {
 }
 
 type OpenaiConfig struct {
+	// Provider 选择生成提交信息的后端：openai|gemini|anthropic|ollama，默认 openai
+	Provider string `yaml:"provider"`
+	ApiKey   string `yaml:"api_key"`
+	BaseURL  string `yaml:"base_url"`
+	Model    string `yaml:"model"`
+	// Ollama 在 provider=ollama 时使用
+	Ollama *OllamaConfig `yaml:"ollama"`
+	// Anthropic 在 provider=anthropic 时使用，不沿用上面 OpenAI 兼容后端的 api_key/base_url/model
+	Anthropic *AnthropicConfig `yaml:"anthropic"`
+	// Retry 请求遇到 429/5xx 时的重试策略与单次请求超时
+	Retry *RetryConfig `yaml:"retry"`
+	// Fallbacks 主后端失败或超时后依次尝试的备用后端，如 gpt-4o-mini → 本地 ollama
+	Fallbacks []FallbackConfig `yaml:"fallbacks"`
+	// Proxy 访问 OpenAI / Anthropic 接口的代理，如 http://127.0.0.1:7890 或 socks5://127.0.0.1:1080，见 NewHTTPClient
+	Proxy string `yaml:"proxy"`
+}
+
+// FallbackConfig 是 openai.fallbacks 中的一个备用后端；与主后端相同 provider 时，留空的 api_key / base_url 沿用主后端
+type FallbackConfig struct {
+	// Provider 为 openai|gemini|anthropic|ollama，默认 openai
+	Provider string `yaml:"provider"`
+	ApiKey   string `yaml:"api_key"`
+	BaseURL  string `yaml:"base_url"`
+	Model    string `yaml:"model"`
+}
+
+// AnthropicConfig 是 openai.anthropic：Anthropic Messages API 的密钥、地址与模型，留空的地址与模型使用官方默认值
+type AnthropicConfig struct {
 	ApiKey  string `yaml:"api_key"`
 	BaseURL string `yaml:"base_url"`
 	Model   string `yaml:"model"`
 }
 
+// RetryConfig 是 openai.retry：遇到 429/5xx 时按指数退避加随机抖动重试，超时不重试，直接交给 fallbacks
+type RetryConfig struct {
+	// MaxRetries 最大重试次数，缺省 3，0 表示不重试
+	MaxRetries *int `yaml:"max_retries"`
+	// Timeout 单次请求超时（流式生成时为两段输出之间的最长等待），如 60s，缺省 60s；超时的请求不会重试
+	Timeout string `yaml:"timeout"`
+	// BaseDelay 首次重试前的等待，之后逐次翻倍，缺省 1s
+	BaseDelay string `yaml:"base_delay"`
+}
+
 func NewOpenaiClient(cfg *OpenaiConfig) *OpenaiClient {
 	var openaiCfg = openai.DefaultConfig(cfg.ApiKey)
 	openaiCfg.BaseURL = cfg.BaseURL
+	openaiCfg.HTTPClient = NewHTTPClient(cfg.Proxy)
 	return &OpenaiClient{
 		Client: openai.NewClientWithConfig(openaiCfg),
 		Cfg:    cfg,
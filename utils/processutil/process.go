@@ -8,26 +8,30 @@ import (
 	"time"
 )
 
-func FindProcess(pid int) *os.Process {
+func FindProcess(pid int) (*os.Process, error) {
 	proc, err := os.FindProcess(pid)
 	if err != nil {
-		// Should be unreachable on all supported operating systems.
-		panic(err)
+		return nil, fmt.Errorf("find process %d: %w", pid, err)
 	}
-	return proc
+	return proc, nil
 }
 
 func IsValidPid(pid int) bool {
 	if pid <= 0 {
 		return false
 	}
-	proc := FindProcess(pid)
-	err := proc.Signal(syscall.Signal(0))
-	return err == nil
+	proc, err := FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	return proc.Signal(syscall.Signal(0)) == nil
 }
 
 func SignalProcess(pid int, sig os.Signal) error {
-	proc := FindProcess(pid)
+	proc, err := FindProcess(pid)
+	if err != nil {
+		return err
+	}
 	return proc.Signal(sig)
 }
 
@@ -52,7 +56,11 @@ func KillGroup(pgid int) error {
 }
 
 func WaitProcess(pid int) (*os.ProcessState, error) {
-	return FindProcess(pid).Wait()
+	proc, err := FindProcess(pid)
+	if err != nil {
+		return nil, err
+	}
+	return proc.Wait()
 }
 
 // Terminates pid, then if context is cancelled, kills pid.
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// ProxyEnv 是未在配置中填写 proxy 时使用的代理地址环境变量
+const ProxyEnv = "FASTGIT_PROXY"
+
+// NewHTTPClient 返回访问 OpenAI / Anthropic / Gemini / GitHub API 的 HTTP 客户端
+// 代理依次取 proxy、FASTGIT_PROXY，都为空时按 HTTPS_PROXY / HTTP_PROXY / NO_PROXY；支持 http(s):// 与 socks5(h)://
+// 地址无效时请求直接返回错误，而不是绕过代理直连
+func NewHTTPClient(proxy string) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = ProxyFunc(proxy)
+	return &http.Client{Transport: transport}
+}
+
+// ConfiguredProxy 返回各层配置中最后一个非空的 openai.proxy，GitHub API 等非 AI 请求复用同一代理
+func ConfiguredProxy(cfgs []*OpenaiConfig) string {
+	var proxy string
+	for _, cfg := range cfgs {
+		if cfg != nil && strings.TrimSpace(cfg.Proxy) != "" {
+			proxy = strings.TrimSpace(cfg.Proxy)
+		}
+	}
+	return proxy
+}
+
+// ProxyFunc 是 NewHTTPClient 使用的 http.Transport.Proxy
+func ProxyFunc(proxy string) func(*http.Request) (*url.URL, error) {
+	proxy = strings.TrimSpace(proxy)
+	if proxy == "" {
+		proxy = strings.TrimSpace(os.Getenv(ProxyEnv))
+	}
+	if proxy == "" {
+		return http.ProxyFromEnvironment
+	}
+
+	u, err := ParseProxyURL(proxy)
+	if err != nil {
+		return func(*http.Request) (*url.URL, error) { return nil, err }
+	}
+	return http.ProxyURL(u)
+}
+
+// ParseProxyURL 解析代理地址，省略协议时按 http:// 处理，如 127.0.0.1:7890
+func ParseProxyURL(proxy string) (*url.URL, error) {
+	if !strings.Contains(proxy, "://") {
+		proxy = "http://" + proxy
+	}
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy %q: %w", proxy, err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("invalid proxy %q: unsupported scheme %q, use http, https, socks5 or socks5h", proxy, u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy %q: missing host", proxy)
+	}
+	return u, nil
+}
@@ -0,0 +1,155 @@
+package utils
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pubgo/fastgit/pkg/timing"
+)
+
+// RepoState 工作区快照：由 commit/tag/pull 等流程共享，index 与 HEAD 不变时复用同一份
+type RepoState struct {
+	// Dir 采集时的工作目录，目录变化时快照自动失效
+	Dir string
+	// key 为采集后的 index 与 HEAD 指纹，见 repoStateKey
+	key string
+	// Status 为 git status --porcelain 的输出
+	Status string
+	// Changes 为暂存区与工作区合并后的增删行数
+	Changes []FileChange
+	// Staged 为暂存区相对 HEAD 的增删行数，即下一次提交的内容
+	Staged []FileChange
+}
+
+// Dirty 工作区或暂存区是否有改动
+func (s *RepoState) Dirty() bool {
+	return s != nil && strings.TrimSpace(s.Status) != ""
+}
+
+var repoState struct {
+	mu    sync.Mutex
+	state *RepoState
+}
+
+// mutatingGitCommands 会改变工作区、暂存区或 HEAD 的 git 子命令，经 ShellExec 执行后快照失效；
+// restore/checkout/clean 等只改工作区的命令不会改变指纹，需要靠这里兜底
+var mutatingGitCommands = map[string]bool{
+	"add": true, "am": true, "apply": true, "checkout": true, "cherry-pick": true, "clean": true,
+	"commit": true, "merge": true, "mv": true, "pull": true, "rebase": true, "reset": true,
+	"restore": true, "revert": true, "rm": true, "stash": true, "switch": true,
+}
+
+// CurrentRepoState 返回当前目录的工作区快照；index 或 HEAD 变化后重新并行执行 status 与两次 diff --numstat
+func CurrentRepoState(ctx context.Context) (*RepoState, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	repoState.mu.Lock()
+	defer repoState.mu.Unlock()
+	if s := repoState.state; s != nil && s.Dir == dir && s.key != "" && s.key == repoStateKey() {
+		return s, nil
+	}
+
+	defer timing.Track(ctx, timing.PhaseGit, "collect repo state")()
+	var (
+		wg                      sync.WaitGroup
+		status, staged, working string
+		errs                    [3]error
+	)
+	wg.Add(3)
+	go func() { defer wg.Done(); status, errs[0] = gitRun("status", "--porcelain") }()
+	go func() { defer wg.Done(); staged, errs[1] = gitRun("diff", "--numstat", "--cached") }()
+	go func() { defer wg.Done(); working, errs[2] = gitRun("diff", "--numstat") }()
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// status 可能顺带刷新 index，指纹在采集之后计算
+	state := &RepoState{Dir: dir, key: repoStateKey(), Status: status, Changes: mergeNumstat(staged, working), Staged: mergeNumstat(staged)}
+	repoState.state = state
+	return state, nil
+}
+
+// repoStateKey 返回当前仓库 index 的修改时间、大小与 HEAD 提交组成的指纹；
+// add/commit/reset/pull 等无论经由哪条路径执行都会改变其中之一。取不到时返回空串，快照不复用
+func repoStateKey() string {
+	out, err := gitRun("rev-parse", "--git-path", "index", "HEAD")
+	if err != nil {
+		return ""
+	}
+	index, head, _ := strings.Cut(out, "\n")
+	info, err := os.Stat(strings.TrimSpace(index))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(head) + "@" + strconv.FormatInt(info.ModTime().UnixNano(), 10) + "/" + strconv.FormatInt(info.Size(), 10)
+}
+
+// InvalidateRepoState 丢弃快照，下次读取时重新采集
+func InvalidateRepoState() {
+	repoState.mu.Lock()
+	repoState.state = nil
+	repoState.mu.Unlock()
+}
+
+// invalidateAfter 在执行会修改仓库的 git 命令后让快照失效
+func invalidateAfter(args []string) {
+	if len(args) > 1 && args[0] == "git" && mutatingGitCommands[args[1]] {
+		InvalidateRepoState()
+	}
+}
+
+// mergeNumstat 合并多份 git diff --numstat 输出，同一文件的增删行数累加，保持首次出现的顺序
+func mergeNumstat(outputs ...string) []FileChange {
+	var stats []FileChange
+	index := make(map[string]int)
+	for _, output := range outputs {
+		for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+			parts := strings.SplitN(line, "\t", 3)
+			if len(parts) < 3 {
+				continue
+			}
+
+			added, _ := strconv.Atoi(parts[0])
+			removed, _ := strconv.Atoi(parts[1])
+			oldPath, path := splitRename(parts[2])
+			if i, ok := index[path]; ok {
+				stats[i].Added += added
+				stats[i].Removed += removed
+				continue
+			}
+			index[path] = len(stats)
+			stats = append(stats, FileChange{Path: path, OldPath: oldPath, Added: added, Removed: removed})
+		}
+	}
+	return stats
+}
+
+// splitRename 解析 numstat 中的重命名路径：old => new 或 dir/{old => new}/file；不是重命名时 oldPath 为空
+func splitRename(path string) (oldPath, newPath string) {
+	if open := strings.Index(path, "{"); open >= 0 {
+		if end := strings.Index(path[open:], "}"); end > 0 {
+			inner := path[open+1 : open+end]
+			if from, to, ok := strings.Cut(inner, " => "); ok {
+				prefix, suffix := path[:open], path[open+end+1:]
+				join := func(mid string) string {
+					// {a => }/x 这类一侧为空的写法会多出一个 /
+					return strings.TrimPrefix(strings.ReplaceAll(prefix+mid+suffix, "//", "/"), "/")
+				}
+				return join(from), join(to)
+			}
+		}
+	}
+	if from, to, ok := strings.Cut(path, " => "); ok {
+		return from, to
+	}
+	return "", path
+}
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeNumstat(t *testing.T) {
+	stats := mergeNumstat("3\t1\ta.go\n-\t-\tlogo.png\n", "2\t0\ta.go\n1\t1\tb.go\n")
+	assert.Equal(t, []FileChange{
+		{Path: "a.go", Added: 5, Removed: 1},
+		{Path: "logo.png"},
+		{Path: "b.go", Added: 1, Removed: 1},
+	}, stats)
+
+	renames := mergeNumstat("0\t0\told name.go => new name.go\n2\t1\tpkg/{a => b}/x.go\n0\t0\t{ => docs}/README.md\n")
+	assert.Equal(t, []FileChange{
+		{Path: "new name.go", OldPath: "old name.go"},
+		{Path: "pkg/b/x.go", OldPath: "pkg/a/x.go", Added: 2, Removed: 1},
+		{Path: "docs/README.md", OldPath: "README.md"},
+	}, renames)
+}
+
+func TestInvalidateAfter(t *testing.T) {
+	repoState.state = &RepoState{Dir: "x"}
+	invalidateAfter([]string{"git", "status"})
+	assert.NotNil(t, repoState.state)
+	invalidateAfter([]string{"git", "add", "-A"})
+	assert.Nil(t, repoState.state)
+}
+
+func TestCurrentRepoStateFollowsIndexAndHead(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv("FASTGIT_EXEC_LOG", "0")
+	run := func(args ...string) {
+		out, err := gitRun(args...)
+		require.NoError(t, err, out)
+	}
+	run("init", "-q")
+	run("config", "user.email", "dev@example.com")
+	run("config", "user.name", "dev")
+	run("commit", "-q", "--allow-empty", "-m", "init")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a\n"), 0o644))
+
+	ctx := context.Background()
+	first, err := CurrentRepoState(ctx)
+	require.NoError(t, err)
+	again, err := CurrentRepoState(ctx)
+	require.NoError(t, err)
+	assert.Same(t, first, again)
+	assert.Empty(t, first.Staged)
+
+	// 不经 ShellExec 修改暂存区，快照同样失效
+	run("add", "a.txt")
+	staged, err := CurrentRepoState(ctx)
+	require.NoError(t, err)
+	assert.NotSame(t, first, staged)
+	assert.Equal(t, []FileChange{{Path: "a.txt", Added: 1}}, staged.Staged)
+
+	// 空提交不改动 index，只改变 HEAD
+	run("commit", "-q", "--allow-empty", "-m", "empty")
+	afterCommit, err := CurrentRepoState(ctx)
+	require.NoError(t, err)
+	assert.NotSame(t, staged, afterCommit)
+}
@@ -0,0 +1,110 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// SigningConfig 是 git 的签名设置：gpg.format、user.signingkey、commit.gpgsign、tag.gpgsign
+type SigningConfig struct {
+	// Format 为 openpgp（默认）、ssh 或 x509
+	Format     string
+	Key        string
+	CommitSign bool
+	TagSign    bool
+	// SSHKeyCommand 是 gpg.ssh.defaultKeyCommand，未配置 user.signingkey 时由它提供 ssh 密钥
+	SSHKeyCommand string
+}
+
+// LoadSigningConfig 读取当前仓库生效的签名配置
+func LoadSigningConfig() SigningConfig {
+	cfg := SigningConfig{
+		Format:        gitConfigValue("gpg.format"),
+		Key:           gitConfigValue("user.signingkey"),
+		CommitSign:    gitConfigBool("commit.gpgsign"),
+		TagSign:       gitConfigBool("tag.gpgsign"),
+		SSHKeyCommand: gitConfigValue("gpg.ssh.defaultKeyCommand"),
+	}
+	if cfg.Format == "" {
+		cfg.Format = "openpgp"
+	}
+	return cfg
+}
+
+// Check 在提交/打 tag 前确认签名密钥可用，缺失时给出配置提示，避免 git 只报 "gpg failed to sign the data"
+func (c SigningConfig) Check() error {
+	switch c.Format {
+	case "ssh":
+		if c.Key == "" {
+			if c.SSHKeyCommand != "" {
+				return nil
+			}
+			return errors.New("ssh signing needs a key: git config user.signingkey ~/.ssh/id_ed25519.pub")
+		}
+		if strings.HasPrefix(c.Key, "key::") || strings.HasPrefix(c.Key, "ssh-") {
+			return nil
+		}
+		path := c.Key
+		if rest, ok := strings.CutPrefix(path, "~/"); ok {
+			if home, err := os.UserHomeDir(); err == nil {
+				path = filepath.Join(home, rest)
+			}
+		}
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("ssh signing key %s not found: %w", c.Key, err)
+		}
+		return nil
+	case "openpgp":
+		program := gitConfigValue("gpg.program")
+		if program == "" {
+			program = "gpg"
+		}
+		if _, err := exec.LookPath(program); err != nil {
+			return fmt.Errorf("%s not found in PATH, install GnuPG or set gpg.format ssh", program)
+		}
+		key := c.Key
+		if key == "" {
+			key = gitConfigValue("user.email")
+		}
+		if key == "" {
+			return errors.New("no gpg signing key: git config user.signingkey <key id>")
+		}
+		if err := exec.Command(program, "--list-secret-keys", key).Run(); err != nil {
+			return fmt.Errorf("no gpg secret key for %q (gpg --list-secret-keys); set one with git config user.signingkey <key id>", key)
+		}
+		return nil
+	}
+	return nil
+}
+
+// signingHint 把 git 的签名失败输出转成可操作的提示
+func signingHint(err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := err.Error()
+	for _, marker := range []string{"gpg failed to sign", "failed to sign", "No secret key", "Load key", "ssh-keygen"} {
+		if strings.Contains(msg, marker) {
+			return fmt.Errorf("%w\nhint: check the signing key with `git config user.signingkey` and gpg-agent/ssh-agent, or commit without --sign", err)
+		}
+	}
+	return err
+}
+
+func gitConfigValue(key string) string {
+	out, err := exec.Command("git", "config", "--get", key).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// gitConfigBool 按 git 的布尔规则读取配置，yes/on/1 与 true 等价；未设置或取值非法时为 false
+func gitConfigBool(key string) bool {
+	out, err := exec.Command("git", "config", "--type=bool", "--get", key).Output()
+	return err == nil && strings.TrimSpace(string(out)) == "true"
+}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSigningConfigCheckSSH(t *testing.T) {
+	assert.Error(t, SigningConfig{Format: "ssh"}.Check())
+	assert.NoError(t, SigningConfig{Format: "ssh", SSHKeyCommand: "ssh-add -L"}.Check())
+	assert.NoError(t, SigningConfig{Format: "ssh", Key: "key::ssh-ed25519 AAAA"}.Check())
+
+	key := filepath.Join(t.TempDir(), "id_ed25519.pub")
+	assert.Error(t, SigningConfig{Format: "ssh", Key: key}.Check())
+	assert.NoError(t, os.WriteFile(key, []byte("ssh-ed25519 AAAA"), 0o600))
+	assert.NoError(t, SigningConfig{Format: "ssh", Key: key}.Check())
+}
+
+func TestSigningHint(t *testing.T) {
+	assert.NoError(t, signingHint(nil))
+	plain := errors.New("git commit failed: nothing to commit")
+	assert.Equal(t, plain, signingHint(plain))
+	err := signingHint(errors.New("git commit -S failed: error: gpg failed to sign the data"))
+	assert.Contains(t, err.Error(), "hint:")
+}
+
+func TestLoadSigningConfigBool(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv("FASTGIT_EXEC_LOG", "0")
+	for _, args := range [][]string{{"init", "-q"}, {"config", "commit.gpgsign", "yes"}, {"config", "tag.gpgsign", "0"}} {
+		out, err := gitRun(args...)
+		assert.NoError(t, err, out)
+	}
+	cfg := LoadSigningConfig()
+	assert.True(t, cfg.CommitSign)
+	assert.False(t, cfg.TagSign)
+}
@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/charmbracelet/x/term"
+)
+
+var plainOutput bool
+
+// PlainOutputVar 供 --no-spinner / FASTGIT_PLAIN 绑定
+func PlainOutputVar() *bool { return &plainOutput }
+
+// PlainOutput 报告是否以普通进度行代替动画：显式开启，或 stdout 不是终端（CI 日志、管道）
+func PlainOutput() bool {
+	return plainOutput || !term.IsTerminal(os.Stdout.Fd())
+}
+
+// Spinner 在终端中显示动画；PlainOutput 时改为向 stderr 输出开始、状态变化与结束的进度行，不写 ANSI 控制符
+type Spinner struct {
+	prefix string
+	label  string
+	anim   *spinner.Spinner
+	out    io.Writer
+
+	mu     sync.Mutex
+	start  time.Time
+	suffix string
+}
+
+// NewSpinner 创建进度提示，prefix 如 "generate git message: "
+func NewSpinner(prefix string) *Spinner {
+	s := &Spinner{prefix: prefix, label: strings.TrimRight(prefix, ": ")}
+	if PlainOutput() {
+		s.out = os.Stderr
+		return s
+	}
+	s.anim = spinner.New(spinner.CharSets[35], 100*time.Millisecond, func(sp *spinner.Spinner) { sp.Prefix = prefix })
+	return s
+}
+
+func (s *Spinner) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.start = time.Now()
+	if s.anim != nil {
+		s.anim.Start()
+		return
+	}
+	_, _ = fmt.Fprintf(s.out, "%s...\n", s.label)
+}
+
+// SetSuffix 更新动画后的状态文字；普通模式下每次变化输出一行
+func (s *Spinner) SetSuffix(suffix string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.anim != nil {
+		s.anim.Lock()
+		s.anim.Suffix = " " + suffix
+		s.anim.Unlock()
+		return
+	}
+	if suffix != "" && suffix != s.suffix {
+		_, _ = fmt.Fprintf(s.out, "%s: %s\n", s.label, strings.TrimSpace(suffix))
+	}
+	s.suffix = suffix
+}
+
+func (s *Spinner) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.anim != nil {
+		s.anim.Stop()
+		return
+	}
+	if !s.start.IsZero() {
+		_, _ = fmt.Fprintf(s.out, "%s: done (%s)\n", s.label, time.Since(s.start).Round(time.Millisecond))
+		s.start = time.Time{}
+	}
+}
@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpinnerPlainOutput(t *testing.T) {
+	prev := plainOutput
+	plainOutput = true
+	defer func() { plainOutput = prev }()
+
+	var out bytes.Buffer
+	s := NewSpinner("generate git message: ")
+	s.out = &out
+	s.Start()
+	s.SetSuffix("rate limited, retrying in 2s")
+	s.SetSuffix("rate limited, retrying in 2s")
+	s.Stop()
+	s.Stop()
+
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	assert.Len(t, lines, 3)
+	assert.Equal(t, "generate git message...", string(lines[0]))
+	assert.Equal(t, "generate git message: rate limited, retrying in 2s", string(lines[1]))
+	assert.Contains(t, string(lines[2]), "generate git message: done (")
+	assert.NotContains(t, out.String(), "\x1b")
+}
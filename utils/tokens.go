@@ -0,0 +1,15 @@
+package utils
+
+import "github.com/pubgo/fastgit/pkg/gitdiff"
+
+// DefaultDiffTokenBudget 发给模型的 diff 的默认 token 上限，超过后先分块摘要再生成提交信息
+const DefaultDiffTokenBudget = gitdiff.DefaultTokenBudget
+
+// CountTokens 统计文本的 token 数；分词器不可用时按 4 字节一个 token 估算
+func CountTokens(texts ...string) int { return gitdiff.CountTokens(texts...) }
+
+// Ellipse 把文本截断到 maxTokens 个 token 以内，截断时追加 "..."
+func Ellipse(s string, maxTokens int) string { return gitdiff.Ellipse(s, maxTokens) }
+
+// SplitDiffByTokens 按文件把 unified diff 分组，每组不超过 budget 个 token；单个文件超出时截断到 budget
+func SplitDiffByTokens(diff string, budget int) []string { return gitdiff.SplitByTokens(diff, budget) }
@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/pubgo/funk/v2/log"
+	"github.com/yarlson/tap"
+
+	"github.com/pubgo/fastgit/pkg/timing"
+)
+
+var remoteURLPattern = regexp.MustCompile(`https?://\S+`)
+
+// HasUpstream 判断当前分支是否已设置上游分支
+func HasUpstream(ctx context.Context) bool {
+	return exec.CommandContext(ctx, "git", "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{u}").Run() == nil
+}
+
+// PullRequestURL 从 git push 输出中提取远端打印的创建 PR/MR 链接（GitHub、GitLab、Bitbucket、Gitea 等）
+func PullRequestURL(output string) string {
+	hinted := false
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "remote:") {
+			continue
+		}
+		lower := strings.ToLower(line)
+		if strings.Contains(lower, "pull request") || strings.Contains(lower, "merge request") {
+			hinted = true
+		}
+		link := remoteURLPattern.FindString(line)
+		if link == "" {
+			continue
+		}
+		if hinted || strings.Contains(link, "/pull/new/") || strings.Contains(link, "/merge_requests/new") || strings.Contains(link, "/pull-requests/new") {
+			return link
+		}
+	}
+	return ""
+}
+
+// OfferPullRequest 打印 push 输出中的 PR 创建链接，并询问是否在浏览器中打开
+func OfferPullRequest(ctx context.Context, pushOutput string) {
+	link := PullRequestURL(pushOutput)
+	if link == "" {
+		return
+	}
+	fmt.Printf("\ncreate a pull request: %s\n", link)
+	if !term.IsTerminal(os.Stdin.Fd()) || NonInteractive() {
+		return
+	}
+
+	done := timing.Track(ctx, timing.PhaseUI, "open pull request?")
+	open := tap.Confirm(ctx, tap.ConfirmOptions{
+		Message:      "Open it in the browser?",
+		InitialValue: false,
+	})
+	done()
+	if !open {
+		return
+	}
+	if err := OpenBrowser(ctx, link); err != nil {
+		log.Warn().Err(err).Msg("failed to open browser")
+	}
+}
+
+// OpenBrowser 用系统默认浏览器打开链接
+func OpenBrowser(ctx context.Context, link string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.CommandContext(ctx, "open", link)
+	case "windows":
+		cmd = exec.CommandContext(ctx, "rundll32", "url.dll,FileProtocolHandler", link)
+	default:
+		cmd = exec.CommandContext(ctx, "xdg-open", link)
+	}
+	return cmd.Start()
+}
@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPullRequestURL(t *testing.T) {
+	github := `remote:
+remote: Create a pull request for 'feat/login' on GitHub by visiting:
+remote:      https://github.com/pubgo/fastgit/pull/new/feat/login
+remote:
+To github.com:pubgo/fastgit.git
+ * [new branch]      feat/login -> feat/login`
+	assert.Equal(t, "https://github.com/pubgo/fastgit/pull/new/feat/login", PullRequestURL(github))
+
+	gitlab := `remote: To create a merge request for feat/login, visit:
+remote:   https://gitlab.com/team/app/-/merge_requests/new?merge_request%5Bsource_branch%5D=feat%2Flogin`
+	assert.Equal(t, "https://gitlab.com/team/app/-/merge_requests/new?merge_request%5Bsource_branch%5D=feat%2Flogin", PullRequestURL(gitlab))
+
+	assert.Empty(t, PullRequestURL("remote: see https://example.com/status\nEverything up-to-date"))
+}
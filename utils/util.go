@@ -7,137 +7,99 @@ import (
 	"os/exec"
 	"os/signal"
 	"path/filepath"
-	"strconv"
 	"strings"
 	"sync"
 	"syscall"
 	"time"
 
-	"github.com/bitfield/script"
-	"github.com/briandowns/spinner"
 	semver "github.com/hashicorp/go-version"
-	"github.com/pubgo/funk/v2/assert"
 	"github.com/pubgo/funk/v2/errors"
 	"github.com/pubgo/funk/v2/log"
-	"github.com/pubgo/funk/v2/recovery"
 	"github.com/pubgo/funk/v2/result"
-	"github.com/pubgo/funk/v2/typex"
-	"github.com/samber/lo"
 	"github.com/tidwall/match"
 	_ "github.com/tidwall/match"
 	"mvdan.cc/sh/v3/shell"
 
 	"github.com/pubgo/fastgit/configs"
+	"github.com/pubgo/fastgit/pkg/daemon"
+	"github.com/pubgo/fastgit/pkg/execlog"
+	"github.com/pubgo/fastgit/pkg/semtag"
+	"github.com/pubgo/fastgit/pkg/timing"
 )
 
-func GetAllRemoteTags(ctx context.Context) []*semver.Version {
+// GetAllRemoteTags 获取 origin 上的 v* tag，跳过无法解析为 semver 的 tag
+func GetAllRemoteTags(ctx context.Context) ([]*semver.Version, error) {
 	log.Info().Msg("get all remote tags")
-	output := ShellExecOutput(ctx, "git", "ls-remote", "--tags", "origin").Unwrap()
-	return lo.Map(strings.Split(output, "\n"), func(item string, index int) *semver.Version {
-		item = strings.TrimSpace(item)
-		if !strings.HasPrefix(item, "refs/tags/") {
-			return nil
-		}
-
-		item = strings.TrimPrefix(item, "refs/tags/")
-		if !strings.HasPrefix(item, "v") {
-			return nil
-		}
-
-		vv, err := semver.NewSemver(item)
-		if err != nil {
-			log.Err(err).Str("tag", item).Msg("failed to parse git tag")
-			assert.Must(err)
-		}
-		return vv
-	})
-}
-
-func GetAllGitTags(ctx context.Context) []*semver.Version {
-	log.Info().Msg("get all tags")
-	var tagText = strings.TrimSpace(ShellExecOutput(ctx, "git", "tag").Unwrap())
-	var tags = strings.Split(tagText, "\n")
-	var versions = make([]*semver.Version, 0, len(tags))
+	output, err := ShellExecOutput(ctx, "git", "ls-remote", "--tags", "origin").UnwrapErr()
+	if err != nil {
+		return nil, err
+	}
 
-	for _, tag := range tags {
-		tag = strings.TrimSpace(tag)
-		if !strings.HasPrefix(tag, "v") {
+	var tags []string
+	for _, line := range strings.Split(output, "\n") {
+		_, ref, ok := strings.Cut(strings.TrimSpace(line), "refs/tags/")
+		if !ok || strings.HasSuffix(ref, "^{}") {
 			continue
 		}
-
-		vv, err := semver.NewSemver(tag)
-		if err != nil {
-			log.Err(err).Str("tag", tag).Msg("failed to parse git tag")
-			assert.Must(err)
-		}
-		versions = append(versions, vv)
+		tags = append(tags, ref)
 	}
-	return versions
+	return ParseTagVersions(strings.Join(tags, "\n")), nil
 }
 
-func GetCurMaxVer(ctx context.Context) *semver.Version {
-	tags := GetAllGitTags(ctx)
-	return typex.DoBlock1(func() *semver.Version {
-		return lo.MaxBy(tags, func(a *semver.Version, b *semver.Version) bool { return a.Compare(b) > 0 })
-	})
+// GetAllGitTags 通过 for-each-ref 获取本地 v* tag，结果已按版本号倒序排列
+// daemon 运行时直接使用其缓存的 tag 列表
+func GetAllGitTags(ctx context.Context) ([]*semver.Version, error) {
+	return GetPrefixedGitTags(ctx, "")
 }
 
-func GetNextReleaseTag(tags []*semver.Version) *semver.Version {
-	if len(tags) == 0 {
-		return semver.Must(semver.NewSemver("v0.0.1"))
+// GetPrefixedGitTags 获取 <prefix>v* 形式的 tag（monorepo 模块 tag），返回的版本号已去掉前缀
+func GetPrefixedGitTags(ctx context.Context, prefix string) ([]*semver.Version, error) {
+	log.Info().Str("prefix", prefix).Msg("get all tags")
+	if snap, err := daemon.Query(configs.GetRepoPath()); err == nil {
+		return ParsePrefixedTagVersions(strings.Join(snap.Tags, "\n"), prefix), nil
 	}
 
-	var curMaxVer = typex.DoBlock1(func() *semver.Version {
-		return lo.MaxBy(tags, func(a *semver.Version, b *semver.Version) bool { return a.Compare(b) > 0 })
-	})
-
-	if curMaxVer.Prerelease() == "" {
-		segments := curMaxVer.Core().Segments()
-		return assert.Must1(semver.NewSemver(fmt.Sprintf("v%d.%d.%d", segments[0], segments[1], segments[2]+1)))
+	output, err := ShellExecOutput(ctx, "git", "-c", "versionsort.suffix=-", "for-each-ref",
+		"--sort=-v:refname", `--format="%(refname:lstrip=2)"`, "refs/tags/"+prefix+"v*").UnwrapErr()
+	if err != nil {
+		return nil, err
 	}
-
-	return curMaxVer.Core()
+	return ParsePrefixedTagVersions(output, prefix), nil
 }
 
-func GetNextTag(pre string, tags []*semver.Version) *semver.Version {
-	if len(tags) == 0 {
-		return semver.Must(semver.NewSemver("v0.0.1"))
-	}
-
-	var maxVer = GetNextGitMaxTag(tags)
-	var curMaxVer = typex.DoBlock1(func() *semver.Version {
-		tags = lo.Filter(tags, func(item *semver.Version, index int) bool { return strings.Contains(item.String(), pre) })
-		return lo.MaxBy(tags, func(a *semver.Version, b *semver.Version) bool { return a.Compare(b) > 0 })
-	})
+// ParseTagVersions 逐行解析 tag 名，保持输入顺序，跳过无法解析为 semver 的 tag
+func ParseTagVersions(output string) []*semver.Version {
+	return ParsePrefixedTagVersions(output, "")
+}
 
-	var ver string
-	if curMaxVer != nil && curMaxVer.Core().GreaterThanOrEqual(maxVer) {
-		ver = strings.ReplaceAll(curMaxVer.Prerelease(), fmt.Sprintf("%s.", pre), "")
-		ver = fmt.Sprintf("v%s-%s.%d", curMaxVer.Core().String(), pre, assert.Must1(strconv.Atoi(ver))+1)
-	} else {
-		ver = fmt.Sprintf("v%s-%s.1", maxVer.Core().String(), pre)
+// ParsePrefixedTagVersions 只保留以 prefix 开头的 tag，去掉前缀后按 semver 解析
+func ParsePrefixedTagVersions(output, prefix string) []*semver.Version {
+	versions, invalid := semtag.Parse(output, prefix)
+	for _, tag := range invalid {
+		log.Warn().Str("tag", tag).Msg("skip invalid semver tag")
 	}
-	return assert.Must1(semver.NewSemver(ver))
+	return versions
 }
 
-func GetNextGitMaxTag(tags []*semver.Version) *semver.Version {
-	maxVer := semver.Must(semver.NewVersion("v0.0.1"))
-	if len(tags) == 0 {
-		return maxVer
+// GetCurMaxVer 返回本地最大版本 tag，无 tag 时返回 nil
+func GetCurMaxVer(ctx context.Context) (*semver.Version, error) {
+	tags, err := GetAllGitTags(ctx)
+	if err != nil || len(tags) == 0 {
+		return nil, err
 	}
+	return tags[0], nil
+}
 
-	for _, tag := range tags {
-		if maxVer.Compare(tag) >= 0 {
-			continue
-		}
-
-		maxVer = tag
-	}
+func GetNextReleaseTag(tags []*semver.Version) *semver.Version {
+	return semtag.NextRelease(tags)
+}
 
-	segments := maxVer.Segments()
-	v3Segment := lo.If(strings.Contains(maxVer.String(), "-"), segments[2]).Else(segments[2] + 1)
+func GetNextTag(pre string, tags []*semver.Version) (*semver.Version, error) {
+	return semtag.NextPrerelease(pre, tags)
+}
 
-	return semver.Must(semver.NewVersion(fmt.Sprintf("v%d.%d.%d", segments[0], segments[1], v3Segment)))
+func GetNextGitMaxTag(tags []*semver.Version) *semver.Version {
+	return semtag.NextCore(tags)
 }
 
 func UsageDesc(format string, args ...interface{}) string {
@@ -160,6 +122,30 @@ func Context() context.Context {
 	return ctx
 }
 
+// CommandMetaNoTTY 标记命令不需要终端（如 CI 中使用的 version set/bump）
+const CommandMetaNoTTY = "no_tty"
+
+// NoTTYMetadata 返回允许在非终端环境运行的命令元数据
+func NoTTYMetadata() map[string]string {
+	return map[string]string{CommandMetaNoTTY: "true"}
+}
+
+// CommandMetaNonInteractive 标记命令支持 --yes/--non-interactive，带该参数时可在非终端环境运行
+const CommandMetaNonInteractive = "non_interactive"
+
+// NonInteractiveMetadata 返回支持非交互模式的命令元数据
+func NonInteractiveMetadata() map[string]string {
+	return map[string]string{CommandMetaNonInteractive: "true"}
+}
+
+var nonInteractive bool
+
+// NonInteractiveVar 供 --yes/--non-interactive 绑定，bootstrap 据此放行非终端调用
+func NonInteractiveVar() *bool { return &nonInteractive }
+
+// NonInteractive 报告是否处于非交互模式：不弹出提示、不打开编辑器，取缺省选项
+func NonInteractive() bool { return nonInteractive }
+
 func IsHelp() bool {
 	help := strings.TrimSpace(os.Args[len(os.Args)-1])
 	if strings.HasSuffix(help, "--help") || strings.HasSuffix(help, "-h") {
@@ -168,22 +154,26 @@ func IsHelp() bool {
 	return false
 }
 
-func GitPush(ctx context.Context, args ...string) string {
+// GitPush 执行 git push 并显示 spinner；被远端拒绝（exit 1）时返回 git 的输出而不是错误，便于调用方判断是否需要 pull
+func GitPush(ctx context.Context, args ...string) (string, error) {
 	now := time.Now()
+	pushArgs := args
 	args = append([]string{"git", "push"}, args...)
 	output := result.Async(func() result.Result[string] { return ShellExecOutput(ctx, args...) })
 	time.Sleep(time.Millisecond * 20)
 
-	spin := spinner.New(spinner.CharSets[35], 100*time.Millisecond, func(s *spinner.Spinner) {
-		s.Prefix = strings.Join(args, " ") + ":"
-	})
+	spin := NewSpinner(strings.Join(args, " ") + ":")
 	spin.Start()
-	res := output.Await(ctx).Unwrap()
+	res, err := output.Await(ctx).UnwrapErr()
 	spin.Stop()
+	if err != nil {
+		return "", err
+	}
 	if res != "" {
 		log.Info().Str("dur", time.Since(now).String()).Msgf("shell result: \n%s\n", res)
 	}
-	return res
+	warnMirrors(ctx, pushArgs...)
+	return res, nil
 }
 
 func ShellExec(ctx context.Context, args ...string) (err error) {
@@ -200,13 +190,27 @@ func ShellExec(ctx context.Context, args ...string) (err error) {
 
 func ShellExecOutput(ctx context.Context, args ...string) (r result.Result[string]) {
 	defer result.Recovery(&r, func(err error) error {
-		if exitErr, ok := errors.AsA[exec.ExitError](err); ok && exitErr.String() == "signal: interrupt" {
-			os.Exit(1)
+		// Ctrl-C 以错误返回给调用方，由命令自行收尾，不在这里直接退出进程
+		if ctx.Err() != nil {
+			return fmt.Errorf("%s: %w", strings.Join(args, " "), ctx.Err())
+		}
+		if IsErrSignalInterrupt(err) {
+			return fmt.Errorf("%s: interrupted: %w", strings.Join(args, " "), err)
 		}
-
 		return err
 	})
 
+	phase := timing.PhaseShell
+	if len(args) > 0 && args[0] == "git" {
+		phase = timing.PhaseGit
+	}
+	defer timing.Track(ctx, phase, strings.Join(args, " "))()
+	defer invalidateAfter(args)
+	record := func(error) {}
+	if len(args) > 0 && args[0] == "git" {
+		record = execlog.Track("", args[1:])
+	}
+
 	sh := getShell()
 	if sh != "" {
 		args = []string{sh, "-c", fmt.Sprintf(`'%s'`, strings.Join(args, " "))}
@@ -220,6 +224,13 @@ func ShellExecOutput(ctx context.Context, args ...string) (r result.Result[strin
 	})
 	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
 	output, err := cmd.CombinedOutput()
+	record(err)
+	if err != nil && ctx.Err() != nil {
+		return r.WithErr(fmt.Errorf("%s: %w", cmdLine, ctx.Err()))
+	}
+	if IsErrSignalInterrupt(err) {
+		return r.WithErr(fmt.Errorf("%s: interrupted: %w", cmdLine, err))
+	}
 	if err != nil && !IsOsExit(err) {
 		log.Err(err, ctx).Msg("git error\n" + string(output))
 		return r.WithErr(err)
@@ -238,7 +249,7 @@ func IsRemotePushCommitFailed(err string) bool {
 
 func Spin[T any](name string, do func() result.Result[T]) (r result.Result[T]) {
 	defer result.Recovery(&r)
-	s := spinner.New(spinner.CharSets[35], 100*time.Millisecond, func(s *spinner.Spinner) { s.Prefix = name })
+	s := NewSpinner(name)
 	s.Start()
 	defer s.Stop()
 	return do()
@@ -251,30 +262,51 @@ func Spin[T any](name string, do func() result.Result[T]) (r result.Result[T]) {
 //
 // nothing to commit, working tree clean
 
-func PreGitPush(ctx context.Context) string {
-	defer recovery.Exit()
-
-	isDirty := IsDirty().Unwrap()
-	if isDirty {
-		return ""
+// PreGitPush 在工作区干净且有未推送（或 amend 后与远端分叉）的提交时先推送，返回 git push 的输出
+func PreGitPush(ctx context.Context) (string, error) {
+	isDirty, err := IsDirty().UnwrapErr()
+	if err != nil || isDirty {
+		return "", err
 	}
 
-	res := ShellExecOutput(ctx, "git", "status").Unwrap()
+	res, err := ShellExecOutput(ctx, "git", "status").UnwrapErr()
+	if err != nil {
+		return "", err
+	}
 	needPush := strings.Contains(res, "Your branch is ahead of") && strings.Contains(res, "(use \"git push\" to publish your local commits)")
 	if !needPush {
 		needPush =
 			match.Match(res, "*Your branch and '*' have diverged*") &&
-				strings.Contains(ShellExecOutput(ctx, "git", "reflog", "-1").Unwrap(), "(amend)")
+				strings.Contains(ShellExecOutput(ctx, "git", "reflog", "-1").UnwrapOr(""), "(amend)")
 	}
 
 	if !needPush {
-		return ""
+		return "", nil
 	}
 
-	return GitPush(ctx, "--force-with-lease", "origin", GetBranchName())
+	branch, err := BranchName()
+	if err != nil {
+		return "", err
+	}
+	return GitPush(ctx, "--force-with-lease", "origin", branch)
 }
 
-var GetBranchName = sync.OnceValue(func() string { return GetCurrentBranch().Unwrap() })
+// BranchName 返回当前分支名，优先取 daemon 快照；分离 HEAD 时为空串，不在 git 仓库中时返回错误
+var BranchName = sync.OnceValues(func() (string, error) {
+	if snap, err := daemon.Query(configs.GetRepoPath()); err == nil && snap.Branch != "" {
+		return snap.Branch, nil
+	}
+	return GetCurrentBranch().UnwrapErr()
+})
+
+// GetBranchName 同 BranchName，出错时返回空串；需要分支才能继续的命令应使用 BranchName 处理错误
+var GetBranchName = sync.OnceValue(func() string {
+	branch, err := BranchName()
+	if err != nil {
+		log.Debug().Err(err).Msg("failed to detect the current branch")
+	}
+	return branch
+})
 
 func LogConfigAndBranch() {
 	log.Info().Msgf("branch: %s", GetBranchName())
@@ -318,25 +350,14 @@ func IsStatusNeedPush(msg string) bool {
 	return match.Match(msg, pattern)
 }
 
-var editors = []string{"zed", "subl", "vim", "code", "open"}
-
-func GetEditor() (r result.Result[string]) {
-	for _, editor := range editors {
-		_, err := exec.LookPath(editor)
-		if err == nil {
-			return r.WithValue(editor)
-		}
+// Edit 用 Editor() 打开 editPath，等待编辑器退出
+func Edit(ctx context.Context, editPath string) error {
+	path, err := filepath.Abs(editPath)
+	if err != nil {
+		return err
 	}
-	return r.WithErr(errors.Errorf("no editor found in %q", editors))
-}
-
-func Edit(editPath string) {
-	log.Info().Msgf("edit path: %s", editPath)
-	editor := GetEditor().Unwrap()
-	path := assert.Exit1(filepath.Abs(editPath))
-	shellData := fmt.Sprintf(`%s "%s"`, editor, path)
-	log.Info().Msg(shellData)
-	assert.Exit1(script.Exec(shellData).Stdout())
+	log.Info().Msgf("edit path: %s", path)
+	return EditFiles(ctx, path)
 }
 
 func IsOsExit(err error) bool { return IsErrExit1(err) || IsErrSignalInterrupt(err) }
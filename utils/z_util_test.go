@@ -25,3 +25,44 @@ func TestMatch(t *testing.T) {
 
 	t.Log(strings.Contains(utils.ShellExecOutput(context.Background(), "git", "reflog", "-1").Unwrap(), "(amend)"))
 }
+
+func TestParseTagVersions(t *testing.T) {
+	var output = `
+v1.10.0
+v1.2.0
+v1.2.0-alpha.1
+vnext
+latest
+`
+	tags := utils.ParseTagVersions(output)
+	assert.Len(t, tags, 3)
+	assert.Equal(t, "v1.10.0", tags[0].Original())
+	assert.Equal(t, "v1.2.0-alpha.1", tags[2].Original())
+	assert.Empty(t, utils.ParseTagVersions(""))
+}
+
+func TestParsePrefixedTagVersions(t *testing.T) {
+	var output = `
+api/v1.3.0
+web/v2.0.0
+api/v1.2.0
+v9.9.9
+`
+	tags := utils.ParsePrefixedTagVersions(output, "api/")
+	assert.Len(t, tags, 2)
+	assert.Equal(t, "v1.3.0", tags[0].Original())
+}
+
+func TestShellExecOutputCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := utils.ShellExecOutput(ctx, "sleep", "1").UnwrapErr()
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestBranchNameOutsideRepo(t *testing.T) {
+	t.Chdir(t.TempDir())
+	_, err := utils.BranchName()
+	assert.Error(t, err)
+	assert.NotPanics(t, func() { assert.Empty(t, utils.GetBranchName()) })
+}